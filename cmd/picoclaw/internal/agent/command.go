@@ -9,6 +9,10 @@ func NewAgentCommand() *cobra.Command {
 		message    string
 		sessionKey string
 		model      string
+		agentName  string
+		toolsSpec  string
+		watch      string
+		noTools    bool
 		debug      bool
 	)
 
@@ -16,8 +20,11 @@ func NewAgentCommand() *cobra.Command {
 		Use:   "agent",
 		Short: "Interact with the agent directly",
 		Args:  cobra.NoArgs,
+		Example: `picoclaw agent -m "What's on my calendar today?"
+picoclaw agent --watch prompt.txt
+picoclaw agent --no-tools -m "Summarize this repo"`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return agentCmd(message, sessionKey, model, debug)
+			return agentCmd(message, sessionKey, model, agentName, toolsSpec, watch, noTools, debug)
 		},
 	}
 
@@ -25,6 +32,13 @@ func NewAgentCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Send a single message (non-interactive mode)")
 	cmd.Flags().StringVarP(&sessionKey, "session", "s", "cli:default", "Session key")
 	cmd.Flags().StringVarP(&model, "model", "", "", "Model to use")
+	cmd.Flags().StringVar(&agentName, "agent", "", "Named agent to talk to (default: the default agent)")
+	cmd.Flags().StringVar(&toolsSpec, "tools", "auto", "Tools offered to the model: none, auto, or a comma-separated list")
+	cmd.Flags().BoolVar(&noTools, "no-tools", false, "Offer no tools and leave skills out of the prompt, for baseline comparison")
+	cmd.Flags().StringVar(&watch, "watch", "", "Run the agent on a file's contents every time the file changes")
+
+	cmd.MarkFlagsMutuallyExclusive("message", "watch")
+	cmd.MarkFlagsMutuallyExclusive("tools", "no-tools")
 
 	return cmd
 }
@@ -30,4 +30,8 @@ func TestNewAgentCommand(t *testing.T) {
 	assert.NotNil(t, cmd.Flags().Lookup("message"))
 	assert.NotNil(t, cmd.Flags().Lookup("session"))
 	assert.NotNil(t, cmd.Flags().Lookup("model"))
+	assert.NotNil(t, cmd.Flags().Lookup("agent"))
+	assert.NotNil(t, cmd.Flags().Lookup("tools"))
+	assert.NotNil(t, cmd.Flags().Lookup("no-tools"))
+	assert.NotNil(t, cmd.Flags().Lookup("watch"))
 }
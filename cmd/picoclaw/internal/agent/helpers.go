@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/chzyer/readline"
 
@@ -16,13 +18,22 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
 )
 
-func agentCmd(message, sessionKey, model string, debug bool) error {
+func agentCmd(message, sessionKey, model, agentName, toolsSpec, watch string, noTools, debug bool) error {
 	if sessionKey == "" {
 		sessionKey = "cli:default"
 	}
 
+	if noTools {
+		toolsSpec = "none"
+	}
+	toolPolicy, err := agent.ParseToolPolicy(toolsSpec)
+	if err != nil {
+		return err
+	}
+
 	if debug {
 		logger.SetLevel(logger.DEBUG)
 		fmt.Println("🔍 Debug mode enabled")
@@ -33,6 +44,19 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 		return fmt.Errorf("error loading config: %w", err)
 	}
 
+	if agentName != "" {
+		ac, err := cfg.Agents.Lookup(agentName)
+		if err != nil {
+			return err
+		}
+		sessionKey = agentSessionKey(ac.ID, sessionKey)
+	}
+
+	if noTools {
+		// channel_tools would otherwise offer tools back on the cli channel
+		cfg.Agents.Defaults.ChannelTools = nil
+	}
+
 	if model != "" {
 		cfg.Agents.Defaults.ModelName = model
 	}
@@ -50,6 +74,10 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 	msgBus := bus.NewMessageBus()
 	defer msgBus.Close()
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
+	agentLoop.SetToolPolicy(toolPolicy)
+	if noTools {
+		agentLoop.DisableSkills()
+	}
 
 	// Print agent startup info (only for interactive mode)
 	startupInfo := agentLoop.GetStartupInfo()
@@ -60,8 +88,13 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 			"skills_available": startupInfo["skills"].(map[string]any)["available"],
 		})
 
+	if watch != "" {
+		return watchMode(agentLoop, watch, sessionKey)
+	}
+
 	if message != "" {
-		ctx := context.Background()
+		ctx, stop := turnContext()
+		defer stop()
 		response, err := agentLoop.ProcessDirect(ctx, message, sessionKey)
 		if err != nil {
 			return fmt.Errorf("error processing message: %w", err)
@@ -76,6 +109,22 @@ func agentCmd(message, sessionKey, model string, debug bool) error {
 	return nil
 }
 
+// turnContext returns the context for one agent turn. Ctrl+C cancels it,
+// which stops the turn with its partial response instead of killing the
+// process; once stop is called, Ctrl+C behaves as usual again.
+func turnContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// agentSessionKey scopes sessionKey to agentID, so the turn runs on that
+// agent and keeps a history separate from the other agents'.
+func agentSessionKey(agentID, sessionKey string) string {
+	if parsed := routing.ParseAgentSessionKey(sessionKey); parsed != nil {
+		sessionKey = parsed.Rest
+	}
+	return "agent:" + routing.NormalizeAgentID(agentID) + ":" + sessionKey
+}
+
 func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 	prompt := fmt.Sprintf("%s You: ", internal.Logo)
 
@@ -115,8 +164,9 @@ func interactiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			return
 		}
 
-		ctx := context.Background()
+		ctx, stop := turnContext()
 		response, err := agentLoop.ProcessDirect(ctx, input, sessionKey)
+		stop()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			continue
@@ -150,8 +200,9 @@ func simpleInteractiveMode(agentLoop *agent.AgentLoop, sessionKey string) {
 			return
 		}
 
-		ctx := context.Background()
+		ctx, stop := turnContext()
 		response, err := agentLoop.ProcessDirect(ctx, input, sessionKey)
+		stop()
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			continue
@@ -0,0 +1,12 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentSessionKey(t *testing.T) {
+	assert.Equal(t, "agent:coder:cli:default", agentSessionKey("coder", "cli:default"))
+	assert.Equal(t, "agent:coder:cli:default", agentSessionKey("Coder", "agent:main:cli:default"))
+}
@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+)
+
+// watchDebounce collects the several writes an editor makes when saving
+// into one run.
+const watchDebounce = 300 * time.Millisecond
+
+// watchMode runs the agent with the contents of path as the prompt, then
+// again every time the file changes, until Ctrl+C. A change while the agent
+// is still answering cancels that run in favor of the new contents; what it
+// had produced by then is still printed, marked as interrupted.
+func watchMode(agentLoop *agent.AgentLoop, path, sessionKey string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	run := func(ctx context.Context, prompt string) {
+		response, err := agentLoop.ProcessDirect(ctx, prompt, sessionKey)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			return
+		}
+		fmt.Printf("\n%s %s\n\n", internal.Logo, response)
+	}
+
+	fmt.Printf("Watching %s for changes... (Ctrl+C to stop)\n", path)
+	err := watchFile(ctx, path, func() {
+		fmt.Printf("%s %s changed, running the agent...\n", internal.Logo, filepath.Base(path))
+	}, run)
+	fmt.Println("\nGoodbye!")
+	return err
+}
+
+// watchFile calls run with the trimmed contents of path once at the start
+// and after every change to them, until ctx ends. Empty and unchanged
+// contents are skipped. The directory is watched rather than the file, so
+// editors that save by replacing the file are noticed too. onChange, if
+// set, is called before each run but the first.
+func watchFile(
+	ctx context.Context, path string, onChange func(), run func(context.Context, string),
+) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot watch %s: %w", path, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("cannot watch %s: %w", path, err)
+	}
+
+	var (
+		last      string
+		cancelRun = func() {}
+		done      = make(chan struct{})
+	)
+	close(done)
+	start := func(first bool) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return // mid-save; the next event reads it
+		}
+		prompt := strings.TrimSpace(string(data))
+		if prompt == "" || prompt == last {
+			return
+		}
+		last = prompt
+
+		cancelRun()
+		<-done
+		if !first && onChange != nil {
+			onChange()
+		}
+		var runCtx context.Context
+		runCtx, cancelRun = context.WithCancel(ctx)
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			defer close(done)
+			run(runCtx, prompt)
+		}(done)
+	}
+	defer func() {
+		cancelRun()
+		<-done
+	}()
+
+	start(true)
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path && event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				debounce.Reset(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("Watch error: %v\n", err)
+		case <-debounce.C:
+			start(false)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchFileRunsOnEveryChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.txt")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	prompts := make(chan string, 10)
+	changes := 0
+	errc := make(chan error, 1)
+	go func() {
+		errc <- watchFile(ctx, path, func() { changes++ }, func(_ context.Context, prompt string) {
+			prompts <- prompt
+		})
+	}()
+
+	next := func() string {
+		select {
+		case p := <-prompts:
+			return p
+		case <-time.After(5 * time.Second):
+			t.Fatal("agent did not run")
+			return ""
+		}
+	}
+	assert.Equal(t, "first", next())
+
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0o644))
+	assert.Equal(t, "second", next())
+
+	// Saving the same contents again, or emptying the file, runs nothing.
+	require.NoError(t, os.WriteFile(path, []byte("second\n"), 0o644))
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	time.Sleep(3 * watchDebounce)
+
+	// Editors that replace the file are noticed too.
+	tmp := path + ".swp"
+	require.NoError(t, os.WriteFile(tmp, []byte("third"), 0o644))
+	require.NoError(t, os.Rename(tmp, path))
+	assert.Equal(t, "third", next())
+
+	cancel()
+	require.NoError(t, <-errc)
+	assert.Empty(t, prompts)
+	assert.Equal(t, 2, changes)
+}
+
+func TestWatchFileMissing(t *testing.T) {
+	err := watchFile(context.Background(), filepath.Join(t.TempDir(), "nope.txt"), nil,
+		func(context.Context, string) {})
+	require.ErrorContains(t, err, "cannot watch")
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/audit"
+)
+
+func NewAuditCommand() *cobra.Command {
+	var (
+		skill  string
+		tool   string
+		since  string
+		until  string
+		lines  int
+		follow bool
+		asJSON bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show the tool call audit log",
+		Long: "Show the tools the agent ran, with their arguments and outcome, from " +
+			"memory/audit.jsonl in the workspace. Dates are YYYY-MM-DD or RFC 3339.",
+		Example: `picoclaw audit
+picoclaw audit --skill weather --since 2026-03-01
+picoclaw audit --tool exec -n 50
+picoclaw audit -f`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+
+			filter := audit.Filter{Skill: skill, Tool: tool}
+			if filter.Since, err = parseDate(since, false); err != nil {
+				return err
+			}
+			if filter.Until, err = parseDate(until, true); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			path := cfg.Tools.Audit.LogPath()
+			if follow {
+				return followCmd(cmd.Context(), out, path, filter, lines, asJSON)
+			}
+			return auditCmd(out, path, filter, lines, asJSON)
+		},
+	}
+
+	cmd.Flags().StringVar(&skill, "skill", "", "Only show calls made for this skill")
+	cmd.Flags().StringVar(&tool, "tool", "", "Only show calls of this tool")
+	cmd.Flags().StringVar(&since, "since", "", "Only show calls on or after this date")
+	cmd.Flags().StringVar(&until, "until", "", "Only show calls up to this date (inclusive for a day)")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 20, "Number of most recent entries to show (0 for all)")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new entries as they are written")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print entries as JSON lines")
+
+	return cmd
+}
+
+// parseDate parses a --since or --until value. A bare date used as the upper
+// bound covers the whole day.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or RFC 3339", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
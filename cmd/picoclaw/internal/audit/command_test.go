@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/audit"
+)
+
+func TestNewAuditCommand(t *testing.T) {
+	cmd := NewAuditCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "audit", cmd.Use)
+	assert.Equal(t, "Show the tool call audit log", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	for _, name := range []string{"skill", "tool", "since", "until", "lines", "follow", "json"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing flag %q", name)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	since, err := parseDate("2026-03-01", false)
+	require.NoError(t, err)
+	until, err := parseDate("2026-03-01", true)
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, until.Sub(since))
+
+	_, err = parseDate("March 1st", false)
+	assert.Error(t, err)
+}
+
+func TestAuditCmd_FiltersAndLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := audit.NewLog(path, nil)
+	for _, e := range []audit.Entry{
+		{Tool: "exec", Skill: "weather", Success: true},
+		{Tool: "read_file", Success: true},
+		{Tool: "exec", Skill: "weather", Error: "exit status 1"},
+	} {
+		require.NoError(t, log.Record(e))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, auditCmd(&out, path, audit.Filter{Skill: "weather"}, 1, false))
+	assert.Contains(t, out.String(), "✗ exec [weather]")
+	assert.Contains(t, out.String(), "error: exit status 1")
+	assert.NotContains(t, out.String(), "read_file")
+}
+
+func TestReadFrom_SkipsPartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, audit.NewLog(path, nil).Record(audit.Entry{Tool: "exec"}))
+
+	var seen []string
+	offset, err := readFrom(path, 0, func(e audit.Entry) { seen = append(seen, e.Tool) })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"exec"}, seen)
+
+	offset, err = readFrom(path, offset, func(e audit.Entry) { seen = append(seen, e.Tool) })
+	require.NoError(t, err)
+	assert.Len(t, seen, 1)
+	assert.Positive(t, offset)
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/audit"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// followInterval is how often --follow checks the log for new entries.
+const followInterval = time.Second
+
+func auditCmd(out io.Writer, path string, filter audit.Filter, lines int, asJSON bool) error {
+	entries, err := audit.Read(path, filter)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No audit entries.")
+		return nil
+	}
+	if lines > 0 && len(entries) > lines {
+		entries = entries[len(entries)-lines:]
+	}
+	for _, e := range entries {
+		printEntry(out, e, asJSON)
+	}
+	return nil
+}
+
+// followCmd prints the last entries and then new ones as they are appended,
+// until ctx is cancelled.
+func followCmd(ctx context.Context, out io.Writer, path string, filter audit.Filter, lines int, asJSON bool) error {
+	entries, err := audit.Read(path, filter)
+	if err != nil {
+		return err
+	}
+	if lines > 0 && len(entries) > lines {
+		entries = entries[len(entries)-lines:]
+	}
+	for _, e := range entries {
+		printEntry(out, e, asJSON)
+	}
+
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		offset, err = readFrom(path, offset, func(e audit.Entry) {
+			if filter.Match(e) {
+				printEntry(out, e, asJSON)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readFrom passes the complete lines after offset to fn and returns the
+// offset to continue from. A truncated log is read again from the start.
+func readFrom(path string, offset int64, fn func(audit.Entry)) (int64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return offset, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// A line without its newline is still being written.
+			return offset, nil
+		}
+		offset += int64(len(line))
+		var e audit.Entry
+		if json.Unmarshal(line, &e) == nil {
+			fn(e)
+		}
+	}
+}
+
+func printEntry(out io.Writer, e audit.Entry, asJSON bool) {
+	if asJSON {
+		line, _ := json.Marshal(e)
+		fmt.Fprintln(out, string(line))
+		return
+	}
+
+	status := "✓"
+	if !e.Success {
+		status = "✗"
+	}
+	tool := e.Tool
+	if e.Skill != "" {
+		tool += " [" + e.Skill + "]"
+	}
+	args, _ := json.Marshal(e.Args)
+	fmt.Fprintf(out, "%s %s %s %s:%s %dms %s\n",
+		e.Time.Local().Format(time.DateTime), status, tool, e.Channel, e.ChatID, e.DurationMS,
+		utils.Truncate(string(args), 120))
+	if e.Error != "" {
+		fmt.Fprintf(out, "    error: %s\n", e.Error)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,14 +16,25 @@ import (
 	"github.com/sipeed/picoclaw/pkg/providers"
 )
 
-const supportedProvidersMsg = "supported providers: openai, anthropic, google-antigravity"
+const supportedProvidersMsg = "supported providers: openai, anthropic, google-antigravity, clawhub"
 
-func authLoginCmd(provider string, useDeviceCode bool) error {
+func authLoginCmd(provider, account string, useDeviceCode bool, totpSecret string) error {
+	if totpSecret != "" && provider != "anthropic" {
+		return fmt.Errorf("--totp-secret is only supported for anthropic")
+	}
+	if account != "" {
+		if provider != "openai" && provider != "anthropic" {
+			return fmt.Errorf("--account is only supported for openai and anthropic")
+		}
+		if strings.Contains(account, ":") {
+			return fmt.Errorf("invalid --account %q: must not contain ':'", account)
+		}
+	}
 	switch provider {
 	case "openai":
-		return authLoginOpenAI(useDeviceCode)
-	case "anthropic":
-		return authLoginPasteToken(provider)
+		return authLoginOpenAI(account, useDeviceCode)
+	case "anthropic", "clawhub":
+		return authLoginPasteToken(provider, account, totpSecret)
 	case "google-antigravity", "antigravity":
 		return authLoginGoogleAntigravity()
 	default:
@@ -30,7 +42,7 @@ func authLoginCmd(provider string, useDeviceCode bool) error {
 	}
 }
 
-func authLoginOpenAI(useDeviceCode bool) error {
+func authLoginOpenAI(account string, useDeviceCode bool) error {
 	cfg := auth.OpenAIOAuthConfig()
 
 	var cred *auth.AuthCredential
@@ -46,10 +58,23 @@ func authLoginOpenAI(useDeviceCode bool) error {
 		return fmt.Errorf("login failed: %w", err)
 	}
 
-	if err = auth.SetCredential("openai", cred); err != nil {
+	if err = auth.SetCredential(auth.CredentialKey("openai", account), cred); err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
+	if account != "" {
+		modelName := "gpt-5.2-" + account
+		if appCfg, err := internal.LoadConfig(); err == nil {
+			modelName = addAccountModel(appCfg, account, modelName, "openai/gpt-5.2", "oauth")
+			if err = config.SaveConfig(internal.GetConfigPath(), appCfg); err != nil {
+				return fmt.Errorf("could not update config: %w", err)
+			}
+		}
+		fmt.Printf("Login successful for openai account %q!\n", account)
+		fmt.Printf("Use it with model: %s\n", modelName)
+		return nil
+	}
+
 	appCfg, err := internal.LoadConfig()
 	if err == nil {
 		// Update Providers (legacy format)
@@ -58,7 +83,7 @@ func authLoginOpenAI(useDeviceCode bool) error {
 		// Update or add openai in ModelList
 		foundOpenAI := false
 		for i := range appCfg.ModelList {
-			if isOpenAIModel(appCfg.ModelList[i].Model) {
+			if isOpenAIModel(appCfg.ModelList[i].Model) && appCfg.ModelList[i].Account == "" {
 				appCfg.ModelList[i].AuthMethod = "oauth"
 				foundOpenAI = true
 				break
@@ -191,16 +216,46 @@ func fetchGoogleUserEmail(accessToken string) (string, error) {
 	return userInfo.Email, nil
 }
 
-func authLoginPasteToken(provider string) error {
+func authLoginPasteToken(provider, account, totpSecret string) error {
+	if totpSecret != "" {
+		if _, err := auth.GenerateTOTP(totpSecret); err != nil {
+			return fmt.Errorf("invalid --totp-secret: %w", err)
+		}
+	}
+
 	cred, err := auth.LoginPasteToken(provider, os.Stdin)
 	if err != nil {
 		return fmt.Errorf("login failed: %w", err)
 	}
+	cred.TOTPSecret = totpSecret
 
-	if err = auth.SetCredential(provider, cred); err != nil {
+	if provider == "anthropic" {
+		if err := verifyAnthropicToken(anthropicAPIBase, cred, promptOTPCode); err != nil {
+			return fmt.Errorf("login failed: %w", err)
+		}
+	}
+
+	key := auth.CredentialKey(provider, account)
+	if err = auth.SetCredential(key, cred); err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
 
+	if account != "" {
+		modelName, model := "claude-sonnet-4.6-"+account, "anthropic/claude-sonnet-4.6"
+		if provider == "openai" {
+			modelName, model = "gpt-5.2-"+account, "openai/gpt-5.2"
+		}
+		if appCfg, err := internal.LoadConfig(); err == nil {
+			modelName = addAccountModel(appCfg, account, modelName, model, "token")
+			if err := config.SaveConfig(internal.GetConfigPath(), appCfg); err != nil {
+				return fmt.Errorf("could not update config: %w", err)
+			}
+		}
+		fmt.Printf("Token saved for %s!\n", key)
+		fmt.Printf("Use it with model: %s\n", modelName)
+		return nil
+	}
+
 	appCfg, err := internal.LoadConfig()
 	if err == nil {
 		switch provider {
@@ -209,7 +264,7 @@ func authLoginPasteToken(provider string) error {
 			// Update ModelList
 			found := false
 			for i := range appCfg.ModelList {
-				if isAnthropicModel(appCfg.ModelList[i].Model) {
+				if isAnthropicModel(appCfg.ModelList[i].Model) && appCfg.ModelList[i].Account == "" {
 					appCfg.ModelList[i].AuthMethod = "token"
 					found = true
 					break
@@ -229,7 +284,7 @@ func authLoginPasteToken(provider string) error {
 			// Update ModelList
 			found := false
 			for i := range appCfg.ModelList {
-				if isOpenAIModel(appCfg.ModelList[i].Model) {
+				if isOpenAIModel(appCfg.ModelList[i].Model) && appCfg.ModelList[i].Account == "" {
 					appCfg.ModelList[i].AuthMethod = "token"
 					found = true
 					break
@@ -252,23 +307,107 @@ func authLoginPasteToken(provider string) error {
 
 	fmt.Printf("Token saved for %s!\n", provider)
 
-	if appCfg != nil {
+	if appCfg != nil && provider != "clawhub" {
 		fmt.Printf("Default model set to: %s\n", appCfg.Agents.Defaults.GetModelName())
 	}
 
 	return nil
 }
 
-func authLogoutCmd(provider string) error {
+// addAccountModel makes sure model_list has an entry that uses the named
+// account's credential and returns its model_name. The default model is
+// left alone, so logging in to a second account doesn't switch to it.
+func addAccountModel(cfg *config.Config, account, modelName, model, authMethod string) string {
+	protocol, _ := providers.ExtractProtocol(model)
+	for i := range cfg.ModelList {
+		mc := &cfg.ModelList[i]
+		if p, _ := providers.ExtractProtocol(mc.Model); p == protocol && mc.Account == account {
+			mc.AuthMethod = authMethod
+			return mc.ModelName
+		}
+	}
+	cfg.ModelList = append(cfg.ModelList, config.ModelConfig{
+		ModelName:  modelName,
+		Model:      model,
+		AuthMethod: authMethod,
+		Account:    account,
+	})
+	return modelName
+}
+
+const anthropicAPIBase = "https://api.anthropic.com"
+
+// verifyAnthropicToken checks the pasted token against the models endpoint.
+// Accounts with MFA answer with an OTP challenge, which is met with a code
+// from the stored TOTP secret or from prompt. Only a rejected one-time code
+// fails the login; other failures are reported and the token is kept, since
+// the endpoint may be unreachable or not accept every token type.
+func verifyAnthropicToken(apiBase string, cred *auth.AuthCredential, prompt func() (string, error)) error {
+	client := &http.Client{Timeout: 15 * time.Second}
+	newReq := func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, apiBase+"/v1/models", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+cred.AccessToken)
+		req.Header.Set("anthropic-version", "2023-06-01")
+		return req, nil
+	}
+
+	resp, err := auth.DoWithOTP(client, newReq, cred.TOTPSecret, prompt)
+	if err != nil {
+		fmt.Printf("Warning: could not verify token: %v\n", err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.Request.Header.Get(auth.OTPHeader) != "" && resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("one-time code was rejected")
+	default:
+		fmt.Printf("Warning: token check returned %s; saving it anyway\n", resp.Status)
+		return nil
+	}
+}
+
+func promptOTPCode() (string, error) {
+	fmt.Print("This account requires a one-time code. Enter the code from your authenticator app:\n> ")
+	var code string
+	if _, err := fmt.Fscanln(os.Stdin, &code); err != nil {
+		return "", fmt.Errorf("reading one-time code: %w", err)
+	}
+	return code, nil
+}
+
+func authLogoutCmd(provider, account string) error {
+	if account != "" && provider == "" {
+		return fmt.Errorf("--account requires --provider")
+	}
+
 	if provider != "" {
-		if err := auth.DeleteCredential(provider); err != nil {
+		key := auth.CredentialKey(provider, account)
+		if err := auth.DeleteCredential(key); err != nil {
 			return fmt.Errorf("failed to remove credentials: %w", err)
 		}
 
 		appCfg, err := internal.LoadConfig()
-		if err == nil {
+		if err == nil && account != "" {
+			// Only the models bound to this account lose their credential
+			for i := range appCfg.ModelList {
+				if p, _ := providers.ExtractProtocol(appCfg.ModelList[i].Model); p == provider &&
+					appCfg.ModelList[i].Account == account {
+					appCfg.ModelList[i].AuthMethod = ""
+				}
+			}
+			config.SaveConfig(internal.GetConfigPath(), appCfg)
+		} else if err == nil {
 			// Clear AuthMethod in ModelList
 			for i := range appCfg.ModelList {
+				if appCfg.ModelList[i].Account != "" {
+					continue
+				}
 				switch provider {
 				case "openai":
 					if isOpenAIModel(appCfg.ModelList[i].Model) {
@@ -296,7 +435,7 @@ func authLogoutCmd(provider string) error {
 			config.SaveConfig(internal.GetConfigPath(), appCfg)
 		}
 
-		fmt.Printf("Logged out from %s\n", provider)
+		fmt.Printf("Logged out from %s\n", key)
 
 		return nil
 	}
@@ -329,15 +468,29 @@ func authStatusCmd() error {
 		return fmt.Errorf("failed to load auth store: %w", err)
 	}
 
+	if store.RecoveredFrom != "" {
+		fmt.Println("⚠ auth.json was corrupt and has been reset to an empty store.")
+		fmt.Printf("  The unreadable file was kept at %s\n\n", store.RecoveredFrom)
+	} else if backups := auth.CorruptBackups(); len(backups) > 0 && len(store.Credentials) == 0 {
+		fmt.Printf("⚠ A corrupt auth.json was reset earlier (backup: %s).\n\n", backups[0])
+	}
+
 	if len(store.Credentials) == 0 {
 		fmt.Println("No authenticated providers.")
 		fmt.Println("Run: picoclaw auth login --provider <name>")
 		return nil
 	}
 
+	keys := make([]string, 0, len(store.Credentials))
+	for key := range store.Credentials {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	fmt.Println("\nAuthenticated Providers:")
 	fmt.Println("------------------------")
-	for provider, cred := range store.Credentials {
+	for _, key := range keys {
+		cred := store.Credentials[key]
 		status := "active"
 		if cred.IsExpired() {
 			status = "expired"
@@ -345,7 +498,11 @@ func authStatusCmd() error {
 			status = "needs refresh"
 		}
 
-		fmt.Printf("  %s:\n", provider)
+		provider, account := auth.SplitCredentialKey(key)
+		if account == "" {
+			account = "default"
+		}
+		fmt.Printf("  %s (%s):\n", provider, account)
 		fmt.Printf("    Method: %s\n", cred.AuthMethod)
 		fmt.Printf("    Status: %s\n", status)
 		if cred.AccountID != "" {
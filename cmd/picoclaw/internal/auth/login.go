@@ -5,7 +5,9 @@ import "github.com/spf13/cobra"
 func newLoginCommand() *cobra.Command {
 	var (
 		provider      string
+		account       string
 		useDeviceCode bool
+		totpSecret    string
 	)
 
 	cmd := &cobra.Command{
@@ -13,12 +15,14 @@ func newLoginCommand() *cobra.Command {
 		Short: "Login via OAuth or paste token",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return authLoginCmd(provider, useDeviceCode)
+			return authLoginCmd(provider, account, useDeviceCode, totpSecret)
 		},
 	}
 
-	cmd.Flags().StringVarP(&provider, "provider", "p", "", "Provider to login with (openai, anthropic)")
+	cmd.Flags().StringVarP(&provider, "provider", "p", "", "Provider to login with (openai, anthropic, clawhub)")
+	cmd.Flags().StringVarP(&account, "account", "a", "", "Name for a second account, e.g. work (openai, anthropic)")
 	cmd.Flags().BoolVar(&useDeviceCode, "device-code", false, "Use device code flow (for headless environments)")
+	cmd.Flags().StringVar(&totpSecret, "totp-secret", "", "Base32 TOTP seed for accounts with MFA (anthropic)")
 	_ = cmd.MarkFlagRequired("provider")
 
 	return cmd
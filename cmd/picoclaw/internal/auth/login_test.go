@@ -1,11 +1,16 @@
 package auth
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/auth"
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 func TestNewLoginSubCommand(t *testing.T) {
@@ -18,6 +23,8 @@ func TestNewLoginSubCommand(t *testing.T) {
 	assert.True(t, cmd.HasFlags())
 
 	assert.NotNil(t, cmd.Flags().Lookup("device-code"))
+	assert.NotNil(t, cmd.Flags().Lookup("totp-secret"))
+	assert.NotNil(t, cmd.Flags().Lookup("account"))
 
 	providerFlag := cmd.Flags().Lookup("provider")
 	require.NotNil(t, providerFlag)
@@ -27,3 +34,47 @@ func TestNewLoginSubCommand(t *testing.T) {
 	require.NotEmpty(t, val)
 	assert.Equal(t, "true", val[0])
 }
+
+func TestVerifyAnthropicTokenAnswersOTPChallenge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get(auth.OTPHeader) {
+		case "":
+			w.Header().Set(auth.OTPHeader, "required")
+			w.WriteHeader(http.StatusUnauthorized)
+		case "654321":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	cred := &auth.AuthCredential{AccessToken: "tok"}
+	prompt := func(code string) func() (string, error) {
+		return func() (string, error) { return code, nil }
+	}
+
+	require.NoError(t, verifyAnthropicToken(server.URL, cred, prompt("654321")))
+	assert.EqualError(t, verifyAnthropicToken(server.URL, cred, prompt("000000")), "one-time code was rejected")
+}
+
+func TestAddAccountModel(t *testing.T) {
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{Defaults: config.AgentDefaults{ModelName: "gpt-5.2"}},
+		ModelList: []config.ModelConfig{
+			{ModelName: "gpt-5.2", Model: "openai/gpt-5.2", AuthMethod: "oauth"},
+		},
+	}
+
+	name := addAccountModel(cfg, "work", "gpt-5.2-work", "openai/gpt-5.2", "oauth")
+	assert.Equal(t, "gpt-5.2-work", name)
+	require.Len(t, cfg.ModelList, 2)
+	assert.Equal(t, "work", cfg.ModelList[1].Account)
+	assert.Equal(t, "gpt-5.2", cfg.Agents.Defaults.ModelName, "default model must not change")
+
+	// Logging in again reuses the existing entry, even if it was renamed.
+	cfg.ModelList[1].ModelName = "work-gpt"
+	assert.Equal(t, "work-gpt", addAccountModel(cfg, "work", "gpt-5.2-work", "openai/gpt-5.2", "token"))
+	require.Len(t, cfg.ModelList, 2)
+	assert.Equal(t, "token", cfg.ModelList[1].AuthMethod)
+}
@@ -3,18 +3,19 @@ package auth
 import "github.com/spf13/cobra"
 
 func newLogoutCommand() *cobra.Command {
-	var provider string
+	var provider, account string
 
 	cmd := &cobra.Command{
 		Use:   "logout",
 		Short: "Remove stored credentials",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return authLogoutCmd(provider)
+			return authLogoutCmd(provider, account)
 		},
 	}
 
 	cmd.Flags().StringVarP(&provider, "provider", "p", "", "Provider to logout from (openai, anthropic); empty = all")
+	cmd.Flags().StringVarP(&account, "account", "a", "", "Named account to logout from (requires --provider)")
 
 	return cmd
 }
@@ -17,4 +17,5 @@ func TestNewLogoutSubcommand(t *testing.T) {
 	assert.True(t, cmd.HasFlags())
 
 	assert.NotNil(t, cmd.Flags().Lookup("provider"))
+	assert.NotNil(t, cmd.Flags().Lookup("account"))
 }
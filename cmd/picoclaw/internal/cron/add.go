@@ -5,28 +5,97 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/cron"
 )
 
-func newAddCommand(storePath func() string) *cobra.Command {
+func newAddCommand(storePath func() string, presets func() []config.CronPreset) *cobra.Command {
 	var (
+		preset  string
 		name    string
 		message string
 		every   int64
 		cronExp string
+		jitter  int
 		deliver bool
 		channel string
 		to      string
+		digest  bool
+		urgent  bool
+
+		withContext bool
+
+		runAsChannel string
+		runAsChatID  string
+
+		webhook         string
+		webhookMethod   string
+		webhookTemplate string
+		webhookSecret   string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "add",
 		Short: "Add a new scheduled job",
 		Args:  cobra.NoArgs,
+		Example: `picoclaw cron add --name backup --cron "0 3 * * *" --message "Back up my notes"
+picoclaw cron add --preset daily-digest
+picoclaw cron add --preset hourly-check --every 7200
+picoclaw cron add --name news --cron "0 9 * * *" --message "Summarize the news" --jitter 300
+picoclaw cron add --name nightly --cron "0 2 * * *" --message "Run the checks" --webhook https://ci.example.com/hook --webhook-secret s3cret`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if preset != "" {
+				p, err := cron.LookupPreset(preset, presets())
+				if err != nil {
+					return err
+				}
+				// Flags given explicitly win over the preset.
+				flags := cmd.Flags()
+				if !flags.Changed("name") {
+					name = p.JobName
+					if name == "" {
+						name = p.Name
+					}
+				}
+				if !flags.Changed("message") {
+					message = p.Message
+				}
+				if !flags.Changed("every") && !flags.Changed("cron") {
+					every, cronExp = p.Every, p.Cron
+				}
+				if !flags.Changed("deliver") {
+					deliver = p.Deliver
+				}
+				if !flags.Changed("digest") && !flags.Changed("urgent") {
+					digest = p.Digest
+				}
+			}
+			if name == "" || message == "" {
+				return fmt.Errorf("--name and --message are required unless --preset provides them")
+			}
 			if every <= 0 && cronExp == "" {
 				return fmt.Errorf("either --every or --cron must be specified")
 			}
+			if jitter < 0 {
+				return fmt.Errorf("--jitter must not be negative")
+			}
+			if (runAsChannel == "") != (runAsChatID == "") {
+				return fmt.Errorf("--run-as-channel and --run-as-chat-id must be used together")
+			}
+			var notification *cron.Notification
+			if webhook != "" {
+				notification = &cron.Notification{
+					WebhookURL: webhook,
+					Method:     webhookMethod,
+					Template:   webhookTemplate,
+					Secret:     webhookSecret,
+				}
+				if err := notification.Validate(); err != nil {
+					return err
+				}
+			} else if webhookMethod != "" || webhookTemplate != "" || webhookSecret != "" {
+				return fmt.Errorf("--webhook-method, --webhook-template and --webhook-secret require --webhook")
+			}
 
 			var schedule cron.CronSchedule
 			if every > 0 {
@@ -35,6 +104,7 @@ func newAddCommand(storePath func() string) *cobra.Command {
 			} else {
 				schedule = cron.CronSchedule{Kind: "cron", Expr: cronExp}
 			}
+			schedule.JitterSeconds = jitter
 
 			cs := cron.NewCronService(storePath(), nil)
 			job, err := cs.AddJob(name, schedule, message, deliver, channel, to)
@@ -42,23 +112,48 @@ func newAddCommand(storePath func() string) *cobra.Command {
 				return fmt.Errorf("error adding job: %w", err)
 			}
 
+			if digest || urgent || withContext || runAsChannel != "" || notification != nil {
+				job.Payload.Digest = digest
+				job.Payload.Urgent = urgent
+				job.Payload.WithContext = withContext
+				if runAsChannel != "" {
+					job.RunAs = &cron.RunAsContact{Channel: runAsChannel, ChatID: runAsChatID}
+				}
+				job.Notification = notification
+				if err := cs.UpdateJob(job); err != nil {
+					return fmt.Errorf("error saving job: %w", err)
+				}
+			}
+
 			fmt.Printf("✓ Added job '%s' (%s)\n", job.Name, job.ID)
 
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVarP(&preset, "preset", "p", "", "Start from a preset (see 'picoclaw cron presets')")
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Job name")
 	cmd.Flags().StringVarP(&message, "message", "m", "", "Message for agent")
 	cmd.Flags().Int64VarP(&every, "every", "e", 0, "Run every N seconds")
 	cmd.Flags().StringVarP(&cronExp, "cron", "c", "", "Cron expression (e.g. '0 9 * * *')")
+	cmd.Flags().IntVar(&jitter, "jitter", 0, "Delay each run by a random amount of up to N seconds")
 	cmd.Flags().BoolVarP(&deliver, "deliver", "d", false, "Deliver response to channel")
 	cmd.Flags().StringVar(&to, "to", "", "Recipient for delivery")
 	cmd.Flags().StringVar(&channel, "channel", "", "Channel for delivery")
+	cmd.Flags().BoolVar(&digest, "digest", false, "Collect output into the gateway digest instead of sending it")
+	cmd.Flags().BoolVar(&urgent, "urgent", false, "Always deliver immediately, bypassing the digest")
+	cmd.Flags().BoolVar(&withContext, "with-context", false, "Give the agent a summary of the owner's recent conversation")
+	cmd.Flags().StringVar(&runAsChannel, "run-as-channel", "", "Run the job in this channel's user context")
+	cmd.Flags().StringVar(&runAsChatID, "run-as-chat-id", "", "Chat ID of the user the job runs as")
+
+	cmd.Flags().StringVar(&webhook, "webhook", "", "URL to call after every run")
+	cmd.Flags().StringVar(&webhookMethod, "webhook-method", "", "HTTP method of the webhook call (default POST)")
+	cmd.Flags().StringVar(&webhookTemplate, "webhook-template", "",
+		"Go template for the webhook body, e.g. '{\"status\":{{json .Status}}}' (default: the run as JSON)")
+	cmd.Flags().StringVar(&webhookSecret, "webhook-secret", "", "Sign the webhook body with HMAC-SHA256 using this secret")
 
-	_ = cmd.MarkFlagRequired("name")
-	_ = cmd.MarkFlagRequired("message")
 	cmd.MarkFlagsMutuallyExclusive("every", "cron")
+	cmd.MarkFlagsMutuallyExclusive("digest", "urgent")
 
 	return cmd
 }
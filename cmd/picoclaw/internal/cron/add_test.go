@@ -1,16 +1,19 @@
 package cron
 
 import (
+	"path/filepath"
 	"testing"
 
-	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
 )
 
 func TestNewAddSubcommand(t *testing.T) {
 	fn := func() string { return "" }
-	cmd := newAddCommand(fn)
+	cmd := newAddCommand(fn, noPresets)
 
 	require.NotNil(t, cmd)
 
@@ -21,29 +24,68 @@ func TestNewAddSubcommand(t *testing.T) {
 
 	assert.NotNil(t, cmd.Flags().Lookup("every"))
 	assert.NotNil(t, cmd.Flags().Lookup("cron"))
+	assert.NotNil(t, cmd.Flags().Lookup("jitter"))
 	assert.NotNil(t, cmd.Flags().Lookup("deliver"))
 	assert.NotNil(t, cmd.Flags().Lookup("to"))
 	assert.NotNil(t, cmd.Flags().Lookup("channel"))
+	assert.NotNil(t, cmd.Flags().Lookup("digest"))
+	assert.NotNil(t, cmd.Flags().Lookup("urgent"))
+	assert.NotNil(t, cmd.Flags().Lookup("with-context"))
+	assert.NotNil(t, cmd.Flags().Lookup("run-as-channel"))
+	assert.NotNil(t, cmd.Flags().Lookup("run-as-chat-id"))
+	assert.NotNil(t, cmd.Flags().Lookup("webhook"))
+	assert.NotNil(t, cmd.Flags().Lookup("webhook-secret"))
 
-	nameFlag := cmd.Flags().Lookup("name")
-	require.NotNil(t, nameFlag)
+	assert.NotNil(t, cmd.Flags().Lookup("preset"))
+	assert.NotNil(t, cmd.Flags().Lookup("name"))
+	assert.NotNil(t, cmd.Flags().Lookup("message"))
+	assert.True(t, cmd.HasExample())
+}
 
-	messageFlag := cmd.Flags().Lookup("message")
-	require.NotNil(t, messageFlag)
+func noPresets() []config.CronPreset { return nil }
 
-	val, found := nameFlag.Annotations[cobra.BashCompOneRequiredFlag]
-	require.True(t, found)
-	require.NotEmpty(t, val)
-	assert.Equal(t, "true", val[0])
+func TestNewAddCommandRequiresNameAndMessageWithoutPreset(t *testing.T) {
+	cmd := newAddCommand(func() string { return filepath.Join(t.TempDir(), "jobs.json") }, noPresets)
+	cmd.SetArgs([]string{"--every", "10"})
 
-	val, found = messageFlag.Annotations[cobra.BashCompOneRequiredFlag]
-	require.True(t, found)
-	require.NotEmpty(t, val)
-	assert.Equal(t, "true", val[0])
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--preset")
+}
+
+func TestNewAddCommandPresetWithOverrides(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	user := []config.CronPreset{{Name: "team-sync", Message: "Post the sync agenda", Cron: "0 10 * * 1"}}
+	cmd := newAddCommand(func() string { return storePath }, func() []config.CronPreset { return user })
+	cmd.SetArgs([]string{"--preset", "team-sync", "--name", "Sync", "--every", "60"})
+	require.NoError(t, cmd.Execute())
+
+	cmd = newAddCommand(func() string { return storePath }, noPresets)
+	cmd.SetArgs([]string{"--preset", "hourly-check"})
+	require.NoError(t, cmd.Execute())
+
+	jobs := cron.NewCronService(storePath, nil).ListJobs(true)
+	require.Len(t, jobs, 2)
+
+	assert.Equal(t, "Sync", jobs[0].Name)
+	assert.Equal(t, "Post the sync agenda", jobs[0].Payload.Message)
+	assert.Equal(t, "every", jobs[0].Schedule.Kind)
+	require.NotNil(t, jobs[0].Schedule.EveryMS)
+	assert.Equal(t, int64(60000), *jobs[0].Schedule.EveryMS)
+
+	assert.Equal(t, "Hourly check", jobs[1].Name)
+	assert.True(t, jobs[1].Payload.Digest)
+}
+
+func TestNewAddCommandUnknownPreset(t *testing.T) {
+	cmd := newAddCommand(func() string { return "testing" }, noPresets)
+	cmd.SetArgs([]string{"--preset", "nope"})
+
+	require.ErrorContains(t, cmd.Execute(), "unknown preset")
 }
 
 func TestNewAddCommandEveryAndCronMutuallyExclusive(t *testing.T) {
-	cmd := newAddCommand(func() string { return "testing" })
+	cmd := newAddCommand(func() string { return "testing" }, noPresets)
 
 	cmd.SetArgs([]string{
 		"--name", "job",
@@ -55,3 +97,40 @@ func TestNewAddCommandEveryAndCronMutuallyExclusive(t *testing.T) {
 	err := cmd.Execute()
 	require.Error(t, err)
 }
+
+func TestNewAddCommandRunAsRequiresBothFlags(t *testing.T) {
+	cmd := newAddCommand(func() string { return "testing" }, noPresets)
+
+	cmd.SetArgs([]string{
+		"--name", "job",
+		"--message", "hello",
+		"--every", "10",
+		"--run-as-channel", "line",
+	})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+}
+
+func TestNewAddCommandWebhook(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "jobs.json")
+	cmd := newAddCommand(func() string { return storePath }, noPresets)
+	cmd.SetArgs([]string{
+		"--name", "job",
+		"--message", "hello",
+		"--every", "10",
+		"--webhook", "https://ci.example.com/hook",
+		"--webhook-secret", "s3cret",
+	})
+	require.NoError(t, cmd.Execute())
+
+	jobs := cron.NewCronService(storePath, nil).ListJobs(true)
+	require.Len(t, jobs, 1)
+	require.NotNil(t, jobs[0].Notification)
+	assert.Equal(t, "https://ci.example.com/hook", jobs[0].Notification.WebhookURL)
+	assert.Equal(t, "s3cret", jobs[0].Notification.Secret)
+
+	cmd = newAddCommand(func() string { return storePath }, noPresets)
+	cmd.SetArgs([]string{"--name", "job", "--message", "hello", "--every", "10", "--webhook-secret", "s3cret"})
+	require.ErrorContains(t, cmd.Execute(), "require --webhook")
+}
@@ -7,10 +7,14 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 func NewCronCommand() *cobra.Command {
-	var storePath string
+	var (
+		storePath string
+		presets   []config.CronPreset
+	)
 
 	cmd := &cobra.Command{
 		Use:     "cron",
@@ -28,16 +32,21 @@ func NewCronCommand() *cobra.Command {
 				return fmt.Errorf("error loading config: %w", err)
 			}
 			storePath = filepath.Join(cfg.WorkspacePath(), "cron", "jobs.json")
+			presets = cfg.Tools.Cron.Presets
 			return nil
 		},
 	}
 
 	cmd.AddCommand(
 		newListCommand(func() string { return storePath }),
-		newAddCommand(func() string { return storePath }),
+		newAddCommand(func() string { return storePath }, func() []config.CronPreset { return presets }),
 		newRemoveCommand(func() string { return storePath }),
 		newEnableCommand(func() string { return storePath }),
 		newDisableCommand(func() string { return storePath }),
+		newPauseAllCommand(func() string { return storePath }),
+		newResumeAllCommand(func() string { return storePath }),
+		newHistoryCommand(func() string { return storePath }),
+		newPresetsCommand(func() []config.CronPreset { return presets }),
 	)
 
 	return cmd
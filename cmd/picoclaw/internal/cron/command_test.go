@@ -35,6 +35,10 @@ func TestNewCronCommand(t *testing.T) {
 		"remove",
 		"enable",
 		"disable",
+		"pause-all",
+		"resume-all",
+		"history",
+		"presets",
 	}
 
 	subcommands := cmd.Commands()
@@ -2,36 +2,97 @@ package cron
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
 	"time"
 
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
-func cronListCmd(storePath string) {
+func cronListCmd(w io.Writer, storePath, format string) error {
 	cs := cron.NewCronService(storePath, nil)
 	jobs := cs.ListJobs(true) // Show all jobs, including disabled
 
+	switch format {
+	case internal.FormatJSON:
+		if jobs == nil {
+			jobs = []cron.CronJob{}
+		}
+		return internal.WriteJSON(w, jobs)
+	case internal.FormatCSV:
+		header := []string{
+			"id", "name", "enabled", "schedule", "next_run", "last_run", "last_status",
+			"channel", "to", "message", "created_by",
+		}
+		rows := make([][]string, 0, len(jobs))
+		for _, job := range jobs {
+			rows = append(rows, []string{
+				job.ID,
+				job.Name,
+				strconv.FormatBool(job.Enabled),
+				describeSchedule(job.Schedule),
+				formatMS(job.State.NextRunAtMS),
+				formatMS(job.State.LastRunAtMS),
+				job.State.LastStatus,
+				job.Payload.Channel,
+				job.Payload.To,
+				job.Payload.Message,
+				job.CreatedBy,
+			})
+		}
+		return internal.WriteCSV(w, header, rows)
+	}
+
+	if cs.IsPaused() {
+		fmt.Fprintln(w, "⚠ All jobs are paused. Run 'picoclaw cron resume-all' to resume.")
+	}
+
 	if len(jobs) == 0 {
-		fmt.Println("No scheduled jobs.")
-		return
+		fmt.Fprintln(w, "No scheduled jobs.")
+		return nil
 	}
 
-	fmt.Println("\nScheduled Jobs:")
-	fmt.Println("----------------")
+	var userJobs, agentJobs []cron.CronJob
 	for _, job := range jobs {
-		var schedule string
-		if job.Schedule.Kind == "every" && job.Schedule.EveryMS != nil {
-			schedule = fmt.Sprintf("every %ds", *job.Schedule.EveryMS/1000)
-		} else if job.Schedule.Kind == "cron" {
-			schedule = job.Schedule.Expr
+		if job.ByAgent() {
+			agentJobs = append(agentJobs, job)
 		} else {
-			schedule = "one-time"
+			userJobs = append(userJobs, job)
+		}
+	}
+	if len(userJobs) > 0 {
+		fmt.Fprintln(w, "\nScheduled Jobs:")
+		fmt.Fprintln(w, "----------------")
+		writeJobs(w, userJobs)
+	}
+	if len(agentJobs) > 0 {
+		fmt.Fprintln(w, "\nScheduled by the Agent:")
+		fmt.Fprintln(w, "-----------------------")
+		writeJobs(w, agentJobs)
+	}
+	return nil
+}
+
+// writeJobs prints the details of jobs for cron list.
+func writeJobs(w io.Writer, jobs []cron.CronJob) {
+	for _, job := range jobs {
+		schedule := describeSchedule(job.Schedule)
+		if job.Schedule.JitterSeconds > 0 {
+			schedule += fmt.Sprintf(" (jitter up to %ds)", job.Schedule.JitterSeconds)
 		}
 
 		nextRun := "scheduled"
 		if job.State.NextRunAtMS != nil {
 			nextTime := time.UnixMilli(*job.State.NextRunAtMS)
 			nextRun = nextTime.Format("2006-01-02 15:04")
+			if runAt := job.State.RunAtMS; runAt != nil && *runAt != *job.State.NextRunAtMS {
+				nextRun += fmt.Sprintf(" (starts %s)", time.UnixMilli(*runAt).Format("15:04:05"))
+			}
 		}
 
 		status := "enabled"
@@ -39,13 +100,78 @@ func cronListCmd(storePath string) {
 			status = "disabled"
 		}
 
-		fmt.Printf("  %s (%s)\n", job.Name, job.ID)
-		fmt.Printf("    Schedule: %s\n", schedule)
-		fmt.Printf("    Status: %s\n", status)
-		fmt.Printf("    Next run: %s\n", nextRun)
+		fmt.Fprintf(w, "  %s (%s)\n", job.Name, job.ID)
+		fmt.Fprintf(w, "    Schedule: %s\n", schedule)
+		fmt.Fprintf(w, "    Status: %s\n", status)
+		if job.RunAs != nil {
+			fmt.Fprintf(w, "    Runs as: %s:%s\n", job.RunAs.Channel, job.RunAs.ChatID)
+		}
+		if job.Notification != nil {
+			fmt.Fprintf(w, "    Webhook: %s\n", job.Notification.WebhookURL)
+		}
+		fmt.Fprintf(w, "    Next run: %s\n", nextRun)
 	}
 }
 
+// describeSchedule renders a job's schedule as "every 60s", its cron
+// expression or "one-time".
+func describeSchedule(s cron.CronSchedule) string {
+	switch {
+	case s.Kind == "every" && s.EveryMS != nil:
+		return fmt.Sprintf("every %ds", *s.EveryMS/1000)
+	case s.Kind == "cron":
+		return s.Expr
+	default:
+		return "one-time"
+	}
+}
+
+// formatMS renders a millisecond timestamp as RFC 3339, or "" when unset.
+func formatMS(ms *int64) string {
+	if ms == nil {
+		return ""
+	}
+	return time.UnixMilli(*ms).Format(time.RFC3339)
+}
+
+func cronHistoryCmd(storePath, jobID string) error {
+	cs := cron.NewCronService(storePath, nil)
+	job := cs.GetJob(jobID)
+	if job == nil {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+
+	fmt.Printf("\nRun history for %s (%s):\n", job.Name, job.ID)
+	if len(job.State.History) == 0 {
+		fmt.Println("  No runs recorded yet.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TIME\tSCHEDULED\tSTATUS\tDELIVERY\tDETAIL")
+	for i := len(job.State.History) - 1; i >= 0; i-- {
+		run := job.State.History[i]
+		delivery := run.Delivery
+		if delivery == "" {
+			delivery = "-"
+		}
+		detail := run.Error
+		if run.DeliveryError != "" {
+			if detail != "" {
+				detail += "; "
+			}
+			detail += "delivery: " + run.DeliveryError
+		}
+		scheduled := "-"
+		if run.ScheduledAtMS > 0 {
+			scheduled = time.UnixMilli(run.ScheduledAtMS).Format("15:04:05")
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+			time.UnixMilli(run.AtMS).Format("2006-01-02 15:04:05"), scheduled, run.Status, delivery, detail)
+	}
+	return w.Flush()
+}
+
 func cronRemoveCmd(storePath, jobID string) {
 	cs := cron.NewCronService(storePath, nil)
 	if cs.RemoveJob(jobID) {
@@ -64,3 +190,38 @@ func cronSetJobEnabled(storePath, jobID string, enabled bool) {
 		fmt.Printf("✗ Job %s not found\n", jobID)
 	}
 }
+
+func cronSetPaused(storePath string, paused bool) error {
+	cs := cron.NewCronService(storePath, nil)
+	if paused {
+		if err := cs.PauseAll(); err != nil {
+			return fmt.Errorf("error pausing jobs: %w", err)
+		}
+		fmt.Println("✓ All jobs paused")
+		return nil
+	}
+
+	if err := cs.ResumeAll(); err != nil {
+		return fmt.Errorf("error resuming jobs: %w", err)
+	}
+	fmt.Println("✓ All jobs resumed")
+	return nil
+}
+
+func cronPresetsCmd(user []config.CronPreset) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PRESET\tSCHEDULE\tDESCRIPTION")
+	for _, p := range cron.Presets(user) {
+		schedule := p.Cron
+		if p.Every > 0 {
+			schedule = fmt.Sprintf("every %ds", p.Every)
+		}
+		description := p.Description
+		if description == "" {
+			description = utils.Truncate(p.Message, 60)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, schedule, description)
+	}
+	w.Flush()
+	fmt.Println("\nUse with: picoclaw cron add --preset <name> [flags to override]")
+}
@@ -0,0 +1,17 @@
+package cron
+
+import "github.com/spf13/cobra"
+
+func newHistoryCommand(storePath func() string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   "Show recent runs of a job",
+		Args:    cobra.ExactArgs(1),
+		Example: `picoclaw cron history 1`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return cronHistoryCmd(storePath(), args[0])
+		},
+	}
+
+	return cmd
+}
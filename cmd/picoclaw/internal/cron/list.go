@@ -1,17 +1,30 @@
 package cron
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
 
 func newListCommand(storePath func() string) *cobra.Command {
+	var format string
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all scheduled jobs",
 		Args:  cobra.NoArgs,
-		RunE: func(_ *cobra.Command, _ []string) error {
-			cronListCmd(storePath())
-			return nil
+		Example: `picoclaw cron list
+picoclaw cron list --format json | jq '.[] | select(.enabled) | .name'
+picoclaw cron list --format csv > jobs.csv`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := internal.CheckFormat(format); err != nil {
+				return err
+			}
+			return cronListCmd(cmd.OutOrStdout(), storePath(), format)
 		},
 	}
 
+	internal.AddFormatFlag(cmd, &format)
+
 	return cmd
 }
@@ -1,10 +1,17 @@
 package cron
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/cron"
 )
 
 func TestNewListSubcommand(t *testing.T) {
@@ -14,4 +21,79 @@ func TestNewListSubcommand(t *testing.T) {
 	require.NotNil(t, cmd)
 
 	assert.Equal(t, "List all scheduled jobs", cmd.Short)
+	assert.True(t, cmd.HasExample())
+	assert.NotNil(t, cmd.Flags().Lookup("format"))
+}
+
+func TestListCommandRejectsUnknownFormat(t *testing.T) {
+	cmd := newListCommand(func() string { return filepath.Join(t.TempDir(), "jobs.json") })
+	cmd.SetArgs([]string{"--format", "yaml"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "use table, json or csv")
+}
+
+func TestCronListFormats(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	every := int64(3600000)
+	cs := cron.NewCronService(storePath, nil)
+	_, err := cs.AddJob("backup, nightly", cron.CronSchedule{Kind: "cron", Expr: "0 3 * * *"}, "run backup", true, "telegram", "42")
+	require.NoError(t, err)
+	_, err = cs.AddJob("ping", cron.CronSchedule{Kind: "every", EveryMS: &every}, "ping", false, "", "")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cronListCmd(&out, storePath, "json"))
+	var jobs []cron.CronJob
+	require.NoError(t, json.Unmarshal(out.Bytes(), &jobs))
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "0 3 * * *", jobs[0].Schedule.Expr)
+	assert.Equal(t, "42", jobs[0].Payload.To)
+
+	out.Reset()
+	require.NoError(t, cronListCmd(&out, storePath, "csv"))
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "id", records[0][0])
+	assert.Equal(t, []string{"backup, nightly", "true", "0 3 * * *"}, records[1][1:4])
+	assert.Equal(t, "every 3600s", records[2][3])
+
+	out.Reset()
+	require.NoError(t, cronListCmd(&out, storePath, "table"))
+	assert.Contains(t, out.String(), "Schedule: 0 3 * * *")
+	assert.NotContains(t, out.String(), "Scheduled by the Agent")
+}
+
+func TestCronListSeparatesAgentJobs(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	cs := cron.NewCronService(storePath, nil)
+	_, err := cs.AddJob("backup", cron.CronSchedule{Kind: "cron", Expr: "0 3 * * *"}, "run backup", true, "telegram", "42")
+	require.NoError(t, err)
+	job, err := cs.AddJob("agent: call mum", cron.CronSchedule{Kind: "cron", Expr: "0 9 * * *"}, "call mum", true, "telegram", "42")
+	require.NoError(t, err)
+	job.CreatedBy = cron.CreatedByAgent
+	require.NoError(t, cs.UpdateJob(job))
+
+	var out bytes.Buffer
+	require.NoError(t, cronListCmd(&out, storePath, "table"))
+	_, agentPart, ok := strings.Cut(out.String(), "Scheduled by the Agent:")
+	require.True(t, ok, out.String())
+	assert.Contains(t, agentPart, "agent: call mum")
+	assert.NotContains(t, agentPart, "backup")
+
+	out.Reset()
+	require.NoError(t, cronListCmd(&out, storePath, "csv"))
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, "created_by", records[0][10])
+	assert.Equal(t, []string{"", "agent"}, []string{records[1][10], records[2][10]})
+}
+
+func TestCronListEmptyJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, cronListCmd(&out, filepath.Join(t.TempDir(), "jobs.json"), "json"))
+	assert.Equal(t, "[]\n", out.String())
 }
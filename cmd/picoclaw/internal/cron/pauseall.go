@@ -0,0 +1,15 @@
+package cron
+
+import "github.com/spf13/cobra"
+
+func newPauseAllCommand(storePath func() string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "pause-all",
+		Short:   "Pause all jobs (e.g. for a maintenance window)",
+		Args:    cobra.NoArgs,
+		Example: `picoclaw cron pause-all`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cronSetPaused(storePath(), true)
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseAllSubcommand(t *testing.T) {
+	fn := func() string { return "" }
+	cmd := newPauseAllCommand(fn)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "pause-all", cmd.Use)
+	assert.Equal(t, "Pause all jobs (e.g. for a maintenance window)", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+}
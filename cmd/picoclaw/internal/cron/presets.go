@@ -0,0 +1,22 @@
+package cron
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newPresetsCommand(presets func() []config.CronPreset) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "presets",
+		Short:   "List job presets for 'cron add --preset'",
+		Args:    cobra.NoArgs,
+		Example: `picoclaw cron presets`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cronPresetsCmd(presets())
+			return nil
+		},
+	}
+
+	return cmd
+}
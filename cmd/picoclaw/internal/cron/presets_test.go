@@ -0,0 +1,18 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPresetsSubcommand(t *testing.T) {
+	cmd := newPresetsCommand(noPresets)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "List job presets for 'cron add --preset'", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+}
@@ -0,0 +1,15 @@
+package cron
+
+import "github.com/spf13/cobra"
+
+func newResumeAllCommand(storePath func() string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "resume-all",
+		Short:   "Resume all jobs after pause-all",
+		Args:    cobra.NoArgs,
+		Example: `picoclaw cron resume-all`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return cronSetPaused(storePath(), false)
+		},
+	}
+}
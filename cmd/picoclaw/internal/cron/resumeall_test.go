@@ -0,0 +1,20 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResumeAllSubcommand(t *testing.T) {
+	fn := func() string { return "" }
+	cmd := newResumeAllCommand(fn)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "resume-all", cmd.Use)
+	assert.Equal(t, "Resume all jobs after pause-all", cmd.Short)
+
+	assert.True(t, cmd.HasExample())
+}
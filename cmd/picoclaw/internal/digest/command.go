@@ -0,0 +1,36 @@
+package digest
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+)
+
+func NewDigestCommand() *cobra.Command {
+	var workspace string
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Manage the batched output digest",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			workspace = cfg.WorkspacePath()
+			return nil
+		},
+	}
+
+	cmd.AddCommand(
+		newFlushCommand(func() string { return workspace }),
+	)
+
+	return cmd
+}
@@ -0,0 +1,27 @@
+package digest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDigestCommand(t *testing.T) {
+	cmd := NewDigestCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "digest", cmd.Use)
+	assert.Equal(t, "Manage the batched output digest", cmd.Short)
+
+	assert.False(t, cmd.HasFlags())
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+	assert.NotNil(t, cmd.PersistentPreRunE)
+
+	assert.True(t, cmd.HasSubCommands())
+	assert.Len(t, cmd.Commands(), 1)
+	assert.Equal(t, "flush", cmd.Commands()[0].Name())
+}
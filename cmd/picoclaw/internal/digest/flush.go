@@ -0,0 +1,52 @@
+package digest
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/digest"
+)
+
+func newFlushCommand(workspace func() string) *cobra.Command {
+	var stdout bool
+
+	cmd := &cobra.Command{
+		Use:   "flush",
+		Short: "Deliver the pending digest now",
+		Long: "Ask the running gateway to deliver all buffered digest items immediately " +
+			"instead of waiting for the next scheduled time.",
+		Example: `picoclaw digest flush
+picoclaw digest flush --stdout`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return flushCmd(digest.NewBuffer(workspace()), stdout)
+		},
+	}
+
+	cmd.Flags().BoolVar(&stdout, "stdout", false, "Print and clear the pending items here instead of sending them")
+
+	return cmd
+}
+
+func flushCmd(buffer *digest.Buffer, stdout bool) error {
+	items, err := buffer.Items()
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("No pending digest items.")
+		return nil
+	}
+
+	if stdout {
+		fmt.Println(digest.FormatItems(items))
+		return buffer.Remove(len(items))
+	}
+
+	if err := buffer.RequestFlush(); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Flush requested for %d item(s); the gateway will deliver them within a minute.\n", len(items))
+	return nil
+}
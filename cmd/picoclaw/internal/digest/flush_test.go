@@ -0,0 +1,36 @@
+package digest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/digest"
+)
+
+func TestNewFlushSubcommand(t *testing.T) {
+	cmd := newFlushCommand(func() string { return "" })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "flush", cmd.Use)
+	assert.Equal(t, "Deliver the pending digest now", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+	assert.True(t, cmd.HasExample())
+
+	assert.NotNil(t, cmd.Flags().Lookup("stdout"))
+}
+
+func TestFlushCmdStdoutDrainsBuffer(t *testing.T) {
+	buffer := digest.NewBuffer(t.TempDir())
+	require.NoError(t, buffer.Add(digest.Item{Source: "cron: disk", Content: "85% used"}))
+
+	require.NoError(t, flushCmd(buffer, true))
+
+	items, err := buffer.Items()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,62 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func NewDoctorCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the configured CLI providers are installed and logged in",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			return doctorCmd(cmd.Context(), os.Stdout, cfg)
+		},
+	}
+}
+
+// doctorCmd probes every model_list entry served by a local CLI (claude-cli,
+// codex-cli) and fails when one of them is unusable.
+func doctorCmd(ctx context.Context, w io.Writer, cfg *config.Config) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	probes := providers.ProbeCLIModels(ctx, cfg)
+	if len(probes) == 0 {
+		fmt.Fprintln(w, "No CLI providers in model_list; nothing to check.")
+		return nil
+	}
+
+	failed := 0
+	for _, p := range probes {
+		mark := "✓"
+		if p.Result.Status != providers.ProbeOK {
+			mark = "✗"
+			failed++
+		}
+		fmt.Fprintf(w, "%s %s (%s): %s\n", mark, p.ModelName, p.Model, p.Result.Status)
+		if p.Result.Version != "" {
+			fmt.Fprintf(w, "    Version: %s (%s)\n", p.Result.Version, p.Result.Command)
+		}
+		if p.Result.Detail != "" {
+			fmt.Fprintf(w, "    %s\n", p.Result.Detail)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d CLI providers are not usable", failed, len(probes))
+	}
+	return nil
+}
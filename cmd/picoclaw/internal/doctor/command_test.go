@@ -0,0 +1,77 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeCLI puts a claude script printing version on PATH.
+func fakeCLI(t *testing.T, version string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake CLI is a shell script")
+	}
+	dir := t.TempDir()
+	body := "#!/bin/sh\necho '" + version + " (Claude Code)'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "claude"), []byte(body), 0o755))
+	t.Setenv("PATH", dir)
+}
+
+func cliConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.ModelList = []config.ModelConfig{
+		{ModelName: "gpt", Model: "openai/gpt-4o", APIKey: "sk-test"},
+		{ModelName: "claude-code", Model: "claude-cli/claude-code"},
+	}
+	return cfg
+}
+
+func TestDoctorCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "")
+	fakeCLI(t, "2.0.14")
+
+	var out bytes.Buffer
+	err := doctorCmd(context.Background(), &out, cliConfig())
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "✗ claude-code (claude-cli/claude-code): needs login")
+	assert.Contains(t, out.String(), "claude login")
+	assert.NotContains(t, out.String(), "gpt")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".claude"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".claude", ".credentials.json"), []byte("{}"), 0o600))
+	out.Reset()
+	require.NoError(t, doctorCmd(context.Background(), &out, cliConfig()))
+	assert.Contains(t, out.String(), "✓ claude-code (claude-cli/claude-code): ok")
+	assert.Contains(t, out.String(), "Version: 2.0.14")
+}
+
+func TestDoctorCmd_Missing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	var out bytes.Buffer
+	err := doctorCmd(context.Background(), &out, cliConfig())
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "missing")
+}
+
+func TestDoctorCmd_NoCLIProviders(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ModelList = nil
+
+	var out bytes.Buffer
+	require.NoError(t, doctorCmd(context.Background(), &out, cfg))
+	assert.Contains(t, out.String(), "nothing to check")
+}
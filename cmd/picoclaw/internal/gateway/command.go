@@ -5,19 +5,37 @@ import (
 )
 
 func NewGatewayCommand() *cobra.Command {
-	var debug bool
+	var (
+		debug       bool
+		skipCatchup bool
+		noChannels  bool
+		only        []string
+		disable     []string
+	)
 
 	cmd := &cobra.Command{
 		Use:     "gateway",
 		Aliases: []string{"g"},
 		Short:   "Start picoclaw gateway",
 		Args:    cobra.NoArgs,
+		Example: `picoclaw gateway
+picoclaw gateway --only telegram
+picoclaw gateway --disable discord,line`,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			return gatewayCmd(debug)
+			return gatewayCmd(debug, skipCatchup, noChannels, only, disable)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
+	cmd.Flags().BoolVar(&skipCatchup, "skip-catchup", false, "Do not run cron jobs missed while the gateway was down")
+	cmd.Flags().BoolVar(&noChannels, "no-channels", false, "Run only the agent, cron and heartbeat without chat channels")
+	cmd.Flags().StringSliceVar(&only, "only", nil, "Start only these enabled channels, e.g. telegram,line")
+	cmd.Flags().StringSliceVar(&disable, "disable", nil, "Do not start these channels, e.g. discord")
+
+	cmd.MarkFlagsMutuallyExclusive("no-channels", "only")
+	cmd.MarkFlagsMutuallyExclusive("no-channels", "disable")
+
+	cmd.AddCommand(newSendCommand(), newInstallServiceCommand(), newMaintenanceCommand())
 
 	return cmd
 }
@@ -24,8 +24,23 @@ func TestNewGatewayCommand(t *testing.T) {
 	assert.Nil(t, cmd.PersistentPreRun)
 	assert.Nil(t, cmd.PersistentPostRun)
 
-	assert.False(t, cmd.HasSubCommands())
+	assert.True(t, cmd.HasSubCommands())
+	send, _, err := cmd.Find([]string{"send"})
+	require.NoError(t, err)
+	assert.Equal(t, "send", send.Name())
+	install, _, err := cmd.Find([]string{"install-service"})
+	require.NoError(t, err)
+	assert.Equal(t, "install-service", install.Name())
+	assert.NotNil(t, install.Flags().Lookup("force"))
+	assert.NotNil(t, install.Flags().Lookup("print"))
+	maint, _, err := cmd.Find([]string{"maintenance"})
+	require.NoError(t, err)
+	assert.Equal(t, "maintenance", maint.Name())
 
 	assert.True(t, cmd.HasFlags())
 	assert.NotNil(t, cmd.Flags().Lookup("debug"))
+	assert.NotNil(t, cmd.Flags().Lookup("skip-catchup"))
+	assert.NotNil(t, cmd.Flags().Lookup("no-channels"))
+	assert.NotNil(t, cmd.Flags().Lookup("only"))
+	assert.NotNil(t, cmd.Flags().Lookup("disable"))
 }
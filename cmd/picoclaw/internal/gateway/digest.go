@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/digest"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// digestSink adapts the digest buffer to the cron tool's sink.
+func digestSink(buffer *digest.Buffer) tools.DigestSink {
+	return func(source, content string) error {
+		return buffer.Add(digest.Item{Source: source, Content: content})
+	}
+}
+
+// newDigestService creates the service delivering the buffered digest at the
+// configured times to the digest chat (or the last active chat).
+func newDigestService(
+	cfg *config.Config,
+	buffer *digest.Buffer,
+	msgBus *bus.MessageBus,
+	provider providers.LLMProvider,
+	stateManager *state.Manager,
+) *digest.Service {
+	digestCfg := cfg.Gateway.Digest
+	service := digest.NewService(buffer, msgBus, digestCfg.Times)
+	service.SetTarget(func() (string, string) {
+		return notifyTarget(digestCfg.Channel, digestCfg.ChatID, stateManager)
+	})
+	service.SetLanguage(cfg.Gateway.LanguageFor)
+	if digestCfg.Summarize {
+		service.SetSummarizer(digest.NewLLMSummarizer(provider, cfg.Agents.Defaults.GetModelName()))
+	}
+	logger.InfoCF("digest", "Digest mode enabled", map[string]any{"times": digestCfg.Times})
+	return service
+}
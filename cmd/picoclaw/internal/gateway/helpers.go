@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
@@ -27,40 +28,92 @@ import (
 	_ "github.com/sipeed/picoclaw/pkg/channels/whatsapp"
 	_ "github.com/sipeed/picoclaw/pkg/channels/whatsapp_native"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/control"
 	"github.com/sipeed/picoclaw/pkg/cron"
 	"github.com/sipeed/picoclaw/pkg/devices"
+	"github.com/sipeed/picoclaw/pkg/digest"
 	"github.com/sipeed/picoclaw/pkg/health"
 	"github.com/sipeed/picoclaw/pkg/heartbeat"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/middleware"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/state"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
 
-func gatewayCmd(debug bool) error {
+func gatewayCmd(debug, skipCatchup, noChannels bool, only, disable []string) error {
 	if debug {
 		logger.SetLevel(logger.DEBUG)
 		fmt.Println("🔍 Debug mode enabled")
 	}
 
+	buildInfo := internal.GetBuildInfo()
+	logger.InfoCF("gateway", "Starting picoclaw gateway",
+		map[string]any{
+			"version":    buildInfo.Version,
+			"git_commit": buildInfo.GitCommit,
+			"build_time": buildInfo.BuildTime,
+			"go_version": buildInfo.GoVersion,
+			"os":         buildInfo.OS,
+			"arch":       buildInfo.Arch,
+			"cgo":        buildInfo.CGO,
+		})
+
 	cfg, err := internal.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("error loading config: %w", err)
 	}
+	// --only and --disable turn channels off before any is constructed
+	if err := cfg.Channels.Restrict(only, disable); err != nil {
+		return err
+	}
+
+	providers.Health().SetPersistPath(providers.HealthFilePath(cfg.WorkspacePath()))
 
 	provider, modelID, err := providers.CreateProvider(cfg)
-	if err != nil {
-		return fmt.Errorf("error creating provider: %w", err)
+	notConfigured := err != nil
+	if notConfigured {
+		if cfg.Gateway.NoProvider.Behavior != config.NoProviderFriendlyReply {
+			return fmt.Errorf("no usable model provider: %w\n"+
+				"Run 'picoclaw auth login' or set an api_key in model_list; "+
+				"to start anyway and answer messages with a notice, set gateway.no_provider.behavior to %q",
+				err, config.NoProviderFriendlyReply)
+		}
+		logger.WarnCF("gateway", "No usable provider; answering messages with a notice", map[string]any{
+			"error": err.Error(),
+		})
+		fmt.Printf("⚠ No usable model provider (%v): every message gets a \"not configured\" reply. Run 'picoclaw auth login'.\n", err)
+		provider = &providers.UnconfiguredProvider{Err: err}
 	}
 
+	warnUnusableCLIProviders(cfg)
+
 	// Use the resolved model ID from provider creation
 	if modelID != "" {
 		cfg.Agents.Defaults.ModelName = modelID
 	}
 
 	msgBus := bus.NewMessageBus()
+	if len(cfg.Gateway.Middleware) > 0 {
+		chain, err := middleware.BuildChain(cfg.Gateway.Middleware, provider, cfg.Agents.Defaults.GetModelName())
+		if err != nil {
+			return fmt.Errorf("error configuring middleware: %w", err)
+		}
+		msgBus.SetMiddleware(chain)
+		logger.InfoCF("gateway", "Message middleware enabled", map[string]any{"count": chain.Len()})
+	}
 	agentLoop := agent.NewAgentLoop(cfg, msgBus, provider)
+	maintenanceMode, err := agentLoop.EnableMaintenance(cfg.Gateway.Maintenance)
+	if err != nil {
+		return fmt.Errorf("error configuring maintenance mode: %w", err)
+	}
+	if notConfigured {
+		agentLoop.SetNotConfigured(cfg.Gateway.NoProvider.Message)
+	}
+	if maintenanceMode.Active() {
+		fmt.Println("⚠ Maintenance mode is on: messages get an offline notice (picoclaw gateway maintenance off)")
+	}
 
 	// Print agent startup info
 	fmt.Println("\n📦 Agent Status:")
@@ -80,6 +133,12 @@ func gatewayCmd(debug bool) error {
 			"skills_available": skillsInfo["available"],
 		})
 
+	// Digest buffer collects non-urgent cron and heartbeat output when enabled
+	var digestBuffer *digest.Buffer
+	if cfg.Gateway.Digest.Enabled {
+		digestBuffer = digest.NewBuffer(cfg.WorkspacePath())
+	}
+
 	// Setup cron tool and service
 	execTimeout := time.Duration(cfg.Tools.Cron.ExecTimeoutMinutes) * time.Minute
 	cronService := setupCronTool(
@@ -89,7 +148,14 @@ func gatewayCmd(debug bool) error {
 		cfg.Agents.Defaults.RestrictToWorkspace,
 		execTimeout,
 		cfg,
+		digestBuffer,
 	)
+	// Scheduled turns are skipped like in maintenance while no provider works
+	skipReason := maintenanceMode.SkipReason
+	if notConfigured {
+		skipReason = func() string { return "no provider" }
+	}
+	cronService.SetSkip(skipReason)
 
 	heartbeatService := heartbeat.NewHeartbeatService(
 		cfg.WorkspacePath(),
@@ -97,11 +163,16 @@ func gatewayCmd(debug bool) error {
 		cfg.Heartbeat.Enabled,
 	)
 	heartbeatService.SetBus(msgBus)
+	heartbeatService.SetSkip(skipReason)
 	heartbeatService.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
 		// Use cli:direct as fallback if no valid channel
 		if channel == "" || chatID == "" {
 			channel, chatID = "cli", "direct"
 		}
+		if cfg.Heartbeat.IncludeRecentContext {
+			rc := cfg.Heartbeat.RecentContext
+			prompt = agentLoop.WithRecentContext(context.Background(), prompt, rc.Turns, rc.MaxTokens)
+		}
 		// Use ProcessHeartbeat - no session history, each heartbeat is independent
 		var response string
 		response, err = agentLoop.ProcessHeartbeat(context.Background(), prompt, channel, chatID)
@@ -111,6 +182,11 @@ func gatewayCmd(debug bool) error {
 		if response == "HEARTBEAT_OK" {
 			return tools.SilentResult("Heartbeat OK")
 		}
+		if digestBuffer != nil && cfg.Heartbeat.Digest && response != "" {
+			if err := digestBuffer.Add(digest.Item{Source: "heartbeat", Content: response}); err != nil {
+				logger.WarnCF("digest", "Failed to buffer heartbeat result", map[string]any{"error": err.Error()})
+			}
+		}
 		// For heartbeat, always return silent - the subagent result will be
 		// sent to user via processSystemMessage when the async task completes
 		return tools.SilentResult(response)
@@ -123,11 +199,21 @@ func gatewayCmd(debug bool) error {
 		Interval: time.Duration(cfg.Tools.MediaCleanup.Interval) * time.Minute,
 	})
 	mediaStore.Start()
+	if cfg.Tools.MediaNormalize.Enabled {
+		media.SetImageNormalization(&media.ImageOptions{MaxDimension: cfg.Tools.MediaNormalize.MaxDimension})
+	}
 
-	channelManager, err := channels.NewManager(cfg, msgBus, mediaStore)
-	if err != nil {
-		mediaStore.Stop()
-		return fmt.Errorf("error creating channel manager: %w", err)
+	var channelManager *channels.Manager
+	if noChannels {
+		// Agent-only mode: cron, heartbeat and the HTTP endpoints still run,
+		// but no chat channel is started.
+		channelManager = channels.NewHeadlessManager(cfg, msgBus, mediaStore)
+	} else {
+		channelManager, err = channels.NewManager(cfg, msgBus, mediaStore)
+		if err != nil {
+			mediaStore.Stop()
+			return fmt.Errorf("error creating channel manager: %w", err)
+		}
 	}
 
 	// Inject channel manager and media store into agent loop
@@ -135,7 +221,9 @@ func gatewayCmd(debug bool) error {
 	agentLoop.SetMediaStore(mediaStore)
 
 	enabledChannels := channelManager.GetEnabledChannels()
-	if len(enabledChannels) > 0 {
+	if noChannels {
+		fmt.Println("✓ Channels disabled (--no-channels)")
+	} else if len(enabledChannels) > 0 {
 		fmt.Printf("✓ Channels enabled: %s\n", enabledChannels)
 	} else {
 		fmt.Println("⚠ Warning: No channels enabled")
@@ -169,10 +257,38 @@ func gatewayCmd(debug bool) error {
 		fmt.Println("✓ Device event service started")
 	}
 
+	var digestService *digest.Service
+	if digestBuffer != nil {
+		digestService = newDigestService(cfg, digestBuffer, msgBus, provider, stateManager)
+		if err := digestService.Start(); err != nil {
+			fmt.Printf("Error starting digest service: %v\n", err)
+		} else {
+			fmt.Printf("✓ Digest delivery at %s\n", strings.Join(cfg.Gateway.Digest.Times, ", "))
+		}
+	}
+
 	// Setup shared HTTP server with health endpoints and webhook handlers
 	healthServer := health.NewServer(cfg.Gateway.Host, cfg.Gateway.Port)
+	healthServer.RegisterStat("agent_turns", func() any { return agentLoop.TurnStats() })
+	healthServer.RegisterStat("channel_restarts_total", func() any { return channels.ChannelRestartsTotal() })
+	healthServer.RegisterStat("channel_links", func() any { return channels.LinkStates() })
+	healthServer.RegisterStat("heartbeat", func() any { return heartbeatService.Status() })
 	addr := fmt.Sprintf("%s:%d", cfg.Gateway.Host, cfg.Gateway.Port)
 	channelManager.SetupHTTPServer(addr, healthServer)
+	heartbeatState := func() (bool, heartbeat.HeartbeatStatus) {
+		return cfg.Heartbeat.Enabled, heartbeatService.GetStatus()
+	}
+	if cfg.Gateway.StatusPage.Enabled {
+		channelManager.HandleHTTP("/status", &statusPage{
+			version:   buildInfo.Version,
+			model:     cfg.Agents.Defaults.GetModelName(),
+			startedAt: time.Now(),
+			auth:      cfg.Gateway.StatusPage.BasicAuth,
+			channels:  channelManager.ChannelStatuses,
+			heartbeat: heartbeatState,
+			now:       time.Now,
+		})
+	}
 
 	if err := channelManager.StartAll(ctx); err != nil {
 		fmt.Printf("Error starting channels: %v\n", err)
@@ -180,9 +296,51 @@ func gatewayCmd(debug bool) error {
 	}
 
 	fmt.Printf("✓ Health endpoints available at http://%s:%d/health and /ready\n", cfg.Gateway.Host, cfg.Gateway.Port)
+	if cfg.Gateway.StatusPage.Enabled {
+		fmt.Printf("✓ Status page available at http://%s:%d/status\n", cfg.Gateway.Host, cfg.Gateway.Port)
+	}
+
+	// Local control socket for CLI commands such as "gateway send"
+	controlServer := control.NewServer(control.SocketPath(filepath.Dir(internal.GetConfigPath())))
+	controlServer.Handle("send", (&sendHandler{
+		hasChannel: func(name string) bool {
+			_, ok := channelManager.GetChannel(name)
+			return ok
+		},
+		bus:   msgBus,
+		store: mediaStore,
+	}).serve)
+	controlServer.Handle("maintenance", (&maintenanceHandler{mode: maintenanceMode}).serve)
+	if err := controlServer.Start(); err != nil {
+		fmt.Printf("⚠ Warning: control socket unavailable: %v\n", err)
+	} else {
+		fmt.Println("✓ Control socket ready for picoclaw gateway send")
+	}
 
 	go agentLoop.Run(ctx)
 
+	// Under systemd (Type=notify), report ready now that everything is up
+	go (&systemdNotifier{
+		busFull:    msgBus.Full,
+		responsive: channelManager.Responsive,
+		channels:   channelManager.ChannelStatuses,
+		heartbeat:  heartbeatState,
+		now:        time.Now,
+	}).run(ctx)
+
+	if !skipCatchup {
+		go func() {
+			if n := cronService.RunAllDue(ctx); n > 0 {
+				logger.InfoCF("cron", "Caught up on missed cron jobs", map[string]any{"count": n})
+			}
+		}()
+	}
+
+	if cfg.Gateway.UpdateCheck.Enabled {
+		go runUpdateChecker(ctx, cfg.Gateway.UpdateCheck, msgBus, stateManager)
+		fmt.Println("✓ Weekly update check enabled")
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
 	<-sigChan
@@ -199,10 +357,14 @@ func gatewayCmd(debug bool) error {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer shutdownCancel()
 
+	controlServer.Stop(shutdownCtx)
 	channelManager.StopAll(shutdownCtx)
 	deviceService.Stop()
 	heartbeatService.Stop()
 	cronService.Stop()
+	if digestService != nil {
+		digestService.Stop()
+	}
 	mediaStore.Stop()
 	agentLoop.Stop()
 	fmt.Println("✓ Gateway stopped")
@@ -210,6 +372,24 @@ func gatewayCmd(debug bool) error {
 	return nil
 }
 
+// warnUnusableCLIProviders probes the model_list entries served by a local
+// CLI, so a missing install or login shows at startup rather than on the
+// first message. See `picoclaw doctor`.
+func warnUnusableCLIProviders(cfg *config.Config) {
+	for _, p := range providers.ProbeCLIModels(context.Background(), cfg) {
+		if p.Result.Status == providers.ProbeOK {
+			continue
+		}
+		logger.WarnCF("gateway", "CLI provider is not usable", map[string]any{
+			"model":  p.ModelName,
+			"status": string(p.Result.Status),
+			"detail": p.Result.Detail,
+		})
+		fmt.Printf("⚠ Model %s (%s): %s: %s (picoclaw doctor)\n",
+			p.ModelName, p.Model, p.Result.Status, p.Result.Detail)
+	}
+}
+
 func setupCronTool(
 	agentLoop *agent.AgentLoop,
 	msgBus *bus.MessageBus,
@@ -217,11 +397,13 @@ func setupCronTool(
 	restrict bool,
 	execTimeout time.Duration,
 	cfg *config.Config,
+	digestBuffer *digest.Buffer,
 ) *cron.CronService {
 	cronStorePath := filepath.Join(workspace, "cron", "jobs.json")
 
 	// Create cron service
 	cronService := cron.NewCronService(cronStorePath, nil)
+	cronService.SetStagger(time.Duration(cfg.Tools.Cron.StaggerSeconds) * time.Second)
 
 	// Create and register CronTool
 	cronTool, err := tools.NewCronTool(cronService, agentLoop, msgBus, workspace, restrict, execTimeout, cfg)
@@ -229,6 +411,10 @@ func setupCronTool(
 		log.Fatalf("Critical error during CronTool initialization: %v", err)
 	}
 
+	if digestBuffer != nil {
+		cronTool.SetDigestSink(digestSink(digestBuffer))
+	}
+
 	agentLoop.RegisterTool(cronTool)
 
 	// Set the onJob handler
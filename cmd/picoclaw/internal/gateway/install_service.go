@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const serviceName = "picoclaw.service"
+
+func newInstallServiceCommand() *cobra.Command {
+	var (
+		force     bool
+		printOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install-service",
+		Short: "Install a systemd user unit that runs the gateway",
+		Args:  cobra.NoArgs,
+		Example: `picoclaw gateway install-service
+picoclaw gateway install-service --print`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			exe, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("cannot locate the picoclaw binary: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+				exe = resolved
+			}
+			unit := renderServiceUnit(exe, serviceEnvironment())
+
+			if printOnly {
+				fmt.Fprint(cmd.OutOrStdout(), unit)
+				return nil
+			}
+
+			configDir, err := os.UserConfigDir()
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(configDir, "systemd", "user", serviceName)
+			if _, err := os.Stat(path); err == nil && !force {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(unit), 0o644); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "✓ Wrote %s\n\n", path)
+			fmt.Fprintln(out, "Start it now and on every login with:")
+			fmt.Fprintln(out, "  systemctl --user daemon-reload")
+			fmt.Fprintln(out, "  systemctl --user enable --now picoclaw")
+			fmt.Fprintln(out, "\nTo keep it running while you are logged out:")
+			fmt.Fprintln(out, "  loginctl enable-linger")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing unit file")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the unit instead of writing it")
+
+	return cmd
+}
+
+// serviceEnvironment returns the picoclaw variables of the current
+// environment that choose the config and workspace, so the service uses the
+// same ones.
+func serviceEnvironment() []string {
+	var env []string
+	for _, name := range []string{"PICOCLAW_CONFIG", "PICOCLAW_HOME"} {
+		if v := os.Getenv(name); v != "" {
+			if abs, err := filepath.Abs(v); err == nil {
+				v = abs
+			}
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// renderServiceUnit returns a user unit that runs "exe gateway" as a
+// Type=notify service: systemd sees it active once the channels are up and
+// restarts it when it stops answering the watchdog. The gateway shuts down
+// gracefully on SIGINT.
+func renderServiceUnit(exe string, env []string) string {
+	var b strings.Builder
+	b.WriteString(`[Unit]
+Description=PicoClaw gateway
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+`)
+	fmt.Fprintf(&b, "ExecStart=%s gateway\n", quoteUnitArg(exe))
+	for _, kv := range env {
+		fmt.Fprintf(&b, "Environment=%s\n", quoteUnitArg(kv))
+	}
+	b.WriteString(`KillSignal=SIGINT
+TimeoutStartSec=120
+TimeoutStopSec=30
+WatchdogSec=60
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`)
+	return b.String()
+}
+
+// quoteUnitArg quotes s for a unit file when it contains spaces, quotes
+// or backslashes.
+func quoteUnitArg(s string) string {
+	if !strings.ContainsAny(s, " \t\"\\") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
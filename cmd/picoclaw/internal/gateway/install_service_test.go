@@ -0,0 +1,18 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderServiceUnit(t *testing.T) {
+	unit := renderServiceUnit("/opt/pico claw/picoclaw", []string{"PICOCLAW_HOME=/srv/picoclaw"})
+
+	assert.Contains(t, unit, "Type=notify\n")
+	assert.Contains(t, unit, "WatchdogSec=60\n")
+	assert.Contains(t, unit, "KillSignal=SIGINT\n")
+	assert.Contains(t, unit, `ExecStart="/opt/pico claw/picoclaw" gateway`+"\n")
+	assert.Contains(t, unit, "Environment=PICOCLAW_HOME=/srv/picoclaw\n")
+	assert.Contains(t, unit, "WantedBy=default.target\n")
+}
@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/control"
+	"github.com/sipeed/picoclaw/pkg/maintenance"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+// maintenanceRequest switches maintenance mode on or off, or with an empty
+// Set only asks for its status.
+type maintenanceRequest struct {
+	Set string `json:"set,omitempty"` // "on", "off" or ""
+}
+
+func newMaintenanceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "maintenance [on|off]",
+		Short:     "Answer messages with an offline notice instead of the model",
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"on", "off"},
+		Example: `picoclaw gateway maintenance on
+picoclaw gateway maintenance off
+picoclaw gateway maintenance`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req maintenanceRequest
+			if len(args) == 1 {
+				req.Set = args[0]
+			}
+
+			socket := control.SocketPath(filepath.Dir(internal.GetConfigPath()))
+			var st maintenance.Status
+			err := control.Call(cmd.Context(), socket, "maintenance", req, &st)
+			if errors.Is(err, control.ErrNotRunning) {
+				// The switch is kept in the workspace state, so it can be
+				// flipped before the gateway starts.
+				st, err = setMaintenanceOffline(req.Set)
+				if err == nil {
+					fmt.Fprintln(cmd.OutOrStdout(), "Gateway is not running; the setting applies when it starts.")
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("maintenance: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), describeMaintenance(st))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// setMaintenanceOffline applies set to the workspace state directly, for
+// when the gateway is not running.
+func setMaintenanceOffline(set string) (maintenance.Status, error) {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return maintenance.Status{}, fmt.Errorf("error loading config: %w", err)
+	}
+	mode, err := maintenance.New(cfg.Gateway.Maintenance, state.NewManager(cfg.WorkspacePath()))
+	if err != nil {
+		return maintenance.Status{}, err
+	}
+	return applyMaintenance(mode, set)
+}
+
+// applyMaintenance flips mode as set asks and returns its status.
+func applyMaintenance(mode *maintenance.Mode, set string) (maintenance.Status, error) {
+	switch set {
+	case "":
+	case "on", "off":
+		if err := mode.Set(set == "on"); err != nil {
+			return maintenance.Status{}, err
+		}
+	default:
+		return maintenance.Status{}, fmt.Errorf("invalid setting %q: use on or off", set)
+	}
+	return mode.Status(), nil
+}
+
+// describeMaintenance renders st for the terminal.
+func describeMaintenance(st maintenance.Status) string {
+	switch {
+	case st.Manual && st.Window != "":
+		return fmt.Sprintf("Maintenance mode is on (switched on, and scheduled %s)", st.Window)
+	case st.Manual:
+		return "Maintenance mode is on (switched on; turn it off with: picoclaw gateway maintenance off)"
+	case st.Window != "":
+		return fmt.Sprintf("Maintenance mode is on until the scheduled window %s ends", st.Window)
+	default:
+		return "Maintenance mode is off"
+	}
+}
+
+// maintenanceHandler serves the "maintenance" control verb.
+type maintenanceHandler struct {
+	mode *maintenance.Mode
+}
+
+func (h *maintenanceHandler) serve(_ context.Context, body json.RawMessage) (any, error) {
+	var req maintenanceRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	return applyMaintenance(h.mode, req.Set)
+}
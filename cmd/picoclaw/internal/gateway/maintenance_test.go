@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/maintenance"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestMaintenanceHandler(t *testing.T) {
+	mode, err := maintenance.New(config.MaintenanceConfig{}, state.NewManager(t.TempDir()))
+	require.NoError(t, err)
+	h := &maintenanceHandler{mode: mode}
+
+	serve := func(body string) (maintenance.Status, error) {
+		resp, err := h.serve(context.Background(), json.RawMessage(body))
+		if err != nil {
+			return maintenance.Status{}, err
+		}
+		return resp.(maintenance.Status), nil
+	}
+
+	st, err := serve(`{}`)
+	require.NoError(t, err)
+	assert.False(t, st.Active)
+
+	st, err = serve(`{"set":"on"}`)
+	require.NoError(t, err)
+	assert.True(t, st.Active)
+	assert.True(t, st.Manual)
+	assert.True(t, mode.Active())
+
+	st, err = serve(`{"set":"off"}`)
+	require.NoError(t, err)
+	assert.False(t, st.Active)
+
+	_, err = serve(`{"set":"maybe"}`)
+	assert.Error(t, err)
+}
+
+func TestDescribeMaintenance(t *testing.T) {
+	assert.Equal(t, "Maintenance mode is off", describeMaintenance(maintenance.Status{}))
+	assert.Contains(t, describeMaintenance(maintenance.Status{Active: true, Manual: true}), "switched on")
+	assert.Equal(t, "Maintenance mode is on until the scheduled window 02:00-04:00 ends",
+		describeMaintenance(maintenance.Status{Active: true, Window: "02:00-04:00"}))
+}
+
+func TestMaintenanceCommandArgs(t *testing.T) {
+	cmd := newMaintenanceCommand()
+	assert.NoError(t, cmd.Args(cmd, []string{"on"}))
+	assert.NoError(t, cmd.Args(cmd, nil))
+	assert.Error(t, cmd.Args(cmd, []string{"maybe"}))
+	assert.Error(t, cmd.Args(cmd, []string{"on", "off"}))
+}
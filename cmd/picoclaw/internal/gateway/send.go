@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/control"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// sendDeliveryTimeout bounds how long the gateway waits for a channel to
+// report the outcome of a send before giving up on the caller's behalf.
+const sendDeliveryTimeout = 60 * time.Second
+
+type sendRequest struct {
+	Channel string   `json:"channel"`
+	To      string   `json:"to"`
+	Message string   `json:"message,omitempty"`
+	Media   []string `json:"media,omitempty"`
+}
+
+type sendResponse struct {
+	State string `json:"state"`
+}
+
+func newSendCommand() *cobra.Command {
+	var (
+		channel   string
+		to        string
+		message   string
+		fromStdin bool
+		mediaArgs []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "send",
+		Short: "Send a message through the running gateway",
+		Args:  cobra.NoArgs,
+		Example: `picoclaw gateway send --channel telegram --to 12345 --message "Backup finished"
+df -h | picoclaw gateway send -c telegram -t 12345 --stdin
+picoclaw gateway send -c discord -t 9876 --media ./report.pdf`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if channel == "" || to == "" {
+				return fmt.Errorf("--channel and --to are required")
+			}
+			if fromStdin {
+				if message != "" {
+					return fmt.Errorf("--message and --stdin cannot be used together")
+				}
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("failed to read stdin: %w", err)
+				}
+				message = strings.TrimRight(string(data), "\n")
+			}
+
+			req := sendRequest{Channel: channel, To: to, Message: message}
+			for _, p := range mediaArgs {
+				abs, err := filepath.Abs(p)
+				if err != nil {
+					return fmt.Errorf("invalid media path %q: %w", p, err)
+				}
+				if _, err := os.Stat(abs); err != nil {
+					return fmt.Errorf("media file %q: %w", p, err)
+				}
+				req.Media = append(req.Media, abs)
+			}
+			if strings.TrimSpace(req.Message) == "" && len(req.Media) == 0 {
+				return fmt.Errorf("nothing to send: give --message, --stdin or --media")
+			}
+
+			socket := control.SocketPath(filepath.Dir(internal.GetConfigPath()))
+			var resp sendResponse
+			if err := control.Call(cmd.Context(), socket, "send", req, &resp); err != nil {
+				if errors.Is(err, control.ErrNotRunning) {
+					return fmt.Errorf("%w (start it with: picoclaw gateway)", err)
+				}
+				return fmt.Errorf("send rejected: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ Sent to %s:%s (%s)\n", channel, to, resp.State)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&channel, "channel", "c", "", "Channel to send on (e.g. telegram)")
+	cmd.Flags().StringVarP(&to, "to", "t", "", "Chat ID to send to")
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Message text")
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read the message text from stdin")
+	cmd.Flags().StringArrayVar(&mediaArgs, "media", nil, "Attach a file (repeatable)")
+
+	return cmd
+}
+
+// sendHandler serves the "send" control verb. Messages go through the
+// outbound bus, so the channel's rate limits and queue apply as usual.
+type sendHandler struct {
+	hasChannel func(name string) bool
+	bus        *bus.MessageBus
+	store      media.MediaStore
+	timeout    time.Duration
+}
+
+func (h *sendHandler) serve(ctx context.Context, body json.RawMessage) (any, error) {
+	var req sendRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if req.Channel == "" || req.To == "" {
+		return nil, fmt.Errorf("channel and to are required")
+	}
+	if !h.hasChannel(req.Channel) {
+		return nil, fmt.Errorf("channel %q is not enabled", req.Channel)
+	}
+	if strings.TrimSpace(req.Message) == "" && len(req.Media) == 0 {
+		return nil, fmt.Errorf("nothing to send")
+	}
+
+	if strings.TrimSpace(req.Message) != "" {
+		err := h.await(ctx, func(report func(bus.DeliveryStatus)) error {
+			return h.bus.PublishOutbound(ctx, bus.OutboundMessage{
+				Channel:    req.Channel,
+				ChatID:     req.To,
+				Content:    req.Message,
+				OnDelivery: report,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(req.Media) > 0 {
+		if h.store == nil {
+			return nil, fmt.Errorf("media is not supported by this gateway")
+		}
+		scope := "gateway-send:" + uuid.New().String()
+		parts := make([]bus.MediaPart, 0, len(req.Media))
+		for _, p := range req.Media {
+			part, err := h.storeMedia(p, scope)
+			if err != nil {
+				h.store.ReleaseAll(scope)
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+		err := h.await(ctx, func(report func(bus.DeliveryStatus)) error {
+			return h.bus.PublishOutboundMedia(ctx, bus.OutboundMediaMessage{
+				Channel:    req.Channel,
+				ChatID:     req.To,
+				Parts:      parts,
+				OnDelivery: report,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sendResponse{State: string(bus.DeliveryAccepted)}, nil
+}
+
+// storeMedia copies path into the media temp directory and registers the
+// copy, since the media store deletes files once they expire.
+func (h *sendHandler) storeMedia(path, scope string) (bus.MediaPart, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return bus.MediaPart{}, fmt.Errorf("failed to open media file: %w", err)
+	}
+	defer src.Close()
+
+	mediaDir := filepath.Join(os.TempDir(), "picoclaw_media")
+	if err := os.MkdirAll(mediaDir, 0o700); err != nil {
+		return bus.MediaPart{}, fmt.Errorf("failed to create media directory: %w", err)
+	}
+	filename := utils.SanitizeFilename(path)
+	localPath := filepath.Join(mediaDir, uuid.New().String()[:8]+"_"+filename)
+	dst, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return bus.MediaPart{}, fmt.Errorf("failed to copy media file: %w", err)
+	}
+	_, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(localPath)
+		return bus.MediaPart{}, fmt.Errorf("failed to copy media file: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	ref, err := h.store.Store(localPath, media.MediaMeta{
+		Filename:    filename,
+		ContentType: contentType,
+		Source:      "cli",
+	}, scope)
+	if err != nil {
+		os.Remove(localPath)
+		return bus.MediaPart{}, fmt.Errorf("failed to store media file: %w", err)
+	}
+	return bus.MediaPart{
+		Type:        media.InferType(filename, contentType),
+		Ref:         ref,
+		Filename:    filename,
+		ContentType: contentType,
+	}, nil
+}
+
+// await publishes a message and waits for its delivery report.
+func (h *sendHandler) await(ctx context.Context, publish func(report func(bus.DeliveryStatus)) error) error {
+	timeout := h.timeout
+	if timeout <= 0 {
+		timeout = sendDeliveryTimeout
+	}
+	status, err := bus.AwaitDelivery(ctx, timeout, publish)
+	if err != nil {
+		return err
+	}
+	if status.State == bus.DeliveryFailed {
+		return fmt.Errorf("delivery failed: %s", status.Error)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/media"
+)
+
+func TestNewSendCommand(t *testing.T) {
+	cmd := newSendCommand()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "send", cmd.Use)
+	assert.Equal(t, "Send a message through the running gateway", cmd.Short)
+	assert.NotNil(t, cmd.RunE)
+
+	for _, name := range []string{"channel", "to", "message", "stdin", "media"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), name)
+	}
+}
+
+func TestSendCommandGatewayNotRunning(t *testing.T) {
+	t.Setenv("PICOCLAW_CONFIG", filepath.Join(t.TempDir(), "config.json"))
+
+	cmd := newSendCommand()
+	cmd.SetArgs([]string{"--channel", "telegram", "--to", "1", "--message", "hi"})
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gateway is not running")
+}
+
+// deliverAll reports status for every outbound message and media send.
+func deliverAll(ctx context.Context, mb *bus.MessageBus, status bus.DeliveryStatus) {
+	go func() {
+		for {
+			msg, ok := mb.SubscribeOutbound(ctx)
+			if !ok {
+				return
+			}
+			msg.OnDelivery(status)
+		}
+	}()
+	go func() {
+		for {
+			msg, ok := mb.SubscribeOutboundMedia(ctx)
+			if !ok {
+				return
+			}
+			msg.OnDelivery(status)
+		}
+	}()
+}
+
+func newTestSendHandler(mb *bus.MessageBus) *sendHandler {
+	return &sendHandler{
+		hasChannel: func(name string) bool { return name == "telegram" },
+		bus:        mb,
+		store:      media.NewFileMediaStore(),
+		timeout:    time.Second,
+	}
+}
+
+func TestSendHandlerDelivers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mb := bus.NewMessageBus()
+	deliverAll(ctx, mb, bus.DeliveryStatus{State: bus.DeliveryAccepted})
+
+	file := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(file, []byte("%PDF"), 0o600))
+
+	body, _ := json.Marshal(sendRequest{Channel: "telegram", To: "1", Message: "hi", Media: []string{file}})
+	resp, err := newTestSendHandler(mb).serve(ctx, body)
+	require.NoError(t, err)
+	assert.Equal(t, sendResponse{State: "accepted"}, resp)
+}
+
+func TestSendHandlerRejects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mb := bus.NewMessageBus()
+	deliverAll(ctx, mb, bus.DeliveryStatus{State: bus.DeliveryFailed, Error: "rate limited"})
+	h := newTestSendHandler(mb)
+
+	body, _ := json.Marshal(sendRequest{Channel: "slack", To: "1", Message: "hi"})
+	_, err := h.serve(ctx, body)
+	assert.ErrorContains(t, err, `channel "slack" is not enabled`)
+
+	body, _ = json.Marshal(sendRequest{Channel: "telegram", To: "1"})
+	_, err = h.serve(ctx, body)
+	assert.ErrorContains(t, err, "nothing to send")
+
+	body, _ = json.Marshal(sendRequest{Channel: "telegram", To: "1", Message: "hi"})
+	_, err = h.serve(ctx, body)
+	assert.ErrorContains(t, err, "delivery failed: rate limited")
+}
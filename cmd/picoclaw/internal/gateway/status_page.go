@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// statusPage renders the gateway's /status HTML page, or JSON for clients
+// asking for application/json or ?format=json. The data sources are
+// functions so the page always shows live values.
+type statusPage struct {
+	version   string
+	model     string
+	startedAt time.Time
+	auth      *config.BasicAuthConfig
+	channels  func() []channels.ChannelStatus
+	heartbeat func() (enabled bool, st heartbeat.HeartbeatStatus)
+	now       func() time.Time
+}
+
+type statusPageData struct {
+	Version   string
+	Model     string
+	Uptime    string
+	Generated string
+	Channels  []statusPageChannel
+	Heartbeat statusPageHeartbeat
+}
+
+type statusPageChannel struct {
+	Name        string
+	Running     bool
+	LastMessage string
+}
+
+type statusPageHeartbeat struct {
+	Enabled bool
+	LastRun string
+	Outcome string
+	NextRun string
+	Runs    int
+	Errors  int
+}
+
+// statusJSON is the JSON form of the status page.
+type statusJSON struct {
+	Version   string                     `json:"version"`
+	Model     string                     `json:"model"`
+	StartedAt time.Time                  `json:"started_at"`
+	Channels  []statusJSONChannel        `json:"channels"`
+	Heartbeat *heartbeat.HeartbeatStatus `json:"heartbeat,omitempty"` // nil when disabled
+}
+
+type statusJSONChannel struct {
+	Name          string    `json:"name"`
+	Running       bool      `json:"running"`
+	LastMessageAt time.Time `json:"last_message_at,omitzero"`
+}
+
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="30">
+<title>PicoClaw status</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em auto; max-width: 40em; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: .4em .6em; border-bottom: 1px solid #ddd; }
+.ok { color: #1a7f37; } .down { color: #cf222e; } .muted { color: #777; }
+</style>
+</head>
+<body>
+<h1>PicoClaw</h1>
+<p>Version {{.Version}} &middot; up {{.Uptime}} &middot; model <strong>{{.Model}}</strong></p>
+<h2>Channels</h2>
+{{if .Channels}}<table>
+<tr><th>Channel</th><th>State</th><th>Last message</th></tr>
+{{range .Channels}}<tr><td>{{.Name}}</td>{{if .Running}}<td class="ok">running</td>{{else}}<td class="down">stopped</td>{{end}}<td>{{.LastMessage}}</td></tr>
+{{end}}</table>{{else}}<p class="muted">No channels enabled.</p>{{end}}
+<h2>Heartbeat</h2>
+{{if .Heartbeat.Enabled}}<p>Last run: {{.Heartbeat.LastRun}}{{if .Heartbeat.Outcome}} &middot; {{.Heartbeat.Outcome}}{{end}}</p>
+{{if .Heartbeat.NextRun}}<p>Next run: {{.Heartbeat.NextRun}}</p>{{end}}
+<p>{{.Heartbeat.Runs}} runs, {{.Heartbeat.Errors}} errors since start</p>{{else}}<p class="muted">Disabled.</p>{{end}}
+<p class="muted">Generated {{.Generated}}</p>
+</body>
+</html>
+`))
+
+func (p *statusPage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.auth != nil && !checkBasicAuth(r, p.auth) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="picoclaw", charset="UTF-8"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(p.json()); err != nil {
+			logger.WarnCF("gateway", "Failed to write status JSON", map[string]any{"error": err.Error()})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusPageTemplate.Execute(w, p.data()); err != nil {
+		logger.WarnCF("gateway", "Failed to render status page", map[string]any{"error": err.Error()})
+	}
+}
+
+func (p *statusPage) data() statusPageData {
+	now := p.now()
+	d := statusPageData{
+		Version:   p.version,
+		Model:     p.model,
+		Uptime:    now.Sub(p.startedAt).Truncate(time.Second).String(),
+		Generated: now.Format(time.DateTime),
+	}
+	for _, ch := range p.channels() {
+		d.Channels = append(d.Channels, statusPageChannel{
+			Name:        ch.Name,
+			Running:     ch.Running,
+			LastMessage: formatLastSeen(ch.LastMessageAt, now),
+		})
+	}
+	if p.heartbeat != nil {
+		enabled, st := p.heartbeat()
+		d.Heartbeat = statusPageHeartbeat{
+			Enabled: enabled,
+			LastRun: formatLastSeen(st.LastRunAt, now),
+			Outcome: st.LastResultSummary,
+			Runs:    st.TotalRuns,
+			Errors:  st.ErrorCount,
+		}
+		if st.IsRunning && !st.NextRunAt.IsZero() {
+			d.Heartbeat.NextRun = st.NextRunAt.Format(time.DateTime)
+		}
+	}
+	return d
+}
+
+func (p *statusPage) json() statusJSON {
+	s := statusJSON{
+		Version:   p.version,
+		Model:     p.model,
+		StartedAt: p.startedAt,
+		Channels:  []statusJSONChannel{},
+	}
+	for _, ch := range p.channels() {
+		s.Channels = append(s.Channels, statusJSONChannel{
+			Name:          ch.Name,
+			Running:       ch.Running,
+			LastMessageAt: ch.LastMessageAt,
+		})
+	}
+	if p.heartbeat != nil {
+		if enabled, st := p.heartbeat(); enabled {
+			s.Heartbeat = &st
+		}
+	}
+	return s
+}
+
+// formatLastSeen renders t as a timestamp with its age, or "never".
+func formatLastSeen(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(time.DateTime) + " (" + now.Sub(t).Truncate(time.Second).String() + " ago)"
+}
+
+func checkBasicAuth(r *http.Request, auth *config.BasicAuthConfig) bool {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(auth.Username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(auth.Password)) == 1
+	return userOK && passOK
+}
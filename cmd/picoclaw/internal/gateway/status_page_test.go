@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+func newTestStatusPage(auth *config.BasicAuthConfig) *statusPage {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &statusPage{
+		version:   "1.2.3",
+		model:     "gpt-5.2",
+		startedAt: now.Add(-2 * time.Hour),
+		auth:      auth,
+		channels: func() []channels.ChannelStatus {
+			return []channels.ChannelStatus{
+				{Name: "discord", Running: false},
+				{Name: "telegram", Running: true, LastMessageAt: now.Add(-5 * time.Minute)},
+			}
+		},
+		heartbeat: func() (bool, heartbeat.HeartbeatStatus) {
+			return true, heartbeat.HeartbeatStatus{
+				LastRunAt:         now.Add(-10 * time.Minute),
+				LastResultSummary: "ok",
+				NextRunAt:         now.Add(20 * time.Minute),
+				IsRunning:         true,
+				TotalRuns:         4,
+				ErrorCount:        1,
+			}
+		},
+		now: func() time.Time { return now },
+	}
+}
+
+func TestStatusPageRendersState(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestStatusPage(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "gpt-5.2")
+	assert.Contains(t, body, "up 2h0m0s")
+	assert.Contains(t, body, "<td>telegram</td><td class=\"ok\">running</td><td>2026-03-01 11:55:00 (5m0s ago)</td>")
+	assert.Contains(t, body, "<td>discord</td><td class=\"down\">stopped</td><td>never</td>")
+	assert.Contains(t, body, "Last run: 2026-03-01 11:50:00 (10m0s ago) &middot; ok")
+	assert.Contains(t, body, "Next run: 2026-03-01 12:20:00")
+	assert.Contains(t, body, "4 runs, 1 errors since start")
+}
+
+func TestStatusPageJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestStatusPage(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status?format=json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var got statusJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "gpt-5.2", got.Model)
+	assert.Len(t, got.Channels, 2)
+	require.NotNil(t, got.Heartbeat)
+	assert.Equal(t, 4, got.Heartbeat.TotalRuns)
+	assert.Equal(t, "ok", got.Heartbeat.LastResultSummary)
+}
+
+func TestStatusPageBasicAuth(t *testing.T) {
+	page := newTestStatusPage(&config.BasicAuthConfig{Username: "admin", Password: "s3cret"})
+
+	rec := httptest.NewRecorder()
+	page.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Basic")
+
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.SetBasicAuth("admin", "wrong")
+	rec = httptest.NewRecorder()
+	page.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/status", nil)
+	req.SetBasicAuth("admin", "s3cret")
+	rec = httptest.NewRecorder()
+	page.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
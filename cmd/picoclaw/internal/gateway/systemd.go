@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/sdnotify"
+)
+
+// systemdStatusInterval is how often the status line is refreshed when the
+// watchdog does not ask for more frequent pings.
+const systemdStatusInterval = 30 * time.Second
+
+// systemdNotifier reports the gateway's state to systemd when it runs as a
+// Type=notify unit: ready once the channels are up, a status line with the
+// channels and heartbeat, and watchdog pings while the gateway is healthy.
+type systemdNotifier struct {
+	busFull    func() bool
+	responsive func(timeout time.Duration) bool
+	channels   func() []channels.ChannelStatus
+	heartbeat  func() (enabled bool, st heartbeat.HeartbeatStatus)
+	now        func() time.Time
+	stalled    bool // the bus was full at the last check
+}
+
+// run reports readiness, then keeps the status and watchdog up to date
+// until ctx is done. It returns at once when systemd did not start the
+// gateway.
+func (n *systemdNotifier) run(ctx context.Context) {
+	sent, err := sdnotify.Notify(sdnotify.Ready + "\n" + sdnotify.Status(n.status()))
+	if err != nil {
+		logger.WarnCF("gateway", "Failed to notify systemd", map[string]any{"error": err.Error()})
+	}
+	if !sent {
+		return
+	}
+
+	watchdog := sdnotify.WatchdogInterval()
+	interval := systemdStatusInterval
+	if watchdog > 0 && watchdog/2 < interval {
+		interval = watchdog / 2
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			sdnotify.Notify(sdnotify.Stopping)
+			return
+		case <-ticker.C:
+		}
+
+		state := sdnotify.Status(n.status())
+		if err := n.alive(interval / 2); err != nil {
+			// Without pings systemd restarts the gateway when the watchdog
+			// times out.
+			logger.WarnCF("gateway", "Gateway unresponsive, skipping watchdog ping",
+				map[string]any{"error": err.Error()})
+			state = sdnotify.Status("unresponsive: " + err.Error())
+		} else if watchdog > 0 {
+			state = sdnotify.Watchdog + "\n" + state
+		}
+		if _, err := sdnotify.Notify(state); err != nil {
+			logger.WarnCF("gateway", "Failed to notify systemd", map[string]any{"error": err.Error()})
+		}
+	}
+}
+
+// alive checks that the bus is being drained and the channel manager is
+// not wedged. A bus that is full once may just be busy; full at two checks
+// in a row, it is stuck.
+func (n *systemdNotifier) alive(timeout time.Duration) error {
+	full := n.busFull()
+	stalled := full && n.stalled
+	n.stalled = full
+	if stalled {
+		return errors.New("message bus is not being drained")
+	}
+	if !n.responsive(timeout) {
+		return errors.New("channel manager is not responding")
+	}
+	return nil
+}
+
+// status renders the one-line summary systemctl status shows.
+func (n *systemdNotifier) status() string {
+	statuses := n.channels()
+	var parts []string
+	if len(statuses) == 0 {
+		parts = append(parts, "no channels")
+	} else {
+		running := 0
+		var stopped []string
+		for _, ch := range statuses {
+			if ch.Running {
+				running++
+			} else {
+				stopped = append(stopped, ch.Name)
+			}
+		}
+		s := fmt.Sprintf("%d/%d channels running", running, len(statuses))
+		if len(stopped) > 0 {
+			s += " (" + strings.Join(stopped, ", ") + " stopped)"
+		}
+		parts = append(parts, s)
+	}
+
+	enabled, hb := n.heartbeat()
+	switch {
+	case !enabled:
+		parts = append(parts, "heartbeat off")
+	case hb.LastRunAt.IsZero():
+		parts = append(parts, "heartbeat not run yet")
+	default:
+		s := "heartbeat " + n.now().Sub(hb.LastRunAt).Truncate(time.Second).String() + " ago"
+		if hb.LastResultSummary != "" {
+			s += ": " + hb.LastResultSummary
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+func TestSystemdNotifierStatus(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	n := &systemdNotifier{
+		channels: func() []channels.ChannelStatus {
+			return []channels.ChannelStatus{{Name: "discord"}, {Name: "telegram", Running: true}}
+		},
+		heartbeat: func() (bool, heartbeat.HeartbeatStatus) {
+			return true, heartbeat.HeartbeatStatus{LastRunAt: now.Add(-5 * time.Minute), LastResultSummary: "ok"}
+		},
+		now: func() time.Time { return now },
+	}
+	assert.Equal(t, "1/2 channels running (discord stopped); heartbeat 5m0s ago: ok", n.status())
+
+	n.channels = func() []channels.ChannelStatus { return nil }
+	n.heartbeat = func() (bool, heartbeat.HeartbeatStatus) { return false, heartbeat.HeartbeatStatus{} }
+	assert.Equal(t, "no channels; heartbeat off", n.status())
+}
+
+func TestSystemdNotifierAlive(t *testing.T) {
+	full, responsive := false, true
+	n := &systemdNotifier{
+		busFull:    func() bool { return full },
+		responsive: func(time.Duration) bool { return responsive },
+	}
+	assert.NoError(t, n.alive(time.Second))
+
+	// A full bus is only stuck when it is still full at the next check.
+	full = true
+	assert.NoError(t, n.alive(time.Second))
+	assert.ErrorContains(t, n.alive(time.Second), "message bus")
+	full = false
+	assert.NoError(t, n.alive(time.Second))
+
+	responsive = false
+	assert.ErrorContains(t, n.alive(time.Second), "channel manager")
+}
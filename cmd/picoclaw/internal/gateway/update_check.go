@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/state"
+	"github.com/sipeed/picoclaw/pkg/updater"
+)
+
+const (
+	updateCheckInterval     = 7 * 24 * time.Hour
+	updateCheckInitialDelay = 5 * time.Minute
+)
+
+// runUpdateChecker checks GitHub releases once shortly after startup and then
+// weekly, notifying the owner chat once per new release.
+func runUpdateChecker(
+	ctx context.Context,
+	cfg config.UpdateCheckConfig,
+	msgBus *bus.MessageBus,
+	stateManager *state.Manager,
+) {
+	client := updater.NewClient(updater.DefaultRepo)
+	notified := ""
+
+	check := func() {
+		checkCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		rel, err := client.LatestRelease(checkCtx, cfg.Channel)
+		if err != nil {
+			logger.WarnCF("update", "Update check failed", map[string]any{"error": err.Error()})
+			return
+		}
+
+		current := internal.GetVersion()
+		if !updater.IsNewer(current, rel.TagName) || rel.TagName == notified {
+			return
+		}
+
+		logger.InfoCF("update", "New picoclaw release available", map[string]any{
+			"current": current,
+			"latest":  rel.TagName,
+		})
+
+		channel, chatID := notifyTarget(cfg.NotifyChannel, cfg.NotifyChatID, stateManager)
+		if channel == "" || chatID == "" {
+			return
+		}
+
+		content := fmt.Sprintf("🦞 picoclaw %s is available (running %s).\nRun `picoclaw update` to install it.",
+			rel.TagName, current)
+		if rel.HTMLURL != "" {
+			content += "\n" + rel.HTMLURL
+		}
+		if err := msgBus.PublishOutbound(ctx, bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: content,
+		}); err == nil {
+			notified = rel.TagName
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(updateCheckInitialDelay):
+		check()
+	}
+
+	ticker := time.NewTicker(updateCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// notifyTarget returns the configured owner chat, falling back to the
+// last active (non-internal) chat recorded in workspace state.
+func notifyTarget(channel, chatID string, stateManager *state.Manager) (string, string) {
+	if channel != "" && chatID != "" {
+		return channel, chatID
+	}
+	if stateManager == nil {
+		return "", ""
+	}
+	channel, chatID, ok := strings.Cut(stateManager.GetLastChannel(), ":")
+	if !ok || constants.IsInternalChannel(channel) {
+		return "", ""
+	}
+	return channel, chatID
+}
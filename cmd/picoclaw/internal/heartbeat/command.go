@@ -0,0 +1,40 @@
+package heartbeat
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func NewHeartbeatCommand() *cobra.Command {
+	var cfg *config.Config
+
+	cmd := &cobra.Command{
+		Use:   "heartbeat",
+		Short: "Inspect and edit the periodic heartbeat",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			var err error
+			cfg, err = internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(
+		newStatusCommand(func() *config.Config { return cfg }),
+		newShowCommand(func() *config.Config { return cfg }),
+		newEditCommand(func() *config.Config { return cfg }),
+		newDryRunCommand(func() *config.Config { return cfg }),
+	)
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package heartbeat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHeartbeatCommand(t *testing.T) {
+	cmd := NewHeartbeatCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "heartbeat", cmd.Use)
+	assert.Equal(t, "Inspect and edit the periodic heartbeat", cmd.Short)
+
+	assert.False(t, cmd.HasFlags())
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+	assert.NotNil(t, cmd.PersistentPreRunE)
+
+	assert.True(t, cmd.HasSubCommands())
+	names := []string{}
+	for _, sub := range cmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	assert.Contains(t, names, "status")
+	assert.Contains(t, names, "show")
+	assert.Contains(t, names, "edit")
+	assert.Contains(t, names, "dry-run")
+}
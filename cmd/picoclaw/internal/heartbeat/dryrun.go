@@ -0,0 +1,33 @@
+package heartbeat
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+func newDryRunCommand(cfgFn func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dry-run",
+		Short: "Print the prompt the next heartbeat would send, without running it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg := cfgFn()
+			return dryRunCmd(cmd.OutOrStdout(), cfg.WorkspacePath(), cfg.Heartbeat.Interval)
+		},
+	}
+}
+
+func dryRunCmd(w io.Writer, workspace string, interval int) error {
+	hs := heartbeat.NewHeartbeatService(workspace, interval, true)
+	prompt := hs.DryRun()
+	if prompt == "" {
+		return fmt.Errorf("%s is empty; the heartbeat would be skipped", heartbeat.TasksPath(workspace))
+	}
+	_, err := io.WriteString(w, prompt)
+	return err
+}
@@ -0,0 +1,88 @@
+package heartbeat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+func newShowCommand(cfgFn func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Print the heartbeat tasks (HEARTBEAT.md)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return showCmd(cmd.OutOrStdout(), cfgFn().WorkspacePath())
+		},
+	}
+}
+
+func showCmd(w io.Writer, workspace string) error {
+	data, err := os.ReadFile(heartbeat.TasksPath(workspace))
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no HEARTBEAT.md in %s; create it with 'picoclaw heartbeat edit'", workspace)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func newEditCommand(cfgFn func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the heartbeat tasks (HEARTBEAT.md) in $EDITOR",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return editCmd(cmd, cfgFn().WorkspacePath(), os.Getenv("EDITOR"))
+		},
+	}
+}
+
+// editCmd opens HEARTBEAT.md in editor, creating it from the default
+// template if needed, and checks the result once the editor exits.
+func editCmd(cmd *cobra.Command, workspace, editor string) error {
+	path := heartbeat.TasksPath(workspace)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(heartbeat.DefaultTasks), 0o644); err != nil {
+			return err
+		}
+	}
+
+	// EDITOR may carry arguments, e.g. "code --wait".
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	ed := exec.Command(args[0], append(args[1:], path)...)
+	ed.Stdin = cmd.InOrStdin()
+	ed.Stdout = cmd.OutOrStdout()
+	ed.Stderr = cmd.ErrOrStderr()
+	if err := ed.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := heartbeat.CheckTasks(string(data)); err != nil {
+		return fmt.Errorf("%w (saved to %s)", err, path)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved %s; the heartbeat reads it on its next run.\n", path)
+	return nil
+}
@@ -0,0 +1,85 @@
+package heartbeat
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+// fakeEditor writes an editor script that appends line to the file it is
+// given.
+func fakeEditor(t *testing.T, line string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake editor is a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "editor")
+	body := "#!/bin/sh\necho '" + line + "' >> \"$1\"\n"
+	require.NoError(t, os.WriteFile(script, []byte(body), 0o755))
+	return script
+}
+
+func TestShowCmd(t *testing.T) {
+	workspace := t.TempDir()
+
+	var out bytes.Buffer
+	err := showCmd(&out, workspace)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "picoclaw heartbeat edit")
+
+	require.NoError(t, os.WriteFile(heartbeat.TasksPath(workspace), []byte("- water the plants\n"), 0o644))
+	require.NoError(t, showCmd(&out, workspace))
+	assert.Equal(t, "- water the plants\n", out.String())
+}
+
+func TestEditCmd(t *testing.T) {
+	workspace := t.TempDir()
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	// A missing file starts from the default template.
+	require.NoError(t, editCmd(cmd, workspace, fakeEditor(t, "- check the backups")))
+	data, err := os.ReadFile(heartbeat.TasksPath(workspace))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Add your heartbeat tasks below this line:")
+	assert.Contains(t, string(data), "- check the backups")
+	assert.Contains(t, out.String(), "Saved")
+}
+
+func TestEditCmdRejectsCommentsOnly(t *testing.T) {
+	workspace := t.TempDir()
+	path := heartbeat.TasksPath(workspace)
+	require.NoError(t, os.WriteFile(path, []byte("# Tasks\n"), 0o644))
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(&bytes.Buffer{})
+	err := editCmd(cmd, workspace, fakeEditor(t, "<!-- nothing yet -->"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "add at least one task")
+}
+
+func TestDryRunCmd(t *testing.T) {
+	workspace := t.TempDir()
+	path := heartbeat.TasksPath(workspace)
+
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	var out bytes.Buffer
+	err := dryRunCmd(&out, workspace, 30)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "would be skipped")
+
+	require.NoError(t, os.WriteFile(path, []byte("- check the backups\n"), 0o644))
+	require.NoError(t, dryRunCmd(&out, workspace, 30))
+	assert.Contains(t, out.String(), "Current time:")
+	assert.Contains(t, out.String(), "HEARTBEAT_OK")
+	assert.Contains(t, out.String(), "- check the backups")
+}
@@ -0,0 +1,83 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+func newStatusCommand(cfgFn func() *config.Config) *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show when the heartbeat last ran and when it runs next",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg := cfgFn()
+			return statusCmd(cmd.OutOrStdout(), cfg.WorkspacePath(), cfg.Heartbeat.Enabled, asJSON, time.Now())
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the raw status file")
+
+	return cmd
+}
+
+func statusCmd(w io.Writer, workspace string, enabled, asJSON bool, now time.Time) error {
+	st, err := heartbeat.ReadStatus(workspace)
+	if errors.Is(err, fs.ErrNotExist) {
+		if !enabled {
+			fmt.Fprintln(w, "Heartbeat is disabled (heartbeat.enabled is false).")
+			return nil
+		}
+		fmt.Fprintln(w, "No heartbeat status recorded yet. Is the gateway running?")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+
+	state := "stopped"
+	if st.Running {
+		state = fmt.Sprintf("running, every %d minutes", st.IntervalMinutes)
+	}
+	if !enabled {
+		state += " (disabled in config)"
+	}
+	fmt.Fprintf(w, "Heartbeat: %s\n", state)
+
+	if st.LastRunAt.IsZero() {
+		fmt.Fprintln(w, "Last run:  never")
+	} else {
+		fmt.Fprintf(w, "Last run:  %s (%s ago): %s\n", st.LastRunAt.Local().Format(time.DateTime),
+			now.Sub(st.LastRunAt).Round(time.Second), st.LastOutcome)
+	}
+	if st.Running && !st.NextRunAt.IsZero() {
+		next := fmt.Sprintf("%s (in %s)", st.NextRunAt.Local().Format(time.DateTime),
+			st.NextRunAt.Sub(now).Round(time.Second))
+		// A next run well in the past means the gateway stopped without
+		// saying so, e.g. it crashed or the device lost power.
+		if now.Sub(st.NextRunAt) > time.Minute {
+			next = fmt.Sprintf("%s, overdue; is the gateway still running?",
+				st.NextRunAt.Local().Format(time.DateTime))
+		}
+		fmt.Fprintf(w, "Next run:  %s\n", next)
+	}
+	fmt.Fprintf(w, "Consecutive errors: %d\n", st.ConsecutiveErrors)
+	return nil
+}
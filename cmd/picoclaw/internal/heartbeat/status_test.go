@@ -0,0 +1,67 @@
+package heartbeat
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+func TestNewStatusSubcommand(t *testing.T) {
+	cmd := newStatusCommand(nil)
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "status", cmd.Use)
+	assert.NotNil(t, cmd.RunE)
+	assert.NotNil(t, cmd.Flags().Lookup("json"))
+}
+
+func writeStatus(t *testing.T, workspace string, st heartbeat.Status) {
+	t.Helper()
+	data, err := json.Marshal(st)
+	require.NoError(t, err)
+	path := heartbeat.StatusPath(workspace)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func TestStatusCmd(t *testing.T) {
+	workspace := t.TempDir()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+
+	var out bytes.Buffer
+	require.NoError(t, statusCmd(&out, workspace, true, false, now))
+	assert.Contains(t, out.String(), "No heartbeat status recorded yet")
+
+	writeStatus(t, workspace, heartbeat.Status{
+		Running:           true,
+		IntervalMinutes:   30,
+		LastRunAt:         now.Add(-5 * time.Minute),
+		LastOutcome:       "error: provider timeout",
+		NextRunAt:         now.Add(25 * time.Minute),
+		ConsecutiveErrors: 2,
+	})
+	out.Reset()
+	require.NoError(t, statusCmd(&out, workspace, true, false, now))
+	assert.Contains(t, out.String(), "Heartbeat: running, every 30 minutes")
+	assert.Contains(t, out.String(), "(5m0s ago): error: provider timeout")
+	assert.Contains(t, out.String(), "(in 25m0s)")
+	assert.Contains(t, out.String(), "Consecutive errors: 2")
+
+	out.Reset()
+	require.NoError(t, statusCmd(&out, workspace, true, false, now.Add(time.Hour)))
+	assert.Contains(t, out.String(), "overdue")
+
+	out.Reset()
+	require.NoError(t, statusCmd(&out, workspace, true, true, now))
+	var st heartbeat.Status
+	require.NoError(t, json.Unmarshal(out.Bytes(), &st))
+	assert.Equal(t, 2, st.ConsecutiveErrors)
+}
@@ -5,8 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
+	"slices"
 
+	"github.com/sipeed/picoclaw/pkg/agent"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
 )
 
 const Logo = "🦞"
@@ -30,6 +34,33 @@ func LoadConfig() (*config.Config, error) {
 	return config.LoadConfig(GetConfigPath())
 }
 
+// AgentWorkspaces returns the workspace of the named agent or, when
+// agentName is empty, the workspaces of all agents and the default
+// workspace, the default agent's first.
+func AgentWorkspaces(cfg *config.Config, agentName string) ([]string, error) {
+	if agentName != "" {
+		ac, err := cfg.Agents.Lookup(agentName)
+		if err != nil {
+			return nil, err
+		}
+		return []string{agent.AgentWorkspace(&ac, cfg)}, nil
+	}
+	var workspaces []string
+	add := func(ac *config.AgentConfig) {
+		if ws := agent.AgentWorkspace(ac, cfg); !slices.Contains(workspaces, ws) {
+			workspaces = append(workspaces, ws)
+		}
+	}
+	if ac, err := cfg.Agents.Lookup(routing.NewRouteResolver(cfg).DefaultAgentID()); err == nil {
+		add(&ac)
+	}
+	add(nil)
+	for _, ac := range cfg.Agents.All() {
+		add(&ac)
+	}
+	return workspaces, nil
+}
+
 // FormatVersion returns the version string with optional git commit
 func FormatVersion() string {
 	v := version
@@ -53,3 +84,42 @@ func FormatBuildInfo() (string, string) {
 func GetVersion() string {
 	return version
 }
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	CGO       bool   `json:"cgo"`
+}
+
+// GetBuildInfo returns version and platform details of the running binary.
+func GetBuildInfo() BuildInfo {
+	build, goVer := FormatBuildInfo()
+	return BuildInfo{
+		Version:   version,
+		GitCommit: gitCommit,
+		BuildTime: build,
+		GoVersion: goVer,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CGO:       cgoEnabled(),
+	}
+}
+
+// cgoEnabled reports whether the binary was built with CGO_ENABLED=1.
+func cgoEnabled() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return false
+	}
+	for _, s := range info.Settings {
+		if s.Key == "CGO_ENABLED" {
+			return s.Value == "1"
+		}
+	}
+	return false
+}
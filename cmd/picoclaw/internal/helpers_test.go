@@ -105,3 +105,19 @@ func TestGetConfigPath_WithEnv(t *testing.T) {
 
 	assert.Equal(t, want, got)
 }
+
+func TestGetBuildInfo(t *testing.T) {
+	oldVersion, oldGit := version, gitCommit
+	t.Cleanup(func() { version, gitCommit = oldVersion, oldGit })
+
+	version = "1.2.3"
+	gitCommit = "abc123"
+
+	info := GetBuildInfo()
+
+	assert.Equal(t, "1.2.3", info.Version)
+	assert.Equal(t, "abc123", info.GitCommit)
+	assert.Equal(t, runtime.GOOS, info.OS)
+	assert.Equal(t, runtime.GOARCH, info.Arch)
+	assert.NotEmpty(t, info.GoVersion)
+}
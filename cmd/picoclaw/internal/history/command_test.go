@@ -0,0 +1,27 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHistoryCommand(t *testing.T) {
+	cmd := NewHistoryCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "history", cmd.Use)
+	assert.Equal(t, "Work with chat transcripts", cmd.Short)
+
+	assert.False(t, cmd.HasFlags())
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+	assert.NotNil(t, cmd.PersistentPreRunE)
+
+	assert.True(t, cmd.HasSubCommands())
+	assert.Len(t, cmd.Commands(), 1)
+	assert.Equal(t, "export", cmd.Commands()[0].Name())
+}
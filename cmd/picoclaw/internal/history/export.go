@@ -0,0 +1,133 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/history"
+)
+
+func newExportCommand(loadConfig func() *config.Config) *cobra.Command {
+	var (
+		chat      string
+		out       string
+		format    string
+		since     string
+		until     string
+		agentName string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the transcript of a chat",
+		Long: "Export the messages of a chat and the agents' replies, with their times, from " +
+			"history/ in the agents' workspaces. Dates are YYYY-MM-DD or RFC 3339.",
+		Example: `picoclaw history export --chat telegram:123 --out transcript.md
+picoclaw history export --chat line:U1234 --format json --since 2026-03-01`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			channel, chatID, ok := strings.Cut(chat, ":")
+			if !ok || channel == "" || chatID == "" {
+				return fmt.Errorf("invalid --chat %q: use <channel>:<chat_id>", chat)
+			}
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("invalid --format %q: use markdown or json", format)
+			}
+			var (
+				filter history.Filter
+				err    error
+			)
+			if filter.Since, err = parseDate(since, false); err != nil {
+				return err
+			}
+			if filter.Until, err = parseDate(until, true); err != nil {
+				return err
+			}
+			workspaces, err := internal.AgentWorkspaces(loadConfig(), agentName)
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if out != "" && out != "-" {
+				f, err := os.Create(out)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", out, err)
+				}
+				defer f.Close()
+				w = f
+			}
+			n, err := exportCmd(w, workspaces, channel, chatID, format, filter)
+			if err != nil {
+				return err
+			}
+			if out != "" && out != "-" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "✓ Exported %d messages to %s\n", n, out)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&chat, "chat", "", "Chat to export as <channel>:<chat_id>, e.g. telegram:123")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "File to write (default stdout)")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown or json")
+	cmd.Flags().StringVar(&since, "since", "", "Only export messages on or after this date")
+	cmd.Flags().StringVar(&until, "until", "", "Only export messages up to this date (inclusive for a day)")
+	cmd.Flags().StringVar(&agentName, "agent", "", "Only export what this agent recorded (default: all agents)")
+	_ = cmd.MarkFlagRequired("chat")
+
+	return cmd
+}
+
+// exportCmd writes the chat's transcript, merged from the given agent
+// workspaces, to w and returns how many messages it holds.
+func exportCmd(w io.Writer, workspaces []string, channel, chatID, format string, filter history.Filter) (int, error) {
+	var entries []history.Entry
+	for _, workspace := range workspaces {
+		found, err := history.Read(history.Path(workspace, channel, chatID), filter)
+		if err != nil {
+			return 0, err
+		}
+		entries = append(entries, found...)
+	}
+	slices.SortStableFunc(entries, func(a, b history.Entry) int { return a.Time.Compare(b.Time) })
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("no messages recorded for %s:%s", channel, chatID)
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return len(entries), enc.Encode(entries)
+	}
+	_, err := io.WriteString(w, history.Markdown(channel, chatID, entries))
+	return len(entries), err
+}
+
+// parseDate parses a --since or --until value. A bare date used as the upper
+// bound covers the whole day.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or RFC 3339", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
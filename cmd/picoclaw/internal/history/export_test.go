@@ -0,0 +1,94 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/history"
+)
+
+func TestNewExportSubcommand(t *testing.T) {
+	cmd := newExportCommand(func() *config.Config { return config.DefaultConfig() })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "export", cmd.Use)
+	assert.Equal(t, "Export the transcript of a chat", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+	assert.True(t, cmd.HasExample())
+
+	for _, name := range []string{"chat", "out", "format", "since", "until", "agent"} {
+		assert.NotNil(t, cmd.Flags().Lookup(name), "missing flag %q", name)
+	}
+}
+
+func TestExportCmd(t *testing.T) {
+	workspace := t.TempDir()
+	store := history.NewStore(workspace)
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	require.NoError(t, store.Append("telegram", "123",
+		history.Entry{Time: day, Role: "user", Content: "old question"},
+		history.Entry{Time: day.Add(time.Minute), Role: "assistant", Content: "old answer"},
+		history.Entry{Time: day.AddDate(0, 0, 1), Role: "user", Content: "is the build green?"},
+		history.Entry{Time: day.AddDate(0, 0, 1).Add(time.Minute), Role: "assistant", Agent: "main", Content: "Yes."},
+	))
+
+	since, err := parseDate("2026-03-02", false)
+	require.NoError(t, err)
+
+	var md bytes.Buffer
+	n, err := exportCmd(&md, []string{workspace}, "telegram", "123", "markdown", history.Filter{Since: since})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Contains(t, md.String(), "# Conversation telegram:123")
+	assert.Contains(t, md.String(), "### User · 2026-03-02 09:00:00\n\nis the build green?")
+	assert.Contains(t, md.String(), "### Assistant (main) · 2026-03-02 09:01:00\n\nYes.")
+	assert.NotContains(t, md.String(), "old question")
+
+	var js bytes.Buffer
+	_, err = exportCmd(&js, []string{workspace}, "telegram", "123", "json", history.Filter{})
+	require.NoError(t, err)
+	var entries []history.Entry
+	require.NoError(t, json.Unmarshal(js.Bytes(), &entries))
+	assert.Len(t, entries, 4)
+
+	_, err = exportCmd(&js, []string{workspace}, "telegram", "999", "json", history.Filter{})
+	assert.Error(t, err)
+}
+
+func TestExportCmd_MergesAgentWorkspaces(t *testing.T) {
+	main, coder := t.TempDir(), t.TempDir()
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	require.NoError(t, history.NewStore(main).Append("telegram", "123",
+		history.Entry{Time: day, Role: "user", Content: "first"}))
+	require.NoError(t, history.NewStore(coder).Append("telegram", "123",
+		history.Entry{Time: day.Add(time.Minute), Role: "assistant", Agent: "coder", Content: "second"}))
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = main
+	cfg.Agents.Named = map[string]config.AgentConfig{"coder": {Workspace: coder}}
+	workspaces, err := internal.AgentWorkspaces(cfg, "")
+	require.NoError(t, err)
+
+	var js bytes.Buffer
+	n, err := exportCmd(&js, workspaces, "telegram", "123", "json", history.Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	var entries []history.Entry
+	require.NoError(t, json.Unmarshal(js.Bytes(), &entries))
+	assert.Equal(t, "first", entries[0].Content)
+	assert.Equal(t, "second", entries[1].Content)
+
+	workspaces, err = internal.AgentWorkspaces(cfg, "coder")
+	require.NoError(t, err)
+	assert.Equal(t, []string{coder}, workspaces)
+}
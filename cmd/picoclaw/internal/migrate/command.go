@@ -17,7 +17,10 @@ func NewMigrateCommand() *cobra.Command {
   picoclaw migrate --from openclaw
   picoclaw migrate --dry-run
   picoclaw migrate --refresh
-  picoclaw migrate --force`,
+  picoclaw migrate --force
+  picoclaw migrate --conflict skip
+  picoclaw migrate --from-config /backup/.picoclaw/config.json
+  picoclaw migrate --from-workspace /mnt/old/workspace --workspace-only`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			m := migrate.NewMigrateInstance(opts)
 			result, err := m.Run(opts)
@@ -43,6 +46,13 @@ func NewMigrateCommand() *cobra.Command {
 		"Only migrate workspace files, skip config")
 	cmd.Flags().BoolVar(&opts.Force, "force", false,
 		"Skip confirmation prompts")
+	cmd.Flags().StringVar((*string)(&opts.ConflictStrategy), "conflict", "",
+		"How to handle existing workspace files: skip, overwrite, backup_overwrite, interactive "+
+			"(default: backup_overwrite, or overwrite with --force)")
+	cmd.Flags().StringVar(&opts.FromConfig, "from-config", "",
+		"Migrate from this PicoClaw config file instead of an OpenClaw install")
+	cmd.Flags().StringVar(&opts.FromWorkspace, "from-workspace", "",
+		"Migrate from this PicoClaw workspace (default: the one --from-config points at)")
 	cmd.Flags().StringVar(&opts.SourceHome, "source-home", "",
 		"Override source home directory (default: ~/.openclaw)")
 	cmd.Flags().StringVar(&opts.TargetHome, "target-home", "",
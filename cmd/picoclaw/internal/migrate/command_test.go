@@ -33,6 +33,9 @@ func TestNewMigrateCommand(t *testing.T) {
 	assert.NotNil(t, cmd.Flags().Lookup("config-only"))
 	assert.NotNil(t, cmd.Flags().Lookup("workspace-only"))
 	assert.NotNil(t, cmd.Flags().Lookup("force"))
+	assert.NotNil(t, cmd.Flags().Lookup("conflict"))
+	assert.NotNil(t, cmd.Flags().Lookup("from-config"))
+	assert.NotNil(t, cmd.Flags().Lookup("from-workspace"))
 	assert.NotNil(t, cmd.Flags().Lookup("source-home"))
 	assert.NotNil(t, cmd.Flags().Lookup("target-home"))
 }
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func newListCommand(cfg func() *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List models and their capabilities",
+		Long: "List the models in model_list with the features and limits the agent assumes for them. " +
+			"Known model families come from a built-in registry; capabilities, context_window and " +
+			"max_tokens on a model_list entry override it.",
+		Example: `picoclaw models list`,
+		Args:    cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return listModels(os.Stdout, cfg())
+		},
+	}
+}
+
+func listModels(out io.Writer, cfg *config.Config) error {
+	if len(cfg.ModelList) == 0 {
+		fmt.Fprintln(out, "No models configured in model_list.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tMODEL\tTOOLS\tVISION\tSTREAMING\tCONTEXT\tMAX TOKENS")
+	for i := range cfg.ModelList {
+		mc := &cfg.ModelList[i]
+		info := providers.ModelConfigInfo(mc)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			mc.ModelName, mc.Model,
+			yesNo(info.Has(providers.CapabilityTools)),
+			yesNo(info.Has(providers.CapabilityVision)),
+			yesNo(info.Has(providers.CapabilityStreaming)),
+			tokens(info.ContextWindow), tokens(info.MaxTokens))
+	}
+	return w.Flush()
+}
+
+func yesNo(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return "no"
+}
+
+func tokens(n int) string {
+	if n <= 0 {
+		return "-"
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewListSubcommand(t *testing.T) {
+	cmd := newListCommand(func() *config.Config { return nil })
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "list", cmd.Use)
+	assert.Equal(t, "List models and their capabilities", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasFlags())
+}
+
+func TestListModels(t *testing.T) {
+	cfg := &config.Config{ModelList: []config.ModelConfig{
+		{ModelName: "gpt4", Model: "openai/gpt-4o"},
+		{ModelName: "local", Model: "vllm/my-model", Capabilities: []string{"vision"}, ContextWindow: 4096},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, listModels(&out, cfg))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"NAME", "MODEL", "TOOLS", "VISION", "STREAMING", "CONTEXT", "MAX", "TOKENS"},
+		strings.Fields(lines[0]))
+	assert.Equal(t, []string{"gpt4", "openai/gpt-4o", "yes", "yes", "yes", "128000", "16384"},
+		strings.Fields(lines[1]))
+	assert.Equal(t, []string{"local", "vllm/my-model", "no", "yes", "yes", "4096", "-"},
+		strings.Fields(lines[2]))
+}
@@ -4,6 +4,8 @@ import (
 	"embed"
 
 	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 )
 
 //go:generate cp -r ../../../../workspace .
@@ -11,14 +13,26 @@ import (
 var embeddedFiles embed.FS
 
 func NewOnboardCommand() *cobra.Command {
+	var opts onboardOptions
+
 	cmd := &cobra.Command{
 		Use:     "onboard",
 		Aliases: []string{"o"},
 		Short:   "Initialize picoclaw configuration and workspace",
-		Run: func(cmd *cobra.Command, args []string) {
-			onboard()
+		Example: `
+picoclaw onboard
+picoclaw onboard --non-interactive --config-template fleet.json
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return onboard(internal.GetConfigPath(), opts)
 		},
 	}
 
+	cmd.Flags().BoolVar(&opts.nonInteractive, "non-interactive", false,
+		"Never prompt; keep an existing config and workspace files unless --force")
+	cmd.Flags().StringVar(&opts.template, "config-template", "", "Config file to merge over the defaults")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Overwrite an existing config and workspace files")
+
 	return cmd
 }
@@ -18,12 +18,15 @@ func TestNewOnboardCommand(t *testing.T) {
 	assert.Len(t, cmd.Aliases, 1)
 	assert.True(t, cmd.HasAlias("o"))
 
-	assert.NotNil(t, cmd.Run)
-	assert.Nil(t, cmd.RunE)
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
 
 	assert.Nil(t, cmd.PersistentPreRun)
 	assert.Nil(t, cmd.PersistentPostRun)
 
-	assert.False(t, cmd.HasFlags())
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("non-interactive"))
+	assert.NotNil(t, cmd.Flags().Lookup("config-template"))
+	assert.NotNil(t, cmd.Flags().Lookup("force"))
 	assert.False(t, cmd.HasSubCommands())
 }
@@ -10,28 +10,65 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 )
 
-func onboard() {
-	configPath := internal.GetConfigPath()
-
-	if _, err := os.Stat(configPath); err == nil {
-		fmt.Printf("Config already exists at %s\n", configPath)
-		fmt.Print("Overwrite? (y/n): ")
-		var response string
-		fmt.Scanln(&response)
-		if response != "y" {
-			fmt.Println("Aborted.")
-			return
+// onboardOptions are the flags of the onboard command.
+type onboardOptions struct {
+	nonInteractive bool   // never prompt; keep existing files unless force
+	template       string // config file merged over the defaults
+	force          bool   // overwrite the config and workspace files
+}
+
+func onboard(configPath string, opts onboardOptions) error {
+	cfg := config.DefaultConfig()
+	if opts.template != "" {
+		var err error
+		if cfg, err = config.LoadTemplate(opts.template); err != nil {
+			return fmt.Errorf("failed to load config template %s: %w", opts.template, err)
 		}
 	}
 
-	cfg := config.DefaultConfig()
-	if err := config.SaveConfig(configPath, cfg); err != nil {
-		fmt.Printf("Error saving config: %v\n", err)
-		os.Exit(1)
+	writeConfig := true
+	if _, err := os.Stat(configPath); err == nil && !opts.force {
+		if opts.nonInteractive {
+			fmt.Printf("Config already exists at %s, keeping it (use --force to overwrite)\n", configPath)
+			writeConfig = false
+		} else {
+			fmt.Printf("Config already exists at %s\n", configPath)
+			fmt.Print("Overwrite? (y/n): ")
+			var response string
+			fmt.Scanln(&response)
+			if response != "y" {
+				fmt.Println("Aborted.")
+				return nil
+			}
+		}
+	}
+
+	if writeConfig {
+		if err := config.SaveConfig(configPath, cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+	} else {
+		// Set up the workspace the kept config points at.
+		existing, err := config.LoadConfig(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load existing config: %w", err)
+		}
+		cfg = existing
 	}
 
 	workspace := cfg.WorkspacePath()
-	createWorkspaceTemplates(workspace)
+	written, kept, err := copyEmbeddedToTarget(workspace, !opts.nonInteractive || opts.force)
+	if err != nil {
+		return fmt.Errorf("failed to copy workspace templates: %w", err)
+	}
+
+	if opts.nonInteractive {
+		if writeConfig {
+			fmt.Printf("Config written to %s\n", configPath)
+		}
+		fmt.Printf("Workspace ready at %s (%d files written, %d kept)\n", workspace, written, kept)
+		return nil
+	}
 
 	fmt.Printf("%s picoclaw is ready!\n", internal.Logo)
 	fmt.Println("\nNext steps:")
@@ -44,23 +81,19 @@ func onboard() {
 	fmt.Println("     See README.md for 17+ supported providers.")
 	fmt.Println("")
 	fmt.Println("  2. Chat: picoclaw agent -m \"Hello!\"")
+	return nil
 }
 
-func createWorkspaceTemplates(workspace string) {
-	err := copyEmbeddedToTarget(workspace)
-	if err != nil {
-		fmt.Printf("Error copying workspace templates: %v\n", err)
-	}
-}
-
-func copyEmbeddedToTarget(targetDir string) error {
+// copyEmbeddedToTarget writes the workspace templates to targetDir. Files
+// that already exist are kept unless overwrite is set.
+func copyEmbeddedToTarget(targetDir string, overwrite bool) (written, kept int, err error) {
 	// Ensure target directory exists
 	if err := os.MkdirAll(targetDir, 0o755); err != nil {
-		return fmt.Errorf("Failed to create target directory: %w", err)
+		return 0, 0, fmt.Errorf("Failed to create target directory: %w", err)
 	}
 
 	// Walk through all files in embed.FS
-	err := fs.WalkDir(embeddedFiles, "workspace", func(path string, d fs.DirEntry, err error) error {
+	err = fs.WalkDir(embeddedFiles, "workspace", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -84,6 +117,13 @@ func copyEmbeddedToTarget(targetDir string) error {
 		// Build target file path
 		targetPath := filepath.Join(targetDir, new_path)
 
+		if !overwrite {
+			if _, err := os.Stat(targetPath); err == nil {
+				kept++
+				return nil
+			}
+		}
+
 		// Ensure target file's directory exists
 		if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
 			return fmt.Errorf("Failed to create directory %s: %w", filepath.Dir(targetPath), err)
@@ -93,9 +133,10 @@ func copyEmbeddedToTarget(targetDir string) error {
 		if err := os.WriteFile(targetPath, data, 0o644); err != nil {
 			return fmt.Errorf("Failed to write file %s: %w", targetPath, err)
 		}
+		written++
 
 		return nil
 	})
 
-	return err
+	return written, kept, err
 }
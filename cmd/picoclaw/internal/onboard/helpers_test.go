@@ -4,12 +4,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 func TestCopyEmbeddedToTargetUsesAgentsMarkdown(t *testing.T) {
 	targetDir := t.TempDir()
 
-	if err := copyEmbeddedToTarget(targetDir); err != nil {
+	if _, _, err := copyEmbeddedToTarget(targetDir, true); err != nil {
 		t.Fatalf("copyEmbeddedToTarget() error = %v", err)
 	}
 
@@ -23,3 +25,67 @@ func TestCopyEmbeddedToTargetUsesAgentsMarkdown(t *testing.T) {
 		t.Fatalf("expected legacy file %s to be absent, got err=%v", legacyPath, err)
 	}
 }
+
+func TestOnboardNonInteractiveIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	workspace := filepath.Join(dir, "workspace")
+	template := filepath.Join(dir, "template.json")
+	tmpl := `{"agents":{"defaults":{"workspace":"` + workspace + `","max_tool_iterations":7}}}`
+	if err := os.WriteFile(template, []byte(tmpl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := onboardOptions{nonInteractive: true, template: template}
+
+	if err := onboard(configPath, opts); err != nil {
+		t.Fatalf("onboard() error = %v", err)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Agents.Defaults.MaxToolIterations != 7 {
+		t.Errorf("MaxToolIterations = %d, want 7 from the template", cfg.Agents.Defaults.MaxToolIterations)
+	}
+	if cfg.Agents.Defaults.MaxTokens != config.DefaultConfig().Agents.Defaults.MaxTokens {
+		t.Errorf("MaxTokens = %d, want the default", cfg.Agents.Defaults.MaxTokens)
+	}
+
+	// A second run keeps edited files.
+	agentsPath := filepath.Join(workspace, "AGENTS.md")
+	if err := os.WriteFile(agentsPath, []byte("edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte(`{"agents":{"defaults":{"workspace":"`+workspace+`"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := onboard(configPath, opts); err != nil {
+		t.Fatalf("second onboard() error = %v", err)
+	}
+	if data, _ := os.ReadFile(agentsPath); string(data) != "edited" {
+		t.Errorf("AGENTS.md = %q, want it kept", data)
+	}
+	if cfg, _ := config.LoadConfig(configPath); cfg.Agents.Defaults.MaxToolIterations == 7 {
+		t.Error("existing config was overwritten without --force")
+	}
+
+	// --force rewrites both.
+	opts.force = true
+	if err := onboard(configPath, opts); err != nil {
+		t.Fatalf("forced onboard() error = %v", err)
+	}
+	if data, _ := os.ReadFile(agentsPath); string(data) == "edited" {
+		t.Error("AGENTS.md was kept with --force")
+	}
+	if cfg, _ := config.LoadConfig(configPath); cfg.Agents.Defaults.MaxToolIterations != 7 {
+		t.Error("config was not rewritten with --force")
+	}
+}
+
+func TestOnboardNonInteractiveBadTemplate(t *testing.T) {
+	dir := t.TempDir()
+	opts := onboardOptions{nonInteractive: true, template: filepath.Join(dir, "missing.json")}
+	if err := onboard(filepath.Join(dir, "config.json"), opts); err == nil {
+		t.Fatal("onboard() with a missing template succeeded")
+	}
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats of list commands. Commands that list things take
+// --format: table for people (the default), json and csv for scripts.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// AddFormatFlag adds the --format flag of a list command, defaulting to
+// table.
+func AddFormatFlag(cmd *cobra.Command, format *string) {
+	cmd.Flags().StringVar(format, "format", FormatTable, "Output format: table, json or csv")
+}
+
+// CheckFormat returns an error for an unknown --format value.
+func CheckFormat(format string) error {
+	switch format {
+	case FormatTable, FormatJSON, FormatCSV:
+		return nil
+	}
+	return fmt.Errorf("invalid --format %q: use table, json or csv", format)
+}
+
+// WriteJSON writes v as indented JSON, ready for piping to jq.
+func WriteJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteCSV writes a header row followed by rows.
+func WriteCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,22 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{FormatTable, FormatJSON, FormatCSV} {
+		assert.NoError(t, CheckFormat(format), format)
+	}
+	assert.Error(t, CheckFormat("yaml"))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, []string{"name", "note"}, [][]string{{"a", "x, y"}}))
+	assert.Equal(t, "name,note\na,\"x, y\"\n", buf.String())
+}
@@ -0,0 +1,22 @@
+package prompt
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewPromptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Inspect the system prompt",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(
+		newShowCommand(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,21 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPromptCommand(t *testing.T) {
+	cmd := NewPromptCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "prompt", cmd.Use)
+	assert.Equal(t, "Inspect the system prompt", cmd.Short)
+
+	assert.True(t, cmd.HasSubCommands())
+	assert.Len(t, cmd.Commands(), 1)
+	assert.Equal(t, "show", cmd.Commands()[0].Name())
+}
@@ -0,0 +1,121 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+func newShowCommand() *cobra.Command {
+	var (
+		channel   string
+		chatID    string
+		agentName string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the system prompt the model receives",
+		Long: "Print the system prompt as the model would receive it for a chat: the global " +
+			"IDENTITY.md, the agent's prompt file and workspace files, the channel and chat " +
+			"personas, and the per-request context. Secrets from the config are masked.",
+		Example: `picoclaw prompt show
+picoclaw prompt show --channel telegram --chat 123456789
+picoclaw prompt show --agent coder`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if chatID != "" && channel == "" {
+				return fmt.Errorf("--chat requires --channel")
+			}
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			return showCmd(cmd.OutOrStdout(), cfg, agentName, channel, chatID)
+		},
+	}
+
+	cmd.Flags().StringVar(&channel, "channel", "", "Channel to build the prompt for (e.g. telegram)")
+	cmd.Flags().StringVar(&chatID, "chat", "", "Chat ID to build the prompt for")
+	cmd.Flags().StringVar(&agentName, "agent", "", "Named agent to build the prompt for (default: the default agent)")
+
+	return cmd
+}
+
+func showCmd(out io.Writer, cfg *config.Config, agentName, channel, chatID string) error {
+	if agentName == "" {
+		agentName = routing.NewRouteResolver(cfg).DefaultAgentID()
+	}
+	ac, err := cfg.Agents.Lookup(agentName)
+	if err != nil {
+		return err
+	}
+	cb := agent.NewAgentContextBuilder(&ac, cfg)
+	messages := cb.BuildMessages(nil, "", "", nil, channel, chatID)
+	if len(messages) == 0 {
+		return fmt.Errorf("no system prompt was built")
+	}
+	fmt.Fprintln(out, redactSecrets(messages[0].Content, configSecrets(cfg)))
+	return nil
+}
+
+// secretFieldSuffixes mark config fields whose values must not be printed.
+var secretFieldSuffixes = []string{"key", "token", "secret", "password"}
+
+// apiKeyRe matches common API key formats pasted into workspace files.
+var apiKeyRe = regexp.MustCompile(`\b(?:sk|pk|ghp|gho|xox[abp])[-_][A-Za-z0-9_-]{16,}`)
+
+// configSecrets returns the values of secret fields in cfg, longest first so
+// a secret containing another is masked whole.
+func configSecrets(cfg *config.Config) []string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil
+	}
+	var secrets []string
+	var walk func(key string, v any)
+	walk = func(key string, v any) {
+		switch val := v.(type) {
+		case map[string]any:
+			for k, child := range val {
+				walk(k, child)
+			}
+		case []any:
+			for _, child := range val {
+				walk(key, child)
+			}
+		case string:
+			name := strings.ToLower(key)
+			for _, suffix := range secretFieldSuffixes {
+				if strings.HasSuffix(name, suffix) && len(val) >= 8 {
+					secrets = append(secrets, val)
+					break
+				}
+			}
+		}
+	}
+	walk("", tree)
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+	return secrets
+}
+
+func redactSecrets(text string, secrets []string) string {
+	for _, s := range secrets {
+		text = strings.ReplaceAll(text, s, "[REDACTED]")
+	}
+	return apiKeyRe.ReplaceAllString(text, "[REDACTED]")
+}
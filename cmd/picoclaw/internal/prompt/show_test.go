@@ -0,0 +1,63 @@
+package prompt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewShowSubcommand(t *testing.T) {
+	cmd := newShowCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "Print the system prompt the model receives", cmd.Short)
+	assert.True(t, cmd.HasExample())
+	assert.NotNil(t, cmd.Flags().Lookup("channel"))
+	assert.NotNil(t, cmd.Flags().Lookup("chat"))
+	assert.NotNil(t, cmd.Flags().Lookup("agent"))
+}
+
+func TestShowCmd_PersonasAndSecrets(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "personas"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "AGENTS.md"),
+		[]byte("Use token 123456:telegram-bot-token and sk-abcdefghijklmnopqrstuvwx."), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "personas", "telegram.md"),
+		[]byte("Keep replies short."), 0o644))
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = workspace
+	cfg.Channels.Telegram.Token = "123456:telegram-bot-token"
+
+	var out bytes.Buffer
+	require.NoError(t, showCmd(&out, cfg, "", "telegram", "42"))
+
+	assert.Contains(t, out.String(), "Keep replies short.")
+	assert.Contains(t, out.String(), "Chat ID: 42")
+	assert.NotContains(t, out.String(), "telegram-bot-token")
+	assert.NotContains(t, out.String(), "sk-abcdefghijklmnopqrstuvwx")
+}
+
+func TestShowCmd_NamedAgent(t *testing.T) {
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "CODER.md"), []byte("You review Go code."), 0o644))
+
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	cfg.Agents.Named = map[string]config.AgentConfig{
+		"coder": {Workspace: workspace, SystemPromptFile: "CODER.md"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, showCmd(&out, cfg, "coder", "", ""))
+	assert.Contains(t, out.String(), "You review Go code.")
+
+	assert.Error(t, showCmd(&out, cfg, "nobody", "", ""))
+}
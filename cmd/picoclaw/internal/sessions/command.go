@@ -0,0 +1,36 @@
+package sessions
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func NewSessionsCommand() *cobra.Command {
+	var cfg *config.Config
+
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "Inspect conversation sessions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			var err error
+			if cfg, err = internal.LoadConfig(); err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(
+		newShowCommand(func() *config.Config { return cfg }),
+	)
+
+	return cmd
+}
@@ -0,0 +1,27 @@
+package sessions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSessionsCommand(t *testing.T) {
+	cmd := NewSessionsCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "sessions", cmd.Use)
+	assert.Equal(t, "Inspect conversation sessions", cmd.Short)
+
+	assert.False(t, cmd.HasFlags())
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+	assert.NotNil(t, cmd.PersistentPreRunE)
+
+	assert.True(t, cmd.HasSubCommands())
+	assert.Len(t, cmd.Commands(), 1)
+	assert.Equal(t, "show", cmd.Commands()[0].Name())
+}
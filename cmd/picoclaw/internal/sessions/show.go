@@ -0,0 +1,92 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+func newShowCommand(loadConfig func() *config.Config) *cobra.Command {
+	var agentName string
+
+	cmd := &cobra.Command{
+		Use:   "show <session-key>",
+		Short: "Show a session and its summary",
+		Long: "Show a session's size and the summary of its older turns that is added to " +
+			"the prompt. Session keys appear in the gateway log as session_key. The session " +
+			"is read from the workspace of the agent named in the key.",
+		Example: `picoclaw sessions show agent:main:main
+picoclaw sessions show agent:main:telegram:direct:123456789
+picoclaw sessions show --agent coder cli:default`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspace, err := sessionWorkspace(loadConfig(), agentName, args[0])
+			if err != nil {
+				return err
+			}
+			return showCmd(cmd.OutOrStdout(), workspace, args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&agentName, "agent", "", "Agent whose workspace holds the session (default: from the key)")
+
+	return cmd
+}
+
+// sessionWorkspace returns the workspace of agentName or, when it is empty,
+// of the configured agent named in an "agent:<id>:..." key, falling back to
+// the default agent.
+func sessionWorkspace(cfg *config.Config, agentName, key string) (string, error) {
+	if agentName == "" {
+		if parsed := routing.ParseAgentSessionKey(key); parsed != nil {
+			if _, err := cfg.Agents.Lookup(parsed.AgentID); err == nil {
+				agentName = parsed.AgentID
+			}
+		}
+	}
+	workspaces, err := internal.AgentWorkspaces(cfg, agentName)
+	if err != nil {
+		return "", err
+	}
+	return workspaces[0], nil
+}
+
+func showCmd(out io.Writer, workspace, key string) error {
+	sess, err := session.Load(filepath.Join(workspace, "sessions"), key)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no session %q in %s", key, workspace)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session %q: %w", key, err)
+	}
+
+	fmt.Fprintf(out, "Session: %s\n", sess.Key)
+	fmt.Fprintf(out, "Messages: %d (updated %s)\n", len(sess.Messages), sess.Updated.Format("2006-01-02 15:04"))
+	if notes := agent.SessionNotesPath(workspace, key); fileExists(notes) {
+		fmt.Fprintf(out, "Notes: %s\n", notes)
+	}
+	fmt.Fprintln(out)
+
+	if summary := strings.TrimSpace(sess.Summary); summary != "" {
+		fmt.Fprintln(out, summary)
+	} else {
+		fmt.Fprintln(out, "No summary yet; older turns are summarized once the session grows long.")
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
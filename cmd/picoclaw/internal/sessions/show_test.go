@@ -0,0 +1,72 @@
+package sessions
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/agent"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/session"
+)
+
+func TestShowCmd(t *testing.T) {
+	workspace := t.TempDir()
+	key := "agent:main:telegram:direct:123"
+
+	sm := session.NewSessionManager(filepath.Join(workspace, "sessions"))
+	sm.AddMessage(key, "user", "hello")
+	sm.AddMessage(key, "assistant", "hi")
+	require.NoError(t, sm.Save(key))
+
+	var out bytes.Buffer
+	require.NoError(t, showCmd(&out, workspace, key))
+	assert.Contains(t, out.String(), "Session: "+key)
+	assert.Contains(t, out.String(), "Messages: 2")
+	assert.Contains(t, out.String(), "No summary yet")
+
+	sm.SetSummary(key, "### Facts\n- Lives in Berlin")
+	require.NoError(t, sm.Save(key))
+	notes := agent.SessionNotesPath(workspace, key)
+	require.NoError(t, os.MkdirAll(filepath.Dir(notes), 0o755))
+	require.NoError(t, os.WriteFile(notes, []byte("## 2026-10-01 09:00\n"), 0o600))
+
+	out.Reset()
+	require.NoError(t, showCmd(&out, workspace, key))
+	assert.Contains(t, out.String(), "### Facts\n- Lives in Berlin")
+	assert.Contains(t, out.String(), "Notes: "+notes)
+
+	err := showCmd(&out, workspace, "agent:main:nope")
+	assert.ErrorContains(t, err, `no session "agent:main:nope"`)
+}
+
+func TestSessionWorkspace(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = "/srv/main"
+	cfg.Agents.Named = map[string]config.AgentConfig{
+		"main":  {Default: true},
+		"coder": {Workspace: "/srv/coder"},
+	}
+
+	tests := []struct {
+		agent, key, want string
+	}{
+		{"", "agent:coder:telegram:direct:1", "/srv/coder"},
+		{"", "agent:main:main", "/srv/main"},
+		{"", "agent:gone:main", "/srv/main"},
+		{"", "cli:default", "/srv/main"},
+		{"coder", "cli:default", "/srv/coder"},
+	}
+	for _, tt := range tests {
+		got, err := sessionWorkspace(cfg, tt.agent, tt.key)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "agent %q key %q", tt.agent, tt.key)
+	}
+
+	_, err := sessionWorkspace(cfg, "nobody", "cli:default")
+	assert.Error(t, err)
+}
@@ -14,6 +14,7 @@ type deps struct {
 	workspace    string
 	installer    *skills.SkillInstaller
 	skillsLoader *skills.SkillsLoader
+	skillEnv     map[string]string
 }
 
 func NewSkillsCommand() *cobra.Command {
@@ -29,6 +30,7 @@ func NewSkillsCommand() *cobra.Command {
 			}
 
 			d.workspace = cfg.WorkspacePath()
+			d.skillEnv = cfg.Tools.Skills.Env
 			d.installer = skills.NewSkillInstaller(d.workspace)
 
 			// get global config directory and builtin skills directory
@@ -58,6 +60,10 @@ func NewSkillsCommand() *cobra.Command {
 		return d.skillsLoader, nil
 	}
 
+	envFn := func() map[string]string {
+		return d.skillEnv
+	}
+
 	workspaceFn := func() (string, error) {
 		if d.workspace == "" {
 			return "", fmt.Errorf("workspace is not initialized")
@@ -67,12 +73,16 @@ func NewSkillsCommand() *cobra.Command {
 
 	cmd.AddCommand(
 		newListCommand(loaderFn),
-		newInstallCommand(installerFn),
+		newInstallCommand(installerFn, envFn),
 		newInstallBuiltinCommand(workspaceFn),
 		newListBuiltinCommand(),
+		newLinkCommand(installerFn),
+		newNewCommand(workspaceFn),
+		newScaffoldCommand(workspaceFn),
 		newRemoveCommand(installerFn),
 		newSearchCommand(),
-		newShowCommand(loaderFn),
+		newPublishCommand(),
+		newShowCommand(loaderFn, envFn),
 	)
 
 	return cmd
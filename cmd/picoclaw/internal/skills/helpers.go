@@ -2,14 +2,19 @@ package skills
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
-	"io"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/skills"
 	"github.com/sipeed/picoclaw/pkg/utils"
@@ -35,7 +40,11 @@ func skillsListCmd(loader *skills.SkillsLoader) {
 	}
 }
 
-func skillsInstallCmd(installer *skills.SkillInstaller, repo string) error {
+func skillsInstallCmd(installer *skills.SkillInstaller, repo string, env map[string]string) error {
+	if path, ok := strings.CutPrefix(repo, localSkillPrefix); ok {
+		return skillsInstallLocalCmd(installer, path, env)
+	}
+
 	fmt.Printf("Installing skill from %s...\n", repo)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -46,6 +55,84 @@ func skillsInstallCmd(installer *skills.SkillInstaller, repo string) error {
 	}
 
 	fmt.Printf("\u2713 Skill '%s' installed successfully!\n", filepath.Base(repo))
+	warnMissingEnv(installer.SkillDir(filepath.Base(repo)), env)
+
+	return nil
+}
+
+// localSkillPrefix marks an install argument as a local directory path.
+const localSkillPrefix = "local:"
+
+func skillsInstallLocalCmd(installer *skills.SkillInstaller, path string, env map[string]string) error {
+	fmt.Printf("Installing skill from %s...\n", path)
+
+	if err := installer.InstallLocal(path); err != nil {
+		return fmt.Errorf("failed to install skill: %w", err)
+	}
+
+	fmt.Printf("\u2713 Skill '%s' installed successfully!\n", localSkillName(path))
+	warnMissingEnv(installer.SkillDir(localSkillName(path)), env)
+
+	return nil
+}
+
+// warnMissingEnv warns about the required variables the skill in dir
+// declares that are not set, so a missing key shows up at install time.
+func warnMissingEnv(dir string, env map[string]string) {
+	m, err := skills.LoadSkillManifest(dir)
+	if err != nil {
+		return
+	}
+	missing := skills.MissingEnv(m.Env, env)
+	for _, v := range missing {
+		fmt.Printf("\u26a0\ufe0f  Warning: skill '%s' needs %s, which is not set.", m.Name, v.Name)
+		if v.Description != "" {
+			fmt.Printf(" (%s)", v.Description)
+		}
+		fmt.Println()
+	}
+	if len(missing) > 0 {
+		fmt.Println("  Set it in tools.skills.env in config.json.")
+	}
+}
+
+// localSkillName returns the skill name a local path installs as.
+func localSkillName(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return filepath.Base(abs)
+	}
+	return filepath.Base(path)
+}
+
+func skillsLinkCmd(installer *skills.SkillInstaller, path string) error {
+	if err := installer.LinkLocal(path); err != nil {
+		return fmt.Errorf("failed to link skill: %w", err)
+	}
+
+	fmt.Printf("\u2713 Skill '%s' linked to %s\n", localSkillName(path), path)
+	fmt.Println("  Edits to the source directory take effect immediately.")
+
+	return nil
+}
+
+func skillsNewCmd(workspace, name string, opts skills.ScaffoldOptions) error {
+	opts.MinPicoClawVersion = internal.GetVersion()
+	dir, err := skills.ScaffoldSkill(filepath.Join(workspace, "skills"), name, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create skill: %w", err)
+	}
+
+	fmt.Printf("\u2713 Skill '%s' created at %s\n", name, dir)
+	switch {
+	case opts.Lang != "":
+		fmt.Println("  Fill in the placeholders in SKILL.md, implement the entry script")
+		fmt.Println("  and add test cases to SKILL_TEST.json.")
+	case opts.WithTool:
+		fmt.Println("  Edit SKILL.md to describe when and how the agent should use it.")
+		fmt.Printf("  Implement the tool in scripts/%s.sh.\n", name)
+	default:
+		fmt.Println("  Edit SKILL.md to describe when and how the agent should use it.")
+	}
 
 	return nil
 }
@@ -64,14 +151,16 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 
 	fmt.Printf("Installing skill '%s' from %s registry...\n", slug, registryName)
 
-	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
-		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
-		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
-	})
+	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfigFrom(cfg))
 
 	registry := registryMgr.GetRegistry(registryName)
 	if registry == nil {
-		return fmt.Errorf("✗  registry '%s' not found or not enabled. check your config.json.", registryName)
+		names := registryMgr.Names()
+		if len(names) == 0 {
+			return fmt.Errorf("✗  registry '%s' not found: no registries are enabled. check your config.json.", registryName)
+		}
+		return fmt.Errorf("✗  registry '%s' not found or not enabled (configured: %s). check your config.json.",
+			registryName, strings.Join(names, ", "))
 	}
 
 	workspace := cfg.WorkspacePath()
@@ -81,14 +170,18 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 		return fmt.Errorf("\u2717 skill '%s' already installed at %s", slug, targetDir)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// Each request is bounded by the registry's timeout and retried per its
+	// retry settings; Ctrl+C still cleans up a partial install.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
 	if err = os.MkdirAll(filepath.Join(workspace, "skills"), 0o755); err != nil {
 		return fmt.Errorf("\u2717 failed to create skills directory: %v", err)
 	}
 
-	result, err := registry.DownloadAndInstall(ctx, slug, "", targetDir)
+	progress := &downloadProgress{w: os.Stdout, now: time.Now}
+	result, err := registry.DownloadAndInstall(skills.WithDownloadProgress(ctx, progress.update), slug, "", targetDir)
+	progress.finish()
 	if err != nil {
 		rmErr := os.RemoveAll(targetDir)
 		if rmErr != nil {
@@ -114,6 +207,7 @@ func skillsInstallFromRegistry(cfg *config.Config, registryName, slug string) er
 	if result.Summary != "" {
 		fmt.Printf("  %s\n", result.Summary)
 	}
+	warnMissingEnv(targetDir, cfg.Tools.Skills.Env)
 
 	return nil
 }
@@ -156,7 +250,7 @@ func skillsInstallBuiltinCmd(workspace string) {
 			continue
 		}
 
-		if err := copyDirectory(builtinPath, workspacePath); err != nil {
+		if err := skills.CopyDirectory(builtinPath, workspacePath); err != nil {
 			fmt.Printf("✗ Failed to copy %s: %v\n", skillName, err)
 		}
 	}
@@ -226,10 +320,7 @@ func skillsSearchCmd(query string) {
 		return
 	}
 
-	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
-		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
-		ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
-	})
+	registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfigFrom(cfg))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -259,7 +350,7 @@ func skillsSearchCmd(query string) {
 	}
 }
 
-func skillsShowCmd(loader *skills.SkillsLoader, skillName string) {
+func skillsShowCmd(loader *skills.SkillsLoader, skillName string, env map[string]string) {
 	content, ok := loader.LoadSkill(skillName)
 	if !ok {
 		fmt.Printf("✗ Skill '%s' not found\n", skillName)
@@ -269,38 +360,101 @@ func skillsShowCmd(loader *skills.SkillsLoader, skillName string) {
 	fmt.Printf("\n📦 Skill: %s\n", skillName)
 	fmt.Println("----------------------")
 	fmt.Println(content)
-}
 
-func copyDirectory(src, dst string) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	m, ok := loader.GetManifest(skillName)
+	if !ok {
+		return
+	}
+	if len(m.Env) > 0 {
+		missing := make(map[string]bool)
+		for _, v := range skills.MissingEnv(m.Env, env) {
+			missing[v.Name] = true
 		}
-
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
+		fmt.Printf("\nEnvironment (%d):\n", len(m.Env))
+		for _, v := range m.Env {
+			state := "set"
+			switch {
+			case missing[v.Name]:
+				state = "⚠ not set, required"
+			case env[v.Name] == "" && os.Getenv(v.Name) == "":
+				state = "not set, optional"
+			}
+			fmt.Printf("  %s: %s\n", v.Name, state)
+			if v.Description != "" {
+				fmt.Printf("    %s\n", v.Description)
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Println("  Set required variables in tools.skills.env in config.json.")
 		}
+	}
+	if len(m.Commands) == 0 {
+		return
+	}
+	names := slices.Sorted(maps.Keys(m.Commands))
+	fmt.Printf("\nCommands (%d):\n", len(names))
+	for _, name := range names {
+		cmd := m.Commands[name]
+		fmt.Printf("  %s (tool %s)\n", name, skills.CommandToolName(m.Name, name))
+		if cmd.Description != "" {
+			fmt.Printf("    %s\n", cmd.Description)
+		}
+		fmt.Printf("    Runs: %s\n", cmd.Command)
+		if props, _ := cmd.Schema()["properties"].(map[string]any); len(props) > 0 {
+			fmt.Printf("    Parameters: %s\n", strings.Join(slices.Sorted(maps.Keys(props)), ", "))
+		}
+	}
+}
 
-		dstPath := filepath.Join(dst, relPath)
+func skillsPublishCmd(registryName, version, dir string) error {
+	if registryName != "clawhub" {
+		return fmt.Errorf("registry %q does not support publishing", registryName)
+	}
 
-		if info.IsDir() {
-			return os.MkdirAll(dstPath, info.Mode())
-		}
+	cred, err := auth.GetCredential("clawhub")
+	if err != nil || cred == nil || cred.AccessToken == "" {
+		return fmt.Errorf("not logged in to ClawHub.\nrun: picoclaw auth login --provider clawhub")
+	}
 
-		srcFile, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer srcFile.Close()
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
-		dstFile, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
-		if err != nil {
-			return err
-		}
-		defer dstFile.Close()
+	hubCfg := skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub)
+	hubCfg.AuthToken = cred.AccessToken
+	registry := skills.NewClawHubRegistry(hubCfg)
 
-		_, err = io.Copy(dstFile, srcFile)
+	keyPath := filepath.Join(filepath.Dir(internal.GetConfigPath()), "keys", "skill_signing.pem")
+	key, created, err := skills.LoadOrCreateSigningKey(keyPath)
+	if err != nil {
 		return err
-	})
+	}
+	if created {
+		fmt.Printf("Generated a new signing key at %s\n", keyPath)
+		fmt.Printf("  Public key: %s\n", base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)))
+		fmt.Println("  Back it up: skills published with another key will not be recognized as yours.")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid skill path: %w", err)
+	}
+
+	fmt.Printf("Publishing skill from %s to %s...\n", absDir, registry.Name())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	publisher := skills.NewSkillPublisher(registry, key)
+	result, err := publisher.Publish(ctx, absDir, version)
+	if err != nil {
+		return fmt.Errorf("failed to publish skill: %w", err)
+	}
+
+	fmt.Printf("\u2713 Published %s %s\n", result.Slug, result.Version)
+	if result.URL != "" {
+		fmt.Printf("  %s\n", result.URL)
+	}
+	return nil
 }
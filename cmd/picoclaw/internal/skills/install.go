@@ -9,7 +9,10 @@ import (
 	"github.com/sipeed/picoclaw/pkg/skills"
 )
 
-func newInstallCommand(installerFn func() (*skills.SkillInstaller, error)) *cobra.Command {
+func newInstallCommand(
+	installerFn func() (*skills.SkillInstaller, error),
+	envFn func() map[string]string,
+) *cobra.Command {
 	var registry string
 
 	cmd := &cobra.Command{
@@ -17,18 +20,20 @@ func newInstallCommand(installerFn func() (*skills.SkillInstaller, error)) *cobr
 		Short: "Install skill from GitHub",
 		Example: `
 picoclaw skills install sipeed/picoclaw-skills/weather
+picoclaw skills install local:/path/to/my-skill
 picoclaw skills install --registry clawhub github
+picoclaw skills install --registry internal weather
 `,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if registry != "" {
-				if len(args) != 2 {
-					return fmt.Errorf("when --registry is set, exactly 2 arguments are required: <name> <slug>")
+				if len(args) != 1 {
+					return fmt.Errorf("when --registry is set, exactly 1 argument is required: <slug>")
 				}
 				return nil
 			}
 
 			if len(args) != 1 {
-				return fmt.Errorf("exactly 1 argument is required: <github>")
+				return fmt.Errorf("exactly 1 argument is required: <github> or local:<path>")
 			}
 
 			return nil
@@ -45,14 +50,14 @@ picoclaw skills install --registry clawhub github
 					return err
 				}
 
-				return skillsInstallFromRegistry(cfg, args[0], args[1])
+				return skillsInstallFromRegistry(cfg, registry, args[0])
 			}
 
-			return skillsInstallCmd(installer, args[0])
+			return skillsInstallCmd(installer, args[0], envFn())
 		},
 	}
 
-	cmd.Flags().StringVar(&registry, "registry", "", "Install from registry: --registry <name> <slug>")
+	cmd.Flags().StringVar(&registry, "registry", "", "Install from the configured registry <name>: --registry <name> <slug>")
 
 	return cmd
 }
@@ -8,7 +8,7 @@ import (
 )
 
 func TestNewInstallSubcommand(t *testing.T) {
-	cmd := newInstallCommand(nil)
+	cmd := newInstallCommand(nil, nil)
 
 	require.NotNil(t, cmd)
 
@@ -0,0 +1,28 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newLinkCommand(installerFn func() (*skills.SkillInstaller, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "link",
+		Short: "Symlink a local skill directory into the workspace",
+		Args:  cobra.ExactArgs(1),
+		Example: `
+picoclaw skills link ./my-skill
+picoclaw skills link /path/to/my-skill
+`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			installer, err := installerFn()
+			if err != nil {
+				return err
+			}
+			return skillsLinkCmd(installer, args[0])
+		},
+	}
+
+	return cmd
+}
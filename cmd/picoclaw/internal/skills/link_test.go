@@ -0,0 +1,25 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLinkSubcommand(t *testing.T) {
+	cmd := newLinkCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "link", cmd.Use)
+	assert.Equal(t, "Symlink a local skill directory into the workspace", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+	assert.False(t, cmd.HasFlags())
+	assert.Len(t, cmd.Aliases, 0)
+}
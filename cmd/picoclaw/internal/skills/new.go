@@ -0,0 +1,40 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newNewCommand(workspaceFn func() (string, error)) *cobra.Command {
+	var opts skills.ScaffoldOptions
+
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Scaffold a new skill in the workspace",
+		Args:  cobra.ExactArgs(1),
+		Example: `
+picoclaw skills new my-skill
+picoclaw skills new my-skill --description "Look up train times" --tags travel,rail
+picoclaw skills new my-skill --with-tool
+picoclaw skills new my-skill --lang python
+`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			workspace, err := workspaceFn()
+			if err != nil {
+				return err
+			}
+			return skillsNewCmd(workspace, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Skill description")
+	cmd.Flags().StringVar(&opts.Author, "author", "", "Skill author")
+	cmd.Flags().StringSliceVar(&opts.Tags, "tags", nil, "Comma-separated tags")
+	cmd.Flags().BoolVar(&opts.WithTool, "with-tool", false, "Generate an executable tool stub in scripts/")
+	cmd.Flags().StringVar(&opts.Lang, "lang", "",
+		"Generate an entry script, SKILL_TEST.json and full frontmatter: python, shell or go")
+	cmd.MarkFlagsMutuallyExclusive("with-tool", "lang")
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNewSubcommand(t *testing.T) {
+	cmd := newNewCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "new", cmd.Use)
+	assert.Equal(t, "Scaffold a new skill in the workspace", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+	assert.Len(t, cmd.Aliases, 0)
+
+	assert.NotNil(t, cmd.Flags().Lookup("description"))
+	assert.NotNil(t, cmd.Flags().Lookup("author"))
+	assert.NotNil(t, cmd.Flags().Lookup("tags"))
+	assert.NotNil(t, cmd.Flags().Lookup("with-tool"))
+
+	lang := cmd.Flags().Lookup("lang")
+	require.NotNil(t, lang)
+	assert.Empty(t, lang.DefValue)
+}
@@ -0,0 +1,52 @@
+package skills
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// progressMinBytes keeps small downloads quiet.
+	progressMinBytes = 1 << 20
+	progressInterval = 500 * time.Millisecond
+)
+
+// downloadProgress prints how much of a skill archive has arrived, on one
+// line rewritten a couple of times a second, so a large download doesn't
+// look hung.
+type downloadProgress struct {
+	w       io.Writer
+	now     func() time.Time
+	last    time.Time
+	printed bool
+}
+
+func (p *downloadProgress) update(written, total int64) {
+	if written < progressMinBytes {
+		return
+	}
+	now := p.now()
+	if p.printed && now.Sub(p.last) < progressInterval && written != total {
+		return
+	}
+	p.last = now
+	p.printed = true
+	if total > 0 {
+		fmt.Fprintf(p.w, "\r  Downloading... %s of %s (%d%%)", formatMB(written), formatMB(total), written*100/total)
+	} else {
+		fmt.Fprintf(p.w, "\r  Downloading... %s", formatMB(written))
+	}
+}
+
+// finish ends the progress line, if one was printed.
+func (p *downloadProgress) finish() {
+	if p.printed {
+		fmt.Fprintln(p.w)
+		p.printed = false
+	}
+}
+
+func formatMB(n int64) string {
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
@@ -0,0 +1,33 @@
+package skills
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadProgress(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	p := &downloadProgress{w: &buf, now: func() time.Time { return now }}
+
+	p.update(512<<10, 4<<20)
+	assert.Empty(t, buf.String(), "small amounts are not reported")
+
+	p.update(1<<20, 4<<20)
+	assert.Equal(t, "\r  Downloading... 1.0 MB of 4.0 MB (25%)", buf.String())
+
+	buf.Reset()
+	p.update(2<<20, 4<<20)
+	assert.Empty(t, buf.String(), "updates are throttled")
+
+	now = now.Add(time.Second)
+	p.update(3<<20, -1)
+	assert.Equal(t, "\r  Downloading... 3.0 MB", buf.String())
+
+	buf.Reset()
+	p.finish()
+	assert.Equal(t, "\n", buf.String())
+}
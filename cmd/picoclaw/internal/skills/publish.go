@@ -0,0 +1,35 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newPublishCommand() *cobra.Command {
+	var (
+		registry string
+		version  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "publish [path]",
+		Short: "Publish a skill to a registry",
+		Example: `
+picoclaw skills publish --version v1.0.0
+picoclaw skills publish ./my-skill --registry clawhub --version v1.2.0
+`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			return skillsPublishCmd(registry, version, dir)
+		},
+	}
+
+	cmd.Flags().StringVar(&registry, "registry", "clawhub", "Registry to publish to")
+	cmd.Flags().StringVar(&version, "version", "", "Version to publish (e.g. v1.0.0)")
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
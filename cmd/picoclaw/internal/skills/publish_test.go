@@ -0,0 +1,29 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPublishSubcommand(t *testing.T) {
+	cmd := newPublishCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "publish [path]", cmd.Use)
+	assert.Equal(t, "Publish a skill to a registry", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("registry"))
+	assert.NotNil(t, cmd.Flags().Lookup("version"))
+
+	assert.Len(t, cmd.Aliases, 0)
+}
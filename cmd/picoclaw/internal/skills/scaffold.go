@@ -0,0 +1,35 @@
+package skills
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func newScaffoldCommand(workspaceFn func() (string, error)) *cobra.Command {
+	var opts skills.ScaffoldOptions
+
+	cmd := &cobra.Command{
+		Use:   "scaffold",
+		Short: "Create a skill template with an entry script",
+		Args:  cobra.ExactArgs(1),
+		Example: `
+picoclaw skills scaffold my-skill
+picoclaw skills scaffold my-skill --type python --description "Look up train times"
+`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			workspace, err := workspaceFn()
+			if err != nil {
+				return err
+			}
+			return skillsNewCmd(workspace, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Lang, "type", skills.ScaffoldShell, "Entry script language: python, shell or go")
+	cmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Skill description")
+	cmd.Flags().StringVar(&opts.Author, "author", "", "Skill author")
+	cmd.Flags().StringSliceVar(&opts.Tags, "tags", nil, "Comma-separated tags")
+
+	return cmd
+}
@@ -0,0 +1,30 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScaffoldSubcommand(t *testing.T) {
+	cmd := newScaffoldCommand(nil)
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "scaffold", cmd.Use)
+	assert.Equal(t, "Create a skill template with an entry script", cmd.Short)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	typeFlag := cmd.Flags().Lookup("type")
+	require.NotNil(t, typeFlag)
+	assert.Equal(t, "shell", typeFlag.DefValue)
+	assert.NotNil(t, cmd.Flags().Lookup("description"))
+	assert.NotNil(t, cmd.Flags().Lookup("author"))
+	assert.NotNil(t, cmd.Flags().Lookup("tags"))
+}
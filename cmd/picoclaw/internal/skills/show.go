@@ -6,7 +6,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/skills"
 )
 
-func newShowCommand(loaderFn func() (*skills.SkillsLoader, error)) *cobra.Command {
+func newShowCommand(loaderFn func() (*skills.SkillsLoader, error), envFn func() map[string]string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "show",
 		Short:   "Show skill details",
@@ -17,7 +17,7 @@ func newShowCommand(loaderFn func() (*skills.SkillsLoader, error)) *cobra.Comman
 			if err != nil {
 				return err
 			}
-			skillsShowCmd(loader, args[0])
+			skillsShowCmd(loader, args[0], envFn())
 			return nil
 		},
 	}
@@ -8,7 +8,7 @@ import (
 )
 
 func TestNewShowSubcommand(t *testing.T) {
-	cmd := newShowCommand(nil)
+	cmd := newShowCommand(nil, nil)
 
 	require.NotNil(t, cmd)
 
@@ -5,14 +5,22 @@ import (
 )
 
 func NewStatusCommand() *cobra.Command {
+	var asJSON bool
+
 	cmd := &cobra.Command{
 		Use:     "status",
 		Aliases: []string{"s"},
 		Short:   "Show picoclaw status",
 		Run: func(cmd *cobra.Command, args []string) {
+			if asJSON {
+				statusJSONCmd()
+				return
+			}
 			statusCmd()
 		},
 	}
 
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print status as JSON")
+
 	return cmd
 }
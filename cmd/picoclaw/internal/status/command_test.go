@@ -20,6 +20,7 @@ func TestNewStatusCommand(t *testing.T) {
 	assert.Equal(t, "Show picoclaw status", cmd.Short)
 
 	assert.False(t, cmd.HasSubCommands())
+	assert.NotNil(t, cmd.Flags().Lookup("json"))
 
 	assert.NotNil(t, cmd.Run)
 	assert.Nil(t, cmd.RunE)
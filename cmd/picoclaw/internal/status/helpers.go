@@ -3,9 +3,16 @@ package status
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/pkg/auth"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+	"github.com/sipeed/picoclaw/pkg/state"
 )
 
 func statusCmd() {
@@ -83,7 +90,14 @@ func statusCmd() {
 			fmt.Println("Ollama: not set")
 		}
 
-		store, _ := auth.LoadStore()
+		store, err := auth.LoadStore()
+		if err != nil {
+			fmt.Printf("\nOAuth/Token Auth: ✗ %v\n", err)
+		} else if store.RecoveredFrom != "" {
+			fmt.Println("\nOAuth/Token Auth: ⚠ auth.json was corrupt and has been reset")
+			fmt.Printf("  Backup: %s\n", store.RecoveredFrom)
+			fmt.Println("  Run: picoclaw auth login --provider <name>")
+		}
 		if store != nil && len(store.Credentials) > 0 {
 			fmt.Println("\nOAuth/Token Auth:")
 			for provider, cred := range store.Credentials {
@@ -96,5 +110,163 @@ func statusCmd() {
 				fmt.Printf("  %s (%s): %s\n", provider, cred.AuthMethod, status)
 			}
 		}
+
+		if lines := formatAgents(cfg); len(lines) > 0 {
+			fmt.Println("\nAgents:")
+			for _, line := range lines {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+
+		var overrides map[string]string
+		var senderUsage map[string]state.SenderUsage
+		if _, err := os.Stat(workspace); err == nil {
+			sm := state.NewManager(workspace)
+			overrides = sm.ChatModels()
+			senderUsage = sm.SenderUsages()
+		}
+		if lines := formatChatModels(cfg, overrides); len(lines) > 0 {
+			fmt.Println("\nChat Models:")
+			for _, line := range lines {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+
+		if lines := formatTopConsumers(senderUsage, time.Now(), 5); len(lines) > 0 {
+			fmt.Println("\nTop Consumers Today:")
+			for _, line := range lines {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+
+		if health, err := providers.LoadProviderHealth(providers.HealthFilePath(workspace)); err == nil && len(health) > 0 {
+			fmt.Println("\nProvider Health:")
+			now := time.Now()
+			for _, h := range health {
+				fmt.Printf("  %s\n", formatProviderHealth(h, now))
+			}
+		}
+	}
+}
+
+// formatChatModels lists the effective model of every chat with an override,
+// e.g. "telegram:123: gpt4 (set with /model)". Overrides persisted with
+// /model win over channels.<name>.chats.<chat_id>.model.
+func formatChatModels(cfg *config.Config, overrides map[string]string) []string {
+	effective := make(map[string]string)
+	for channel, chats := range cfg.Channels.AllChatConfigs() {
+		for chatID, chat := range chats {
+			if chat.Model != "" {
+				effective[channel+":"+chatID] = chat.Model + " (from config)"
+			}
+		}
+	}
+	for chatKey, model := range overrides {
+		effective[chatKey] = model + " (set with /model)"
+	}
+
+	keys := make([]string, 0, len(effective))
+	for chatKey := range effective {
+		keys = append(keys, chatKey)
+	}
+	slices.Sort(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, chatKey := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", chatKey, effective[chatKey]))
+	}
+	return lines
+}
+
+// formatAgents lists the agents defined in agents.list and agents.named,
+// e.g. "coder: model gpt4, tools exec (default)". It is empty when only the
+// implicit main agent exists.
+func formatAgents(cfg *config.Config) []string {
+	agents := cfg.Agents.All()
+	defaultID := routing.NewRouteResolver(cfg).DefaultAgentID()
+	lines := make([]string, 0, len(agents))
+	for _, ac := range agents {
+		model := cfg.Agents.Defaults.GetModelName()
+		if ac.Model != nil && ac.Model.Primary != "" {
+			model = ac.Model.Primary
+		}
+		details := []string{"model " + model}
+		if ac.Tools != "" {
+			details = append(details, "tools "+ac.Tools)
+		}
+		if len(ac.Skills) > 0 {
+			details = append(details, "skills "+strings.Join(ac.Skills, ","))
+		}
+		line := fmt.Sprintf("%s: %s", ac.ID, strings.Join(details, ", "))
+		if routing.NormalizeAgentID(ac.ID) == defaultID {
+			line += " (default)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatTopConsumers lists the senders that used the most tokens today,
+// e.g. "telegram:123: 4210 tokens, 12 messages", at most limit of them.
+func formatTopConsumers(usage map[string]state.SenderUsage, now time.Time, limit int) []string {
+	today := now.Format(time.DateOnly)
+	keys := make([]string, 0, len(usage))
+	for key, u := range usage {
+		if u.Day == today {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		if d := usage[b].Tokens - usage[a].Tokens; d != 0 {
+			return d
+		}
+		if d := usage[b].Messages - usage[a].Messages; d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		u := usage[key]
+		line := fmt.Sprintf("%s: %d tokens, %d messages", key, u.Tokens, u.Messages)
+		if u.Warned {
+			line += " (daily budget reached)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatProviderHealth renders one provider's last outcome, e.g.
+// "openai: last error 3m ago: rate limited".
+func formatProviderHealth(h providers.ProviderHealth, now time.Time) string {
+	if h.Healthy() {
+		if h.LastSuccessAt.IsZero() {
+			return fmt.Sprintf("%s: no calls recorded", h.Provider)
+		}
+		line := fmt.Sprintf("%s: ✓ last success %s", h.Provider, formatAgo(now.Sub(h.LastSuccessAt)))
+		if !h.LastErrorAt.IsZero() {
+			line += fmt.Sprintf(" (last error %s: %s)", formatAgo(now.Sub(h.LastErrorAt)), h.LastError)
+		}
+		return line
+	}
+	return fmt.Sprintf("%s: ✗ last error %s: %s", h.Provider, formatAgo(now.Sub(h.LastErrorAt)), h.LastError)
+}
+
+// formatAgo renders a duration as a coarse "3m ago" style string.
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
 	}
 }
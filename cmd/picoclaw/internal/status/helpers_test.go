@@ -0,0 +1,80 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestFormatProviderHealth(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	failing := providers.ProviderHealth{
+		Provider:      "openai",
+		LastError:     "rate limited",
+		LastErrorAt:   now.Add(-3 * time.Minute),
+		LastSuccessAt: now.Add(-time.Hour),
+	}
+	assert.Equal(t, "openai: ✗ last error 3m ago: rate limited", formatProviderHealth(failing, now))
+
+	recovered := providers.ProviderHealth{
+		Provider:      "anthropic",
+		LastError:     "timed out",
+		LastErrorAt:   now.Add(-2 * time.Hour),
+		LastSuccessAt: now.Add(-10 * time.Second),
+	}
+	assert.Equal(t,
+		"anthropic: ✓ last success 10s ago (last error 2h ago: timed out)",
+		formatProviderHealth(recovered, now))
+}
+
+func TestFormatChatModels(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Channels.Telegram.Chats = map[string]config.ChatConfig{
+		"family": {Model: "cheap"},
+		"me":     {Model: "cheap"},
+	}
+	overrides := map[string]string{"telegram:me": "best"}
+
+	assert.Equal(t, []string{
+		"telegram:family: cheap (from config)",
+		"telegram:me: best (set with /model)",
+	}, formatChatModels(cfg, overrides))
+	assert.Empty(t, formatChatModels(config.DefaultConfig(), nil))
+}
+
+func TestFormatTopConsumers(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	usage := map[string]state.SenderUsage{
+		"telegram:1": {Day: "2026-03-01", Messages: 3, Tokens: 500},
+		"telegram:2": {Day: "2026-03-01", Messages: 9, Tokens: 9000, Warned: true},
+		"discord:7":  {Day: "2026-03-01", Messages: 1, Tokens: 50},
+		"slack:old":  {Day: "2026-02-28", Messages: 99, Tokens: 99999},
+	}
+
+	assert.Equal(t, []string{
+		"telegram:2: 9000 tokens, 9 messages (daily budget reached)",
+		"telegram:1: 500 tokens, 3 messages",
+	}, formatTopConsumers(usage, now, 2))
+	assert.Empty(t, formatTopConsumers(nil, now, 5))
+}
+
+func TestFormatAgents(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.ModelName = "gpt4"
+	assert.Empty(t, formatAgents(cfg))
+
+	cfg.Agents.Named = map[string]config.AgentConfig{
+		"coder":  {Model: &config.AgentModelConfig{Primary: "claude"}, Tools: "exec,read_file"},
+		"butler": {Default: true, Skills: []string{"weather", "calendar"}},
+	}
+	assert.Equal(t, []string{
+		"butler: model gpt4, skills weather,calendar (default)",
+		"coder: model claude, tools exec,read_file",
+	}, formatAgents(cfg))
+}
@@ -0,0 +1,49 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+// statusReport is the output of picoclaw status --json.
+type statusReport struct {
+	Version   string                     `json:"version"`
+	Config    string                     `json:"config"`
+	Workspace string                     `json:"workspace"`
+	Model     string                     `json:"model"`
+	Heartbeat *heartbeat.HeartbeatStatus `json:"heartbeat,omitempty"` // nil until the gateway records one
+}
+
+func statusJSONCmd() {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	data, err := json.MarshalIndent(buildStatusReport(cfg, internal.GetConfigPath()), "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding status: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// buildStatusReport gathers the status of cfg. The heartbeat comes from the
+// status file the gateway keeps in the workspace.
+func buildStatusReport(cfg *config.Config, configPath string) statusReport {
+	r := statusReport{
+		Version:   internal.GetVersion(),
+		Config:    configPath,
+		Workspace: cfg.WorkspacePath(),
+		Model:     cfg.Agents.Defaults.GetModelName(),
+	}
+	if st, err := heartbeat.ReadStatus(r.Workspace); err == nil {
+		summary := st.Summary()
+		r.Heartbeat = &summary
+	}
+	return r
+}
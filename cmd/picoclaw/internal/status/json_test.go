@@ -0,0 +1,34 @@
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/heartbeat"
+)
+
+func TestBuildStatusReport(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+
+	r := buildStatusReport(cfg, "/etc/picoclaw/config.json")
+	assert.Equal(t, "/etc/picoclaw/config.json", r.Config)
+	assert.Nil(t, r.Heartbeat)
+
+	path := heartbeat.StatusPath(cfg.WorkspacePath())
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path,
+		[]byte(`{"running": true, "last_outcome": "ok", "total_runs": 7, "error_count": 2}`), 0o644))
+
+	r = buildStatusReport(cfg, "/etc/picoclaw/config.json")
+	require.NotNil(t, r.Heartbeat)
+	assert.True(t, r.Heartbeat.IsRunning)
+	assert.Equal(t, "ok", r.Heartbeat.LastResultSummary)
+	assert.Equal(t, 7, r.Heartbeat.TotalRuns)
+	assert.Equal(t, 2, r.Heartbeat.ErrorCount)
+}
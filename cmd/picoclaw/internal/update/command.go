@@ -0,0 +1,44 @@
+package update
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/sipeed/picoclaw/pkg/updater"
+)
+
+func NewUpdateCommand() *cobra.Command {
+	var (
+		opts     updateOptions
+		rollback bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update picoclaw to the latest release",
+		Example: `
+picoclaw update --check-only
+picoclaw update --channel prerelease
+picoclaw update --version v1.3.0
+picoclaw update --dry-run
+picoclaw update --rollback
+`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if rollback {
+				return updateRollbackCmd()
+			}
+			return updateCmd(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.checkOnly, "check-only", false, "Only check whether an update is available")
+	cmd.Flags().StringVar(&opts.channel, "channel", updater.ChannelStable, "Release channel: stable or prerelease")
+	cmd.Flags().StringVar(&opts.version, "version", "", "Install this release tag instead of the latest (e.g. v1.3.0)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be downloaded without installing it")
+	cmd.Flags().BoolVar(&rollback, "rollback", false, "Restore the binary replaced by the last update")
+	cmd.MarkFlagsMutuallyExclusive("check-only", "dry-run", "rollback")
+	cmd.MarkFlagsMutuallyExclusive("version", "channel")
+	cmd.MarkFlagsMutuallyExclusive("version", "rollback")
+
+	return cmd
+}
@@ -0,0 +1,32 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUpdateCommand(t *testing.T) {
+	cmd := NewUpdateCommand()
+
+	require.NotNil(t, cmd)
+
+	assert.Equal(t, "update", cmd.Use)
+	assert.Equal(t, "Update picoclaw to the latest release", cmd.Short)
+
+	assert.Len(t, cmd.Aliases, 0)
+
+	assert.Nil(t, cmd.Run)
+	assert.NotNil(t, cmd.RunE)
+
+	assert.True(t, cmd.HasExample())
+	assert.False(t, cmd.HasSubCommands())
+
+	assert.True(t, cmd.HasFlags())
+	assert.NotNil(t, cmd.Flags().Lookup("check-only"))
+	assert.NotNil(t, cmd.Flags().Lookup("channel"))
+	assert.NotNil(t, cmd.Flags().Lookup("rollback"))
+	assert.NotNil(t, cmd.Flags().Lookup("version"))
+	assert.NotNil(t, cmd.Flags().Lookup("dry-run"))
+}
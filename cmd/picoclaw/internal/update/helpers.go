@@ -0,0 +1,118 @@
+package update
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/updater"
+)
+
+func currentExecutable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate current binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
+
+// updateOptions are the flags of picoclaw update.
+type updateOptions struct {
+	checkOnly bool
+	dryRun    bool
+	channel   string
+	version   string // a release tag to install instead of the latest
+}
+
+func updateCmd(opts updateOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client := updater.NewClient(updater.DefaultRepo)
+	current := internal.GetVersion()
+
+	var rel *updater.Release
+	var err error
+	if opts.version != "" {
+		rel, err = client.ReleaseByTag(ctx, opts.version)
+		if err != nil {
+			return fmt.Errorf("failed to find release %s: %w", opts.version, err)
+		}
+		if updater.NormalizeTag(rel.TagName) == updater.NormalizeTag(current) {
+			fmt.Printf("✓ picoclaw %s is already installed\n", current)
+			return nil
+		}
+		fmt.Printf("Found release %s (current: %s)\n", rel.TagName, current)
+	} else {
+		rel, err = client.LatestRelease(ctx, opts.channel)
+		if err != nil {
+			return fmt.Errorf("failed to check for updates: %w", err)
+		}
+		if !updater.IsNewer(current, rel.TagName) {
+			fmt.Printf("✓ picoclaw %s is up to date\n", current)
+			return nil
+		}
+		fmt.Printf("Update available: %s → %s\n", current, rel.TagName)
+	}
+	if rel.HTMLURL != "" {
+		fmt.Printf("  %s\n", rel.HTMLURL)
+	}
+	if opts.checkOnly {
+		return nil
+	}
+
+	assetName := updater.CurrentAssetName()
+	asset := rel.FindAsset(assetName)
+	if asset == nil {
+		return fmt.Errorf("release %s has no asset %s for this platform", rel.TagName, assetName)
+	}
+
+	exe, err := currentExecutable()
+	if err != nil {
+		return err
+	}
+
+	if opts.dryRun {
+		fmt.Printf("Would download %s (%.1f MB)\n", asset.Name, float64(asset.Size)/(1024*1024))
+		fmt.Printf("  from %s\n", asset.BrowserDownloadURL)
+		if sums := rel.ChecksumAsset(); sums != nil {
+			fmt.Printf("  verified against %s\n", sums.Name)
+		}
+		fmt.Printf("  and replace %s\n", exe)
+		return nil
+	}
+
+	fmt.Printf("Downloading %s...\n", asset.Name)
+	newPath, err := client.Download(ctx, rel, asset, filepath.Dir(exe))
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	fmt.Println("✓ Checksum verified")
+
+	if err := updater.Install(exe, newPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+
+	fmt.Printf("✓ Updated picoclaw to %s\n", rel.TagName)
+	fmt.Printf("  Previous binary kept at %s (use --rollback to restore)\n", updater.BackupPath(exe))
+	return nil
+}
+
+func updateRollbackCmd() error {
+	exe, err := currentExecutable()
+	if err != nil {
+		return err
+	}
+	if err := updater.Rollback(exe); err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+	fmt.Println("✓ Restored previous picoclaw binary")
+	return nil
+}
@@ -1,23 +1,43 @@
 package version
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
+	"github.com/sipeed/picoclaw/pkg/updater"
 )
 
 func NewVersionCommand() *cobra.Command {
+	var (
+		asJSON bool
+		check  bool
+	)
+
 	cmd := &cobra.Command{
 		Use:     "version",
 		Aliases: []string{"v"},
 		Short:   "Show version information",
 		Run: func(_ *cobra.Command, _ []string) {
+			if check {
+				checkForUpdate()
+				return
+			}
+			if asJSON {
+				printVersionJSON()
+				return
+			}
 			printVersion()
 		},
 	}
 
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print version information as JSON")
+	cmd.Flags().BoolVar(&check, "check", false, "Check GitHub for a newer release (cached for 24h)")
+
 	return cmd
 }
 
@@ -30,4 +50,55 @@ func printVersion() {
 	if goVer != "" {
 		fmt.Printf("  Go: %s\n", goVer)
 	}
+	info := internal.GetBuildInfo()
+	fmt.Printf("  Platform: %s/%s\n", info.OS, info.Arch)
+	if info.CGO {
+		fmt.Println("  CGO: enabled")
+	} else {
+		fmt.Println("  CGO: disabled")
+	}
+}
+
+func printVersionJSON() {
+	data, err := json.MarshalIndent(internal.GetBuildInfo(), "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding version: %v\n", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// checkForUpdate compares this build with the latest stable release. The
+// result is cached in the workspace so repeated runs stay off the API.
+func checkForUpdate() {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	if !cfg.Gateway.UpdateCheck.VersionCheck {
+		fmt.Println("Update check is disabled (gateway.update_check.version_check is false)")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	res, err := updater.NewClient(updater.DefaultRepo).
+		CheckLatest(ctx, updater.CheckCachePath(cfg.WorkspacePath()), updater.CheckInterval)
+	if err != nil {
+		fmt.Printf("Error checking for updates: %v\n", err)
+		return
+	}
+
+	current := internal.GetVersion()
+	if !updater.IsNewer(current, res.Latest) {
+		fmt.Printf("✓ picoclaw %s is up to date\n", current)
+		return
+	}
+	fmt.Printf("Update available: %s → %s\n", current, res.Latest)
+	if res.URL != "" {
+		fmt.Printf("  %s\n", res.URL)
+	}
+	fmt.Println("  Run 'picoclaw update' to install it.")
 }
@@ -14,13 +14,22 @@ import (
 
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/agent"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/audit"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/auth"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/cron"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/digest"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/doctor"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/gateway"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/heartbeat"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/history"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/migrate"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/models"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/onboard"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/prompt"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/sessions"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/skills"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/status"
+	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/update"
 	"github.com/sipeed/picoclaw/cmd/picoclaw/internal/version"
 )
 
@@ -35,13 +44,22 @@ func NewPicoclawCommand() *cobra.Command {
 
 	cmd.AddCommand(
 		onboard.NewOnboardCommand(),
+		prompt.NewPromptCommand(),
 		agent.NewAgentCommand(),
+		audit.NewAuditCommand(),
 		auth.NewAuthCommand(),
 		gateway.NewGatewayCommand(),
+		heartbeat.NewHeartbeatCommand(),
+		history.NewHistoryCommand(),
 		status.NewStatusCommand(),
 		cron.NewCronCommand(),
+		digest.NewDigestCommand(),
+		doctor.NewDoctorCommand(),
 		migrate.NewMigrateCommand(),
+		models.NewModelsCommand(),
+		sessions.NewSessionsCommand(),
 		skills.NewSkillsCommand(),
+		update.NewUpdateCommand(),
 		version.NewVersionCommand(),
 	)
 
@@ -34,13 +34,22 @@ func TestNewPicoclawCommand(t *testing.T) {
 
 	allowedCommands := []string{
 		"agent",
+		"audit",
 		"auth",
 		"cron",
+		"digest",
+		"doctor",
 		"gateway",
+		"heartbeat",
+		"history",
 		"migrate",
+		"models",
 		"onboard",
+		"prompt",
+		"sessions",
 		"skills",
 		"status",
+		"update",
 		"version",
 	}
 
@@ -0,0 +1,202 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	// classifierTimeout bounds the classifier call; a slow classifier falls
+	// back to the heuristics.
+	classifierTimeout = 15 * time.Second
+	// classifierMaxChars is how much of the message the classifier sees.
+	classifierMaxChars = 2000
+)
+
+// codePattern matches fenced or indented code and lines that start like
+// source code.
+var codePattern = regexp.MustCompile("```|(?m)^(\t| {4})\\S|" +
+	`(?m)^\s*(func|def|class|import|package|#include|public|private|fn|const|let|var|SELECT|INSERT|UPDATE)\s`)
+
+// toolHints are phrases suggesting a message needs tools, such as a web
+// search, a file or a reminder.
+var toolHints = []string{
+	"http://", "https://", "search", "look up", "browse", "download", "install",
+	"file", "folder", "run ", "execute", "schedule", "remind", "weather", "news", "latest",
+}
+
+// autoRoute returns the model_list name of the auto-route tier that should
+// answer msg, or "" when auto-routing is off or the agent has its own model.
+func (al *AgentLoop) autoRoute(
+	ctx context.Context,
+	agent *AgentInstance,
+	msg bus.InboundMessage,
+	sessionKey, content string,
+) string {
+	if al.cfg == nil {
+		return ""
+	}
+	ar := al.cfg.Agents.Defaults.AutoRoute
+	if !ar.Enabled || len(ar.Tiers) == 0 || agent.Model != al.cfg.Agents.Defaults.GetModelName() {
+		return ""
+	}
+
+	tier, reason := al.pickTier(ctx, agent, msg, sessionKey, content)
+	logger.InfoCF("agent", "Auto-routed message",
+		map[string]any{
+			"agent_id": agent.ID,
+			"tier":     tier.Name,
+			"model":    tier.Model,
+			"reason":   reason,
+		})
+	return tier.Model
+}
+
+// pickTier chooses the tier for a message and says why.
+func (al *AgentLoop) pickTier(
+	ctx context.Context,
+	agent *AgentInstance,
+	msg bus.InboundMessage,
+	sessionKey, content string,
+) (config.AutoRouteTier, string) {
+	ar := al.cfg.Agents.Defaults.AutoRoute
+	top := ar.Tiers[len(ar.Tiers)-1]
+
+	if al.state != nil {
+		if name := al.state.GetChatTier(msg.Channel + ":" + msg.ChatID); name != "" {
+			if tier, ok := ar.Tier(name); ok {
+				return tier, "pinned with /route"
+			}
+		}
+	}
+	if usedToolsLastTurn(agent.Sessions.GetHistory(sessionKey)) {
+		return top, "previous turn used tools"
+	}
+	lower := strings.ToLower(content)
+	for _, kw := range ar.EscalateKeywords {
+		if kw != "" && strings.Contains(lower, strings.ToLower(kw)) {
+			return top, fmt.Sprintf("asked for %q", kw)
+		}
+	}
+	if ar.Classifier != "" {
+		if tier, ok := al.classifyTier(ctx, sessionKey, content); ok {
+			return tier, "classifier"
+		}
+	}
+	return tierFor(ar.Tiers, content, len(msg.Media) > 0), "heuristics"
+}
+
+// tierFor returns the first tier that suits the message, or the last tier.
+// Attachments count as needing tools, since reading them usually does.
+func tierFor(tiers []config.AutoRouteTier, content string, hasMedia bool) config.AutoRouteTier {
+	chars := utf8.RuneCountInString(content)
+	code := codePattern.MatchString(content)
+	tools := hasMedia || needsTools(content)
+	for _, t := range tiers[:len(tiers)-1] {
+		if (t.MaxChars == 0 || chars <= t.MaxChars) && (!code || t.Code) && (!tools || t.Tools) {
+			return t
+		}
+	}
+	return tiers[len(tiers)-1]
+}
+
+// needsTools reports whether content mentions something that usually takes
+// a tool call.
+func needsTools(content string) bool {
+	lower := strings.ToLower(content)
+	for _, hint := range toolHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// usedToolsLastTurn reports whether the latest turn in history called tools.
+func usedToolsLastTurn(history []providers.Message) bool {
+	for i := len(history) - 1; i >= 0; i-- {
+		switch m := history[i]; {
+		case m.Role == "user":
+			return false
+		case m.Role == "tool" || len(m.ToolCalls) > 0:
+			return true
+		}
+	}
+	return false
+}
+
+// classifyTier asks the classifier model which tier suits content. Its
+// usage is charged to the session like any other request.
+func (al *AgentLoop) classifyTier(ctx context.Context, sessionKey, content string) (config.AutoRouteTier, bool) {
+	ar := al.cfg.Agents.Defaults.AutoRoute
+	mc, err := al.cfg.GetModelConfig(ar.Classifier)
+	if err != nil {
+		return config.AutoRouteTier{}, false
+	}
+	provider, modelID, err := al.modelProvider(mc)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to create auto-route classifier",
+			map[string]any{"model": ar.Classifier, "error": err.Error()})
+		return config.AutoRouteTier{}, false
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Pick the cheapest model tier that can answer the user's message well. " +
+		"Tiers, cheapest first:\n")
+	for i, t := range ar.Tiers {
+		fmt.Fprintf(&prompt, "- %s: %s\n", t.Name, describeTier(t, i == len(ar.Tiers)-1))
+	}
+	prompt.WriteString("Answer with the tier name only.")
+
+	if utf8.RuneCountInString(content) > classifierMaxChars {
+		content = string([]rune(content)[:classifierMaxChars])
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, classifierTimeout)
+	defer cancel()
+	resp, err := provider.Chat(ctx, []providers.Message{
+		{Role: "system", Content: prompt.String()},
+		{Role: "user", Content: content},
+	}, nil, modelID, map[string]any{"max_tokens": 16, "temperature": 0.0})
+	if err != nil {
+		logger.WarnCF("agent", "Auto-route classifier failed, using heuristics",
+			map[string]any{"model": ar.Classifier, "error": err.Error()})
+		return config.AutoRouteTier{}, false
+	}
+	al.recordUsage(sessionKey, ar.Classifier, resp.Usage)
+
+	answer := strings.Fields(strings.ToLower(resp.Content))
+	if len(answer) == 0 {
+		return config.AutoRouteTier{}, false
+	}
+	return ar.Tier(strings.Trim(answer[0], ".,:;\"'`*"))
+}
+
+// describeTier tells the classifier what a tier is for.
+func describeTier(t config.AutoRouteTier, top bool) string {
+	if top {
+		return "the most capable model, for anything else"
+	}
+	var parts []string
+	if t.MaxChars > 0 {
+		parts = append(parts, fmt.Sprintf("messages up to %d characters", t.MaxChars))
+	} else {
+		parts = append(parts, "messages of any length")
+	}
+	if t.Code {
+		parts = append(parts, "handles code")
+	}
+	if t.Tools {
+		parts = append(parts, "handles tool use")
+	}
+	return strings.Join(parts, ", ")
+}
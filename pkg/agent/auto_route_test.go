@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// tierProvider answers with its name, so a reply shows which model served
+// it; as the classifier, the name is its tier choice.
+type tierProvider struct {
+	name  string
+	calls int
+}
+
+func (p *tierProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls++
+	return &providers.LLMResponse{
+		Content: p.name,
+		Usage:   &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+	}, nil
+}
+
+func (p *tierProvider) GetDefaultModel() string { return p.name }
+
+func testTiers() []config.AutoRouteTier {
+	return []config.AutoRouteTier{
+		{Name: "cheap", Model: "cheap-model", MaxChars: 200},
+		{Name: "coder", Model: "coder-model", MaxChars: 4000, Code: true},
+		{Name: "top", Model: "top-model"},
+	}
+}
+
+// newAutoRouteLoop returns a loop with auto-routing over testTiers, each
+// tier served by its own tierProvider.
+func newAutoRouteLoop(t *testing.T) (*AgentLoop, map[string]*tierProvider, func()) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	cfg.Commands.Enabled = true
+	cfg.Commands.Owners = config.FlexibleStringSlice{"42"}
+	cfg.Agents.Defaults.AutoRoute = config.AutoRouteConfig{
+		Enabled:          true,
+		Tiers:            testTiers(),
+		EscalateKeywords: []string{"think hard"},
+	}
+	served := make(map[string]*tierProvider)
+	for _, name := range []string{"cheap-model", "coder-model", "top-model", "classifier"} {
+		cfg.ModelList = append(cfg.ModelList, config.ModelConfig{ModelName: name, Model: "openai/" + name})
+		p := &tierProvider{name: name}
+		served[name] = p
+		al.capabilityProviders.Store(name, capabilityProviderEntry{provider: p, modelID: name})
+	}
+	return al, served, cleanup
+}
+
+func TestTierFor(t *testing.T) {
+	tiers := testTiers()
+	tests := []struct {
+		name    string
+		content string
+		media   bool
+		want    string
+	}{
+		{"short chat", "hi, how are you?", false, "cheap"},
+		{"long text", strings.Repeat("word ", 100), false, "coder"},
+		{"code", "why does this fail?\n```go\nfunc main() {}\n```", false, "coder"},
+		{"tools", "search the web for the latest release", false, "top"},
+		{"attachment", "what is this?", true, "top"},
+		{"very long", strings.Repeat("word ", 1000), false, "top"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tierFor(tiers, tt.content, tt.media); got.Name != tt.want {
+				t.Errorf("tierFor() = %s, want %s", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsedToolsLastTurn(t *testing.T) {
+	history := []providers.Message{
+		{Role: "user", Content: "what's the weather"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1"}}},
+		{Role: "tool", ToolCallID: "1", Content: "sunny"},
+		{Role: "assistant", Content: "It's sunny."},
+	}
+	if !usedToolsLastTurn(history) {
+		t.Error("expected the tool call to be found")
+	}
+	history = append(history,
+		providers.Message{Role: "user", Content: "thanks"},
+		providers.Message{Role: "assistant", Content: "You're welcome."})
+	if usedToolsLastTurn(history) {
+		t.Error("only the latest turn counts")
+	}
+}
+
+func TestProcessMessage_AutoRoute(t *testing.T) {
+	al, served, cleanup := newAutoRouteLoop(t)
+	defer cleanup()
+
+	send := func(content string) string {
+		t.Helper()
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: "telegram", SenderID: "42", ChatID: "chat", Content: content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage: %v", err)
+		}
+		return resp
+	}
+
+	if got := send("hello"); got != "cheap-model" {
+		t.Errorf("short message served by %q, want cheap-model", got)
+	}
+	if got := send("please think hard about this"); got != "top-model" {
+		t.Errorf("escalation keyword served by %q, want top-model", got)
+	}
+
+	agent := al.registry.GetDefaultAgent()
+	if len(al.usage.sessions) != 1 {
+		t.Fatalf("usage recorded for %d sessions, want 1", len(al.usage.sessions))
+	}
+	var sessionKey string
+	for key := range al.usage.sessions {
+		sessionKey = key
+	}
+	if got := agent.Sessions.GetModel(sessionKey); got != "top-model" {
+		t.Errorf("session model = %q, want top-model", got)
+	}
+	usage := al.usage.Get(sessionKey)
+	if usage.Models["cheap-model"] != 1 || usage.Models["top-model"] != 1 {
+		t.Errorf("usage models = %v, want one request each", usage.Models)
+	}
+	if served["coder-model"].calls != 0 {
+		t.Errorf("coder tier was not expected to be called")
+	}
+}
+
+func TestProcessMessage_AutoRouteClassifier(t *testing.T) {
+	al, served, cleanup := newAutoRouteLoop(t)
+	defer cleanup()
+	al.cfg.Agents.Defaults.AutoRoute.Classifier = "classifier"
+	// The classifier answers with its own name, which is not a tier, so the
+	// heuristics decide.
+	resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+		Channel: "telegram", SenderID: "42", ChatID: "chat", Content: "hello",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "cheap-model" || served["classifier"].calls != 1 {
+		t.Errorf("resp = %q, classifier calls = %d", resp, served["classifier"].calls)
+	}
+
+	served["classifier"].name = "Top."
+	resp, err = al.processMessage(context.Background(), bus.InboundMessage{
+		Channel: "telegram", SenderID: "42", ChatID: "chat", Content: "hello again",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "top-model" {
+		t.Errorf("resp = %q, want the classifier's choice top-model", resp)
+	}
+}
+
+func TestRouteCommandPinsTier(t *testing.T) {
+	al, _, cleanup := newAutoRouteLoop(t)
+	defer cleanup()
+
+	send := func(content string) string {
+		t.Helper()
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: "telegram", SenderID: "42", ChatID: "chat", Content: content,
+		})
+		if err != nil {
+			t.Fatalf("processMessage: %v", err)
+		}
+		return resp
+	}
+
+	if got := send("/route nope"); !strings.Contains(got, "cheap, coder, top") {
+		t.Errorf("unknown tier reply = %q", got)
+	}
+	if got := send("/route top"); !strings.Contains(got, "top") {
+		t.Errorf("pin reply = %q", got)
+	}
+	if got := send("hi"); got != "top-model" {
+		t.Errorf("pinned chat served by %q, want top-model", got)
+	}
+	send("/route auto")
+	if got := send("hi"); got != "cheap-model" {
+		t.Errorf("after /route auto served by %q, want cheap-model", got)
+	}
+}
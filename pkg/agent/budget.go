@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+// budgetGuard enforces the spending limits of budget.channels and
+// budget.chats. Spending is written through to the state manager, so a
+// restart doesn't reset a period.
+type budgetGuard struct {
+	cfg   config.BudgetConfig
+	loc   *time.Location
+	state *state.Manager
+	now   func() time.Time
+
+	mu    sync.Mutex
+	usage map[string]state.BudgetUsage // by channel or "channel:chat_id"
+}
+
+func newBudgetGuard(cfg config.BudgetConfig, sm *state.Manager) *budgetGuard {
+	loc := time.Local
+	if cfg.Timezone != "" {
+		if l, err := time.LoadLocation(cfg.Timezone); err == nil {
+			loc = l
+		}
+	}
+	g := &budgetGuard{
+		cfg:   cfg,
+		loc:   loc,
+		state: sm,
+		now:   time.Now,
+		usage: make(map[string]state.BudgetUsage),
+	}
+	if sm != nil {
+		for key, usage := range sm.BudgetUsages() {
+			g.usage[key] = usage
+		}
+	}
+	return g
+}
+
+// budgetBreach is a limit that a chat's turn would exceed.
+type budgetBreach struct {
+	key    string // channel or "channel:chat_id"
+	period string // config.BudgetDay or config.BudgetMonth
+	usage  state.BudgetUsage
+	notify bool // first breach of the period; tell the owner
+}
+
+// budgetLimits returns the limits that apply to a chat by budget key.
+func (g *budgetGuard) budgetLimits(channel, chatID string) map[string]config.BudgetLimit {
+	limits := make(map[string]config.BudgetLimit, 2)
+	if limit, ok := g.cfg.Channels[channel]; ok {
+		limits[channel] = limit
+	}
+	if chatID == "" {
+		return limits
+	}
+	key := channel + ":" + chatID
+	if limit, ok := g.cfg.Chats[key]; ok {
+		limits[key] = limit
+	} else if group, _, ok := strings.Cut(chatID, ":topic:"); ok {
+		// A Telegram topic ("<chat_id>:topic:<thread_id>") spends its group's budget.
+		key = channel + ":" + group
+		if limit, ok := g.cfg.Chats[key]; ok {
+			limits[key] = limit
+		}
+	}
+	return limits
+}
+
+// check returns the used-up limit of a chat or its channel, or nil when the
+// chat may spend more.
+func (g *budgetGuard) check(channel, chatID string) *budgetBreach {
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, limit := range g.budgetLimits(channel, chatID) {
+		usage := g.usageLocked(key, limit, now)
+		if (limit.Tokens <= 0 || usage.Tokens < limit.Tokens) && (limit.Cost <= 0 || usage.Cost < limit.Cost) {
+			continue
+		}
+		breach := &budgetBreach{key: key, period: budgetPeriod(limit), usage: usage, notify: !usage.Notified}
+		if breach.notify {
+			usage.Notified = true
+			g.saveLocked(key, usage)
+			logger.InfoCF("agent", "Budget exceeded", map[string]any{
+				"budget": key,
+				"tokens": usage.Tokens,
+				"cost":   usage.Cost,
+				"period": usage.Period,
+			})
+		}
+		return breach
+	}
+	return nil
+}
+
+// charge adds the tokens and cost of a turn to the chat and its channel.
+func (g *budgetGuard) charge(channel, chatID string, tokens int, cost float64) {
+	if tokens <= 0 && cost <= 0 {
+		return
+	}
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key, limit := range g.budgetLimits(channel, chatID) {
+		usage := g.usageLocked(key, limit, now)
+		usage.Tokens += tokens
+		usage.Cost += cost
+		g.saveLocked(key, usage)
+	}
+}
+
+// usageLocked returns the spending of key in the current period, starting
+// afresh when the recorded spending is from an earlier one.
+func (g *budgetGuard) usageLocked(key string, limit config.BudgetLimit, now time.Time) state.BudgetUsage {
+	layout := time.DateOnly
+	if budgetPeriod(limit) == config.BudgetMonth {
+		layout = "2006-01"
+	}
+	period := now.In(g.loc).Format(layout)
+	usage := g.usage[key]
+	if usage.Period != period {
+		usage = state.BudgetUsage{Period: period}
+	}
+	return usage
+}
+
+func (g *budgetGuard) saveLocked(key string, usage state.BudgetUsage) {
+	g.usage[key] = usage
+	if g.state == nil {
+		return
+	}
+	if err := g.state.SetBudgetUsage(key, usage); err != nil {
+		logger.WarnCF("agent", "Failed to save budget usage",
+			map[string]any{"budget": key, "error": err.Error()})
+	}
+}
+
+func budgetPeriod(limit config.BudgetLimit) string {
+	if limit.Period == config.BudgetMonth {
+		return config.BudgetMonth
+	}
+	return config.BudgetDay
+}
+
+// notifyBudgetOwner tells the owner that a budget ran out, in
+// budget.notify_channel/notify_chat_id or else the last active chat. The
+// chat that hit the limit is never used, since it already got a refusal.
+func (al *AgentLoop) notifyBudgetOwner(ctx context.Context, breach *budgetBreach, fromChannel, fromChat string) {
+	channel, chatID := al.cfg.Budget.NotifyChannel, al.cfg.Budget.NotifyChatID
+	if (channel == "" || chatID == "") && al.state != nil {
+		channel, chatID, _ = strings.Cut(al.state.GetLastChannel(), ":")
+	}
+	if channel == "" || chatID == "" || constants.IsInternalChannel(channel) ||
+		(channel == fromChannel && chatID == fromChat) {
+		return
+	}
+
+	key := i18n.BudgetNoticeDay
+	if breach.period == config.BudgetMonth {
+		key = i18n.BudgetNoticeMonth
+	}
+	al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel: channel,
+		ChatID:  chatID,
+		Content: i18n.T(al.language(channel), key, breach.key, breach.usage.Tokens, breach.usage.Cost),
+	})
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestBudgetGuard_ChatLimitAndReset(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, loc)
+	sm := state.NewManager(t.TempDir())
+	g := newBudgetGuard(config.BudgetConfig{
+		Timezone: "Asia/Tokyo",
+		Chats:    map[string]config.BudgetLimit{"telegram:-100": {Cost: 1}},
+	}, sm)
+	g.now = func() time.Time { return now }
+
+	if b := g.check("telegram", "-100"); b != nil {
+		t.Fatalf("fresh budget breached: %+v", b)
+	}
+	g.charge("telegram", "-100", 5000, 1.2)
+	g.charge("telegram", "42", 5000, 50) // unlimited chat
+
+	b := g.check("telegram", "-100")
+	if b == nil || !b.notify || b.key != "telegram:-100" || b.period != config.BudgetDay {
+		t.Fatalf("first breach = %+v; want day breach with notify", b)
+	}
+	if b = g.check("telegram", "-100"); b == nil || b.notify {
+		t.Fatalf("second breach = %+v; want breach without notify", b)
+	}
+	if b = g.check("telegram", "42"); b != nil {
+		t.Errorf("chat without a limit refused: %+v", b)
+	}
+
+	// Spending survives a restart.
+	restarted := newBudgetGuard(g.cfg, sm)
+	restarted.now = g.now
+	if b = restarted.check("telegram", "-100"); b == nil || b.notify {
+		t.Fatalf("after restart = %+v; want breach already notified", b)
+	}
+
+	// Midnight in the budget timezone starts a new day.
+	now = now.Add(time.Hour)
+	if b = restarted.check("telegram", "-100"); b != nil {
+		t.Errorf("budget not reset on a new day: %+v", b)
+	}
+}
+
+func TestBudgetGuard_ChannelTotalMonthly(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	g := newBudgetGuard(config.BudgetConfig{
+		Channels: map[string]config.BudgetLimit{"discord": {Period: config.BudgetMonth, Tokens: 1000}},
+	}, nil)
+	g.now = func() time.Time { return now }
+
+	g.charge("discord", "a", 600, 0)
+	g.charge("discord", "b", 600, 0)
+	if b := g.check("discord", "c"); b == nil || b.key != "discord" || b.period != config.BudgetMonth {
+		t.Fatalf("channel total breach = %+v", b)
+	}
+	if b := g.check("slack", "a"); b != nil {
+		t.Errorf("other channels must not be limited: %+v", b)
+	}
+
+	now = now.AddDate(0, 0, 20)
+	if b := g.check("discord", "c"); b == nil {
+		t.Error("monthly budget reset within the month")
+	}
+	now = now.AddDate(0, 1, 0)
+	if b := g.check("discord", "c"); b != nil {
+		t.Errorf("monthly budget not reset in a new month: %+v", b)
+	}
+}
+
+func TestBudgetGuard_TopicSpendsGroupBudget(t *testing.T) {
+	g := newBudgetGuard(config.BudgetConfig{
+		Chats: map[string]config.BudgetLimit{
+			"telegram:-100":         {Tokens: 1000},
+			"telegram:-100:topic:9": {Tokens: 5000},
+		},
+	}, state.NewManager(t.TempDir()))
+
+	g.charge("telegram", "-100:topic:7", 1200, 0)
+	if b := g.check("telegram", "-100"); b == nil || b.key != "telegram:-100" {
+		t.Fatalf("group check = %+v; want the topic's spending on the group budget", b)
+	}
+	if b := g.check("telegram", "-100:topic:7"); b == nil {
+		t.Error("topic without its own budget escaped the group budget")
+	}
+	// A topic with its own budget is limited by that one alone.
+	if b := g.check("telegram", "-100:topic:9"); b != nil {
+		t.Errorf("topic with its own budget refused: %+v", b)
+	}
+}
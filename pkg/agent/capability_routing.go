@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"path/filepath"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const noVisionModelNotice = "⚠ The current model can't read images and no vision-capable model is configured " +
+	"in model_list. Add one (or set \"capabilities\": [\"vision\"] on an entry) to let me see attachments. " +
+	"Replying to the text only."
+
+// capabilityRoute describes which provider/model should serve a turn after
+// checking the capabilities the request needs.
+type capabilityRoute struct {
+	Provider     providers.LLMProvider
+	ProviderName string // provider/protocol name, used for health tracking
+	Model        string
+	ModelName    string // model_list name, used to look up pricing
+	Routed       bool   // true if the agent's own model was replaced for this turn
+	DropTools    bool   // true if no model supports tool calling; tools are described in the prompt instead
+	MaxTokens    int    // the agent's max_tokens, capped to what the model can produce
+	Notice       string // user-facing message when no capable model is configured
+}
+
+// hasImageMedia reports whether any of the media refs resolves to an image.
+func (al *AgentLoop) hasImageMedia(refs []string) bool {
+	for _, ref := range refs {
+		filename, contentType := filepath.Base(ref), ""
+		if al.mediaStore != nil {
+			if _, meta, err := al.mediaStore.ResolveWithMeta(ref); err == nil {
+				if meta.Filename != "" {
+					filename = meta.Filename
+				}
+				contentType = meta.ContentType
+			}
+		}
+		if media.InferType(filename, contentType) == "image" {
+			return true
+		}
+	}
+	return false
+}
+
+// turnNeeds is what a turn requires of the model serving it.
+type turnNeeds struct {
+	Vision bool // the message carries an image
+	// Tools is set for tool-heavy turns, which are worth moving to a
+	// tool-capable model. Other turns keep the agent's model and, if it
+	// cannot call tools, get the offered tools described in the prompt.
+	Tools     bool
+	HaveTools bool // tools are offered to the model at all
+}
+
+// toolHeavyWindow is how many recent history messages are checked for tool
+// use when deciding whether a turn is tool-heavy.
+const toolHeavyWindow = 20
+
+// isToolHeavy reports whether a turn is likely to need tool calls: a
+// scheduled or heartbeat task, or a conversation whose recent history
+// already used tools.
+func isToolHeavy(messages []providers.Message, opts processOptions) bool {
+	if opts.NoHistory {
+		return true
+	}
+	for i := len(messages) - 1; i >= 0 && i >= len(messages)-toolHeavyWindow; i-- {
+		if messages[i].Role == "tool" || len(messages[i].ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCapabilityRoute picks a model from model_list that supports what the
+// turn requires (image input and/or tool calling) when the agent's own model
+// does not. If nothing suitable is configured the agent's model is kept and
+// the route carries a notice explaining the degradation.
+func (al *AgentLoop) resolveCapabilityRoute(agent *AgentInstance, needs turnNeeds) capabilityRoute {
+	needVision := needs.Vision
+	needTools := needs.Tools && needs.HaveTools
+	route := capabilityRoute{
+		Provider:  agent.Provider,
+		Model:     agent.Model,
+		ModelName: agent.Model,
+		MaxTokens: replyTokens(agent.MaxTokens, al.modelInfo(agent.Model)),
+	}
+	if len(agent.Candidates) > 0 {
+		route.ProviderName = agent.Candidates[0].Provider
+	}
+
+	var required []providers.Capability
+	if needVision {
+		required = append(required, providers.CapabilityVision)
+	}
+	if needTools {
+		required = append(required, providers.CapabilityTools)
+	}
+	if providers.HasCapabilities(al.agentCapabilities(agent), required...) {
+		return al.describeToolsIfUnsupported(agent, route, needs)
+	}
+
+	if target := providers.FindModelWithCapabilities(al.cfg, required...); target != nil {
+		if r, ok := al.routeToModel(agent, target.ModelName, required); ok {
+			return r
+		}
+	}
+
+	// A vision model without tools still beats ignoring the image.
+	if needVision && needTools {
+		if target := providers.FindModelWithCapabilities(al.cfg, providers.CapabilityVision); target != nil {
+			if r, ok := al.routeToModel(agent, target.ModelName, required); ok {
+				r.DropTools = true
+				return r
+			}
+		}
+	}
+
+	if needVision && !providers.HasCapabilities(al.agentCapabilities(agent), providers.CapabilityVision) {
+		route.Notice = noVisionModelNotice
+	}
+	return al.describeToolsIfUnsupported(agent, route, needs)
+}
+
+// describeToolsIfUnsupported makes a route that keeps the agent's model
+// describe the offered tools in the prompt when the model cannot call them.
+func (al *AgentLoop) describeToolsIfUnsupported(
+	agent *AgentInstance,
+	route capabilityRoute,
+	needs turnNeeds,
+) capabilityRoute {
+	if needs.HaveTools && !providers.HasCapabilities(al.agentCapabilities(agent), providers.CapabilityTools) {
+		route.DropTools = true
+		logger.WarnCF("agent", "Model does not support tool calling, describing tools in the prompt",
+			map[string]any{"agent_id": agent.ID, "model": agent.Model})
+	}
+	return route
+}
+
+// routeToModel builds a route to the model_list entry named modelName,
+// creating (and caching) its provider on first use.
+func (al *AgentLoop) routeToModel(
+	agent *AgentInstance,
+	modelName string,
+	required []providers.Capability,
+) (capabilityRoute, bool) {
+	mc, err := al.cfg.GetModelConfig(modelName)
+	if err != nil {
+		return capabilityRoute{}, false
+	}
+
+	provider, modelID, err := al.modelProvider(mc)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to create provider for capability routing",
+			map[string]any{"model": modelName, "error": err.Error()})
+		return capabilityRoute{}, false
+	}
+
+	logger.InfoCF("agent", "Routing turn to capable model",
+		map[string]any{
+			"agent_id":     agent.ID,
+			"from_model":   agent.Model,
+			"to_model":     modelName,
+			"capabilities": required,
+		})
+
+	protocol, _ := providers.ExtractProtocol(mc.Model)
+	return capabilityRoute{
+		Provider:     provider,
+		ProviderName: protocol,
+		Model:        modelID,
+		ModelName:    modelName,
+		Routed:       true,
+		MaxTokens:    replyTokens(agent.MaxTokens, providers.ModelConfigInfo(mc)),
+	}, true
+}
+
+// capabilityProviderEntry caches a provider created for capability routing.
+type capabilityProviderEntry struct {
+	provider providers.LLMProvider
+	modelID  string
+}
+
+// modelProvider returns the provider and model ID of a model_list entry,
+// creating the provider on first use and caching it.
+func (al *AgentLoop) modelProvider(mc *config.ModelConfig) (providers.LLMProvider, string, error) {
+	if cached, ok := al.capabilityProviders.Load(mc.ModelName); ok {
+		entry := cached.(capabilityProviderEntry)
+		return entry.provider, entry.modelID, nil
+	}
+	provider, modelID, err := providers.CreateProviderFromConfig(mc, al.cfg.Providers.HTTP)
+	if err != nil {
+		return nil, "", err
+	}
+	al.capabilityProviders.Store(mc.ModelName, capabilityProviderEntry{provider: provider, modelID: modelID})
+	return provider, modelID, nil
+}
+
+// agentCapabilities returns the capabilities of the agent's primary model.
+func (al *AgentLoop) agentCapabilities(agent *AgentInstance) []providers.Capability {
+	return al.modelInfo(agent.Model).Capabilities
+}
+
+// modelInfo returns what is known about a model_list name or model ID.
+func (al *AgentLoop) modelInfo(model string) providers.ModelInfo {
+	return lookupModelInfo(al.cfg, model)
+}
+
+// lookupModelInfo resolves model as a model_list name first, then as the
+// model ID of a model_list entry, so the entry's explicit capabilities and
+// limits apply either way; only a model outside model_list falls back to
+// the built-in registry.
+func lookupModelInfo(cfg *config.Config, model string) providers.ModelInfo {
+	if cfg != nil {
+		if mc, ok := cfg.LookupModelConfig(model); ok {
+			return providers.ModelConfigInfo(mc)
+		}
+		for i := range cfg.ModelList {
+			mc := &cfg.ModelList[i]
+			if _, id := providers.ExtractProtocol(mc.Model); mc.Model == model || id == model {
+				return providers.ModelConfigInfo(mc)
+			}
+		}
+	}
+	return providers.LookupModel(model)
+}
+
+// replyTokens caps maxTokens to the longest reply the model can produce.
+func replyTokens(maxTokens int, info providers.ModelInfo) int {
+	if info.MaxTokens > 0 && (maxTokens <= 0 || info.MaxTokens < maxTokens) {
+		return info.MaxTokens
+	}
+	return maxTokens
+}
@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestResolveCapabilityRoute_RoutesImagesToVisionModel(t *testing.T) {
+	al, cfg, _, provider, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	cfg.ModelList = []config.ModelConfig{
+		{ModelName: "vision", Model: "openai/gpt-4o", APIKey: "test-key"},
+	}
+	agent := al.registry.GetDefaultAgent()
+
+	route := al.resolveCapabilityRoute(agent, turnNeeds{Vision: true, Tools: true, HaveTools: true})
+	if !route.Routed {
+		t.Fatal("expected turn to be routed to the vision model")
+	}
+	if route.Model != "gpt-4o" {
+		t.Errorf("expected model gpt-4o, got %q", route.Model)
+	}
+	if route.Provider == provider {
+		t.Error("expected a provider created from the vision model config")
+	}
+	if route.Notice != "" {
+		t.Errorf("unexpected notice: %q", route.Notice)
+	}
+	if want := min(agent.MaxTokens, 16384); route.MaxTokens != want {
+		t.Errorf("MaxTokens = %d, want %d capped by gpt-4o's limit", route.MaxTokens, want)
+	}
+}
+
+func TestResolveCapabilityRoute_NoVisionModelConfigured(t *testing.T) {
+	al, _, _, provider, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	agent := al.registry.GetDefaultAgent()
+
+	route := al.resolveCapabilityRoute(agent, turnNeeds{Vision: true, Tools: true, HaveTools: true})
+	if route.Routed {
+		t.Fatal("expected no routing without a vision model")
+	}
+	if route.Provider != provider || route.Model != agent.Model {
+		t.Error("expected the agent's own provider and model to be kept")
+	}
+	if route.Notice == "" {
+		t.Error("expected a user-facing notice")
+	}
+}
+
+func TestResolveCapabilityRoute_TextOnlyKeepsAgentModel(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	agent := al.registry.GetDefaultAgent()
+
+	route := al.resolveCapabilityRoute(agent, turnNeeds{Tools: true, HaveTools: true})
+	if route.Routed || route.Notice != "" || route.DropTools {
+		t.Errorf("expected unchanged route, got %+v", route)
+	}
+}
+
+func TestResolveCapabilityRoute_ToolsOnlyForToolHeavyTurns(t *testing.T) {
+	al, cfg, _, provider, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	cfg.ModelList = []config.ModelConfig{
+		{ModelName: "plain", Model: "openai/local-model", Capabilities: []string{"streaming"}},
+		{ModelName: "tools", Model: "openai/gpt-4o", APIKey: "test-key"},
+	}
+	agent := al.registry.GetDefaultAgent()
+	agent.Model = "plain"
+
+	route := al.resolveCapabilityRoute(agent, turnNeeds{HaveTools: true})
+	if route.Routed || route.Provider != provider {
+		t.Errorf("a chat turn must keep the agent's model, got %+v", route)
+	}
+	if !route.DropTools {
+		t.Error("a model without tool calling should get the tools described in the prompt")
+	}
+
+	route = al.resolveCapabilityRoute(agent, turnNeeds{Tools: true, HaveTools: true})
+	if !route.Routed || route.ModelName != "tools" {
+		t.Errorf("a tool-heavy turn should move to the tool-capable model, got %+v", route)
+	}
+}
+
+func TestIsToolHeavy(t *testing.T) {
+	chat := []providers.Message{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}
+	if isToolHeavy(chat, processOptions{}) {
+		t.Error("a plain conversation is not tool-heavy")
+	}
+	withTools := append(chat, providers.Message{Role: "tool", Content: "ok", ToolCallID: "1"})
+	if !isToolHeavy(withTools, processOptions{}) {
+		t.Error("recent tool use makes a turn tool-heavy")
+	}
+	if !isToolHeavy(chat, processOptions{NoHistory: true}) {
+		t.Error("scheduled tasks are tool-heavy")
+	}
+}
+
+func TestLookupModelInfo_ByModelID(t *testing.T) {
+	cfg := &config.Config{ModelList: []config.ModelConfig{
+		{ModelName: "local", Model: "vllm/my-model", Capabilities: []string{"vision"}},
+	}}
+	if !lookupModelInfo(cfg, "my-model").Has(providers.CapabilityVision) {
+		t.Error("a model ID should resolve to its model_list entry")
+	}
+	if !lookupModelInfo(cfg, "local").Has(providers.CapabilityVision) {
+		t.Error("a model_list name should resolve to its entry")
+	}
+}
+
+func TestHasImageMedia(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	if !al.hasImageMedia([]string{"/tmp/photo.JPG"}) {
+		t.Error("expected jpg to be detected as image")
+	}
+	if al.hasImageMedia([]string{"/tmp/voice.ogg"}) {
+		t.Error("expected ogg not to be detected as image")
+	}
+}
@@ -0,0 +1,480 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/identity"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// CommandRequest is what a command gets to work with.
+type CommandRequest struct {
+	Msg        bus.InboundMessage
+	Args       []string
+	Agent      *AgentInstance
+	SessionKey string
+}
+
+// Command is a chat command answered without an LLM call.
+type Command struct {
+	Name  string // without the prefix
+	Usage string // written with "/", shown with the configured prefix
+	// Description is an i18n key; text that is not a key is shown as is.
+	Description string
+	OwnerOnly   bool
+	Run         func(al *AgentLoop, req CommandRequest) string
+}
+
+// builtinCommands returns the registry of slash commands, in /help order.
+func builtinCommands() []Command {
+	return []Command{
+		{Name: "help", Usage: "/help", Description: i18n.CommandHelpDesc, Run: cmdHelp},
+		{Name: "reset", Usage: "/reset", Description: i18n.CommandResetDesc, Run: cmdReset},
+		{Name: "whoami", Usage: "/whoami", Description: i18n.CommandWhoamiDesc, Run: cmdWhoami},
+		{
+			Name:        "model",
+			Usage:       "/model [name|default]",
+			Description: i18n.CommandModelDesc,
+			OwnerOnly:   true,
+			Run:         cmdModel,
+		},
+		{
+			Name:        "route",
+			Usage:       "/route [tier|auto]",
+			Description: i18n.CommandRouteDesc,
+			OwnerOnly:   true,
+			Run:         cmdRoute,
+		},
+		{Name: "status", Usage: "/status", Description: i18n.CommandStatusDesc, Run: cmdStatus},
+		{Name: "skills", Usage: "/skills", Description: i18n.CommandSkillsDesc, Run: cmdSkills},
+		{Name: "show", Usage: "/show [model|channel|agents]", Description: i18n.CommandShowDesc, Run: cmdShow},
+		{Name: "list", Usage: "/list [models|channels|agents]", Description: i18n.CommandListDesc, Run: cmdList},
+		{
+			Name:        "switch",
+			Usage:       "/switch [model|channel] to <name>",
+			Description: i18n.CommandSwitchDesc,
+			OwnerOnly:   true,
+			Run:         cmdSwitch,
+		},
+	}
+}
+
+// RegisterCommand adds a command to the built-in set, replacing a built-in
+// of the same name. It must be called before the loop starts handling
+// messages.
+func (al *AgentLoop) RegisterCommand(cmd Command) {
+	cmd.Name = strings.ToLower(cmd.Name)
+	al.extraCommands = slices.DeleteFunc(al.extraCommands, func(c Command) bool { return c.Name == cmd.Name })
+	al.extraCommands = append(al.extraCommands, cmd)
+}
+
+// commands returns the built-in commands followed by registered ones, in
+// /help order.
+func (al *AgentLoop) commands() []Command {
+	commands := builtinCommands()
+	for _, extra := range al.extraCommands {
+		if i := slices.IndexFunc(commands, func(c Command) bool { return c.Name == extra.Name }); i >= 0 {
+			commands[i] = extra
+		} else {
+			commands = append(commands, extra)
+		}
+	}
+	return commands
+}
+
+// parseCommand splits "<prefix>name@bot arg1 arg2" into its name and arguments.
+func parseCommand(content, prefix string) (string, []string, bool) {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, prefix) {
+		return "", nil, false
+	}
+	parts := strings.Fields(content)
+	name := strings.TrimPrefix(parts[0], prefix)
+	// Group chats address commands to a bot as /cmd@botname.
+	name, _, _ = strings.Cut(name, "@")
+	if name == "" {
+		return "", nil, false
+	}
+	return strings.ToLower(name), parts[1:], true
+}
+
+// handleCommand runs a chat command. Unknown or disabled commands are not
+// handled, so they reach the agent as normal messages.
+func (al *AgentLoop) handleCommand(
+	_ context.Context,
+	msg bus.InboundMessage,
+	agent *AgentInstance,
+	sessionKey string,
+) (string, bool) {
+	if al.cfg == nil || !al.cfg.Commands.Enabled {
+		return "", false
+	}
+
+	prefix := al.cfg.Commands.CommandPrefix()
+	name, args, ok := parseCommand(msg.Content, prefix)
+	if !ok {
+		return "", false
+	}
+
+	commands := al.commands()
+	idx := slices.IndexFunc(commands, func(c Command) bool { return c.Name == name })
+	if idx < 0 || !al.commandEnabled(name, msg.Channel) {
+		return "", false
+	}
+	cmd := commands[idx]
+
+	if cmd.OwnerOnly && !al.isCommandOwner(msg) {
+		logger.InfoCF("agent", "Owner-only command rejected",
+			map[string]any{"command": name, "channel": msg.Channel, "sender_id": msg.SenderID})
+		return i18n.T(al.language(msg.Channel), i18n.CommandOwnerOnly, prefix+name), true
+	}
+
+	logger.InfoCF("agent", "Handling built-in command",
+		map[string]any{"command": name, "channel": msg.Channel, "chat_id": msg.ChatID})
+
+	return cmd.Run(al, CommandRequest{Msg: msg, Args: args, Agent: agent, SessionKey: sessionKey}), true
+}
+
+// commandEnabled reports whether a command is enabled on a channel.
+func (al *AgentLoop) commandEnabled(name, channel string) bool {
+	cfg := al.cfg.Commands
+	if slices.Contains(cfg.Disabled, name) {
+		return false
+	}
+	disabled := cfg.ChannelDisabled[channel]
+	return !slices.Contains(disabled, "*") && !slices.Contains(disabled, name)
+}
+
+// isCommandOwner reports whether the sender may run owner-only commands:
+// someone listed in commands.owners, or the local user of the CLI. Without
+// owners nobody on a chat channel may.
+func (al *AgentLoop) isCommandOwner(msg bus.InboundMessage) bool {
+	return msg.Channel == "cli" || al.isOwner(msg)
+}
+
+// isOwner reports whether the sender is listed in commands.owners.
+func (al *AgentLoop) isOwner(msg bus.InboundMessage) bool {
+	idPart, _, _ := strings.Cut(msg.SenderID, "|")
+	for _, owner := range al.cfg.Commands.Owners {
+		if msg.SenderID == owner || idPart == owner || identity.MatchAllowed(msg.Sender, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// language returns the locale of canned replies on channel.
+func (al *AgentLoop) language(channel string) string {
+	if al.cfg == nil {
+		return ""
+	}
+	return al.cfg.Gateway.LanguageFor(channel)
+}
+
+// chatModel returns the model_list name used for a chat, or "" for the
+// agent's default model.
+func (al *AgentLoop) chatModel(channel, chatID string) string {
+	model, _ := al.chatModelSource(channel, chatID)
+	return model
+}
+
+// chatModelSource returns the chat's model and where it was set: an override
+// persisted with /model wins over channels.<name>.chats.<chat_id>.model.
+func (al *AgentLoop) chatModelSource(channel, chatID string) (model, source string) {
+	if al.state != nil {
+		if model := al.state.GetChatModel(channel + ":" + chatID); model != "" {
+			return model, "set with /model"
+		}
+	}
+	if al.cfg != nil {
+		if model := al.cfg.Channels.ChatModel(channel, chatID); model != "" {
+			return model, "from config"
+		}
+	}
+	return "", ""
+}
+
+// describeChatModel renders the effective model of a chat for /model and /status.
+func (al *AgentLoop) describeChatModel(agent *AgentInstance, channel, chatID string) string {
+	model, source := al.chatModelSource(channel, chatID)
+	if model == "" {
+		return fmt.Sprintf("%s (default)", agent.Model)
+	}
+	return fmt.Sprintf("%s (%s)", model, source)
+}
+
+// displayUsage shows a usage written with "/" with the configured prefix.
+func displayUsage(prefix, usage string) string {
+	return prefix + strings.TrimPrefix(usage, "/")
+}
+
+// commandUsage returns the usage reply of the command called name.
+func (al *AgentLoop) commandUsage(name string) string {
+	for _, c := range al.commands() {
+		if c.Name == name {
+			return "Usage: " + displayUsage(al.cfg.Commands.CommandPrefix(), c.Usage)
+		}
+	}
+	return ""
+}
+
+func cmdHelp(al *AgentLoop, req CommandRequest) string {
+	lang := al.language(req.Msg.Channel)
+	var sb strings.Builder
+	prefix := al.cfg.Commands.CommandPrefix()
+	sb.WriteString(i18n.T(lang, i18n.CommandHelpHeader) + "\n")
+	for _, c := range al.commands() {
+		if !al.commandEnabled(c.Name, req.Msg.Channel) {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s - %s", displayUsage(prefix, c.Usage), i18n.T(lang, c.Description))
+		if c.OwnerOnly && len(al.cfg.Commands.Owners) > 0 {
+			sb.WriteString(i18n.T(lang, i18n.CommandHelpOwnerTag))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(i18n.T(lang, i18n.CommandHelpFooter))
+	return sb.String()
+}
+
+func cmdReset(al *AgentLoop, req CommandRequest) string {
+	lang := al.language(req.Msg.Channel)
+	sessions := req.Agent.Sessions
+	sessions.SetHistory(req.SessionKey, nil)
+	sessions.SetSummary(req.SessionKey, "")
+	if err := sessions.Save(req.SessionKey); err != nil {
+		return i18n.T(lang, i18n.CommandResetFailed, err)
+	}
+	// Contacts confirmed for send_message must be confirmed again
+	if st, ok := sendMessageTool(req.Agent); ok {
+		st.EndConversation(req.Msg.Channel, req.Msg.ChatID)
+	}
+	return i18n.T(lang, i18n.CommandResetDone)
+}
+
+func cmdWhoami(al *AgentLoop, req CommandRequest) string {
+	lang := al.language(req.Msg.Channel)
+	sender := req.Msg.SenderID
+	if req.Msg.Sender.CanonicalID != "" {
+		sender = req.Msg.Sender.CanonicalID
+	}
+	if name := req.Msg.Sender.DisplayName; name != "" {
+		sender = fmt.Sprintf("%s (%s)", name, sender)
+	} else if name := req.Msg.Sender.Username; name != "" {
+		sender = fmt.Sprintf("%s (%s)", name, sender)
+	}
+	reply := i18n.T(lang, i18n.CommandWhoamiReply, sender, req.Msg.Channel, req.Msg.ChatID, req.Agent.ID)
+	if len(al.cfg.Commands.Owners) > 0 && al.isOwner(req.Msg) {
+		reply += "\n" + i18n.T(lang, i18n.CommandWhoamiOwner)
+	}
+	return reply
+}
+
+func cmdModel(al *AgentLoop, req CommandRequest) string {
+	lang := al.language(req.Msg.Channel)
+	if len(req.Args) == 0 {
+		return i18n.T(lang, i18n.CommandModelCurrent, al.describeChatModel(req.Agent, req.Msg.Channel, req.Msg.ChatID))
+	}
+	if al.state == nil {
+		return i18n.T(lang, i18n.CommandModelNoState)
+	}
+
+	chatKey := req.Msg.Channel + ":" + req.Msg.ChatID
+	name := req.Args[0]
+	if name == "default" || name == "reset" {
+		if err := al.state.SetChatModel(chatKey, ""); err != nil {
+			return i18n.T(lang, i18n.CommandModelSetFailed, err)
+		}
+		return i18n.T(lang, i18n.CommandModelSwitched, al.describeChatModel(req.Agent, req.Msg.Channel, req.Msg.ChatID))
+	}
+
+	if _, err := al.cfg.GetModelConfig(name); err != nil {
+		valid := al.cfg.ModelNames()
+		if len(valid) == 0 {
+			return i18n.T(lang, i18n.CommandModelNoModels, name)
+		}
+		return i18n.T(lang, i18n.CommandModelUnknown, name, strings.Join(valid, ", "))
+	}
+	if err := al.state.SetChatModel(chatKey, name); err != nil {
+		return i18n.T(lang, i18n.CommandModelSetFailed, err)
+	}
+	return i18n.T(lang, i18n.CommandModelSwitched, name)
+}
+
+func cmdRoute(al *AgentLoop, req CommandRequest) string {
+	lang := al.language(req.Msg.Channel)
+	ar := al.cfg.Agents.Defaults.AutoRoute
+	if !ar.Enabled || len(ar.Tiers) == 0 {
+		return i18n.T(lang, i18n.CommandRouteOff)
+	}
+	tiers := strings.Join(ar.TierNames(), ", ")
+	chatKey := req.Msg.Channel + ":" + req.Msg.ChatID
+	if len(req.Args) == 0 {
+		if al.state != nil {
+			if tier, ok := ar.Tier(al.state.GetChatTier(chatKey)); ok {
+				return i18n.T(lang, i18n.CommandRoutePinned, tier.Name)
+			}
+		}
+		return i18n.T(lang, i18n.CommandRouteAuto, tiers)
+	}
+	if al.state == nil {
+		return i18n.T(lang, i18n.CommandModelNoState)
+	}
+
+	name := req.Args[0]
+	if strings.EqualFold(name, "auto") {
+		if err := al.state.SetChatTier(chatKey, ""); err != nil {
+			return i18n.T(lang, i18n.CommandRouteSetFailed, err)
+		}
+		return i18n.T(lang, i18n.CommandRouteAuto, tiers)
+	}
+	tier, ok := ar.Tier(name)
+	if !ok {
+		return i18n.T(lang, i18n.CommandRouteUnknown, name, tiers)
+	}
+	if err := al.state.SetChatTier(chatKey, tier.Name); err != nil {
+		return i18n.T(lang, i18n.CommandRouteSetFailed, err)
+	}
+	return i18n.T(lang, i18n.CommandRoutePinned, tier.Name)
+}
+
+func cmdStatus(al *AgentLoop, req CommandRequest) string {
+	history := req.Agent.Sessions.GetHistory(req.SessionKey)
+	var user, assistant, toolCalls int
+	for _, m := range history {
+		switch m.Role {
+		case "user":
+			user++
+		case "assistant":
+			assistant++
+			toolCalls += len(m.ToolCalls)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Agent: %s\n", req.Agent.ID)
+	fmt.Fprintf(&sb, "Model: %s\n", al.describeChatModel(req.Agent, req.Msg.Channel, req.Msg.ChatID))
+	fmt.Fprintf(&sb, "Messages in session: %d (%d from you, %d replies, %d tool calls)\n",
+		len(history), user, assistant, toolCalls)
+	fmt.Fprintf(&sb, "Context size: ~%d tokens of %d\n", al.estimateTokens(history), req.Agent.ContextWindow)
+	if usage := al.usage.Get(req.SessionKey); usage.Requests > 0 {
+		fmt.Fprintf(&sb, "Tokens used: %d prompt (%d cached), %d completion over %d requests\n",
+			usage.PromptTokens, usage.CachedPromptTokens, usage.CompletionTokens, usage.Requests)
+		if usage.Priced {
+			fmt.Fprintf(&sb, "Estimated cost: $%.4f\n", usage.Cost)
+		}
+		if len(usage.Models) > 1 {
+			models := slices.Sorted(maps.Keys(usage.Models))
+			for i, m := range models {
+				models[i] = fmt.Sprintf("%s (%d)", m, usage.Models[m])
+			}
+			fmt.Fprintf(&sb, "Models used: %s\n", strings.Join(models, ", "))
+		}
+	}
+	if req.Agent.Sessions.GetSummary(req.SessionKey) != "" {
+		sb.WriteString("Older messages have been summarized.")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func cmdSkills(al *AgentLoop, req CommandRequest) string {
+	lang := al.language(req.Msg.Channel)
+	skills := req.Agent.ContextBuilder.ListSkills()
+	if len(skills) == 0 {
+		return i18n.T(lang, i18n.CommandSkillsNone)
+	}
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.CommandSkillsHeader, len(skills)) + "\n")
+	for _, s := range skills {
+		fmt.Fprintf(&sb, "• %s", s.Name)
+		if s.Description != "" {
+			fmt.Fprintf(&sb, " - %s", s.Description)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func cmdShow(al *AgentLoop, req CommandRequest) string {
+	if len(req.Args) < 1 {
+		return al.commandUsage("show")
+	}
+	switch req.Args[0] {
+	case "model":
+		defaultAgent := al.registry.GetDefaultAgent()
+		if defaultAgent == nil {
+			return "No default agent configured"
+		}
+		return fmt.Sprintf("Current model: %s", defaultAgent.Model)
+	case "channel":
+		return fmt.Sprintf("Current channel: %s", req.Msg.Channel)
+	case "agents":
+		agentIDs := al.registry.ListAgentIDs()
+		return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", "))
+	default:
+		return fmt.Sprintf("Unknown show target: %s", req.Args[0])
+	}
+}
+
+func cmdList(al *AgentLoop, req CommandRequest) string {
+	if len(req.Args) < 1 {
+		return al.commandUsage("list")
+	}
+	switch req.Args[0] {
+	case "models":
+		if len(al.cfg.ModelList) == 0 {
+			return "Available models: configured in config.json per agent"
+		}
+		return fmt.Sprintf("Available models: %s", strings.Join(al.cfg.ModelNames(), ", "))
+	case "channels":
+		if al.channelManager == nil {
+			return "Channel manager not initialized"
+		}
+		channels := al.channelManager.GetEnabledChannels()
+		if len(channels) == 0 {
+			return "No channels enabled"
+		}
+		return fmt.Sprintf("Enabled channels: %s", strings.Join(channels, ", "))
+	case "agents":
+		agentIDs := al.registry.ListAgentIDs()
+		return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", "))
+	default:
+		return fmt.Sprintf("Unknown list target: %s", req.Args[0])
+	}
+}
+
+func cmdSwitch(al *AgentLoop, req CommandRequest) string {
+	args := req.Args
+	if len(args) < 3 || args[1] != "to" {
+		return al.commandUsage("switch")
+	}
+	target := args[0]
+	value := args[2]
+
+	switch target {
+	case "model":
+		defaultAgent := al.registry.GetDefaultAgent()
+		if defaultAgent == nil {
+			return "No default agent configured"
+		}
+		oldModel := defaultAgent.Model
+		defaultAgent.Model = value
+		return fmt.Sprintf("Switched model from %s to %s", oldModel, value)
+	case "channel":
+		if al.channelManager == nil {
+			return "Channel manager not initialized"
+		}
+		if _, exists := al.channelManager.GetChannel(value); !exists && value != "cli" {
+			return fmt.Sprintf("Channel '%s' not found or not enabled", value)
+		}
+		return fmt.Sprintf("Switched target channel to %s", value)
+	default:
+		return fmt.Sprintf("Unknown switch target: %s", target)
+	}
+}
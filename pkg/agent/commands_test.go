@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func commandMessage(content string) bus.InboundMessage {
+	return bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "42",
+		ChatID:   "chat1",
+		Content:  content,
+		Peer:     bus.Peer{Kind: "direct", ID: "42"},
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantName string
+		wantArgs int
+		wantOK   bool
+	}{
+		{"/reset", "reset", 0, true},
+		{"  /Model gpt-5.2 ", "model", 1, true},
+		{"/status@picobot", "status", 0, true},
+		{"hello /reset", "", 0, false},
+		{"/", "", 0, false},
+	}
+	for _, tt := range tests {
+		name, args, ok := parseCommand(tt.in, "/")
+		if ok != tt.wantOK || name != tt.wantName || len(args) != tt.wantArgs {
+			t.Errorf("parseCommand(%q) = (%q, %v, %v), want (%q, %d args, %v)",
+				tt.in, name, args, ok, tt.wantName, tt.wantArgs, tt.wantOK)
+		}
+	}
+}
+
+func TestHandleCommand_ResetClearsSession(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+
+	ctx := context.Background()
+	if _, err := al.processMessage(ctx, commandMessage("hello")); err != nil {
+		t.Fatalf("processMessage failed: %v", err)
+	}
+
+	agent := al.registry.GetDefaultAgent()
+	const sessionKey = "agent:main:main" // default dm_scope shares one session
+	if len(agent.Sessions.GetHistory(sessionKey)) == 0 {
+		t.Fatal("expected the first message to create session history")
+	}
+
+	resp, err := al.processMessage(ctx, commandMessage("/reset"))
+	if err != nil {
+		t.Fatalf("processMessage failed: %v", err)
+	}
+	if !strings.Contains(resp, "cleared") {
+		t.Errorf("unexpected /reset response: %q", resp)
+	}
+	if n := len(agent.Sessions.GetHistory(sessionKey)); n != 0 {
+		t.Errorf("history has %d messages after /reset, want 0", n)
+	}
+}
+
+func TestHandleCommand_RepliesInChannelLanguage(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.Commands.Owners = config.FlexibleStringSlice{"owner"}
+	cfg.Gateway.Language = "zh-CN"
+	cfg.Gateway.ChannelLanguages = map[string]string{"line": "ja"}
+
+	agent := al.registry.GetDefaultAgent()
+	resp, _ := al.handleCommand(context.Background(), commandMessage("/model gpt4"), agent, "s")
+	if resp != "/model 仅限机器人所有者使用。" {
+		t.Errorf("telegram reply = %q, want the gateway language", resp)
+	}
+
+	msg := commandMessage("/help")
+	msg.Channel = "line"
+	resp, _ = al.handleCommand(context.Background(), msg, agent, "s")
+	if !strings.HasPrefix(resp, "使用できるコマンド:") || !strings.Contains(resp, "（所有者のみ）") {
+		t.Errorf("line /help = %q, want the channel override", resp)
+	}
+}
+
+func TestHandleCommand_UnknownAndDisabledFallThrough(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.Commands.ChannelDisabled = map[string][]string{"telegram": {"status"}}
+
+	agent := al.registry.GetDefaultAgent()
+	for _, content := range []string{"/weather Paris", "/status"} {
+		if _, handled := al.handleCommand(context.Background(), commandMessage(content), agent, "s"); handled {
+			t.Errorf("%q should fall through to the agent", content)
+		}
+	}
+
+	msg := commandMessage("/status")
+	msg.Channel = "discord"
+	if _, handled := al.handleCommand(context.Background(), msg, agent, "s"); !handled {
+		t.Errorf("/status should be handled on channels where it is not disabled")
+	}
+
+	cfg.Commands.Enabled = false
+	if _, handled := al.handleCommand(context.Background(), msg, agent, "s"); handled {
+		t.Errorf("commands should not be handled when disabled in config")
+	}
+}
+
+func TestHandleCommand_OwnerOnlyFailsClosedWithoutOwners(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.ModelList = []config.ModelConfig{{ModelName: "fast", Model: "openai/gpt-5.2", APIKey: "k"}}
+
+	agent := al.registry.GetDefaultAgent()
+	resp, _ := al.handleCommand(context.Background(), commandMessage("/model fast"), agent, "s")
+	if !strings.Contains(resp, "restricted") {
+		t.Errorf("/model without owners = %q, want restriction notice", resp)
+	}
+
+	local := commandMessage("/model fast")
+	local.Channel = "cli"
+	if resp, _ := al.handleCommand(context.Background(), local, agent, "s"); strings.Contains(resp, "restricted") {
+		t.Errorf("/model on the CLI = %q, want it allowed", resp)
+	}
+}
+
+func TestHandleCommand_ModelIsOwnerOnlyAndPersisted(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.Commands.Owners = config.FlexibleStringSlice{"telegram:1"}
+	cfg.ModelList = []config.ModelConfig{{ModelName: "fast", Model: "openai/gpt-5.2", APIKey: "k"}}
+
+	agent := al.registry.GetDefaultAgent()
+
+	resp, _ := al.handleCommand(context.Background(), commandMessage("/model fast"), agent, "s")
+	if !strings.Contains(resp, "restricted") {
+		t.Fatalf("non-owner /model response = %q, want restriction notice", resp)
+	}
+	if got := al.chatModel("telegram", "chat1"); got != "" {
+		t.Fatalf("non-owner changed the chat model to %q", got)
+	}
+
+	owner := commandMessage("/model fast")
+	owner.Sender = bus.SenderInfo{Platform: "telegram", PlatformID: "1", CanonicalID: "telegram:1"}
+	if resp, _ := al.handleCommand(context.Background(), owner, agent, "s"); !strings.Contains(resp, "fast") {
+		t.Fatalf("owner /model response = %q", resp)
+	}
+	if got := al.chatModel("telegram", "chat1"); got != "fast" {
+		t.Errorf("chat model = %q, want fast", got)
+	}
+	if got := al.chatModel("telegram", "other"); got != "" {
+		t.Errorf("model override leaked to another chat: %q", got)
+	}
+
+	owner.Content = "/model nope"
+	if resp, _ := al.handleCommand(context.Background(), owner, agent, "s"); !strings.Contains(resp, "Valid models: fast") {
+		t.Errorf("unknown model response = %q", resp)
+	}
+}
+
+func TestChatModel_StateOverrideWinsOverConfig(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.ModelList = []config.ModelConfig{
+		{ModelName: "cheap", Model: "openai/gpt-5-mini", APIKey: "k"},
+		{ModelName: "best", Model: "openai/gpt-5.2", APIKey: "k"},
+	}
+	cfg.Commands.Owners = config.FlexibleStringSlice{"42"}
+	cfg.Channels.Telegram.Chats = map[string]config.ChatConfig{"chat1": {Model: "cheap"}}
+
+	agent := al.registry.GetDefaultAgent()
+	if got := al.chatModel("telegram", "chat1"); got != "cheap" {
+		t.Fatalf("chat model = %q, want cheap from config", got)
+	}
+	resp, _ := al.handleCommand(context.Background(), commandMessage("/status"), agent, "s")
+	if !strings.Contains(resp, "Model: cheap (from config)") {
+		t.Errorf("/status response = %q", resp)
+	}
+
+	al.handleCommand(context.Background(), commandMessage("/model best"), agent, "s")
+	if got := al.chatModel("telegram", "chat1"); got != "best" {
+		t.Fatalf("chat model = %q, want best from /model", got)
+	}
+
+	resp, _ = al.handleCommand(context.Background(), commandMessage("/model default"), agent, "s")
+	if !strings.Contains(resp, "cheap (from config)") {
+		t.Errorf("/model default response = %q", resp)
+	}
+}
+
+func TestHandleCommand_Whoami(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.Commands.Owners = config.FlexibleStringSlice{"42"}
+
+	msg := commandMessage("/whoami")
+	msg.Sender = bus.SenderInfo{CanonicalID: "telegram:42", Username: "alice"}
+	resp, handled := al.handleCommand(context.Background(), msg, al.registry.GetDefaultAgent(), "s")
+	if !handled {
+		t.Fatal("/whoami was not handled")
+	}
+	want := "You are alice (telegram:42) on telegram, chat chat1, talking to agent main.\nYou are a bot owner."
+	if resp != want {
+		t.Errorf("/whoami = %q, want %q", resp, want)
+	}
+}
+
+func TestHandleCommand_CustomPrefixAndRegisteredCommand(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.Commands.Prefix = "!"
+	al.RegisterCommand(Command{
+		Name:        "ping",
+		Usage:       "/ping",
+		Description: "Check the bot is alive",
+		Run:         func(_ *AgentLoop, req CommandRequest) string { return "pong " + strings.Join(req.Args, " ") },
+	})
+
+	agent := al.registry.GetDefaultAgent()
+	if resp, handled := al.handleCommand(context.Background(), commandMessage("!ping a b"), agent, "s"); resp != "pong a b" ||
+		!handled {
+		t.Errorf("!ping = (%q, %v), want pong a b", resp, handled)
+	}
+	if _, handled := al.handleCommand(context.Background(), commandMessage("/ping"), agent, "s"); handled {
+		t.Error("/ping should fall through when the prefix is !")
+	}
+
+	resp, _ := al.handleCommand(context.Background(), commandMessage("!help"), agent, "s")
+	if !strings.Contains(resp, "!ping - Check the bot is alive") || !strings.Contains(resp, "!reset - ") {
+		t.Errorf("!help does not list commands with the prefix:\n%s", resp)
+	}
+
+	resp, _ = al.handleCommand(context.Background(), commandMessage("!list"), agent, "s")
+	if resp != "Usage: !list [models|channels|agents]" {
+		t.Errorf("!list without a target = %q, want the usage with the prefix", resp)
+	}
+}
@@ -19,8 +19,12 @@ import (
 
 type ContextBuilder struct {
 	workspace    string
+	globalDir    string // holds the global IDENTITY.md; see persona.go
+	agentPrompt  string // the agent's system_prompt_file, "" when unset
+	noSkills     bool   // leave skills out of the system prompt
 	skillsLoader *skills.SkillsLoader
 	memory       *MemoryStore
+	files        promptFileCache
 
 	// Cache for system prompt to avoid rebuilding on every call.
 	// This fixes issue #607: repeated reprocessing of the entire context.
@@ -34,6 +38,10 @@ type ContextBuilder struct {
 	// created (didn't exist at cache time, now exist) or deleted (existed at
 	// cache time, now gone) — both of which should trigger a cache rebuild.
 	existedAtCache map[string]bool
+
+	// responseLanguage returns the response language setting of a channel;
+	// see SetResponseLanguage.
+	responseLanguage func(channel string) string
 }
 
 func getGlobalConfigDir() string {
@@ -49,10 +57,12 @@ func NewContextBuilder(workspace string) *ContextBuilder {
 	// Use the skills/ directory under the current working directory
 	wd, _ := os.Getwd()
 	builtinSkillsDir := filepath.Join(wd, "skills")
-	globalSkillsDir := filepath.Join(getGlobalConfigDir(), "skills")
+	globalDir := getGlobalConfigDir()
+	globalSkillsDir := filepath.Join(globalDir, "skills")
 
 	return &ContextBuilder{
 		workspace:    workspace,
+		globalDir:    globalDir,
 		skillsLoader: skills.NewSkillsLoader(workspace, globalSkillsDir, builtinSkillsDir),
 		memory:       NewMemoryStore(workspace),
 	}
@@ -79,24 +89,32 @@ Your workspace is at: %s
 
 3. **Memory** - When interacting with me if something seems memorable, update %s/memory/MEMORY.md
 
-4. **Context summaries** - Conversation summaries provided as context are approximate references only. They may be incomplete or outdated. Always defer to explicit user instructions over summary content.`,
-		workspacePath, workspacePath, workspacePath, workspacePath, workspacePath)
+4. **Context summaries** - Conversation summaries provided as context are approximate references only. They may be incomplete or outdated. Always defer to explicit user instructions over summary content.
+
+5. **Choices** - To let the user pick from a few options, end your reply with a card block. Chat apps show it as buttons or numbered options, and the chosen button's value comes back as the user's next message:
+%s`,
+		workspacePath, workspacePath, workspacePath, workspacePath, workspacePath, cardExample)
 }
 
+// cardExample shows the model how to offer choices, see bus.ExtractCard.
+const cardExample = "```card\n" +
+	`{"title": "Dinner?", "buttons": [{"label": "Sushi", "value": "sushi"}, {"label": "Pizza"}]}` +
+	"\n```"
+
 func (cb *ContextBuilder) BuildSystemPrompt() string {
 	parts := []string{}
 
 	// Core identity section
 	parts = append(parts, cb.getIdentity())
 
-	// Bootstrap files
-	bootstrapContent := cb.LoadBootstrapFiles()
-	if bootstrapContent != "" {
-		parts = append(parts, bootstrapContent)
-	}
+	// Global identity, agent prompt and bootstrap files
+	parts = append(parts, cb.personaParts()...)
 
 	// Skills - show summary, AI can read full content with read_file tool
-	skillsSummary := cb.skillsLoader.BuildSkillsSummary()
+	skillsSummary := ""
+	if !cb.noSkills {
+		skillsSummary = cb.skillsLoader.BuildSkillsSummary()
+	}
 	if skillsSummary != "" {
 		parts = append(parts, fmt.Sprintf(`# Skills
 
@@ -115,6 +133,39 @@ The following skills extend your capabilities. To use a skill, read its SKILL.md
 	return strings.Join(parts, "\n\n---\n\n")
 }
 
+// personaParts returns the system prompt sections that give the agent its
+// persona: the global identity, the agent prompt and the bootstrap files.
+func (cb *ContextBuilder) personaParts() []string {
+	var parts []string
+
+	// Global identity, shared by every workspace
+	if path := cb.globalIdentityPath(); path != "" {
+		if data, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) != "" {
+			parts = append(parts, "# Global Identity\n\n"+strings.TrimSpace(string(data)))
+		}
+	}
+
+	// Agent prompt, for agents that share a workspace but not a role
+	if cb.agentPrompt != "" {
+		if data, err := os.ReadFile(cb.agentPrompt); err == nil && strings.TrimSpace(string(data)) != "" {
+			parts = append(parts, "# Agent Prompt\n\n"+strings.TrimSpace(string(data)))
+		}
+	}
+
+	// Bootstrap files
+	if bootstrapContent := cb.LoadBootstrapFiles(); bootstrapContent != "" {
+		parts = append(parts, bootstrapContent)
+	}
+	return parts
+}
+
+// BuildPersonaPrompt returns the persona sections of the system prompt
+// without the tool rules, skills and memory, for replies made without
+// tools. It returns "" when the workspace defines no persona.
+func (cb *ContextBuilder) BuildPersonaPrompt() string {
+	return strings.Join(cb.personaParts(), "\n\n---\n\n")
+}
+
 // BuildSystemPromptWithCache returns the cached system prompt if available
 // and source files haven't changed, otherwise builds and caches it.
 // Source file changes are detected via mtime checks (cheap stat calls).
@@ -176,13 +227,29 @@ func (cb *ContextBuilder) InvalidateCache() {
 // separately in sourceFilesChangedLocked because it requires both directory-
 // level and recursive file-level mtime checks.
 func (cb *ContextBuilder) sourcePaths() []string {
-	return []string{
+	paths := []string{
 		filepath.Join(cb.workspace, "AGENTS.md"),
 		filepath.Join(cb.workspace, "SOUL.md"),
 		filepath.Join(cb.workspace, "USER.md"),
 		filepath.Join(cb.workspace, "IDENTITY.md"),
 		filepath.Join(cb.workspace, "memory", "MEMORY.md"),
 	}
+	if path := cb.globalIdentityPath(); path != "" {
+		paths = append(paths, path)
+	}
+	if cb.agentPrompt != "" {
+		paths = append(paths, cb.agentPrompt)
+	}
+	return paths
+}
+
+// globalIdentityPath returns the IDENTITY.md shared by all workspaces, or ""
+// when there is no global config dir.
+func (cb *ContextBuilder) globalIdentityPath() string {
+	if cb.globalDir == "" {
+		return ""
+	}
+	return filepath.Join(cb.globalDir, "IDENTITY.md")
 }
 
 // cacheBaseline holds the file existence snapshot and the latest observed
@@ -374,6 +441,36 @@ func (cb *ContextBuilder) buildDynamicContext(channel, chatID string) string {
 	return sb.String()
 }
 
+// SetAgentPrompt adds the file at path to the system prompt, after the
+// global identity. A relative path is resolved against the workspace.
+func (cb *ContextBuilder) SetAgentPrompt(path string) {
+	if path != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(cb.workspace, path)
+	}
+	cb.agentPrompt = path
+}
+
+// SetSkills limits the skills listed in the system prompt to names; an
+// empty list lists every installed skill.
+func (cb *ContextBuilder) SetSkills(names []string) {
+	cb.skillsLoader.SetAllowed(names)
+}
+
+// OmitSkills leaves the skills section out of the system prompt. Skills are
+// still loaded, so startup info and the skills tools see them.
+func (cb *ContextBuilder) OmitSkills() {
+	cb.noSkills = true
+	cb.InvalidateCache()
+}
+
+// SetResponseLanguage sets how the reply language of a channel is looked
+// up: a language name, "auto" to follow the user's message, or "" for no
+// instruction. The instruction goes into the per-request context, so it
+// composes with the workspace prompt files instead of replacing them.
+func (cb *ContextBuilder) SetResponseLanguage(lookup func(channel string) string) {
+	cb.responseLanguage = lookup
+}
+
 func (cb *ContextBuilder) BuildMessages(
 	history []providers.Message,
 	summary string,
@@ -396,6 +493,11 @@ func (cb *ContextBuilder) BuildMessages(
 
 	// Build short dynamic context (time, runtime, session) — changes per request
 	dynamicCtx := cb.buildDynamicContext(channel, chatID)
+	if cb.responseLanguage != nil {
+		if instruction := responseLanguageInstruction(cb.responseLanguage(channel), currentMessage); instruction != "" {
+			dynamicCtx += "\n\n" + instruction
+		}
+	}
 
 	// Compose a single system message: static (cached) + dynamic + optional summary.
 	// Keeping all system content in one message ensures every provider adapter can
@@ -406,13 +508,21 @@ func (cb *ContextBuilder) BuildMessages(
 	// cache-aware adapters (Anthropic) can set per-block cache_control.
 	// The static block is marked "ephemeral" — its prefix hash is stable
 	// across requests, enabling LLM-side KV cache reuse.
-	stringParts := []string{staticPrompt, dynamicCtx}
-
+	stringParts := []string{staticPrompt}
 	contentBlocks := []providers.ContentBlock{
 		{Type: "text", Text: staticPrompt, CacheControl: &providers.CacheControl{Type: "ephemeral"}},
-		{Type: "text", Text: dynamicCtx},
 	}
 
+	// Channel and chat personas come after the workspace files so they can
+	// refine them for one place.
+	if persona := cb.buildPersonaContext(channel, chatID); persona != "" {
+		stringParts = append(stringParts, persona)
+		contentBlocks = append(contentBlocks, providers.ContentBlock{Type: "text", Text: persona})
+	}
+
+	stringParts = append(stringParts, dynamicCtx)
+	contentBlocks = append(contentBlocks, providers.ContentBlock{Type: "text", Text: dynamicCtx})
+
 	if summary != "" {
 		summaryText := fmt.Sprintf(
 			"CONTEXT_SUMMARY: The following is an approximate summary of prior conversation "+
@@ -567,6 +677,16 @@ func (cb *ContextBuilder) AddAssistantMessage(
 }
 
 // GetSkillsInfo returns information about loaded skills.
+// ListSkills returns the skills available to the agent.
+func (cb *ContextBuilder) ListSkills() []skills.SkillInfo {
+	return cb.skillsLoader.ListSkills()
+}
+
+// ListSkillCommands returns the commands declared by the agent's skills.
+func (cb *ContextBuilder) ListSkillCommands() []skills.SkillCommandInfo {
+	return cb.skillsLoader.ListCommands()
+}
+
 func (cb *ContextBuilder) GetSkillsInfo() map[string]any {
 	allSkills := cb.skillsLoader.ListSkills()
 	skillNames := make([]string, 0, len(allSkills))
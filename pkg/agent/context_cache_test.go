@@ -241,6 +241,26 @@ func TestExplicitInvalidateCache(t *testing.T) {
 	cb.systemPromptMutex.RUnlock()
 }
 
+// TestOmitSkills verifies that OmitSkills drops the skills section from a
+// prompt that was already cached.
+func TestOmitSkills(t *testing.T) {
+	tmpDir := setupWorkspace(t, map[string]string{
+		"skills/demo/SKILL.md": "---\nname: demo\ndescription: \"demo skill\"\n---\n# Demo",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	cb := NewContextBuilder(tmpDir)
+	if sp := cb.BuildSystemPromptWithCache(); !strings.Contains(sp, "# Skills") {
+		t.Fatal("prompt should list the demo skill")
+	}
+
+	cb.OmitSkills()
+	sp := cb.BuildSystemPromptWithCache()
+	if strings.Contains(sp, "# Skills") || strings.Contains(sp, "demo skill") {
+		t.Error("prompt should leave skills out after OmitSkills()")
+	}
+}
+
 // TestCacheStability verifies that the static prompt is stable across repeated calls
 // when no files change (regression test for issue #607).
 func TestCacheStability(t *testing.T) {
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// degradedHistoryMessages is how many recent user/assistant messages the
+// degraded retry keeps from the session history.
+const degradedHistoryMessages = 6
+
+const degradedSystemPrompt = `You are picoclaw, a helpful AI assistant.
+
+Your tools are unavailable for this reply. Answer the user's last message as well as you can from the conversation and your own knowledge. Do not claim to have run commands, read files or taken any other action. If the request cannot be done without tools, say so briefly and suggest what the user can try.`
+
+// degradedReply retries a failed turn once with a trimmed prompt and no
+// tools: a short system prompt with the agent's persona, the session
+// summary and the last few plain messages. The answer is prefixed with a note that it is degraded.
+func (al *AgentLoop) degradedReply(
+	ctx context.Context,
+	agent *AgentInstance,
+	history []providers.Message,
+	summary string,
+	opts processOptions,
+) (string, error) {
+	logger.WarnCF("agent", "Turn failed, retrying without tools",
+		map[string]any{"agent_id": agent.ID, "session_key": opts.SessionKey})
+
+	persona := agent.ContextBuilder.BuildPersonaPrompt()
+	messages := degradedMessages(persona, history, summary, opts.UserMessage)
+	options := map[string]any{
+		"max_tokens":  agent.outputTokens(agent.MaxTokens),
+		"temperature": agent.Temperature,
+	}
+
+	var resp *providers.LLMResponse
+	var err error
+	usageModel := agent.Model
+	if len(agent.Candidates) > 1 && al.fallback != nil {
+		var fbResult *providers.FallbackResult
+		fbResult, err = al.fallback.Execute(ctx, agent.Candidates,
+			func(ctx context.Context, provider, model string) (*providers.LLMResponse, error) {
+				ctx, cancel := agent.requestContext(ctx)
+				defer cancel()
+				return agent.Provider.Chat(ctx, messages, nil, model, options)
+			})
+		if err == nil {
+			resp = fbResult.Response
+			if fbResult.Name != "" {
+				usageModel = fbResult.Name
+			}
+		}
+	} else {
+		callCtx, cancel := agent.requestContext(ctx)
+		defer cancel()
+		resp, err = agent.Provider.Chat(callCtx, messages, nil, agent.Model, options)
+	}
+	if err != nil {
+		return "", err
+	}
+	al.recordUsage(opts.SessionKey, usageModel, resp.Usage)
+
+	content := strings.TrimSpace(resp.Content)
+	if content == "" {
+		return "", fmt.Errorf("empty degraded response")
+	}
+	return i18n.T(al.language(opts.Channel), i18n.AgentDegradedNote) + "\n\n" + content, nil
+}
+
+// degradedMessages builds the trimmed prompt of a degraded retry. Tool calls
+// and results are dropped since the model gets no tools to match them.
+// persona, the identity and bootstrap files of the agent, follows the
+// fixed instructions so the reply keeps the agent's voice.
+func degradedMessages(persona string, history []providers.Message, summary, userMessage string) []providers.Message {
+	system := degradedSystemPrompt
+	if persona != "" {
+		system += "\n\n---\n\n" + persona
+	}
+	if summary != "" {
+		system += "\n\n## Summary of earlier conversation\n\n" + summary
+	}
+
+	var recent []providers.Message
+	for i := len(history) - 1; i >= 0 && len(recent) < degradedHistoryMessages; i-- {
+		m := history[i]
+		if (m.Role != "user" && m.Role != "assistant") || m.Content == "" {
+			continue
+		}
+		recent = append(recent, providers.Message{Role: m.Role, Content: m.Content})
+	}
+
+	messages := make([]providers.Message, 0, len(recent)+2)
+	messages = append(messages, providers.Message{Role: "system", Content: system})
+	for i := len(recent) - 1; i >= 0; i-- {
+		messages = append(messages, recent[i])
+	}
+	return append(messages, providers.Message{Role: "user", Content: userMessage})
+}
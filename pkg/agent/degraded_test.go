@@ -0,0 +1,133 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// recordingFailFirstProvider fails the first call and records the tools
+// and messages of every call.
+type recordingFailFirstProvider struct {
+	calls    int
+	tools    [][]providers.ToolDefinition
+	messages [][]providers.Message
+}
+
+func (p *recordingFailFirstProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls++
+	p.tools = append(p.tools, tools)
+	p.messages = append(p.messages, messages)
+	if p.calls == 1 {
+		return nil, errors.New("upstream exploded")
+	}
+	return &providers.LLMResponse{Content: "Best guess answer"}, nil
+}
+
+func (p *recordingFailFirstProvider) GetDefaultModel() string { return "test-model" }
+
+const degradedSession = "agent:main:degraded"
+
+func newDegradedTestLoop(t *testing.T, enabled bool) (*AgentLoop, *recordingFailFirstProvider) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+				DegradedFallback:  enabled,
+			},
+		},
+	}
+	provider := &recordingFailFirstProvider{}
+	return NewAgentLoop(cfg, bus.NewMessageBus(), provider), provider
+}
+
+func TestRunAgentLoop_DegradedRetryAfterFailure(t *testing.T) {
+	al, provider := newDegradedTestLoop(t, true)
+	agent := al.registry.GetDefaultAgent()
+	agent.Sessions.SetHistory(degradedSession, []providers.Message{
+		{Role: "user", Content: "earlier question"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "c1", Name: "exec"}}},
+		{Role: "tool", Content: "tool output", ToolCallID: "c1"},
+		{Role: "assistant", Content: "earlier answer"},
+	})
+	if err := os.WriteFile(filepath.Join(agent.Workspace, "SOUL.md"), []byte("Speak like a pirate."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := al.ProcessDirectWithChannel(context.Background(), "do the thing", degradedSession, "telegram", "1")
+	if err != nil {
+		t.Fatalf("ProcessDirectWithChannel() error = %v", err)
+	}
+
+	wantPrefix := i18n.T("", i18n.AgentDegradedNote)
+	if !strings.HasPrefix(response, wantPrefix) || !strings.HasSuffix(response, "Best guess answer") {
+		t.Errorf("response = %q, want degraded note then the answer", response)
+	}
+	if provider.calls != 2 {
+		t.Fatalf("provider calls = %d, want 2", provider.calls)
+	}
+	if len(provider.tools[1]) != 0 {
+		t.Errorf("degraded retry sent %d tools, want none", len(provider.tools[1]))
+	}
+	retry := provider.messages[1]
+	for _, m := range retry {
+		if m.Role == "tool" || len(m.ToolCalls) > 0 {
+			t.Errorf("degraded retry kept tool message %+v", m)
+		}
+	}
+	if !strings.Contains(retry[0].Content, "Speak like a pirate.") {
+		t.Errorf("degraded system prompt lost the persona:\n%s", retry[0].Content)
+	}
+	if last := retry[len(retry)-1]; last.Role != "user" || last.Content != "do the thing" {
+		t.Errorf("last retry message = %+v, want the user message", last)
+	}
+
+	history := agent.Sessions.GetHistory(degradedSession)
+	if got := history[len(history)-1]; got.Role != "assistant" || got.Content != response {
+		t.Errorf("last history message = %+v, want the degraded reply", got)
+	}
+}
+
+func TestRunAgentLoop_DegradedRetryDisabledFailsLoudly(t *testing.T) {
+	al, provider := newDegradedTestLoop(t, false)
+
+	_, err := al.ProcessDirectWithChannel(context.Background(), "do the thing", degradedSession, "telegram", "1")
+	if err == nil || !strings.Contains(err.Error(), "upstream exploded") {
+		t.Fatalf("error = %v, want the original failure", err)
+	}
+	if provider.calls != 1 {
+		t.Errorf("provider calls = %d, want 1", provider.calls)
+	}
+}
+
+func TestNewAgentInstance_DegradedFallbackOverride(t *testing.T) {
+	off := false
+	defaults := &config.AgentDefaults{Workspace: t.TempDir(), Model: "m", DegradedFallback: true}
+	cfg := &config.Config{}
+
+	if !NewAgentInstance(nil, defaults, cfg, &mockProvider{}).DegradedFallback {
+		t.Error("default agent should inherit degraded_fallback from defaults")
+	}
+	strict := &config.AgentConfig{ID: "strict", DegradedFallback: &off}
+	if NewAgentInstance(strict, defaults, cfg, &mockProvider{}).DegradedFallback {
+		t.Error("agent override degraded_fallback=false should win")
+	}
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// maxTrackedEdits bounds how many recent messages can still be edited.
+const maxTrackedEdits = 256
+
+// editedPrefix introduces an edited message to the model when its original
+// is no longer in the history.
+const editedPrefix = "[The user edited their previous message. The edited message follows.]\n"
+
+// editedNote is the turn that answers an edit whose original was replaced
+// in the history, so the edited text is not there twice.
+const editedNote = "[The user edited one of their earlier messages; the history above shows the edited text. " +
+	"Answer the edited version.]"
+
+// editTracker remembers the content of recent user messages by platform
+// message ID, so an edit can find the original in the session history.
+type editTracker struct {
+	mu      sync.Mutex
+	content map[string]string
+	order   []string
+}
+
+func editKey(sessionKey, messageID string) string {
+	return sessionKey + "\x00" + messageID
+}
+
+// record remembers content as the current text of a message, forgetting
+// the oldest message once maxTrackedEdits are kept.
+func (e *editTracker) record(sessionKey, messageID, content string) {
+	if messageID == "" {
+		return
+	}
+	key := editKey(sessionKey, messageID)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.content == nil {
+		e.content = make(map[string]string)
+	}
+	if _, ok := e.content[key]; !ok {
+		e.order = append(e.order, key)
+		if len(e.order) > maxTrackedEdits {
+			delete(e.content, e.order[0])
+			e.order = e.order[1:]
+		}
+	}
+	e.content[key] = content
+}
+
+func (e *editTracker) lookup(sessionKey, messageID string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	content, ok := e.content[editKey(sessionKey, messageID)]
+	return content, ok
+}
+
+// applyEdit replaces the original of an edited message in the session
+// history with content and returns the user message for the turn that
+// answers the edit: a note pointing at the replaced entry, or the edited
+// text itself when the original is not found, as for messages from before a
+// restart or from too long ago.
+func (al *AgentLoop) applyEdit(agent *AgentInstance, sessionKey, messageID, content string) string {
+	replaced := false
+	if original, ok := al.edits.lookup(sessionKey, messageID); ok {
+		history := agent.Sessions.GetHistory(sessionKey)
+		for i := len(history) - 1; i >= 0; i-- {
+			if history[i].Role == "user" && history[i].Content == original {
+				history[i].Content = content
+				agent.Sessions.SetHistory(sessionKey, history)
+				replaced = true
+				break
+			}
+		}
+	}
+	logger.InfoCF("agent", "User edited a message",
+		map[string]any{
+			"session_key": sessionKey,
+			"message_id":  messageID,
+			"replaced":    replaced,
+		})
+	al.edits.record(sessionKey, messageID, content)
+	if replaced {
+		return editedNote
+	}
+	return editedPrefix + content
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/channels"
+)
+
+func TestEditTracker_ForgetsOldest(t *testing.T) {
+	var e editTracker
+	for i := 0; i <= maxTrackedEdits; i++ {
+		e.record("s", fmt.Sprint(i), fmt.Sprint("msg ", i))
+	}
+	if _, ok := e.lookup("s", "0"); ok {
+		t.Error("expected the oldest message to be forgotten")
+	}
+	if got, ok := e.lookup("s", "1"); !ok || got != "msg 1" {
+		t.Errorf("lookup(1) = %q, %v; want msg 1", got, ok)
+	}
+	e.record("s", "", "no id")
+	if len(e.order) != maxTrackedEdits {
+		t.Errorf("tracked %d messages, want %d", len(e.order), maxTrackedEdits)
+	}
+}
+
+func TestProcessMessage_EditReplacesOriginal(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	ctx := context.Background()
+	sessionKey := "agent:main:edit-test"
+
+	msg := commandMessage("what is the capital of Austria")
+	msg.MessageID = "101"
+	msg.SessionKey = sessionKey
+	if _, err := al.processMessage(ctx, msg); err != nil {
+		t.Fatalf("processMessage failed: %v", err)
+	}
+
+	edit := commandMessage("what is the capital of Australia")
+	edit.MessageID = "101"
+	edit.SessionKey = sessionKey
+	edit.Metadata = map[string]string{channels.EditedMessageIDKey: "101"}
+	if _, err := al.processMessage(ctx, edit); err != nil {
+		t.Fatalf("processMessage failed: %v", err)
+	}
+
+	history := al.registry.GetDefaultAgent().Sessions.GetHistory(sessionKey)
+	var users []string
+	for _, m := range history {
+		if m.Role == "user" {
+			users = append(users, m.Content)
+		}
+	}
+	if len(users) != 2 {
+		t.Fatalf("user messages = %q, want the original and the edit", users)
+	}
+	if users[0] != "what is the capital of Australia" {
+		t.Errorf("original = %q, want it replaced by the edit", users[0])
+	}
+	if users[1] != editedNote {
+		t.Errorf("edit turn = %q, want a note instead of the edited text again", users[1])
+	}
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// errorReply returns what the user on channel is told when a turn fails.
+// Provider failures become a short explanation in the bot language; the
+// full error only goes to the log.
+func (al *AgentLoop) errorReply(channel string, err error) string {
+	lang := al.language(channel)
+	logger.ErrorCF("agent", "Turn failed",
+		map[string]any{
+			"channel": channel,
+			"error":   err.Error(),
+		})
+
+	switch {
+	case errors.Is(err, providers.ErrRateLimited):
+		var pe *providers.ProviderError
+		if errors.As(err, &pe) && pe.RetryAfter > 0 {
+			return i18n.T(lang, i18n.ProviderRateLimitedRetry, formatWait(pe.RetryAfter))
+		}
+		return i18n.T(lang, i18n.ProviderRateLimited)
+	case errors.Is(err, providers.ErrAuth):
+		return i18n.T(lang, i18n.ProviderAuth)
+	case errors.Is(err, providers.ErrContextTooLong):
+		return i18n.T(lang, i18n.ProviderContextTooLong)
+	case errors.Is(err, providers.ErrContentFiltered):
+		return i18n.T(lang, i18n.ProviderContentFiltered)
+	case errors.Is(err, providers.ErrUnavailable):
+		return i18n.T(lang, i18n.ProviderUnavailable)
+	case errors.Is(err, providers.ErrBadRequest):
+		return i18n.T(lang, i18n.ProviderBadRequest)
+	}
+	return i18n.T(lang, i18n.AgentError, err)
+}
+
+// formatWait rounds a wait for a chat message: "45s", "3m", "1h30m".
+func formatWait(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return d.String()
+	}
+	d = d.Round(time.Minute)
+	h, m := int(d/time.Hour), int(d%time.Hour/time.Minute)
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh%dm", h, m)
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestErrorReply(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	rateLimited := &providers.ProviderError{
+		Kind:       providers.ErrRateLimited,
+		RetryAfter: 90 * time.Second,
+		Err:        errors.New("API request failed: Status: 429"),
+	}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"rate limited with wait", fmt.Errorf("LLM call failed after retries: %w", rateLimited),
+			i18n.T("", i18n.ProviderRateLimitedRetry, "2m")},
+		{"rate limited", &providers.ProviderError{Kind: providers.ErrRateLimited, Err: errors.New("429")},
+			i18n.T("", i18n.ProviderRateLimited)},
+		{"auth", &providers.ProviderError{Kind: providers.ErrAuth, Err: errors.New("codex cli error: 401")},
+			i18n.T("", i18n.ProviderAuth)},
+		{"context", &providers.ProviderError{Kind: providers.ErrContextTooLong, Err: errors.New("too long")},
+			i18n.T("", i18n.ProviderContextTooLong)},
+		{"filtered", &providers.ProviderError{Kind: providers.ErrContentFiltered, Err: errors.New("content_filter")},
+			i18n.T("", i18n.ProviderContentFiltered)},
+		{"unavailable", &providers.FallbackExhaustedError{Attempts: []providers.FallbackAttempt{
+			{Error: &providers.ProviderError{Kind: providers.ErrUnavailable, Err: errors.New("503")}},
+		}}, i18n.T("", i18n.ProviderUnavailable)},
+		{"bad request", &providers.ProviderError{Kind: providers.ErrBadRequest, Err: errors.New("400")},
+			i18n.T("", i18n.ProviderBadRequest)},
+		{"other", errors.New("disk full"), i18n.T("", i18n.AgentError, errors.New("disk full"))},
+	}
+	for _, tt := range tests {
+		if got := al.errorReply("telegram", tt.err); got != tt.want {
+			t.Errorf("%s: errorReply() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWait(t *testing.T) {
+	tests := map[time.Duration]string{
+		45 * time.Second:                      "45s",
+		1500 * time.Millisecond:               "2s",
+		3*time.Minute + 10*time.Second:        "3m",
+		time.Hour + 30*time.Minute:            "1h30m",
+		2 * time.Hour:                         "2h",
+		2*time.Hour + 20*time.Second:          "2h",
+		time.Hour + 5*time.Minute + 31e9:      "1h6m",
+		59*time.Second + 600*time.Millisecond: "1m",
+	}
+	for d, want := range tests {
+		if got := formatWait(d); got != want {
+			t.Errorf("formatWait(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
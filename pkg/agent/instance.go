@@ -8,7 +8,10 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/sipeed/picoclaw/pkg/audit"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/history"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/routing"
 	"github.com/sipeed/picoclaw/pkg/session"
@@ -34,6 +37,17 @@ type AgentInstance struct {
 	Subagents      *config.SubagentsConfig
 	SkillsFilter   []string
 	Candidates     []providers.FallbackCandidate
+	// ToolPolicy is the agent's own tool policy; see toolPolicyFor.
+	ToolPolicy ToolPolicy
+	// DegradedFallback retries a failed turn without tools; see
+	// config.AgentDefaults.DegradedFallback.
+	DegradedFallback bool
+	// Limits bounds each model call; see config.AgentLimits.
+	Limits config.AgentLimits
+	// Audit records every tool call; nil when tools.audit is disabled.
+	Audit *audit.Log
+	// History keeps the transcript of every chat for export.
+	History *history.Store
 }
 
 // NewAgentInstance creates an agent instance from config.
@@ -72,18 +86,37 @@ func NewAgentInstance(
 	sessionsDir := filepath.Join(workspace, "sessions")
 	sessionsManager := session.NewSessionManager(sessionsDir)
 
-	contextBuilder := NewContextBuilder(workspace)
+	contextBuilder := NewAgentContextBuilder(agentCfg, cfg)
 
 	agentID := routing.DefaultAgentID
 	agentName := ""
 	var subagents *config.SubagentsConfig
 	var skillsFilter []string
 
+	degradedFallback := defaults.DegradedFallback
+	limits := defaults.Limits
+	var toolPolicy ToolPolicy
+
 	if agentCfg != nil {
 		agentID = routing.NormalizeAgentID(agentCfg.ID)
 		agentName = agentCfg.Name
 		subagents = agentCfg.Subagents
 		skillsFilter = agentCfg.Skills
+		if agentCfg.DegradedFallback != nil {
+			degradedFallback = *agentCfg.DegradedFallback
+		}
+		limits = limits.Merge(agentCfg.Limits)
+		if p, err := ParseToolPolicy(agentCfg.Tools); err != nil {
+			logger.WarnCF("agent", "Ignoring invalid agent tool policy",
+				map[string]any{"agent_id": agentID, "error": err.Error()})
+		} else {
+			toolPolicy = p
+		}
+	}
+
+	// Commands declared by skills run as tools of their own
+	for _, command := range contextBuilder.ListSkillCommands() {
+		toolsRegistry.Register(tools.NewSkillCommandTool(command, execTool, cfg.Tools.Skills.Env))
 	}
 
 	maxIter := defaults.MaxToolIterations
@@ -95,6 +128,12 @@ func NewAgentInstance(
 	if maxTokens == 0 {
 		maxTokens = 8192
 	}
+	// The context window follows max_tokens but never exceeds what the
+	// model accepts.
+	contextWindow := maxTokens
+	if info := lookupModelInfo(cfg, model); info.ContextWindow > 0 && info.ContextWindow < contextWindow {
+		contextWindow = info.ContextWindow
+	}
 
 	temperature := 0.7
 	if defaults.Temperature != nil {
@@ -148,6 +187,11 @@ func NewAgentInstance(
 
 	candidates := providers.ResolveCandidatesWithLookup(modelCfg, defaults.Provider, resolveFromModelList)
 
+	var auditLog *audit.Log
+	if cfg.Tools.Audit.Enabled {
+		auditLog = audit.NewLog(cfg.Tools.Audit.LogPath(), cfg.Tools.Audit.RedactKeys)
+	}
+
 	return &AgentInstance{
 		ID:             agentID,
 		Name:           agentName,
@@ -157,7 +201,7 @@ func NewAgentInstance(
 		MaxIterations:  maxIter,
 		MaxTokens:      maxTokens,
 		Temperature:    temperature,
-		ContextWindow:  maxTokens,
+		ContextWindow:  contextWindow,
 		Provider:       provider,
 		Sessions:       sessionsManager,
 		ContextBuilder: contextBuilder,
@@ -165,6 +209,12 @@ func NewAgentInstance(
 		Subagents:      subagents,
 		SkillsFilter:   skillsFilter,
 		Candidates:     candidates,
+		ToolPolicy:     toolPolicy,
+
+		DegradedFallback: degradedFallback,
+		Limits:           limits,
+		Audit:            auditLog,
+		History:          history.NewStore(workspace),
 	}
 }
 
@@ -181,6 +231,27 @@ func resolveAgentWorkspace(agentCfg *config.AgentConfig, defaults *config.AgentD
 	return filepath.Join(home, ".picoclaw", "workspace-"+id)
 }
 
+// AgentWorkspace returns the workspace of the agent agentCfg, or of the
+// default agent when it is nil.
+func AgentWorkspace(agentCfg *config.AgentConfig, cfg *config.Config) string {
+	return resolveAgentWorkspace(agentCfg, &cfg.Agents.Defaults)
+}
+
+// NewAgentContextBuilder returns the context builder of the agent agentCfg,
+// or of the default agent when it is nil: the agent's workspace, system
+// prompt file and skills, and the configured response language.
+func NewAgentContextBuilder(agentCfg *config.AgentConfig, cfg *config.Config) *ContextBuilder {
+	cb := NewContextBuilder(resolveAgentWorkspace(agentCfg, &cfg.Agents.Defaults))
+	cb.SetResponseLanguage(func(channel string) string {
+		return cfg.Gateway.ResponseLanguageFor(channel)
+	})
+	if agentCfg != nil {
+		cb.SetAgentPrompt(expandHome(strings.TrimSpace(agentCfg.SystemPromptFile)))
+		cb.SetSkills(agentCfg.Skills)
+	}
+	return cb
+}
+
 // resolveAgentModel resolves the primary model for an agent.
 func resolveAgentModel(agentCfg *config.AgentConfig, defaults *config.AgentDefaults) string {
 	if agentCfg != nil && agentCfg.Model != nil && strings.TrimSpace(agentCfg.Model.Primary) != "" {
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// interruptedProvider answers the first call with a tool step, then cancels
+// the turn during the second call after streaming part of an answer.
+type interruptedProvider struct {
+	cancel  context.CancelFunc
+	partial string
+	calls   int
+}
+
+func (p *interruptedProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls++
+	if p.calls == 1 {
+		return &providers.LLMResponse{
+			Content:   "Looked at the logs.",
+			ToolCalls: []providers.ToolCall{{ID: "c1", Name: "no_such_tool", Arguments: map[string]any{}}},
+		}, nil
+	}
+	p.cancel()
+	if p.partial == "" {
+		return nil, ctx.Err()
+	}
+	return nil, &providers.PartialResponseError{Content: p.partial, Err: ctx.Err()}
+}
+
+func (p *interruptedProvider) GetDefaultModel() string { return "test-model" }
+
+const interruptSession = "agent:main:interrupt"
+
+func newInterruptedTestLoop(t *testing.T, partial string) (*AgentLoop, context.Context) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	provider := &interruptedProvider{cancel: cancel, partial: partial}
+	return NewAgentLoop(cfg, bus.NewMessageBus(), provider), ctx
+}
+
+func TestRunAgentLoop_InterruptedKeepsPartialResponse(t *testing.T) {
+	al, ctx := newInterruptedTestLoop(t, "The cause is a full disk")
+
+	response, err := al.ProcessDirect(ctx, "why is the build failing?", interruptSession)
+	if err != nil {
+		t.Fatalf("ProcessDirect() error = %v", err)
+	}
+	want := "Looked at the logs.\n\nThe cause is a full disk\n\n" + InterruptedMarker
+	if response != want {
+		t.Errorf("response = %q, want %q", response, want)
+	}
+
+	history := al.registry.GetDefaultAgent().Sessions.GetHistory(interruptSession)
+	if got := history[len(history)-1]; got.Role != "assistant" || got.Content != want {
+		t.Errorf("last history message = %+v, want the interrupted response", got)
+	}
+	if got := history[len(history)-2]; got.Role != "tool" || got.ToolCallID != "c1" {
+		t.Errorf("tool call c1 has no result before the interrupted response: %+v", got)
+	}
+}
+
+func TestRunAgentLoop_InterruptedWithoutPartialText(t *testing.T) {
+	al, ctx := newInterruptedTestLoop(t, "")
+
+	// The tool step's text alone is still worth keeping.
+	response, err := al.ProcessDirect(ctx, "why?", interruptSession)
+	if err != nil || response != "Looked at the logs.\n\n"+InterruptedMarker {
+		t.Errorf("ProcessDirect() = %q, %v", response, err)
+	}
+
+	// Cancelled before anything was produced, the turn fails as before.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := al.ProcessDirect(ctx, "why?", interruptSession+"2"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ProcessDirect() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// responseLanguageAuto makes the bot answer in the language of the message
+// it is replying to.
+const responseLanguageAuto = "auto"
+
+// scriptLanguages maps scripts that identify a language well enough to name
+// it. Latin and other shared scripts are left to the model.
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "Japanese"},
+	{unicode.Katakana, "Japanese"},
+	{unicode.Hangul, "Korean"},
+	{unicode.Han, "Chinese"},
+	{unicode.Thai, "Thai"},
+	{unicode.Hebrew, "Hebrew"},
+	{unicode.Greek, "Greek"},
+	{unicode.Devanagari, "Hindi"},
+	{unicode.Arabic, "Arabic"},
+	{unicode.Cyrillic, "Russian"},
+}
+
+// minKanaPercent is the share of kana among the CJK letters of a text from
+// which it is Japanese rather than Chinese. Japanese prose is mostly kana,
+// while Chinese text may quote a katakana brand or name.
+const minKanaPercent = 20
+
+// detectLanguage guesses the language of text from its script. Han letters
+// count towards Japanese when enough of the CJK text is kana, and kana
+// towards Chinese otherwise. It returns "" when the script does not decide
+// the language, e.g. for Latin text.
+func detectLanguage(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				counts[s.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	if kana, cjk := counts["Japanese"], counts["Japanese"]+counts["Chinese"]; kana*100 >= cjk*minKanaPercent {
+		counts["Japanese"], counts["Chinese"] = cjk, 0
+	} else {
+		counts["Japanese"], counts["Chinese"] = 0, cjk
+	}
+	best, bestCount := "", 0
+	for _, s := range scriptLanguages {
+		if n := counts[s.language]; n > bestCount {
+			best, bestCount = s.language, n
+		}
+	}
+	// A few foreign words in a Latin sentence do not make it foreign.
+	if bestCount*2 < letters {
+		return ""
+	}
+	return best
+}
+
+// responseLanguageInstruction returns the system prompt section asking the
+// model to reply in setting, or in the language of message when setting is
+// "auto". It returns "" when no response language is configured.
+func responseLanguageInstruction(setting, message string) string {
+	setting = strings.TrimSpace(setting)
+	if setting == "" {
+		return ""
+	}
+	if !strings.EqualFold(setting, responseLanguageAuto) {
+		return fmt.Sprintf("## Response Language\nAlways reply in %s, whatever language the user writes in.", setting)
+	}
+	if lang := detectLanguage(message); lang != "" {
+		return fmt.Sprintf("## Response Language\nThe user wrote in %s. Reply in %s.", lang, lang)
+	}
+	return "## Response Language\nReply in the same language as the user's latest message."
+}
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"今日はいい天気ですね": "Japanese",
+		"東京に行きたいです":  "Japanese",
+		"今天天气很好":     "Chinese",
+		"我昨天买了一台新的索尼相机，叫做アルファ，很好用": "Chinese",
+		"안녕하세요":                         "Korean",
+		"Привет, как дела?":             "Russian",
+		"How are you?":                  "",
+		"Is 東京 nice this time of year?": "",
+		"12345 !!":                      "",
+	}
+	for text, want := range tests {
+		if got := detectLanguage(text); got != want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestResponseLanguageInstruction(t *testing.T) {
+	if got := responseLanguageInstruction("", "bonjour"); got != "" {
+		t.Errorf("no setting should add no instruction, got %q", got)
+	}
+	if got := responseLanguageInstruction("French", "hello"); !strings.Contains(got, "Always reply in French") {
+		t.Errorf("fixed language instruction = %q", got)
+	}
+	if got := responseLanguageInstruction("auto", "今天天气很好"); !strings.Contains(got, "Reply in Chinese.") {
+		t.Errorf("auto instruction for Chinese = %q", got)
+	}
+	if got := responseLanguageInstruction("auto", "hola"); !strings.Contains(got, "same language as the user's latest message") {
+		t.Errorf("auto instruction for Latin text = %q", got)
+	}
+}
+
+func TestBuildMessages_AddsResponseLanguage(t *testing.T) {
+	cb := NewContextBuilder(t.TempDir())
+	cb.SetResponseLanguage(func(channel string) string {
+		if channel == "telegram" {
+			return "auto"
+		}
+		return ""
+	})
+
+	system := cb.BuildMessages(nil, "", "Привет", nil, "telegram", "1")[0].Content
+	if !strings.Contains(system, "Reply in Russian.") {
+		t.Errorf("telegram system prompt lacks the response language:\n%s", system)
+	}
+	system = cb.BuildMessages(nil, "", "Привет", nil, "discord", "1")[0].Content
+	if strings.Contains(system, "Response Language") {
+		t.Error("discord has no response language but got an instruction")
+	}
+}
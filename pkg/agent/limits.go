@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// outputTokens returns the max_tokens to request: maxTokens, capped by
+// limits.max_output_tokens when that is set.
+func (a *AgentInstance) outputTokens(maxTokens int) int {
+	if limit := a.Limits.MaxOutputTokens; limit > 0 && (maxTokens <= 0 || limit < maxTokens) {
+		return limit
+	}
+	return maxTokens
+}
+
+// requestContext returns the context for one model call, bounded by
+// limits.request_timeout_seconds when that is set.
+func (a *AgentInstance) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.Limits.RequestTimeoutSeconds <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(a.Limits.RequestTimeoutSeconds)*time.Second)
+}
+
+// fitInputLimit leaves the oldest history out of messages until the prompt
+// fits limits.max_input_tokens. The system prompt and the current turn, from
+// the last user message on, are always kept, so a turn that is too large by
+// itself is sent as is.
+func (al *AgentLoop) fitInputLimit(agent *AgentInstance, messages []providers.Message) []providers.Message {
+	limit := agent.Limits.MaxInputTokens
+	if limit <= 0 || al.estimateTokens(messages) <= limit {
+		return messages
+	}
+
+	start := 0
+	if len(messages) > 0 && messages[0].Role == "system" {
+		start = 1
+	}
+	turn := len(messages)
+	for i := len(messages) - 1; i >= start; i-- {
+		if messages[i].Role == "user" {
+			turn = i
+			break
+		}
+	}
+
+	fixed := al.estimateTokens(messages[:start]) + al.estimateTokens(messages[turn:])
+	history := messages[start:turn]
+	for len(history) > 0 && fixed+al.estimateTokens(history) > limit {
+		history = history[1:]
+	}
+	// Tool results whose call was dropped would be rejected by the provider.
+	history = sanitizeHistoryForProvider(history)
+
+	trimmed := make([]providers.Message, 0, start+len(history)+len(messages)-turn)
+	trimmed = append(trimmed, messages[:start]...)
+	trimmed = append(trimmed, history...)
+	trimmed = append(trimmed, messages[turn:]...)
+
+	logger.WarnCF("agent", "Prompt exceeds max_input_tokens, leaving out older history",
+		map[string]any{
+			"agent_id":         agent.ID,
+			"max_input_tokens": limit,
+			"dropped_msgs":     len(messages) - len(trimmed),
+			"estimated_tokens": al.estimateTokens(trimmed),
+		})
+	return trimmed
+}
@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestOutputTokens(t *testing.T) {
+	agent := &AgentInstance{}
+	if got := agent.outputTokens(8192); got != 8192 {
+		t.Errorf("outputTokens() without limit = %d, want 8192", got)
+	}
+
+	agent.Limits.MaxOutputTokens = 1000
+	if got := agent.outputTokens(8192); got != 1000 {
+		t.Errorf("outputTokens() = %d, want the 1000 limit", got)
+	}
+	if got := agent.outputTokens(500); got != 500 {
+		t.Errorf("outputTokens() below the limit = %d, want 500", got)
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	agent := &AgentInstance{}
+	ctx, cancel := agent.requestContext(context.Background())
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("requestContext() without limit has a deadline")
+	}
+	cancel()
+
+	agent.Limits.RequestTimeoutSeconds = 30
+	ctx, cancel = agent.requestContext(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > 30*time.Second || time.Until(deadline) < 29*time.Second {
+		t.Errorf("requestContext() deadline = %v, want in 30s", deadline)
+	}
+}
+
+func TestFitInputLimit(t *testing.T) {
+	al := &AgentLoop{}
+	long := strings.Repeat("x", 100) // 40 tokens
+	messages := []providers.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: long},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{{ID: "1", Name: "read_file"}}},
+		{Role: "tool", ToolCallID: "1", Content: long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "now"},
+	}
+
+	agent := &AgentInstance{}
+	if got := al.fitInputLimit(agent, messages); len(got) != len(messages) {
+		t.Errorf("fitInputLimit() without limit dropped %d messages", len(messages)-len(got))
+	}
+
+	// Room for one old message: the tool result that loses its call goes too.
+	agent.Limits.MaxInputTokens = 50
+	got := al.fitInputLimit(agent, messages)
+	var roles []string
+	for _, m := range got {
+		roles = append(roles, m.Role)
+	}
+	if strings.Join(roles, ",") != "system,assistant,user" || got[1].Content != long || got[2].Content != "now" {
+		t.Errorf("fitInputLimit() roles = %v, want system, the last reply and the current turn", roles)
+	}
+
+	// The current turn is kept even when it alone is too large.
+	agent.Limits.MaxInputTokens = 1
+	got = al.fitInputLimit(agent, messages)
+	if len(got) != 2 || got[1].Content != "now" {
+		t.Errorf("fitInputLimit() = %+v, want the system prompt and current turn", got)
+	}
+}
+
+func TestNewAgentInstance_LimitsOverride(t *testing.T) {
+	defaults := &config.AgentDefaults{
+		Workspace: t.TempDir(),
+		Limits:    config.AgentLimits{RequestTimeoutSeconds: 60, MaxOutputTokens: 2000},
+	}
+	cfg := &config.Config{}
+	maxOutput, noTimeout := 4000, 0
+	agentCfg := &config.AgentConfig{ID: "coder", Limits: &config.AgentLimitsOverride{
+		RequestTimeoutSeconds: &noTimeout,
+		MaxOutputTokens:       &maxOutput,
+	}}
+
+	agent := NewAgentInstance(agentCfg, defaults, cfg, &mockProvider{})
+	want := config.AgentLimits{MaxOutputTokens: 4000}
+	if agent.Limits != want {
+		t.Errorf("Limits = %+v, want %+v", agent.Limits, want)
+	}
+}
@@ -18,11 +18,14 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/sipeed/picoclaw/pkg/audit"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/maintenance"
 	"github.com/sipeed/picoclaw/pkg/mcp"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/providers"
@@ -43,22 +46,58 @@ type AgentLoop struct {
 	fallback       *providers.FallbackChain
 	channelManager *channels.Manager
 	mediaStore     media.MediaStore
+
+	capabilityProviders sync.Map // model_name -> capabilityProviderEntry
+	toolPolicy          ToolPolicy
+
+	turns *turnLimiter
+	chats chatQueues
+	usage *usageTracker
+	// limiter throttles non-owner senders; nil when rate limiting is off.
+	limiter *senderLimiter
+	// budget caps what channels and chats spend; nil when budgets are off.
+	budget *budgetGuard
+	// extraCommands are added with RegisterCommand.
+	extraCommands []Command
+	recentContext recentContextCache
+	edits         editTracker
+	// maintenance answers messages with a notice instead of calling the
+	// model while it is on; nil outside the gateway.
+	maintenance *maintenance.Mode
+	// notConfigured is set when the gateway runs without a usable provider;
+	// messages are then answered with notConfiguredReply.
+	notConfigured      bool
+	notConfiguredReply string
+}
+
+// emptyResponse returns the reply sent when the model keeps answering with
+// nothing: agents.defaults.empty_response, or a notice in the bot language.
+func (al *AgentLoop) emptyResponse(channel string) string {
+	if al.cfg != nil && al.cfg.Agents.Defaults.EmptyResponse != "" {
+		return al.cfg.Agents.Defaults.EmptyResponse
+	}
+	return i18n.T(al.language(channel), i18n.AgentEmptyResponse)
 }
 
+// InterruptedMarker ends a response whose turn was cancelled before it
+// finished; the text before it is what the agent had produced so far.
+const InterruptedMarker = "[interrupted]"
+
 // processOptions configures how a message is processed
 type processOptions struct {
-	SessionKey      string // Session identifier for history/context
-	Channel         string // Target channel for tool execution
-	ChatID          string // Target chat ID for tool execution
-	UserMessage     string // User message content (may include prefix)
-	DefaultResponse string // Response when LLM returns empty
-	EnableSummary   bool   // Whether to trigger summarization
-	SendResponse    bool   // Whether to send response via bus
-	NoHistory       bool   // If true, don't load session history (for heartbeat)
+	SessionKey      string   // Session identifier for history/context
+	Channel         string   // Target channel for tool execution
+	ChatID          string   // Target chat ID for tool execution
+	UserMessage     string   // User message content (may include prefix)
+	Media           []string // Media refs attached to the user message
+	DefaultResponse string   // Response when LLM returns empty
+	EnableSummary   bool     // Whether to trigger summarization
+	SendResponse    bool     // Whether to send response via bus
+	NoHistory       bool     // If true, don't load session history (for heartbeat)
+	ModelOverride   string   // model_list name chosen for this chat with /model
+	Stream          bool     // Whether the reply may be streamed to the chat as it is written
 }
 
-const defaultResponse = "I've completed processing but have no response to give. Increase `max_tool_iterations` in config.json."
-
 func NewAgentLoop(
 	cfg *config.Config,
 	msgBus *bus.MessageBus,
@@ -80,6 +119,15 @@ func NewAgentLoop(
 		stateManager = state.NewManager(defaultAgent.Workspace)
 	}
 
+	var limiter *senderLimiter
+	if cfg.RateLimit.Enabled {
+		limiter = newSenderLimiter(cfg.RateLimit, stateManager)
+	}
+	var budget *budgetGuard
+	if cfg.Budget.Enabled {
+		budget = newBudgetGuard(cfg.Budget, stateManager)
+	}
+
 	return &AgentLoop{
 		bus:         msgBus,
 		cfg:         cfg,
@@ -87,6 +135,10 @@ func NewAgentLoop(
 		state:       stateManager,
 		summarizing: sync.Map{},
 		fallback:    fallbackChain,
+		turns:       newTurnLimiter(cfg.Gateway.MaxConcurrentTurns, cfg.Gateway.MaxQueuedTurns),
+		usage:       newUsageTracker(),
+		limiter:     limiter,
+		budget:      budget,
 	}
 }
 
@@ -148,11 +200,19 @@ func registerSharedTools(
 		})
 		agent.Tools.Register(messageTool)
 
+		// Proactive messages to configured contacts, with confirmation and audit
+		if cfg.Tools.SendMessage.Enabled && len(cfg.Tools.SendMessage.Contacts) > 0 {
+			contacts, err := tools.ParseContacts(cfg.Tools.SendMessage.Contacts)
+			if err != nil {
+				logger.ErrorCF("agent", "Invalid send_message contacts", map[string]any{"error": err.Error()})
+			} else {
+				agent.Tools.Register(tools.NewSendMessageTool(contacts, deliverMessage(msgBus),
+					filepath.Join(agent.Workspace, "audit", "send_message.jsonl")))
+			}
+		}
+
 		// Skill discovery and installation tools
-		registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfig{
-			MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
-			ClawHub:               skills.ClawHubConfig(cfg.Tools.Skills.Registries.ClawHub),
-		})
+		registryMgr := skills.NewRegistryManagerFromConfig(skills.RegistryConfigFrom(cfg))
 		searchCache := skills.NewSearchCache(
 			cfg.Tools.Skills.SearchCache.MaxSize,
 			time.Duration(cfg.Tools.Skills.SearchCache.TTLSeconds)*time.Second,
@@ -172,6 +232,28 @@ func registerSharedTools(
 	}
 }
 
+// deliverMessage returns a send_message callback that publishes through the
+// bus and waits for the channel to report delivery.
+func deliverMessage(msgBus *bus.MessageBus) tools.DeliverCallback {
+	return func(ctx context.Context, channel, chatID, content string) error {
+		status, err := bus.AwaitDelivery(ctx, 30*time.Second, func(report func(bus.DeliveryStatus)) error {
+			return msgBus.PublishOutbound(ctx, bus.OutboundMessage{
+				Channel:    channel,
+				ChatID:     chatID,
+				Content:    content,
+				OnDelivery: report,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if status.State == bus.DeliveryFailed {
+			return errors.New(status.Error)
+		}
+		return nil
+	}
+}
+
 func (al *AgentLoop) Run(ctx context.Context) error {
 	al.running.Store(true)
 
@@ -250,67 +332,59 @@ func (al *AgentLoop) Run(ctx context.Context) error {
 				continue
 			}
 
-			// Process message
-			func() {
-				// TODO: Re-enable media cleanup after inbound media is properly consumed by the agent.
-				// Currently disabled because files are deleted before the LLM can access their content.
-				// defer func() {
-				// 	if al.mediaStore != nil && msg.MediaScope != "" {
-				// 		if releaseErr := al.mediaStore.ReleaseAll(msg.MediaScope); releaseErr != nil {
-				// 			logger.WarnCF("agent", "Failed to release media", map[string]any{
-				// 				"scope": msg.MediaScope,
-				// 				"error": releaseErr.Error(),
-				// 			})
-				// 		}
-				// 	}
-				// }()
-
-				response, err := al.processMessage(ctx, msg)
-				if err != nil {
-					response = fmt.Sprintf("Error processing message: %v", err)
-				}
-
-				if response != "" {
-					// Check if the message tool already sent a response during this round.
-					// If so, skip publishing to avoid duplicate messages to the user.
-					// Use default agent's tools to check (message tool is shared).
-					alreadySent := false
-					defaultAgent := al.registry.GetDefaultAgent()
-					if defaultAgent != nil {
-						if tool, ok := defaultAgent.Tools.Get("message"); ok {
-							if mt, ok := tool.(*tools.MessageTool); ok {
-								alreadySent = mt.HasSentInRound()
-							}
-						}
-					}
-
-					if !alreadySent {
-						al.bus.PublishOutbound(ctx, bus.OutboundMessage{
-							Channel: msg.Channel,
-							ChatID:  msg.ChatID,
-							Content: response,
-						})
-						logger.InfoCF("agent", "Published outbound response",
-							map[string]any{
-								"channel":     msg.Channel,
-								"chat_id":     msg.ChatID,
-								"content_len": len(response),
-							})
-					} else {
-						logger.DebugCF(
-							"agent",
-							"Skipped outbound (message tool already sent)",
-							map[string]any{"channel": msg.Channel},
-						)
-					}
-				}
-			}()
+			al.dispatchInbound(ctx, msg)
 		}
 	}
 
 	return nil
 }
 
+// handleInbound processes one inbound message and publishes the reply.
+func (al *AgentLoop) handleInbound(ctx context.Context, msg bus.InboundMessage) {
+	// TODO: Re-enable media cleanup after inbound media is properly consumed by the agent.
+	// Currently disabled because files are deleted before the LLM can access their content.
+	// defer func() {
+	// 	if al.mediaStore != nil && msg.MediaScope != "" {
+	// 		if releaseErr := al.mediaStore.ReleaseAll(msg.MediaScope); releaseErr != nil {
+	// 			logger.WarnCF("agent", "Failed to release media", map[string]any{
+	// 				"scope": msg.MediaScope,
+	// 				"error": releaseErr.Error(),
+	// 			})
+	// 		}
+	// 	}
+	// }()
+
+	response, err := al.processMessageWithStream(ctx, msg, true)
+	if err != nil {
+		response = al.errorReply(msg.Channel, err)
+	}
+
+	if response == "" {
+		return
+	}
+	// Check if the message tool already sent a response during this round.
+	// If so, skip publishing to avoid duplicate messages to the user.
+	if al.messageSentInRound(msg.Channel, msg.ChatID) {
+		logger.DebugCF(
+			"agent",
+			"Skipped outbound (message tool already sent)",
+			map[string]any{"channel": msg.Channel},
+		)
+		return
+	}
+	al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel: msg.Channel,
+		ChatID:  msg.ChatID,
+		Content: response,
+	})
+	logger.InfoCF("agent", "Published outbound response",
+		map[string]any{
+			"channel":     msg.Channel,
+			"chat_id":     msg.ChatID,
+			"content_len": len(response),
+		})
+}
+
 func (al *AgentLoop) Stop() {
 	al.running.Store(false)
 }
@@ -323,43 +397,43 @@ func (al *AgentLoop) RegisterTool(tool tools.Tool) {
 	}
 }
 
-func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
-	al.channelManager = cm
+// DisableSkills leaves skills out of every agent's system prompt, for runs
+// that compare the model against a baseline without them.
+func (al *AgentLoop) DisableSkills() {
+	for _, agentID := range al.registry.ListAgentIDs() {
+		if agent, ok := al.registry.GetAgent(agentID); ok {
+			agent.ContextBuilder.OmitSkills()
+		}
+	}
 }
 
-// SetMediaStore injects a MediaStore for media lifecycle management.
-func (al *AgentLoop) SetMediaStore(s media.MediaStore) {
-	al.mediaStore = s
+func (al *AgentLoop) SetChannelManager(cm *channels.Manager) {
+	al.channelManager = cm
 }
 
-// inferMediaType determines the media type ("image", "audio", "video", "file")
-// from a filename and MIME content type.
-func inferMediaType(filename, contentType string) string {
-	ct := strings.ToLower(contentType)
-	fn := strings.ToLower(filename)
-
-	if strings.HasPrefix(ct, "image/") {
-		return "image"
-	}
-	if strings.HasPrefix(ct, "audio/") || ct == "application/ogg" {
-		return "audio"
-	}
-	if strings.HasPrefix(ct, "video/") {
-		return "video"
+// EnableMaintenance makes the loop answer messages from chat channels with
+// the maintenance notice while maintenance is on. The manual switch is kept
+// in the loop's workspace state; the returned Mode flips it.
+func (al *AgentLoop) EnableMaintenance(cfg config.MaintenanceConfig) (*maintenance.Mode, error) {
+	m, err := maintenance.New(cfg, al.state)
+	if err != nil {
+		return nil, err
 	}
+	al.maintenance = m
+	return m, nil
+}
 
-	// Fallback: infer from extension
-	ext := filepath.Ext(fn)
-	switch ext {
-	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".svg":
-		return "image"
-	case ".mp3", ".wav", ".ogg", ".m4a", ".flac", ".aac", ".wma", ".opus":
-		return "audio"
-	case ".mp4", ".avi", ".mov", ".webm", ".mkv":
-		return "video"
-	}
+// SetNotConfigured makes the loop answer every channel message with reply,
+// or a localized "not configured yet" notice when empty, instead of calling
+// the model. The gateway uses it when no provider is usable.
+func (al *AgentLoop) SetNotConfigured(reply string) {
+	al.notConfigured = true
+	al.notConfiguredReply = reply
+}
 
-	return "file"
+// SetMediaStore injects a MediaStore for media lifecycle management.
+func (al *AgentLoop) SetMediaStore(s media.MediaStore) {
+	al.mediaStore = s
 }
 
 // RecordLastChannel records the last active channel for this workspace.
@@ -399,9 +473,59 @@ func (al *AgentLoop) ProcessDirectWithChannel(
 		SessionKey: sessionKey,
 	}
 
+	if !al.turns.wait(ctx) {
+		return "", ctx.Err()
+	}
+	defer al.turns.release()
 	return al.processMessage(ctx, msg)
 }
 
+// ProcessAsContact runs content as a turn from the given chat, using that
+// chat's routed agent and session, and delivers the reply back to it unless
+// the message tool already did. Used by cron jobs with a run-as contact.
+func (al *AgentLoop) ProcessAsContact(ctx context.Context, content, channel, chatID string) (string, error) {
+	msg := bus.InboundMessage{
+		Channel:  channel,
+		SenderID: "cron",
+		ChatID:   chatID,
+		Content:  content,
+		Peer:     bus.Peer{Kind: "direct", ID: chatID},
+	}
+
+	if !al.turns.wait(ctx) {
+		return "", ctx.Err()
+	}
+	defer al.turns.release()
+	response, err := al.processMessageWithStream(ctx, msg, true)
+	if err != nil {
+		return "", err
+	}
+	if response != "" && !al.messageSentInRound(channel, chatID) {
+		al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+			Channel: channel,
+			ChatID:  chatID,
+			Content: response,
+		})
+	}
+	return response, nil
+}
+
+// messageSentInRound reports whether the shared message tool already sent a
+// reply to the chat during its current round. The default agent's instance
+// is checked because the message tool is shared across agents.
+func (al *AgentLoop) messageSentInRound(channel, chatID string) bool {
+	defaultAgent := al.registry.GetDefaultAgent()
+	if defaultAgent == nil {
+		return false
+	}
+	if tool, ok := defaultAgent.Tools.Get("message"); ok {
+		if mt, ok := tool.(*tools.MessageTool); ok {
+			return mt.HasSentTo(channel, chatID)
+		}
+	}
+	return false
+}
+
 // ProcessHeartbeat processes a heartbeat request without session history.
 // Each heartbeat is independent and doesn't accumulate context.
 func (al *AgentLoop) ProcessHeartbeat(
@@ -412,12 +536,16 @@ func (al *AgentLoop) ProcessHeartbeat(
 	if agent == nil {
 		return "", fmt.Errorf("no default agent for heartbeat")
 	}
+	if !al.turns.wait(ctx) {
+		return "", ctx.Err()
+	}
+	defer al.turns.release()
 	return al.runAgentLoop(ctx, agent, processOptions{
 		SessionKey:      "heartbeat",
 		Channel:         channel,
 		ChatID:          chatID,
 		UserMessage:     content,
-		DefaultResponse: defaultResponse,
+		DefaultResponse: i18n.T(al.language(channel), i18n.AgentNoResponse),
 		EnableSummary:   false,
 		SendResponse:    false,
 		NoHistory:       true, // Don't load session history for heartbeat
@@ -425,6 +553,12 @@ func (al *AgentLoop) ProcessHeartbeat(
 }
 
 func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage) (string, error) {
+	return al.processMessageWithStream(ctx, msg, false)
+}
+
+// processMessageWithStream is processMessage for callers that publish the
+// reply to the chat, which may then see it as it is written.
+func (al *AgentLoop) processMessageWithStream(ctx context.Context, msg bus.InboundMessage, stream bool) (string, error) {
 	// Add message preview to log (show full content for error messages)
 	var logContent string
 	if strings.Contains(msg.Content, "Error:") || strings.Contains(msg.Content, "error") {
@@ -448,11 +582,6 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		return al.processSystemMessage(ctx, msg)
 	}
 
-	// Check for commands
-	if response, handled := al.handleCommand(ctx, msg); handled {
-		return response, nil
-	}
-
 	// Route to determine agent and session key
 	route := al.registry.ResolveRoute(routing.RouteInput{
 		Channel:    msg.Channel,
@@ -463,12 +592,19 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		TeamID:     msg.Metadata["team_id"],
 	})
 
-	agent, ok := al.registry.GetAgent(route.AgentID)
+	// A pre-set agent-scoped session key (picoclaw agent --agent) picks its agent
+	agentID := route.AgentID
+	if parsed := routing.ParseAgentSessionKey(msg.SessionKey); parsed != nil {
+		if _, ok := al.registry.GetAgent(parsed.AgentID); ok {
+			agentID = parsed.AgentID
+		}
+	}
+	agent, ok := al.registry.GetAgent(agentID)
 	if !ok {
 		agent = al.registry.GetDefaultAgent()
 	}
 	if agent == nil {
-		return "", fmt.Errorf("no agent available for route (agent_id=%s)", route.AgentID)
+		return "", fmt.Errorf("no agent available for route (agent_id=%s)", agentID)
 	}
 
 	// Reset message-tool state for this round so we don't skip publishing due to a previous round.
@@ -484,6 +620,30 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 		sessionKey = msg.SessionKey
 	}
 
+	// Built-in slash commands are answered without calling the model
+	if response, handled := al.handleCommand(ctx, msg, agent, sessionKey); handled {
+		return response, nil
+	}
+	al.recordOwnerSession(msg, sessionKey)
+
+	// Without a usable provider, tell the sender instead of failing the turn
+	if al.notConfigured && !constants.IsInternalChannel(msg.Channel) {
+		if al.notConfiguredReply != "" {
+			return al.notConfiguredReply, nil
+		}
+		return i18n.T(al.language(msg.Channel), i18n.AgentNotConfigured), nil
+	}
+
+	// In maintenance mode messages are received but the model is not called
+	if al.maintenance.Active() && !constants.IsInternalChannel(msg.Channel) {
+		logger.InfoCF("agent", "Maintenance mode, answering with notice",
+			map[string]any{"channel": msg.Channel, "chat_id": msg.ChatID})
+		if reply := al.maintenance.Message(); reply != "" {
+			return reply, nil
+		}
+		return i18n.T(al.language(msg.Channel), i18n.AgentMaintenance), nil
+	}
+
 	logger.InfoCF("agent", "Routed message",
 		map[string]any{
 			"agent_id":    agent.ID,
@@ -491,15 +651,86 @@ func (al *AgentLoop) processMessage(ctx context.Context, msg bus.InboundMessage)
 			"matched_by":  route.MatchedBy,
 		})
 
-	return al.runAgentLoop(ctx, agent, processOptions{
+	// Throttle chatty senders before any tokens are spent on them
+	var limitKey string
+	if al.limiter != nil && !constants.IsInternalChannel(msg.Channel) && !al.isOwner(msg) {
+		limitKey = senderKey(msg.Channel, msg.SenderID)
+		if ok, notify := al.limiter.allow(msg.Channel, limitKey); !ok {
+			if !notify {
+				return "", nil
+			}
+			if reply := al.cfg.RateLimit.Response; reply != "" {
+				return reply, nil
+			}
+			return i18n.T(al.language(msg.Channel), i18n.RateLimitSlowDown), nil
+		}
+	}
+
+	// Refuse chats that used up their budget, before the provider is called
+	budgeted := al.budget != nil && !constants.IsInternalChannel(msg.Channel)
+	if budgeted && !al.isOwner(msg) {
+		if breach := al.budget.check(msg.Channel, msg.ChatID); breach != nil {
+			if breach.notify {
+				al.notifyBudgetOwner(ctx, breach, msg.Channel, msg.ChatID)
+			}
+			if reply := al.cfg.Budget.Response; reply != "" {
+				return reply, nil
+			}
+			return i18n.T(al.language(msg.Channel), i18n.BudgetExceeded), nil
+		}
+	}
+
+	// Inline workspace files referenced as @path. Only for the terminal: on
+	// chat channels "@john.doe" or "@team/backend" is a handle, not a file.
+	content := msg.Content
+	if msg.Channel == "cli" {
+		var err error
+		content, err = expandFileMentions(agent.Workspace, msg.Content)
+		if err != nil {
+			return "", err
+		}
+	}
+	if editedID := msg.Metadata[channels.EditedMessageIDKey]; editedID != "" {
+		content = al.applyEdit(agent, sessionKey, editedID, content)
+	} else {
+		al.edits.record(sessionKey, msg.MessageID, content)
+	}
+
+	// Only a message from the user can confirm a pending send_message
+	if msg.SenderID != "cron" && !constants.IsInternalChannel(msg.Channel) {
+		ctx = tools.WithUserTurn(ctx)
+		if st, ok := sendMessageTool(agent); ok {
+			st.UserTurn(msg.Channel, msg.ChatID)
+		}
+	}
+
+	before := al.usage.Get(sessionKey)
+	// A model chosen with /model wins over auto-routing
+	modelOverride := al.chatModel(msg.Channel, msg.ChatID)
+	if modelOverride == "" {
+		modelOverride = al.autoRoute(ctx, agent, msg, sessionKey, content)
+	}
+	response, err := al.runAgentLoop(ctx, agent, processOptions{
 		SessionKey:      sessionKey,
 		Channel:         msg.Channel,
 		ChatID:          msg.ChatID,
-		UserMessage:     msg.Content,
-		DefaultResponse: defaultResponse,
+		UserMessage:     content,
+		Media:           msg.Media,
+		DefaultResponse: i18n.T(al.language(msg.Channel), i18n.AgentNoResponse),
 		EnableSummary:   true,
 		SendResponse:    false,
+		ModelOverride:   modelOverride,
+		Stream:          stream,
 	})
+	after := al.usage.Get(sessionKey)
+	tokens := after.PromptTokens + after.CompletionTokens - before.PromptTokens - before.CompletionTokens
+	if limitKey != "" {
+		al.limiter.addTokens(limitKey, tokens)
+	}
+	if budgeted {
+		al.budget.charge(msg.Channel, msg.ChatID, tokens, after.Cost-before.Cost)
+	}
+	return response, err
 }
 
 func (al *AgentLoop) processSystemMessage(
@@ -561,7 +792,7 @@ func (al *AgentLoop) processSystemMessage(
 		Channel:         originChannel,
 		ChatID:          originChatID,
 		UserMessage:     fmt.Sprintf("[System: %s] %s", msg.SenderID, msg.Content),
-		DefaultResponse: "Background task completed.",
+		DefaultResponse: i18n.T(al.language(originChannel), i18n.AgentBackgroundDone),
 		EnableSummary:   false,
 		SendResponse:    true,
 	})
@@ -573,6 +804,8 @@ func (al *AgentLoop) runAgentLoop(
 	agent *AgentInstance,
 	opts processOptions,
 ) (string, error) {
+	received := time.Now()
+
 	// 0. Record last channel for heartbeat notifications (skip internal channels)
 	if opts.Channel != "" && opts.ChatID != "" {
 		// Don't record internal channels (cli, system, subagent)
@@ -612,21 +845,34 @@ func (al *AgentLoop) runAgentLoop(
 
 	// 4. Run LLM iteration loop
 	finalContent, iteration, err := al.runLLMIteration(ctx, agent, messages, opts)
+	if err != nil && ctx.Err() != nil && strings.TrimSpace(finalContent) != "" {
+		return al.flushInterrupted(agent, opts, received, finalContent), nil
+	}
 	if err != nil {
-		return "", err
+		if !agent.DegradedFallback || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			return "", err
+		}
+		degraded, degradedErr := al.degradedReply(ctx, agent, history, summary, opts)
+		if degradedErr != nil {
+			logger.WarnCF("agent", "Degraded retry failed",
+				map[string]any{"agent_id": agent.ID, "error": degradedErr.Error()})
+			return "", err
+		}
+		finalContent = degraded
 	}
 
 	// If last tool had ForUser content and we already sent it, we might not need to send final response
 	// This is controlled by the tool's Silent flag and ForUser content
 
 	// 5. Handle empty response
-	if finalContent == "" {
+	if strings.TrimSpace(finalContent) == "" {
 		finalContent = opts.DefaultResponse
 	}
 
 	// 6. Save final assistant message to session
 	agent.Sessions.AddMessage(opts.SessionKey, "assistant", finalContent)
 	agent.Sessions.Save(opts.SessionKey)
+	recordTranscript(agent, opts, received, finalContent)
 
 	// 7. Optional: summarization
 	if opts.EnableSummary {
@@ -720,6 +966,34 @@ func (al *AgentLoop) runLLMIteration(
 ) (string, int, error) {
 	iteration := 0
 	var finalContent string
+	retriedEmpty := false
+	// Text the model wrote alongside its tool calls, kept in case the turn
+	// is cancelled before it finishes.
+	var steps []string
+
+	toolPolicy := al.toolPolicyFor(agent, opts.Channel)
+
+	// Route to a vision/tool-capable model when the agent's own model lacks
+	// what this turn needs.
+	route := al.resolveCapabilityRoute(agent, turnNeeds{
+		Vision:    al.hasImageMedia(opts.Media),
+		Tools:     isToolHeavy(messages, opts),
+		HaveTools: agent.Tools.Count() > 0 && !toolPolicy.IsNone(),
+	})
+	if opts.ModelOverride != "" && !route.Routed && route.Notice == "" {
+		if r, ok := al.routeToModel(agent, opts.ModelOverride, nil); ok {
+			route = r
+		}
+	}
+	agent.Sessions.SetModel(opts.SessionKey, route.ModelName)
+	if route.Notice != "" && !constants.IsInternalChannel(opts.Channel) {
+		al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+			Channel: opts.Channel,
+			ChatID:  opts.ChatID,
+			Content: route.Notice,
+		})
+	}
+	stream := al.newReplyStream(ctx, opts)
 
 	for iteration < agent.MaxIterations {
 		iteration++
@@ -731,15 +1005,22 @@ func (al *AgentLoop) runLLMIteration(
 				"max":       agent.MaxIterations,
 			})
 
-		// Build tool definitions
-		providerToolDefs := agent.Tools.ToProviderDefs()
+		// Build tool definitions. A model without tool calling gets them
+		// described in the prompt instead.
+		var providerToolDefs, textToolDefs []providers.ToolDefinition
+		switch {
+		case !route.DropTools:
+			providerToolDefs = toolPolicy.Filter(agent.Tools.ToProviderDefs())
+		default:
+			textToolDefs = toolPolicy.Filter(agent.Tools.ToProviderDefs())
+		}
 
 		// Log LLM request details
 		logger.DebugCF("agent", "LLM request",
 			map[string]any{
 				"agent_id":          agent.ID,
 				"iteration":         iteration,
-				"model":             agent.Model,
+				"model":             route.Model,
 				"messages_count":    len(messages),
 				"tools_count":       len(providerToolDefs),
 				"max_tokens":        agent.MaxTokens,
@@ -758,20 +1039,32 @@ func (al *AgentLoop) runLLMIteration(
 		// Call LLM with fallback chain if candidates are configured.
 		var response *providers.LLMResponse
 		var err error
+		usageModel := route.ModelName // the model_list entry that answered
 
 		callLLM := func() (*providers.LLMResponse, error) {
-			if len(agent.Candidates) > 1 && al.fallback != nil {
+			request := al.fitInputLimit(agent, messages)
+			replyStream := stream
+			if len(textToolDefs) > 0 {
+				// Tool calls written as text must not reach the chat.
+				request = textToolMessages(request, textToolDefs)
+				replyStream = nil
+			}
+			if !route.Routed && len(agent.Candidates) > 1 && al.fallback != nil {
 				fbResult, fbErr := al.fallback.Execute(
 					ctx,
 					agent.Candidates,
 					func(ctx context.Context, provider, model string) (*providers.LLMResponse, error) {
-						return agent.Provider.Chat(
+						// Each candidate gets the full request timeout.
+						ctx, cancel := agent.requestContext(ctx)
+						defer cancel()
+						return replyStream.chat(
 							ctx,
-							messages,
+							agent.Provider,
+							request,
 							providerToolDefs,
 							model,
 							map[string]any{
-								"max_tokens":       agent.MaxTokens,
+								"max_tokens":       agent.outputTokens(agent.MaxTokens),
 								"temperature":      agent.Temperature,
 								"prompt_cache_key": agent.ID,
 							},
@@ -781,6 +1074,9 @@ func (al *AgentLoop) runLLMIteration(
 				if fbErr != nil {
 					return nil, fbErr
 				}
+				if fbResult.Name != "" {
+					usageModel = fbResult.Name
+				}
 				if fbResult.Provider != "" && len(fbResult.Attempts) > 0 {
 					logger.InfoCF(
 						"agent",
@@ -791,11 +1087,19 @@ func (al *AgentLoop) runLLMIteration(
 				}
 				return fbResult.Response, nil
 			}
-			return agent.Provider.Chat(ctx, messages, providerToolDefs, agent.Model, map[string]any{
-				"max_tokens":       agent.MaxTokens,
+			callCtx, cancel := agent.requestContext(ctx)
+			defer cancel()
+			resp, err := replyStream.chat(callCtx, route.Provider, request, providerToolDefs, route.Model, map[string]any{
+				"max_tokens":       agent.outputTokens(route.MaxTokens),
 				"temperature":      agent.Temperature,
 				"prompt_cache_key": agent.ID,
 			})
+			if err != nil && ctx.Err() == nil {
+				providers.Health().RecordError(route.ProviderName, err)
+			} else if err == nil {
+				providers.Health().RecordSuccess(route.ProviderName)
+			}
+			return resp, err
 		}
 
 		// Retry loop for context/token errors
@@ -806,25 +1110,15 @@ func (al *AgentLoop) runLLMIteration(
 				break
 			}
 
-			errMsg := strings.ToLower(err.Error())
-
-			// Check if this is a network/HTTP timeout — not a context window error.
-			isTimeoutError := errors.Is(err, context.DeadlineExceeded) ||
-				strings.Contains(errMsg, "deadline exceeded") ||
-				strings.Contains(errMsg, "client.timeout") ||
-				strings.Contains(errMsg, "timed out") ||
-				strings.Contains(errMsg, "timeout exceeded")
-
-			// Detect real context window / token limit errors, excluding network timeouts.
-			isContextError := !isTimeoutError && (strings.Contains(errMsg, "context_length_exceeded") ||
-				strings.Contains(errMsg, "context window") ||
-				strings.Contains(errMsg, "maximum context length") ||
-				strings.Contains(errMsg, "token limit") ||
-				strings.Contains(errMsg, "too many tokens") ||
-				strings.Contains(errMsg, "max_tokens") ||
-				strings.Contains(errMsg, "invalidparameter") ||
-				strings.Contains(errMsg, "prompt is too long") ||
-				strings.Contains(errMsg, "request too large"))
+			err = providers.MapError(route.ProviderName, err)
+			if ctx.Err() != nil {
+				break
+			}
+
+			// A provider that is down or timed out may be back shortly; a
+			// context that is too long needs compressing first.
+			isTimeoutError := errors.Is(err, providers.ErrUnavailable)
+			isContextError := errors.Is(err, providers.ErrContextTooLong)
 
 			if isTimeoutError && retry < maxRetries {
 				backoff := time.Duration(retry+1) * 5 * time.Second
@@ -851,7 +1145,7 @@ func (al *AgentLoop) runLLMIteration(
 					al.bus.PublishOutbound(ctx, bus.OutboundMessage{
 						Channel: opts.Channel,
 						ChatID:  opts.ChatID,
-						Content: "Context window exceeded. Compressing history and retrying...",
+						Content: i18n.T(al.language(opts.Channel), i18n.AgentContextCompressing),
 					})
 				}
 
@@ -867,6 +1161,13 @@ func (al *AgentLoop) runLLMIteration(
 			break
 		}
 
+		if err != nil && ctx.Err() != nil {
+			var partial *providers.PartialResponseError
+			if errors.As(err, &partial) {
+				steps = append(steps, partial.Content)
+			}
+			return strings.Join(steps, "\n\n"), iteration, err
+		}
 		if err != nil {
 			logger.ErrorCF("agent", "LLM call failed",
 				map[string]any{
@@ -876,6 +1177,10 @@ func (al *AgentLoop) runLLMIteration(
 				})
 			return "", iteration, fmt.Errorf("LLM call failed after retries: %w", err)
 		}
+		al.recordUsage(opts.SessionKey, usageModel, response.Usage)
+		if len(textToolDefs) > 0 {
+			parseTextToolCalls(response, iteration)
+		}
 
 		go al.handleReasoning(
 			ctx,
@@ -896,6 +1201,29 @@ func (al *AgentLoop) runLLMIteration(
 			})
 		// Check if no tool calls - we're done
 		if len(response.ToolCalls) == 0 {
+			// Some providers stop with no content at all, e.g. when the whole
+			// answer went into a refused tool call. Ask once more, then fall
+			// back to a canned reply so the user is never left hanging. A
+			// reply already sent with the message tool needs neither.
+			if strings.TrimSpace(response.Content) == "" && !al.messageSentInRound(opts.Channel, opts.ChatID) {
+				if !retriedEmpty && iteration < agent.MaxIterations {
+					retriedEmpty = true
+					logger.WarnCF("agent", "LLM returned an empty response, retrying",
+						map[string]any{
+							"agent_id":      agent.ID,
+							"iteration":     iteration,
+							"finish_reason": response.FinishReason,
+						})
+					continue
+				}
+				finalContent = al.emptyResponse(opts.Channel)
+				logger.WarnCF("agent", "LLM returned an empty response, sending fallback",
+					map[string]any{
+						"agent_id":  agent.ID,
+						"iteration": iteration,
+					})
+				break
+			}
 			finalContent = response.Content
 			logger.InfoCF("agent", "LLM response without tool calls (direct answer)",
 				map[string]any{
@@ -956,6 +1284,9 @@ func (al *AgentLoop) runLLMIteration(
 
 		// Save assistant message with tool calls to session
 		agent.Sessions.AddFullMessage(opts.SessionKey, assistantMsg)
+		if strings.TrimSpace(response.Content) != "" {
+			steps = append(steps, strings.TrimSpace(response.Content))
+		}
 
 		// Execute tool calls
 		for _, tc := range normalizedToolCalls {
@@ -984,14 +1315,21 @@ func (al *AgentLoop) runLLMIteration(
 				}
 			}
 
-			toolResult := agent.Tools.ExecuteWithContext(
-				ctx,
-				tc.Name,
-				tc.Arguments,
-				opts.Channel,
-				opts.ChatID,
-				asyncCallback,
-			)
+			var toolResult *tools.ToolResult
+			toolStart := time.Now()
+			if toolPolicy.Allows(tc.Name) {
+				toolResult = agent.Tools.ExecuteWithContext(
+					ctx,
+					tc.Name,
+					tc.Arguments,
+					opts.Channel,
+					opts.ChatID,
+					asyncCallback,
+				)
+			} else {
+				toolResult = tools.ErrorResult(fmt.Sprintf("tool %q is disabled for this conversation", tc.Name))
+			}
+			auditToolCall(agent, opts, tc, toolResult, time.Since(toolStart))
 
 			// Send ForUser content to user immediately if not Silent
 			if !toolResult.Silent && toolResult.ForUser != "" && opts.SendResponse {
@@ -1017,7 +1355,7 @@ func (al *AgentLoop) runLLMIteration(
 						if _, meta, err := al.mediaStore.ResolveWithMeta(ref); err == nil {
 							part.Filename = meta.Filename
 							part.ContentType = meta.ContentType
-							part.Type = inferMediaType(meta.Filename, meta.ContentType)
+							part.Type = media.InferType(meta.Filename, meta.ContentType)
 						}
 					}
 					parts = append(parts, part)
@@ -1045,11 +1383,72 @@ func (al *AgentLoop) runLLMIteration(
 			// Save tool result message to session
 			agent.Sessions.AddFullMessage(opts.SessionKey, toolResultMsg)
 		}
+
+		// Every tool call has its result by now, so the history stays
+		// valid if the turn ends here.
+		if ctx.Err() != nil {
+			return strings.Join(steps, "\n\n"), iteration, ctx.Err()
+		}
 	}
 
 	return finalContent, iteration, nil
 }
 
+// flushInterrupted saves the content a cancelled turn produced so far,
+// marked with InterruptedMarker, and returns it. A long turn stopped with
+// Ctrl+C keeps its useful partial work instead of losing all of it.
+func (al *AgentLoop) flushInterrupted(
+	agent *AgentInstance,
+	opts processOptions,
+	received time.Time,
+	partial string,
+) string {
+	content := strings.TrimSpace(partial) + "\n\n" + InterruptedMarker
+	agent.Sessions.AddMessage(opts.SessionKey, "assistant", content)
+	agent.Sessions.Save(opts.SessionKey)
+	recordTranscript(agent, opts, received, content)
+	logger.InfoCF("agent", "Turn interrupted, kept partial response",
+		map[string]any{
+			"agent_id":    agent.ID,
+			"session_key": opts.SessionKey,
+			"length":      len(partial),
+		})
+	return content
+}
+
+// auditToolCall records a finished tool call in the agent's audit log.
+func auditToolCall(
+	agent *AgentInstance,
+	opts processOptions,
+	tc providers.ToolCall,
+	result *tools.ToolResult,
+	duration time.Duration,
+) {
+	if agent.Audit == nil {
+		return
+	}
+	entry := audit.Entry{
+		Channel:    opts.Channel,
+		ChatID:     opts.ChatID,
+		Agent:      agent.ID,
+		Skill:      audit.SkillFor(tc.Arguments),
+		Tool:       tc.Name,
+		Args:       tc.Arguments,
+		DurationMS: duration.Milliseconds(),
+		Success:    !result.IsError,
+	}
+	if result.IsError {
+		entry.Error = result.ForLLM
+		if result.Err != nil {
+			entry.Error = result.Err.Error()
+		}
+		entry.Error = utils.Truncate(entry.Error, 500)
+	}
+	if err := agent.Audit.Record(entry); err != nil {
+		logger.WarnCF("agent", "Failed to write audit log", map[string]any{"tool": tc.Name, "error": err.Error()})
+	}
+}
+
 // updateToolContexts updates the context for tools that need channel/chatID info.
 func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID string) {
 	// Use ContextualTool interface instead of type assertions
@@ -1058,6 +1457,11 @@ func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID st
 			mt.SetContext(channel, chatID)
 		}
 	}
+	if tool, ok := agent.Tools.Get("send_message"); ok {
+		if st, ok := tool.(tools.ContextualTool); ok {
+			st.SetContext(channel, chatID)
+		}
+	}
 	if tool, ok := agent.Tools.Get("spawn"); ok {
 		if st, ok := tool.(tools.ContextualTool); ok {
 			st.SetContext(channel, chatID)
@@ -1070,6 +1474,16 @@ func (al *AgentLoop) updateToolContexts(agent *AgentInstance, channel, chatID st
 	}
 }
 
+// sendMessageTool returns the agent's send_message tool, if it has one.
+func sendMessageTool(agent *AgentInstance) (*tools.SendMessageTool, bool) {
+	tool, ok := agent.Tools.Get("send_message")
+	if !ok {
+		return nil, false
+	}
+	st, ok := tool.(*tools.SendMessageTool)
+	return st, ok
+}
+
 // maybeSummarize triggers summarization if the session history exceeds thresholds.
 func (al *AgentLoop) maybeSummarize(agent *AgentInstance, sessionKey, channel, chatID string) {
 	newHistory := agent.Sessions.GetHistory(sessionKey)
@@ -1082,7 +1496,7 @@ func (al *AgentLoop) maybeSummarize(agent *AgentInstance, sessionKey, channel, c
 			go func() {
 				defer al.summarizing.Delete(summarizeKey)
 				logger.Debug("Memory threshold reached. Optimizing conversation history...")
-				al.summarizeSession(agent, sessionKey)
+				al.summarizeSession(agent, sessionKey, channel, chatID)
 			}()
 		}
 	}
@@ -1228,8 +1642,11 @@ func formatToolsForLog(toolDefs []providers.ToolDefinition) string {
 	return sb.String()
 }
 
-// summarizeSession summarizes the conversation history for a session.
-func (al *AgentLoop) summarizeSession(agent *AgentInstance, sessionKey string) {
+// summarizeSession distills the older part of a session into notes. The
+// notes are appended to the session's notes file and added to the summary
+// the prompt carries, which is summarized again once it outgrows
+// agents.defaults.summary_max_tokens.
+func (al *AgentLoop) summarizeSession(agent *AgentInstance, sessionKey, channel, chatID string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
@@ -1264,50 +1681,57 @@ func (al *AgentLoop) summarizeSession(agent *AgentInstance, sessionKey string) {
 		return
 	}
 
+	provider, model := al.summaryModel(agent, channel, chatID)
+
 	// Multi-Part Summarization
-	var finalSummary string
+	batches := [][]providers.Message{validMessages}
 	if len(validMessages) > 10 {
 		mid := len(validMessages) / 2
-		part1 := validMessages[:mid]
-		part2 := validMessages[mid:]
-
-		s1, _ := al.summarizeBatch(ctx, agent, part1, "")
-		s2, _ := al.summarizeBatch(ctx, agent, part2, "")
-
-		mergePrompt := fmt.Sprintf(
-			"Merge these two conversation summaries into one cohesive summary:\n\n1: %s\n\n2: %s",
-			s1,
-			s2,
-		)
-		resp, err := agent.Provider.Chat(
-			ctx,
-			[]providers.Message{{Role: "user", Content: mergePrompt}},
-			nil,
-			agent.Model,
-			map[string]any{
-				"max_tokens":       1024,
-				"temperature":      0.3,
-				"prompt_cache_key": agent.ID,
-			},
-		)
-		if err == nil {
-			finalSummary = resp.Content
-		} else {
-			finalSummary = s1 + " " + s2
+		batches = [][]providers.Message{validMessages[:mid], validMessages[mid:]}
+	}
+	parts := make([]string, 0, len(batches))
+	existing := summary
+	for _, batch := range batches {
+		// Each batch sees the notes of the batches before it, so they are not repeated
+		notes, err := al.distillNotes(ctx, agent, provider, model, batch, existing)
+		if err != nil {
+			logger.WarnCF("agent", "Failed to summarize session",
+				map[string]any{"session_key": sessionKey, "error": err.Error()})
+			return
+		}
+		if notes = strings.TrimSpace(notes); notes != "" {
+			parts = append(parts, notes)
+			existing = strings.TrimSpace(existing + "\n\n" + notes)
 		}
-	} else {
-		finalSummary, _ = al.summarizeBatch(ctx, agent, validMessages, summary)
 	}
+	if len(parts) == 0 {
+		return
+	}
+	notes := strings.Join(parts, "\n\n")
 
-	if omitted && finalSummary != "" {
-		finalSummary += "\n[Note: Some oversized messages were omitted from this summary for efficiency.]"
+	if omitted {
+		notes += "\n[Note: Some oversized messages were omitted from these notes for efficiency.]"
 	}
 
-	if finalSummary != "" {
-		agent.Sessions.SetSummary(sessionKey, finalSummary)
-		agent.Sessions.TruncateHistory(sessionKey, 4)
-		agent.Sessions.Save(sessionKey)
+	if err := appendSessionNotes(agent.Workspace, sessionKey, notes); err != nil {
+		logger.WarnCF("agent", "Failed to write session notes",
+			map[string]any{"session_key": sessionKey, "error": err.Error()})
 	}
+
+	summary = strings.TrimSpace(summary + "\n\n" + notes)
+	if maxTokens := al.summaryMaxTokens(); al.estimateTokens([]providers.Message{{Content: summary}}) > maxTokens {
+		compacted, err := al.compactNotes(ctx, agent, provider, model, summary, maxTokens)
+		if err != nil {
+			logger.WarnCF("agent", "Failed to compact session notes",
+				map[string]any{"session_key": sessionKey, "error": err.Error()})
+		} else if compacted = strings.TrimSpace(compacted); compacted != "" {
+			summary = compacted
+		}
+	}
+
+	agent.Sessions.SetSummary(sessionKey, summary)
+	agent.Sessions.TruncateHistory(sessionKey, 4)
+	agent.Sessions.Save(sessionKey)
 }
 
 // summarizeBatch summarizes a batch of messages.
@@ -1361,96 +1785,6 @@ func (al *AgentLoop) estimateTokens(messages []providers.Message) int {
 	return totalChars * 2 / 5
 }
 
-func (al *AgentLoop) handleCommand(ctx context.Context, msg bus.InboundMessage) (string, bool) {
-	content := strings.TrimSpace(msg.Content)
-	if !strings.HasPrefix(content, "/") {
-		return "", false
-	}
-
-	parts := strings.Fields(content)
-	if len(parts) == 0 {
-		return "", false
-	}
-
-	cmd := parts[0]
-	args := parts[1:]
-
-	switch cmd {
-	case "/show":
-		if len(args) < 1 {
-			return "Usage: /show [model|channel|agents]", true
-		}
-		switch args[0] {
-		case "model":
-			defaultAgent := al.registry.GetDefaultAgent()
-			if defaultAgent == nil {
-				return "No default agent configured", true
-			}
-			return fmt.Sprintf("Current model: %s", defaultAgent.Model), true
-		case "channel":
-			return fmt.Sprintf("Current channel: %s", msg.Channel), true
-		case "agents":
-			agentIDs := al.registry.ListAgentIDs()
-			return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", ")), true
-		default:
-			return fmt.Sprintf("Unknown show target: %s", args[0]), true
-		}
-
-	case "/list":
-		if len(args) < 1 {
-			return "Usage: /list [models|channels|agents]", true
-		}
-		switch args[0] {
-		case "models":
-			return "Available models: configured in config.json per agent", true
-		case "channels":
-			if al.channelManager == nil {
-				return "Channel manager not initialized", true
-			}
-			channels := al.channelManager.GetEnabledChannels()
-			if len(channels) == 0 {
-				return "No channels enabled", true
-			}
-			return fmt.Sprintf("Enabled channels: %s", strings.Join(channels, ", ")), true
-		case "agents":
-			agentIDs := al.registry.ListAgentIDs()
-			return fmt.Sprintf("Registered agents: %s", strings.Join(agentIDs, ", ")), true
-		default:
-			return fmt.Sprintf("Unknown list target: %s", args[0]), true
-		}
-
-	case "/switch":
-		if len(args) < 3 || args[1] != "to" {
-			return "Usage: /switch [model|channel] to <name>", true
-		}
-		target := args[0]
-		value := args[2]
-
-		switch target {
-		case "model":
-			defaultAgent := al.registry.GetDefaultAgent()
-			if defaultAgent == nil {
-				return "No default agent configured", true
-			}
-			oldModel := defaultAgent.Model
-			defaultAgent.Model = value
-			return fmt.Sprintf("Switched model from %s to %s", oldModel, value), true
-		case "channel":
-			if al.channelManager == nil {
-				return "Channel manager not initialized", true
-			}
-			if _, exists := al.channelManager.GetChannel(value); !exists && value != "cli" {
-				return fmt.Sprintf("Channel '%s' not found or not enabled", value), true
-			}
-			return fmt.Sprintf("Switched target channel to %s", value), true
-		default:
-			return fmt.Sprintf("Unknown switch target: %s", target), true
-		}
-	}
-
-	return "", false
-}
-
 // extractPeer extracts the routing peer from the inbound message's structured Peer field.
 func extractPeer(msg bus.InboundMessage) *routing.RoutePeer {
 	if msg.Peer.Kind == "" {
@@ -9,9 +9,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sipeed/picoclaw/pkg/audit"
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/providers"
 	"github.com/sipeed/picoclaw/pkg/tools"
 )
@@ -27,6 +29,8 @@ func (f *fakeChannel) IsAllowed(string) bool                                   {
 func (f *fakeChannel) IsAllowedSender(sender bus.SenderInfo) bool              { return true }
 func (f *fakeChannel) ReasoningChannelID() string                              { return f.id }
 
+func (f *fakeChannel) HandleEditedMessage(string, string, string, string, map[string]string) {}
+
 func newTestAgentLoop(
 	t *testing.T,
 ) (al *AgentLoop, cfg *config.Config, msgBus *bus.MessageBus, provider *mockProvider, cleanup func()) {
@@ -808,3 +812,248 @@ func TestHandleReasoning(t *testing.T) {
 		}
 	})
 }
+
+func TestProcessAsContact_DeliversReplyToContact(t *testing.T) {
+	al, _, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	resp, err := al.ProcessAsContact(context.Background(), "water the plants", "line", "U123")
+	if err != nil {
+		t.Fatalf("ProcessAsContact failed: %v", err)
+	}
+	if resp != "Mock response" {
+		t.Errorf("response = %q, want mock response", resp)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("expected reply to be published to the contact")
+	}
+	if out.Channel != "line" || out.ChatID != "U123" || out.Content != "Mock response" {
+		t.Errorf("published %+v, want Mock response to line:U123", out)
+	}
+}
+
+func TestAuditToolCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "tools.jsonl")
+	agent := &AgentInstance{ID: "main", Audit: audit.NewLog(path, nil)}
+	opts := processOptions{Channel: "telegram", ChatID: "42"}
+	tc := providers.ToolCall{
+		Name:      "exec",
+		Arguments: map[string]any{"command": "sh skills/weather/run.sh", "password": "hunter2"},
+	}
+
+	auditToolCall(agent, opts, tc, tools.ErrorResult("exit status 1"), 1500*time.Millisecond)
+
+	entries, err := audit.Read(path, audit.Filter{})
+	if err != nil {
+		t.Fatalf("audit.Read: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Tool != "exec" || e.Skill != "weather" || e.Channel != "telegram" || e.ChatID != "42" || e.Agent != "main" {
+		t.Errorf("entry = %+v, want exec of skill weather from telegram:42", e)
+	}
+	if e.Success || e.Error != "exit status 1" || e.DurationMS != 1500 {
+		t.Errorf("entry outcome = %+v, want a 1500ms failure", e)
+	}
+	if e.Args["password"] != audit.Redacted {
+		t.Errorf("password = %v, want redacted", e.Args["password"])
+	}
+}
+
+func TestProcessDirect_AgentScopedSessionKeyPicksAgent(t *testing.T) {
+	_, cfg, msgBus, provider, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Agents.Named = map[string]config.AgentConfig{
+		"main":  {Default: true},
+		"coder": {Workspace: filepath.Join(cfg.Agents.Defaults.Workspace, "coder")},
+	}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	sessionKey := "agent:coder:cli:default"
+	if _, err := al.ProcessDirect(context.Background(), "hello", sessionKey); err != nil {
+		t.Fatalf("ProcessDirect failed: %v", err)
+	}
+
+	coder, _ := al.registry.GetAgent("coder")
+	main, _ := al.registry.GetAgent("main")
+	if len(coder.Sessions.GetHistory(sessionKey)) == 0 {
+		t.Error("expected the coder agent to handle the turn")
+	}
+	if len(main.Sessions.GetHistory(sessionKey)) != 0 {
+		t.Error("expected the main agent to be left alone")
+	}
+}
+
+// emptyFirstMockProvider answers with empty content on the first N calls
+type emptyFirstMockProvider struct {
+	empties int
+	calls   int
+}
+
+func (m *emptyFirstMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls++
+	if m.calls <= m.empties {
+		return &providers.LLMResponse{Content: " \n", FinishReason: "stop"}, nil
+	}
+	return &providers.LLMResponse{Content: "Here you go", FinishReason: "stop"}, nil
+}
+
+func (m *emptyFirstMockProvider) GetDefaultModel() string {
+	return "mock-empty-model"
+}
+
+func TestProcessDirect_EmptyResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		empties       int
+		emptyResponse string
+		want          string
+		wantCalls     int
+	}{
+		{"retried once", 1, "", "Here you go", 2},
+		{"falls back", 5, "", i18n.T("", i18n.AgentEmptyResponse), 2},
+		{"configured fallback", 5, "Nothing to say.", "Nothing to say.", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cfg, msgBus, _, cleanup := newTestAgentLoop(t)
+			defer cleanup()
+			cfg.Agents.Defaults.EmptyResponse = tt.emptyResponse
+			provider := &emptyFirstMockProvider{empties: tt.empties}
+			al := NewAgentLoop(cfg, msgBus, provider)
+
+			got, err := al.ProcessDirect(context.Background(), "hello", "agent:main:test-empty")
+			if err != nil {
+				t.Fatalf("ProcessDirect failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("response = %q, want %q", got, tt.want)
+			}
+			if provider.calls != tt.wantCalls {
+				t.Errorf("provider calls = %d, want %d", provider.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// messageThenEmptyMockProvider replies through the message tool and then
+// ends the turn with empty content.
+type messageThenEmptyMockProvider struct {
+	calls int
+}
+
+func (m *messageThenEmptyMockProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	m.calls++
+	if m.calls == 1 {
+		return &providers.LLMResponse{ToolCalls: []providers.ToolCall{{
+			ID:        "c1",
+			Name:      "message",
+			Arguments: map[string]any{"content": "Sent directly"},
+		}}}, nil
+	}
+	return &providers.LLMResponse{FinishReason: "stop"}, nil
+}
+
+func (m *messageThenEmptyMockProvider) GetDefaultModel() string {
+	return "mock-message-model"
+}
+
+func TestProcessMessage_EmptyAfterMessageToolIsFinal(t *testing.T) {
+	_, cfg, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	provider := &messageThenEmptyMockProvider{}
+	al := NewAgentLoop(cfg, msgBus, provider)
+
+	if _, err := al.processMessage(context.Background(), commandMessage("hi")); err != nil {
+		t.Fatalf("processMessage failed: %v", err)
+	}
+	if provider.calls != 2 {
+		t.Errorf("provider calls = %d, want 2 with no retry after the message tool replied", provider.calls)
+	}
+}
+
+func TestProcessMessage_Maintenance(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	mode, err := al.EnableMaintenance(config.MaintenanceConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(channel string) string {
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: channel, SenderID: "42", ChatID: "chat", Content: "hello",
+		})
+		if err != nil {
+			t.Fatalf("processMessage: %v", err)
+		}
+		return resp
+	}
+
+	if got := send("telegram"); got != "Mock response" {
+		t.Fatalf("before maintenance got %q", got)
+	}
+	if err := mode.Set(true); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := send("telegram"), i18n.T("", i18n.AgentMaintenance); got != want {
+		t.Errorf("in maintenance got %q, want %q", got, want)
+	}
+	if got := send("cli"); got != "Mock response" {
+		t.Errorf("internal channels should not be affected, got %q", got)
+	}
+
+	// The switch is persisted, so a reconfigured mode is still on.
+	if _, err := al.EnableMaintenance(config.MaintenanceConfig{Message: "Back at 9."}); err != nil {
+		t.Fatal(err)
+	}
+	if got := send("telegram"); got != "Back at 9." {
+		t.Errorf("configured message: got %q", got)
+	}
+}
+
+func TestProcessMessage_NotConfigured(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	al.SetNotConfigured("")
+
+	send := func(channel string) string {
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: channel, SenderID: "42", ChatID: "chat", Content: "hello",
+		})
+		if err != nil {
+			t.Fatalf("processMessage: %v", err)
+		}
+		return resp
+	}
+
+	if got, want := send("telegram"), i18n.T("", i18n.AgentNotConfigured); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := send("cli"); got != "Mock response" {
+		t.Errorf("internal channels should not be affected, got %q", got)
+	}
+
+	al.SetNotConfigured("Setup in progress.")
+	if got := send("telegram"); got != "Setup in progress." {
+		t.Errorf("configured message: got %q", got)
+	}
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/tools"
+)
+
+// maxMentionBytes caps how much of the workspace one @mention may inline.
+const maxMentionBytes = 64 << 10
+
+// fileMentionRe matches @tokens at the start of the message or after
+// whitespace, so e-mail addresses are left alone.
+var fileMentionRe = regexp.MustCompile(`(^|\s)@([^\s@]+)`)
+
+// expandFileMentions inlines workspace files referenced as @path in a user
+// message. Each @path becomes the bare path in the text and the file's
+// contents are appended under a header. Only path-like tokens (containing a
+// "/" or a file extension) count, so chat handles like @alice pass through.
+// A mention that is missing, outside the workspace or not a readable text
+// file is an error rather than being silently ignored.
+func expandFileMentions(workspace, content string) (string, error) {
+	if !strings.Contains(content, "@") {
+		return content, nil
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	text := fileMentionRe.ReplaceAllStringFunc(content, func(match string) string {
+		sub := fileMentionRe.FindStringSubmatch(match)
+		lead, token := sub[1], sub[2]
+		path := strings.TrimRight(token, ",;:!?)\"'.")
+		if !isPathLike(path) {
+			return match
+		}
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+		// Drop the @ but keep any trailing punctuation.
+		return lead + token
+	})
+	if len(paths) == 0 {
+		return content, nil
+	}
+
+	var sb strings.Builder
+	var mentionErr error
+	sb.WriteString(text)
+	for _, path := range paths {
+		data, err := readMentionedFile(workspace, path)
+		if err != nil {
+			mentionErr = errors.Join(mentionErr, fmt.Errorf("cannot include @%s: %w", path, err))
+			continue
+		}
+		fmt.Fprintf(&sb, "\n\n[File: %s]\n%s", path, strings.TrimRight(string(data), "\n"))
+		sb.WriteString("\n[End of file: " + path + "]")
+	}
+	if mentionErr != nil {
+		return "", mentionErr
+	}
+	return sb.String(), nil
+}
+
+func isPathLike(token string) bool {
+	return strings.Contains(token, "/") || filepath.Ext(token) != ""
+}
+
+func readMentionedFile(workspace, path string) ([]byte, error) {
+	full, err := tools.ValidateWorkspacePath(filepath.FromSlash(path), workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(full)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no such file in the workspace")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("is a directory")
+	}
+	if info.Size() > maxMentionBytes {
+		return nil, fmt.Errorf("file is too large (%d KB, limit %d KB)", info.Size()>>10, maxMentionBytes>>10)
+	}
+
+	data, err := os.ReadFile(full)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("not a text file")
+	}
+	return data, nil
+}
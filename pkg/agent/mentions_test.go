@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestExpandFileMentions(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "memory"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "memory", "notes.md"), []byte("buy milk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := expandFileMentions(workspace, "summarize @memory/notes.md, then compare with @memory/notes.md")
+	if err != nil {
+		t.Fatalf("expandFileMentions() error = %v", err)
+	}
+
+	if !strings.HasPrefix(got, "summarize memory/notes.md, then compare with memory/notes.md\n\n") {
+		t.Errorf("visible prompt not stripped of @ tokens: %q", got)
+	}
+	if strings.Count(got, "[File: memory/notes.md]\nbuy milk\n[End of file: memory/notes.md]") != 1 {
+		t.Errorf("file should be inlined once, got %q", got)
+	}
+}
+
+func TestExpandFileMentionsLeavesHandlesAlone(t *testing.T) {
+	for _, content := range []string{
+		"hi @alice, mail bob@example.com",
+		"no mentions here",
+	} {
+		got, err := expandFileMentions(t.TempDir(), content)
+		if err != nil {
+			t.Fatalf("expandFileMentions(%q) error = %v", content, err)
+		}
+		if got != content {
+			t.Errorf("expandFileMentions(%q) = %q, want unchanged", content, got)
+		}
+	}
+}
+
+func TestExpandFileMentionsErrors(t *testing.T) {
+	root := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	if err := os.MkdirAll(filepath.Join(workspace, "memory"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "secret.txt"), []byte("key"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "secret.txt"), filepath.Join(workspace, "link.txt")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "big.log"), make([]byte, maxMentionBytes+1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"read @missing.md", "@missing.md: no such file in the workspace"},
+		{"read @../secret.txt", "@../secret.txt: access denied: path is outside the workspace"},
+		{"read @" + filepath.Join(root, "secret.txt"), "path is outside the workspace"},
+		{"read @link.txt", "@link.txt: access denied: symlink resolves outside workspace"},
+		{"read @memory/", "@memory/: is a directory"},
+		{"read @big.log", "@big.log: file is too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			_, err := expandFileMentions(workspace, tt.content)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessMessageLeavesChannelMentionsAlone(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	_, err := al.processMessage(t.Context(), bus.InboundMessage{
+		Channel: "telegram", SenderID: "42", ChatID: "chat", Content: "ping @john.doe and @team/backend",
+	})
+	if err != nil {
+		t.Fatalf("processMessage() error = %v, want chat handles passed through", err)
+	}
+}
+
+func TestProcessMessageRejectsMissingMention(t *testing.T) {
+	al, _, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	_, err := al.ProcessDirect(t.Context(), "summarize @memory/nope.md", "test-session")
+	if err == nil || !strings.Contains(err.Error(), "cannot include @memory/nope.md") {
+		t.Fatalf("ProcessDirect() error = %v, want mention error", err)
+	}
+}
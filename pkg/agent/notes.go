@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// defaultSummaryMaxTokens caps the session notes kept in the prompt when
+// agents.defaults.summary_max_tokens is not set.
+const defaultSummaryMaxTokens = 800
+
+// notesHeadings are the sections session notes are written under.
+const notesHeadings = `### Facts
+What was learned about the user, their situation and their work.
+### Open tasks
+Requests and follow-ups that are not finished.
+### Preferences
+How the user wants things done: tone, format, tools, recurring choices.`
+
+var notesFileReplacer = strings.NewReplacer(":", "_", "/", "_", `\`, "_")
+
+// SessionNotesPath returns the file that notes distilled from a session's
+// older turns are appended to.
+func SessionNotesPath(workspace, sessionKey string) string {
+	return filepath.Join(workspace, "memory", "sessions", notesFileReplacer.Replace(sessionKey)+".md")
+}
+
+// appendSessionNotes adds a dated entry to the session's notes file.
+func appendSessionNotes(workspace, sessionKey, notes string) error {
+	path := SessionNotesPath(workspace, sessionKey)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "## %s\n\n%s\n\n", time.Now().Format("2006-01-02 15:04"), notes); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// summaryModel returns the provider and model that summarize a session:
+// agents.defaults.summary_model if set, else the chat's own model.
+func (al *AgentLoop) summaryModel(agent *AgentInstance, channel, chatID string) (providers.LLMProvider, string) {
+	if al.cfg == nil {
+		return agent.Provider, agent.Model
+	}
+	name := al.cfg.Agents.Defaults.SummaryModel
+	if name == "" {
+		name = al.chatModel(channel, chatID)
+	}
+	if name == "" {
+		return agent.Provider, agent.Model
+	}
+
+	mc, err := al.cfg.GetModelConfig(name)
+	if err == nil {
+		var provider providers.LLMProvider
+		var modelID string
+		if provider, modelID, err = al.modelProvider(mc); err == nil {
+			return provider, modelID
+		}
+	}
+	logger.WarnCF("agent", "Summary model unavailable, using the agent's model",
+		map[string]any{"model": name, "error": err.Error()})
+	return agent.Provider, agent.Model
+}
+
+// summaryMaxTokens returns how large the session notes in the prompt may
+// grow before they are summarized again.
+func (al *AgentLoop) summaryMaxTokens() int {
+	if al.cfg != nil && al.cfg.Agents.Defaults.SummaryMaxTokens > 0 {
+		return al.cfg.Agents.Defaults.SummaryMaxTokens
+	}
+	return defaultSummaryMaxTokens
+}
+
+// distillNotes turns a batch of messages into notes under notesHeadings,
+// leaving out what the existing notes already say.
+func (al *AgentLoop) distillNotes(
+	ctx context.Context,
+	agent *AgentInstance,
+	provider providers.LLMProvider,
+	model string,
+	batch []providers.Message,
+	existing string,
+) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("Distill this conversation segment into notes for continuing the conversation later. " +
+		"Use these headings and leave out any that would be empty:\n\n")
+	sb.WriteString(notesHeadings)
+	sb.WriteString("\n\nWrite short bullets. Do not repeat anything already in the existing notes.\n")
+	if existing != "" {
+		sb.WriteString("\nEXISTING NOTES:\n")
+		sb.WriteString(existing)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nCONVERSATION:\n")
+	for _, m := range batch {
+		fmt.Fprintf(&sb, "%s: %s\n", m.Role, m.Content)
+	}
+	return al.summaryChat(ctx, agent, provider, model, sb.String())
+}
+
+// compactNotes summarizes accumulated notes into a single set of at most
+// about maxTokens.
+func (al *AgentLoop) compactNotes(
+	ctx context.Context,
+	agent *AgentInstance,
+	provider providers.LLMProvider,
+	model string,
+	notes string,
+	maxTokens int,
+) (string, error) {
+	prompt := fmt.Sprintf("Rewrite these notes as one set under the same headings:\n\n%s\n\n"+
+		"Merge duplicates, keep only the latest version of anything that changed, drop tasks that were "+
+		"finished, and keep the result under %d words.\n\nNOTES:\n%s",
+		notesHeadings, maxTokens*3/4, notes)
+	return al.summaryChat(ctx, agent, provider, model, prompt)
+}
+
+func (al *AgentLoop) summaryChat(
+	ctx context.Context,
+	agent *AgentInstance,
+	provider providers.LLMProvider,
+	model, prompt string,
+) (string, error) {
+	resp, err := provider.Chat(
+		ctx,
+		[]providers.Message{{Role: "user", Content: prompt}},
+		nil,
+		model,
+		map[string]any{
+			"max_tokens":       1024,
+			"temperature":      0.3,
+			"prompt_cache_key": agent.ID,
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
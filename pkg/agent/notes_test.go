@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// notesProvider answers distill prompts with notes and compaction prompts
+// with compacted, recording every prompt it gets.
+type notesProvider struct {
+	notes     string
+	compacted string
+	prompts   []string
+}
+
+func (p *notesProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	prompt := messages[len(messages)-1].Content
+	p.prompts = append(p.prompts, prompt)
+	if strings.HasPrefix(prompt, "Rewrite these notes") {
+		return &providers.LLMResponse{Content: p.compacted}, nil
+	}
+	return &providers.LLMResponse{Content: p.notes}, nil
+}
+
+func (p *notesProvider) GetDefaultModel() string { return "test-model" }
+
+const notesSession = "agent:main:notes"
+
+func newNotesTestLoop(t *testing.T, defaults config.AgentDefaults) (*AgentLoop, *AgentInstance, *notesProvider) {
+	t.Helper()
+	defaults.Workspace = t.TempDir()
+	defaults.Model = "test-model"
+	defaults.MaxTokens = 4096
+	defaults.MaxToolIterations = 10
+	cfg := &config.Config{
+		Agents:    config.AgentsConfig{Defaults: defaults},
+		ModelList: []config.ModelConfig{{ModelName: "cheap", Model: "openai/cheap-model"}},
+	}
+	provider := &notesProvider{
+		notes:     "### Facts\n- Flies to Kyoto in May",
+		compacted: "### Facts\n- Kyoto in May (compacted)",
+	}
+	al := NewAgentLoop(cfg, bus.NewMessageBus(), provider)
+	agent := al.registry.GetDefaultAgent()
+	for i := range 6 {
+		agent.Sessions.AddMessage(notesSession, "user", fmt.Sprintf("question %d", i))
+		agent.Sessions.AddMessage(notesSession, "assistant", fmt.Sprintf("answer %d", i))
+	}
+	return al, agent, provider
+}
+
+func TestSummarizeSession_WritesNotes(t *testing.T) {
+	al, agent, provider := newNotesTestLoop(t, config.AgentDefaults{})
+
+	al.summarizeSession(agent, notesSession, "telegram", "123")
+
+	if got := agent.Sessions.GetSummary(notesSession); got != provider.notes {
+		t.Errorf("summary = %q, want the distilled notes", got)
+	}
+	if n := len(agent.Sessions.GetHistory(notesSession)); n != 4 {
+		t.Errorf("history has %d messages after summarizing, want 4", n)
+	}
+	if !strings.Contains(provider.prompts[0], "### Open tasks") ||
+		!strings.Contains(provider.prompts[0], "user: question 0") {
+		t.Errorf("distill prompt = %q, want the headings and the conversation", provider.prompts[0])
+	}
+
+	data, err := os.ReadFile(SessionNotesPath(agent.Workspace, notesSession))
+	if err != nil {
+		t.Fatalf("notes file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "## ") || !strings.Contains(string(data), provider.notes) {
+		t.Errorf("notes file = %q, want a dated entry with the notes", data)
+	}
+
+	// A second round passes the existing notes along and appends to the file.
+	for i := range 4 {
+		agent.Sessions.AddMessage(notesSession, "user", fmt.Sprintf("more %d", i))
+	}
+	al.summarizeSession(agent, notesSession, "telegram", "123")
+	if last := provider.prompts[len(provider.prompts)-1]; !strings.Contains(last, "EXISTING NOTES:\n"+provider.notes) {
+		t.Errorf("second distill prompt = %q, want the existing notes", last)
+	}
+	data, _ = os.ReadFile(SessionNotesPath(agent.Workspace, notesSession))
+	if n := strings.Count(string(data), "\n## "); n != 1 {
+		t.Errorf("notes file has %d later entries, want 1:\n%s", n, data)
+	}
+}
+
+func TestSummarizeSession_BatchesSeeEarlierNotes(t *testing.T) {
+	al, agent, provider := newNotesTestLoop(t, config.AgentDefaults{})
+	for i := range 6 {
+		agent.Sessions.AddMessage(notesSession, "user", fmt.Sprintf("extra %d", i))
+	}
+
+	al.summarizeSession(agent, notesSession, "telegram", "123")
+
+	if len(provider.prompts) != 2 {
+		t.Fatalf("got %d distill prompts, want 2 batches", len(provider.prompts))
+	}
+	if strings.Contains(provider.prompts[0], "EXISTING NOTES") {
+		t.Errorf("first batch prompt = %q, want no existing notes", provider.prompts[0])
+	}
+	if !strings.Contains(provider.prompts[1], "EXISTING NOTES:\n"+provider.notes) {
+		t.Errorf("second batch prompt = %q, want the first batch's notes", provider.prompts[1])
+	}
+}
+
+func TestSummarizeSession_CompactsLongNotes(t *testing.T) {
+	al, agent, provider := newNotesTestLoop(t, config.AgentDefaults{SummaryMaxTokens: 10})
+	agent.Sessions.SetSummary(notesSession, strings.Repeat("- an old fact\n", 10))
+
+	al.summarizeSession(agent, notesSession, "telegram", "123")
+
+	if got := agent.Sessions.GetSummary(notesSession); got != provider.compacted {
+		t.Errorf("summary = %q, want the compacted notes", got)
+	}
+	last := provider.prompts[len(provider.prompts)-1]
+	if !strings.HasPrefix(last, "Rewrite these notes") || !strings.Contains(last, "- an old fact") ||
+		!strings.Contains(last, provider.notes) {
+		t.Errorf("compaction prompt = %q, want the old and new notes", last)
+	}
+}
+
+func TestSummarizeSession_UsesSummaryModel(t *testing.T) {
+	al, agent, provider := newNotesTestLoop(t, config.AgentDefaults{SummaryModel: "cheap"})
+	cheap := &notesProvider{notes: "### Preferences\n- Short answers"}
+	al.capabilityProviders.Store("cheap", capabilityProviderEntry{provider: cheap, modelID: "cheap-model"})
+
+	al.summarizeSession(agent, notesSession, "telegram", "123")
+
+	if len(provider.prompts) != 0 || len(cheap.prompts) != 1 {
+		t.Errorf("agent model got %d prompts, summary model %d; want 0 and 1",
+			len(provider.prompts), len(cheap.prompts))
+	}
+	if got := agent.Sessions.GetSummary(notesSession); got != cheap.notes {
+		t.Errorf("summary = %q, want the summary model's notes", got)
+	}
+}
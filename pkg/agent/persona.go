@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// The system prompt is layered from the most general source to the most
+// specific, each under its own heading:
+//
+//  1. global identity: IDENTITY.md in the global config dir (~/.picoclaw)
+//  2. agent prompt:    the agent's system_prompt_file, if any
+//  3. workspace files: AGENTS.md, SOUL.md, USER.md, IDENTITY.md
+//  4. channel persona: <workspace>/personas/<channel>.md
+//  5. chat persona:    <workspace>/personas/<channel>/<chat_id>.md
+//
+// Layers 1 to 3 are part of the cached static prompt. Personas depend on
+// the chat, so they are read per request through promptFileCache.
+const personasDir = "personas"
+
+// promptFileCache keeps the contents of prompt files and rereads a file only
+// when its mtime or size changes, so edits apply without a restart.
+type promptFileCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedPromptFile
+}
+
+type cachedPromptFile struct {
+	modTime time.Time
+	size    int64
+	content string
+}
+
+// read returns the trimmed contents of path, or "" when it does not exist.
+func (c *promptFileCache) read(path string) string {
+	info, err := os.Stat(path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		delete(c.entries, path)
+		return ""
+	}
+	if e, ok := c.entries[path]; ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		return e.content
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]cachedPromptFile)
+	}
+	content := strings.TrimSpace(string(data))
+	c.entries[path] = cachedPromptFile{modTime: info.ModTime(), size: info.Size(), content: content}
+	return content
+}
+
+// personaPaths returns the channel and chat persona files for a chat.
+func (cb *ContextBuilder) personaPaths(channel, chatID string) (channelPath, chatPath string) {
+	if channel == "" {
+		return "", ""
+	}
+	dir := filepath.Join(cb.workspace, personasDir)
+	channel = utils.SanitizeFilename(channel)
+	channelPath = filepath.Join(dir, channel+".md")
+	if chatID != "" {
+		chatPath = filepath.Join(dir, channel, utils.SanitizeFilename(chatID)+".md")
+	}
+	return channelPath, chatPath
+}
+
+// buildPersonaContext returns the channel and chat persona sections for a
+// chat, or "" when neither file exists.
+func (cb *ContextBuilder) buildPersonaContext(channel, chatID string) string {
+	channelPath, chatPath := cb.personaPaths(channel, chatID)
+	var parts []string
+	if channelPath != "" {
+		if content := cb.files.read(channelPath); content != "" {
+			parts = append(parts, "# Channel Persona ("+channel+")\n\n"+content)
+		}
+	}
+	if chatPath != "" {
+		if content := cb.files.read(chatPath); content != "" {
+			parts = append(parts, "# Chat Persona ("+channel+":"+chatID+")\n\n"+content)
+		}
+	}
+	return strings.Join(parts, "\n\n---\n\n")
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessages_PromptLayerOrder(t *testing.T) {
+	tmpDir := setupWorkspace(t, map[string]string{
+		"AGENTS.md":                   "workspace agents",
+		"prompts/coder.md":            "agent prompt",
+		"personas/telegram.md":        "telegram persona",
+		"personas/telegram/chat42.md": "chat persona",
+		"personas/discord.md":         "discord persona",
+	})
+	defer os.RemoveAll(tmpDir)
+	globalDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(globalDir, "IDENTITY.md"), []byte("global identity"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cb := NewContextBuilder(tmpDir)
+	cb.globalDir = globalDir
+	cb.SetAgentPrompt("prompts/coder.md")
+
+	prompt := cb.BuildMessages(nil, "", "hi", nil, "telegram", "chat42")[0].Content
+	order := []string{"global identity", "agent prompt", "workspace agents", "telegram persona", "chat persona", "## Current Time"}
+	last := -1
+	for _, want := range order {
+		i := strings.Index(prompt, want)
+		if i < 0 {
+			t.Fatalf("prompt is missing %q", want)
+		}
+		if i < last {
+			t.Errorf("%q appears out of order", want)
+		}
+		last = i
+	}
+	if strings.Contains(prompt, "discord persona") {
+		t.Error("prompt includes another channel's persona")
+	}
+
+	other := cb.BuildMessages(nil, "", "hi", nil, "telegram", "chat7")[0].Content
+	if !strings.Contains(other, "telegram persona") || strings.Contains(other, "chat persona") {
+		t.Error("a chat without its own persona should get only the channel persona")
+	}
+}
+
+func TestBuildMessages_PersonaEditsApplyWithoutRestart(t *testing.T) {
+	tmpDir := setupWorkspace(t, map[string]string{"personas/telegram.md": "be formal"})
+	defer os.RemoveAll(tmpDir)
+	globalDir := t.TempDir()
+	globalIdentity := filepath.Join(globalDir, "IDENTITY.md")
+
+	cb := NewContextBuilder(tmpDir)
+	cb.globalDir = globalDir
+
+	if prompt := cb.BuildMessages(nil, "", "hi", nil, "telegram", "1")[0].Content; !strings.Contains(prompt, "be formal") {
+		t.Fatal("persona missing from the first prompt")
+	}
+
+	persona := filepath.Join(tmpDir, "personas", "telegram.md")
+	if err := os.WriteFile(persona, []byte("be casual"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(2 * time.Second)
+	os.Chtimes(persona, future, future)
+	if err := os.WriteFile(globalIdentity, []byte("new global identity"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(globalIdentity, future, future)
+
+	prompt := cb.BuildMessages(nil, "", "hi", nil, "telegram", "1")[0].Content
+	if strings.Contains(prompt, "be formal") || !strings.Contains(prompt, "be casual") {
+		t.Error("persona edit was not picked up")
+	}
+	if !strings.Contains(prompt, "new global identity") {
+		t.Error("new global IDENTITY.md was not picked up")
+	}
+
+	os.Remove(persona)
+	if prompt := cb.BuildMessages(nil, "", "hi", nil, "telegram", "1")[0].Content; strings.Contains(prompt, "Channel Persona") {
+		t.Error("deleted persona is still in the prompt")
+	}
+}
+
+func TestPersonaPaths_SanitizesIDs(t *testing.T) {
+	cb := NewContextBuilder("/ws")
+	channelPath, chatPath := cb.personaPaths("slack", "../../etc/passwd")
+	if channelPath != filepath.Join("/ws", "personas", "slack.md") {
+		t.Errorf("channel path = %q", channelPath)
+	}
+	if !strings.HasPrefix(chatPath, filepath.Join("/ws", "personas", "slack")+string(filepath.Separator)) ||
+		strings.Contains(chatPath, "..") {
+		t.Errorf("chat path %q escapes the personas directory", chatPath)
+	}
+}
@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+// senderWindow is the in-memory message history of one sender.
+type senderWindow struct {
+	recent      []time.Time // messages accepted in the last hour
+	silentUntil time.Time   // already warned; ignore messages until then
+}
+
+// senderLimiter enforces the per-sender rate limits. Minute and hour
+// windows live in memory; daily usage is written through to the state
+// manager so a restart doesn't reset budgets.
+type senderLimiter struct {
+	cfg   config.RateLimitConfig
+	state *state.Manager
+	now   func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*senderWindow
+	daily   map[string]state.SenderUsage
+}
+
+func newSenderLimiter(cfg config.RateLimitConfig, sm *state.Manager) *senderLimiter {
+	l := &senderLimiter{
+		cfg:     cfg,
+		state:   sm,
+		now:     time.Now,
+		windows: make(map[string]*senderWindow),
+		daily:   make(map[string]state.SenderUsage),
+	}
+	if sm != nil {
+		for key, usage := range sm.SenderUsages() {
+			l.daily[key] = usage
+		}
+	}
+	return l
+}
+
+// allow records a message from senderKey ("channel:sender_id") and reports
+// whether the agent may handle it. For a rejected message, notify is true
+// the first time a limit is hit; later messages in the window get silence.
+func (l *senderLimiter) allow(channel, senderKey string) (ok, notify bool) {
+	rule := l.cfg.RuleFor(channel)
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage := l.usageLocked(senderKey, now)
+	if rule.DailyTokens > 0 && usage.Tokens >= rule.DailyTokens {
+		if usage.Warned {
+			return false, false
+		}
+		usage.Warned = true
+		l.saveLocked(senderKey, usage)
+		logger.InfoCF("agent", "Sender exceeded daily token budget",
+			map[string]any{"sender": senderKey, "tokens": usage.Tokens, "limit": rule.DailyTokens})
+		return false, true
+	}
+
+	w, exists := l.windows[senderKey]
+	if !exists {
+		w = &senderWindow{}
+		l.windows[senderKey] = w
+	}
+	w.recent = trimBefore(w.recent, now.Add(-time.Hour))
+
+	var until time.Time
+	if rule.MessagesPerHour > 0 && len(w.recent) >= rule.MessagesPerHour {
+		until = w.recent[len(w.recent)-rule.MessagesPerHour].Add(time.Hour)
+	}
+	if rule.MessagesPerMinute > 0 {
+		lastMinute := trimBefore(w.recent, now.Add(-time.Minute))
+		if len(lastMinute) >= rule.MessagesPerMinute {
+			if t := lastMinute[len(lastMinute)-rule.MessagesPerMinute].Add(time.Minute); t.After(until) {
+				until = t
+			}
+		}
+	}
+	if !until.IsZero() {
+		if now.Before(w.silentUntil) {
+			return false, false
+		}
+		w.silentUntil = until
+		logger.InfoCF("agent", "Sender rate limited",
+			map[string]any{"sender": senderKey, "until": until.Format(time.RFC3339)})
+		return false, true
+	}
+
+	w.recent = append(w.recent, now)
+	usage.Messages++
+	l.saveLocked(senderKey, usage)
+	return true, false
+}
+
+// addTokens charges tokens spent on a sender's turn to their daily budget.
+func (l *senderLimiter) addTokens(senderKey string, tokens int) {
+	if tokens <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	usage := l.usageLocked(senderKey, l.now())
+	usage.Tokens += tokens
+	l.saveLocked(senderKey, usage)
+}
+
+// usageLocked returns today's usage for senderKey, starting afresh when the
+// recorded usage is from an earlier day.
+func (l *senderLimiter) usageLocked(senderKey string, now time.Time) state.SenderUsage {
+	today := now.Format(time.DateOnly)
+	usage := l.daily[senderKey]
+	if usage.Day != today {
+		usage = state.SenderUsage{Day: today}
+	}
+	return usage
+}
+
+func (l *senderLimiter) saveLocked(senderKey string, usage state.SenderUsage) {
+	for key, u := range l.daily {
+		if u.Day != usage.Day {
+			delete(l.daily, key)
+		}
+	}
+	l.daily[senderKey] = usage
+	if l.state == nil {
+		return
+	}
+	if err := l.state.SetSenderUsage(senderKey, usage); err != nil {
+		logger.WarnCF("agent", "Failed to save sender usage",
+			map[string]any{"sender": senderKey, "error": err.Error()})
+	}
+}
+
+// trimBefore drops the times up to and including cutoff from a sorted
+// slice, so a message falls out of a window exactly one window later.
+func trimBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// senderKey identifies a sender across chats for rate limiting. Sender IDs
+// of the form "id|username" are keyed by the id alone.
+func senderKey(channel, senderID string) string {
+	idPart, _, _ := strings.Cut(senderID, "|")
+	return fmt.Sprintf("%s:%s", channel, idPart)
+}
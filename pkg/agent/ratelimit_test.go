@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func newTestLimiter(t *testing.T, cfg config.RateLimitConfig, now *time.Time) *senderLimiter {
+	t.Helper()
+	l := newSenderLimiter(cfg, state.NewManager(t.TempDir()))
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestSenderLimiter_WarnsOnceThenSilent(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	l := newTestLimiter(t, config.RateLimitConfig{MessagesPerMinute: 2}, &now)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("telegram", "telegram:1"); !ok {
+			t.Fatalf("message %d rejected", i)
+		}
+	}
+	if ok, notify := l.allow("telegram", "telegram:1"); ok || !notify {
+		t.Fatalf("first violation = %v, %v; want rejected with notice", ok, notify)
+	}
+	if ok, notify := l.allow("telegram", "telegram:1"); ok || notify {
+		t.Fatalf("second violation = %v, %v; want silent rejection", ok, notify)
+	}
+	if ok, _ := l.allow("telegram", "telegram:2"); !ok {
+		t.Fatal("other senders must not be limited")
+	}
+
+	now = now.Add(time.Minute)
+	if ok, _ := l.allow("telegram", "telegram:1"); !ok {
+		t.Fatal("sender still limited after the window passed")
+	}
+}
+
+func TestSenderLimiter_ChannelOverride(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	l := newTestLimiter(t, config.RateLimitConfig{
+		MessagesPerHour: 10,
+		Channels:        map[string]config.RateLimitRule{"discord": {MessagesPerHour: 1}},
+	}, &now)
+
+	l.allow("discord", "discord:1")
+	if ok, _ := l.allow("discord", "discord:1"); ok {
+		t.Error("discord override not applied")
+	}
+	l.allow("slack", "slack:1")
+	if ok, _ := l.allow("slack", "slack:1"); !ok {
+		t.Error("global limit should apply outside discord")
+	}
+}
+
+func TestSenderLimiter_DailyTokensPersist(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	sm := state.NewManager(t.TempDir())
+	cfg := config.RateLimitConfig{DailyTokens: 100}
+
+	l := newSenderLimiter(cfg, sm)
+	l.now = func() time.Time { return now }
+	l.allow("telegram", "telegram:1")
+	l.addTokens("telegram:1", 150)
+
+	// A restarted gateway still knows the budget is spent.
+	l = newSenderLimiter(cfg, sm)
+	l.now = func() time.Time { return now }
+	if ok, notify := l.allow("telegram", "telegram:1"); ok || !notify {
+		t.Fatalf("after restart = %v, %v; want rejected with notice", ok, notify)
+	}
+	if ok, notify := l.allow("telegram", "telegram:1"); ok || notify {
+		t.Fatalf("second violation = %v, %v; want silent rejection", ok, notify)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if ok, _ := l.allow("telegram", "telegram:1"); !ok {
+		t.Error("budget should reset the next day")
+	}
+}
+
+func TestProcessMessage_RateLimitExemptsOwners(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Owners = config.FlexibleStringSlice{"owner"}
+	cfg.RateLimit = config.RateLimitConfig{Enabled: true, MessagesPerMinute: 1, Response: "slow down"}
+	al.limiter = newSenderLimiter(cfg.RateLimit, nil)
+
+	send := func(sender string) string {
+		resp, err := al.processMessage(context.Background(), bus.InboundMessage{
+			Channel: "telegram", SenderID: sender, ChatID: "chat", Content: "hello",
+		})
+		if err != nil {
+			t.Fatalf("processMessage: %v", err)
+		}
+		return resp
+	}
+
+	send("stranger")
+	if resp := send("stranger"); resp != "slow down" {
+		t.Errorf("limited sender got %q", resp)
+	}
+	send("owner")
+	if resp := send("owner"); resp == "slow down" {
+		t.Error("owner must be exempt from rate limits")
+	}
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+	"github.com/sipeed/picoclaw/pkg/routing"
+)
+
+const (
+	defaultRecentContextTurns     = 6
+	defaultRecentContextMaxTokens = 400
+)
+
+// recentContextCache keeps the last recent-context summary so proactive runs
+// between two owner messages do not summarize the same history again.
+type recentContextCache struct {
+	mu          sync.Mutex
+	fingerprint string
+	summary     string
+}
+
+// recordOwnerSession remembers the session of the owner's latest message for
+// RecentContext. Only senders listed in commands.owners count, so without
+// configured owners nothing is recorded; cron and other pre-keyed turns
+// never count.
+func (al *AgentLoop) recordOwnerSession(msg bus.InboundMessage, sessionKey string) {
+	if al.state == nil || msg.SessionKey != "" || msg.SenderID == "cron" || constants.IsInternalChannel(msg.Channel) {
+		return
+	}
+	if al.cfg == nil || !al.isOwner(msg) {
+		return
+	}
+	if err := al.state.SetLastSessionKey(sessionKey); err != nil {
+		logger.WarnCF("agent", "Failed to record owner session", map[string]any{"error": err.Error()})
+	}
+}
+
+// RecentContext returns a compact summary of the last turns of the owner's
+// most recent session, for heartbeat and cron prompts that refer to earlier
+// conversations. It stays within maxTokens and returns "" when there is no
+// session yet or summarizing fails. Zero turns or maxTokens use defaults.
+func (al *AgentLoop) RecentContext(ctx context.Context, turns, maxTokens int) string {
+	if turns <= 0 {
+		turns = defaultRecentContextTurns
+	}
+	if maxTokens <= 0 {
+		maxTokens = defaultRecentContextMaxTokens
+	}
+	if al.state == nil {
+		return ""
+	}
+	sessionKey := al.state.GetLastSessionKey()
+	if sessionKey == "" {
+		return ""
+	}
+
+	agent := al.registry.GetDefaultAgent()
+	if parsed := routing.ParseAgentSessionKey(sessionKey); parsed != nil {
+		if a, ok := al.registry.GetAgent(parsed.AgentID); ok {
+			agent = a
+		}
+	}
+	if agent == nil {
+		return ""
+	}
+
+	recent := lastTurns(agent.Sessions.GetHistory(sessionKey), turns)
+	if len(recent) == 0 {
+		return ""
+	}
+	earlier := agent.Sessions.GetSummary(sessionKey)
+
+	last := recent[len(recent)-1]
+	fingerprint := fmt.Sprintf("%s|%d|%d|%s|%d", sessionKey, turns, maxTokens, last.Content, len(earlier))
+	al.recentContext.mu.Lock()
+	defer al.recentContext.mu.Unlock()
+	if al.recentContext.fingerprint == fingerprint {
+		return al.recentContext.summary
+	}
+
+	summary, err := al.summarizeBatch(ctx, agent, recent, earlier)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to summarize recent context",
+			map[string]any{"session_key": sessionKey, "error": err.Error()})
+		return ""
+	}
+	summary = truncateToTokens(strings.TrimSpace(summary), maxTokens)
+	al.recentContext.fingerprint = fingerprint
+	al.recentContext.summary = summary
+	return summary
+}
+
+// WithRecentContext appends the owner's recent conversation to prompt; see
+// RecentContext.
+func (al *AgentLoop) WithRecentContext(ctx context.Context, prompt string, turns, maxTokens int) string {
+	summary := al.RecentContext(ctx, turns, maxTokens)
+	if summary == "" {
+		return prompt
+	}
+	return prompt + "\n\n## Recent conversation with the user\n\n" + summary
+}
+
+// lastTurns returns the user and assistant messages of the last turns user
+// turns, dropping tool traffic.
+func lastTurns(history []providers.Message, turns int) []providers.Message {
+	start, seen := len(history), 0
+	for i := len(history) - 1; i >= 0 && seen < turns; i-- {
+		if history[i].Role == "user" {
+			seen++
+			start = i
+		}
+	}
+	var recent []providers.Message
+	for _, m := range history[start:] {
+		if (m.Role == "user" || m.Role == "assistant") && m.Content != "" {
+			recent = append(recent, m)
+		}
+	}
+	return recent
+}
+
+// truncateToTokens cuts text to about maxTokens using the same 2.5
+// characters per token estimate as estimateTokens.
+func truncateToTokens(text string, maxTokens int) string {
+	maxRunes := maxTokens * 5 / 2
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:maxRunes])) + "…"
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// countingProvider answers every call with response and counts the calls.
+type countingProvider struct {
+	response string
+	calls    int
+}
+
+func (p *countingProvider) Chat(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+) (*providers.LLMResponse, error) {
+	p.calls++
+	return &providers.LLMResponse{Content: p.response}, nil
+}
+
+func (p *countingProvider) GetDefaultModel() string { return "test-model" }
+
+func newRecentContextLoop(t *testing.T, response string) (*AgentLoop, *countingProvider) {
+	t.Helper()
+	cfg := &config.Config{
+		Agents: config.AgentsConfig{
+			Defaults: config.AgentDefaults{
+				Workspace:         t.TempDir(),
+				Model:             "test-model",
+				MaxTokens:         4096,
+				MaxToolIterations: 10,
+			},
+		},
+		Commands: config.CommandsConfig{Owners: config.FlexibleStringSlice{"42"}},
+	}
+	provider := &countingProvider{response: response}
+	return NewAgentLoop(cfg, bus.NewMessageBus(), provider), provider
+}
+
+func TestRecentContext_NoSession(t *testing.T) {
+	al, provider := newRecentContextLoop(t, "summary")
+
+	if got := al.RecentContext(context.Background(), 5, 100); got != "" {
+		t.Errorf("RecentContext() = %q, want empty without a session", got)
+	}
+	if got := al.WithRecentContext(context.Background(), "Check the inbox", 5, 100); got != "Check the inbox" {
+		t.Errorf("WithRecentContext() = %q, want the prompt unchanged", got)
+	}
+	if provider.calls != 0 {
+		t.Errorf("provider called %d times, want 0", provider.calls)
+	}
+}
+
+func TestRecentContext_NoOwnersConfigured(t *testing.T) {
+	al, provider := newRecentContextLoop(t, "summary")
+	al.cfg.Commands.Owners = nil
+	ctx := context.Background()
+
+	if _, err := al.processMessage(ctx, commandMessage("Let's plan the Kyoto trip")); err != nil {
+		t.Fatalf("processMessage: %v", err)
+	}
+	callsAfterTurn := provider.calls
+
+	if got := al.RecentContext(ctx, 5, 100); got != "" {
+		t.Errorf("RecentContext() = %q, want empty: without owners nobody's chat is shared", got)
+	}
+	if provider.calls != callsAfterTurn {
+		t.Error("no summary should be made without owners")
+	}
+}
+
+func TestRecentContext_SummarizesOwnerSessionOnce(t *testing.T) {
+	al, provider := newRecentContextLoop(t, "We planned the trip to Kyoto.")
+	ctx := context.Background()
+
+	if _, err := al.processMessage(ctx, commandMessage("Let's plan the Kyoto trip")); err != nil {
+		t.Fatalf("processMessage: %v", err)
+	}
+	callsAfterTurn := provider.calls
+
+	got := al.WithRecentContext(ctx, "Check the inbox", 5, 100)
+	if !strings.HasPrefix(got, "Check the inbox") || !strings.Contains(got, "We planned the trip to Kyoto.") {
+		t.Errorf("WithRecentContext() = %q, want the prompt followed by the summary", got)
+	}
+	al.RecentContext(ctx, 5, 100)
+	if n := provider.calls - callsAfterTurn; n != 1 {
+		t.Errorf("summarized %d times, want 1 (cached)", n)
+	}
+}
+
+func TestRecentContext_RespectsTokenCap(t *testing.T) {
+	al, _ := newRecentContextLoop(t, strings.Repeat("word ", 500))
+	ctx := context.Background()
+
+	if _, err := al.processMessage(ctx, commandMessage("hello")); err != nil {
+		t.Fatalf("processMessage: %v", err)
+	}
+	got := al.RecentContext(ctx, 5, 20)
+	if n := len([]rune(got)); n > 20*5/2+1 {
+		t.Errorf("summary has %d runes, want at most %d", n, 20*5/2+1)
+	}
+}
+
+func TestRecentContext_IgnoresCronTurns(t *testing.T) {
+	al, _ := newRecentContextLoop(t, "reply")
+	ctx := context.Background()
+
+	if _, err := al.ProcessDirectWithChannel(ctx, "run job", "cron-1", "telegram", "chat1"); err != nil {
+		t.Fatalf("ProcessDirectWithChannel: %v", err)
+	}
+	if got := al.RecentContext(ctx, 5, 100); got != "" {
+		t.Errorf("RecentContext() = %q, want empty after only cron turns", got)
+	}
+}
@@ -26,7 +26,7 @@ func NewAgentRegistry(
 		resolver: routing.NewRouteResolver(cfg),
 	}
 
-	agentConfigs := cfg.Agents.List
+	agentConfigs := cfg.Agents.All()
 	if len(agentConfigs) == 0 {
 		implicitAgent := &config.AgentConfig{
 			ID:      "main",
@@ -34,7 +34,7 @@ func NewAgentRegistry(
 		}
 		instance := NewAgentInstance(implicitAgent, &cfg.Agents.Defaults, cfg, provider)
 		registry.agents["main"] = instance
-		logger.InfoCF("agent", "Created implicit main agent (no agents.list configured)", nil)
+		logger.InfoCF("agent", "Created implicit main agent (no agents configured)", nil)
 	} else {
 		for i := range agentConfigs {
 			ac := &agentConfigs[i]
@@ -51,6 +51,15 @@ func NewAgentRegistry(
 		}
 	}
 
+	for _, b := range registry.resolver.UnknownBindings() {
+		logger.WarnCF("agent", "Binding names an unknown agent; its messages go to the default agent",
+			map[string]any{
+				"agent_id": b.AgentID,
+				"channel":  b.Match.Channel,
+				"default":  registry.resolver.DefaultAgentID(),
+			})
+	}
+
 	return registry
 }
 
@@ -104,6 +113,9 @@ func (r *AgentRegistry) CanSpawnSubagent(parentAgentID, targetAgentID string) bo
 func (r *AgentRegistry) GetDefaultAgent() *AgentInstance {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	if agent, ok := r.agents[r.resolver.DefaultAgentID()]; ok {
+		return agent
+	}
 	if agent, ok := r.agents["main"]; ok {
 		return agent
 	}
@@ -203,3 +203,34 @@ func TestAgentInstance_FallbackExplicitEmpty(t *testing.T) {
 		t.Errorf("expected 0 fallbacks (explicit empty), got %d: %v", len(agent.Fallbacks), agent.Fallbacks)
 	}
 }
+
+func TestNewAgentRegistry_NamedAgents(t *testing.T) {
+	cfg := testCfg(nil)
+	cfg.Agents.Defaults.Workspace = t.TempDir()
+	cfg.Agents.Named = map[string]config.AgentConfig{
+		"butler": {Default: true, Workspace: cfg.Agents.Defaults.Workspace},
+		"coder": {
+			Workspace: cfg.Agents.Defaults.Workspace,
+			Model:     &config.AgentModelConfig{Primary: "claude"},
+			Tools:     "exec",
+		},
+	}
+	registry := NewAgentRegistry(cfg, &mockRegistryProvider{})
+
+	if ids := registry.ListAgentIDs(); len(ids) != 2 {
+		t.Fatalf("expected 2 agents, got %v", ids)
+	}
+	if def := registry.GetDefaultAgent(); def == nil || def.ID != "butler" {
+		t.Fatalf("default agent = %v, want butler", def)
+	}
+	coder, ok := registry.GetAgent("coder")
+	if !ok {
+		t.Fatal("expected to find 'coder' agent")
+	}
+	if coder.Model != "claude" {
+		t.Errorf("coder.Model = %q, want 'claude'", coder.Model)
+	}
+	if !coder.ToolPolicy.Allows("exec") || coder.ToolPolicy.Allows("read_file") {
+		t.Error("expected coder to be limited to exec")
+	}
+}
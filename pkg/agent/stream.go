@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// defaultStreamEditInterval is the least time between two partial messages
+// when gateway.streaming.edit_interval_ms is not set.
+const defaultStreamEditInterval = time.Second
+
+// replyStream publishes the text of a reply as it is written, as partial
+// outbound messages that the channel manager edits into the placeholder.
+// Publishing is throttled so channels stay within their edit rate limits.
+type replyStream struct {
+	ctx      context.Context
+	cfg      *config.Config
+	bus      *bus.MessageBus
+	channel  string
+	chatID   string
+	interval time.Duration
+	text     strings.Builder
+	last     time.Time
+}
+
+// newReplyStream returns a stream for the turn's reply, or nil when
+// streaming is off or the turn's reply is not sent to a chat.
+func (al *AgentLoop) newReplyStream(ctx context.Context, opts processOptions) *replyStream {
+	if !opts.Stream || al.cfg == nil || !al.cfg.Gateway.Streaming.Enabled ||
+		constants.IsInternalChannel(opts.Channel) {
+		return nil
+	}
+	interval := defaultStreamEditInterval
+	if ms := al.cfg.Gateway.Streaming.EditIntervalMS; ms > 0 {
+		interval = time.Duration(ms) * time.Millisecond
+	}
+	return &replyStream{
+		ctx:      ctx,
+		cfg:      al.cfg,
+		bus:      al.bus,
+		channel:  opts.Channel,
+		chatID:   opts.ChatID,
+		interval: interval,
+	}
+}
+
+// reset starts over for a new model call; the next text replaces what was
+// shown so far.
+func (s *replyStream) reset() {
+	s.text.Reset()
+	s.last = time.Time{}
+}
+
+// write adds a piece of reply text and publishes the text so far unless
+// the last partial message went out less than an interval ago.
+func (s *replyStream) write(delta string) {
+	s.text.WriteString(delta)
+	if time.Since(s.last) < s.interval || strings.TrimSpace(s.text.String()) == "" {
+		return
+	}
+	s.last = time.Now()
+	s.bus.PublishOutbound(s.ctx, bus.OutboundMessage{
+		Channel: s.channel,
+		ChatID:  s.chatID,
+		Content: s.text.String(),
+		Partial: true,
+	})
+}
+
+// chat calls provider.Chat, or streams the reply through s when s is not
+// nil, the provider can stream and the model has the streaming capability.
+func (s *replyStream) chat(
+	ctx context.Context,
+	provider providers.LLMProvider,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	options map[string]any,
+) (*providers.LLMResponse, error) {
+	sp, ok := provider.(providers.StreamingProvider)
+	if s == nil || !ok || !lookupModelInfo(s.cfg, model).Has(providers.CapabilityStreaming) {
+		return provider.Chat(ctx, messages, tools, model, options)
+	}
+	s.reset()
+	return sp.ChatStream(ctx, messages, tools, model, options, s.write)
+}
@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// streamingProvider streams its reply in fixed pieces.
+type streamingProvider struct {
+	mockProvider
+	pieces []string
+}
+
+func (p *streamingProvider) ChatStream(
+	ctx context.Context,
+	messages []providers.Message,
+	tools []providers.ToolDefinition,
+	model string,
+	opts map[string]any,
+	onDelta func(string),
+) (*providers.LLMResponse, error) {
+	content := ""
+	for _, piece := range p.pieces {
+		content += piece
+		onDelta(piece)
+	}
+	return &providers.LLMResponse{Content: content}, nil
+}
+
+// drainOutbound returns the outbound messages published so far.
+func drainOutbound(msgBus *bus.MessageBus) []bus.OutboundMessage {
+	var msgs []bus.OutboundMessage
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		msg, ok := msgBus.SubscribeOutbound(ctx)
+		cancel()
+		if !ok {
+			return msgs
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+func TestReplyStream_PublishesPartials(t *testing.T) {
+	al, cfg, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Gateway.Streaming.Enabled = true
+	cfg.Gateway.Streaming.EditIntervalMS = int(time.Hour / time.Millisecond)
+	al.registry.GetDefaultAgent().Provider = &streamingProvider{pieces: []string{"Hel", "lo", " world"}}
+
+	resp, err := al.processMessageWithStream(context.Background(), commandMessage("hi"), true)
+	if err != nil {
+		t.Fatalf("processMessageWithStream() error: %v", err)
+	}
+	if resp != "Hello world" {
+		t.Errorf("response = %q, want %q", resp, "Hello world")
+	}
+
+	// The first piece goes out at once; the rest wait for the interval,
+	// and the complete reply is left to the caller.
+	msgs := drainOutbound(msgBus)
+	if len(msgs) != 1 || !msgs[0].Partial || msgs[0].Content != "Hel" || msgs[0].ChatID != "chat1" {
+		t.Errorf("outbound = %+v, want one partial message with %q", msgs, "Hel")
+	}
+}
+
+func TestReplyStream_ThroughCoalescingProvider(t *testing.T) {
+	al, cfg, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Gateway.Streaming.Enabled = true
+	cfg.Agents.Defaults.CoalesceWindowSeconds = 5
+	inner := &streamingProvider{pieces: []string{"Hel", "lo"}}
+	al.registry.GetDefaultAgent().Provider = providers.NewCoalescingProvider(inner, 5*time.Second)
+
+	resp, err := al.processMessageWithStream(context.Background(), commandMessage("hi"), true)
+	if err != nil {
+		t.Fatalf("processMessageWithStream() error: %v", err)
+	}
+	if resp != "Hello" {
+		t.Errorf("response = %q, want %q", resp, "Hello")
+	}
+	if msgs := drainOutbound(msgBus); len(msgs) == 0 || !msgs[0].Partial {
+		t.Errorf("outbound = %+v, want the reply streamed through the coalescing wrapper", msgs)
+	}
+}
+
+func TestReplyStream_OnlyWhenRequested(t *testing.T) {
+	al, cfg, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Gateway.Streaming.Enabled = true
+	al.registry.GetDefaultAgent().Provider = &streamingProvider{pieces: []string{"Hello"}}
+
+	// Cron jobs do not publish the reply, so it is not streamed either.
+	if _, err := al.processMessage(context.Background(), commandMessage("hi")); err != nil {
+		t.Fatalf("processMessage() error: %v", err)
+	}
+	if msgs := drainOutbound(msgBus); len(msgs) != 0 {
+		t.Errorf("outbound = %+v, want none", msgs)
+	}
+
+	cfg.Gateway.Streaming.Enabled = false
+	if _, err := al.processMessageWithStream(context.Background(), commandMessage("hi"), true); err != nil {
+		t.Fatalf("processMessageWithStream() error: %v", err)
+	}
+	if msgs := drainOutbound(msgBus); len(msgs) != 0 {
+		t.Errorf("outbound with streaming disabled = %+v, want none", msgs)
+	}
+}
+
+func TestReplyStream_ModelWithoutStreaming(t *testing.T) {
+	cfg := &config.Config{ModelList: []config.ModelConfig{{ModelName: "cli", Model: "claude-cli/claude-sonnet"}}}
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+	s := &replyStream{ctx: context.Background(), cfg: cfg, bus: msgBus, channel: "telegram", chatID: "1"}
+	provider := &streamingProvider{pieces: []string{"Hello"}}
+
+	if _, err := s.chat(context.Background(), provider, nil, nil, "claude-sonnet", nil); err != nil {
+		t.Fatalf("chat() error: %v", err)
+	}
+	if msgs := drainOutbound(msgBus); len(msgs) != 0 {
+		t.Errorf("outbound = %+v, want no partials for a model that cannot stream", msgs)
+	}
+
+	if _, err := s.chat(context.Background(), provider, nil, nil, "gpt-4o", nil); err != nil {
+		t.Fatalf("chat() error: %v", err)
+	}
+	if msgs := drainOutbound(msgBus); len(msgs) != 1 {
+		t.Errorf("outbound = %+v, want the reply streamed", msgs)
+	}
+}
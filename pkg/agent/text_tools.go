@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// textToolMessages prepares a request for a model without native tool
+// calling: the tools are described in the system prompt, and earlier tool
+// calls and results are turned into plain text, which such models accept.
+func textToolMessages(messages []providers.Message, defs []providers.ToolDefinition) []providers.Message {
+	prompt := providers.TextToolsPrompt(defs)
+	out := make([]providers.Message, 0, len(messages))
+	for i, m := range messages {
+		switch {
+		case i == 0 && m.Role == "system":
+			m.Content += "\n\n" + prompt
+			if len(m.SystemParts) > 0 {
+				parts := make([]providers.ContentBlock, len(m.SystemParts), len(m.SystemParts)+1)
+				copy(parts, m.SystemParts)
+				m.SystemParts = append(parts, providers.ContentBlock{Type: "text", Text: prompt})
+			}
+		case m.Role == "tool":
+			m = providers.Message{Role: "user", Content: fmt.Sprintf("Tool result (%s):\n%s", m.ToolCallID, m.Content)}
+		case len(m.ToolCalls) > 0:
+			m = providers.Message{Role: m.Role, Content: strings.TrimSpace(m.Content + "\n" + textToolCalls(m.ToolCalls))}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// textToolCalls writes tool calls in the JSON form TextToolsPrompt asks for.
+func textToolCalls(calls []providers.ToolCall) string {
+	type function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	}
+	type call struct {
+		ID       string   `json:"id"`
+		Type     string   `json:"type"`
+		Function function `json:"function"`
+	}
+	wrapper := struct {
+		ToolCalls []call `json:"tool_calls"`
+	}{}
+	for _, tc := range calls {
+		tc = providers.NormalizeToolCall(tc)
+		args, _ := json.Marshal(tc.Arguments)
+		wrapper.ToolCalls = append(wrapper.ToolCalls, call{
+			ID:       tc.ID,
+			Type:     "function",
+			Function: function{Name: tc.Name, Arguments: string(args)},
+		})
+	}
+	data, _ := json.Marshal(wrapper)
+	return string(data)
+}
+
+// parseTextToolCalls moves the tool calls a model wrote into its reply to
+// resp.ToolCalls, giving each a unique ID since models copy the example's.
+func parseTextToolCalls(resp *providers.LLMResponse, iteration int) {
+	if resp == nil || len(resp.ToolCalls) > 0 {
+		return
+	}
+	calls, rest := providers.ExtractTextToolCalls(resp.Content)
+	if len(calls) == 0 {
+		return
+	}
+	for i := range calls {
+		calls[i].ID = fmt.Sprintf("call_text_%d_%d", iteration, i+1)
+	}
+	resp.ToolCalls = calls
+	resp.Content = rest
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestTextToolMessages(t *testing.T) {
+	defs := []providers.ToolDefinition{{
+		Type:     "function",
+		Function: providers.ToolFunctionDefinition{Name: "read_file", Description: "Read a file"},
+	}}
+	messages := []providers.Message{
+		{Role: "system", Content: "You are picoclaw."},
+		{Role: "user", Content: "show notes.txt"},
+		{Role: "assistant", ToolCalls: []providers.ToolCall{
+			{ID: "call_1", Name: "read_file", Arguments: map[string]any{"path": "notes.txt"}},
+		}},
+		{Role: "tool", ToolCallID: "call_1", Content: "buy milk"},
+	}
+
+	got := textToolMessages(messages, defs)
+
+	if !strings.Contains(got[0].Content, "#### read_file") {
+		t.Errorf("system prompt does not describe the tools: %q", got[0].Content)
+	}
+	if len(got[2].ToolCalls) != 0 || !strings.Contains(got[2].Content, `"name":"read_file"`) {
+		t.Errorf("tool call not turned into text: %+v", got[2])
+	}
+	if got[3].Role != "user" || !strings.Contains(got[3].Content, "buy milk") {
+		t.Errorf("tool result not turned into a user message: %+v", got[3])
+	}
+	if messages[0].Content != "You are picoclaw." || len(messages[2].ToolCalls) != 1 {
+		t.Error("textToolMessages modified its input")
+	}
+}
+
+func TestParseTextToolCalls(t *testing.T) {
+	resp := &providers.LLMResponse{Content: `Let me look.
+{"tool_calls":[{"id":"call_xxx","type":"function","function":{"name":"read_file","arguments":"{\"path\":\"a.txt\"}"}}]}`}
+
+	parseTextToolCalls(resp, 2)
+
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" {
+		t.Fatalf("ToolCalls = %+v, want one read_file call", resp.ToolCalls)
+	}
+	if resp.ToolCalls[0].ID != "call_text_2_1" {
+		t.Errorf("ID = %q, want a unique ID", resp.ToolCalls[0].ID)
+	}
+	if resp.Content != "Let me look." {
+		t.Errorf("Content = %q, want the call stripped", resp.Content)
+	}
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// ToolPolicy selects which registered tools are offered to the model.
+// The zero value is "auto": every registered tool is available.
+type ToolPolicy struct {
+	none    bool
+	allowed map[string]bool // nil means all tools
+}
+
+// ParseToolPolicy parses a tool policy spec: "none", "auto" (or empty), or a
+// comma-separated list of tool names.
+func ParseToolPolicy(spec string) (ToolPolicy, error) {
+	spec = strings.TrimSpace(spec)
+	switch strings.ToLower(spec) {
+	case "", "auto":
+		return ToolPolicy{}, nil
+	case "none":
+		return ToolPolicy{none: true}, nil
+	}
+
+	allowed := make(map[string]bool)
+	for _, name := range strings.Split(spec, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			allowed[name] = true
+		}
+	}
+	if len(allowed) == 0 {
+		return ToolPolicy{}, fmt.Errorf("invalid tools value %q: expected none, auto or a comma-separated list", spec)
+	}
+	return ToolPolicy{allowed: allowed}, nil
+}
+
+// IsNone reports whether the policy disables tool use entirely.
+func (p ToolPolicy) IsNone() bool {
+	return p.none
+}
+
+// Allows reports whether the named tool may be offered and executed.
+func (p ToolPolicy) Allows(name string) bool {
+	if p.none {
+		return false
+	}
+	return p.allowed == nil || p.allowed[name]
+}
+
+// Filter returns the subset of defs permitted by the policy.
+func (p ToolPolicy) Filter(defs []providers.ToolDefinition) []providers.ToolDefinition {
+	if p.none {
+		return nil
+	}
+	if p.allowed == nil {
+		return defs
+	}
+	filtered := make([]providers.ToolDefinition, 0, len(p.allowed))
+	for _, def := range defs {
+		if p.allowed[def.Function.Name] {
+			filtered = append(filtered, def)
+		}
+	}
+	return filtered
+}
+
+// isAuto reports whether the policy offers every tool.
+func (p ToolPolicy) isAuto() bool {
+	return !p.none && p.allowed == nil
+}
+
+// intersect returns the policy allowing only tools both p and q allow.
+func (p ToolPolicy) intersect(q ToolPolicy) ToolPolicy {
+	switch {
+	case p.none || q.none:
+		return ToolPolicy{none: true}
+	case p.allowed == nil:
+		return q
+	case q.allowed == nil:
+		return p
+	}
+	allowed := make(map[string]bool)
+	for name := range p.allowed {
+		if q.allowed[name] {
+			allowed[name] = true
+		}
+	}
+	if len(allowed) == 0 {
+		return ToolPolicy{none: true}
+	}
+	return ToolPolicy{allowed: allowed}
+}
+
+// SetToolPolicy sets the tool policy applied to every turn that has no
+// channel-specific policy in config.
+func (al *AgentLoop) SetToolPolicy(p ToolPolicy) {
+	al.toolPolicy = p
+}
+
+// toolPolicyFor returns the policy for a turn of agent on channel: a
+// restrictive loop-wide policy or else the agent's own, narrowed further by
+// agents.defaults.channel_tools. A channel can take tools away from an agent
+// but never give it more.
+func (al *AgentLoop) toolPolicyFor(agent *AgentInstance, channel string) ToolPolicy {
+	policy := al.toolPolicy
+	if policy.isAuto() && agent != nil {
+		policy = agent.ToolPolicy
+	}
+	if al.cfg != nil {
+		if spec, ok := al.cfg.Agents.Defaults.ChannelTools[channel]; ok {
+			if p, err := ParseToolPolicy(spec); err == nil {
+				policy = policy.intersect(p)
+			}
+		}
+	}
+	return policy
+}
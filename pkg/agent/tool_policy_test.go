@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func toolDefs(names ...string) []providers.ToolDefinition {
+	defs := make([]providers.ToolDefinition, 0, len(names))
+	for _, name := range names {
+		defs = append(defs, providers.ToolDefinition{
+			Type:     "function",
+			Function: providers.ToolFunctionDefinition{Name: name},
+		})
+	}
+	return defs
+}
+
+func TestParseToolPolicy(t *testing.T) {
+	defs := toolDefs("exec", "read_file", "web_search")
+
+	tests := []struct {
+		spec string
+		want int
+	}{
+		{"", 3},
+		{"auto", 3},
+		{"none", 0},
+		{"NONE", 0},
+		{"read_file, web_search", 2},
+		{"read_file,unknown", 1},
+	}
+	for _, tt := range tests {
+		p, err := ParseToolPolicy(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseToolPolicy(%q) error: %v", tt.spec, err)
+		}
+		if got := len(p.Filter(defs)); got != tt.want {
+			t.Errorf("ParseToolPolicy(%q): got %d tools, want %d", tt.spec, got, tt.want)
+		}
+	}
+
+	if _, err := ParseToolPolicy(" , "); err == nil {
+		t.Error("expected error for empty tool list")
+	}
+}
+
+func TestToolPolicyAllows(t *testing.T) {
+	p, _ := ParseToolPolicy("read_file")
+	if !p.Allows("read_file") || p.Allows("exec") {
+		t.Error("list policy should allow only listed tools")
+	}
+	if !(ToolPolicy{}).Allows("exec") {
+		t.Error("auto policy should allow every tool")
+	}
+	none, _ := ParseToolPolicy("none")
+	if none.Allows("read_file") || !none.IsNone() {
+		t.Error("none policy should allow nothing")
+	}
+}
+
+func TestToolPolicyFor_ChannelOverride(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	cfg.Agents.Defaults.ChannelTools = map[string]string{"telegram": "none"}
+	al.SetToolPolicy(ToolPolicy{allowed: map[string]bool{"exec": true}})
+
+	if !al.toolPolicyFor(nil, "telegram").IsNone() {
+		t.Error("expected channel policy to override loop policy")
+	}
+	if p := al.toolPolicyFor(nil, "cli"); !p.Allows("exec") || p.Allows("read_file") {
+		t.Error("expected loop policy for channels without override")
+	}
+}
+
+func TestToolPolicyFor_AgentPolicy(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	coder := &AgentInstance{ToolPolicy: ToolPolicy{allowed: map[string]bool{"exec": true}}}
+	if p := al.toolPolicyFor(coder, "cli"); !p.Allows("exec") || p.Allows("read_file") {
+		t.Error("expected the agent policy under an auto loop policy")
+	}
+
+	al.SetToolPolicy(ToolPolicy{none: true})
+	if !al.toolPolicyFor(coder, "cli").IsNone() {
+		t.Error("expected a restrictive loop policy to override the agent policy")
+	}
+
+	al.SetToolPolicy(ToolPolicy{})
+	cfg.Agents.Defaults.ChannelTools = map[string]string{"telegram": "auto", "line": "exec,read_file", "slack": "read_file"}
+	if p := al.toolPolicyFor(coder, "telegram"); !p.Allows("exec") || p.Allows("read_file") {
+		t.Error("expected channel policy not to widen the agent policy")
+	}
+	if p := al.toolPolicyFor(coder, "line"); !p.Allows("exec") || p.Allows("read_file") {
+		t.Error("expected the intersection of the channel and agent policies")
+	}
+	if !al.toolPolicyFor(coder, "slack").IsNone() {
+		t.Error("expected no tools when the channel and agent policies share none")
+	}
+}
@@ -0,0 +1,26 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/constants"
+	"github.com/sipeed/picoclaw/pkg/history"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// recordTranscript appends a user message and its reply to the chat's
+// transcript. Heartbeat runs and internal channels are not recorded.
+func recordTranscript(agent *AgentInstance, opts processOptions, received time.Time, reply string) {
+	if agent.History == nil || opts.NoHistory || opts.Channel == "" || opts.ChatID == "" ||
+		constants.IsInternalChannel(opts.Channel) {
+		return
+	}
+	err := agent.History.Append(opts.Channel, opts.ChatID,
+		history.Entry{Time: received, Role: "user", Content: opts.UserMessage},
+		history.Entry{Role: "assistant", Agent: agent.ID, Content: reply},
+	)
+	if err != nil {
+		logger.WarnCF("agent", "Failed to record transcript",
+			map[string]any{"channel": opts.Channel, "chat_id": opts.ChatID, "error": err.Error()})
+	}
+}
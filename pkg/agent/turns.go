@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// TurnStats is a snapshot of the concurrent turn limiter, reported by the
+// gateway health endpoint.
+type TurnStats struct {
+	InFlight      int `json:"in_flight"`
+	Queued        int `json:"queued"`
+	MaxConcurrent int `json:"max_concurrent"`
+	MaxQueued     int `json:"max_queued"`
+}
+
+// turnLimiter bounds how many agent turns run at once and how many inbound
+// messages may wait for a free slot.
+type turnLimiter struct {
+	slots     chan struct{}
+	maxQueued int
+	queued    atomic.Int32
+	inFlight  atomic.Int32
+}
+
+func newTurnLimiter(maxConcurrent, maxQueued int) *turnLimiter {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	if maxQueued < 0 {
+		maxQueued = 0
+	}
+	return &turnLimiter{
+		slots:     make(chan struct{}, maxConcurrent),
+		maxQueued: maxQueued,
+	}
+}
+
+// reserve claims a place in the queue. It returns false when the queue is
+// full and the message should be turned away.
+func (l *turnLimiter) reserve() bool {
+	for {
+		n := l.queued.Load()
+		// Messages that will find a free slot straight away don't count
+		// against the queue bound.
+		free := cap(l.slots) - int(l.inFlight.Load())
+		if int(n) >= l.maxQueued+free {
+			return false
+		}
+		if l.queued.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// acquire waits for a free slot for a turn previously reserved. The
+// reservation is given up either way.
+func (l *turnLimiter) acquire(ctx context.Context) bool {
+	defer l.queued.Add(-1)
+	select {
+	case l.slots <- struct{}{}:
+		l.inFlight.Add(1)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// wait reserves and acquires a slot regardless of the queue bound. Used by
+// internal callers (cron, heartbeat, CLI) that must not be turned away.
+func (l *turnLimiter) wait(ctx context.Context) bool {
+	l.queued.Add(1)
+	return l.acquire(ctx)
+}
+
+func (l *turnLimiter) release() {
+	l.inFlight.Add(-1)
+	<-l.slots
+}
+
+func (l *turnLimiter) stats() TurnStats {
+	return TurnStats{
+		InFlight:      int(l.inFlight.Load()),
+		Queued:        int(l.queued.Load()),
+		MaxConcurrent: cap(l.slots),
+		MaxQueued:     l.maxQueued,
+	}
+}
+
+// TurnStats reports how many agent turns are running and waiting.
+func (al *AgentLoop) TurnStats() TurnStats {
+	return al.turns.stats()
+}
+
+// chatQueues runs inbound messages one at a time per chat, in arrival order,
+// while different chats proceed concurrently.
+type chatQueues struct {
+	mu      sync.Mutex
+	pending map[string][]bus.InboundMessage
+}
+
+// push queues msg for its chat and reports whether the caller must start a
+// worker to drain that chat.
+func (q *chatQueues) push(key string, msg bus.InboundMessage) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == nil {
+		q.pending = make(map[string][]bus.InboundMessage)
+	}
+	_, active := q.pending[key]
+	q.pending[key] = append(q.pending[key], msg)
+	return !active
+}
+
+// next returns the next message for a chat, or false once it is drained.
+func (q *chatQueues) next(key string) (bus.InboundMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	msgs := q.pending[key]
+	if len(msgs) == 0 {
+		delete(q.pending, key)
+		return bus.InboundMessage{}, false
+	}
+	q.pending[key] = msgs[1:]
+	return msgs[0], true
+}
+
+// dispatchInbound queues an inbound message behind the turn limiter, or
+// answers with a busy reply when too many messages are already waiting.
+func (al *AgentLoop) dispatchInbound(ctx context.Context, msg bus.InboundMessage) {
+	if !al.turns.reserve() {
+		logger.WarnCF("agent", "Turn queue full, rejecting message",
+			map[string]any{"channel": msg.Channel, "chat_id": msg.ChatID, "stats": al.turns.stats()})
+		al.bus.PublishOutbound(ctx, bus.OutboundMessage{
+			Channel: msg.Channel,
+			ChatID:  msg.ChatID,
+			Content: i18n.T(al.language(msg.Channel), i18n.AgentBusy),
+		})
+		return
+	}
+
+	key := msg.Channel + ":" + msg.ChatID
+	if !al.chats.push(key, msg) {
+		return
+	}
+	go func() {
+		for {
+			next, ok := al.chats.next(key)
+			if !ok {
+				return
+			}
+			if !al.turns.acquire(ctx) {
+				continue
+			}
+			al.handleInbound(ctx, next)
+			al.turns.release()
+		}
+	}()
+}
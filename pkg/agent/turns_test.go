@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
+
+func TestTurnLimiter_BoundsConcurrencyAndQueue(t *testing.T) {
+	l := newTurnLimiter(1, 1)
+	ctx := context.Background()
+
+	// One message runs, one waits, the third is turned away.
+	if !l.reserve() || !l.acquire(ctx) {
+		t.Fatal("expected the first turn to start")
+	}
+	if !l.reserve() {
+		t.Fatal("expected the second message to queue")
+	}
+	if l.reserve() {
+		t.Fatal("expected the queue to be full")
+	}
+
+	stats := l.stats()
+	if stats.InFlight != 1 || stats.Queued != 1 {
+		t.Fatalf("stats = %+v, want 1 in flight and 1 queued", stats)
+	}
+
+	started := make(chan struct{})
+	go func() {
+		if l.acquire(ctx) {
+			close(started)
+		}
+	}()
+	select {
+	case <-started:
+		t.Fatal("queued turn started before a slot was free")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	l.release()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("queued turn did not start after release")
+	}
+	if got := l.stats(); got.InFlight != 1 || got.Queued != 0 {
+		t.Fatalf("stats = %+v, want 1 in flight and none queued", got)
+	}
+}
+
+func TestTurnLimiter_AcquireGivesUpOnCancel(t *testing.T) {
+	l := newTurnLimiter(1, 4)
+	if !l.wait(context.Background()) {
+		t.Fatal("expected a free slot")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if l.wait(ctx) {
+		t.Fatal("expected wait to fail on a cancelled context")
+	}
+	if got := l.stats().Queued; got != 0 {
+		t.Fatalf("queued = %d, want 0 after cancel", got)
+	}
+}
+
+func TestDispatchInbound_RepliesBusyWhenQueueFull(t *testing.T) {
+	al, _, msgBus, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+
+	al.turns = newTurnLimiter(1, 0)
+	if !al.turns.wait(context.Background()) {
+		t.Fatal("expected to occupy the only slot")
+	}
+	defer al.turns.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	al.dispatchInbound(ctx, bus.InboundMessage{
+		Channel:  "telegram",
+		SenderID: "user1",
+		ChatID:   "chat1",
+		Content:  "hello",
+	})
+
+	out, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("expected a busy reply")
+	}
+	if out.Content != i18n.T("", i18n.AgentBusy) || out.ChatID != "chat1" {
+		t.Fatalf("got %+v, want busy reply to chat1", out)
+	}
+}
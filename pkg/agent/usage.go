@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// sessionUsage is the token usage accumulated by one session since the
+// gateway started.
+type sessionUsage struct {
+	Requests           int
+	PromptTokens       int
+	CachedPromptTokens int
+	CompletionTokens   int
+	Cost               float64 // USD, only for models with pricing configured
+	Priced             bool    // true if any request had pricing
+	// Models counts requests per model_list name, e.g. across auto-route
+	// tiers.
+	Models map[string]int
+}
+
+// usageTracker accumulates token usage and estimated cost per session.
+type usageTracker struct {
+	mu       sync.Mutex
+	sessions map[string]*sessionUsage
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{sessions: make(map[string]*sessionUsage)}
+}
+
+// Get returns a copy of the usage recorded for sessionKey.
+func (t *usageTracker) Get(sessionKey string) sessionUsage {
+	if t == nil {
+		return sessionUsage{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u, ok := t.sessions[sessionKey]; ok {
+		c := *u
+		c.Models = maps.Clone(u.Models)
+		return c
+	}
+	return sessionUsage{}
+}
+
+// recordUsage adds one LLM response's usage to the session, priced with the
+// model_list entry named modelName, the one that answered, when it has
+// pricing. For DeepSeek models with
+// off_peak_hint set it also notes requests made in the discounted window.
+func (al *AgentLoop) recordUsage(sessionKey, modelName string, usage *providers.UsageInfo) {
+	if usage == nil || al.usage == nil {
+		return
+	}
+
+	var cost float64
+	priced := false
+	if al.cfg != nil {
+		if mc, ok := al.cfg.LookupModelConfig(modelName); ok {
+			if mc.Pricing != nil {
+				cost = mc.Pricing.Cost(usage.PromptTokens, usage.CachedPromptTokens, usage.CompletionTokens)
+				priced = true
+			}
+			if protocol, _ := providers.ExtractProtocol(mc.Model); mc.OffPeakHint && protocol == "deepseek" &&
+				providers.InDeepSeekOffPeak(time.Now()) {
+				logger.InfoCF("agent", "DeepSeek request ran in the off-peak window; discounted pricing applies",
+					map[string]any{"model": modelName, "prompt_tokens": usage.PromptTokens})
+			}
+		}
+	}
+
+	al.usage.mu.Lock()
+	defer al.usage.mu.Unlock()
+	u, ok := al.usage.sessions[sessionKey]
+	if !ok {
+		u = &sessionUsage{}
+		al.usage.sessions[sessionKey] = u
+	}
+	u.Requests++
+	u.PromptTokens += usage.PromptTokens
+	u.CachedPromptTokens += usage.CachedPromptTokens
+	u.CompletionTokens += usage.CompletionTokens
+	u.Cost += cost
+	u.Priced = u.Priced || priced
+	if modelName != "" {
+		if u.Models == nil {
+			u.Models = make(map[string]int)
+		}
+		u.Models[modelName]++
+	}
+}
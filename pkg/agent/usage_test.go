@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+func TestRecordUsage_PricesCachedTokens(t *testing.T) {
+	al, cfg, _, _, cleanup := newTestAgentLoop(t)
+	defer cleanup()
+	cfg.Commands.Enabled = true
+	cfg.ModelList = []config.ModelConfig{{
+		ModelName: "deepseek",
+		Model:     "deepseek/deepseek-chat",
+		Pricing:   &config.ModelPricing{Input: 1, CachedInput: 0.25, Output: 2},
+	}}
+
+	usage := &providers.UsageInfo{PromptTokens: 1_000_000, CachedPromptTokens: 800_000, CompletionTokens: 500_000}
+	al.recordUsage("s", "deepseek", usage)
+	al.recordUsage("s", "unpriced", &providers.UsageInfo{PromptTokens: 10, CompletionTokens: 1})
+
+	got := al.usage.Get("s")
+	if got.Requests != 2 || got.PromptTokens != 1_000_010 || got.CachedPromptTokens != 800_000 {
+		t.Fatalf("usage = %+v", got)
+	}
+	// 200k uncached at $1 + 800k cached at $0.25 + 500k output at $2.
+	if want := 0.2 + 0.2 + 1.0; math.Abs(got.Cost-want) > 1e-9 {
+		t.Errorf("cost = %v, want %v", got.Cost, want)
+	}
+
+	resp, _ := al.handleCommand(context.Background(), commandMessage("/status"), al.registry.GetDefaultAgent(), "s")
+	if !strings.Contains(resp, "(800000 cached)") || !strings.Contains(resp, "Estimated cost: $1.4000") {
+		t.Errorf("/status response = %q", resp)
+	}
+}
@@ -0,0 +1,195 @@
+// Package audit keeps an append-only record of the tools the agent runs, so
+// what third-party skills actually did can be reviewed afterwards.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Redacted replaces the value of arguments whose name matches a redact key.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys are redacted when no keys are configured.
+var DefaultRedactKeys = []string{"password", "secret", "token", "api_key", "apikey", "authorization"}
+
+// Entry is one tool call.
+type Entry struct {
+	Time       time.Time      `json:"time"`
+	Channel    string         `json:"channel,omitempty"`
+	ChatID     string         `json:"chat_id,omitempty"`
+	Agent      string         `json:"agent,omitempty"`
+	Skill      string         `json:"skill,omitempty"`
+	Tool       string         `json:"tool"`
+	Args       map[string]any `json:"args,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+	Success    bool           `json:"success"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// Log appends entries to a JSON Lines file.
+type Log struct {
+	path       string
+	redactKeys []string
+
+	mu sync.Mutex
+}
+
+// NewLog returns the log at path. An argument is redacted when its name
+// contains one of redactKeys, case-insensitively; nil uses DefaultRedactKeys.
+func NewLog(path string, redactKeys []string) *Log {
+	if redactKeys == nil {
+		redactKeys = DefaultRedactKeys
+	}
+	keys := make([]string, 0, len(redactKeys))
+	for _, k := range redactKeys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return &Log{path: path, redactKeys: keys}
+}
+
+// Record redacts e's arguments and appends it to the log.
+func (l *Log) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Args = l.redact(e.Args)
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// redact returns a copy of args with sensitive values replaced, descending
+// into nested objects.
+func (l *Log) redact(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		switch {
+		case l.sensitive(k):
+			out[k] = Redacted
+		case isObject(v):
+			out[k] = l.redact(v.(map[string]any))
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func isObject(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
+
+func (l *Log) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range l.redactKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// skillPathRe finds a path inside a skills directory, e.g.
+// ~/.picoclaw/workspace/skills/weather/run.sh.
+var skillPathRe = regexp.MustCompile(`\bskills[/\\]([A-Za-z0-9][A-Za-z0-9_-]*)[/\\]`)
+
+// SkillFor guesses which skill a tool call belongs to from the paths in its
+// arguments, e.g. an exec of a script in a skill's directory. It returns ""
+// for calls that do not touch a skill.
+func SkillFor(args map[string]any) string {
+	for _, v := range args {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if m := skillPathRe.FindStringSubmatch(s); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Skill string
+	Tool  string
+	Since time.Time
+	Until time.Time
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Entry) bool {
+	if f.Skill != "" && !strings.EqualFold(e.Skill, f.Skill) {
+		return false
+	}
+	if f.Tool != "" && e.Tool != f.Tool {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Read returns the entries at path that match f, oldest first. A missing
+// file has no entries; lines that do not parse are skipped.
+func Read(path string, f Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if f.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
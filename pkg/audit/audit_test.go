@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogRecord_RedactsArguments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory", "audit.jsonl")
+	log := NewLog(path, []string{"Token"})
+
+	err := log.Record(Entry{
+		Tool: "web_fetch",
+		Args: map[string]any{
+			"url":       "https://example.com",
+			"api_token": "abc123",
+			"headers":   map[string]any{"X-Token": "xyz"},
+		},
+		Success: true,
+	})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	entries, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	args := entries[0].Args
+	if args["url"] != "https://example.com" {
+		t.Errorf("url = %v, want it kept", args["url"])
+	}
+	if args["api_token"] != Redacted {
+		t.Errorf("api_token = %v, want redacted", args["api_token"])
+	}
+	if headers, _ := args["headers"].(map[string]any); headers["X-Token"] != Redacted {
+		t.Errorf("nested X-Token = %v, want redacted", headers["X-Token"])
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("expected the entry to be timestamped")
+	}
+}
+
+func TestRead_Filter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(path, nil)
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, e := range []Entry{
+		{Time: day, Tool: "exec", Skill: "weather"},
+		{Time: day.Add(24 * time.Hour), Tool: "exec", Skill: "github"},
+		{Time: day.Add(48 * time.Hour), Tool: "read_file"},
+	} {
+		if err := log.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"all", Filter{}, 3},
+		{"skill", Filter{Skill: "Weather"}, 1},
+		{"tool", Filter{Tool: "exec"}, 2},
+		{"since", Filter{Since: day.Add(time.Hour)}, 2},
+		{"until", Filter{Until: day.Add(24 * time.Hour)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(path, tt.filter)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("got %d entries, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRead_MissingFile(t *testing.T) {
+	entries, err := Read(filepath.Join(t.TempDir(), "none.jsonl"), Filter{})
+	if err != nil || entries != nil {
+		t.Errorf("Read() = %v, %v; want no entries and no error", entries, err)
+	}
+}
+
+func TestSkillFor(t *testing.T) {
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"command": "bash ~/.picoclaw/workspace/skills/weather/run.sh Paris"}, "weather"},
+		{map[string]any{"path": `C:\picoclaw\skills\git-helper\SKILL.md`}, "git-helper"},
+		{map[string]any{"command": "ls skills"}, ""},
+		{map[string]any{"count": 3}, ""},
+	}
+	for _, tt := range tests {
+		if got := SkillFor(tt.args); got != tt.want {
+			t.Errorf("SkillFor(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
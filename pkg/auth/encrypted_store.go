@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/term"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	// EnvAuthEncrypt turns on the encrypted store, migrating auth.json.
+	EnvAuthEncrypt = "PICOCLAW_AUTH_ENCRYPT"
+	// EnvAuthPassphrase supplies the passphrase for the encrypted store.
+	EnvAuthPassphrase = "PICOCLAW_AUTH_PASSPHRASE"
+
+	encryptedStoreVersion = 1
+)
+
+// Argon2id parameters for new files. They are stored in each file, so they
+// can be raised later without breaking existing stores. 32 MiB keeps key
+// derivation workable on small boards.
+const (
+	argonTime    = 3
+	argonMemory  = 32 * 1024 // KiB
+	argonThreads = 2
+	argonKeyLen  = 32 // AES-256
+	argonSaltLen = 16
+)
+
+// ErrWrongPassphrase is returned when the encrypted store cannot be
+// decrypted, which means a wrong passphrase or a damaged file.
+var ErrWrongPassphrase = errors.New("cannot decrypt auth store: wrong passphrase or corrupt file")
+
+// encryptedFile is the on-disk format of the encrypted store.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Time       uint32 `json:"time"`
+	Memory     uint32 `json:"memory"`
+	Threads    uint8  `json:"threads"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptedFileStore keeps the auth store encrypted with AES-256-GCM, using a
+// key derived from a passphrase with Argon2id. It is meant for headless
+// machines without an OS keychain, where auth.json would be plaintext.
+type EncryptedFileStore struct {
+	path          string
+	plaintextPath string
+	passphrase    func() (string, error)
+}
+
+// NewEncryptedFileStore returns a store encrypted at path. If path does not
+// exist yet, the plaintext store at plaintextPath (if any) is migrated into
+// it on first use and then removed.
+func NewEncryptedFileStore(path, plaintextPath string, passphrase func() (string, error)) *EncryptedFileStore {
+	return &EncryptedFileStore{path: path, plaintextPath: plaintextPath, passphrase: passphrase}
+}
+
+func encryptedAuthFilePath() string {
+	return filepath.Join(filepath.Dir(authFilePath()), "auth.json.enc")
+}
+
+// encryptionEnabled reports whether credentials go to the encrypted store:
+// either it already exists or PICOCLAW_AUTH_ENCRYPT asks for it.
+func encryptionEnabled() bool {
+	if _, err := os.Stat(encryptedAuthFilePath()); err == nil {
+		return true
+	}
+	v := strings.ToLower(os.Getenv(EnvAuthEncrypt))
+	return v == "true" || v == "1" || v == "yes"
+}
+
+func defaultEncryptedStore() *EncryptedFileStore {
+	return NewEncryptedFileStore(encryptedAuthFilePath(), authFilePath(), cachedPassphrase)
+}
+
+// Load decrypts the store, migrating the plaintext store first if the
+// encrypted one does not exist yet.
+func (s *EncryptedFileStore) Load() (*AuthStore, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s.migrate()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("encrypted auth store %s is corrupt: %w", s.path, err)
+	}
+	if file.Version != encryptedStoreVersion || file.KDF != "argon2id" {
+		return nil, fmt.Errorf("unsupported encrypted auth store (version %d, kdf %q)", file.Version, file.KDF)
+	}
+
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	key := deriveKey(passphrase, file.Salt, file.Time, file.Memory, file.Threads)
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	var store AuthStore
+	if err := json.Unmarshal(plaintext, &store); err != nil {
+		return nil, fmt.Errorf("decrypted auth store is corrupt: %w", err)
+	}
+	if store.Credentials == nil {
+		store.Credentials = make(map[string]*AuthCredential)
+	}
+	return &store, nil
+}
+
+// Save encrypts store with a fresh salt and nonce.
+func (s *EncryptedFileStore) Save(store *AuthStore) error {
+	plaintext, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		Version: encryptedStoreVersion,
+		KDF:     "argon2id",
+		Time:    argonTime,
+		Memory:  argonMemory,
+		Threads: argonThreads,
+		Salt:    make([]byte, argonSaltLen),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(deriveKey(passphrase, file.Salt, file.Time, file.Memory, file.Threads))
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(s.path, data, 0o600)
+}
+
+// migrate moves the plaintext store into the encrypted one. Without a
+// plaintext store it returns an empty store and writes nothing.
+func (s *EncryptedFileStore) migrate() (*AuthStore, error) {
+	if s.plaintextPath == "" {
+		return &AuthStore{Credentials: make(map[string]*AuthCredential)}, nil
+	}
+	if _, err := os.Stat(s.plaintextPath); os.IsNotExist(err) {
+		return &AuthStore{Credentials: make(map[string]*AuthCredential)}, nil
+	}
+
+	store, err := loadPlaintextStore(s.plaintextPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Save(store); err != nil {
+		return nil, fmt.Errorf("failed to write encrypted auth store: %w", err)
+	}
+	if err := os.Remove(s.plaintextPath); err != nil {
+		return nil, fmt.Errorf("encrypted auth store written but plaintext %s could not be removed: %w",
+			s.plaintextPath, err)
+	}
+	logger.InfoCF("auth", "Migrated auth store to encrypted storage",
+		map[string]any{"from": s.plaintextPath, "to": s.path})
+	return store, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Argon2id is deliberately slow and memory-hungry, so keys are cached per
+// salt for the life of the process.
+var (
+	keyCacheMu sync.Mutex
+	keyCache   = make(map[string][]byte)
+)
+
+func deriveKey(passphrase string, salt []byte, time, memory uint32, threads uint8) []byte {
+	cacheKey := fmt.Sprintf("%x:%d:%d:%d:%s", salt, time, memory, threads, passphrase)
+	keyCacheMu.Lock()
+	defer keyCacheMu.Unlock()
+	if key, ok := keyCache[cacheKey]; ok {
+		return key
+	}
+	key := argon2.IDKey([]byte(passphrase), salt, time, memory, threads, argonKeyLen)
+	keyCache[cacheKey] = key
+	return key
+}
+
+var (
+	passphraseMu sync.Mutex
+	passphrase   string
+)
+
+// cachedPassphrase returns PICOCLAW_AUTH_PASSPHRASE, or prompts for the
+// passphrase once per process when stdin is a terminal.
+func cachedPassphrase() (string, error) {
+	if p := os.Getenv(EnvAuthPassphrase); p != "" {
+		return p, nil
+	}
+
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	if passphrase != "" {
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("auth store is encrypted: set %s", EnvAuthPassphrase)
+	}
+	fmt.Fprint(os.Stderr, "Auth store passphrase: ")
+	input, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	if len(input) == 0 {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	passphrase = string(input)
+	return passphrase, nil
+}
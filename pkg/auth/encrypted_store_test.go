@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fixedPassphrase(p string) func() (string, error) {
+	return func() (string, error) { return p, nil }
+}
+
+func TestEncryptedFileStore_Roundtrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json.enc")
+	store := NewEncryptedFileStore(path, "", fixedPassphrase("correct horse"))
+
+	in := &AuthStore{Credentials: map[string]*AuthCredential{
+		"anthropic": {AccessToken: "sk-secret-token", Provider: "anthropic", AuthMethod: "token"},
+	}}
+	if err := store.Save(in); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("sk-secret-token")) {
+		t.Fatal("token stored in plaintext")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	out, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := out.Credentials["anthropic"]; got == nil || got.AccessToken != "sk-secret-token" {
+		t.Errorf("loaded credential = %+v", got)
+	}
+
+	wrong := NewEncryptedFileStore(path, "", fixedPassphrase("wrong"))
+	if _, err := wrong.Load(); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Load() with wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestEncryptedStore_MigratesPlaintext(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvAuthPassphrase, "pass")
+
+	cred := &AuthCredential{AccessToken: "plain-token", Provider: "openai", AuthMethod: "oauth"}
+	if err := SetCredential("openai", cred); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(authFilePath()); err != nil {
+		t.Fatalf("plaintext store not written: %v", err)
+	}
+
+	t.Setenv(EnvAuthEncrypt, "true")
+	got, err := GetCredential("openai")
+	if err != nil {
+		t.Fatalf("GetCredential() error: %v", err)
+	}
+	if got == nil || got.AccessToken != "plain-token" {
+		t.Fatalf("migrated credential = %+v", got)
+	}
+	if _, err := os.Stat(authFilePath()); !os.IsNotExist(err) {
+		t.Error("plaintext auth.json should be removed after migration")
+	}
+
+	// Once the encrypted store exists it is used even without the env flag.
+	t.Setenv(EnvAuthEncrypt, "")
+	if err := SetCredential("anthropic", &AuthCredential{AccessToken: "tok2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(authFilePath()); !os.IsNotExist(err) {
+		t.Error("credentials must not be written back to plaintext")
+	}
+	got, _ = GetCredential("anthropic")
+	if got == nil || got.AccessToken != "tok2" {
+		t.Errorf("credential = %+v", got)
+	}
+}
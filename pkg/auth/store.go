@@ -2,13 +2,21 @@ package auth
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
+// corruptSuffix is appended (with a timestamp) to an unreadable auth.json
+// when it is moved aside.
+const corruptSuffix = ".corrupt-"
+
 type AuthCredential struct {
 	AccessToken  string    `json:"access_token"`
 	RefreshToken string    `json:"refresh_token,omitempty"`
@@ -18,10 +26,19 @@ type AuthCredential struct {
 	AuthMethod   string    `json:"auth_method"`
 	Email        string    `json:"email,omitempty"`
 	ProjectID    string    `json:"project_id,omitempty"`
+	// TOTPSecret is the base32 MFA seed, used to answer OTP challenges
+	// without prompting.
+	TOTPSecret string `json:"totp_secret,omitempty"`
 }
 
 type AuthStore struct {
+	// Credentials is keyed by provider, or by "provider:account" for named
+	// accounts (see CredentialKey).
 	Credentials map[string]*AuthCredential `json:"credentials"`
+
+	// RecoveredFrom is set when LoadStore found auth.json corrupt and
+	// replaced it with an empty store; it holds the backup's path.
+	RecoveredFrom string `json:"-"`
 }
 
 func (c *AuthCredential) IsExpired() bool {
@@ -43,8 +60,20 @@ func authFilePath() string {
 	return filepath.Join(home, ".picoclaw", "auth.json")
 }
 
+// LoadStore reads auth.json. A file that is not valid JSON is moved aside to
+// auth.json.corrupt-<timestamp> and an empty store is returned, so the user
+// can log in again instead of every auth-dependent command failing.
+//
+// When encryption is enabled (see EncryptedFileStore) the encrypted store is
+// used instead.
 func LoadStore() (*AuthStore, error) {
-	path := authFilePath()
+	if encryptionEnabled() {
+		return defaultEncryptedStore().Load()
+	}
+	return loadPlaintextStore(authFilePath())
+}
+
+func loadPlaintextStore(path string) (*AuthStore, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -55,7 +84,13 @@ func LoadStore() (*AuthStore, error) {
 
 	var store AuthStore
 	if err := json.Unmarshal(data, &store); err != nil {
-		return nil, err
+		backup := path + corruptSuffix + time.Now().Format("20060102-150405")
+		if renameErr := os.Rename(path, backup); renameErr != nil {
+			return nil, fmt.Errorf("auth store is corrupt (%v) and could not be backed up: %w", err, renameErr)
+		}
+		logger.WarnCF("auth", "Auth store was corrupt; moved aside and starting empty",
+			map[string]any{"backup": backup, "error": err.Error()})
+		return &AuthStore{Credentials: make(map[string]*AuthCredential), RecoveredFrom: backup}, nil
 	}
 	if store.Credentials == nil {
 		store.Credentials = make(map[string]*AuthCredential)
@@ -63,7 +98,19 @@ func LoadStore() (*AuthStore, error) {
 	return &store, nil
 }
 
+// CorruptBackups returns the paths of auth.json files previously moved aside
+// by LoadStore, newest first.
+func CorruptBackups() []string {
+	matches, _ := filepath.Glob(authFilePath() + corruptSuffix + "*")
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+	return matches
+}
+
 func SaveStore(store *AuthStore) error {
+	if encryptionEnabled() {
+		return defaultEncryptedStore().Save(store)
+	}
+
 	path := authFilePath()
 	data, err := json.MarshalIndent(store, "", "  ")
 	if err != nil {
@@ -74,6 +121,25 @@ func SaveStore(store *AuthStore) error {
 	return fileutil.WriteFileAtomic(path, data, 0o600)
 }
 
+// CredentialKey returns the store key of a provider's credential. Named
+// accounts are namespaced as "provider:account" so one provider can hold
+// several logins (e.g. "openai:work"); an empty account is the provider's
+// default credential.
+func CredentialKey(provider, account string) string {
+	if account == "" {
+		return provider
+	}
+	return provider + ":" + account
+}
+
+// SplitCredentialKey is the inverse of CredentialKey.
+func SplitCredentialKey(key string) (provider, account string) {
+	provider, account, _ = strings.Cut(key, ":")
+	return provider, account
+}
+
+// GetCredential returns the credential stored under provider, which may be
+// namespaced as "provider:account". It returns nil if there is none.
 func GetCredential(provider string) (*AuthCredential, error) {
 	store, err := LoadStore()
 	if err != nil {
@@ -105,9 +171,10 @@ func DeleteCredential(provider string) error {
 }
 
 func DeleteAllCredentials() error {
-	path := authFilePath()
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return err
+	for _, path := range []string{authFilePath(), encryptedAuthFilePath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 	return nil
 }
@@ -146,6 +146,36 @@ func TestStoreMultiProvider(t *testing.T) {
 	}
 }
 
+func TestStoreNamespacedAccounts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	personal := &AuthCredential{AccessToken: "personal-token", Provider: "openai", AuthMethod: "oauth"}
+	work := &AuthCredential{AccessToken: "work-token", Provider: "openai", AuthMethod: "oauth"}
+
+	if err := SetCredential("openai", personal); err != nil {
+		t.Fatalf("SetCredential(openai) error: %v", err)
+	}
+	if err := SetCredential(CredentialKey("openai", "work"), work); err != nil {
+		t.Fatalf("SetCredential(openai:work) error: %v", err)
+	}
+
+	loaded, err := GetCredential("openai:work")
+	if err != nil || loaded == nil || loaded.AccessToken != "work-token" {
+		t.Fatalf("GetCredential(openai:work) = %v, %v", loaded, err)
+	}
+	loaded, err = GetCredential("openai")
+	if err != nil || loaded == nil || loaded.AccessToken != "personal-token" {
+		t.Fatalf("GetCredential(openai) = %v, %v", loaded, err)
+	}
+
+	if provider, account := SplitCredentialKey("openai:work"); provider != "openai" || account != "work" {
+		t.Errorf("SplitCredentialKey(openai:work) = %q, %q", provider, account)
+	}
+	if provider, account := SplitCredentialKey("anthropic"); provider != "anthropic" || account != "" {
+		t.Errorf("SplitCredentialKey(anthropic) = %q, %q", provider, account)
+	}
+}
+
 func TestDeleteCredential(t *testing.T) {
 	tmpDir := t.TempDir()
 	origHome := os.Getenv("HOME")
@@ -187,3 +217,41 @@ func TestLoadStoreEmpty(t *testing.T) {
 		t.Errorf("expected empty credentials, got %d", len(store.Credentials))
 	}
 }
+
+func TestLoadStoreRecoversFromCorruptFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	path := filepath.Join(tmpDir, ".picoclaw", "auth.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"credentials": {"openai": `), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := LoadStore()
+	if err != nil {
+		t.Fatalf("LoadStore() error: %v", err)
+	}
+	if len(store.Credentials) != 0 {
+		t.Errorf("expected empty store, got %d credentials", len(store.Credentials))
+	}
+	if store.RecoveredFrom == "" {
+		t.Fatal("RecoveredFrom not set")
+	}
+	if data, err := os.ReadFile(store.RecoveredFrom); err != nil || string(data) != `{"credentials": {"openai": ` {
+		t.Errorf("backup does not hold the original content: %q, %v", data, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("corrupt auth.json should have been moved aside")
+	}
+	if backups := CorruptBackups(); len(backups) != 1 || backups[0] != store.RecoveredFrom {
+		t.Errorf("CorruptBackups() = %v, want [%s]", backups, store.RecoveredFrom)
+	}
+
+	// The user can log in again afterwards.
+	if err := SetCredential("openai", &AuthCredential{AccessToken: "new", Provider: "openai"}); err != nil {
+		t.Fatalf("SetCredential() after recovery: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// OTPHeader carries a server's one-time-code challenge ("required") on a 401
+// response, and the code itself on the retried request.
+const OTPHeader = "X-OTP"
+
+// totpOpts are the RFC 6238 defaults authenticator apps use.
+var totpOpts = totp.ValidateOpts{
+	Period:    30,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// GenerateTOTP returns the current RFC 6238 code (SHA-1, 30s, 6 digits) for
+// a base32 secret as shown by authenticator apps. Spaces, dashes, case and
+// missing padding in the secret are tolerated.
+func GenerateTOTP(secret string) (string, error) {
+	return generateTOTPAt(secret, time.Now())
+}
+
+func generateTOTPAt(secret string, t time.Time) (string, error) {
+	cleaned := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(secret))
+	cleaned = strings.TrimRight(cleaned, "=")
+	if cleaned == "" {
+		return "", fmt.Errorf("TOTP secret is empty")
+	}
+	code, err := totp.GenerateCodeCustom(cleaned, t, totpOpts)
+	if err != nil {
+		return "", fmt.Errorf("TOTP secret is not valid base32: %w", err)
+	}
+	return code, nil
+}
+
+// IsOTPChallenge reports whether resp asks for a one-time code.
+func IsOTPChallenge(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusUnauthorized &&
+		strings.HasPrefix(strings.ToLower(resp.Header.Get(OTPHeader)), "required")
+}
+
+// DoWithOTP sends the request built by newReq and, if the server answers
+// with an OTP challenge, retries once with a one-time code in OTPHeader. The
+// code is generated from secret when one is stored, otherwise prompt asks
+// the user for it. Without either the challenge response is returned as is.
+func DoWithOTP(
+	client *http.Client,
+	newReq func() (*http.Request, error),
+	secret string,
+	prompt func() (string, error),
+) (*http.Response, error) {
+	req, err := newReq()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil || !IsOTPChallenge(resp) {
+		return resp, err
+	}
+
+	var code string
+	switch {
+	case secret != "":
+		code, err = GenerateTOTP(secret)
+	case prompt != nil:
+		code, err = prompt()
+	default:
+		return resp, nil
+	}
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("getting one-time code: %w", err)
+	}
+
+	req, err = newReq()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(OTPHeader, strings.TrimSpace(code))
+	return client.Do(req)
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the RFC 6238 SHA-1 test key "12345678901234567890".
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTP_RFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := generateTOTPAt(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("generateTOTPAt(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("generateTOTPAt(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateTOTP_NormalizesSecret(t *testing.T) {
+	at := time.Unix(59, 0)
+	got, err := generateTOTPAt("gezd gnbv-gy3t qojq gezd gnbv gy3t qojq", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "287082" {
+		t.Errorf("code = %s, want 287082", got)
+	}
+
+	for _, bad := range []string{"", "not base32!"} {
+		if _, err := GenerateTOTP(bad); err == nil {
+			t.Errorf("GenerateTOTP(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestDoWithOTP_RetriesWithCode(t *testing.T) {
+	var codes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := r.Header.Get(OTPHeader)
+		codes = append(codes, code)
+		if code != "123456" {
+			w.Header().Set(OTPHeader, "required; totp")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	newReq := func() (*http.Request, error) { return http.NewRequest(http.MethodGet, server.URL, nil) }
+	prompted := 0
+	resp, err := DoWithOTP(server.Client(), newReq, "", func() (string, error) {
+		prompted++
+		return "123456\n", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || prompted != 1 {
+		t.Fatalf("status = %d, prompted %d times", resp.StatusCode, prompted)
+	}
+	if len(codes) != 2 || codes[0] != "" {
+		t.Errorf("codes sent = %q, want no code then 123456", codes)
+	}
+
+	// With a stored secret the code is generated instead of prompted.
+	codes = nil
+	resp, err = DoWithOTP(server.Client(), newReq, rfc6238Secret, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(codes) != 2 || len(codes[1]) != 6 {
+		t.Errorf("codes sent = %q, want a generated 6-digit code on retry", codes)
+	}
+}
+
+func TestIsOTPChallenge(t *testing.T) {
+	plain := &http.Response{StatusCode: http.StatusUnauthorized, Header: http.Header{}}
+	if IsOTPChallenge(plain) {
+		t.Error("401 without OTP header should not be a challenge")
+	}
+	challenge := &http.Response{StatusCode: http.StatusUnauthorized, Header: http.Header{}}
+	challenge.Header.Set(OTPHeader, "Required")
+	if !IsOTPChallenge(challenge) {
+		t.Error("401 with OTP header should be a challenge")
+	}
+}
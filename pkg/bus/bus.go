@@ -3,6 +3,7 @@ package bus
 import (
 	"context"
 	"errors"
+	"sync"
 	"sync/atomic"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
@@ -19,6 +20,11 @@ type MessageBus struct {
 	outboundMedia chan OutboundMediaMessage
 	done          chan struct{}
 	closed        atomic.Bool
+	middleware    atomic.Pointer[MiddlewareChain]
+
+	pipelineOnce sync.Once
+	inboundPipe  atomic.Pointer[chatPipeline[InboundMessage]]
+	outboundPipe atomic.Pointer[chatPipeline[OutboundMessage]]
 }
 
 func NewMessageBus() *MessageBus {
@@ -47,9 +53,45 @@ func (mb *MessageBus) PublishInbound(ctx context.Context, msg InboundMessage) er
 	}
 }
 
+// SetMiddleware installs the chain that consumed messages pass through.
+// Messages a middleware drops are never returned to consumers. The chain
+// runs per chat, off the consumers' path, so a slow middleware delays only
+// the chat it is working on. Call it before consumers start.
+func (mb *MessageBus) SetMiddleware(chain *MiddlewareChain) {
+	mb.middleware.Store(chain)
+	if chain.Len() > 0 {
+		mb.pipelineOnce.Do(mb.startPipelines)
+	}
+}
+
+// startPipelines moves published messages through the middleware chain
+// until the bus closes.
+func (mb *MessageBus) startPipelines() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-mb.done
+		cancel()
+	}()
+
+	in := newChatPipeline(mb.done, func(msg InboundMessage) (InboundMessage, bool) {
+		return mb.middleware.Load().Inbound(ctx, msg)
+	})
+	out := newChatPipeline(mb.done, func(msg OutboundMessage) (OutboundMessage, bool) {
+		return mb.middleware.Load().Outbound(ctx, msg)
+	})
+	mb.inboundPipe.Store(in)
+	mb.outboundPipe.Store(out)
+	go in.run(mb.inbound, func(msg InboundMessage) string { return msg.Channel + ":" + msg.ChatID })
+	go out.run(mb.outbound, func(msg OutboundMessage) string { return msg.Channel + ":" + msg.ChatID })
+}
+
 func (mb *MessageBus) ConsumeInbound(ctx context.Context) (InboundMessage, bool) {
+	src := mb.inbound
+	if p := mb.inboundPipe.Load(); p != nil {
+		src = p.out
+	}
 	select {
-	case msg, ok := <-mb.inbound:
+	case msg, ok := <-src:
 		return msg, ok
 	case <-mb.done:
 		return InboundMessage{}, false
@@ -76,8 +118,12 @@ func (mb *MessageBus) PublishOutbound(ctx context.Context, msg OutboundMessage)
 }
 
 func (mb *MessageBus) SubscribeOutbound(ctx context.Context) (OutboundMessage, bool) {
+	src := mb.outbound
+	if p := mb.outboundPipe.Load(); p != nil {
+		src = p.out
+	}
 	select {
-	case msg, ok := <-mb.outbound:
+	case msg, ok := <-src:
 		return msg, ok
 	case <-mb.done:
 		return OutboundMessage{}, false
@@ -114,6 +160,18 @@ func (mb *MessageBus) SubscribeOutboundMedia(ctx context.Context) (OutboundMedia
 	}
 }
 
+// Full reports whether the inbound or outbound buffer is full, which means
+// its consumer has stopped keeping up or is stuck.
+func (mb *MessageBus) Full() bool {
+	if p := mb.inboundPipe.Load(); p != nil && len(p.out) == cap(p.out) {
+		return true
+	}
+	if p := mb.outboundPipe.Load(); p != nil && len(p.out) == cap(p.out) {
+		return true
+	}
+	return len(mb.inbound) == cap(mb.inbound) || len(mb.outbound) == cap(mb.outbound)
+}
+
 func (mb *MessageBus) Close() {
 	if mb.closed.CompareAndSwap(false, true) {
 		close(mb.done)
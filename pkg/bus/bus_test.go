@@ -213,6 +213,23 @@ func TestPublishInbound_FullBuffer(t *testing.T) {
 	}
 }
 
+func TestFull(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+
+	ctx := context.Background()
+	for range defaultBusBufferSize - 1 {
+		mb.PublishOutbound(ctx, OutboundMessage{Content: "fill"})
+	}
+	if mb.Full() {
+		t.Fatal("Full() = true with room left in the buffers")
+	}
+	mb.PublishOutbound(ctx, OutboundMessage{Content: "fill"})
+	if !mb.Full() {
+		t.Fatal("Full() = false with the outbound buffer full")
+	}
+}
+
 func TestCloseIdempotent(t *testing.T) {
 	mb := NewMessageBus()
 
@@ -227,3 +244,21 @@ func TestCloseIdempotent(t *testing.T) {
 		t.Fatalf("expected ErrBusClosed after multiple closes, got %v", err)
 	}
 }
+
+func TestAwaitDelivery(t *testing.T) {
+	status, err := AwaitDelivery(context.Background(), time.Second, func(report func(DeliveryStatus)) error {
+		go func() {
+			report(DeliveryStatus{State: DeliveryFailed, Error: "blocked"})
+			report(DeliveryStatus{State: DeliveryAccepted}) // later chunks are ignored
+		}()
+		return nil
+	})
+	if err != nil || status.State != DeliveryFailed || status.Error != "blocked" {
+		t.Errorf("AwaitDelivery() = %+v, %v, want the first report", status, err)
+	}
+
+	_, err = AwaitDelivery(context.Background(), 10*time.Millisecond, func(func(DeliveryStatus)) error { return nil })
+	if err == nil {
+		t.Error("AwaitDelivery() without a report returned no error, want timeout")
+	}
+}
@@ -0,0 +1,89 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Card is a message with buttons the user can tap to answer, e.g. a choice
+// between three restaurants. Channels that render cards send a button's
+// Value back as the user's message; the others show the buttons as
+// numbered options.
+type Card struct {
+	Title   string       `json:"title,omitempty"`
+	Text    string       `json:"text,omitempty"`
+	Buttons []CardButton `json:"buttons"`
+}
+
+// CardButton is one choice on a card.
+type CardButton struct {
+	Label string `json:"label"`
+	Value string `json:"value,omitempty"` // sent back when tapped; Label when empty
+}
+
+// ReplyValue returns what the user answers by tapping b.
+func (b CardButton) ReplyValue() string {
+	if b.Value != "" {
+		return b.Value
+	}
+	return b.Label
+}
+
+// cardBlockRe matches a card written into message text as a fenced block:
+//
+//	```card
+//	{"title": "Dinner?", "buttons": [{"label": "Sushi"}, {"label": "Pizza"}]}
+//	```
+var cardBlockRe = regexp.MustCompile("(?s)```card[ \\t]*\\r?\\n(.*?)\\r?\\n?```")
+
+// ExtractCard removes the first card block from content and returns the
+// remaining text and the card. Content without a valid card is returned
+// unchanged with a nil card.
+func ExtractCard(content string) (string, *Card) {
+	loc := cardBlockRe.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return content, nil
+	}
+	var card Card
+	if err := json.Unmarshal([]byte(content[loc[2]:loc[3]]), &card); err != nil || card.Validate() != nil {
+		return content, nil
+	}
+	text := strings.TrimSpace(content[:loc[0]] + content[loc[1]:])
+	return text, &card
+}
+
+// Validate reports whether the card can be shown.
+func (c *Card) Validate() error {
+	if len(c.Buttons) == 0 {
+		return fmt.Errorf("card has no buttons")
+	}
+	for i, b := range c.Buttons {
+		if strings.TrimSpace(b.Label) == "" {
+			return fmt.Errorf("card button %d has no label", i+1)
+		}
+	}
+	return nil
+}
+
+// PlainText renders the card as numbered options for channels without
+// buttons.
+func (c *Card) PlainText() string {
+	var sb strings.Builder
+	if c.Title != "" {
+		sb.WriteString(c.Title)
+		sb.WriteString("\n")
+	}
+	if c.Text != "" {
+		sb.WriteString(c.Text)
+		sb.WriteString("\n")
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n")
+	}
+	for i, b := range c.Buttons {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, b.Label)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
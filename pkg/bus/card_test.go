@@ -0,0 +1,44 @@
+package bus
+
+import "testing"
+
+func TestExtractCard(t *testing.T) {
+	content := "Pick one:\n```card\n" +
+		`{"title": "Size", "buttons": [{"label": "Small", "value": "s"}, {"label": "Large"}]}` +
+		"\n```\nThanks!"
+
+	text, card := ExtractCard(content)
+	if card == nil {
+		t.Fatal("expected a card")
+	}
+	if text != "Pick one:\n\nThanks!" {
+		t.Errorf("text = %q", text)
+	}
+	if card.Title != "Size" || len(card.Buttons) != 2 {
+		t.Fatalf("card = %+v", card)
+	}
+	if card.Buttons[0].ReplyValue() != "s" || card.Buttons[1].ReplyValue() != "Large" {
+		t.Errorf("reply values = %q, %q", card.Buttons[0].ReplyValue(), card.Buttons[1].ReplyValue())
+	}
+}
+
+func TestExtractCard_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"no card here",
+		"```card\nnot json\n```",
+		"```card\n{\"title\": \"no buttons\"}\n```",
+		"```card\n{\"buttons\": [{\"label\": \" \"}]}\n```",
+	} {
+		text, card := ExtractCard(content)
+		if card != nil || text != content {
+			t.Errorf("ExtractCard(%q) = %q, %+v; want content unchanged and no card", content, text, card)
+		}
+	}
+}
+
+func TestCardPlainText(t *testing.T) {
+	card := Card{Text: "Continue?", Buttons: []CardButton{{Label: "Yes"}, {Label: "No"}}}
+	if got, want := card.PlainText(), "Continue?\n\n1. Yes\n2. No"; got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+}
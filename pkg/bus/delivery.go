@@ -0,0 +1,40 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AwaitDelivery publishes a message through publish, handing it a report
+// function to set as OnDelivery, and waits for the first delivery status.
+// Long texts are split into chunks that each report; the first one decides.
+// An error is returned if publishing fails, ctx ends or timeout elapses.
+func AwaitDelivery(
+	ctx context.Context,
+	timeout time.Duration,
+	publish func(report func(DeliveryStatus)) error,
+) (DeliveryStatus, error) {
+	statuses := make(chan DeliveryStatus, 1)
+	report := func(s DeliveryStatus) {
+		select {
+		case statuses <- s:
+		default:
+		}
+	}
+	if err := publish(report); err != nil {
+		return DeliveryStatus{}, fmt.Errorf("failed to queue message: %w", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case s := <-statuses:
+		return s, nil
+	case <-timer.C:
+		return DeliveryStatus{}, fmt.Errorf("timed out waiting for delivery after %s", timeout)
+	case <-ctx.Done():
+		return DeliveryStatus{}, ctx.Err()
+	}
+}
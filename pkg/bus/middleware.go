@@ -0,0 +1,134 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// Decision tells the bus what to do with a message after a middleware ran.
+type Decision int
+
+const (
+	Pass Decision = iota // deliver the (possibly modified) message
+	Drop                 // discard the message
+)
+
+// Middleware inspects or rewrites messages as they leave the bus. Inbound
+// messages pass through it before the agent sees them, outbound ones before
+// a channel sends them.
+type Middleware interface {
+	Name() string
+	OnInbound(ctx context.Context, msg InboundMessage) (InboundMessage, Decision, error)
+	OnOutbound(ctx context.Context, msg OutboundMessage) (OutboundMessage, Decision, error)
+}
+
+type middlewareEntry struct {
+	mw         Middleware
+	failClosed bool
+}
+
+// MiddlewareChain runs middlewares in order. A middleware that fails is
+// logged and skipped, leaving the message as it was, unless it was added
+// with failClosed, in which case the message is dropped.
+type MiddlewareChain struct {
+	entries []middlewareEntry
+}
+
+// Add appends mw to the chain.
+func (c *MiddlewareChain) Add(mw Middleware, failClosed bool) {
+	c.entries = append(c.entries, middlewareEntry{mw: mw, failClosed: failClosed})
+}
+
+// Len returns the number of middlewares in the chain.
+func (c *MiddlewareChain) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.entries)
+}
+
+// Inbound runs msg through the chain. It returns false if the message was
+// dropped.
+func (c *MiddlewareChain) Inbound(ctx context.Context, msg InboundMessage) (InboundMessage, bool) {
+	if c.Len() == 0 {
+		return msg, true
+	}
+	for _, e := range c.entries {
+		out, decision, err := e.mw.OnInbound(ctx, msg)
+		if err != nil {
+			if !c.handleError(e, "inbound", msg.Channel, msg.ChatID, err) {
+				return msg, false
+			}
+			continue
+		}
+		if decision == Drop {
+			logger.InfoCF("bus", "Inbound message dropped by middleware",
+				map[string]any{"middleware": e.mw.Name(), "channel": msg.Channel, "chat_id": msg.ChatID})
+			return msg, false
+		}
+		msg = out
+	}
+	return msg, true
+}
+
+// Outbound runs msg through the chain. It returns false if the message was
+// dropped, after reporting the drop to the message's delivery callback.
+// Partial stream chunks pass untouched: the chain sees the final reply
+// whole, instead of translating or filtering every fragment of it.
+func (c *MiddlewareChain) Outbound(ctx context.Context, msg OutboundMessage) (OutboundMessage, bool) {
+	if c.Len() == 0 || msg.Partial {
+		return msg, true
+	}
+	for _, e := range c.entries {
+		out, decision, err := e.mw.OnOutbound(ctx, msg)
+		if err != nil {
+			if !c.handleError(e, "outbound", msg.Channel, msg.ChatID, err) {
+				reportDropped(msg, e.mw.Name())
+				return msg, false
+			}
+			continue
+		}
+		if decision == Drop {
+			logger.InfoCF("bus", "Outbound message dropped by middleware",
+				map[string]any{"middleware": e.mw.Name(), "channel": msg.Channel, "chat_id": msg.ChatID})
+			reportDropped(msg, e.mw.Name())
+			return msg, false
+		}
+		// Middlewares see the message as JSON at most; keep the callback.
+		out.OnDelivery = msg.OnDelivery
+		msg = out
+	}
+	return msg, true
+}
+
+// handleError logs a middleware failure and reports whether the message
+// should continue down the chain.
+func (c *MiddlewareChain) handleError(e middlewareEntry, direction, channel, chatID string, err error) bool {
+	fields := map[string]any{
+		"middleware": e.mw.Name(),
+		"direction":  direction,
+		"channel":    channel,
+		"chat_id":    chatID,
+		"error":      err.Error(),
+	}
+	if e.failClosed {
+		logger.WarnCF("bus", "Middleware failed; dropping message", fields)
+		return false
+	}
+	logger.WarnCF("bus", "Middleware failed; passing message through unchanged", fields)
+	return true
+}
+
+func reportDropped(msg OutboundMessage, middleware string) {
+	if msg.OnDelivery == nil {
+		return
+	}
+	msg.OnDelivery(DeliveryStatus{
+		State:   DeliveryFailed,
+		Channel: msg.Channel,
+		ChatID:  msg.ChatID,
+		Error:   fmt.Sprintf("dropped by middleware %s", middleware),
+	})
+}
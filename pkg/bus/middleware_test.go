@@ -0,0 +1,138 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type funcMiddleware struct {
+	name     string
+	inbound  func(InboundMessage) (InboundMessage, Decision, error)
+	outbound func(OutboundMessage) (OutboundMessage, Decision, error)
+}
+
+func (m *funcMiddleware) Name() string { return m.name }
+
+func (m *funcMiddleware) OnInbound(_ context.Context, msg InboundMessage) (InboundMessage, Decision, error) {
+	if m.inbound == nil {
+		return msg, Pass, nil
+	}
+	return m.inbound(msg)
+}
+
+func (m *funcMiddleware) OnOutbound(_ context.Context, msg OutboundMessage) (OutboundMessage, Decision, error) {
+	if m.outbound == nil {
+		return msg, Pass, nil
+	}
+	return m.outbound(msg)
+}
+
+func TestMiddleware_RewritesAndDropsInbound(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	ctx := context.Background()
+
+	chain := &MiddlewareChain{}
+	chain.Add(&funcMiddleware{name: "upper", inbound: func(m InboundMessage) (InboundMessage, Decision, error) {
+		m.Content = strings.ToUpper(m.Content)
+		return m, Pass, nil
+	}}, false)
+	chain.Add(&funcMiddleware{name: "drop-spam", inbound: func(m InboundMessage) (InboundMessage, Decision, error) {
+		if m.Content == "SPAM" {
+			return m, Drop, nil
+		}
+		return m, Pass, nil
+	}}, false)
+	mb.SetMiddleware(chain)
+
+	mb.PublishInbound(ctx, InboundMessage{Content: "spam"})
+	mb.PublishInbound(ctx, InboundMessage{Content: "hello"})
+
+	got, ok := mb.ConsumeInbound(ctx)
+	if !ok || got.Content != "HELLO" {
+		t.Fatalf("ConsumeInbound = %q, %v; want HELLO after the dropped message", got.Content, ok)
+	}
+}
+
+func TestMiddleware_FailOpenAndFailClosed(t *testing.T) {
+	failing := &funcMiddleware{name: "broken", outbound: func(m OutboundMessage) (OutboundMessage, Decision, error) {
+		m.Content = "garbled"
+		return m, Pass, errors.New("boom")
+	}}
+
+	open := &MiddlewareChain{}
+	open.Add(failing, false)
+	msg, ok := open.Outbound(context.Background(), OutboundMessage{Content: "hi"})
+	if !ok || msg.Content != "hi" {
+		t.Errorf("fail-open Outbound = %q, %v; want the original message", msg.Content, ok)
+	}
+
+	var status DeliveryStatus
+	closed := &MiddlewareChain{}
+	closed.Add(failing, true)
+	_, ok = closed.Outbound(context.Background(), OutboundMessage{
+		Content:    "hi",
+		OnDelivery: func(s DeliveryStatus) { status = s },
+	})
+	if ok {
+		t.Error("fail-closed Outbound should drop the message")
+	}
+	if status.State != DeliveryFailed || !strings.Contains(status.Error, "broken") {
+		t.Errorf("delivery status = %+v, want failed naming the middleware", status)
+	}
+}
+
+func TestMiddleware_SlowChatDoesNotBlockOthers(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+	chain := &MiddlewareChain{}
+	chain.Add(&funcMiddleware{name: "slow", inbound: func(m InboundMessage) (InboundMessage, Decision, error) {
+		if m.ChatID == "slow" {
+			<-release
+		}
+		return m, Pass, nil
+	}}, false)
+	mb.SetMiddleware(chain)
+
+	mb.PublishInbound(ctx, InboundMessage{Channel: "test", ChatID: "slow", Content: "1"})
+	mb.PublishInbound(ctx, InboundMessage{Channel: "test", ChatID: "fast", Content: "2"})
+
+	got, ok := mb.ConsumeInbound(ctx)
+	if !ok || got.ChatID != "fast" {
+		t.Fatalf("ConsumeInbound = %+v, %v; want the fast chat's message while the slow one is held", got, ok)
+	}
+}
+
+func TestMiddleware_SkipsPartialOutbound(t *testing.T) {
+	mb := NewMessageBus()
+	defer mb.Close()
+	ctx := context.Background()
+
+	var calls int
+	chain := &MiddlewareChain{}
+	chain.Add(&funcMiddleware{name: "count", outbound: func(m OutboundMessage) (OutboundMessage, Decision, error) {
+		calls++
+		return m, Pass, nil
+	}}, false)
+	mb.SetMiddleware(chain)
+
+	mb.PublishOutbound(ctx, OutboundMessage{Channel: "test", ChatID: "1", Content: "Hel", Partial: true})
+	mb.PublishOutbound(ctx, OutboundMessage{Channel: "test", ChatID: "1", Content: "Hello"})
+
+	first, _ := mb.SubscribeOutbound(ctx)
+	second, _ := mb.SubscribeOutbound(ctx)
+	if first.Content != "Hel" || second.Content != "Hello" {
+		t.Errorf("got %q then %q, want the chat's messages in order", first.Content, second.Content)
+	}
+	if calls != 1 {
+		t.Errorf("middleware ran %d times, want once for the final message only", calls)
+	}
+}
@@ -0,0 +1,101 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// chatIdleTimeout is how long a chat's middleware worker waits for another
+// message before it exits.
+const chatIdleTimeout = time.Minute
+
+// chatPipeline runs the middleware chain for each chat in its own goroutine,
+// so a slow middleware (an LLM translation, an exec hook) holds up only the
+// chat whose message it is working on. Messages of one chat keep their
+// order. Processed messages are sent to out.
+type chatPipeline[T any] struct {
+	out     chan T
+	done    <-chan struct{}
+	process func(T) (T, bool)
+
+	mu    sync.Mutex
+	chats map[string]*chatQueue[T]
+}
+
+type chatQueue[T any] struct {
+	ch      chan T
+	pending int // messages submitted but not yet processed, guarded by chatPipeline.mu
+}
+
+func newChatPipeline[T any](done <-chan struct{}, process func(T) (T, bool)) *chatPipeline[T] {
+	return &chatPipeline[T]{
+		out:     make(chan T, defaultBusBufferSize),
+		done:    done,
+		process: process,
+		chats:   make(map[string]*chatQueue[T]),
+	}
+}
+
+// run submits every message read from in until the bus closes.
+func (p *chatPipeline[T]) run(in <-chan T, key func(T) string) {
+	for {
+		select {
+		case msg := <-in:
+			p.submit(key(msg), msg)
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// submit queues msg for its chat's worker, starting the worker if needed.
+func (p *chatPipeline[T]) submit(key string, msg T) {
+	p.mu.Lock()
+	q, ok := p.chats[key]
+	if !ok {
+		q = &chatQueue[T]{ch: make(chan T, defaultBusBufferSize)}
+		p.chats[key] = q
+		go p.work(key, q)
+	}
+	q.pending++
+	p.mu.Unlock()
+
+	select {
+	case q.ch <- msg:
+	case <-p.done:
+	}
+}
+
+// work processes one chat's messages in order and exits once the chat has
+// been idle for chatIdleTimeout.
+func (p *chatPipeline[T]) work(key string, q *chatQueue[T]) {
+	idle := time.NewTimer(chatIdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case msg := <-q.ch:
+			if out, ok := p.process(msg); ok {
+				select {
+				case p.out <- out:
+				case <-p.done:
+					return
+				}
+			}
+			p.mu.Lock()
+			q.pending--
+			p.mu.Unlock()
+			idle.Reset(chatIdleTimeout)
+		case <-idle.C:
+			p.mu.Lock()
+			if q.pending == 0 {
+				delete(p.chats, key)
+				p.mu.Unlock()
+				return
+			}
+			p.mu.Unlock()
+			idle.Reset(chatIdleTimeout)
+		case <-p.done:
+			return
+		}
+	}
+}
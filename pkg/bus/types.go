@@ -33,6 +33,39 @@ type OutboundMessage struct {
 	Channel string `json:"channel"`
 	ChatID  string `json:"chat_id"`
 	Content string `json:"content"`
+	// Card adds buttons to the message. A card written into Content as a
+	// fenced card block is moved here by the channel manager.
+	Card *Card `json:"card,omitempty"`
+	// Partial marks the text so far of a reply that is still being
+	// written. Channels that can edit messages show it in the placeholder;
+	// others drop it and wait for the complete reply.
+	Partial bool `json:"partial,omitempty"`
+
+	// OnDelivery, when set, is called once the channel manager knows the
+	// outcome of the send. It runs on the channel worker and must not block.
+	OnDelivery func(DeliveryStatus) `json:"-"`
+}
+
+// DeliveryState is how far an outbound message got.
+type DeliveryState string
+
+const (
+	// DeliveryAccepted means the platform API took the message. The bot APIs
+	// we support (Telegram, LINE, ...) expose no delivered/read receipts for
+	// bot messages, so this is the strongest confirmation available.
+	DeliveryAccepted DeliveryState = "accepted"
+	// DeliveryQueued means the channel is in quiet hours and will send the
+	// message when they end. No further status follows.
+	DeliveryQueued DeliveryState = "queued"
+	DeliveryFailed DeliveryState = "failed"
+)
+
+// DeliveryStatus reports the outcome of an outbound message.
+type DeliveryStatus struct {
+	State   DeliveryState
+	Channel string
+	ChatID  string
+	Error   string // set when State is DeliveryFailed
 }
 
 // MediaPart describes a single media attachment to send.
@@ -49,4 +82,7 @@ type OutboundMediaMessage struct {
 	Channel string      `json:"channel"`
 	ChatID  string      `json:"chat_id"`
 	Parts   []MediaPart `json:"parts"`
+
+	// OnDelivery works as for OutboundMessage.
+	OnDelivery func(DeliveryStatus) `json:"-"`
 }
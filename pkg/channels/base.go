@@ -5,16 +5,22 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"encoding/hex"
+	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/moderation"
+	"github.com/sipeed/picoclaw/pkg/scan"
 )
 
 var (
@@ -49,6 +55,7 @@ type Channel interface {
 	IsAllowed(senderID string) bool
 	IsAllowedSender(sender bus.SenderInfo) bool
 	ReasoningChannelID() string
+	HandleEditedMessage(senderID, chatID, originalMessageID, newContent string, metadata map[string]string)
 }
 
 // BaseChannelOption is a functional option for configuring a BaseChannel.
@@ -84,12 +91,22 @@ type BaseChannel struct {
 	running             atomic.Bool
 	name                string
 	allowList           []string
+	allowPatterns       sync.Map // allow_from "re:" entry -> *regexp.Regexp, nil if invalid
 	maxMessageLength    int
 	groupTrigger        config.GroupTriggerConfig
+	mediaDownload       config.MediaConfig
+	mediaDefaults       []string // kinds downloaded when mediaDownload.Download is unset, nil for all
 	mediaStore          media.MediaStore
 	placeholderRecorder PlaceholderRecorder
 	owner               Channel // the concrete channel that embeds this BaseChannel
 	reasoningChannelID  string
+	moderation          *moderation.Policy
+	scan                *scan.Policy // nil when attachment scanning is off
+	scanQueue           chatQueue    // runs scanned messages off the receive goroutine
+	language            string       // locale of canned messages, see pkg/i18n
+	lastMessageAt       atomic.Int64 // unix nanos of the last accepted inbound message
+	quietHours          *QuietHours  // nil when the channel has no quiet hours
+	panicHandler        atomic.Pointer[func(any)]
 }
 
 func NewBaseChannel(
@@ -183,6 +200,14 @@ func (c *BaseChannel) IsAllowed(senderID string) bool {
 	}
 
 	for _, allowed := range c.allowList {
+		if re, ok := c.allowPattern(allowed); ok {
+			// Usernames can be chosen by anyone, so patterns match IDs only.
+			if re != nil && re.MatchString(idPart) {
+				return true
+			}
+			continue
+		}
+
 		// Strip leading "@" from allowed value for username matching
 		trimmed := strings.TrimPrefix(allowed, "@")
 		allowedID := trimmed
@@ -217,6 +242,12 @@ func (c *BaseChannel) IsAllowedSender(sender bus.SenderInfo) bool {
 	}
 
 	for _, allowed := range c.allowList {
+		if re, ok := c.allowPattern(allowed); ok {
+			if re != nil && matchesAny(re, sender.PlatformID, sender.CanonicalID) {
+				return true
+			}
+			continue
+		}
 		if identity.MatchAllowed(sender, allowed) {
 			return true
 		}
@@ -225,6 +256,73 @@ func (c *BaseChannel) IsAllowedSender(sender bus.SenderInfo) bool {
 	return false
 }
 
+// allowPatternPrefix marks an allow_from entry as a regular expression, e.g.
+// "re:^U[0-9a-f]{32}$" to allow any LINE user.
+const allowPatternPrefix = "re:"
+
+// allowPattern reports whether an allow_from entry is a pattern and returns
+// it compiled, or nil when it does not compile. Patterns are compiled on
+// first use and cached.
+func (c *BaseChannel) allowPattern(entry string) (*regexp.Regexp, bool) {
+	expr, ok := strings.CutPrefix(strings.TrimSpace(entry), allowPatternPrefix)
+	if !ok {
+		return nil, false
+	}
+	if cached, ok := c.allowPatterns.Load(entry); ok {
+		return cached.(*regexp.Regexp), true
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		logger.WarnCF("channels", "Ignoring invalid allow_from pattern", map[string]any{
+			"channel": c.name,
+			"pattern": entry,
+			"error":   err.Error(),
+		})
+		re = nil
+	}
+	c.allowPatterns.Store(entry, re)
+	return re, true
+}
+
+func matchesAny(re *regexp.Regexp, values ...string) bool {
+	for _, v := range values {
+		if v != "" && re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// EditedMessageIDKey is the inbound metadata key that marks a message as an
+// edit and names the message it replaces.
+const EditedMessageIDKey = "edited_message_id"
+
+// HandleEditedMessage handles a user editing a message they sent earlier.
+// Channels whose platform reports edits override it, usually with
+// PublishEdit; the default ignores edits.
+func (c *BaseChannel) HandleEditedMessage(senderID, chatID, originalMessageID, newContent string, metadata map[string]string) {
+}
+
+// PublishEdit passes an edit to the agent like a new message, marked with
+// EditedMessageIDKey so the agent can update the original in its history.
+// media is the edited message's attachments, such as the photo whose caption
+// changed.
+func (c *BaseChannel) PublishEdit(
+	ctx context.Context,
+	peer bus.Peer,
+	senderID, chatID, originalMessageID, newContent string,
+	media []string,
+	metadata map[string]string,
+	senderOpts ...bus.SenderInfo,
+) {
+	edited := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		edited[k] = v
+	}
+	edited[EditedMessageIDKey] = originalMessageID
+	c.HandleMessage(ctx, peer, originalMessageID, senderID, chatID, newContent, media, edited, senderOpts...)
+}
+
 func (c *BaseChannel) HandleMessage(
 	ctx context.Context,
 	peer bus.Peer,
@@ -248,6 +346,8 @@ func (c *BaseChannel) HandleMessage(
 		}
 	}
 
+	c.lastMessageAt.Store(time.Now().UnixNano())
+
 	// Set SenderID to canonical if available, otherwise keep the raw senderID
 	resolvedSenderID := senderID
 	if sender.CanonicalID != "" {
@@ -269,6 +369,33 @@ func (c *BaseChannel) HandleMessage(
 		Metadata:   metadata,
 	}
 
+	if c.scan != nil {
+		// Scans can take a while. They run off the receive goroutine, and
+		// every message of the chat waits its turn so the chat keeps its
+		// order.
+		c.scanQueue.run(chatID, func() {
+			c.scanInbound(ctx, &msg)
+			c.deliverInbound(ctx, msg)
+		})
+		return
+	}
+	c.deliverInbound(ctx, msg)
+}
+
+// deliverInbound moderates msg, starts the typing indicator, reaction and
+// placeholder, and publishes it to the agent.
+func (c *BaseChannel) deliverInbound(ctx context.Context, msg bus.InboundMessage) {
+	chatID, messageID := msg.ChatID, msg.MessageID
+
+	if !c.moderateInbound(ctx, msg) {
+		return
+	}
+
+	if c.isInQuietHours() {
+		c.receiveInQuietHours(ctx, msg)
+		return
+	}
+
 	// Auto-trigger typing indicator, message reaction, and placeholder before publishing.
 	// Each capability is independent — all three may fire for the same message.
 	if c.owner != nil && c.placeholderRecorder != nil {
@@ -301,6 +428,169 @@ func (c *BaseChannel) HandleMessage(
 	}
 }
 
+// moderateInbound reports whether msg may reach the agent. A blocked message
+// is answered with the canned response instead; moderation errors let the
+// message through.
+func (c *BaseChannel) moderateInbound(ctx context.Context, msg bus.InboundMessage) bool {
+	if c.moderation == nil {
+		return true
+	}
+	verdict, err := c.moderation.Moderator.CheckInbound(ctx, msg)
+	if err != nil {
+		logger.WarnCF("channels", "Inbound moderation failed; passing message through", map[string]any{
+			"channel": c.name,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+		return true
+	}
+	if verdict.Allowed {
+		return true
+	}
+
+	logger.WarnCF("channels", "Inbound message blocked by moderation", map[string]any{
+		"channel":   c.name,
+		"chat_id":   msg.ChatID,
+		"sender_id": msg.SenderID,
+		"reason":    verdict.Reason,
+	})
+	reply := c.moderation.InboundReply
+	if reply == "" {
+		reply = i18n.T(c.language, i18n.ModerationInbound)
+	}
+	if err := c.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel: c.name,
+		ChatID:  msg.ChatID,
+		Content: reply,
+	}); err != nil {
+		logger.ErrorCF("channels", "Failed to send moderation reply", map[string]any{
+			"channel": c.name,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+	}
+	return false
+}
+
+// SetModeration injects the moderation policy applied to inbound messages.
+func (c *BaseChannel) SetModeration(p *moderation.Policy) { c.moderation = p }
+
+// scanInbound scans the attachments of msg for malware. Infected files are
+// deleted and dropped from msg, whose text is replaced with a warning, and
+// the owner is notified. Files that could not be scanned are passed through
+// unless the policy blocks unscanned files.
+func (c *BaseChannel) scanInbound(ctx context.Context, msg *bus.InboundMessage) {
+	if c.scan == nil || len(msg.Media) == 0 {
+		return
+	}
+
+	var kept, signatures []string
+	unscanned := 0
+	for _, ref := range msg.Media {
+		stored := c.mediaStore != nil && strings.HasPrefix(ref, "media://")
+		path := ref
+		var err error
+		if stored {
+			path, err = c.mediaStore.Resolve(ref)
+		}
+		var res scan.Result
+		if err == nil {
+			res, err = c.scan.Scan(ctx, path)
+		}
+		switch {
+		case err != nil:
+			logger.WarnCF("channels", "Attachment scan failed", map[string]any{
+				"channel": c.name,
+				"chat_id": msg.ChatID,
+				"ref":     ref,
+				"error":   err.Error(),
+			})
+			if !c.scan.BlockUnscanned {
+				kept = append(kept, ref)
+				continue
+			}
+			unscanned++
+		case res.Infected:
+			logger.WarnCF("channels", "Infected attachment deleted", map[string]any{
+				"channel":   c.name,
+				"chat_id":   msg.ChatID,
+				"sender_id": msg.SenderID,
+				"path":      path,
+				"signature": res.Signature,
+			})
+			signatures = append(signatures, res.Signature)
+		default:
+			kept = append(kept, ref)
+			continue
+		}
+		c.deleteAttachment(ref, path, stored)
+	}
+	if len(kept) == len(msg.Media) {
+		return
+	}
+
+	msg.Media = kept
+	var notes []string
+	if len(signatures) > 0 {
+		notes = append(notes, i18n.T(c.language, i18n.ScanRemoved, len(signatures)))
+	}
+	if unscanned > 0 {
+		notes = append(notes, i18n.T(c.language, i18n.ScanUnscanned, unscanned))
+	}
+	msg.Content = strings.Join(notes, "\n")
+	if len(signatures) == 0 || c.scan.NotifyChannel == "" || c.scan.NotifyChatID == "" {
+		return
+	}
+	if err := c.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel: c.scan.NotifyChannel,
+		ChatID:  c.scan.NotifyChatID,
+		Content: i18n.T(c.language, i18n.ScanOwnerNotice,
+			len(signatures), msg.SenderID, c.name+":"+msg.ChatID, strings.Join(signatures, ", ")),
+	}); err != nil {
+		logger.ErrorCF("channels", "Failed to notify owner of infected attachment", map[string]any{
+			"channel": c.name,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// deleteAttachment deletes a removed attachment, releasing it from the media
+// store when it was stored there.
+func (c *BaseChannel) deleteAttachment(ref, path string, stored bool) {
+	var err error
+	if stored {
+		err = c.mediaStore.Release(ref)
+	} else if err = os.Remove(path); os.IsNotExist(err) {
+		err = nil
+	}
+	if err != nil {
+		logger.ErrorCF("channels", "Failed to delete attachment", map[string]any{
+			"ref":   ref,
+			"error": err.Error(),
+		})
+	}
+}
+
+// SetScanPolicy injects the malware scan policy applied to inbound
+// attachments; nil turns scanning off.
+func (c *BaseChannel) SetScanPolicy(p *scan.Policy) { c.scan = p }
+
+// SetLanguage sets the locale of the channel's canned messages.
+func (c *BaseChannel) SetLanguage(lang string) { c.language = lang }
+
+// Language returns the locale of the channel's canned messages.
+func (c *BaseChannel) Language() string { return c.language }
+
+// LastMessageAt returns when the channel last accepted an inbound message,
+// or the zero time if it hasn't yet.
+func (c *BaseChannel) LastMessageAt() time.Time {
+	if n := c.lastMessageAt.Load(); n != 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
 func (c *BaseChannel) SetRunning(running bool) {
 	c.running.Store(running)
 }
@@ -321,6 +611,27 @@ func (c *BaseChannel) GetPlaceholderRecorder() PlaceholderRecorder {
 	return c.placeholderRecorder
 }
 
+// Go runs fn, a receive loop or message handler of the channel, in its own
+// goroutine. Under a SupervisedChannel a panic in fn is recovered and the
+// channel is restarted; otherwise it crashes the process like any panic.
+func (c *BaseChannel) Go(fn func()) {
+	go func() {
+		if h := c.panicHandler.Load(); h != nil {
+			defer func() {
+				if r := recover(); r != nil {
+					(*h)(r)
+				}
+			}()
+		}
+		fn()
+	}()
+}
+
+// SetPanicHandler sets what handles a panic in a goroutine started with Go.
+func (c *BaseChannel) SetPanicHandler(h func(any)) {
+	c.panicHandler.Store(&h)
+}
+
 // SetOwner injects the concrete channel that embeds this BaseChannel.
 // This allows HandleMessage to auto-trigger TypingCapable / ReactionCapable / PlaceholderCapable.
 func (c *BaseChannel) SetOwner(ch Channel) {
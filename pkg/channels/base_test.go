@@ -1,7 +1,9 @@
 package channels
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
@@ -44,6 +46,30 @@ func TestBaseChannelIsAllowed(t *testing.T) {
 			senderID:  "654321|bob",
 			want:      false,
 		},
+		{
+			name:      "regex pattern matches sender",
+			allowList: []string{"re:^U[0-9a-f]{32}$"},
+			senderID:  "U0123456789abcdef0123456789abcdef",
+			want:      true,
+		},
+		{
+			name:      "regex pattern ignores username of compound sender",
+			allowList: []string{"re:^team_"},
+			senderID:  "123456|team_alice",
+			want:      false,
+		},
+		{
+			name:      "regex pattern does not match",
+			allowList: []string{"re:^U[0-9a-f]{32}$"},
+			senderID:  "Uxyz",
+			want:      false,
+		},
+		{
+			name:      "invalid regex pattern matches nothing",
+			allowList: []string{"re:[", "re:["},
+			senderID:  "re:[",
+			want:      false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -252,6 +278,47 @@ func TestIsAllowedSender(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name:      "regex pattern matches PlatformID",
+			allowList: []string{"re:^U[0-9a-f]{32}$"},
+			sender: bus.SenderInfo{
+				Platform:    "line",
+				PlatformID:  "U0123456789abcdef0123456789abcdef",
+				CanonicalID: "line:U0123456789abcdef0123456789abcdef",
+			},
+			want: true,
+		},
+		{
+			name:      "regex pattern matches CanonicalID",
+			allowList: []string{"re:^telegram:12"},
+			sender: bus.SenderInfo{
+				Platform:    "telegram",
+				PlatformID:  "123456",
+				CanonicalID: "telegram:123456",
+			},
+			want: true,
+		},
+		{
+			name:      "regex pattern ignores Username",
+			allowList: []string{"re:^admin"},
+			sender: bus.SenderInfo{
+				Platform:    "telegram",
+				PlatformID:  "123456",
+				CanonicalID: "telegram:123456",
+				Username:    "admin_not_really",
+			},
+			want: false,
+		},
+		{
+			name:      "regex pattern is not treated as a canonical ID",
+			allowList: []string{"re:^9"},
+			sender: bus.SenderInfo{
+				Platform:    "re",
+				PlatformID:  "^9",
+				CanonicalID: "re:^9",
+			},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -263,3 +330,33 @@ func TestIsAllowedSender(t *testing.T) {
 		})
 	}
 }
+
+func TestPublishEdit(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("test", nil, mb, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	ch.HandleEditedMessage("u1", "chat1", "m1", "ignored", nil)
+	if msg, ok := mb.ConsumeInbound(ctx); ok {
+		t.Fatalf("default HandleEditedMessage published %+v", msg)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	meta := map[string]string{"user_id": "u1"}
+	ch.PublishEdit(ctx, bus.Peer{Kind: "direct", ID: "u1"}, "u1", "chat1", "m1", "fixed typo",
+		[]string{"media://photo"}, meta)
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok || msg.Content != "fixed typo" || msg.MessageID != "m1" || msg.Metadata[EditedMessageIDKey] != "m1" {
+		t.Fatalf("inbound = %+v, %v; want the edit marked with its original message", msg, ok)
+	}
+	if len(msg.Media) != 1 || msg.Media[0] != "media://photo" {
+		t.Errorf("media = %v, want the edited message's attachments", msg.Media)
+	}
+	if _, ok := meta[EditedMessageIDKey]; ok {
+		t.Error("PublishEdit modified the caller's metadata")
+	}
+}
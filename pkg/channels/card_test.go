@@ -0,0 +1,71 @@
+package channels
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// mockCardSender is a channel that renders cards.
+type mockCardSender struct {
+	mockChannel
+	sendCardFn func(ctx context.Context, msg bus.OutboundMessage) error
+}
+
+func (m *mockCardSender) SendCard(ctx context.Context, msg bus.OutboundMessage) error {
+	return m.sendCardFn(ctx, msg)
+}
+
+const cardContent = "Where to?\n\n```card\n" +
+	`{"title": "Dinner", "buttons": [{"label": "Sushi", "value": "sushi"}, {"label": "Pizza"}]}` +
+	"\n```"
+
+func TestPrepareCard_DegradesToNumberedOptions(t *testing.T) {
+	msg := prepareCard(&mockChannel{}, bus.OutboundMessage{Content: cardContent})
+
+	if msg.Card != nil {
+		t.Fatalf("Card = %+v, want nil for a channel without cards", msg.Card)
+	}
+	want := "Where to?\n\nDinner\n\n1. Sushi\n2. Pizza"
+	if msg.Content != want {
+		t.Errorf("Content = %q, want %q", msg.Content, want)
+	}
+}
+
+func TestPrepareCard_KeepsCardForCardSender(t *testing.T) {
+	msg := prepareCard(&mockCardSender{}, bus.OutboundMessage{Content: cardContent})
+
+	if msg.Card == nil || len(msg.Card.Buttons) != 2 {
+		t.Fatalf("Card = %+v, want the parsed card", msg.Card)
+	}
+	if msg.Content != "Where to?" {
+		t.Errorf("Content = %q, want the text without the card block", msg.Content)
+	}
+}
+
+func TestSendWithRetry_UsesSendCard(t *testing.T) {
+	m := newTestManager()
+	var sent, sentCard int
+	ch := &mockCardSender{
+		mockChannel: mockChannel{sendFn: func(context.Context, bus.OutboundMessage) error {
+			sent++
+			return nil
+		}},
+		sendCardFn: func(context.Context, bus.OutboundMessage) error {
+			sentCard++
+			return nil
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	card := &bus.Card{Buttons: []bus.CardButton{{Label: "Yes"}}}
+	m.sendWithRetry(context.Background(), "test", w, bus.OutboundMessage{ChatID: "1", Content: "Ok?", Card: card})
+	m.sendWithRetry(context.Background(), "test", w, bus.OutboundMessage{ChatID: "1", Content: "plain"})
+
+	if sentCard != 1 || sent != 1 {
+		t.Errorf("SendCard calls = %d, Send calls = %d, want 1 and 1", sentCard, sent)
+	}
+}
@@ -0,0 +1,47 @@
+package channels
+
+import "sync"
+
+// chatQueue runs functions one at a time per chat, each chat on its own
+// goroutine, so slow work for one message holds up neither the caller nor
+// other chats, and a chat's messages keep their order. The zero value is
+// ready to use.
+type chatQueue struct {
+	mu      sync.Mutex
+	pending map[string][]func()
+}
+
+// run queues fn behind the work already queued for chatID.
+func (q *chatQueue) run(chatID string, fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == nil {
+		q.pending = make(map[string][]func())
+	}
+	busy := len(q.pending[chatID]) > 0
+	q.pending[chatID] = append(q.pending[chatID], fn)
+	if !busy {
+		go q.drain(chatID)
+	}
+}
+
+// drain runs chatID's queued functions until there are none left.
+func (q *chatQueue) drain(chatID string) {
+	for {
+		q.mu.Lock()
+		fn := q.pending[chatID][0]
+		q.mu.Unlock()
+
+		fn()
+
+		q.mu.Lock()
+		rest := q.pending[chatID][1:]
+		if len(rest) == 0 {
+			delete(q.pending, chatID)
+			q.mu.Unlock()
+			return
+		}
+		q.pending[chatID] = rest
+		q.mu.Unlock()
+	}
+}
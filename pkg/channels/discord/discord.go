@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
@@ -13,6 +12,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
@@ -43,6 +43,7 @@ func NewDiscordChannel(cfg config.DiscordConfig, bus *bus.MessageBus) (*DiscordC
 	base := channels.NewBaseChannel("discord", cfg, bus, cfg.AllowFrom,
 		channels.WithMaxMessageLength(2000),
 		channels.WithGroupTrigger(cfg.GroupTrigger),
+		channels.WithMediaDownload(cfg.Media),
 		channels.WithReasoningChannelID(cfg.ReasoningChannelID),
 	)
 
@@ -67,7 +68,9 @@ func (c *DiscordChannel) Start(ctx context.Context) error {
 	}
 	c.botUserID = botUser.ID
 
-	c.session.AddHandler(c.handleMessage)
+	c.session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		c.Go(func() { c.handleMessage(s, m) })
+	})
 
 	if err := c.session.Open(); err != nil {
 		return fmt.Errorf("failed to open discord session: %w", err)
@@ -235,7 +238,7 @@ func (c *DiscordChannel) SendPlaceholder(ctx context.Context, chatID string) (st
 
 	text := c.config.Placeholder.Text
 	if text == "" {
-		text = "Thinking... 💭"
+		text = i18n.T(c.Language(), i18n.ChannelPlaceholder)
 	}
 
 	msg, err := c.session.ChannelMessageSend(chatID, text)
@@ -311,7 +314,8 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 	// In guild (group) channels, apply unified group trigger filtering
 	// DMs (GuildID is empty) always get a response
 	if m.GuildID != "" {
-		isMentioned := false
+		isMentioned := channels.MentionsBot(channels.DiscordMentionParser{}.ParseMentions(content), c.botUserID)
+		// Discord also lists mentions the text does not spell out, e.g. replies
 		for _, mention := range m.Mentions {
 			if mention.ID == c.botUserID {
 				isMentioned = true
@@ -353,9 +357,13 @@ func (c *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 	}
 
 	for _, attachment := range m.Attachments {
-		isAudio := utils.IsAudioFile(attachment.Filename, attachment.ContentType)
+		kind := channels.MediaKind(attachment.Filename, attachment.ContentType)
+		if !c.ShouldDownloadMedia(kind) {
+			content = appendContent(content, channels.SkippedMedia(kind, attachment.Filename))
+			continue
+		}
 
-		if isAudio {
+		if kind == config.MediaAudio {
 			localPath := c.downloadAttachment(attachment.URL, attachment.Filename)
 			if localPath != "" {
 				mediaPaths = append(mediaPaths, storeMedia(localPath, attachment.Filename))
@@ -469,13 +477,10 @@ func (c *DiscordChannel) downloadAttachment(url, filename string) string {
 }
 
 // stripBotMention removes the bot mention from the message content.
-// Discord mentions have the format <@USER_ID> or <@!USER_ID> (with nickname).
 func (c *DiscordChannel) stripBotMention(text string) string {
 	if c.botUserID == "" {
 		return text
 	}
-	// Remove both regular mention <@USER_ID> and nickname mention <@!USER_ID>
-	text = strings.ReplaceAll(text, fmt.Sprintf("<@%s>", c.botUserID), "")
-	text = strings.ReplaceAll(text, fmt.Sprintf("<@!%s>", c.botUserID), "")
-	return strings.TrimSpace(text)
+	p := channels.DiscordMentionParser{}
+	return p.StripMentions(text, p.ParseMentions(text), c.botUserID)
 }
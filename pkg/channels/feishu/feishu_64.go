@@ -68,13 +68,13 @@ func (c *FeishuChannel) Start(ctx context.Context) error {
 	c.SetRunning(true)
 	logger.InfoC("feishu", "Feishu channel started (websocket mode)")
 
-	go func() {
+	c.Go(func() {
 		if err := wsClient.Start(runCtx); err != nil {
 			logger.ErrorCF("feishu", "Feishu websocket stopped with error", map[string]any{
 				"error": err.Error(),
 			})
 		}
-	}()
+	})
 
 	return nil
 }
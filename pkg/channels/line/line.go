@@ -7,12 +7,15 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
@@ -65,6 +68,7 @@ func NewLINEChannel(cfg config.LINEConfig, messageBus *bus.MessageBus) (*LINECha
 	base := channels.NewBaseChannel("line", cfg, messageBus, cfg.AllowFrom,
 		channels.WithMaxMessageLength(5000),
 		channels.WithGroupTrigger(cfg.GroupTrigger),
+		channels.WithMediaDownload(cfg.Media),
 		channels.WithReasoningChannelID(cfg.ReasoningChannelID),
 	)
 
@@ -196,8 +200,12 @@ func (c *LINEChannel) webhookHandler(w http.ResponseWriter, r *http.Request) {
 	// Return 200 immediately, process events asynchronously
 	w.WriteHeader(http.StatusOK)
 
-	for _, event := range payload.Events {
-		go c.processEvent(event)
+	singles, sets := splitImageSets(payload.Events)
+	for _, event := range singles {
+		c.Go(func() { c.processEvent(event) })
+	}
+	for _, set := range sets {
+		c.Go(func() { c.processImageSet(set) })
 	}
 }
 
@@ -216,11 +224,20 @@ func (c *LINEChannel) verifySignature(body []byte, signature string) bool {
 
 // LINE webhook event types
 type lineEvent struct {
-	Type       string          `json:"type"`
-	ReplyToken string          `json:"replyToken"`
-	Source     lineSource      `json:"source"`
-	Message    json.RawMessage `json:"message"`
-	Timestamp  int64           `json:"timestamp"`
+	Type           string          `json:"type"`
+	WebhookEventID string          `json:"webhookEventId"`
+	ReplyToken     string          `json:"replyToken"`
+	Source         lineSource      `json:"source"`
+	Message        json.RawMessage `json:"message"`
+	Postback       *linePostback   `json:"postback"`
+	Timestamp      int64           `json:"timestamp"`
+}
+
+// linePostback is the payload of a postback action (rich menu or template
+// button). Params is set by datetime picker actions.
+type linePostback struct {
+	Data   string            `json:"data"`
+	Params map[string]string `json:"params"`
 }
 
 type lineSource struct {
@@ -236,28 +253,145 @@ type lineMessage struct {
 	Text       string `json:"text"`
 	QuoteToken string `json:"quoteToken"`
 	Mention    *struct {
-		Mentionees []lineMentionee `json:"mentionees"`
+		Mentionees []channels.LINEMentionee `json:"mentionees"`
 	} `json:"mention"`
 	ContentProvider struct {
 		Type string `json:"type"`
 	} `json:"contentProvider"`
+	ImageSet *lineImageSet `json:"imageSet"`
 }
 
-type lineMentionee struct {
-	Index  int    `json:"index"`
-	Length int    `json:"length"`
-	Type   string `json:"type"` // "user", "all"
-	UserID string `json:"userId"`
+// lineImageSet marks an image sent together with others. LINE delivers each
+// image of the set as its own message event.
+type lineImageSet struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"` // 1-based
+	Total int    `json:"total"`
 }
 
 func (c *LINEChannel) processEvent(event lineEvent) {
-	if event.Type != "message" {
-		logger.DebugCF("line", "Ignoring non-message event", map[string]any{
+	switch event.Type {
+	case "message":
+		c.processMessageEvent(event, nil)
+	case "follow":
+		c.processFollowEvent(event)
+	case "postback":
+		c.processPostbackEvent(event)
+	default:
+		logger.DebugCF("line", "Ignoring unsupported event", map[string]any{
 			"type": event.Type,
 		})
+	}
+}
+
+// processFollowEvent greets a user who added the bot as a friend.
+func (c *LINEChannel) processFollowEvent(event lineEvent) {
+	welcome := c.config.WelcomeMessage
+	if welcome == "" {
+		return
+	}
+
+	userID := event.Source.UserID
+	if !c.IsAllowedSender(lineSender(userID)) {
+		return
+	}
+
+	logger.InfoCF("line", "New follower, sending welcome message", map[string]any{
+		"user_id": userID,
+	})
+
+	if event.ReplyToken != "" {
+		if err := c.sendReply(c.ctx, event.ReplyToken, []any{buildTextMessage(welcome, "")}); err == nil {
+			return
+		}
+		logger.DebugC("line", "Reply API failed for welcome message, falling back to Push API")
+	}
+	if err := c.sendPush(c.ctx, userID, []any{buildTextMessage(welcome, "")}); err != nil {
+		logger.ErrorCF("line", "Failed to send welcome message", map[string]any{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// processPostbackEvent routes postback data from rich menus and template
+// buttons to the agent as a message from the user who tapped it.
+func (c *LINEChannel) processPostbackEvent(event lineEvent) {
+	if event.Postback == nil || strings.TrimSpace(event.Postback.Data) == "" {
 		return
 	}
 
+	senderID := event.Source.UserID
+	sender := lineSender(senderID)
+	if !c.IsAllowedSender(sender) {
+		return
+	}
+
+	chatID := c.resolveChatID(event.Source)
+	isGroup := event.Source.Type == "group" || event.Source.Type == "room"
+
+	if event.ReplyToken != "" {
+		c.replyTokens.Store(chatID, replyTokenEntry{
+			token:     event.ReplyToken,
+			timestamp: time.Now(),
+		})
+	}
+
+	content := postbackContent(event.Postback)
+	metadata := map[string]string{
+		"platform":      "line",
+		"source_type":   event.Source.Type,
+		"event_type":    "postback",
+		"postback_data": event.Postback.Data,
+	}
+
+	var peer bus.Peer
+	if isGroup {
+		peer = bus.Peer{Kind: "group", ID: chatID}
+	} else {
+		peer = bus.Peer{Kind: "direct", ID: senderID}
+	}
+
+	logger.DebugCF("line", "Received postback", map[string]any{
+		"sender_id": senderID,
+		"chat_id":   chatID,
+		"data":      utils.Truncate(event.Postback.Data, 50),
+	})
+
+	// Postbacks are explicit taps on the bot's own buttons, so group
+	// trigger filtering does not apply.
+	c.HandleMessage(c.ctx, peer, event.WebhookEventID, senderID, chatID, content, nil, metadata, sender)
+}
+
+// postbackContent renders postback data, plus any datetime picker values,
+// as the text of the synthetic message.
+func postbackContent(pb *linePostback) string {
+	if len(pb.Params) == 0 {
+		return pb.Data
+	}
+	keys := make([]string, 0, len(pb.Params))
+	for k := range pb.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		params = append(params, k+"="+pb.Params[k])
+	}
+	return fmt.Sprintf("%s (%s)", pb.Data, strings.Join(params, ", "))
+}
+
+func lineSender(userID string) bus.SenderInfo {
+	return bus.SenderInfo{
+		Platform:    "line",
+		PlatformID:  userID,
+		CanonicalID: identity.BuildCanonicalID("line", userID),
+	}
+}
+
+// processMessageEvent handles a message event. For an image set, set holds
+// the images of the set in order, and they are passed on as one message.
+func (c *LINEChannel) processMessageEvent(event lineEvent, set []lineMessage) {
 	senderID := event.Source.UserID
 	chatID := c.resolveChatID(event.Source)
 	isGroup := event.Source.Type == "group" || event.Source.Type == "room"
@@ -302,6 +436,38 @@ func (c *LINEChannel) processEvent(event lineEvent) {
 		return localPath // fallback
 	}
 
+	// mediaTooLarge is set when a download is rejected by max_media_size_bytes.
+	var mediaTooLarge bool
+	download := func(messages []lineMessage, filename, tag string) {
+		downloads := make([]channels.MediaDownload, len(messages))
+		for i, m := range messages {
+			downloads[i] = func(context.Context) (string, error) {
+				return c.downloadContent(m.ID, filename)
+			}
+		}
+		paths, errs := c.DownloadMedia(c.ctx, downloads)
+
+		var tags []string
+		for i, err := range errs {
+			switch {
+			case errors.Is(err, utils.ErrFileTooLarge):
+				logger.WarnCF("line", "Rejected media over the size limit", map[string]any{
+					"chat_id":    chatID,
+					"message_id": messages[i].ID,
+					"type":       msg.Type,
+					"error":      err.Error(),
+				})
+				tags = append(tags, lineMediaTooLarge)
+				mediaTooLarge = true
+			case err == nil:
+				mediaPaths = append(mediaPaths, storeMedia(paths[i], filename))
+				tags = append(tags, tag)
+			}
+		}
+		content = strings.Join(tags, "\n")
+	}
+	single := []lineMessage{msg}
+
 	switch msg.Type {
 	case "text":
 		content = msg.Text
@@ -310,22 +476,26 @@ func (c *LINEChannel) processEvent(event lineEvent) {
 			content = c.stripBotMention(content, msg)
 		}
 	case "image":
-		localPath := c.downloadContent(msg.ID, "image.jpg")
-		if localPath != "" {
-			mediaPaths = append(mediaPaths, storeMedia(localPath, "image.jpg"))
-			content = "[image]"
+		if !c.ShouldDownloadMedia(config.MediaImage) {
+			content = channels.SkippedMedia(config.MediaImage, "")
+		} else {
+			images := set
+			if images == nil {
+				images = single
+			}
+			download(images, "image.jpg", "[image]")
 		}
 	case "audio":
-		localPath := c.downloadContent(msg.ID, "audio.m4a")
-		if localPath != "" {
-			mediaPaths = append(mediaPaths, storeMedia(localPath, "audio.m4a"))
-			content = "[audio]"
+		if !c.ShouldDownloadMedia(config.MediaAudio) {
+			content = channels.SkippedMedia(config.MediaAudio, "")
+		} else {
+			download(single, "audio.m4a", "[audio]")
 		}
 	case "video":
-		localPath := c.downloadContent(msg.ID, "video.mp4")
-		if localPath != "" {
-			mediaPaths = append(mediaPaths, storeMedia(localPath, "video.mp4"))
-			content = "[video]"
+		if !c.ShouldDownloadMedia(config.MediaVideo) {
+			content = channels.SkippedMedia(config.MediaVideo, "")
+		} else {
+			download(single, "video.mp4", "[video]")
 		}
 	case "file":
 		content = "[file]"
@@ -356,6 +526,9 @@ func (c *LINEChannel) processEvent(event lineEvent) {
 		"platform":    "line",
 		"source_type": event.Source.Type,
 	}
+	if mediaTooLarge {
+		metadata["media_error"] = "too_large"
+	}
 
 	var peer bus.Peer
 	if isGroup {
@@ -372,12 +545,7 @@ func (c *LINEChannel) processEvent(event lineEvent) {
 		"preview":      utils.Truncate(content, 50),
 	})
 
-	sender := bus.SenderInfo{
-		Platform:    "line",
-		PlatformID:  senderID,
-		CanonicalID: identity.BuildCanonicalID("line", senderID),
-	}
-
+	sender := lineSender(senderID)
 	if !c.IsAllowedSender(sender) {
 		return
 	}
@@ -385,90 +553,90 @@ func (c *LINEChannel) processEvent(event lineEvent) {
 	c.HandleMessage(c.ctx, peer, msg.ID, senderID, chatID, content, mediaPaths, metadata, sender)
 }
 
-// isBotMentioned checks if the bot is mentioned in the message.
-// It first checks the mention metadata (userId match), then falls back
-// to text-based detection using the bot's display name, since LINE may
-// not include userId in mentionees for Official Accounts.
-func (c *LINEChannel) isBotMentioned(msg lineMessage) bool {
-	// Check mention metadata
-	if msg.Mention != nil {
-		for _, m := range msg.Mention.Mentionees {
-			if m.Type == "all" {
-				return true
-			}
-			if c.botUserID != "" && m.UserID == c.botUserID {
-				return true
-			}
+// splitImageSets separates the images of a set sent in one webhook call
+// from the other events, so they reach the agent as one message with all
+// images instead of one message per image. Sets are returned in the order
+// their first image arrived.
+func splitImageSets(events []lineEvent) (singles []lineEvent, sets [][]lineEvent) {
+	index := make(map[string]int)
+	for _, event := range events {
+		var msg lineMessage
+		if event.Type != "message" || json.Unmarshal(event.Message, &msg) != nil ||
+			msg.Type != "image" || msg.ImageSet == nil || msg.ImageSet.ID == "" {
+			singles = append(singles, event)
+			continue
 		}
-		// Mention metadata exists with mentionees but bot not matched by userId.
-		// The bot IS likely mentioned (LINE includes mention struct when bot is @-ed),
-		// so check if any mentionee overlaps with bot display name in text.
-		if c.botDisplayName != "" {
-			for _, m := range msg.Mention.Mentionees {
-				if m.Index >= 0 && m.Length > 0 {
-					runes := []rune(msg.Text)
-					end := m.Index + m.Length
-					if end <= len(runes) {
-						mentionText := string(runes[m.Index:end])
-						if strings.Contains(mentionText, c.botDisplayName) {
-							return true
-						}
-					}
-				}
-			}
+		if i, ok := index[msg.ImageSet.ID]; ok {
+			sets[i] = append(sets[i], event)
+			continue
+		}
+		index[msg.ImageSet.ID] = len(sets)
+		sets = append(sets, []lineEvent{event})
+	}
+	// A set with one image in this call is handled like any image.
+	kept := sets[:0]
+	for _, set := range sets {
+		if len(set) == 1 {
+			singles = append(singles, set[0])
+		} else {
+			kept = append(kept, set)
 		}
 	}
+	return singles, kept
+}
 
-	// Fallback: text-based detection with display name
-	if c.botDisplayName != "" && strings.Contains(msg.Text, "@"+c.botDisplayName) {
-		return true
+// processImageSet passes the images of a set on as one message, answered
+// through the reply token of the last image.
+func (c *LINEChannel) processImageSet(events []lineEvent) {
+	type image struct {
+		event lineEvent
+		msg   lineMessage
+	}
+	images := make([]image, 0, len(events))
+	for _, event := range events {
+		var msg lineMessage
+		if err := json.Unmarshal(event.Message, &msg); err == nil {
+			images = append(images, image{event, msg})
+		}
+	}
+	if len(images) == 0 {
+		return
 	}
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].msg.ImageSet.Index < images[j].msg.ImageSet.Index
+	})
 
-	return false
+	set := make([]lineMessage, len(images))
+	for i, img := range images {
+		set[i] = img.msg
+	}
+	event := images[0].event
+	for _, img := range images {
+		if img.event.ReplyToken != "" {
+			event.ReplyToken = img.event.ReplyToken
+		}
+	}
+	c.processMessageEvent(event, set)
 }
 
-// stripBotMention removes the @BotName mention text from the message.
-func (c *LINEChannel) stripBotMention(text string, msg lineMessage) string {
-	stripped := false
-
-	// Try to strip using mention metadata indices
+// mentionParser returns the parser for msg's mention metadata.
+func (c *LINEChannel) mentionParser(msg lineMessage) channels.LINEMentionParser {
+	p := channels.LINEMentionParser{BotUserID: c.botUserID, BotName: c.botDisplayName}
 	if msg.Mention != nil {
-		runes := []rune(text)
-		for i := len(msg.Mention.Mentionees) - 1; i >= 0; i-- {
-			m := msg.Mention.Mentionees[i]
-			// Strip if userId matches OR if the mention text contains the bot display name
-			shouldStrip := false
-			if c.botUserID != "" && m.UserID == c.botUserID {
-				shouldStrip = true
-			} else if c.botDisplayName != "" && m.Index >= 0 && m.Length > 0 {
-				end := m.Index + m.Length
-				if end <= len(runes) {
-					mentionText := string(runes[m.Index:end])
-					if strings.Contains(mentionText, c.botDisplayName) {
-						shouldStrip = true
-					}
-				}
-			}
-			if shouldStrip {
-				start := m.Index
-				end := m.Index + m.Length
-				if start >= 0 && end <= len(runes) {
-					runes = append(runes[:start], runes[end:]...)
-					stripped = true
-				}
-			}
-		}
-		if stripped {
-			return strings.TrimSpace(string(runes))
-		}
+		p.Mentionees = msg.Mention.Mentionees
 	}
+	return p
+}
 
-	// Fallback: strip @DisplayName from text
-	if c.botDisplayName != "" {
-		text = strings.ReplaceAll(text, "@"+c.botDisplayName, "")
-	}
+// isBotMentioned checks if the bot or everyone is mentioned in the message.
+func (c *LINEChannel) isBotMentioned(msg lineMessage) bool {
+	return channels.MentionsBot(c.mentionParser(msg).ParseMentions(msg.Text), c.botUserID)
+}
 
-	return strings.TrimSpace(text)
+// stripBotMention removes the @BotName mention text from the message.
+func (c *LINEChannel) stripBotMention(text string, msg lineMessage) string {
+	p := c.mentionParser(msg)
+	return p.StripMentions(text, p.ParseMentions(text), c.botUserID)
 }
 
 // resolveChatID determines the chat ID from the event source.
@@ -490,20 +658,55 @@ func (c *LINEChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	if !c.IsRunning() {
 		return channels.ErrNotRunning
 	}
+	return c.deliver(ctx, msg.ChatID, func(quoteToken string) []any {
+		return []any{buildTextMessage(msg.Content, quoteToken)}
+	})
+}
 
+// SendCard implements channels.CardSender. The card is sent as a Flex
+// Message whose buttons post their value back, after the text if any.
+func (c *LINEChannel) SendCard(ctx context.Context, msg bus.OutboundMessage) error {
+	if !c.IsRunning() {
+		return channels.ErrNotRunning
+	}
+	if msg.Card == nil {
+		return c.Send(ctx, msg)
+	}
+	if !fitsPostback(msg.Card) {
+		// LINE would reject the buttons; offer the choices as text.
+		options := msg.Card.PlainText()
+		if strings.TrimSpace(msg.Content) != "" {
+			options = msg.Content + "\n\n" + options
+		}
+		msg.Content, msg.Card = options, nil
+		return c.Send(ctx, msg)
+	}
+	return c.deliver(ctx, msg.ChatID, func(quoteToken string) []any {
+		var messages []any
+		if strings.TrimSpace(msg.Content) != "" {
+			messages = append(messages, buildTextMessage(msg.Content, quoteToken))
+		}
+		return append(messages, buildFlexMessage(msg.Card))
+	})
+}
+
+// deliver sends the messages built for chatID, through the Reply API when a
+// fresh reply token is cached and the Push API otherwise.
+func (c *LINEChannel) deliver(ctx context.Context, chatID string, build func(quoteToken string) []any) error {
 	// Load and consume quote token for this chat
 	var quoteToken string
-	if qt, ok := c.quoteTokens.LoadAndDelete(msg.ChatID); ok {
+	if qt, ok := c.quoteTokens.LoadAndDelete(chatID); ok {
 		quoteToken = qt.(string)
 	}
+	messages := build(quoteToken)
 
 	// Try reply token first (free, valid for ~25 seconds)
-	if entry, ok := c.replyTokens.LoadAndDelete(msg.ChatID); ok {
+	if entry, ok := c.replyTokens.LoadAndDelete(chatID); ok {
 		tokenEntry := entry.(replyTokenEntry)
 		if time.Since(tokenEntry.timestamp) < lineReplyTokenMaxAge {
-			if err := c.sendReply(ctx, tokenEntry.token, msg.Content, quoteToken); err == nil {
+			if err := c.sendReply(ctx, tokenEntry.token, messages); err == nil {
 				logger.DebugCF("line", "Message sent via Reply API", map[string]any{
-					"chat_id": msg.ChatID,
+					"chat_id": chatID,
 					"quoted":  quoteToken != "",
 				})
 				return nil
@@ -513,7 +716,7 @@ func (c *LINEChannel) Send(ctx context.Context, msg bus.OutboundMessage) error {
 	}
 
 	// Fall back to Push API
-	return c.sendPush(ctx, msg.ChatID, msg.Content, quoteToken)
+	return c.sendPush(ctx, chatID, messages)
 }
 
 // SendMedia implements the channels.MediaSender interface.
@@ -538,7 +741,7 @@ func (c *LINEChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessag
 			caption = fmt.Sprintf("[%s: %s]", part.Type, part.Filename)
 		}
 
-		if err := c.sendPush(ctx, msg.ChatID, caption, ""); err != nil {
+		if err := c.sendPush(ctx, msg.ChatID, []any{buildTextMessage(caption, "")}); err != nil {
 			return err
 		}
 	}
@@ -558,21 +761,93 @@ func buildTextMessage(content, quoteToken string) map[string]string {
 	return msg
 }
 
-// sendReply sends a message using the LINE Reply API.
-func (c *LINEChannel) sendReply(ctx context.Context, replyToken, content, quoteToken string) error {
+// lineFlexAltTextMax is the longest altText LINE accepts for a Flex Message.
+const lineFlexAltTextMax = 400
+
+// linePostbackMax is the longest data and displayText, in characters, LINE
+// accepts for a postback action.
+const linePostbackMax = 300
+
+// fitsPostback reports whether every button of card can be sent as a
+// postback action.
+func fitsPostback(card *bus.Card) bool {
+	for _, b := range card.Buttons {
+		if utf8.RuneCountInString(b.ReplyValue()) > linePostbackMax ||
+			utf8.RuneCountInString(b.Label) > linePostbackMax {
+			return false
+		}
+	}
+	return true
+}
+
+// buildFlexMessage renders a card as a Flex Message bubble: the title and
+// text in the body and one postback button per choice in the footer. The
+// postback data is the button's reply value and displayText shows the label
+// in the chat, as if the user had typed it.
+func buildFlexMessage(card *bus.Card) map[string]any {
+	var body []map[string]any
+	if card.Title != "" {
+		body = append(body, map[string]any{
+			"type": "text", "text": card.Title, "weight": "bold", "size": "lg", "wrap": true,
+		})
+	}
+	if card.Text != "" {
+		body = append(body, map[string]any{"type": "text", "text": card.Text, "wrap": true})
+	}
+
+	buttons := make([]map[string]any, 0, len(card.Buttons))
+	for _, b := range card.Buttons {
+		buttons = append(buttons, map[string]any{
+			"type":  "button",
+			"style": "secondary",
+			"action": map[string]any{
+				"type":        "postback",
+				"label":       utils.Truncate(b.Label, 20),
+				"data":        b.ReplyValue(),
+				"displayText": b.Label,
+			},
+		})
+	}
+
+	bubble := map[string]any{
+		"type": "bubble",
+		"footer": map[string]any{
+			"type": "box", "layout": "vertical", "spacing": "sm", "contents": buttons,
+		},
+	}
+	if len(body) > 0 {
+		bubble["body"] = map[string]any{"type": "box", "layout": "vertical", "spacing": "md", "contents": body}
+	}
+
+	altText := card.Title
+	if altText == "" {
+		altText = card.Text
+	}
+	if altText == "" {
+		altText = card.PlainText()
+	}
+	return map[string]any{
+		"type":     "flex",
+		"altText":  utils.Truncate(altText, lineFlexAltTextMax),
+		"contents": bubble,
+	}
+}
+
+// sendReply sends messages using the LINE Reply API.
+func (c *LINEChannel) sendReply(ctx context.Context, replyToken string, messages []any) error {
 	payload := map[string]any{
 		"replyToken": replyToken,
-		"messages":   []map[string]string{buildTextMessage(content, quoteToken)},
+		"messages":   messages,
 	}
 
 	return c.callAPI(ctx, lineReplyEndpoint, payload)
 }
 
-// sendPush sends a message using the LINE Push API.
-func (c *LINEChannel) sendPush(ctx context.Context, to, content, quoteToken string) error {
+// sendPush sends messages using the LINE Push API.
+func (c *LINEChannel) sendPush(ctx context.Context, to string, messages []any) error {
 	payload := map[string]any{
 		"to":       to,
-		"messages": []map[string]string{buildTextMessage(content, quoteToken)},
+		"messages": messages,
 	}
 
 	return c.callAPI(ctx, linePushEndpoint, payload)
@@ -661,11 +936,17 @@ func (c *LINEChannel) callAPI(ctx context.Context, endpoint string, payload any)
 	return nil
 }
 
-// downloadContent downloads media content from the LINE API.
-func (c *LINEChannel) downloadContent(messageID, filename string) string {
+// lineMediaTooLarge is the message content passed on for media over
+// max_media_size_bytes.
+const lineMediaTooLarge = "[media too large]"
+
+// downloadContent downloads media content from the LINE API, rejecting files
+// over max_media_size_bytes with utils.ErrFileTooLarge.
+func (c *LINEChannel) downloadContent(messageID, filename string) (string, error) {
 	url := fmt.Sprintf(lineContentEndpoint, messageID)
-	return utils.DownloadFile(url, filename, utils.DownloadOptions{
+	return utils.FetchFile(url, filename, utils.DownloadOptions{
 		LoggerPrefix: "line",
+		MaxBytes:     c.config.MaxMediaSizeBytes,
 		ExtraHeaders: map[string]string{
 			"Authorization": "Bearer " + c.config.ChannelAccessToken,
 		},
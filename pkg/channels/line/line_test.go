@@ -0,0 +1,164 @@
+package line
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func newTestLINEChannel(t *testing.T) (*LINEChannel, *bus.MessageBus) {
+	t.Helper()
+	msgBus := bus.NewMessageBus()
+	ch, err := NewLINEChannel(config.LINEConfig{
+		ChannelSecret:      "secret",
+		ChannelAccessToken: "token",
+	}, msgBus)
+	if err != nil {
+		t.Fatalf("NewLINEChannel() error: %v", err)
+	}
+	ch.ctx = context.Background()
+	return ch, msgBus
+}
+
+func TestProcessEvent_PostbackRoutesToAgent(t *testing.T) {
+	ch, msgBus := newTestLINEChannel(t)
+
+	ch.processEvent(lineEvent{
+		Type:           "postback",
+		WebhookEventID: "01HZX",
+		ReplyToken:     "reply-1",
+		Source:         lineSource{Type: "user", UserID: "U123"},
+		Postback: &linePostback{
+			Data:   "action=book",
+			Params: map[string]string{"date": "2026-03-01"},
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := msgBus.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("expected postback to be published as an inbound message")
+	}
+	if msg.Content != "action=book (date=2026-03-01)" {
+		t.Errorf("Content = %q", msg.Content)
+	}
+	if msg.ChatID != "U123" || msg.Peer.Kind != "direct" {
+		t.Errorf("ChatID = %q, Peer = %+v", msg.ChatID, msg.Peer)
+	}
+	if msg.Metadata["event_type"] != "postback" || msg.Metadata["postback_data"] != "action=book" {
+		t.Errorf("Metadata = %v", msg.Metadata)
+	}
+	if _, ok := ch.replyTokens.Load("U123"); !ok {
+		t.Error("expected the postback reply token to be kept for the reply")
+	}
+}
+
+func TestProcessEvent_PostbackFromBlockedSenderKeepsNoToken(t *testing.T) {
+	ch, err := NewLINEChannel(config.LINEConfig{
+		ChannelSecret:      "secret",
+		ChannelAccessToken: "token",
+		AllowFrom:          config.FlexibleStringSlice{"U123"},
+	}, bus.NewMessageBus())
+	if err != nil {
+		t.Fatalf("NewLINEChannel() error: %v", err)
+	}
+	ch.ctx = context.Background()
+
+	ch.processEvent(lineEvent{
+		Type:       "postback",
+		ReplyToken: "reply-1",
+		Source:     lineSource{Type: "user", UserID: "U999"},
+		Postback:   &linePostback{Data: "action=book"},
+	})
+
+	if _, ok := ch.replyTokens.Load("U999"); ok {
+		t.Error("a blocked sender's reply token must not be stored")
+	}
+}
+
+func TestProcessEvent_FollowWithoutWelcomeIsIgnored(t *testing.T) {
+	ch, msgBus := newTestLINEChannel(t)
+
+	ch.processEvent(lineEvent{
+		Type:       "follow",
+		ReplyToken: "reply-1",
+		Source:     lineSource{Type: "user", UserID: "U123"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, ok := msgBus.ConsumeInbound(ctx); ok {
+		t.Fatal("follow events must not reach the agent")
+	}
+}
+
+func TestBuildFlexMessage(t *testing.T) {
+	msg := buildFlexMessage(&bus.Card{
+		Title:   "Dinner",
+		Text:    "Where should we go?",
+		Buttons: []bus.CardButton{{Label: "Sushi", Value: "sushi"}, {Label: "Pizza"}},
+	})
+
+	if msg["type"] != "flex" || msg["altText"] != "Dinner" {
+		t.Fatalf("type = %v, altText = %v", msg["type"], msg["altText"])
+	}
+	bubble := msg["contents"].(map[string]any)
+	if body := bubble["body"].(map[string]any)["contents"].([]map[string]any); len(body) != 2 {
+		t.Errorf("body has %d components, want title and text", len(body))
+	}
+	buttons := bubble["footer"].(map[string]any)["contents"].([]map[string]any)
+	if len(buttons) != 2 {
+		t.Fatalf("footer has %d buttons, want 2", len(buttons))
+	}
+	for i, want := range []struct{ label, data string }{{"Sushi", "sushi"}, {"Pizza", "Pizza"}} {
+		action := buttons[i]["action"].(map[string]any)
+		if action["type"] != "postback" || action["label"] != want.label || action["data"] != want.data ||
+			action["displayText"] != want.label {
+			t.Errorf("button %d action = %v", i, action)
+		}
+	}
+}
+
+func TestFitsPostback(t *testing.T) {
+	card := &bus.Card{Buttons: []bus.CardButton{{Label: "Sushi", Value: strings.Repeat("寿", linePostbackMax)}}}
+	if !fitsPostback(card) {
+		t.Error("a value of exactly 300 characters should fit")
+	}
+	card.Buttons = append(card.Buttons, bus.CardButton{Label: "Pizza", Value: strings.Repeat("p", linePostbackMax+1)})
+	if fitsPostback(card) {
+		t.Error("a value over 300 characters should not fit")
+	}
+}
+
+func TestSplitImageSets(t *testing.T) {
+	image := func(id, set string, index int) lineEvent {
+		msg := `{"id":"` + id + `","type":"image"`
+		if set != "" {
+			msg += fmt.Sprintf(`,"imageSet":{"id":%q,"index":%d,"total":3}`, set, index)
+		}
+		return lineEvent{Type: "message", Message: json.RawMessage(msg + "}")}
+	}
+	events := []lineEvent{
+		image("1", "A", 2),
+		{Type: "message", Message: json.RawMessage(`{"id":"2","type":"text","text":"hi"}`)},
+		image("3", "A", 1),
+		image("4", "B", 1),
+		image("5", "", 0),
+		image("6", "A", 3),
+	}
+
+	singles, sets := splitImageSets(events)
+	if len(sets) != 1 || len(sets[0]) != 3 {
+		t.Fatalf("sets = %d (%v), want one set of 3", len(sets), sets)
+	}
+	if len(singles) != 3 {
+		t.Errorf("singles = %d, want the text, the unset image and the lone image of set B", len(singles))
+	}
+}
@@ -0,0 +1,79 @@
+package channels
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// LinkState is the connection state of a channel that polls its platform,
+// exposed as the channel_links stat.
+type LinkState struct {
+	Up        bool      `json:"up"`
+	Since     time.Time `json:"since"`
+	Drops     int64     `json:"drops"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+var (
+	linkMu     sync.Mutex
+	linkStates = map[string]LinkState{}
+)
+
+// SetLinkUp records that channel reached its platform. Only the transition
+// from down to up is logged.
+func SetLinkUp(channel string) {
+	linkMu.Lock()
+	state, known := linkStates[channel]
+	if known && state.Up {
+		linkMu.Unlock()
+		return
+	}
+	now := time.Now()
+	downFor := now.Sub(state.Since)
+	state.Up, state.Since = true, now
+	linkStates[channel] = state
+	linkMu.Unlock()
+
+	if known {
+		logger.InfoCF("channels", "Link up", map[string]any{
+			"channel":  channel,
+			"down_for": downFor.Round(time.Second).String(),
+			"drops":    state.Drops,
+		})
+	}
+}
+
+// SetLinkDown records that channel failed to reach its platform. Only the
+// transition from up to down is logged and counted as a drop.
+func SetLinkDown(channel string, err error) {
+	linkMu.Lock()
+	state, known := linkStates[channel]
+	if err != nil {
+		state.LastError = err.Error()
+	}
+	if known && !state.Up {
+		linkStates[channel] = state
+		linkMu.Unlock()
+		return
+	}
+	state.Up, state.Since = false, time.Now()
+	state.Drops++
+	linkStates[channel] = state
+	linkMu.Unlock()
+
+	logger.WarnCF("channels", "Link down", map[string]any{
+		"channel": channel,
+		"error":   state.LastError,
+		"drops":   state.Drops,
+	})
+}
+
+// LinkStates returns the link state of every polling channel by name.
+func LinkStates() map[string]LinkState {
+	linkMu.Lock()
+	defer linkMu.Unlock()
+	return maps.Clone(linkStates)
+}
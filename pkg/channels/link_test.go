@@ -0,0 +1,31 @@
+package channels
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLinkStateTransitions(t *testing.T) {
+	const name = "link-test"
+
+	SetLinkUp(name)
+	if s := LinkStates()[name]; !s.Up || s.Drops != 0 {
+		t.Fatalf("after first up: %+v", s)
+	}
+
+	SetLinkDown(name, errors.New("dial tcp: no route to host"))
+	SetLinkDown(name, errors.New("dial tcp: i/o timeout"))
+	s := LinkStates()[name]
+	if s.Up || s.Drops != 1 {
+		t.Errorf("repeated failures should count one drop: %+v", s)
+	}
+	if s.LastError != "dial tcp: i/o timeout" {
+		t.Errorf("LastError = %q, want the latest error", s.LastError)
+	}
+
+	SetLinkUp(name)
+	SetLinkUp(name)
+	if s := LinkStates()[name]; !s.Up || s.Drops != 1 {
+		t.Errorf("after recovery: %+v", s)
+	}
+}
@@ -68,7 +68,7 @@ func (c *MaixCamChannel) Start(ctx context.Context) error {
 		"port": c.config.Port,
 	})
 
-	go c.acceptConnections()
+	c.Go(c.acceptConnections)
 
 	return nil
 }
@@ -100,7 +100,7 @@ func (c *MaixCamChannel) acceptConnections() {
 			c.clients[conn] = true
 			c.clientsMux.Unlock()
 
-			go c.handleConnection(conn)
+			c.Go(func() { c.handleConnection(conn) })
 		}
 	}
 }
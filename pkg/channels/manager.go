@@ -12,6 +12,8 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -21,8 +23,11 @@ import (
 	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/health"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/moderation"
+	"github.com/sipeed/picoclaw/pkg/scan"
 )
 
 const (
@@ -86,6 +91,9 @@ type Manager struct {
 	placeholders  sync.Map // "channel:chatID" → placeholderID (string)
 	typingStops   sync.Map // "channel:chatID" → func()
 	reactionUndos sync.Map // "channel:chatID" → reactionEntry
+	moderation    *moderation.Policy
+	scan          *scan.Policy
+	headless      bool // started without channels; see NewHeadlessManager
 }
 
 type asyncTask struct {
@@ -114,8 +122,10 @@ func (m *Manager) RecordReactionUndo(channel, chatID string, undo func()) {
 }
 
 // preSend handles typing stop, reaction undo, and placeholder editing before sending a message.
-// Returns true if the message was edited into a placeholder (skip Send).
-func (m *Manager) preSend(ctx context.Context, name string, msg bus.OutboundMessage, ch Channel) bool {
+// Returns true if the message was edited into a placeholder (skip Send). A
+// message with a card is always sent; its text goes into the placeholder
+// instead and is cleared from msg.
+func (m *Manager) preSend(ctx context.Context, name string, msg *bus.OutboundMessage, ch Channel) bool {
 	key := name + ":" + msg.ChatID
 
 	// 1. Stop typing
@@ -136,6 +146,18 @@ func (m *Manager) preSend(ctx context.Context, name string, msg bus.OutboundMess
 	if v, loaded := m.placeholders.LoadAndDelete(key); loaded {
 		if entry, ok := v.(placeholderEntry); ok && entry.id != "" {
 			if editor, ok := ch.(MessageEditor); ok {
+				if msg.Card != nil {
+					// A card needs a message of its own: the placeholder
+					// takes the text, or the options when there is none.
+					text := msg.Content
+					if text == "" {
+						text = msg.Card.PlainText()
+					}
+					if err := editor.EditMessage(ctx, msg.ChatID, entry.id, text); err == nil {
+						msg.Content = ""
+					}
+					return false
+				}
 				if err := editor.EditMessage(ctx, msg.ChatID, entry.id, msg.Content); err == nil {
 					return true // edited successfully, skip Send
 				}
@@ -156,6 +178,18 @@ func NewManager(cfg *config.Config, messageBus *bus.MessageBus, store media.Medi
 		mediaStore: store,
 	}
 
+	policy, err := moderation.NewPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m.moderation = policy
+
+	scanPolicy, err := scan.NewPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	m.scan = scanPolicy
+
 	if err := m.initChannels(); err != nil {
 		return nil, err
 	}
@@ -163,6 +197,21 @@ func NewManager(cfg *config.Config, messageBus *bus.MessageBus, store media.Medi
 	return m, nil
 }
 
+// NewHeadlessManager returns a manager that starts no channels. It still
+// serves the shared HTTP server (health endpoints and extra handlers) and
+// drains the outbound bus, reporting messages as undeliverable, so cron and
+// heartbeat output never blocks its publishers.
+func NewHeadlessManager(cfg *config.Config, messageBus *bus.MessageBus, store media.MediaStore) *Manager {
+	return &Manager{
+		channels:   make(map[string]Channel),
+		workers:    make(map[string]*channelWorker),
+		bus:        messageBus,
+		config:     cfg,
+		mediaStore: store,
+		headless:   true,
+	}
+}
+
 // initChannel is a helper that looks up a factory by name and creates the channel.
 func (m *Manager) initChannel(name, displayName string) {
 	f, ok := getFactory(name)
@@ -192,6 +241,30 @@ func (m *Manager) initChannel(name, displayName string) {
 		if setter, ok := ch.(interface{ SetPlaceholderRecorder(r PlaceholderRecorder) }); ok {
 			setter.SetPlaceholderRecorder(m)
 		}
+		// Inject the moderation policy so BaseChannel.HandleMessage screens input
+		if setter, ok := ch.(interface{ SetModeration(p *moderation.Policy) }); ok {
+			setter.SetModeration(m.moderation)
+		}
+		// Inject the scan policy so BaseChannel.HandleMessage checks attachments
+		if setter, ok := ch.(interface{ SetScanPolicy(p *scan.Policy) }); ok {
+			setter.SetScanPolicy(m.scan)
+		}
+		// Inject the locale of canned messages (placeholders, moderation replies)
+		if setter, ok := ch.(interface{ SetLanguage(lang string) }); ok {
+			setter.SetLanguage(m.config.Gateway.LanguageFor(ch.Name()))
+		}
+		// Inject quiet hours; a bad setting is logged and leaves the channel always on
+		if setter, ok := ch.(interface{ SetQuietHours(q *QuietHours) }); ok {
+			q, err := NewQuietHours(m.config.Gateway.QuietHoursFor(ch.Name()))
+			if err != nil {
+				logger.ErrorCF("channels", "Ignoring invalid quiet hours", map[string]any{
+					"channel": displayName,
+					"error":   err.Error(),
+				})
+			} else if q != nil {
+				setter.SetQuietHours(q)
+			}
+		}
 		// Inject owner reference so BaseChannel.HandleMessage can auto-trigger typing/reaction
 		if setter, ok := ch.(interface{ SetOwner(ch Channel) }); ok {
 			setter.SetOwner(ch)
@@ -311,11 +384,17 @@ func (m *Manager) SetupHTTPServer(addr string, healthServer *health.Server) {
 	}
 }
 
+// HandleHTTP registers an extra handler on the shared HTTP server. It must
+// be called after SetupHTTPServer and before StartAll.
+func (m *Manager) HandleHTTP(pattern string, handler http.Handler) {
+	m.mux.Handle(pattern, handler)
+}
+
 func (m *Manager) StartAll(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if len(m.channels) == 0 {
+	if len(m.channels) == 0 && !m.headless {
 		logger.WarnC("channels", "No channels enabled")
 		return errors.New("no channels enabled")
 	}
@@ -329,7 +408,13 @@ func (m *Manager) StartAll(ctx context.Context) error {
 		logger.InfoCF("channels", "Starting channel", map[string]any{
 			"channel": name,
 		})
-		if err := channel.Start(ctx); err != nil {
+		// The supervisor guards Start and the channel's receive loops; the
+		// manager keeps using the channel itself so its optional interfaces
+		// stay visible.
+		restart := m.config.Gateway.ChannelRestart
+		supervised := NewSupervisedChannel(channel,
+			time.Duration(restart.DelaySeconds)*time.Second, restart.MaxRestarts)
+		if err := supervised.Start(ctx); err != nil {
 			logger.ErrorCF("channels", "Failed to start channel", map[string]any{
 				"channel": name,
 				"error":   err.Error(),
@@ -392,6 +477,16 @@ func (m *Manager) StopAll(ctx context.Context) error {
 		m.dispatchTask = nil
 	}
 
+	// Drop messages held for quiet hours so no release races the queue close
+	for _, w := range m.workers {
+		if w == nil {
+			continue
+		}
+		if q, ok := w.ch.(interface{ quietHoursConfig() *QuietHours }); ok && q.quietHoursConfig() != nil {
+			q.quietHoursConfig().Stop()
+		}
+	}
+
 	// Close all worker queues and wait for them to drain
 	for _, w := range m.workers {
 		if w != nil {
@@ -461,17 +556,27 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 			if !ok {
 				return
 			}
+			if q := activeQuietHours(w.ch); q != nil {
+				if !msg.Partial { // the complete reply is held instead
+					report := msg.OnDelivery
+					msg.OnDelivery = nil // the sender hears now that the message is queued
+					held := q.holdOutbound(func() { requeue(ctx, w, msg) })
+					reportHeld(name, msg.Channel, msg.ChatID, report, held)
+				}
+				continue
+			}
+			if msg.Partial {
+				m.streamPartial(ctx, name, w, msg)
+				continue
+			}
+			msg = m.moderateOutbound(ctx, name, msg)
+			msg = prepareCard(w.ch, msg)
 			maxLen := 0
 			if mlp, ok := w.ch.(MessageLengthProvider); ok {
 				maxLen = mlp.MaxMessageLength()
 			}
 			if maxLen > 0 && len([]rune(msg.Content)) > maxLen {
-				chunks := SplitMessage(msg.Content, maxLen)
-				for _, chunk := range chunks {
-					chunkMsg := msg
-					chunkMsg.Content = chunk
-					m.sendWithRetry(ctx, name, w, chunkMsg)
-				}
+				m.sendChunks(ctx, name, w, msg, SplitMessage(msg.Content, maxLen))
 			} else {
 				m.sendWithRetry(ctx, name, w, msg)
 			}
@@ -481,6 +586,178 @@ func (m *Manager) runWorker(ctx context.Context, name string, w *channelWorker)
 	}
 }
 
+// sendChunks sends the parts of a message that was too long for the
+// channel, reporting one delivery status for all of them: failed if any part
+// failed.
+func (m *Manager) sendChunks(
+	ctx context.Context,
+	name string,
+	w *channelWorker,
+	msg bus.OutboundMessage,
+	chunks []string,
+) {
+	var failed error
+	for i, chunk := range chunks {
+		chunkMsg := msg
+		chunkMsg.Content = chunk
+		if i < len(chunks)-1 {
+			chunkMsg.Card = nil // the buttons go with the last chunk
+		}
+		if msg.OnDelivery != nil {
+			chunkMsg.OnDelivery = func(s bus.DeliveryStatus) {
+				if s.State == bus.DeliveryFailed && failed == nil {
+					failed = fmt.Errorf("part %d of %d: %s", i+1, len(chunks), s.Error)
+				}
+			}
+		}
+		m.sendWithRetry(ctx, name, w, chunkMsg)
+	}
+	reportDelivery(msg, failed)
+}
+
+// streamPartial shows the text so far of a reply that is still being
+// written by editing the chat's placeholder, sending one first if there is
+// none. Partial messages are dropped on channels that cannot edit messages
+// or have no placeholder, and when outbound moderation is on, since the
+// edits would bypass it. The complete reply then edits the placeholder as
+// usual in preSend.
+func (m *Manager) streamPartial(ctx context.Context, name string, w *channelWorker, msg bus.OutboundMessage) {
+	editor, ok := w.ch.(MessageEditor)
+	if !ok || (m.moderation != nil && m.moderation.ChecksOutbound()) {
+		return
+	}
+
+	key := name + ":" + msg.ChatID
+	var placeholderID string
+	if v, ok := m.placeholders.Load(key); ok {
+		if entry, ok := v.(placeholderEntry); ok {
+			placeholderID = entry.id
+		}
+	} else if pc, ok := w.ch.(PlaceholderCapable); ok {
+		if err := w.limiter.Wait(ctx); err != nil {
+			return
+		}
+		id, err := pc.SendPlaceholder(ctx, msg.ChatID)
+		if err != nil {
+			logger.DebugCF("channels", "Streaming placeholder failed", map[string]any{
+				"channel": name,
+				"chat_id": msg.ChatID,
+				"error":   err.Error(),
+			})
+			return
+		}
+		if id != "" {
+			m.RecordPlaceholder(name, msg.ChatID, id)
+			placeholderID = id
+		}
+	}
+	if placeholderID == "" {
+		return
+	}
+
+	content := msg.Content
+	if mlp, ok := w.ch.(MessageLengthProvider); ok {
+		if maxLen := mlp.MaxMessageLength(); maxLen > 1 && len([]rune(content)) > maxLen {
+			content = string([]rune(content)[:maxLen-1]) + "…"
+		}
+	}
+	if err := w.limiter.Wait(ctx); err != nil {
+		return
+	}
+	if err := editor.EditMessage(ctx, msg.ChatID, placeholderID, content); err != nil {
+		logger.DebugCF("channels", "Streaming edit failed", map[string]any{
+			"channel": name,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// prepareCard moves a card block written into the text to msg.Card and, for
+// channels that are not CardSenders, appends the card as numbered options.
+func prepareCard(ch Channel, msg bus.OutboundMessage) bus.OutboundMessage {
+	if msg.Card == nil {
+		msg.Content, msg.Card = bus.ExtractCard(msg.Content)
+	}
+	if msg.Card == nil {
+		return msg
+	}
+	if _, ok := ch.(CardSender); ok {
+		return msg
+	}
+	options := msg.Card.PlainText()
+	if msg.Content != "" {
+		options = msg.Content + "\n\n" + options
+	}
+	msg.Content, msg.Card = options, nil
+	return msg
+}
+
+// activeQuietHours returns the quiet hours of a channel built on BaseChannel
+// when they are in effect, and nil otherwise.
+func activeQuietHours(ch Channel) *QuietHours {
+	if q, ok := ch.(interface{ isInQuietHours() bool }); ok && q.isInQuietHours() {
+		return ch.(interface{ quietHoursConfig() *QuietHours }).quietHoursConfig()
+	}
+	return nil
+}
+
+// requeue puts a held message back on the worker's queue.
+func requeue(ctx context.Context, w *channelWorker, msg bus.OutboundMessage) {
+	select {
+	case w.queue <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// reportHeld tells the sender of a message arriving during quiet hours that
+// it is queued, or that it failed because the queue is full. Senders waiting
+// on delivery would otherwise time out and retry into duplicates.
+func reportHeld(name, channel, chatID string, report func(bus.DeliveryStatus), held bool) {
+	status := bus.DeliveryStatus{State: bus.DeliveryQueued, Channel: channel, ChatID: chatID}
+	if !held {
+		logger.WarnCF("channels", "Quiet hours queue is full, dropping outbound message", map[string]any{
+			"channel": name,
+			"chat_id": chatID,
+		})
+		status.State = bus.DeliveryFailed
+		status.Error = "quiet hours queue is full"
+	}
+	if report != nil {
+		report(status)
+	}
+}
+
+// moderateOutbound replaces a reply the moderation policy blocks with the
+// canned response. Moderation errors let the reply through.
+func (m *Manager) moderateOutbound(ctx context.Context, name string, msg bus.OutboundMessage) bus.OutboundMessage {
+	if m.moderation == nil {
+		return msg
+	}
+	verdict, err := m.moderation.Moderator.CheckOutbound(ctx, msg)
+	if err != nil {
+		logger.WarnCF("channels", "Outbound moderation failed; sending unchecked", map[string]any{
+			"channel": name,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+		return msg
+	}
+	if verdict.Allowed {
+		return msg
+	}
+	logger.WarnCF("channels", "Outbound message blocked by moderation", map[string]any{
+		"channel": name,
+		"chat_id": msg.ChatID,
+		"reason":  verdict.Reason,
+	})
+	msg.Content = m.moderation.OutboundReply
+	if msg.Content == "" {
+		msg.Content = i18n.T(m.config.Gateway.LanguageFor(msg.Channel), i18n.ModerationOutbound)
+	}
+	return msg
+}
+
 // sendWithRetry sends a message through the channel with rate limiting and
 // retry logic. It classifies errors to determine the retry strategy:
 //   - ErrNotRunning / ErrSendFailed: permanent, no retry
@@ -490,18 +767,26 @@ func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWork
 	// Rate limit: wait for token
 	if err := w.limiter.Wait(ctx); err != nil {
 		// ctx canceled, shutting down
+		reportDelivery(msg, err)
 		return
 	}
 
 	// Pre-send: stop typing and try to edit placeholder
-	if m.preSend(ctx, name, msg, w.ch) {
+	if m.preSend(ctx, name, &msg, w.ch) {
+		reportDelivery(msg, nil)
 		return // placeholder was edited successfully, skip Send
 	}
 
+	send := w.ch.Send
+	if cs, ok := w.ch.(CardSender); ok && msg.Card != nil {
+		send = cs.SendCard
+	}
+
 	var lastErr error
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		lastErr = w.ch.Send(ctx, msg)
+		lastErr = send(ctx, msg)
 		if lastErr == nil {
+			reportDelivery(msg, nil)
 			return
 		}
 
@@ -521,6 +806,7 @@ func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWork
 			case <-time.After(rateLimitDelay):
 				continue
 			case <-ctx.Done():
+				reportDelivery(msg, lastErr)
 				return
 			}
 		}
@@ -530,6 +816,7 @@ func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWork
 		select {
 		case <-time.After(backoff):
 		case <-ctx.Done():
+			reportDelivery(msg, lastErr)
 			return
 		}
 	}
@@ -541,6 +828,29 @@ func (m *Manager) sendWithRetry(ctx context.Context, name string, w *channelWork
 		"error":   lastErr.Error(),
 		"retries": maxRetries,
 	})
+	reportDelivery(msg, lastErr)
+}
+
+// reportDelivery tells the sender, if it asked, whether the platform
+// accepted msg.
+func reportDelivery(msg bus.OutboundMessage, err error) {
+	if msg.OnDelivery == nil {
+		return
+	}
+	status := bus.DeliveryStatus{State: bus.DeliveryAccepted, Channel: msg.Channel, ChatID: msg.ChatID}
+	if err != nil {
+		status.State = bus.DeliveryFailed
+		status.Error = err.Error()
+	}
+	msg.OnDelivery(status)
+}
+
+// reportMediaDelivery is reportDelivery for media messages.
+func reportMediaDelivery(msg bus.OutboundMediaMessage, err error) {
+	if msg.OnDelivery == nil {
+		return
+	}
+	reportDelivery(bus.OutboundMessage{Channel: msg.Channel, ChatID: msg.ChatID, OnDelivery: msg.OnDelivery}, err)
 }
 
 func dispatchLoop[M any](
@@ -549,6 +859,7 @@ func dispatchLoop[M any](
 	subscribe func(context.Context) (M, bool),
 	getChannel func(M) string,
 	enqueue func(context.Context, *channelWorker, M) bool,
+	dropped func(M, error),
 	startMsg, stopMsg, unknownMsg, noWorkerMsg string,
 ) {
 	logger.InfoC("channels", startMsg)
@@ -574,6 +885,7 @@ func dispatchLoop[M any](
 
 		if !exists {
 			logger.WarnCF("channels", unknownMsg, map[string]any{"channel": channel})
+			dropped(msg, fmt.Errorf("channel %s not found", channel))
 			continue
 		}
 
@@ -583,6 +895,7 @@ func dispatchLoop[M any](
 			}
 		} else if exists {
 			logger.WarnCF("channels", noWorkerMsg, map[string]any{"channel": channel})
+			dropped(msg, fmt.Errorf("channel %s has no active worker", channel))
 		}
 	}
 }
@@ -600,6 +913,7 @@ func (m *Manager) dispatchOutbound(ctx context.Context) {
 				return false
 			}
 		},
+		reportDelivery,
 		"Outbound dispatcher started",
 		"Outbound dispatcher stopped",
 		"Unknown channel for outbound message",
@@ -620,6 +934,7 @@ func (m *Manager) dispatchOutboundMedia(ctx context.Context) {
 				return false
 			}
 		},
+		reportMediaDelivery,
 		"Outbound media dispatcher started",
 		"Outbound media dispatcher stopped",
 		"Unknown channel for outbound media message",
@@ -636,6 +951,18 @@ func (m *Manager) runMediaWorker(ctx context.Context, name string, w *channelWor
 			if !ok {
 				return
 			}
+			if q := activeQuietHours(w.ch); q != nil {
+				report := msg.OnDelivery
+				msg.OnDelivery = nil // the sender hears now that the message is queued
+				held := q.holdOutbound(func() {
+					select {
+					case w.mediaQueue <- msg:
+					case <-ctx.Done():
+					}
+				})
+				reportHeld(name, msg.Channel, msg.ChatID, report, held)
+				continue
+			}
 			m.sendMediaWithRetry(ctx, name, w, msg)
 		case <-ctx.Done():
 			return
@@ -651,11 +978,13 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 		logger.DebugCF("channels", "Channel does not support MediaSender, skipping media", map[string]any{
 			"channel": name,
 		})
+		reportMediaDelivery(msg, fmt.Errorf("channel %s does not support media", name))
 		return
 	}
 
 	// Rate limit: wait for token
 	if err := w.limiter.Wait(ctx); err != nil {
+		reportMediaDelivery(msg, err)
 		return
 	}
 
@@ -663,6 +992,7 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		lastErr = ms.SendMedia(ctx, msg)
 		if lastErr == nil {
+			reportMediaDelivery(msg, nil)
 			return
 		}
 
@@ -682,6 +1012,7 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 			case <-time.After(rateLimitDelay):
 				continue
 			case <-ctx.Done():
+				reportMediaDelivery(msg, lastErr)
 				return
 			}
 		}
@@ -691,6 +1022,7 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 		select {
 		case <-time.After(backoff):
 		case <-ctx.Done():
+			reportMediaDelivery(msg, lastErr)
 			return
 		}
 	}
@@ -702,6 +1034,7 @@ func (m *Manager) sendMediaWithRetry(ctx context.Context, name string, w *channe
 		"error":   lastErr.Error(),
 		"retries": maxRetries,
 	})
+	reportMediaDelivery(msg, lastErr)
 }
 
 // runTTLJanitor periodically scans the typingStops and placeholders maps
@@ -755,6 +1088,26 @@ func (m *Manager) GetChannel(name string) (Channel, bool) {
 	return channel, ok
 }
 
+// Responsive reports whether the manager's lock can be taken within
+// timeout. A manager that stays locked longer is wedged: no message gets
+// sent and no channel can be started or stopped. It polls instead of
+// blocking, so checking a wedged manager leaves no goroutine behind.
+func (m *Manager) Responsive(timeout time.Duration) bool {
+	const poll = 10 * time.Millisecond
+	deadline := time.Now().Add(timeout)
+	for {
+		if m.mu.TryRLock() {
+			m.mu.RUnlock()
+			return true
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			return false
+		}
+		time.Sleep(min(poll, left))
+	}
+}
+
 func (m *Manager) GetStatus() map[string]any {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -769,6 +1122,30 @@ func (m *Manager) GetStatus() map[string]any {
 	return status
 }
 
+// ChannelStatus is a channel's state as shown on the gateway status page.
+type ChannelStatus struct {
+	Name          string
+	Running       bool
+	LastMessageAt time.Time // zero if no message was received yet
+}
+
+// ChannelStatuses returns the state of every enabled channel, sorted by name.
+func (m *Manager) ChannelStatuses() []ChannelStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	statuses := make([]ChannelStatus, 0, len(m.channels))
+	for name, ch := range m.channels {
+		st := ChannelStatus{Name: name, Running: ch.IsRunning()}
+		if lm, ok := ch.(interface{ LastMessageAt() time.Time }); ok {
+			st.LastMessageAt = lm.LastMessageAt()
+		}
+		statuses = append(statuses, st)
+	}
+	slices.SortFunc(statuses, func(a, b ChannelStatus) int { return strings.Compare(a.Name, b.Name) })
+	return statuses
+}
+
 func (m *Manager) GetEnabledChannels() []string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
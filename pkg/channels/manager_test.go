@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 // mockChannel is a test double that delegates Send to a configurable function.
@@ -59,6 +61,156 @@ func TestSendWithRetry_Success(t *testing.T) {
 	}
 }
 
+func TestSendWithRetry_ReportsDelivery(t *testing.T) {
+	tests := []struct {
+		name      string
+		sendErr   error
+		wantState bus.DeliveryState
+	}{
+		{name: "accepted", sendErr: nil, wantState: bus.DeliveryAccepted},
+		{name: "failed", sendErr: fmt.Errorf("chat not found: %w", ErrSendFailed), wantState: bus.DeliveryFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager()
+			w := &channelWorker{
+				ch: &mockChannel{
+					sendFn: func(_ context.Context, _ bus.OutboundMessage) error { return tt.sendErr },
+				},
+				limiter: rate.NewLimiter(rate.Inf, 1),
+			}
+
+			var got []bus.DeliveryStatus
+			msg := bus.OutboundMessage{
+				Channel:    "test",
+				ChatID:     "1",
+				Content:    "hello",
+				OnDelivery: func(s bus.DeliveryStatus) { got = append(got, s) },
+			}
+			m.sendWithRetry(context.Background(), "test", w, msg)
+
+			if len(got) != 1 {
+				t.Fatalf("expected 1 delivery report, got %d", len(got))
+			}
+			if got[0].State != tt.wantState || got[0].ChatID != "1" {
+				t.Fatalf("delivery = %+v, want state %s", got[0], tt.wantState)
+			}
+			if tt.sendErr != nil && got[0].Error == "" {
+				t.Fatal("expected failure reason in delivery report")
+			}
+		})
+	}
+}
+
+func TestSendChunks_ReportsOnce(t *testing.T) {
+	m := newTestManager()
+	var sent int
+	w := &channelWorker{
+		ch: &mockChannel{
+			sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+				sent++
+				if msg.Content == "two" {
+					return fmt.Errorf("too long: %w", ErrSendFailed)
+				}
+				return nil
+			},
+		},
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+
+	var got []bus.DeliveryStatus
+	msg := bus.OutboundMessage{
+		Channel:    "test",
+		ChatID:     "1",
+		OnDelivery: func(s bus.DeliveryStatus) { got = append(got, s) },
+	}
+	m.sendChunks(context.Background(), "test", w, msg, []string{"one", "two", "three"})
+
+	if sent != 3 {
+		t.Fatalf("sent %d chunks, want 3", sent)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 delivery report, got %d", len(got))
+	}
+	if got[0].State != bus.DeliveryFailed || !strings.Contains(got[0].Error, "part 2 of 3") {
+		t.Fatalf("delivery = %+v, want failed in part 2", got[0])
+	}
+}
+
+func TestDispatchOutbound_UnknownChannelReportsFailure(t *testing.T) {
+	m := newTestManager()
+	m.bus = bus.NewMessageBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.dispatchOutbound(ctx)
+
+	done := make(chan bus.DeliveryStatus, 1)
+	m.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel:    "nowhere",
+		ChatID:     "1",
+		Content:    "hello",
+		OnDelivery: func(s bus.DeliveryStatus) { done <- s },
+	})
+
+	select {
+	case s := <-done:
+		if s.State != bus.DeliveryFailed {
+			t.Fatalf("delivery state = %s, want failed", s.State)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no delivery report for unknown channel")
+	}
+}
+
+func TestHeadlessManager_StartsAndDrainsOutbound(t *testing.T) {
+	m := NewHeadlessManager(&config.Config{}, bus.NewMessageBus(), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := m.StartAll(ctx); err != nil {
+		t.Fatalf("StartAll() error = %v, want nil without channels", err)
+	}
+	defer m.StopAll(context.Background())
+
+	done := make(chan bus.DeliveryStatus, 1)
+	m.bus.PublishOutbound(ctx, bus.OutboundMessage{
+		Channel:    "telegram",
+		ChatID:     "1",
+		Content:    "cron output",
+		OnDelivery: func(s bus.DeliveryStatus) { done <- s },
+	})
+
+	select {
+	case s := <-done:
+		if s.State != bus.DeliveryFailed {
+			t.Fatalf("delivery state = %s, want failed", s.State)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("headless manager did not drain the outbound bus")
+	}
+}
+
+func TestStartAll_NoChannelsFails(t *testing.T) {
+	m := newTestManager()
+	if err := m.StartAll(context.Background()); err == nil {
+		t.Error("StartAll() without channels succeeded, want error")
+	}
+}
+
+func TestResponsive(t *testing.T) {
+	m := newTestManager()
+	if !m.Responsive(time.Second) {
+		t.Error("Responsive() = false for an idle manager")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Responsive(10 * time.Millisecond) {
+		t.Error("Responsive() = true while the manager is locked")
+	}
+}
+
 func TestSendWithRetry_TemporaryThenSuccess(t *testing.T) {
 	m := newTestManager()
 	var callCount int
@@ -427,6 +579,27 @@ func (m *mockMessageEditor) EditMessage(ctx context.Context, chatID, messageID,
 	return m.editFn(ctx, chatID, messageID, content)
 }
 
+func TestPreSend_CardMovesTextIntoPlaceholder(t *testing.T) {
+	m := newTestManager()
+	var edited string
+	ch := &mockMessageEditor{
+		editFn: func(_ context.Context, _, _, content string) error {
+			edited = content
+			return nil
+		},
+	}
+	m.RecordPlaceholder("test", "chat1", "ph_id")
+
+	card := &bus.Card{Buttons: []bus.CardButton{{Label: "Yes"}, {Label: "No"}}}
+	msg := bus.OutboundMessage{Channel: "test", ChatID: "chat1", Content: "Book it?", Card: card}
+	if m.preSend(context.Background(), "test", &msg, ch) {
+		t.Fatal("preSend() = true, want the card to be sent")
+	}
+	if edited != "Book it?" || msg.Content != "" {
+		t.Errorf("placeholder = %q, content = %q; want the text in the placeholder only", edited, msg.Content)
+	}
+}
+
 func TestPreSend_PlaceholderEditSuccess(t *testing.T) {
 	m := newTestManager()
 	var sendCalled bool
@@ -458,7 +631,7 @@ func TestPreSend_PlaceholderEditSuccess(t *testing.T) {
 	m.RecordPlaceholder("test", "123", "456")
 
 	msg := bus.OutboundMessage{Channel: "test", ChatID: "123", Content: "hello"}
-	edited := m.preSend(context.Background(), "test", msg, ch)
+	edited := m.preSend(context.Background(), "test", &msg, ch)
 
 	if !edited {
 		t.Fatal("expected preSend to return true (placeholder edited)")
@@ -488,7 +661,7 @@ func TestPreSend_PlaceholderEditFails_FallsThrough(t *testing.T) {
 	m.RecordPlaceholder("test", "123", "456")
 
 	msg := bus.OutboundMessage{Channel: "test", ChatID: "123", Content: "hello"}
-	edited := m.preSend(context.Background(), "test", msg, ch)
+	edited := m.preSend(context.Background(), "test", &msg, ch)
 
 	if edited {
 		t.Fatal("expected preSend to return false when edit fails")
@@ -510,7 +683,7 @@ func TestPreSend_TypingStopCalled(t *testing.T) {
 	})
 
 	msg := bus.OutboundMessage{Channel: "test", ChatID: "123", Content: "hello"}
-	m.preSend(context.Background(), "test", msg, ch)
+	m.preSend(context.Background(), "test", &msg, ch)
 
 	if !stopCalled {
 		t.Fatal("expected typing stop func to be called")
@@ -527,7 +700,7 @@ func TestPreSend_NoRegisteredState(t *testing.T) {
 	}
 
 	msg := bus.OutboundMessage{Channel: "test", ChatID: "123", Content: "hello"}
-	edited := m.preSend(context.Background(), "test", msg, ch)
+	edited := m.preSend(context.Background(), "test", &msg, ch)
 
 	if edited {
 		t.Fatal("expected preSend to return false with no registered state")
@@ -557,7 +730,7 @@ func TestPreSend_TypingAndPlaceholder(t *testing.T) {
 	m.RecordPlaceholder("test", "123", "456")
 
 	msg := bus.OutboundMessage{Channel: "test", ChatID: "123", Content: "hello"}
-	edited := m.preSend(context.Background(), "test", msg, ch)
+	edited := m.preSend(context.Background(), "test", &msg, ch)
 
 	if !stopCalled {
 		t.Fatal("expected typing stop to be called")
@@ -787,7 +960,7 @@ func TestPreSendStillWorksWithWrappedTypes(t *testing.T) {
 	m.RecordPlaceholder("test", "chat1", "ph_id")
 
 	msg := bus.OutboundMessage{Channel: "test", ChatID: "chat1", Content: "response"}
-	edited := m.preSend(context.Background(), "test", msg, ch)
+	edited := m.preSend(context.Background(), "test", &msg, ch)
 
 	if !stopCalled {
 		t.Fatal("expected typing stop to be called via wrapped type")
@@ -860,3 +1033,95 @@ func TestBuildMediaScope_WithMessageID(t *testing.T) {
 		t.Fatalf("expected %s, got %s", expected, scope)
 	}
 }
+
+// mockStreamingChannel can send a placeholder and edit it.
+type mockStreamingChannel struct {
+	mockMessageEditor
+	placeholders int
+}
+
+func (m *mockStreamingChannel) SendPlaceholder(ctx context.Context, chatID string) (string, error) {
+	m.placeholders++
+	return "ph-1", nil
+}
+
+func TestStreamPartial_EditsPlaceholder(t *testing.T) {
+	m := newTestManager()
+	var edits []string
+	var sent int
+	ch := &mockStreamingChannel{
+		mockMessageEditor: mockMessageEditor{
+			mockChannel: mockChannel{
+				sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+					sent++
+					return nil
+				},
+			},
+			editFn: func(_ context.Context, _, messageID, content string) error {
+				edits = append(edits, messageID+":"+content)
+				return nil
+			},
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+	ctx := context.Background()
+
+	m.streamPartial(ctx, "test", w, bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "Hel", Partial: true})
+	m.streamPartial(ctx, "test", w, bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "Hello", Partial: true})
+	m.sendWithRetry(ctx, "test", w, bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "Hello world"})
+
+	if ch.placeholders != 1 {
+		t.Errorf("sent %d placeholders, want 1", ch.placeholders)
+	}
+	want := []string{"ph-1:Hel", "ph-1:Hello", "ph-1:Hello world"}
+	if fmt.Sprint(edits) != fmt.Sprint(want) {
+		t.Errorf("edits = %v, want %v", edits, want)
+	}
+	if sent != 0 {
+		t.Errorf("Send called %d times, want the final reply edited in", sent)
+	}
+}
+
+func TestStreamPartial_NewManagerWithoutModeration(t *testing.T) {
+	m, err := NewManager(&config.Config{}, bus.NewMessageBus(), nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	var edits int
+	ch := &mockStreamingChannel{
+		mockMessageEditor: mockMessageEditor{
+			editFn: func(_ context.Context, _, _, _ string) error {
+				edits++
+				return nil
+			},
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	m.streamPartial(context.Background(), "test", w,
+		bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "Hel", Partial: true})
+
+	if ch.placeholders != 1 || edits != 1 {
+		t.Errorf("placeholders = %d, edits = %d, want the partial streamed with moderation off",
+			ch.placeholders, edits)
+	}
+}
+
+func TestStreamPartial_DroppedWithoutEditor(t *testing.T) {
+	m := newTestManager()
+	var sent int
+	ch := &mockChannel{
+		sendFn: func(_ context.Context, _ bus.OutboundMessage) error {
+			sent++
+			return nil
+		},
+	}
+	w := &channelWorker{ch: ch, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+	m.streamPartial(context.Background(), "test", w,
+		bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "Hel", Partial: true})
+
+	if sent != 0 {
+		t.Errorf("Send called %d times for a partial message, want 0", sent)
+	}
+}
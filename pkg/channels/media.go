@@ -2,8 +2,17 @@ package channels
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
 
 	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
 // MediaSender is an optional interface for channels that can send
@@ -13,3 +22,109 @@ import (
 type MediaSender interface {
 	SendMedia(ctx context.Context, msg bus.OutboundMediaMessage) error
 }
+
+// WithMediaDownload sets which kinds of inbound media a channel downloads.
+func WithMediaDownload(m config.MediaConfig) BaseChannelOption {
+	return func(c *BaseChannel) { c.mediaDownload = m }
+}
+
+// WithDefaultMediaDownload sets the kinds a channel downloads when
+// media.download is not configured, for channels that have always left some
+// kinds, such as video, alone.
+func WithDefaultMediaDownload(kinds ...string) BaseChannelOption {
+	return func(c *BaseChannel) { c.mediaDefaults = kinds }
+}
+
+// ShouldDownloadMedia reports whether inbound media of kind (one of
+// config.MediaKinds) should be downloaded. When it returns false the
+// channel passes SkippedMedia to the agent instead.
+func (c *BaseChannel) ShouldDownloadMedia(kind string) bool {
+	media := c.mediaDownload
+	if media.Download == nil && c.mediaDefaults != nil {
+		media.Download = c.mediaDefaults
+	}
+	if media.Downloads(kind) {
+		return true
+	}
+	logger.DebugCF("channels", "Skipping media download", map[string]any{
+		"channel": c.name,
+		"kind":    kind,
+	})
+	return false
+}
+
+// SkippedMedia is the placeholder for media that was not downloaded, e.g.
+// "[video: not downloaded]" or "[file: report.pdf, not downloaded]".
+func SkippedMedia(kind, name string) string {
+	if name == "" {
+		return fmt.Sprintf("[%s: not downloaded]", kind)
+	}
+	return fmt.Sprintf("[%s: %s, not downloaded]", kind, name)
+}
+
+var (
+	imageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp", ".heic"}
+	videoExtensions = []string{".mp4", ".mov", ".webm", ".mkv", ".avi", ".m4v", ".3gp"}
+)
+
+// MediaKind classifies an attachment by MIME type, or by file extension
+// when the type is unknown.
+func MediaKind(filename, contentType string) string {
+	contentType = strings.ToLower(contentType)
+	ext := strings.ToLower(filepath.Ext(filename))
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return config.MediaImage
+	case strings.HasPrefix(contentType, "video/"):
+		return config.MediaVideo
+	case utils.IsAudioFile(filename, contentType):
+		return config.MediaAudio
+	case contentType != "" && contentType != "application/octet-stream":
+	case slices.Contains(imageExtensions, ext):
+		return config.MediaImage
+	case slices.Contains(videoExtensions, ext):
+		return config.MediaVideo
+	}
+	return config.MediaFile
+}
+
+// DefaultMaxConcurrentDownloads limits parallel downloads when a channel
+// sets no media.max_concurrent_downloads.
+const DefaultMaxConcurrentDownloads = 3
+
+// MediaDownload fetches one attachment and returns its local path.
+type MediaDownload func(ctx context.Context) (string, error)
+
+// DownloadAll runs downloads concurrently, at most limit at a time, so the
+// attachments of one message do not wait on each other without throttling
+// the platform. Paths and errors are returned in the order of downloads; a
+// failed download does not stop the others. A limit of 0 or less uses
+// DefaultMaxConcurrentDownloads.
+func DownloadAll(ctx context.Context, limit int, downloads []MediaDownload) ([]string, []error) {
+	paths := make([]string, len(downloads))
+	errs := make([]error, len(downloads))
+	if len(downloads) == 1 {
+		paths[0], errs[0] = downloads[0](ctx)
+		return paths, errs
+	}
+	if limit <= 0 {
+		limit = DefaultMaxConcurrentDownloads
+	}
+
+	var g errgroup.Group
+	g.SetLimit(limit)
+	for i, download := range downloads {
+		g.Go(func() error {
+			paths[i], errs[i] = download(ctx)
+			return nil
+		})
+	}
+	g.Wait()
+	return paths, errs
+}
+
+// DownloadMedia is DownloadAll limited by the channel's
+// media.max_concurrent_downloads.
+func (c *BaseChannel) DownloadMedia(ctx context.Context, downloads []MediaDownload) ([]string, []error) {
+	return DownloadAll(ctx, c.mediaDownload.MaxConcurrentDownloads, downloads)
+}
@@ -0,0 +1,99 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestMediaKind(t *testing.T) {
+	tests := []struct {
+		filename, contentType, want string
+	}{
+		{"cat.png", "image/png", config.MediaImage},
+		{"clip.bin", "video/mp4", config.MediaVideo},
+		{"note.ogg", "", config.MediaAudio},
+		{"IMG_1.HEIC", "", config.MediaImage},
+		{"movie.mov", "application/octet-stream", config.MediaVideo},
+		{"report.pdf", "application/pdf", config.MediaFile},
+		{"fake.mp4", "application/pdf", config.MediaFile},
+	}
+	for _, tt := range tests {
+		if got := MediaKind(tt.filename, tt.contentType); got != tt.want {
+			t.Errorf("MediaKind(%q, %q) = %q, want %q", tt.filename, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestShouldDownloadMedia(t *testing.T) {
+	ch := NewBaseChannel("test", nil, nil, nil,
+		WithMediaDownload(config.MediaConfig{Download: []string{"image", "audio"}}))
+
+	if !ch.ShouldDownloadMedia(config.MediaImage) || !ch.ShouldDownloadMedia(config.MediaAudio) {
+		t.Error("expected images and audio to be downloaded")
+	}
+	if ch.ShouldDownloadMedia(config.MediaVideo) {
+		t.Error("expected video to be skipped")
+	}
+	if !NewBaseChannel("test", nil, nil, nil).ShouldDownloadMedia(config.MediaVideo) {
+		t.Error("expected everything to be downloaded by default")
+	}
+
+	defaults := NewBaseChannel("test", nil, nil, nil,
+		WithDefaultMediaDownload(config.MediaImage), WithMediaDownload(config.MediaConfig{}))
+	if !defaults.ShouldDownloadMedia(config.MediaImage) || defaults.ShouldDownloadMedia(config.MediaVideo) {
+		t.Error("expected the channel defaults to apply when download is unset")
+	}
+	configured := NewBaseChannel("test", nil, nil, nil, WithDefaultMediaDownload(config.MediaImage),
+		WithMediaDownload(config.MediaConfig{Download: []string{"video"}}))
+	if !configured.ShouldDownloadMedia(config.MediaVideo) {
+		t.Error("expected a configured download list to override the channel defaults")
+	}
+	if got := SkippedMedia(config.MediaVideo, ""); got != "[video: not downloaded]" {
+		t.Errorf("SkippedMedia() = %q", got)
+	}
+}
+
+func TestDownloadAll(t *testing.T) {
+	var running, peak atomic.Int32
+	downloads := make([]MediaDownload, 6)
+	for i := range downloads {
+		downloads[i] = func(context.Context) (string, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			// Finish in reverse so ordering by completion would be wrong.
+			time.Sleep(time.Duration(len(downloads)-i) * 5 * time.Millisecond)
+			if i == 2 {
+				return "", errors.New("boom")
+			}
+			return fmt.Sprintf("file-%d", i), nil
+		}
+	}
+
+	paths, errs := DownloadAll(context.Background(), 2, downloads)
+	for i := range downloads {
+		if i == 2 {
+			if errs[i] == nil || paths[i] != "" {
+				t.Errorf("download 2 = %q, %v; want the error", paths[i], errs[i])
+			}
+			continue
+		}
+		if want := fmt.Sprintf("file-%d", i); paths[i] != want || errs[i] != nil {
+			t.Errorf("download %d = %q, %v; want %q", i, paths[i], errs[i], want)
+		}
+	}
+	if p := peak.Load(); p != 2 {
+		t.Errorf("peak concurrency = %d, want 2", p)
+	}
+}
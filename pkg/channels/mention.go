@@ -0,0 +1,160 @@
+package channels
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Mention is a user mention in message text. Start and End are rune offsets
+// of the mention as written, e.g. "@pico_bot" or "<@1234>".
+type Mention struct {
+	Start  int
+	End    int
+	UserID string // user ID, or username on platforms that mention by name
+	All    bool   // mentions everyone in the chat
+}
+
+// MentionParser finds mentions in a platform's message text and removes the
+// bot's own mentions before the text reaches the agent.
+type MentionParser interface {
+	ParseMentions(text string) []Mention
+	StripMentions(text string, mentions []Mention, botID string) string
+}
+
+// MentionsBot reports whether mentions address botID or everyone. IDs are
+// compared case-insensitively, as Telegram usernames are.
+func MentionsBot(mentions []Mention, botID string) bool {
+	for _, m := range mentions {
+		if m.All || isBotMention(m, botID) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBotMention(m Mention, botID string) bool {
+	return botID != "" && strings.EqualFold(m.UserID, botID)
+}
+
+// stripMentions cuts the mentions of botID out of text and trims the rest.
+func stripMentions(text string, mentions []Mention, botID string) string {
+	runes := []rune(text)
+	// Cut from the end so earlier offsets stay valid.
+	sorted := slices.Clone(mentions)
+	slices.SortFunc(sorted, func(a, b Mention) int { return b.Start - a.Start })
+	for _, m := range sorted {
+		if !isBotMention(m, botID) || m.Start < 0 || m.End > len(runes) || m.Start >= m.End {
+			continue
+		}
+		runes = append(runes[:m.Start], runes[m.End:]...)
+	}
+	return strings.TrimSpace(string(runes))
+}
+
+// findMentions returns the matches of re as mentions, taking the user from
+// capture group 2 and the mention span from group 1.
+func findMentions(re *regexp.Regexp, text string) []Mention {
+	var mentions []Mention
+	for _, loc := range re.FindAllStringSubmatchIndex(text, -1) {
+		start := utf8.RuneCountInString(text[:loc[2]])
+		mentions = append(mentions, Mention{
+			Start:  start,
+			End:    start + utf8.RuneCountInString(text[loc[2]:loc[3]]),
+			UserID: text[loc[4]:loc[5]],
+		})
+	}
+	return mentions
+}
+
+var telegramMentionRe = regexp.MustCompile(`(?:^|[^\w@])(@(\w+))`)
+
+// TelegramMentionParser parses Telegram's "@username" mentions. The bot ID
+// is the bot's username.
+type TelegramMentionParser struct{}
+
+func (TelegramMentionParser) ParseMentions(text string) []Mention {
+	return findMentions(telegramMentionRe, text)
+}
+
+func (TelegramMentionParser) StripMentions(text string, mentions []Mention, botID string) string {
+	return stripMentions(text, mentions, botID)
+}
+
+var discordMentionRe = regexp.MustCompile(`(<@!?(\d+)>)`)
+
+// DiscordMentionParser parses Discord's "<@USER_ID>" mentions and the
+// "<@!USER_ID>" form used for nicknames. The bot ID is the bot's user ID.
+type DiscordMentionParser struct{}
+
+func (DiscordMentionParser) ParseMentions(text string) []Mention {
+	return findMentions(discordMentionRe, text)
+}
+
+func (DiscordMentionParser) StripMentions(text string, mentions []Mention, botID string) string {
+	return stripMentions(text, mentions, botID)
+}
+
+// LINEMentionee is an entry of a LINE message's mention metadata. Index and
+// Length are rune offsets into the message text.
+type LINEMentionee struct {
+	Index  int    `json:"index"`
+	Length int    `json:"length"`
+	Type   string `json:"type"` // "user", "all"
+	UserID string `json:"userId"`
+}
+
+// LINEMentionParser reads the mention metadata LINE sends next to the text.
+// LINE may leave out the userId when an Official Account is mentioned, so a
+// mentionee whose text contains BotName counts as the bot, and "@BotName" in
+// the text is used when the metadata does not mention the bot at all.
+type LINEMentionParser struct {
+	Mentionees []LINEMentionee
+	BotUserID  string
+	BotName    string
+}
+
+func (p LINEMentionParser) ParseMentions(text string) []Mention {
+	runes := []rune(text)
+	var mentions []Mention
+	botFound := false
+	for _, m := range p.Mentionees {
+		end := m.Index + m.Length
+		if m.Index < 0 || m.Length <= 0 || end > len(runes) {
+			continue
+		}
+		mention := Mention{Start: m.Index, End: end, UserID: m.UserID, All: m.Type == "all"}
+		if p.BotName != "" && mention.UserID != p.BotUserID &&
+			strings.Contains(string(runes[m.Index:end]), p.BotName) {
+			mention.UserID = p.BotUserID
+		}
+		if p.BotUserID != "" && mention.UserID == p.BotUserID {
+			botFound = true
+		}
+		mentions = append(mentions, mention)
+	}
+	if botFound || p.BotName == "" || p.BotUserID == "" {
+		return mentions
+	}
+
+	name := "@" + p.BotName
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], name)
+		if i < 0 {
+			break
+		}
+		start := utf8.RuneCountInString(text[:offset+i])
+		mentions = append(mentions, Mention{
+			Start:  start,
+			End:    start + utf8.RuneCountInString(name),
+			UserID: p.BotUserID,
+		})
+		offset += i + len(name)
+	}
+	return mentions
+}
+
+func (p LINEMentionParser) StripMentions(text string, mentions []Mention, botID string) string {
+	return stripMentions(text, mentions, botID)
+}
@@ -0,0 +1,116 @@
+package channels
+
+import "testing"
+
+func TestMentionParsers(t *testing.T) {
+	tests := []struct {
+		name          string
+		parser        MentionParser
+		text          string
+		botID         string
+		wantMentioned bool
+		wantStripped  string
+	}{
+		{
+			name:          "telegram mention",
+			parser:        TelegramMentionParser{},
+			text:          "@Pico_Bot what's the weather?",
+			botID:         "pico_bot",
+			wantMentioned: true,
+			wantStripped:  "what's the weather?",
+		},
+		{
+			name:          "telegram other user",
+			parser:        TelegramMentionParser{},
+			text:          "ask @alice about it",
+			botID:         "pico_bot",
+			wantMentioned: false,
+			wantStripped:  "ask @alice about it",
+		},
+		{
+			name:          "telegram email is not a mention",
+			parser:        TelegramMentionParser{},
+			text:          "mail me at me@pico_bot",
+			botID:         "pico_bot",
+			wantMentioned: false,
+			wantStripped:  "mail me at me@pico_bot",
+		},
+		{
+			name:          "discord mention and nickname mention",
+			parser:        DiscordMentionParser{},
+			text:          "<@123> hi <@!123>",
+			botID:         "123",
+			wantMentioned: true,
+			wantStripped:  "hi",
+		},
+		{
+			name:          "discord other user",
+			parser:        DiscordMentionParser{},
+			text:          "<@456> hi",
+			botID:         "123",
+			wantMentioned: false,
+			wantStripped:  "<@456> hi",
+		},
+		{
+			name: "line metadata by user ID",
+			parser: LINEMentionParser{
+				Mentionees: []LINEMentionee{{Index: 0, Length: 5, Type: "user", UserID: "Ubot"}},
+				BotUserID:  "Ubot",
+				BotName:    "Pico",
+			},
+			text:          "@Pico hello",
+			botID:         "Ubot",
+			wantMentioned: true,
+			wantStripped:  "hello",
+		},
+		{
+			name: "line metadata without user ID",
+			parser: LINEMentionParser{
+				Mentionees: []LINEMentionee{{Index: 3, Length: 4, Type: "user"}},
+				BotUserID:  "Ubot",
+				BotName:    "ピコ君",
+			},
+			text:          "やあ @ピコ君 元気",
+			botID:         "Ubot",
+			wantMentioned: true,
+			wantStripped:  "やあ  元気",
+		},
+		{
+			name:          "line display name fallback",
+			parser:        LINEMentionParser{BotUserID: "Ubot", BotName: "Pico"},
+			text:          "hey @Pico",
+			botID:         "Ubot",
+			wantMentioned: true,
+			wantStripped:  "hey",
+		},
+		{
+			name: "line mention all",
+			parser: LINEMentionParser{
+				Mentionees: []LINEMentionee{{Index: 0, Length: 4, Type: "all"}},
+				BotUserID:  "Ubot",
+			},
+			text:          "@All meeting now",
+			botID:         "Ubot",
+			wantMentioned: true,
+			wantStripped:  "@All meeting now",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mentions := tt.parser.ParseMentions(tt.text)
+			if got := MentionsBot(mentions, tt.botID); got != tt.wantMentioned {
+				t.Errorf("MentionsBot() = %v, want %v (mentions %+v)", got, tt.wantMentioned, mentions)
+			}
+			if got := tt.parser.StripMentions(tt.text, mentions, tt.botID); got != tt.wantStripped {
+				t.Errorf("StripMentions() = %q, want %q", got, tt.wantStripped)
+			}
+		})
+	}
+}
+
+func TestMentionsBot_EmptyBotID(t *testing.T) {
+	if MentionsBot([]Mention{{Start: 0, End: 1}}, "") {
+		t.Error("a mention without a user must not match an unknown bot ID")
+	}
+}
@@ -0,0 +1,116 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/moderation"
+)
+
+// keywordModerator blocks content containing "bad" and fails on "error".
+type keywordModerator struct{}
+
+func (keywordModerator) check(content string) (moderation.Verdict, error) {
+	switch {
+	case strings.Contains(content, "error"):
+		return moderation.Verdict{}, errors.New("moderation unavailable")
+	case strings.Contains(content, "bad"):
+		return moderation.Verdict{Reason: "flagged: harassment"}, nil
+	}
+	return moderation.Allow, nil
+}
+
+func (k keywordModerator) CheckInbound(_ context.Context, msg bus.InboundMessage) (moderation.Verdict, error) {
+	return k.check(msg.Content)
+}
+
+func (k keywordModerator) CheckOutbound(_ context.Context, msg bus.OutboundMessage) (moderation.Verdict, error) {
+	return k.check(msg.Content)
+}
+
+func testPolicy() *moderation.Policy {
+	return &moderation.Policy{
+		Moderator:     keywordModerator{},
+		InboundReply:  "blocked input",
+		OutboundReply: "blocked output",
+	}
+}
+
+func TestHandleMessage_ModerationBlocksInbound(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("test", nil, mb, nil)
+	ch.SetModeration(testPolicy())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "you are bad", nil, nil)
+	reply, ok := mb.SubscribeOutbound(ctx)
+	if !ok || reply.Content != "blocked input" || reply.ChatID != "chat1" {
+		t.Fatalf("reply = %+v, %v; want the canned response", reply, ok)
+	}
+
+	// A moderation error fails open.
+	ch.HandleMessage(ctx, bus.Peer{}, "m2", "u1", "chat1", "error please", nil, nil)
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok || msg.Content != "error please" {
+		t.Fatalf("inbound = %+v, %v; want the message passed through", msg, ok)
+	}
+}
+
+func TestHandleMessage_ModerationReplyDefaultsToChannelLanguage(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("line", nil, mb, nil)
+	ch.SetModeration(&moderation.Policy{Moderator: keywordModerator{}})
+	ch.SetLanguage("zh-TW")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "you are bad", nil, nil)
+	reply, ok := mb.SubscribeOutbound(ctx)
+	if want := i18n.T("zh-TW", i18n.ModerationInbound); !ok || reply.Content != want {
+		t.Fatalf("reply = %+v, %v; want %q", reply, ok, want)
+	}
+}
+
+func TestRunWorker_ModerationReplacesOutbound(t *testing.T) {
+	m := newTestManager()
+	m.moderation = testPolicy()
+
+	sent := make(chan string, 2)
+	ch := &mockChannel{sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+		sent <- msg.Content
+		return nil
+	}}
+	w := &channelWorker{
+		ch:      ch,
+		queue:   make(chan bus.OutboundMessage, 2),
+		done:    make(chan struct{}),
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	go m.runWorker(t.Context(), "test", w)
+
+	w.queue <- bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "something bad"}
+	w.queue <- bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "fine"}
+
+	for _, want := range []string{"blocked output", "fine"} {
+		select {
+		case got := <-sent:
+			if got != want {
+				t.Errorf("sent %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
@@ -99,6 +99,7 @@ type oneBotMessageSegment struct {
 func NewOneBotChannel(cfg config.OneBotConfig, messageBus *bus.MessageBus) (*OneBotChannel, error) {
 	base := channels.NewBaseChannel("onebot", cfg, messageBus, cfg.AllowFrom,
 		channels.WithGroupTrigger(cfg.GroupTrigger),
+		channels.WithMediaDownload(cfg.Media),
 		channels.WithReasoningChannelID(cfg.ReasoningChannelID),
 	)
 
@@ -161,12 +162,12 @@ func (c *OneBotChannel) Start(ctx context.Context) error {
 			"error": err.Error(),
 		})
 	} else {
-		go c.listen()
+		c.Go(c.listen)
 		c.fetchSelfID()
 	}
 
 	if c.config.ReconnectInterval > 0 {
-		go c.reconnectLoop()
+		c.Go(c.reconnectLoop)
 	} else {
 		if c.conn == nil {
 			return fmt.Errorf("failed to connect to OneBot and reconnect is disabled")
@@ -206,7 +207,7 @@ func (c *OneBotChannel) connect() error {
 	c.conn = conn
 	c.mu.Unlock()
 
-	go c.pinger(conn)
+	c.Go(func() { c.pinger(conn) })
 
 	logger.InfoC("onebot", "WebSocket connected")
 	return nil
@@ -355,7 +356,7 @@ func (c *OneBotChannel) reconnectLoop() {
 						"error": err.Error(),
 					})
 				} else {
-					go c.listen()
+					c.Go(c.listen)
 					c.fetchSelfID()
 				}
 			}
@@ -782,7 +783,9 @@ func (c *OneBotChannel) parseMessageSegments(
 		case "image", "video", "file":
 			if data != nil {
 				url, _ := data["url"].(string)
-				if url != "" {
+				if url != "" && !c.ShouldDownloadMedia(segType) {
+					textParts = append(textParts, channels.SkippedMedia(segType, ""))
+				} else if url != "" {
 					defaults := map[string]string{"image": "image.jpg", "video": "video.mp4", "file": "file"}
 					filename := defaults[segType]
 					if f, ok := data["file"].(string); ok && f != "" {
@@ -803,7 +806,9 @@ func (c *OneBotChannel) parseMessageSegments(
 		case "record":
 			if data != nil {
 				url, _ := data["url"].(string)
-				if url != "" {
+				if url != "" && !c.ShouldDownloadMedia(config.MediaAudio) {
+					textParts = append(textParts, channels.SkippedMedia("voice", ""))
+				} else if url != "" {
 					localPath := utils.DownloadFile(url, "voice.amr", utils.DownloadOptions{
 						LoggerPrefix: "onebot",
 					})
@@ -16,6 +16,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -181,7 +182,7 @@ func (c *PicoChannel) SendPlaceholder(ctx context.Context, chatID string) (strin
 
 	text := c.config.Placeholder.Text
 	if text == "" {
-		text = "Thinking... 💭"
+		text = i18n.T(c.Language(), i18n.ChannelPlaceholder)
 	}
 
 	msgID := uuid.New().String()
@@ -279,7 +280,7 @@ func (c *PicoChannel) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		"session_id": sessionID,
 	})
 
-	go c.readLoop(pc)
+	c.Go(func() { c.readLoop(pc) })
 }
 
 // authenticate checks the Bearer token from the Authorization header.
@@ -336,7 +337,7 @@ func (c *PicoChannel) readLoop(pc *picoConn) {
 	if pingInterval <= 0 {
 		pingInterval = 30 * time.Second
 	}
-	go c.pingLoop(pc, pingInterval)
+	c.Go(func() { c.pingLoop(pc, pingInterval) })
 
 	for {
 		select {
@@ -90,14 +90,14 @@ func (c *QQChannel) Start(ctx context.Context) error {
 	c.sessionManager = botgo.NewSessionManager()
 
 	// start WebSocket connection in goroutine to avoid blocking
-	go func() {
+	c.Go(func() {
 		if err := c.sessionManager.Start(wsInfo, c.tokenSource, &intent); err != nil {
 			logger.ErrorCF("qq", "WebSocket session error", map[string]any{
 				"error": err.Error(),
 			})
 			c.SetRunning(false)
 		}
-	}()
+	})
 
 	c.SetRunning(true)
 	logger.InfoC("qq", "QQ bot started successfully")
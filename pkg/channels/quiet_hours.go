@@ -0,0 +1,258 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// What happens to a message received during quiet hours.
+const (
+	QuietHoursQueue  = "queue"  // hold, tell the sender when it will be answered, replay at End
+	QuietHoursReject = "reject" // tell the sender the bot is quiet and drop the message
+	QuietHoursDefer  = "defer"  // hold silently and replay at End
+)
+
+// maxHeldMessages caps how many inbound and how many outbound messages are
+// held for one quiet hours window. Later messages are dropped.
+const maxHeldMessages = 100
+
+// QuietHours is a daily window during which a channel neither processes
+// messages nor sends any. Only the clock time of Start and End is used.
+type QuietHours struct {
+	Start             time.Time
+	End               time.Time
+	Timezone          string
+	BehaviorOnReceive string
+
+	loc *time.Location
+	now func() time.Time
+
+	mu             sync.Mutex
+	inbound        []bus.InboundMessage
+	outbound       []func()
+	releaseInbound func(bus.InboundMessage)
+	timer          *time.Timer
+	notified       map[string]bool // chats told about quiet hours this window
+}
+
+// NewQuietHours parses cfg. It returns nil when quiet hours are disabled.
+func NewQuietHours(cfg config.QuietHoursConfig) (*QuietHours, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(cfg.Start))
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours start %q: use HH:MM", cfg.Start)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(cfg.End))
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours end %q: use HH:MM", cfg.End)
+	}
+	if start.Equal(end) {
+		return nil, fmt.Errorf("quiet hours start and end are both %s", cfg.Start)
+	}
+	loc := time.Local
+	if cfg.Timezone != "" {
+		if loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid quiet hours timezone %q: %w", cfg.Timezone, err)
+		}
+	}
+	behavior := strings.ToLower(strings.TrimSpace(cfg.BehaviorOnReceive))
+	switch behavior {
+	case "":
+		behavior = QuietHoursQueue
+	case QuietHoursQueue, QuietHoursReject, QuietHoursDefer:
+	default:
+		return nil, fmt.Errorf("invalid quiet hours behavior %q: use queue, reject or defer", cfg.BehaviorOnReceive)
+	}
+	return &QuietHours{
+		Start:             start,
+		End:               end,
+		Timezone:          cfg.Timezone,
+		BehaviorOnReceive: behavior,
+		loc:               loc,
+		now:               time.Now,
+	}, nil
+}
+
+// Active reports whether t falls within quiet hours. Start is inclusive,
+// End exclusive.
+func (q *QuietHours) Active(t time.Time) bool {
+	t = t.In(q.loc)
+	minute := t.Hour()*60 + t.Minute()
+	start := q.Start.Hour()*60 + q.Start.Minute()
+	end := q.End.Hour()*60 + q.End.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// The window passes midnight.
+	return minute >= start || minute < end
+}
+
+// NextEnd returns the first end of quiet hours after t.
+func (q *QuietHours) NextEnd(t time.Time) time.Time {
+	t = t.In(q.loc)
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End.Hour(), q.End.Minute(), 0, 0, q.loc)
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// holdInbound keeps msg until quiet hours end, when release publishes it.
+// It reports false when the queue is full and msg was not held.
+func (q *QuietHours) holdInbound(msg bus.InboundMessage, release func(bus.InboundMessage)) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.inbound) >= maxHeldMessages {
+		return false
+	}
+	q.inbound = append(q.inbound, msg)
+	q.scheduleLocked(release)
+	return true
+}
+
+// holdOutbound runs send once quiet hours end. It reports false when the
+// queue is full and send was not held.
+func (q *QuietHours) holdOutbound(send func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.outbound) >= maxHeldMessages {
+		return false
+	}
+	q.outbound = append(q.outbound, send)
+	q.scheduleLocked(nil)
+	return true
+}
+
+// firstNotice reports whether chatID has not yet been told about the
+// current quiet hours, and marks it as told.
+func (q *QuietHours) firstNotice(chatID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.notified[chatID] {
+		return false
+	}
+	if q.notified == nil {
+		q.notified = make(map[string]bool)
+	}
+	q.notified[chatID] = true
+	return true
+}
+
+// scheduleLocked arms the timer that releases held messages at the end of
+// the current quiet hours. The first inbound release callback is kept.
+func (q *QuietHours) scheduleLocked(release func(bus.InboundMessage)) {
+	if release != nil {
+		q.releaseInbound = release
+	}
+	if q.timer != nil {
+		return
+	}
+	q.timer = time.AfterFunc(q.NextEnd(q.now()).Sub(q.now()), q.flush)
+}
+
+// flush releases everything held, in the order it arrived.
+func (q *QuietHours) flush() {
+	q.mu.Lock()
+	inbound, outbound, release := q.inbound, q.outbound, q.releaseInbound
+	if q.timer != nil {
+		q.timer.Stop()
+	}
+	q.inbound, q.outbound, q.timer, q.notified = nil, nil, nil, nil
+	q.mu.Unlock()
+
+	if len(inbound)+len(outbound) > 0 {
+		logger.InfoCF("channels", "Quiet hours ended, releasing held messages", map[string]any{
+			"inbound":  len(inbound),
+			"outbound": len(outbound),
+		})
+	}
+	for _, msg := range inbound {
+		if release != nil {
+			release(msg)
+		}
+	}
+	for _, send := range outbound {
+		send()
+	}
+}
+
+// Stop drops held messages and cancels the release timer.
+func (q *QuietHours) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	q.inbound, q.outbound, q.notified = nil, nil, nil
+}
+
+// SetQuietHours injects the channel's quiet hours; nil disables them.
+func (c *BaseChannel) SetQuietHours(q *QuietHours) { c.quietHours = q }
+
+func (c *BaseChannel) quietHoursConfig() *QuietHours { return c.quietHours }
+
+// isInQuietHours reports whether the channel is currently quiet.
+func (c *BaseChannel) isInQuietHours() bool {
+	return c.quietHours != nil && c.quietHours.Active(c.quietHours.now())
+}
+
+// receiveInQuietHours applies the quiet hours behavior to an inbound
+// message. Each chat gets at most one notice per window. The notice goes
+// straight to the channel, since replies sent through the manager are held
+// until quiet hours end.
+func (c *BaseChannel) receiveInQuietHours(ctx context.Context, msg bus.InboundMessage) {
+	q := c.quietHours
+	until := q.NextEnd(q.now()).Format("15:04")
+	logger.InfoCF("channels", "Message received during quiet hours", map[string]any{
+		"channel":  c.name,
+		"chat_id":  msg.ChatID,
+		"behavior": q.BehaviorOnReceive,
+		"until":    until,
+	})
+
+	var notice string
+	switch q.BehaviorOnReceive {
+	case QuietHoursReject:
+		notice = i18n.T(c.language, i18n.QuietHoursRejected, until)
+	case QuietHoursQueue:
+		notice = i18n.T(c.language, i18n.QuietHoursQueued, until)
+	}
+	if q.BehaviorOnReceive != QuietHoursReject {
+		held := q.holdInbound(msg, func(held bus.InboundMessage) {
+			if err := c.bus.PublishInbound(context.Background(), held); err != nil {
+				logger.ErrorCF("channels", "Failed to replay message held for quiet hours", map[string]any{
+					"channel": c.name,
+					"chat_id": held.ChatID,
+					"error":   err.Error(),
+				})
+			}
+		})
+		if !held {
+			logger.WarnCF("channels", "Quiet hours queue is full, dropping message", map[string]any{
+				"channel": c.name,
+				"chat_id": msg.ChatID,
+			})
+		}
+	}
+	if notice == "" || c.owner == nil || !q.firstNotice(msg.ChatID) {
+		return
+	}
+	if err := c.owner.Send(ctx, bus.OutboundMessage{Channel: c.name, ChatID: msg.ChatID, Content: notice}); err != nil {
+		logger.WarnCF("channels", "Failed to send quiet hours notice", map[string]any{
+			"channel": c.name,
+			"chat_id": msg.ChatID,
+			"error":   err.Error(),
+		})
+	}
+}
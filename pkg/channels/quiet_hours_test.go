@@ -0,0 +1,284 @@
+package channels
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+)
+
+func newTestQuietHours(t *testing.T, cfg config.QuietHoursConfig, now time.Time) *QuietHours {
+	t.Helper()
+	cfg.Enabled = true
+	q, err := NewQuietHours(cfg)
+	if err != nil {
+		t.Fatalf("NewQuietHours() error: %v", err)
+	}
+	q.now = func() time.Time { return now }
+	t.Cleanup(q.Stop)
+	return q
+}
+
+func TestNewQuietHours(t *testing.T) {
+	if q, err := NewQuietHours(config.QuietHoursConfig{Start: "bad"}); q != nil || err != nil {
+		t.Fatalf("disabled = %v, %v; want nil, nil", q, err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.QuietHoursConfig
+	}{
+		{"bad start", config.QuietHoursConfig{Start: "25:00", End: "07:00"}},
+		{"bad end", config.QuietHoursConfig{Start: "22:00", End: "7am"}},
+		{"empty window", config.QuietHoursConfig{Start: "22:00", End: "22:00"}},
+		{"bad timezone", config.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}},
+		{"bad behavior", config.QuietHoursConfig{Start: "22:00", End: "07:00", BehaviorOnReceive: "ignore"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Enabled = true
+			if _, err := NewQuietHours(tt.cfg); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+
+	q, err := NewQuietHours(config.QuietHoursConfig{Enabled: true, Start: "22:00", End: "07:00"})
+	if err != nil {
+		t.Fatalf("NewQuietHours() error: %v", err)
+	}
+	if q.BehaviorOnReceive != QuietHoursQueue {
+		t.Fatalf("BehaviorOnReceive = %q, want %q", q.BehaviorOnReceive, QuietHoursQueue)
+	}
+}
+
+func TestQuietHoursActive(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("no tz data: %v", err)
+	}
+	overnight := newTestQuietHours(t, config.QuietHoursConfig{
+		Start: "22:00", End: "07:00", Timezone: "Asia/Tokyo",
+	}, time.Time{})
+	daytime := newTestQuietHours(t, config.QuietHoursConfig{
+		Start: "12:00", End: "13:30", Timezone: "Asia/Tokyo",
+	}, time.Time{})
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 1, hour, minute, 0, 0, tokyo)
+	}
+	tests := []struct {
+		q    *QuietHours
+		t    time.Time
+		want bool
+	}{
+		{overnight, at(21, 59), false},
+		{overnight, at(22, 0), true},
+		{overnight, at(3, 0), true},
+		{overnight, at(7, 0), false},
+		{daytime, at(12, 30), true},
+		{daytime, at(13, 30), false},
+		{daytime, at(23, 0), false},
+		// 14:00 UTC is 23:00 in Tokyo.
+		{overnight, time.Date(2026, 3, 1, 14, 0, 0, 0, time.UTC), true},
+		{overnight, time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Active(tt.t); got != tt.want {
+			t.Errorf("Active(%s) = %v, want %v", tt.t.In(tokyo).Format("15:04"), got, tt.want)
+		}
+	}
+
+	if got, want := overnight.NextEnd(at(23, 0)), time.Date(2026, 3, 2, 7, 0, 0, 0, tokyo); !got.Equal(want) {
+		t.Errorf("NextEnd(23:00) = %v, want %v", got, want)
+	}
+	if got, want := overnight.NextEnd(at(3, 0)), at(7, 0); !got.Equal(want) {
+		t.Errorf("NextEnd(03:00) = %v, want %v", got, want)
+	}
+}
+
+func TestHandleMessage_QuietHours(t *testing.T) {
+	night := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	cfg := config.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"}
+
+	tests := []struct {
+		behavior   string
+		wantNotice string
+		wantHeld   bool
+	}{
+		{QuietHoursQueue, i18n.T("", i18n.QuietHoursQueued, "07:00"), true},
+		{QuietHoursReject, i18n.T("", i18n.QuietHoursRejected, "07:00"), false},
+		{QuietHoursDefer, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.behavior, func(t *testing.T) {
+			mb := bus.NewMessageBus()
+			defer mb.Close()
+
+			var sent []bus.OutboundMessage
+			ch := &mockChannel{
+				BaseChannel: *NewBaseChannel("test", nil, mb, nil),
+				sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+					sent = append(sent, msg)
+					return nil
+				},
+			}
+			ch.SetOwner(ch)
+			cfg.BehaviorOnReceive = tt.behavior
+			q := newTestQuietHours(t, cfg, night)
+			ch.SetQuietHours(q)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "hello", nil, nil)
+
+			if tt.wantNotice == "" && len(sent) != 0 {
+				t.Fatalf("sent = %+v, want no notice", sent)
+			}
+			if tt.wantNotice != "" && (len(sent) != 1 || sent[0].Content != tt.wantNotice || sent[0].ChatID != "chat1") {
+				t.Fatalf("sent = %+v, want notice %q", sent, tt.wantNotice)
+			}
+			if msg, ok := mb.ConsumeInbound(ctx); ok {
+				t.Fatalf("inbound = %+v, want nothing published during quiet hours", msg)
+			}
+
+			q.flush()
+			ctx2, cancel2 := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel2()
+			msg, ok := mb.ConsumeInbound(ctx2)
+			if tt.wantHeld && (!ok || msg.Content != "hello") {
+				t.Fatalf("after quiet hours inbound = %+v, %v; want the held message", msg, ok)
+			}
+			if !tt.wantHeld && ok {
+				t.Fatalf("after quiet hours inbound = %+v; want the rejected message dropped", msg)
+			}
+		})
+	}
+}
+
+func TestRunWorker_HoldsOutboundDuringQuietHours(t *testing.T) {
+	m := newTestManager()
+	sent := make(chan bus.OutboundMessage, 1)
+	ch := &mockChannel{
+		BaseChannel: *NewBaseChannel("test", nil, nil, nil),
+		sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+			sent <- msg
+			return nil
+		},
+	}
+	q := newTestQuietHours(t, config.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"}, time.Time{})
+	var clock atomic.Int64
+	clock.Store(time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC).Unix())
+	q.now = func() time.Time { return time.Unix(clock.Load(), 0) }
+	ch.SetQuietHours(q)
+	w := &channelWorker{
+		ch:      ch,
+		queue:   make(chan bus.OutboundMessage, 10),
+		done:    make(chan struct{}),
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	go m.runWorker(t.Context(), "test", w)
+
+	w.queue <- bus.OutboundMessage{Channel: "test", ChatID: "1", Content: "good morning"}
+	select {
+	case msg := <-sent:
+		t.Fatalf("sent %+v during quiet hours", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	clock.Store(time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC).Unix())
+	q.flush()
+	select {
+	case msg := <-sent:
+		if msg.Content != "good morning" {
+			t.Fatalf("sent %+v, want the held message", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("held message was not sent after quiet hours")
+	}
+}
+
+func TestHandleMessage_QuietHoursNoticeOncePerChat(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+
+	var sent []bus.OutboundMessage
+	ch := &mockChannel{
+		BaseChannel: *NewBaseChannel("test", nil, mb, nil),
+		sendFn: func(_ context.Context, msg bus.OutboundMessage) error {
+			sent = append(sent, msg)
+			return nil
+		},
+	}
+	ch.SetOwner(ch)
+	night := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	q := newTestQuietHours(t, config.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"}, night)
+	ch.SetQuietHours(q)
+
+	ctx := t.Context()
+	ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "one", nil, nil)
+	ch.HandleMessage(ctx, bus.Peer{}, "m2", "u1", "chat1", "two", nil, nil)
+	ch.HandleMessage(ctx, bus.Peer{}, "m3", "u2", "chat2", "three", nil, nil)
+	if len(sent) != 2 || sent[0].ChatID != "chat1" || sent[1].ChatID != "chat2" {
+		t.Fatalf("sent = %+v, want one notice each for chat1 and chat2", sent)
+	}
+
+	q.flush() // a new window starts over
+	ch.HandleMessage(ctx, bus.Peer{}, "m4", "u1", "chat1", "four", nil, nil)
+	if len(sent) != 3 {
+		t.Fatalf("sent %d notices, want a fresh notice in the next window", len(sent))
+	}
+}
+
+func TestQuietHours_HoldIsBounded(t *testing.T) {
+	q := newTestQuietHours(t, config.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"},
+		time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC))
+	for i := 0; i < maxHeldMessages; i++ {
+		if !q.holdInbound(bus.InboundMessage{}, nil) || !q.holdOutbound(func() {}) {
+			t.Fatalf("message %d was not held", i)
+		}
+	}
+	if q.holdInbound(bus.InboundMessage{}, nil) {
+		t.Error("holdInbound() held a message beyond the cap")
+	}
+	if q.holdOutbound(func() {}) {
+		t.Error("holdOutbound() held a message beyond the cap")
+	}
+}
+
+func TestRunWorker_QuietHoursReportsQueued(t *testing.T) {
+	m := newTestManager()
+	ch := &mockChannel{BaseChannel: *NewBaseChannel("test", nil, nil, nil)}
+	q := newTestQuietHours(t, config.QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"},
+		time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC))
+	ch.SetQuietHours(q)
+	w := &channelWorker{
+		ch:      ch,
+		queue:   make(chan bus.OutboundMessage, 10),
+		done:    make(chan struct{}),
+		limiter: rate.NewLimiter(rate.Inf, 1),
+	}
+	go m.runWorker(t.Context(), "test", w)
+
+	statuses := make(chan bus.DeliveryStatus, 1)
+	w.queue <- bus.OutboundMessage{
+		Channel:    "test",
+		ChatID:     "1",
+		Content:    "good morning",
+		OnDelivery: func(s bus.DeliveryStatus) { statuses <- s },
+	}
+	select {
+	case s := <-statuses:
+		if s.State != bus.DeliveryQueued {
+			t.Fatalf("delivery state = %q, want %q", s.State, bus.DeliveryQueued)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no delivery status while the message is held")
+	}
+}
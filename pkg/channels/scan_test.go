@@ -0,0 +1,162 @@
+package channels
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/media"
+	"github.com/sipeed/picoclaw/pkg/scan"
+)
+
+// nameScanner flags files whose name contains "virus" and fails on files
+// whose name contains "broken".
+type nameScanner struct{}
+
+func (nameScanner) Scan(_ context.Context, path string) (scan.Result, error) {
+	switch {
+	case strings.Contains(path, "virus"):
+		return scan.Result{Infected: true, Signature: "Test.Virus"}, nil
+	case strings.Contains(path, "broken"):
+		return scan.Result{}, context.DeadlineExceeded
+	}
+	return scan.Result{}, nil
+}
+
+func TestHandleMessage_ScanRemovesInfectedAttachments(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("test", nil, mb, nil)
+	ch.SetScanPolicy(&scan.Policy{Scanner: nameScanner{}, NotifyChannel: "telegram", NotifyChatID: "owner"})
+
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "photo.jpg")
+	infected := filepath.Join(dir, "virus.exe")
+	broken := filepath.Join(dir, "broken.zip")
+	for _, p := range []string{clean, infected, broken} {
+		if err := os.WriteFile(p, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "look at this",
+		[]string{clean, infected, broken}, nil)
+
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok {
+		t.Fatal("expected the message to reach the agent")
+	}
+	if want := i18n.T("", i18n.ScanRemoved, 1); msg.Content != want {
+		t.Errorf("content = %q, want %q", msg.Content, want)
+	}
+	if len(msg.Media) != 2 || msg.Media[0] != clean || msg.Media[1] != broken {
+		t.Errorf("media = %v, want the clean and unscanned files", msg.Media)
+	}
+	if _, err := os.Stat(infected); !os.IsNotExist(err) {
+		t.Errorf("infected file still exists: %v", err)
+	}
+
+	notice, ok := mb.SubscribeOutbound(ctx)
+	if !ok || notice.Channel != "telegram" || notice.ChatID != "owner" ||
+		!strings.Contains(notice.Content, "Test.Virus") {
+		t.Fatalf("notice = %+v, %v; want an owner notice naming the signature", notice, ok)
+	}
+}
+
+func TestHandleMessage_ScanBlocksUnscanned(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("test", nil, mb, nil)
+	ch.SetScanPolicy(&scan.Policy{Scanner: nameScanner{}, BlockUnscanned: true})
+
+	broken := filepath.Join(t.TempDir(), "broken.zip")
+	if err := os.WriteFile(broken, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "here", []string{broken}, nil)
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok || len(msg.Media) != 0 {
+		t.Fatalf("inbound = %+v, %v; want the unscanned file dropped", msg, ok)
+	}
+	if want := i18n.T("", i18n.ScanUnscanned, 1); msg.Content != want {
+		t.Errorf("content = %q, want %q", msg.Content, want)
+	}
+	if _, err := os.Stat(broken); !os.IsNotExist(err) {
+		t.Errorf("unscanned file still exists: %v", err)
+	}
+}
+
+func TestHandleMessage_ScanReleasesStoredAttachments(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("test", nil, mb, nil)
+	store := media.NewFileMediaStore()
+	ch.SetMediaStore(store)
+	ch.SetScanPolicy(&scan.Policy{Scanner: nameScanner{}, BlockUnscanned: true})
+
+	infected := filepath.Join(t.TempDir(), "virus.exe")
+	if err := os.WriteFile(infected, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := store.Store(infected, media.MediaMeta{}, "scope")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// The second ref is unknown to the store, so it cannot be scanned.
+	ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "here",
+		[]string{ref, "media://missing"}, nil)
+	msg, ok := mb.ConsumeInbound(ctx)
+	if !ok || len(msg.Media) != 0 {
+		t.Fatalf("inbound = %+v, %v; want both attachments dropped", msg, ok)
+	}
+	want := i18n.T("", i18n.ScanRemoved, 1) + "\n" + i18n.T("", i18n.ScanUnscanned, 1)
+	if msg.Content != want {
+		t.Errorf("content = %q, want %q", msg.Content, want)
+	}
+	if _, err := os.Stat(infected); !os.IsNotExist(err) {
+		t.Errorf("infected file still exists: %v", err)
+	}
+	if _, err := store.Resolve(ref); err == nil {
+		t.Error("infected attachment is still in the media store")
+	}
+}
+
+func TestHandleMessage_ScanKeepsChatOrder(t *testing.T) {
+	mb := bus.NewMessageBus()
+	defer mb.Close()
+	ch := NewBaseChannel("test", nil, mb, nil)
+	ch.SetScanPolicy(&scan.Policy{Scanner: nameScanner{}})
+
+	photo := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(photo, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ch.HandleMessage(ctx, bus.Peer{}, "m1", "u1", "chat1", "photo", []string{photo}, nil)
+	ch.HandleMessage(ctx, bus.Peer{}, "m2", "u1", "chat1", "what is this?", nil, nil)
+	for _, want := range []string{"photo", "what is this?"} {
+		msg, ok := mb.ConsumeInbound(ctx)
+		if !ok || msg.Content != want {
+			t.Fatalf("inbound = %q, %v; want %q", msg.Content, ok, want)
+		}
+	}
+}
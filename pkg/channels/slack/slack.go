@@ -13,6 +13,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
@@ -51,6 +52,7 @@ func NewSlackChannel(cfg config.SlackConfig, messageBus *bus.MessageBus) (*Slack
 	base := channels.NewBaseChannel("slack", cfg, messageBus, cfg.AllowFrom,
 		channels.WithMaxMessageLength(40000),
 		channels.WithGroupTrigger(cfg.GroupTrigger),
+		channels.WithMediaDownload(cfg.Media),
 		channels.WithReasoningChannelID(cfg.ReasoningChannelID),
 	)
 
@@ -79,9 +81,9 @@ func (c *SlackChannel) Start(ctx context.Context) error {
 		"team":        authResp.Team,
 	})
 
-	go c.eventLoop()
+	c.Go(c.eventLoop)
 
-	go func() {
+	c.Go(func() {
 		if err := c.socketClient.RunContext(c.ctx); err != nil {
 			if c.ctx.Err() == nil {
 				logger.ErrorCF("slack", "Socket Mode connection error", map[string]any{
@@ -89,7 +91,7 @@ func (c *SlackChannel) Start(ctx context.Context) error {
 				})
 			}
 		}
-	}()
+	})
 
 	c.SetRunning(true)
 	logger.InfoC("slack", "Slack channel started (Socket Mode)")
@@ -201,6 +203,45 @@ func (c *SlackChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMessa
 	return nil
 }
 
+// SendPlaceholder implements channels.PlaceholderCapable.
+// It posts a placeholder message and returns its timestamp, which Slack
+// uses as the message ID.
+func (c *SlackChannel) SendPlaceholder(ctx context.Context, chatID string) (string, error) {
+	if !c.config.Placeholder.Enabled {
+		return "", nil
+	}
+
+	text := c.config.Placeholder.Text
+	if text == "" {
+		text = i18n.T(c.Language(), i18n.ChannelPlaceholder)
+	}
+
+	channelID, threadTS := parseSlackChatID(chatID)
+	if channelID == "" {
+		return "", fmt.Errorf("invalid slack chat ID: %s", chatID)
+	}
+	opts := []slack.MsgOption{slack.MsgOptionText(text, false)}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+
+	_, ts, err := c.api.PostMessageContext(ctx, channelID, opts...)
+	if err != nil {
+		return "", err
+	}
+	return ts, nil
+}
+
+// EditMessage implements channels.MessageEditor.
+func (c *SlackChannel) EditMessage(ctx context.Context, chatID string, messageID string, content string) error {
+	channelID, _ := parseSlackChatID(chatID)
+	if channelID == "" {
+		return fmt.Errorf("invalid slack chat ID: %s", chatID)
+	}
+	_, _, _, err := c.api.UpdateMessageContext(ctx, channelID, messageID, slack.MsgOptionText(content, false))
+	return err
+}
+
 // ReactToMessage implements channels.ReactionCapable.
 // It adds an "eyes" (👀) reaction to the inbound message and returns an undo function
 // that removes the reaction.
@@ -335,6 +376,10 @@ func (c *SlackChannel) handleMessageEvent(ev *slackevents.MessageEvent) {
 
 	if ev.Message != nil && len(ev.Message.Files) > 0 {
 		for _, file := range ev.Message.Files {
+			if kind := channels.MediaKind(file.Name, file.Mimetype); !c.ShouldDownloadMedia(kind) {
+				content += "\n" + channels.SkippedMedia(kind, file.Name)
+				continue
+			}
 			localPath := c.downloadSlackFile(file)
 			if localPath == "" {
 				continue
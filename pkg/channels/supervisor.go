@@ -0,0 +1,168 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const defaultRestartDelay = 5 * time.Second
+
+// channelRestartsTotal counts supervised channel restarts across the
+// process; exposed as the channel_restarts_total stat.
+var channelRestartsTotal atomic.Int64
+
+// ChannelRestartsTotal returns how many times supervised channels were
+// restarted after a panic.
+func ChannelRestartsTotal() int64 {
+	return channelRestartsTotal.Load()
+}
+
+// SupervisedChannel guards a channel against panics: in Start, and in the
+// receive loops and handlers the channel runs with BaseChannel.Go. A panic
+// is logged and, after RestartDelay, the channel is stopped and started
+// again, up to MaxRestarts times. Webhook handlers need no guard, since
+// net/http recovers a panicking request. The manager keeps the wrapped
+// channel for everything else, so optional interfaces such as MediaSender
+// still apply.
+type SupervisedChannel struct {
+	Channel
+	RestartDelay time.Duration
+	MaxRestarts  int
+
+	restarts   atomic.Int64
+	restarting atomic.Bool
+}
+
+// panicReporter is implemented by channels that embed BaseChannel.
+type panicReporter interface {
+	SetPanicHandler(func(any))
+}
+
+// NewSupervisedChannel wraps ch. A zero delay falls back to 5 seconds. A
+// maxRestarts of zero disables restarts: panics are still recovered and
+// logged, but the channel stays down.
+func NewSupervisedChannel(ch Channel, restartDelay time.Duration, maxRestarts int) *SupervisedChannel {
+	if restartDelay <= 0 {
+		restartDelay = defaultRestartDelay
+	}
+	maxRestarts = max(maxRestarts, 0)
+	return &SupervisedChannel{Channel: ch, RestartDelay: restartDelay, MaxRestarts: maxRestarts}
+}
+
+// Restarts returns how many times the channel was restarted.
+func (s *SupervisedChannel) Restarts() int64 {
+	return s.restarts.Load()
+}
+
+// Start starts the channel in a goroutine guarded by recover. An error from
+// the first attempt is returned as is; a panic is not, since the supervisor
+// takes over restarting the channel in the background.
+func (s *SupervisedChannel) Start(ctx context.Context) error {
+	if pr, ok := s.Channel.(panicReporter); ok {
+		pr.SetPanicHandler(func(r any) {
+			s.logPanic(r)
+			s.restart(ctx)
+		})
+	}
+
+	result := make(chan error, 1)
+	panicked := make(chan struct{}, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				s.logPanic(r)
+				panicked <- struct{}{}
+			}
+		}()
+		result <- s.Channel.Start(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-panicked:
+		s.restart(ctx)
+		return nil
+	}
+}
+
+// restart starts the restart loop unless it is already running, e.g. when
+// several goroutines of the channel panic at once.
+func (s *SupervisedChannel) restart(ctx context.Context) {
+	if s.restarting.CompareAndSwap(false, true) {
+		go func() {
+			defer s.restarting.Store(false)
+			s.restartLoop(ctx)
+		}()
+	}
+}
+
+// restartLoop restarts the channel until a Start no longer panics, the
+// restart budget is spent or ctx ends.
+func (s *SupervisedChannel) restartLoop(ctx context.Context) {
+	for {
+		if int(s.restarts.Load()) >= s.MaxRestarts {
+			logger.ErrorCF("channels", "Channel restart limit reached, giving up", map[string]any{
+				"channel":      s.Name(),
+				"max_restarts": s.MaxRestarts,
+			})
+			return
+		}
+
+		timer := time.NewTimer(s.RestartDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		n := s.restarts.Add(1)
+		channelRestartsTotal.Add(1)
+		logger.ErrorCF("channels", "Restarting channel after panic", map[string]any{
+			"channel": s.Name(),
+			"restart": n,
+			"max":     s.MaxRestarts,
+		})
+
+		panicked, err := s.safeRestart(ctx)
+		if panicked {
+			continue
+		}
+		if err != nil {
+			logger.ErrorCF("channels", "Channel restart failed", map[string]any{
+				"channel": s.Name(),
+				"error":   err.Error(),
+			})
+		}
+		return
+	}
+}
+
+// safeRestart stops what is left of the channel and starts it again,
+// reporting whether Start panicked.
+func (s *SupervisedChannel) safeRestart(ctx context.Context) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logPanic(r)
+			panicked, err = true, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	_ = s.Channel.Stop(stopCtx)
+	cancel()
+	return false, s.Channel.Start(ctx)
+}
+
+func (s *SupervisedChannel) logPanic(r any) {
+	logger.ErrorCF("channels", "Channel panicked", map[string]any{
+		"channel": s.Name(),
+		"panic":   fmt.Sprint(r),
+		"stack":   string(debug.Stack()),
+	})
+}
@@ -0,0 +1,143 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyChannel panics on the first panics Start calls.
+type flakyChannel struct {
+	mockChannel
+	panics int32
+	starts atomic.Int32
+	stops  atomic.Int32
+}
+
+func (c *flakyChannel) Start(ctx context.Context) error {
+	if c.starts.Add(1) <= c.panics {
+		panic("boom")
+	}
+	return nil
+}
+
+func (c *flakyChannel) Stop(ctx context.Context) error {
+	c.stops.Add(1)
+	return nil
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSupervisedChannel_RestartsAfterPanic(t *testing.T) {
+	ch := &flakyChannel{panics: 2}
+	before := ChannelRestartsTotal()
+	s := NewSupervisedChannel(ch, time.Millisecond, 3)
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v, want nil after a panic", err)
+	}
+	waitFor(t, func() bool { return ch.starts.Load() == 3 })
+
+	if got := s.Restarts(); got != 2 {
+		t.Errorf("Restarts() = %d, want 2", got)
+	}
+	if got := ChannelRestartsTotal() - before; got != 2 {
+		t.Errorf("channel_restarts_total grew by %d, want 2", got)
+	}
+	if got := ch.stops.Load(); got != 2 {
+		t.Errorf("Stop called %d times, want once per restart", got)
+	}
+}
+
+func TestSupervisedChannel_GivesUpAfterMaxRestarts(t *testing.T) {
+	ch := &flakyChannel{panics: 100}
+	s := NewSupervisedChannel(ch, time.Millisecond, 2)
+
+	s.Start(context.Background())
+	waitFor(t, func() bool { return s.Restarts() == 2 })
+	time.Sleep(20 * time.Millisecond)
+
+	if got := ch.starts.Load(); got != 3 {
+		t.Errorf("Start called %d times, want 1 + 2 restarts", got)
+	}
+}
+
+func TestSupervisedChannel_ZeroMaxRestartsDisablesRestarts(t *testing.T) {
+	ch := &flakyChannel{panics: 1}
+	s := NewSupervisedChannel(ch, time.Millisecond, 0)
+
+	s.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+
+	if got := ch.starts.Load(); got != 1 {
+		t.Errorf("Start called %d times, want no restart", got)
+	}
+	if got := s.Restarts(); got != 0 {
+		t.Errorf("Restarts() = %d, want 0", got)
+	}
+}
+
+func TestSupervisedChannel_ReturnsStartError(t *testing.T) {
+	want := errors.New("bad token")
+	ch := &mockChannel{}
+	s := NewSupervisedChannel(&erroringChannel{mockChannel: ch, err: want}, time.Millisecond, 3)
+
+	if err := s.Start(context.Background()); !errors.Is(err, want) {
+		t.Errorf("Start() error = %v, want %v", err, want)
+	}
+	if s.Restarts() != 0 {
+		t.Errorf("a plain Start error must not trigger restarts")
+	}
+}
+
+// loopChannel starts a receive loop that panics after Start has returned,
+// on the first panics starts.
+type loopChannel struct {
+	flakyChannel
+}
+
+func (c *loopChannel) Start(ctx context.Context) error {
+	n := c.starts.Add(1)
+	c.Go(func() {
+		if n <= c.panics {
+			panic("boom in receive loop")
+		}
+	})
+	return nil
+}
+
+func TestSupervisedChannel_RestartsAfterLoopPanic(t *testing.T) {
+	ch := &loopChannel{flakyChannel{panics: 1}}
+	s := NewSupervisedChannel(ch, time.Millisecond, 3)
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	waitFor(t, func() bool { return ch.starts.Load() == 2 })
+	time.Sleep(20 * time.Millisecond)
+
+	if got := s.Restarts(); got != 1 {
+		t.Errorf("Restarts() = %d, want 1", got)
+	}
+	if got := ch.stops.Load(); got != 1 {
+		t.Errorf("Stop called %d times, want 1", got)
+	}
+}
+
+type erroringChannel struct {
+	*mockChannel
+	err error
+}
+
+func (c *erroringChannel) Start(ctx context.Context) error { return c.err }
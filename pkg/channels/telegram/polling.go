@@ -0,0 +1,193 @@
+package telegram
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mymmrac/telego"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/channels"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	pollTimeoutSeconds = 30
+	pollBackoffMin     = time.Second
+	pollBackoffMax     = time.Minute
+	pollBufferSize     = 100
+)
+
+// pollUpdates long-polls getUpdates until ctx ends and sends the updates to
+// out in the order Telegram returned them. Unlike telego's own polling, which
+// retries at a fixed interval, failures back off exponentially with jitter,
+// so a device on a flaky link does not hammer the API, and link state changes
+// are recorded with channels.SetLinkUp and SetLinkDown.
+func (c *TelegramChannel) pollUpdates(ctx context.Context, out chan<- telego.Update) {
+	defer close(out)
+
+	params := &telego.GetUpdatesParams{Timeout: pollTimeoutSeconds}
+	failures := 0
+	for ctx.Err() == nil {
+		updates, err := c.bot.GetUpdates(ctx, params)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			channels.SetLinkDown("telegram", err)
+			delay := pollBackoff(failures, rand.Float64)
+			logger.DebugCF("telegram", "Retrying getUpdates", map[string]any{
+				"attempt": failures,
+				"delay":   delay.String(),
+			})
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		failures = 0
+		channels.SetLinkUp("telegram")
+		for _, update := range updates {
+			if update.UpdateID < params.Offset {
+				continue
+			}
+			params.Offset = update.UpdateID + 1
+			c.order.enqueue(update)
+			select {
+			case <-ctx.Done():
+				return
+			case out <- update.WithContext(ctx):
+			}
+		}
+	}
+}
+
+// pollBackoff returns the delay before retry number failures: doubling from
+// pollBackoffMin up to pollBackoffMax, then jittered down by up to half so
+// devices that lost the same link do not reconnect in lockstep.
+func pollBackoff(failures int, jitter func() float64) time.Duration {
+	delay := pollBackoffMin
+	for i := 1; i < failures && delay < pollBackoffMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, pollBackoffMax)
+	return delay/2 + time.Duration(jitter()*float64(delay/2))
+}
+
+// chatOrder makes the bot handler, which runs every update in its own
+// goroutine, handle the updates of one chat in the order they were polled.
+// After an outage Telegram delivers the backlog in a single batch, and
+// without this the messages of a chat would reach the agent shuffled.
+type chatOrder struct {
+	mu      sync.Mutex
+	tails   map[string]chan struct{} // done channel of a chat's last update
+	tickets map[int]orderTicket      // by update ID
+}
+
+type orderTicket struct {
+	chat string
+	prev chan struct{} // closed when the previous update of the chat is done
+	done chan struct{}
+}
+
+// enqueue queues update behind the earlier updates of its chat. It must be
+// called in polling order.
+func (o *chatOrder) enqueue(update telego.Update) {
+	chat := updateChat(update)
+	if chat == "" {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.tails == nil {
+		o.tails = make(map[string]chan struct{})
+		o.tickets = make(map[int]orderTicket)
+	}
+	done := make(chan struct{})
+	o.tickets[update.UpdateID] = orderTicket{chat: chat, prev: o.tails[chat], done: done}
+	o.tails[chat] = done
+}
+
+// wait blocks until the earlier updates of update's chat are handled, and
+// returns the function that marks update as handled.
+func (o *chatOrder) wait(ctx context.Context, update telego.Update) func() {
+	o.mu.Lock()
+	ticket, ok := o.tickets[update.UpdateID]
+	delete(o.tickets, update.UpdateID)
+	o.mu.Unlock()
+	if !ok {
+		return func() {}
+	}
+
+	if ticket.prev != nil {
+		select {
+		case <-ticket.prev:
+		case <-ctx.Done():
+		}
+	}
+	return func() {
+		close(ticket.done)
+		o.mu.Lock()
+		if o.tails[ticket.chat] == ticket.done {
+			delete(o.tails, ticket.chat)
+		}
+		o.mu.Unlock()
+	}
+}
+
+// updateChat returns the chat an update belongs to, or "" for updates that
+// need no ordering.
+func updateChat(update telego.Update) string {
+	switch {
+	case update.Message != nil:
+		return strconv.FormatInt(update.Message.Chat.ID, 10)
+	case update.EditedMessage != nil:
+		return strconv.FormatInt(update.EditedMessage.Chat.ID, 10)
+	}
+	return ""
+}
+
+// expired reports whether message waited longer than max_message_age_minutes
+// to be handled, typically because the device was offline. Instead of
+// answering a long stale message in full, the sender gets an apology; in
+// groups only when the message would have been answered at all.
+func (c *TelegramChannel) expired(ctx context.Context, message *telego.Message, chatID string) bool {
+	maxAge := time.Duration(c.config.Channels.Telegram.MaxMessageAgeMinutes) * time.Minute
+	if maxAge <= 0 || message.Date == 0 {
+		return false
+	}
+	age := time.Since(time.Unix(message.Date, 0))
+	if age <= maxAge {
+		return false
+	}
+
+	logger.InfoCF("telegram", "Message too old, not processing it", map[string]any{
+		"chat_id": chatID,
+		"age":     age.Round(time.Second).String(),
+	})
+	if message.Chat.Type != "private" {
+		text := message.Text
+		if text == "" {
+			text = message.Caption
+		}
+		if respond, _ := c.ShouldRespondInGroup(c.isBotMentioned(message), text); !respond {
+			return true
+		}
+	}
+	apology := i18n.T(c.Language(), i18n.ChannelMessageExpired, int(age.Minutes()))
+	if err := c.Send(ctx, bus.OutboundMessage{Channel: "telegram", ChatID: chatID, Content: apology}); err != nil {
+		logger.WarnCF("telegram", "Failed to send apology for old message", map[string]any{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mymmrac/telego"
+)
+
+func TestPollBackoff(t *testing.T) {
+	for _, tt := range []struct {
+		failures int
+		jitter   float64
+		want     time.Duration
+	}{
+		{1, 1, time.Second},
+		{1, 0, 500 * time.Millisecond},
+		{2, 1, 2 * time.Second},
+		{4, 1, 8 * time.Second},
+		{7, 1, time.Minute},
+		{50, 0.5, 45 * time.Second},
+	} {
+		got := pollBackoff(tt.failures, func() float64 { return tt.jitter })
+		if got != tt.want {
+			t.Errorf("pollBackoff(%d, %v) = %v, want %v", tt.failures, tt.jitter, got, tt.want)
+		}
+	}
+}
+
+func TestChatOrderKeepsPollingOrderPerChat(t *testing.T) {
+	var order chatOrder
+	update := func(id int, chat int64) telego.Update {
+		return telego.Update{UpdateID: id, Message: &telego.Message{Chat: telego.Chat{ID: chat}}}
+	}
+	updates := []telego.Update{update(1, 10), update(2, 20), update(3, 10), update(4, 10)}
+	for _, u := range updates {
+		order.enqueue(u)
+	}
+
+	var (
+		mu      sync.Mutex
+		handled []int
+		wg      sync.WaitGroup
+	)
+	// Start the handlers in reverse, as the bot handler's goroutines may.
+	for i := len(updates) - 1; i >= 0; i-- {
+		wg.Add(1)
+		go func(u telego.Update) {
+			defer wg.Done()
+			release := order.wait(context.Background(), u)
+			defer release()
+			if u.Message.Chat.ID == 10 {
+				mu.Lock()
+				handled = append(handled, u.UpdateID)
+				mu.Unlock()
+			}
+		}(updates[i])
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if len(handled) != 3 || handled[0] != 1 || handled[1] != 3 || handled[2] != 4 {
+		t.Errorf("chat 10 handled in order %v, want [1 3 4]", handled)
+	}
+	if len(order.tails) != 0 || len(order.tickets) != 0 {
+		t.Errorf("chatOrder kept state after all updates were handled: %d tails, %d tickets",
+			len(order.tails), len(order.tickets))
+	}
+}
+
+func TestChatOrderWaitUnknownUpdate(t *testing.T) {
+	var order chatOrder
+	release := order.wait(context.Background(), telego.Update{UpdateID: 99})
+	release()
+}
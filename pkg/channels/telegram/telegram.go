@@ -10,6 +10,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mymmrac/telego"
@@ -19,12 +20,20 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/media"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
+const (
+	defaultPendingReaction = "👀"
+	defaultDoneReaction    = "✅"
+	editedReaction         = "📝"
+	noReaction             = "none"
+)
+
 var (
 	reHeading    = regexp.MustCompile(`^#{1,6}\s+(.+)$`)
 	reBlockquote = regexp.MustCompile(`^>\s*(.*)$`)
@@ -45,6 +54,8 @@ type TelegramChannel struct {
 	commands TelegramCommander
 	config   *config.Config
 	chatIDs  map[string]int64
+	closed   sync.Map // chat IDs of topics seen closed, see topicChatID
+	order    chatOrder
 	ctx      context.Context
 	cancel   context.CancelFunc
 }
@@ -84,6 +95,9 @@ func NewTelegramChannel(cfg *config.Config, bus *bus.MessageBus) (*TelegramChann
 		telegramCfg.AllowFrom,
 		channels.WithMaxMessageLength(4096),
 		channels.WithGroupTrigger(telegramCfg.GroupTrigger),
+		channels.WithMediaDownload(telegramCfg.Media),
+		// Videos are often large, so they are fetched only when listed.
+		channels.WithDefaultMediaDownload(config.MediaImage, config.MediaAudio, config.MediaFile),
 		channels.WithReasoningChannelID(telegramCfg.ReasoningChannelID),
 	)
 
@@ -107,13 +121,8 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 		})
 	}
 
-	updates, err := c.bot.UpdatesViaLongPolling(c.ctx, &telego.GetUpdatesParams{
-		Timeout: 30,
-	})
-	if err != nil {
-		c.cancel()
-		return fmt.Errorf("failed to start long polling: %w", err)
-	}
+	updates := make(chan telego.Update, pollBufferSize)
+	c.Go(func() { c.pollUpdates(c.ctx, updates) })
 
 	bh, err := th.NewBotHandler(c.bot, updates)
 	if err != nil {
@@ -122,6 +131,13 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 	}
 	c.bh = bh
 
+	// Registered first so it runs for every update, see chatOrder.
+	bh.Use(func(ctx *th.Context, update telego.Update) error {
+		release := c.order.wait(ctx, update)
+		defer release()
+		return ctx.Next(update)
+	})
+
 	bh.HandleMessage(func(ctx *th.Context, message telego.Message) error {
 		return c.commands.Start(ctx, message)
 	}, th.CommandEqual("start"))
@@ -141,18 +157,22 @@ func (c *TelegramChannel) Start(ctx context.Context) error {
 		return c.handleMessage(ctx, &message)
 	}, th.AnyMessage())
 
+	bh.HandleEditedMessage(func(ctx *th.Context, message telego.Message) error {
+		return c.handleEditedMessage(ctx, &message)
+	})
+
 	c.SetRunning(true)
 	logger.InfoCF("telegram", "Telegram bot connected", map[string]any{
 		"username": c.bot.Username(),
 	})
 
-	go func() {
-		if err = bh.Start(); err != nil {
+	c.Go(func() {
+		if err := bh.Start(); err != nil {
 			logger.ErrorCF("telegram", "Bot handler failed", map[string]any{
 				"error": err.Error(),
 			})
 		}
-	}()
+	})
 
 	return nil
 }
@@ -199,6 +219,22 @@ func (c *TelegramChannel) initBotCommands(ctx context.Context) error {
 			Command:     "list",
 			Description: "List available options",
 		},
+		{
+			Command:     "reset",
+			Description: "Clear the conversation history",
+		},
+		{
+			Command:     "model",
+			Description: "Show or switch the model for this chat",
+		},
+		{
+			Command:     "status",
+			Description: "Show session usage for this chat",
+		},
+		{
+			Command:     "skills",
+			Description: "List installed skills",
+		},
 	}
 
 	// Setting commands on each start will hit the rate limit very quickly, that's why we check if an update is needed
@@ -224,7 +260,7 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 		return channels.ErrNotRunning
 	}
 
-	chatID, err := parseChatID(msg.ChatID)
+	chatID, threadID, err := parseChatTarget(msg.ChatID)
 	if err != nil {
 		return fmt.Errorf("invalid chat ID %s: %w", msg.ChatID, channels.ErrSendFailed)
 	}
@@ -233,9 +269,17 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 
 	// Typing/placeholder handled by Manager.preSend — just send the message
 	tgMsg := tu.Message(tu.ID(chatID), htmlContent)
+	tgMsg.MessageThreadID = threadID
 	tgMsg.ParseMode = telego.ModeHTML
 
 	if _, err = c.bot.SendMessage(ctx, tgMsg); err != nil {
+		if isTopicClosedError(err) {
+			c.closed.Store(msg.ChatID, struct{}{})
+			logger.WarnCF("telegram", "Topic is closed, dropping reply", map[string]any{
+				"chat_id": msg.ChatID,
+			})
+			return fmt.Errorf("telegram topic closed: %w", channels.ErrSendFailed)
+		}
 		logger.ErrorCF("telegram", "HTML parse failed, falling back to plain text", map[string]any{
 			"error": err.Error(),
 		})
@@ -253,13 +297,15 @@ func (c *TelegramChannel) Send(ctx context.Context, msg bus.OutboundMessage) err
 // (Telegram's typing indicator expires after ~5s) in a background goroutine.
 // The returned stop function is idempotent and cancels the goroutine.
 func (c *TelegramChannel) StartTyping(ctx context.Context, chatID string) (func(), error) {
-	cid, err := parseChatID(chatID)
+	cid, threadID, err := parseChatTarget(chatID)
 	if err != nil {
 		return func() {}, err
 	}
+	action := tu.ChatAction(tu.ID(cid), telego.ChatActionTyping)
+	action.MessageThreadID = threadID
 
 	// Send the first typing action immediately
-	_ = c.bot.SendChatAction(ctx, tu.ChatAction(tu.ID(cid), telego.ChatActionTyping))
+	_ = c.bot.SendChatAction(ctx, action)
 
 	typingCtx, cancel := context.WithCancel(ctx)
 	go func() {
@@ -270,7 +316,7 @@ func (c *TelegramChannel) StartTyping(ctx context.Context, chatID string) (func(
 			case <-typingCtx.Done():
 				return
 			case <-ticker.C:
-				_ = c.bot.SendChatAction(typingCtx, tu.ChatAction(tu.ID(cid), telego.ChatActionTyping))
+				_ = c.bot.SendChatAction(typingCtx, action)
 			}
 		}
 	}()
@@ -295,6 +341,65 @@ func (c *TelegramChannel) EditMessage(ctx context.Context, chatID string, messag
 	return err
 }
 
+// ReactToMessage implements channels.ReactionCapable.
+// When reactions are enabled it marks the inbound message with the pending
+// emoji (👀 by default) and returns an undo function that swaps it to the
+// done emoji (✅ by default) once the reply has been sent.
+func (c *TelegramChannel) ReactToMessage(ctx context.Context, chatID, messageID string) (func(), error) {
+	rc := c.config.Channels.Telegram.Reactions
+	if !rc.Enabled {
+		return func() {}, nil
+	}
+
+	cid, err := parseChatID(chatID)
+	if err != nil {
+		return func() {}, err
+	}
+	mid, err := strconv.Atoi(messageID)
+	if err != nil {
+		return func() {}, err
+	}
+
+	pending := rc.Pending
+	if pending == "" {
+		pending = defaultPendingReaction
+	}
+	if err := c.setReaction(ctx, cid, mid, pending); err != nil {
+		logger.DebugCF("telegram", "Failed to set reaction", map[string]any{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return func() {}, err
+	}
+
+	done := rc.Done
+	if done == "" {
+		done = defaultDoneReaction
+	} else if done == noReaction {
+		done = ""
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			_ = c.setReaction(c.ctx, cid, mid, done)
+		})
+	}, nil
+}
+
+// setReaction replaces the bot's reaction on a message; an empty emoji
+// removes it.
+func (c *TelegramChannel) setReaction(ctx context.Context, chatID int64, messageID int, emoji string) error {
+	params := &telego.SetMessageReactionParams{
+		ChatID:    tu.ID(chatID),
+		MessageID: messageID,
+	}
+	if emoji != "" {
+		params.Reaction = []telego.ReactionType{tu.ReactionEmoji(emoji)}
+	}
+	return c.bot.SetMessageReaction(ctx, params)
+}
+
 // SendPlaceholder implements channels.PlaceholderCapable.
 // It sends a placeholder message (e.g. "Thinking... 💭") that will later be
 // edited to the actual response via EditMessage (channels.MessageEditor).
@@ -306,15 +411,17 @@ func (c *TelegramChannel) SendPlaceholder(ctx context.Context, chatID string) (s
 
 	text := phCfg.Text
 	if text == "" {
-		text = "Thinking... 💭"
+		text = i18n.T(c.Language(), i18n.ChannelPlaceholder)
 	}
 
-	cid, err := parseChatID(chatID)
+	cid, threadID, err := parseChatTarget(chatID)
 	if err != nil {
 		return "", err
 	}
+	phMsg := tu.Message(tu.ID(cid), text)
+	phMsg.MessageThreadID = threadID
 
-	pMsg, err := c.bot.SendMessage(ctx, tu.Message(tu.ID(cid), text))
+	pMsg, err := c.bot.SendMessage(ctx, phMsg)
 	if err != nil {
 		return "", err
 	}
@@ -328,7 +435,7 @@ func (c *TelegramChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMe
 		return channels.ErrNotRunning
 	}
 
-	chatID, err := parseChatID(msg.ChatID)
+	chatID, threadID, err := parseChatTarget(msg.ChatID)
 	if err != nil {
 		return fmt.Errorf("invalid chat ID %s: %w", msg.ChatID, channels.ErrSendFailed)
 	}
@@ -360,30 +467,34 @@ func (c *TelegramChannel) SendMedia(ctx context.Context, msg bus.OutboundMediaMe
 		switch part.Type {
 		case "image":
 			params := &telego.SendPhotoParams{
-				ChatID:  tu.ID(chatID),
-				Photo:   telego.InputFile{File: file},
-				Caption: part.Caption,
+				ChatID:          tu.ID(chatID),
+				MessageThreadID: threadID,
+				Photo:           telego.InputFile{File: file},
+				Caption:         part.Caption,
 			}
 			_, err = c.bot.SendPhoto(ctx, params)
 		case "audio":
 			params := &telego.SendAudioParams{
-				ChatID:  tu.ID(chatID),
-				Audio:   telego.InputFile{File: file},
-				Caption: part.Caption,
+				ChatID:          tu.ID(chatID),
+				MessageThreadID: threadID,
+				Audio:           telego.InputFile{File: file},
+				Caption:         part.Caption,
 			}
 			_, err = c.bot.SendAudio(ctx, params)
 		case "video":
 			params := &telego.SendVideoParams{
-				ChatID:  tu.ID(chatID),
-				Video:   telego.InputFile{File: file},
-				Caption: part.Caption,
+				ChatID:          tu.ID(chatID),
+				MessageThreadID: threadID,
+				Video:           telego.InputFile{File: file},
+				Caption:         part.Caption,
 			}
 			_, err = c.bot.SendVideo(ctx, params)
 		default: // "file" or unknown types
 			params := &telego.SendDocumentParams{
-				ChatID:   tu.ID(chatID),
-				Document: telego.InputFile{File: file},
-				Caption:  part.Caption,
+				ChatID:          tu.ID(chatID),
+				MessageThreadID: threadID,
+				Document:        telego.InputFile{File: file},
+				Caption:         part.Caption,
 			}
 			_, err = c.bot.SendDocument(ctx, params)
 		}
@@ -407,6 +518,20 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		return fmt.Errorf("message is nil")
 	}
 
+	chatID := message.Chat.ID
+	threadID := messageThreadID(message)
+	chatIDStr := topicChatID(chatID, threadID)
+
+	// Whoever closes a topic, the bot can no longer answer in it.
+	switch {
+	case message.ForumTopicClosed != nil:
+		c.closed.Store(chatIDStr, struct{}{})
+		return nil
+	case message.ForumTopicReopened != nil:
+		c.closed.Delete(chatIDStr)
+		return nil
+	}
+
 	user := message.From
 	if user == nil {
 		return fmt.Errorf("message sender (user) is nil")
@@ -429,13 +554,31 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		return nil
 	}
 
-	chatID := message.Chat.ID
+	if !topicAllowed(c.config.Channels.Telegram.Topics, chatID, threadID) {
+		logger.DebugCF("telegram", "Message rejected by topic allowlist", map[string]any{
+			"chat_id": chatIDStr,
+		})
+		return nil
+	}
+	if _, closed := c.closed.Load(chatIDStr); closed {
+		if c.isBotMentioned(message) {
+			logger.InfoCF("telegram", "Mentioned in a closed topic, skipping", map[string]any{
+				"chat_id": chatIDStr,
+				"user_id": platformID,
+			})
+		}
+		return nil
+	}
+
 	c.chatIDs[platformID] = chatID
 
+	if c.expired(ctx, message, chatIDStr) {
+		return nil
+	}
+
 	content := ""
 	mediaPaths := []string{}
 
-	chatIDStr := fmt.Sprintf("%d", chatID)
 	messageIDStr := fmt.Sprintf("%d", message.MessageID)
 	scope := channels.BuildMediaScope("telegram", chatIDStr, messageIDStr)
 
@@ -464,49 +607,55 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		content += message.Caption
 	}
 
+	addTag := func(tag string) {
+		if content != "" {
+			content += "\n"
+		}
+		content += tag
+	}
+
 	if len(message.Photo) > 0 {
-		photo := message.Photo[len(message.Photo)-1]
-		photoPath := c.downloadPhoto(ctx, photo.FileID)
-		if photoPath != "" {
+		if !c.ShouldDownloadMedia(config.MediaImage) {
+			addTag(channels.SkippedMedia(config.MediaImage, ""))
+		} else if photoPath := c.downloadPhoto(ctx, message.Photo[len(message.Photo)-1].FileID); photoPath != "" {
 			mediaPaths = append(mediaPaths, storeMedia(photoPath, "photo.jpg"))
-			if content != "" {
-				content += "\n"
-			}
-			content += "[image: photo]"
+			addTag("[image: photo]")
 		}
 	}
 
 	if message.Voice != nil {
-		voicePath := c.downloadFile(ctx, message.Voice.FileID, ".ogg")
-		if voicePath != "" {
+		if !c.ShouldDownloadMedia(config.MediaAudio) {
+			addTag(channels.SkippedMedia("voice", ""))
+		} else if voicePath := c.downloadFile(ctx, message.Voice.FileID, ".ogg"); voicePath != "" {
 			mediaPaths = append(mediaPaths, storeMedia(voicePath, "voice.ogg"))
-
-			if content != "" {
-				content += "\n"
-			}
-			content += "[voice]"
+			addTag("[voice]")
 		}
 	}
 
 	if message.Audio != nil {
-		audioPath := c.downloadFile(ctx, message.Audio.FileID, ".mp3")
-		if audioPath != "" {
+		if !c.ShouldDownloadMedia(config.MediaAudio) {
+			addTag(channels.SkippedMedia(config.MediaAudio, message.Audio.FileName))
+		} else if audioPath := c.downloadFile(ctx, message.Audio.FileID, ".mp3"); audioPath != "" {
 			mediaPaths = append(mediaPaths, storeMedia(audioPath, "audio.mp3"))
-			if content != "" {
-				content += "\n"
-			}
-			content += "[audio]"
+			addTag("[audio]")
+		}
+	}
+
+	if message.Video != nil {
+		if !c.ShouldDownloadMedia(config.MediaVideo) {
+			addTag(channels.SkippedMedia(config.MediaVideo, message.Video.FileName))
+		} else if videoPath := c.downloadFile(ctx, message.Video.FileID, ".mp4"); videoPath != "" {
+			mediaPaths = append(mediaPaths, storeMedia(videoPath, "video.mp4"))
+			addTag("[video]")
 		}
 	}
 
 	if message.Document != nil {
-		docPath := c.downloadFile(ctx, message.Document.FileID, "")
-		if docPath != "" {
+		if !c.ShouldDownloadMedia(config.MediaFile) {
+			addTag(channels.SkippedMedia(config.MediaFile, message.Document.FileName))
+		} else if docPath := c.downloadFile(ctx, message.Document.FileID, ""); docPath != "" {
 			mediaPaths = append(mediaPaths, storeMedia(docPath, "document"))
-			if content != "" {
-				content += "\n"
-			}
-			content += "[file]"
+			addTag("[file]")
 		}
 	}
 
@@ -529,7 +678,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 
 	logger.DebugCF("telegram", "Received message", map[string]any{
 		"sender_id": sender.CanonicalID,
-		"chat_id":   fmt.Sprintf("%d", chatID),
+		"chat_id":   chatIDStr,
 		"preview":   utils.Truncate(content, 50),
 	})
 
@@ -539,7 +688,7 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 	peerID := fmt.Sprintf("%d", user.ID)
 	if message.Chat.Type != "private" {
 		peerKind = "group"
-		peerID = fmt.Sprintf("%d", chatID)
+		peerID = chatIDStr
 	}
 
 	peer := bus.Peer{Kind: peerKind, ID: peerID}
@@ -551,12 +700,23 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 		"first_name": user.FirstName,
 		"is_group":   fmt.Sprintf("%t", message.Chat.Type != "private"),
 	}
+	if threadID != 0 {
+		metadata["message_thread_id"] = strconv.Itoa(threadID)
+		// Bindings for the group keep matching its topics
+		metadata["parent_peer_kind"] = "group"
+		metadata["parent_peer_id"] = strconv.FormatInt(chatID, 10)
+	}
+	if message.EditDate != 0 {
+		metadata["edited"] = "true"
+		c.publishEdit(platformID, chatIDStr, messageID, content, mediaPaths, metadata)
+		return nil
+	}
 
 	c.HandleMessage(c.ctx,
 		peer,
 		messageID,
 		platformID,
-		fmt.Sprintf("%d", chatID),
+		chatIDStr,
 		content,
 		mediaPaths,
 		metadata,
@@ -565,6 +725,68 @@ func (c *TelegramChannel) handleMessage(ctx context.Context, message *telego.Mes
 	return nil
 }
 
+// handleEditedMessage handles edited_message updates. With reprocess_edits
+// enabled the edit goes to the agent through HandleEditedMessage; otherwise
+// the edit is acknowledged with a 📝 reaction so the user knows it was seen.
+func (c *TelegramChannel) handleEditedMessage(ctx context.Context, message *telego.Message) error {
+	if message == nil || message.From == nil {
+		return nil
+	}
+
+	if c.config.Channels.Telegram.ReprocessEdits {
+		return c.handleMessage(ctx, message)
+	}
+
+	platformID := fmt.Sprintf("%d", message.From.ID)
+	sender := bus.SenderInfo{
+		Platform:    "telegram",
+		PlatformID:  platformID,
+		CanonicalID: identity.BuildCanonicalID("telegram", platformID),
+		Username:    message.From.Username,
+	}
+	if !c.IsAllowedSender(sender) {
+		return nil
+	}
+
+	if err := c.setReaction(ctx, message.Chat.ID, message.MessageID, editedReaction); err != nil {
+		logger.DebugCF("telegram", "Failed to acknowledge edited message", map[string]any{
+			"chat_id": message.Chat.ID,
+			"error":   err.Error(),
+		})
+	}
+	return nil
+}
+
+// HandleEditedMessage passes an edit to the agent, which updates the original
+// message in the conversation and answers the edited text.
+func (c *TelegramChannel) HandleEditedMessage(
+	senderID, chatID, originalMessageID, newContent string,
+	metadata map[string]string,
+) {
+	c.publishEdit(senderID, chatID, originalMessageID, newContent, nil, metadata)
+}
+
+// publishEdit is HandleEditedMessage with the attachments of the edited
+// message, so an edited caption keeps its photo.
+func (c *TelegramChannel) publishEdit(
+	senderID, chatID, originalMessageID, newContent string,
+	media []string,
+	metadata map[string]string,
+) {
+	peer := bus.Peer{Kind: "direct", ID: metadata["user_id"]}
+	if metadata["is_group"] == "true" {
+		peer = bus.Peer{Kind: "group", ID: chatID}
+	}
+	sender := bus.SenderInfo{
+		Platform:    "telegram",
+		PlatformID:  senderID,
+		CanonicalID: identity.BuildCanonicalID("telegram", senderID),
+		Username:    metadata["username"],
+		DisplayName: metadata["first_name"],
+	}
+	c.PublishEdit(c.ctx, peer, senderID, chatID, originalMessageID, newContent, media, metadata, sender)
+}
+
 func (c *TelegramChannel) downloadPhoto(ctx context.Context, fileID string) string {
 	file, err := c.bot.GetFile(ctx, &telego.GetFileParams{FileID: fileID})
 	if err != nil {
@@ -604,9 +826,9 @@ func (c *TelegramChannel) downloadFile(ctx context.Context, fileID, ext string)
 	return c.downloadFileWithInfo(file, ext)
 }
 
+// parseChatID returns the Telegram chat of a chat ID, ignoring any topic.
 func parseChatID(chatIDStr string) (int64, error) {
-	var id int64
-	_, err := fmt.Sscanf(chatIDStr, "%d", &id)
+	id, _, err := parseChatTarget(chatIDStr)
 	return id, err
 }
 
@@ -715,38 +937,29 @@ func escapeHTML(text string) string {
 	return text
 }
 
-// isBotMentioned checks if the bot is mentioned in the message via entities.
+// isBotMentioned checks if the bot is mentioned by @username or, for users
+// without one, through a text_mention entity.
 func (c *TelegramChannel) isBotMentioned(message *telego.Message) bool {
 	botUsername := c.bot.Username()
 	if botUsername == "" {
 		return false
 	}
 
+	text := message.Text
+	if text == "" {
+		text = message.Caption
+	}
+	if channels.MentionsBot(channels.TelegramMentionParser{}.ParseMentions(text), botUsername) {
+		return true
+	}
+
 	entities := message.Entities
 	if entities == nil {
 		entities = message.CaptionEntities
 	}
-
 	for _, entity := range entities {
-		if entity.Type == "mention" {
-			// Extract the mention text from the message
-			text := message.Text
-			if text == "" {
-				text = message.Caption
-			}
-			runes := []rune(text)
-			end := entity.Offset + entity.Length
-			if end <= len(runes) {
-				mention := string(runes[entity.Offset:end])
-				if strings.EqualFold(mention, "@"+botUsername) {
-					return true
-				}
-			}
-		}
-		if entity.Type == "text_mention" && entity.User != nil {
-			if entity.User.Username == botUsername {
-				return true
-			}
+		if entity.Type == "text_mention" && entity.User != nil && entity.User.Username == botUsername {
+			return true
 		}
 	}
 	return false
@@ -758,8 +971,6 @@ func (c *TelegramChannel) stripBotMention(content string) string {
 	if botUsername == "" {
 		return content
 	}
-	// Case-insensitive replacement
-	re := regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(botUsername))
-	content = re.ReplaceAllString(content, "")
-	return strings.TrimSpace(content)
+	p := channels.TelegramMentionParser{}
+	return p.StripMentions(content, p.ParseMentions(content), botUsername)
 }
@@ -42,10 +42,15 @@ func (c *cmd) Help(ctx context.Context, message telego.Message) error {
 /help - Show this help message
 /show [model|channel] - Show current configuration
 /list [models|channels] - List available options
+/reset - Clear the conversation history
+/model [name|default] - Show or switch the model for this chat
+/status - Show session usage for this chat
+/skills - List installed skills
 	`
 	_, err := c.bot.SendMessage(ctx, &telego.SendMessageParams{
-		ChatID: telego.ChatID{ID: message.Chat.ID},
-		Text:   msg,
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: messageThreadID(&message),
+		Text:            msg,
 		ReplyParameters: &telego.ReplyParameters{
 			MessageID: message.MessageID,
 		},
@@ -55,8 +60,9 @@ func (c *cmd) Help(ctx context.Context, message telego.Message) error {
 
 func (c *cmd) Start(ctx context.Context, message telego.Message) error {
 	_, err := c.bot.SendMessage(ctx, &telego.SendMessageParams{
-		ChatID: telego.ChatID{ID: message.Chat.ID},
-		Text:   "Hello! I am PicoClaw 🦞",
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: messageThreadID(&message),
+		Text:            "Hello! I am PicoClaw 🦞",
 		ReplyParameters: &telego.ReplyParameters{
 			MessageID: message.MessageID,
 		},
@@ -68,8 +74,9 @@ func (c *cmd) Show(ctx context.Context, message telego.Message) error {
 	args := commandArgs(message.Text)
 	if args == "" {
 		_, err := c.bot.SendMessage(ctx, &telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: message.Chat.ID},
-			Text:   "Usage: /show [model|channel]",
+			ChatID:          telego.ChatID{ID: message.Chat.ID},
+			MessageThreadID: messageThreadID(&message),
+			Text:            "Usage: /show [model|channel]",
 			ReplyParameters: &telego.ReplyParameters{
 				MessageID: message.MessageID,
 			},
@@ -90,8 +97,9 @@ func (c *cmd) Show(ctx context.Context, message telego.Message) error {
 	}
 
 	_, err := c.bot.SendMessage(ctx, &telego.SendMessageParams{
-		ChatID: telego.ChatID{ID: message.Chat.ID},
-		Text:   response,
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: messageThreadID(&message),
+		Text:            response,
 		ReplyParameters: &telego.ReplyParameters{
 			MessageID: message.MessageID,
 		},
@@ -103,8 +111,9 @@ func (c *cmd) List(ctx context.Context, message telego.Message) error {
 	args := commandArgs(message.Text)
 	if args == "" {
 		_, err := c.bot.SendMessage(ctx, &telego.SendMessageParams{
-			ChatID: telego.ChatID{ID: message.Chat.ID},
-			Text:   "Usage: /list [models|channels]",
+			ChatID:          telego.ChatID{ID: message.Chat.ID},
+			MessageThreadID: messageThreadID(&message),
+			Text:            "Usage: /list [models|channels]",
 			ReplyParameters: &telego.ReplyParameters{
 				MessageID: message.MessageID,
 			},
@@ -146,8 +155,9 @@ func (c *cmd) List(ctx context.Context, message telego.Message) error {
 	}
 
 	_, err := c.bot.SendMessage(ctx, &telego.SendMessageParams{
-		ChatID: telego.ChatID{ID: message.Chat.ID},
-		Text:   response,
+		ChatID:          telego.ChatID{ID: message.Chat.ID},
+		MessageThreadID: messageThreadID(&message),
+		Text:            response,
 		ReplyParameters: &telego.ReplyParameters{
 			MessageID: message.MessageID,
 		},
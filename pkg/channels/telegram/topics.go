@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mymmrac/telego"
+)
+
+// topicSeparator joins a forum chat ID and a topic's thread ID into the chat
+// ID the rest of picoclaw sees, e.g. "-1001234567890:topic:42". Each topic
+// thereby gets its own session, and replies find their way back to it.
+const topicSeparator = ":topic:"
+
+// messageThreadID returns the forum topic a message was sent in, or 0 for
+// messages outside topics, including the General topic. Replies in groups
+// without topics also carry a thread ID, which is ignored.
+func messageThreadID(message *telego.Message) int {
+	if message == nil || !message.IsTopicMessage {
+		return 0
+	}
+	return message.MessageThreadID
+}
+
+// topicChatID returns the chat ID of a chat, or of one of its topics.
+func topicChatID(chatID int64, threadID int) string {
+	if threadID == 0 {
+		return strconv.FormatInt(chatID, 10)
+	}
+	return fmt.Sprintf("%d%s%d", chatID, topicSeparator, threadID)
+}
+
+// parseChatTarget splits a chat ID built by topicChatID.
+func parseChatTarget(chatIDStr string) (chatID int64, threadID int, err error) {
+	chatPart, threadPart, hasTopic := strings.Cut(chatIDStr, topicSeparator)
+	if chatID, err = strconv.ParseInt(chatPart, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid chat ID %q", chatIDStr)
+	}
+	if hasTopic {
+		if threadID, err = strconv.Atoi(threadPart); err != nil || threadID <= 0 {
+			return 0, 0, fmt.Errorf("invalid topic in chat ID %q", chatIDStr)
+		}
+	}
+	return chatID, threadID, nil
+}
+
+// topicAllowed reports whether the bot may answer in a topic. Entries of
+// channels.telegram.topics are "<chat_id>:<thread_id>",
+// "<chat_id>:topic:<thread_id>" or a bare "<thread_id>" for any chat. An
+// empty list allows every topic; messages outside topics are not affected.
+func topicAllowed(allowed []string, chatID int64, threadID int) bool {
+	if len(allowed) == 0 || threadID == 0 {
+		return true
+	}
+	chat, thread := strconv.FormatInt(chatID, 10), strconv.Itoa(threadID)
+	for _, entry := range allowed {
+		entry = strings.ReplaceAll(strings.TrimSpace(entry), topicSeparator, ":")
+		entryChat, entryThread, ok := strings.Cut(entry, ":")
+		if !ok {
+			entryChat, entryThread = chat, entry
+		}
+		if entryChat == chat && entryThread == thread {
+			return true
+		}
+	}
+	return false
+}
+
+// isTopicClosedError reports whether Telegram refused a send because the
+// topic is closed.
+func isTopicClosedError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "TOPIC_CLOSED")
+}
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mymmrac/telego"
+)
+
+func TestTopicChatIDRoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		chatID   int64
+		threadID int
+		want     string
+	}{
+		{-1001234567890, 42, "-1001234567890:topic:42"},
+		{-1001234567890, 0, "-1001234567890"},
+		{123456, 0, "123456"},
+	} {
+		got := topicChatID(tt.chatID, tt.threadID)
+		if got != tt.want {
+			t.Errorf("topicChatID(%d, %d) = %q, want %q", tt.chatID, tt.threadID, got, tt.want)
+		}
+		chatID, threadID, err := parseChatTarget(got)
+		if err != nil || chatID != tt.chatID || threadID != tt.threadID {
+			t.Errorf("parseChatTarget(%q) = %d, %d, %v", got, chatID, threadID, err)
+		}
+	}
+
+	for _, bad := range []string{"", "abc", "-100:topic:x", "-100:topic:0"} {
+		if _, _, err := parseChatTarget(bad); err == nil {
+			t.Errorf("parseChatTarget(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestMessageThreadID(t *testing.T) {
+	topic := &telego.Message{MessageThreadID: 42, IsTopicMessage: true}
+	if got := messageThreadID(topic); got != 42 {
+		t.Errorf("topic message thread = %d, want 42", got)
+	}
+	// In groups without topics, replies carry a thread ID too.
+	reply := &telego.Message{MessageThreadID: 7}
+	if got := messageThreadID(reply); got != 0 {
+		t.Errorf("reply thread = %d, want 0", got)
+	}
+}
+
+func TestTopicAllowed(t *testing.T) {
+	allowed := []string{"-1001:42", "-1002:topic:7", "9"}
+	tests := []struct {
+		chatID   int64
+		threadID int
+		want     bool
+	}{
+		{-1001, 42, true},
+		{-1001, 7, false},
+		{-1002, 7, true},
+		{-1003, 9, true},
+		{-1003, 10, false},
+		{-1003, 0, true}, // outside topics
+	}
+	for _, tt := range tests {
+		if got := topicAllowed(allowed, tt.chatID, tt.threadID); got != tt.want {
+			t.Errorf("topicAllowed(%d, %d) = %v, want %v", tt.chatID, tt.threadID, got, tt.want)
+		}
+	}
+	if !topicAllowed(nil, -1001, 42) {
+		t.Error("an empty allowlist should allow every topic")
+	}
+}
+
+func TestIsTopicClosedError(t *testing.T) {
+	if !isTopicClosedError(errors.New("api: 400 Bad Request: TOPIC_CLOSED")) {
+		t.Error("expected TOPIC_CLOSED to be detected")
+	}
+	if isTopicClosedError(errors.New("api: 429 Too Many Requests")) || isTopicClosedError(nil) {
+		t.Error("unexpected topic closed match")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/channels"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/i18n"
 	"github.com/sipeed/picoclaw/pkg/identity"
 	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/utils"
@@ -168,7 +169,7 @@ func (c *WeComAIBotChannel) Start(ctx context.Context) error {
 	c.ctx, c.cancel = context.WithCancel(ctx)
 
 	// Start cleanup goroutine for old tasks
-	go c.cleanupLoop()
+	c.Go(c.cleanupLoop)
 
 	c.SetRunning(true)
 	logger.InfoC("wecom_aibot", "WeCom AI Bot channel started")
@@ -474,7 +475,7 @@ func (c *WeComAIBotChannel) processMessage(
 			Stream: WeComAIBotStreamInfo{
 				ID:      c.generateStreamID(),
 				Finish:  true,
-				Content: "Unsupported message type: " + msg.MsgType,
+				Content: i18n.T(c.Language(), i18n.WeComUnsupportedType, msg.MsgType),
 			},
 		})
 	}
@@ -533,7 +534,7 @@ func (c *WeComAIBotChannel) handleTextMessage(
 
 	// Publish to agent asynchronously; agent will call Send() with reply.
 	// Use task.ctx (not c.ctx) so the agent goroutine is canceled when the task is removed.
-	go func() {
+	c.Go(func() {
 		sender := bus.SenderInfo{
 			Platform:    "wecom_aibot",
 			PlatformID:  userID,
@@ -556,7 +557,7 @@ func (c *WeComAIBotChannel) handleTextMessage(
 		}
 		c.HandleMessage(task.ctx, peer, msg.MsgID, userID, chatID,
 			content, nil, metadata, sender)
-	}()
+	})
 
 	// Return first streaming response immediately (finish=false, content empty)
 	return c.getStreamResponse(task, timestamp, nonce)
@@ -592,7 +593,7 @@ func (c *WeComAIBotChannel) handleStreamMessage(
 			Stream: WeComAIBotStreamInfo{
 				ID:      streamID,
 				Finish:  true,
-				Content: "Task not found or already finished. Please resend your message to start a new session.",
+				Content: i18n.T(c.Language(), i18n.WeComTaskNotFound),
 			},
 		})
 	}
@@ -619,12 +620,9 @@ func (c *WeComAIBotChannel) handleImageMessage(
 	return c.encryptResponse("", timestamp, nonce, WeComAIBotStreamResponse{
 		MsgType: "stream",
 		Stream: WeComAIBotStreamInfo{
-			ID:     c.generateStreamID(),
-			Finish: true,
-			Content: fmt.Sprintf(
-				"Image received (URL: %s), but image messages are not yet supported",
-				imageURL,
-			),
+			ID:      c.generateStreamID(),
+			Finish:  true,
+			Content: i18n.T(c.Language(), i18n.WeComImageUnsupported, imageURL),
 		},
 	})
 }
@@ -641,7 +639,7 @@ func (c *WeComAIBotChannel) handleMixedMessage(
 		Stream: WeComAIBotStreamInfo{
 			ID:      c.generateStreamID(),
 			Finish:  true,
-			Content: "Mixed message type is not yet supported",
+			Content: i18n.T(c.Language(), i18n.WeComMixedUnsupported),
 		},
 	})
 }
@@ -170,7 +170,7 @@ func (c *WeComAppChannel) Start(ctx context.Context) error {
 	}
 
 	// Start token refresh goroutine
-	go c.tokenRefreshLoop()
+	c.Go(c.tokenRefreshLoop)
 
 	c.SetRunning(true)
 	logger.InfoC("wecom_app", "WeCom App channel started")
@@ -586,7 +586,7 @@ func (c *WeComAppChannel) handleMessageCallback(ctx context.Context, w http.Resp
 
 	// Process the message with the channel's long-lived context (not the HTTP
 	// request context, which is canceled as soon as we return the response).
-	go c.processMessage(c.ctx, msg)
+	c.Go(func() { c.processMessage(c.ctx, msg) })
 
 	// Return success response immediately
 	// WeCom App requires response within configured timeout (default 5 seconds)
@@ -308,7 +308,7 @@ func (c *WeComBotChannel) handleMessageCallback(ctx context.Context, w http.Resp
 
 	// Process the message with the channel's long-lived context (not the HTTP
 	// request context, which is canceled as soon as we return the response).
-	go c.processMessage(c.ctx, msg)
+	c.Go(func() { c.processMessage(c.ctx, msg) })
 
 	// Return success response immediately
 	// WeCom Bot requires response within configured timeout (default 5 seconds)
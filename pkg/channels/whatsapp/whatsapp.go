@@ -73,7 +73,7 @@ func (c *WhatsAppChannel) Start(ctx context.Context) error {
 	c.SetRunning(true)
 	logger.InfoC("whatsapp", "WhatsApp channel connected")
 
-	go c.listen()
+	c.Go(c.listen)
 
 	return nil
 }
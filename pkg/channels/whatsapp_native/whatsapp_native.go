@@ -175,7 +175,7 @@ func (c *WhatsAppNativeChannel) Start(ctx context.Context) error {
 		}
 		c.wg.Add(1)
 		c.reconnectMu.Unlock()
-		go func() {
+		c.Go(func() {
 			defer c.wg.Done()
 			for {
 				select {
@@ -197,7 +197,7 @@ func (c *WhatsAppNativeChannel) Start(ctx context.Context) error {
 					}
 				}
 			}
-		}()
+		})
 	} else {
 		if err := client.Connect(); err != nil {
 			return fmt.Errorf("connect: %w", err)
@@ -287,10 +287,10 @@ func (c *WhatsAppNativeChannel) eventHandler(evt any) {
 		c.reconnecting = true
 		c.wg.Add(1)
 		c.reconnectMu.Unlock()
-		go func() {
+		c.Go(func() {
 			defer c.wg.Done()
 			c.reconnectWithBackoff()
-		}()
+		})
 	}
 }
 
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 
@@ -48,16 +52,24 @@ func (f *FlexibleStringSlice) UnmarshalJSON(data []byte) error {
 }
 
 type Config struct {
-	Agents    AgentsConfig    `json:"agents"`
-	Bindings  []AgentBinding  `json:"bindings,omitempty"`
-	Session   SessionConfig   `json:"session,omitempty"`
-	Channels  ChannelsConfig  `json:"channels"`
-	Providers ProvidersConfig `json:"providers,omitempty"`
-	ModelList []ModelConfig   `json:"model_list"` // New model-centric provider configuration
-	Gateway   GatewayConfig   `json:"gateway"`
-	Tools     ToolsConfig     `json:"tools"`
-	Heartbeat HeartbeatConfig `json:"heartbeat"`
-	Devices   DevicesConfig   `json:"devices"`
+	Agents     AgentsConfig     `json:"agents"`
+	Bindings   []AgentBinding   `json:"bindings,omitempty"`
+	Session    SessionConfig    `json:"session,omitempty"`
+	Channels   ChannelsConfig   `json:"channels"`
+	Providers  ProvidersConfig  `json:"providers,omitempty"`
+	ModelList  []ModelConfig    `json:"model_list"` // New model-centric provider configuration
+	Gateway    GatewayConfig    `json:"gateway"`
+	Tools      ToolsConfig      `json:"tools"`
+	Heartbeat  HeartbeatConfig  `json:"heartbeat"`
+	Devices    DevicesConfig    `json:"devices"`
+	Commands   CommandsConfig   `json:"commands"`
+	Moderation ModerationConfig `json:"moderation"`
+	RateLimit  RateLimitConfig  `json:"rate_limit"`
+	Budget     BudgetConfig     `json:"budget"`
+	Security   SecurityConfig   `json:"security"`
+
+	// secretRefs maps secrets read from files back to their references.
+	secretRefs map[string]string
 }
 
 // MarshalJSON implements custom JSON marshaling for Config
@@ -88,6 +100,64 @@ func (c Config) MarshalJSON() ([]byte, error) {
 type AgentsConfig struct {
 	Defaults AgentDefaults `json:"defaults"`
 	List     []AgentConfig `json:"list,omitempty"`
+	// Named defines agents by ID, e.g. "coder": {"model": "gpt4"}. Each is
+	// merged over Defaults like a List entry, and replaces a List entry with
+	// the same ID.
+	Named map[string]AgentConfig `json:"named,omitempty"`
+}
+
+// All returns the configured agents: List in order, then Named sorted by ID.
+func (a AgentsConfig) All() []AgentConfig {
+	if len(a.Named) == 0 {
+		return a.List
+	}
+	ids := make([]string, 0, len(a.Named))
+	for id := range a.Named {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	agents := make([]AgentConfig, 0, len(a.List)+len(ids))
+	for _, ac := range a.List {
+		if _, ok := a.Named[ac.ID]; !ok {
+			agents = append(agents, ac)
+		}
+	}
+	for _, id := range ids {
+		ac := a.Named[id]
+		ac.ID = id
+		agents = append(agents, ac)
+	}
+	return agents
+}
+
+// IDs returns the IDs of All, or the implicit "main" when none are defined.
+func (a AgentsConfig) IDs() []string {
+	agents := a.All()
+	if len(agents) == 0 {
+		return []string{"main"}
+	}
+	ids := make([]string, len(agents))
+	for i, ac := range agents {
+		ids[i] = ac.ID
+	}
+	return ids
+}
+
+// Lookup returns the agent with the given ID, or an error listing the
+// available agents.
+func (a AgentsConfig) Lookup(id string) (AgentConfig, error) {
+	id = strings.TrimSpace(id)
+	agents := a.All()
+	if len(agents) == 0 && strings.EqualFold(id, "main") {
+		return AgentConfig{ID: "main", Default: true}, nil
+	}
+	for _, ac := range agents {
+		if strings.EqualFold(ac.ID, id) {
+			return ac, nil
+		}
+	}
+	return AgentConfig{}, fmt.Errorf("unknown agent %q (available: %s)", id, strings.Join(a.IDs(), ", "))
 }
 
 // AgentModelConfig supports both string and structured model config.
@@ -137,6 +207,53 @@ type AgentConfig struct {
 	Model     *AgentModelConfig `json:"model,omitempty"`
 	Skills    []string          `json:"skills,omitempty"`
 	Subagents *SubagentsConfig  `json:"subagents,omitempty"`
+	// SystemPromptFile is added to the system prompt after the global
+	// identity; a relative path is resolved against the agent's workspace.
+	SystemPromptFile string `json:"system_prompt_file,omitempty"`
+	// Tools is the agent's tool policy: "none", "auto" or a comma-separated
+	// list of tool names. agents.defaults.channel_tools still wins.
+	Tools string `json:"tools,omitempty"`
+	// DegradedFallback overrides agents.defaults.degraded_fallback.
+	DegradedFallback *bool `json:"degraded_fallback,omitempty"`
+	// Limits overrides the agents.defaults.limits that it sets.
+	Limits *AgentLimitsOverride `json:"limits,omitempty"`
+}
+
+// AgentLimits bounds each model call of an agent. Zero means no limit.
+type AgentLimits struct {
+	// RequestTimeoutSeconds is how long one model call may take; a call that
+	// runs longer is cancelled and retried like a provider timeout.
+	RequestTimeoutSeconds int `json:"request_timeout_seconds" env:"PICOCLAW_AGENTS_DEFAULTS_LIMITS_REQUEST_TIMEOUT_SECONDS"`
+	// MaxOutputTokens caps the tokens the model may write per call.
+	MaxOutputTokens int `json:"max_output_tokens" env:"PICOCLAW_AGENTS_DEFAULTS_LIMITS_MAX_OUTPUT_TOKENS"`
+	// MaxInputTokens caps the estimated size of the prompt; the oldest
+	// history is left out of a prompt that is larger.
+	MaxInputTokens int `json:"max_input_tokens" env:"PICOCLAW_AGENTS_DEFAULTS_LIMITS_MAX_INPUT_TOKENS"`
+}
+
+// AgentLimitsOverride is an agent's own limits. A field left out keeps the
+// default; one set to 0 removes it.
+type AgentLimitsOverride struct {
+	RequestTimeoutSeconds *int `json:"request_timeout_seconds,omitempty"`
+	MaxOutputTokens       *int `json:"max_output_tokens,omitempty"`
+	MaxInputTokens        *int `json:"max_input_tokens,omitempty"`
+}
+
+// Merge returns l with the limits that o sets replacing its own.
+func (l AgentLimits) Merge(o *AgentLimitsOverride) AgentLimits {
+	if o == nil {
+		return l
+	}
+	if o.RequestTimeoutSeconds != nil {
+		l.RequestTimeoutSeconds = *o.RequestTimeoutSeconds
+	}
+	if o.MaxOutputTokens != nil {
+		l.MaxOutputTokens = *o.MaxOutputTokens
+	}
+	if o.MaxInputTokens != nil {
+		l.MaxInputTokens = *o.MaxInputTokens
+	}
+	return l
 }
 
 type SubagentsConfig struct {
@@ -180,6 +297,129 @@ type AgentDefaults struct {
 	MaxTokens                 int      `json:"max_tokens"                      env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOKENS"`
 	Temperature               *float64 `json:"temperature,omitempty"           env:"PICOCLAW_AGENTS_DEFAULTS_TEMPERATURE"`
 	MaxToolIterations         int      `json:"max_tool_iterations"             env:"PICOCLAW_AGENTS_DEFAULTS_MAX_TOOL_ITERATIONS"`
+	// ChannelTools maps a channel name to a tool policy: "none", "auto" or a
+	// comma-separated list of tool names.
+	ChannelTools map[string]string `json:"channel_tools,omitempty"`
+	// DegradedFallback retries a failed turn once without tools and with a
+	// trimmed prompt, and sends that answer marked as degraded instead of
+	// an error.
+	DegradedFallback bool `json:"degraded_fallback" env:"PICOCLAW_AGENTS_DEFAULTS_DEGRADED_FALLBACK"`
+	// EmptyResponse is sent when the model answers with no content twice in
+	// a row. Empty uses a notice in the bot language.
+	EmptyResponse string `json:"empty_response,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_EMPTY_RESPONSE"`
+	// CoalesceWindowSeconds, when positive, makes an identical model request
+	// share the call of one still in flight that started at most this many
+	// seconds earlier.
+	CoalesceWindowSeconds int `json:"coalesce_window_seconds,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_COALESCE_WINDOW_SECONDS"`
+	// SummaryModel is the model_list entry that distills old turns into
+	// session notes. Empty uses the session's own model.
+	SummaryModel string `json:"summary_model,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARY_MODEL"`
+	// SummaryMaxTokens caps the notes kept in the prompt; longer notes are
+	// summarized again. 0 uses 800.
+	SummaryMaxTokens int `json:"summary_max_tokens,omitempty" env:"PICOCLAW_AGENTS_DEFAULTS_SUMMARY_MAX_TOKENS"`
+	// Limits bounds each model call; agents can override single limits.
+	Limits AgentLimits `json:"limits"`
+	// AutoRoute sends each message of agents on the default model to the
+	// cheapest tier that suits it.
+	AutoRoute AutoRouteConfig `json:"auto_route"`
+}
+
+// AutoRouteConfig picks a model per message. Tiers go from cheapest to most
+// capable; a message gets the first tier that suits it, and the last tier
+// takes everything else. A message goes straight to the last tier when the
+// previous turn used tools or it contains one of EscalateKeywords.
+// Classifier, a model_list name such as a small local model, picks the tier
+// instead of the heuristics when set.
+type AutoRouteConfig struct {
+	Enabled          bool            `json:"enabled"                      env:"PICOCLAW_AGENTS_DEFAULTS_AUTO_ROUTE_ENABLED"`
+	Tiers            []AutoRouteTier `json:"tiers,omitempty"`
+	Classifier       string          `json:"classifier,omitempty"         env:"PICOCLAW_AGENTS_DEFAULTS_AUTO_ROUTE_CLASSIFIER"`
+	EscalateKeywords []string        `json:"escalate_keywords,omitempty"`
+}
+
+// AutoRouteTier is one model tier. A message suits it when it is at most
+// MaxChars long (0 is unlimited), has no code unless Code is set, and seems
+// to need no tools unless Tools is set.
+type AutoRouteTier struct {
+	Name     string `json:"name"`
+	Model    string `json:"model"` // model_list name
+	MaxChars int    `json:"max_chars,omitempty"`
+	Code     bool   `json:"code,omitempty"`
+	Tools    bool   `json:"tools,omitempty"`
+}
+
+// Tier returns the tier called name.
+func (c AutoRouteConfig) Tier(name string) (AutoRouteTier, bool) {
+	for _, t := range c.Tiers {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return AutoRouteTier{}, false
+}
+
+// TierNames returns the names of the tiers, cheapest first.
+func (c AutoRouteConfig) TierNames() []string {
+	names := make([]string, len(c.Tiers))
+	for i, t := range c.Tiers {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// ValidateChannelTools checks that every agents.defaults.channel_tools entry
+// is "none", "auto" or a list naming at least one tool, so a typo is not
+// silently read as "no restriction".
+func (c *Config) ValidateChannelTools() error {
+	for channel, spec := range c.Agents.Defaults.ChannelTools {
+		switch strings.ToLower(strings.TrimSpace(spec)) {
+		case "", "auto", "none":
+			continue
+		}
+		named := false
+		for _, name := range strings.Split(spec, ",") {
+			if strings.TrimSpace(name) != "" {
+				named = true
+			}
+		}
+		if !named {
+			return fmt.Errorf("agents.defaults.channel_tools.%s: invalid tools value %q: "+
+				"expected none, auto or a comma-separated list", channel, spec)
+		}
+	}
+	return nil
+}
+
+// ValidateAutoRoute checks that the tiers are named uniquely and use
+// model_list entries.
+func (c *Config) ValidateAutoRoute() error {
+	ar := c.Agents.Defaults.AutoRoute
+	if !ar.Enabled {
+		return nil
+	}
+	if len(ar.Tiers) == 0 {
+		return fmt.Errorf("agents.defaults.auto_route: at least one tier is required")
+	}
+	seen := make(map[string]bool)
+	for i, t := range ar.Tiers {
+		key := strings.ToLower(t.Name)
+		if key == "" || key == "auto" {
+			return fmt.Errorf("agents.defaults.auto_route.tiers[%d]: name must be set and not \"auto\"", i)
+		}
+		if seen[key] {
+			return fmt.Errorf("agents.defaults.auto_route.tiers[%d]: duplicate name %q", i, t.Name)
+		}
+		seen[key] = true
+		if _, err := c.GetModelConfig(t.Model); err != nil {
+			return fmt.Errorf("agents.defaults.auto_route.tiers[%d] (%s): %w", i, t.Name, err)
+		}
+	}
+	if ar.Classifier != "" {
+		if _, err := c.GetModelConfig(ar.Classifier); err != nil {
+			return fmt.Errorf("agents.defaults.auto_route.classifier: %w", err)
+		}
+	}
+	return nil
 }
 
 // GetModelName returns the effective model name for the agent defaults.
@@ -208,12 +448,220 @@ type ChannelsConfig struct {
 	Pico       PicoConfig       `json:"pico"`
 }
 
+// ChatConfig holds per-chat settings under channels.<name>.chats.<chat_id>.
+type ChatConfig struct {
+	Model string `json:"model,omitempty"` // model_list name used for this chat
+}
+
+// ChatConfigs returns the per-chat settings of the named channel.
+func (c *ChannelsConfig) ChatConfigs(channel string) map[string]ChatConfig {
+	switch channel {
+	case "whatsapp", "whatsapp_native":
+		return c.WhatsApp.Chats
+	case "telegram":
+		return c.Telegram.Chats
+	case "feishu":
+		return c.Feishu.Chats
+	case "discord":
+		return c.Discord.Chats
+	case "maixcam":
+		return c.MaixCam.Chats
+	case "qq":
+		return c.QQ.Chats
+	case "dingtalk":
+		return c.DingTalk.Chats
+	case "slack":
+		return c.Slack.Chats
+	case "line":
+		return c.LINE.Chats
+	case "onebot":
+		return c.OneBot.Chats
+	case "wecom":
+		return c.WeCom.Chats
+	case "wecom_app":
+		return c.WeComApp.Chats
+	case "wecom_aibot":
+		return c.WeComAIBot.Chats
+	case "pico":
+		return c.Pico.Chats
+	}
+	return nil
+}
+
+// AllChatConfigs returns the per-chat settings of every channel that has
+// any, keyed by channel name. WhatsApp chats are listed under the name the
+// channel runs as, whatsapp_native with use_native.
+func (c *ChannelsConfig) AllChatConfigs() map[string]map[string]ChatConfig {
+	all := make(map[string]map[string]ChatConfig)
+	whatsApp := "whatsapp"
+	if c.WhatsApp.UseNative {
+		whatsApp = "whatsapp_native"
+	}
+	for _, channel := range chatChannels {
+		if strings.HasPrefix(channel, "whatsapp") && channel != whatsApp {
+			continue
+		}
+		if chats := c.ChatConfigs(channel); len(chats) > 0 {
+			all[channel] = chats
+		}
+	}
+	return all
+}
+
+// chatChannels lists the channel names accepted by ChatConfigs.
+var chatChannels = []string{
+	"whatsapp", "whatsapp_native", "telegram", "feishu", "discord", "maixcam", "qq", "dingtalk",
+	"slack", "line", "onebot", "wecom", "wecom_app", "wecom_aibot", "pico",
+}
+
+// enabledFlag returns the enabled flag of the named channel, or nil if no
+// channel has that name.
+func (c *ChannelsConfig) enabledFlag(channel string) *bool {
+	switch channel {
+	case "whatsapp", "whatsapp_native":
+		return &c.WhatsApp.Enabled
+	case "telegram":
+		return &c.Telegram.Enabled
+	case "feishu":
+		return &c.Feishu.Enabled
+	case "discord":
+		return &c.Discord.Enabled
+	case "maixcam":
+		return &c.MaixCam.Enabled
+	case "qq":
+		return &c.QQ.Enabled
+	case "dingtalk":
+		return &c.DingTalk.Enabled
+	case "slack":
+		return &c.Slack.Enabled
+	case "line":
+		return &c.LINE.Enabled
+	case "onebot":
+		return &c.OneBot.Enabled
+	case "wecom":
+		return &c.WeCom.Enabled
+	case "wecom_app":
+		return &c.WeComApp.Enabled
+	case "wecom_aibot":
+		return &c.WeComAIBot.Enabled
+	case "pico":
+		return &c.Pico.Enabled
+	}
+	return nil
+}
+
+// enableConfigured turns on the channels that data configures without
+// setting enabled, so "enabled": false is only needed to turn one off.
+func (c *ChannelsConfig) enableConfigured(data []byte) error {
+	var raw struct {
+		Channels map[string]map[string]json.RawMessage `json:"channels"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for name, section := range raw.Channels {
+		if _, set := section["enabled"]; set || len(section) == 0 {
+			continue
+		}
+		if flag := c.enabledFlag(name); flag != nil {
+			*flag = true
+		}
+	}
+	return nil
+}
+
+// Restrict turns channels off for one run: when only is not empty every
+// channel not in it, and every channel in disable. Channels it keeps still
+// need to be enabled in the config. An unknown channel name is an error.
+func (c *ChannelsConfig) Restrict(only, disable []string) error {
+	for _, name := range slices.Concat(only, disable) {
+		if c.enabledFlag(name) == nil {
+			return fmt.Errorf("unknown channel %q (known: %s)", name, strings.Join(chatChannels, ", "))
+		}
+	}
+	if len(only) > 0 {
+		keep := make(map[*bool]bool, len(only))
+		for _, name := range only {
+			keep[c.enabledFlag(name)] = true
+		}
+		for _, name := range chatChannels {
+			if flag := c.enabledFlag(name); !keep[flag] {
+				*flag = false
+			}
+		}
+	}
+	for _, name := range disable {
+		*c.enabledFlag(name) = false
+	}
+	return nil
+}
+
+// MediaConfigs returns the media settings of the channels that download
+// inbound media, by channel name.
+func (c *ChannelsConfig) MediaConfigs() map[string]MediaConfig {
+	return map[string]MediaConfig{
+		"telegram": c.Telegram.Media,
+		"discord":  c.Discord.Media,
+		"slack":    c.Slack.Media,
+		"line":     c.LINE.Media,
+		"onebot":   c.OneBot.Media,
+	}
+}
+
+// ChatModel returns the model configured for a chat, or "" if none is set.
+func (c *ChannelsConfig) ChatModel(channel, chatID string) string {
+	chats := c.ChatConfigs(channel)
+	if chat, ok := chats[chatID]; ok {
+		return chat.Model
+	}
+	// A Telegram topic ("<chat_id>:topic:<thread_id>") uses its group's model.
+	if group, _, ok := strings.Cut(chatID, ":topic:"); ok {
+		return chats[group].Model
+	}
+	return ""
+}
+
 // GroupTriggerConfig controls when the bot responds in group chats.
 type GroupTriggerConfig struct {
 	MentionOnly bool     `json:"mention_only,omitempty"`
 	Prefixes    []string `json:"prefixes,omitempty"`
 }
 
+// Kinds of inbound media, see MediaConfig.
+const (
+	MediaImage = "image"
+	MediaAudio = "audio"
+	MediaVideo = "video"
+	MediaFile  = "file"
+)
+
+// MediaKinds lists the media kinds MediaConfig.Download accepts.
+var MediaKinds = []string{MediaImage, MediaAudio, MediaVideo, MediaFile}
+
+// MediaConfig controls which inbound media a channel downloads.
+type MediaConfig struct {
+	// Download lists the kinds to download; the agent is told about the
+	// others without them being fetched. Unset downloads everything and an
+	// empty list nothing.
+	Download []string `json:"download,omitempty"`
+	// MaxConcurrentDownloads limits how many attachments of one message are
+	// downloaded at once. 0 uses the default of 3.
+	MaxConcurrentDownloads int `json:"max_concurrent_downloads,omitempty"`
+}
+
+// Downloads reports whether media of kind should be downloaded.
+func (m MediaConfig) Downloads(kind string) bool {
+	if m.Download == nil {
+		return true
+	}
+	for _, k := range m.Download {
+		if strings.EqualFold(strings.TrimSpace(k), kind) {
+			return true
+		}
+	}
+	return false
+}
+
 // TypingConfig controls typing indicator behavior (Phase 10).
 type TypingConfig struct {
 	Enabled bool `json:"enabled,omitempty"`
@@ -222,173 +670,427 @@ type TypingConfig struct {
 // PlaceholderConfig controls placeholder message behavior (Phase 10).
 type PlaceholderConfig struct {
 	Enabled bool   `json:"enabled,omitempty"`
-	Text    string `json:"text,omitempty"`
+	Text    string `json:"text,omitempty"` // empty uses the gateway language's default
+}
+
+// ReactionConfig controls emoji reactions used to acknowledge inbound messages.
+type ReactionConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Pending string `json:"pending,omitempty"` // shown while the model is working (default 👀)
+	Done    string `json:"done,omitempty"`    // shown once the reply is sent (default ✅, "none" clears)
 }
 
 type WhatsAppConfig struct {
-	Enabled            bool                `json:"enabled"              env:"PICOCLAW_CHANNELS_WHATSAPP_ENABLED"`
-	BridgeURL          string              `json:"bridge_url"           env:"PICOCLAW_CHANNELS_WHATSAPP_BRIDGE_URL"`
-	UseNative          bool                `json:"use_native"           env:"PICOCLAW_CHANNELS_WHATSAPP_USE_NATIVE"`
-	SessionStorePath   string              `json:"session_store_path"   env:"PICOCLAW_CHANNELS_WHATSAPP_SESSION_STORE_PATH"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"           env:"PICOCLAW_CHANNELS_WHATSAPP_ALLOW_FROM"`
-	ReasoningChannelID string              `json:"reasoning_channel_id" env:"PICOCLAW_CHANNELS_WHATSAPP_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"              env:"PICOCLAW_CHANNELS_WHATSAPP_ENABLED"`
+	BridgeURL          string                `json:"bridge_url"           env:"PICOCLAW_CHANNELS_WHATSAPP_BRIDGE_URL"`
+	UseNative          bool                  `json:"use_native"           env:"PICOCLAW_CHANNELS_WHATSAPP_USE_NATIVE"`
+	SessionStorePath   string                `json:"session_store_path"   env:"PICOCLAW_CHANNELS_WHATSAPP_SESSION_STORE_PATH"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"           env:"PICOCLAW_CHANNELS_WHATSAPP_ALLOW_FROM"`
+	ReasoningChannelID string                `json:"reasoning_channel_id" env:"PICOCLAW_CHANNELS_WHATSAPP_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type TelegramConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_TELEGRAM_ENABLED"`
-	Token              string              `json:"token"                   env:"PICOCLAW_CHANNELS_TELEGRAM_TOKEN"`
-	Proxy              string              `json:"proxy"                   env:"PICOCLAW_CHANNELS_TELEGRAM_PROXY"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_TELEGRAM_ALLOW_FROM"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	Typing             TypingConfig        `json:"typing,omitempty"`
-	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_TELEGRAM_REASONING_CHANNEL_ID"`
+	Enabled        bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_TELEGRAM_ENABLED"`
+	Token          string              `json:"token"                   env:"PICOCLAW_CHANNELS_TELEGRAM_TOKEN"`
+	Proxy          string              `json:"proxy"                   env:"PICOCLAW_CHANNELS_TELEGRAM_PROXY"`
+	AllowFrom      FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_TELEGRAM_ALLOW_FROM"`
+	GroupTrigger   GroupTriggerConfig  `json:"group_trigger,omitempty"`
+	Media          MediaConfig         `json:"media,omitempty"`
+	Typing         TypingConfig        `json:"typing,omitempty"`
+	Placeholder    PlaceholderConfig   `json:"placeholder,omitempty"`
+	Reactions      ReactionConfig      `json:"reactions,omitempty"`
+	Topics         FlexibleStringSlice `json:"topics,omitempty"`
+	ReprocessEdits bool                `json:"reprocess_edits"         env:"PICOCLAW_CHANNELS_TELEGRAM_REPROCESS_EDITS"`
+	// MaxMessageAgeMinutes answers messages that waited longer than this,
+	// e.g. while the device was offline, with an apology instead of
+	// processing them. 0 processes every message.
+	MaxMessageAgeMinutes int                   `json:"max_message_age_minutes,omitempty" env:"PICOCLAW_CHANNELS_TELEGRAM_MAX_MESSAGE_AGE_MINUTES"`
+	ReasoningChannelID   string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_TELEGRAM_REASONING_CHANNEL_ID"`
+	Chats                map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type FeishuConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_FEISHU_ENABLED"`
-	AppID              string              `json:"app_id"                  env:"PICOCLAW_CHANNELS_FEISHU_APP_ID"`
-	AppSecret          string              `json:"app_secret"              env:"PICOCLAW_CHANNELS_FEISHU_APP_SECRET"`
-	EncryptKey         string              `json:"encrypt_key"             env:"PICOCLAW_CHANNELS_FEISHU_ENCRYPT_KEY"`
-	VerificationToken  string              `json:"verification_token"      env:"PICOCLAW_CHANNELS_FEISHU_VERIFICATION_TOKEN"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_FEISHU_ALLOW_FROM"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_FEISHU_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_FEISHU_ENABLED"`
+	AppID              string                `json:"app_id"                  env:"PICOCLAW_CHANNELS_FEISHU_APP_ID"`
+	AppSecret          string                `json:"app_secret"              env:"PICOCLAW_CHANNELS_FEISHU_APP_SECRET"`
+	EncryptKey         string                `json:"encrypt_key"             env:"PICOCLAW_CHANNELS_FEISHU_ENCRYPT_KEY"`
+	VerificationToken  string                `json:"verification_token"      env:"PICOCLAW_CHANNELS_FEISHU_VERIFICATION_TOKEN"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_FEISHU_ALLOW_FROM"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_FEISHU_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type DiscordConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_DISCORD_ENABLED"`
-	Token              string              `json:"token"                   env:"PICOCLAW_CHANNELS_DISCORD_TOKEN"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_DISCORD_ALLOW_FROM"`
-	MentionOnly        bool                `json:"mention_only"            env:"PICOCLAW_CHANNELS_DISCORD_MENTION_ONLY"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	Typing             TypingConfig        `json:"typing,omitempty"`
-	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_DISCORD_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_DISCORD_ENABLED"`
+	Token              string                `json:"token"                   env:"PICOCLAW_CHANNELS_DISCORD_TOKEN"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_DISCORD_ALLOW_FROM"`
+	MentionOnly        bool                  `json:"mention_only"            env:"PICOCLAW_CHANNELS_DISCORD_MENTION_ONLY"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	Media              MediaConfig           `json:"media,omitempty"`
+	Typing             TypingConfig          `json:"typing,omitempty"`
+	Placeholder        PlaceholderConfig     `json:"placeholder,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_DISCORD_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type MaixCamConfig struct {
-	Enabled            bool                `json:"enabled"              env:"PICOCLAW_CHANNELS_MAIXCAM_ENABLED"`
-	Host               string              `json:"host"                 env:"PICOCLAW_CHANNELS_MAIXCAM_HOST"`
-	Port               int                 `json:"port"                 env:"PICOCLAW_CHANNELS_MAIXCAM_PORT"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"           env:"PICOCLAW_CHANNELS_MAIXCAM_ALLOW_FROM"`
-	ReasoningChannelID string              `json:"reasoning_channel_id" env:"PICOCLAW_CHANNELS_MAIXCAM_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"              env:"PICOCLAW_CHANNELS_MAIXCAM_ENABLED"`
+	Host               string                `json:"host"                 env:"PICOCLAW_CHANNELS_MAIXCAM_HOST"`
+	Port               int                   `json:"port"                 env:"PICOCLAW_CHANNELS_MAIXCAM_PORT"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"           env:"PICOCLAW_CHANNELS_MAIXCAM_ALLOW_FROM"`
+	ReasoningChannelID string                `json:"reasoning_channel_id" env:"PICOCLAW_CHANNELS_MAIXCAM_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type QQConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_QQ_ENABLED"`
-	AppID              string              `json:"app_id"                  env:"PICOCLAW_CHANNELS_QQ_APP_ID"`
-	AppSecret          string              `json:"app_secret"              env:"PICOCLAW_CHANNELS_QQ_APP_SECRET"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_QQ_ALLOW_FROM"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_QQ_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_QQ_ENABLED"`
+	AppID              string                `json:"app_id"                  env:"PICOCLAW_CHANNELS_QQ_APP_ID"`
+	AppSecret          string                `json:"app_secret"              env:"PICOCLAW_CHANNELS_QQ_APP_SECRET"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_QQ_ALLOW_FROM"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_QQ_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type DingTalkConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_DINGTALK_ENABLED"`
-	ClientID           string              `json:"client_id"               env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_ID"`
-	ClientSecret       string              `json:"client_secret"           env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_SECRET"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_DINGTALK_ALLOW_FROM"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_DINGTALK_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_DINGTALK_ENABLED"`
+	ClientID           string                `json:"client_id"               env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_ID"`
+	ClientSecret       string                `json:"client_secret"           env:"PICOCLAW_CHANNELS_DINGTALK_CLIENT_SECRET"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_DINGTALK_ALLOW_FROM"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_DINGTALK_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type SlackConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_SLACK_ENABLED"`
-	BotToken           string              `json:"bot_token"               env:"PICOCLAW_CHANNELS_SLACK_BOT_TOKEN"`
-	AppToken           string              `json:"app_token"               env:"PICOCLAW_CHANNELS_SLACK_APP_TOKEN"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_SLACK_ALLOW_FROM"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	Typing             TypingConfig        `json:"typing,omitempty"`
-	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_SLACK_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_SLACK_ENABLED"`
+	BotToken           string                `json:"bot_token"               env:"PICOCLAW_CHANNELS_SLACK_BOT_TOKEN"`
+	AppToken           string                `json:"app_token"               env:"PICOCLAW_CHANNELS_SLACK_APP_TOKEN"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_SLACK_ALLOW_FROM"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	Media              MediaConfig           `json:"media,omitempty"`
+	Typing             TypingConfig          `json:"typing,omitempty"`
+	Placeholder        PlaceholderConfig     `json:"placeholder,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_SLACK_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type LINEConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_LINE_ENABLED"`
-	ChannelSecret      string              `json:"channel_secret"          env:"PICOCLAW_CHANNELS_LINE_CHANNEL_SECRET"`
-	ChannelAccessToken string              `json:"channel_access_token"    env:"PICOCLAW_CHANNELS_LINE_CHANNEL_ACCESS_TOKEN"`
-	WebhookHost        string              `json:"webhook_host"            env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_HOST"`
-	WebhookPort        int                 `json:"webhook_port"            env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PORT"`
-	WebhookPath        string              `json:"webhook_path"            env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PATH"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_LINE_ALLOW_FROM"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	Typing             TypingConfig        `json:"typing,omitempty"`
-	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_LINE_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_LINE_ENABLED"`
+	ChannelSecret      string                `json:"channel_secret"          env:"PICOCLAW_CHANNELS_LINE_CHANNEL_SECRET"`
+	ChannelAccessToken string                `json:"channel_access_token"    env:"PICOCLAW_CHANNELS_LINE_CHANNEL_ACCESS_TOKEN"`
+	WebhookHost        string                `json:"webhook_host"            env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_HOST"`
+	WebhookPort        int                   `json:"webhook_port"            env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PORT"`
+	WebhookPath        string                `json:"webhook_path"            env:"PICOCLAW_CHANNELS_LINE_WEBHOOK_PATH"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_LINE_ALLOW_FROM"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	Media              MediaConfig           `json:"media,omitempty"`
+	Typing             TypingConfig          `json:"typing,omitempty"`
+	Placeholder        PlaceholderConfig     `json:"placeholder,omitempty"`
+	MaxMediaSizeBytes  int64                 `json:"max_media_size_bytes"    env:"PICOCLAW_CHANNELS_LINE_MAX_MEDIA_SIZE_BYTES"` // 0 = no limit
+	WelcomeMessage     string                `json:"welcome_message"         env:"PICOCLAW_CHANNELS_LINE_WELCOME_MESSAGE"`      // Sent on follow event; empty = no welcome
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_LINE_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type OneBotConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_ONEBOT_ENABLED"`
-	WSUrl              string              `json:"ws_url"                  env:"PICOCLAW_CHANNELS_ONEBOT_WS_URL"`
-	AccessToken        string              `json:"access_token"            env:"PICOCLAW_CHANNELS_ONEBOT_ACCESS_TOKEN"`
-	ReconnectInterval  int                 `json:"reconnect_interval"      env:"PICOCLAW_CHANNELS_ONEBOT_RECONNECT_INTERVAL"`
-	GroupTriggerPrefix []string            `json:"group_trigger_prefix"    env:"PICOCLAW_CHANNELS_ONEBOT_GROUP_TRIGGER_PREFIX"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_ONEBOT_ALLOW_FROM"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	Typing             TypingConfig        `json:"typing,omitempty"`
-	Placeholder        PlaceholderConfig   `json:"placeholder,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_ONEBOT_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_ONEBOT_ENABLED"`
+	WSUrl              string                `json:"ws_url"                  env:"PICOCLAW_CHANNELS_ONEBOT_WS_URL"`
+	AccessToken        string                `json:"access_token"            env:"PICOCLAW_CHANNELS_ONEBOT_ACCESS_TOKEN"`
+	ReconnectInterval  int                   `json:"reconnect_interval"      env:"PICOCLAW_CHANNELS_ONEBOT_RECONNECT_INTERVAL"`
+	GroupTriggerPrefix []string              `json:"group_trigger_prefix"    env:"PICOCLAW_CHANNELS_ONEBOT_GROUP_TRIGGER_PREFIX"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_ONEBOT_ALLOW_FROM"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	Media              MediaConfig           `json:"media,omitempty"`
+	Typing             TypingConfig          `json:"typing,omitempty"`
+	Placeholder        PlaceholderConfig     `json:"placeholder,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_ONEBOT_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type WeComConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_WECOM_ENABLED"`
-	Token              string              `json:"token"                   env:"PICOCLAW_CHANNELS_WECOM_TOKEN"`
-	EncodingAESKey     string              `json:"encoding_aes_key"        env:"PICOCLAW_CHANNELS_WECOM_ENCODING_AES_KEY"`
-	WebhookURL         string              `json:"webhook_url"             env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_URL"`
-	WebhookHost        string              `json:"webhook_host"            env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_HOST"`
-	WebhookPort        int                 `json:"webhook_port"            env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PORT"`
-	WebhookPath        string              `json:"webhook_path"            env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PATH"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_WECOM_ALLOW_FROM"`
-	ReplyTimeout       int                 `json:"reply_timeout"           env:"PICOCLAW_CHANNELS_WECOM_REPLY_TIMEOUT"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_WECOM_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_WECOM_ENABLED"`
+	Token              string                `json:"token"                   env:"PICOCLAW_CHANNELS_WECOM_TOKEN"`
+	EncodingAESKey     string                `json:"encoding_aes_key"        env:"PICOCLAW_CHANNELS_WECOM_ENCODING_AES_KEY"`
+	WebhookURL         string                `json:"webhook_url"             env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_URL"`
+	WebhookHost        string                `json:"webhook_host"            env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_HOST"`
+	WebhookPort        int                   `json:"webhook_port"            env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PORT"`
+	WebhookPath        string                `json:"webhook_path"            env:"PICOCLAW_CHANNELS_WECOM_WEBHOOK_PATH"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_WECOM_ALLOW_FROM"`
+	ReplyTimeout       int                   `json:"reply_timeout"           env:"PICOCLAW_CHANNELS_WECOM_REPLY_TIMEOUT"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_WECOM_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type WeComAppConfig struct {
-	Enabled            bool                `json:"enabled"                 env:"PICOCLAW_CHANNELS_WECOM_APP_ENABLED"`
-	CorpID             string              `json:"corp_id"                 env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_ID"`
-	CorpSecret         string              `json:"corp_secret"             env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_SECRET"`
-	AgentID            int64               `json:"agent_id"                env:"PICOCLAW_CHANNELS_WECOM_APP_AGENT_ID"`
-	Token              string              `json:"token"                   env:"PICOCLAW_CHANNELS_WECOM_APP_TOKEN"`
-	EncodingAESKey     string              `json:"encoding_aes_key"        env:"PICOCLAW_CHANNELS_WECOM_APP_ENCODING_AES_KEY"`
-	WebhookHost        string              `json:"webhook_host"            env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_HOST"`
-	WebhookPort        int                 `json:"webhook_port"            env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PORT"`
-	WebhookPath        string              `json:"webhook_path"            env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PATH"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"              env:"PICOCLAW_CHANNELS_WECOM_APP_ALLOW_FROM"`
-	ReplyTimeout       int                 `json:"reply_timeout"           env:"PICOCLAW_CHANNELS_WECOM_APP_REPLY_TIMEOUT"`
-	GroupTrigger       GroupTriggerConfig  `json:"group_trigger,omitempty"`
-	ReasoningChannelID string              `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_WECOM_APP_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"                 env:"PICOCLAW_CHANNELS_WECOM_APP_ENABLED"`
+	CorpID             string                `json:"corp_id"                 env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_ID"`
+	CorpSecret         string                `json:"corp_secret"             env:"PICOCLAW_CHANNELS_WECOM_APP_CORP_SECRET"`
+	AgentID            int64                 `json:"agent_id"                env:"PICOCLAW_CHANNELS_WECOM_APP_AGENT_ID"`
+	Token              string                `json:"token"                   env:"PICOCLAW_CHANNELS_WECOM_APP_TOKEN"`
+	EncodingAESKey     string                `json:"encoding_aes_key"        env:"PICOCLAW_CHANNELS_WECOM_APP_ENCODING_AES_KEY"`
+	WebhookHost        string                `json:"webhook_host"            env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_HOST"`
+	WebhookPort        int                   `json:"webhook_port"            env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PORT"`
+	WebhookPath        string                `json:"webhook_path"            env:"PICOCLAW_CHANNELS_WECOM_APP_WEBHOOK_PATH"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"              env:"PICOCLAW_CHANNELS_WECOM_APP_ALLOW_FROM"`
+	ReplyTimeout       int                   `json:"reply_timeout"           env:"PICOCLAW_CHANNELS_WECOM_APP_REPLY_TIMEOUT"`
+	GroupTrigger       GroupTriggerConfig    `json:"group_trigger,omitempty"`
+	ReasoningChannelID string                `json:"reasoning_channel_id"    env:"PICOCLAW_CHANNELS_WECOM_APP_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type WeComAIBotConfig struct {
-	Enabled            bool                `json:"enabled"              env:"PICOCLAW_CHANNELS_WECOM_AIBOT_ENABLED"`
-	Token              string              `json:"token"                env:"PICOCLAW_CHANNELS_WECOM_AIBOT_TOKEN"`
-	EncodingAESKey     string              `json:"encoding_aes_key"     env:"PICOCLAW_CHANNELS_WECOM_AIBOT_ENCODING_AES_KEY"`
-	WebhookPath        string              `json:"webhook_path"         env:"PICOCLAW_CHANNELS_WECOM_AIBOT_WEBHOOK_PATH"`
-	AllowFrom          FlexibleStringSlice `json:"allow_from"           env:"PICOCLAW_CHANNELS_WECOM_AIBOT_ALLOW_FROM"`
-	ReplyTimeout       int                 `json:"reply_timeout"        env:"PICOCLAW_CHANNELS_WECOM_AIBOT_REPLY_TIMEOUT"`
-	MaxSteps           int                 `json:"max_steps"            env:"PICOCLAW_CHANNELS_WECOM_AIBOT_MAX_STEPS"`       // Maximum streaming steps
-	WelcomeMessage     string              `json:"welcome_message"      env:"PICOCLAW_CHANNELS_WECOM_AIBOT_WELCOME_MESSAGE"` // Sent on enter_chat event; empty = no welcome
-	ReasoningChannelID string              `json:"reasoning_channel_id" env:"PICOCLAW_CHANNELS_WECOM_AIBOT_REASONING_CHANNEL_ID"`
+	Enabled            bool                  `json:"enabled"              env:"PICOCLAW_CHANNELS_WECOM_AIBOT_ENABLED"`
+	Token              string                `json:"token"                env:"PICOCLAW_CHANNELS_WECOM_AIBOT_TOKEN"`
+	EncodingAESKey     string                `json:"encoding_aes_key"     env:"PICOCLAW_CHANNELS_WECOM_AIBOT_ENCODING_AES_KEY"`
+	WebhookPath        string                `json:"webhook_path"         env:"PICOCLAW_CHANNELS_WECOM_AIBOT_WEBHOOK_PATH"`
+	AllowFrom          FlexibleStringSlice   `json:"allow_from"           env:"PICOCLAW_CHANNELS_WECOM_AIBOT_ALLOW_FROM"`
+	ReplyTimeout       int                   `json:"reply_timeout"        env:"PICOCLAW_CHANNELS_WECOM_AIBOT_REPLY_TIMEOUT"`
+	MaxSteps           int                   `json:"max_steps"            env:"PICOCLAW_CHANNELS_WECOM_AIBOT_MAX_STEPS"`       // Maximum streaming steps
+	WelcomeMessage     string                `json:"welcome_message"      env:"PICOCLAW_CHANNELS_WECOM_AIBOT_WELCOME_MESSAGE"` // Sent on enter_chat event; empty = no welcome
+	ReasoningChannelID string                `json:"reasoning_channel_id" env:"PICOCLAW_CHANNELS_WECOM_AIBOT_REASONING_CHANNEL_ID"`
+	Chats              map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type PicoConfig struct {
-	Enabled         bool                `json:"enabled"                     env:"PICOCLAW_CHANNELS_PICO_ENABLED"`
-	Token           string              `json:"token"                       env:"PICOCLAW_CHANNELS_PICO_TOKEN"`
-	AllowTokenQuery bool                `json:"allow_token_query,omitempty"`
-	AllowOrigins    []string            `json:"allow_origins,omitempty"`
-	PingInterval    int                 `json:"ping_interval,omitempty"`
-	ReadTimeout     int                 `json:"read_timeout,omitempty"`
-	WriteTimeout    int                 `json:"write_timeout,omitempty"`
-	MaxConnections  int                 `json:"max_connections,omitempty"`
-	AllowFrom       FlexibleStringSlice `json:"allow_from"                  env:"PICOCLAW_CHANNELS_PICO_ALLOW_FROM"`
-	Placeholder     PlaceholderConfig   `json:"placeholder,omitempty"`
+	Enabled         bool                  `json:"enabled"                     env:"PICOCLAW_CHANNELS_PICO_ENABLED"`
+	Token           string                `json:"token"                       env:"PICOCLAW_CHANNELS_PICO_TOKEN"`
+	AllowTokenQuery bool                  `json:"allow_token_query,omitempty"`
+	AllowOrigins    []string              `json:"allow_origins,omitempty"`
+	PingInterval    int                   `json:"ping_interval,omitempty"`
+	ReadTimeout     int                   `json:"read_timeout,omitempty"`
+	WriteTimeout    int                   `json:"write_timeout,omitempty"`
+	MaxConnections  int                   `json:"max_connections,omitempty"`
+	AllowFrom       FlexibleStringSlice   `json:"allow_from"                  env:"PICOCLAW_CHANNELS_PICO_ALLOW_FROM"`
+	Placeholder     PlaceholderConfig     `json:"placeholder,omitempty"`
+	Chats           map[string]ChatConfig `json:"chats,omitempty"`
 }
 
 type HeartbeatConfig struct {
 	Enabled  bool `json:"enabled"  env:"PICOCLAW_HEARTBEAT_ENABLED"`
 	Interval int  `json:"interval" env:"PICOCLAW_HEARTBEAT_INTERVAL"` // minutes, min 5
+	Digest   bool `json:"digest"   env:"PICOCLAW_HEARTBEAT_DIGEST"`   // batch findings into the gateway digest
+	// IncludeRecentContext appends a summary of the owner's most recent
+	// conversation to the heartbeat prompt.
+	IncludeRecentContext bool                `json:"include_recent_context" env:"PICOCLAW_HEARTBEAT_INCLUDE_RECENT_CONTEXT"`
+	RecentContext        RecentContextConfig `json:"recent_context"`
+}
+
+// RecentContextConfig bounds the recent conversation summary given to
+// heartbeat and cron runs.
+type RecentContextConfig struct {
+	Turns     int `json:"turns"`      // user turns to summarize
+	MaxTokens int `json:"max_tokens"` // cap on the summary's size
+}
+
+// CommandsConfig controls the built-in slash commands (/reset, /model, ...)
+// answered without calling the model.
+type CommandsConfig struct {
+	Enabled bool `json:"enabled" env:"PICOCLAW_COMMANDS_ENABLED"`
+	// Disabled lists command names (without "/") turned off everywhere.
+	Disabled FlexibleStringSlice `json:"disabled,omitempty" env:"PICOCLAW_COMMANDS_DISABLED"`
+	// ChannelDisabled lists commands turned off per channel; "*" disables all.
+	ChannelDisabled map[string][]string `json:"channel_disabled,omitempty"`
+	// Owners are sender IDs allowed to run owner-only commands and exempt
+	// from rate_limit. When empty, owner-only commands are available to
+	// everyone and nobody is exempt from rate limiting.
+	Owners FlexibleStringSlice `json:"owners,omitempty" env:"PICOCLAW_COMMANDS_OWNERS"`
+	// Prefix starts a command, "/" when empty.
+	Prefix string `json:"prefix,omitempty" env:"PICOCLAW_COMMANDS_PREFIX"`
+}
+
+// CommandPrefix returns the configured command prefix or "/".
+func (c CommandsConfig) CommandPrefix() string {
+	if c.Prefix == "" {
+		return "/"
+	}
+	return c.Prefix
+}
+
+// ModerationConfig screens user messages before the agent sees them and
+// replies before they are sent. Blocked content is replaced by the canned
+// responses, which default to the gateway language's wording when empty.
+type ModerationConfig struct {
+	Enabled  bool   `json:"enabled"  env:"PICOCLAW_MODERATION_ENABLED"`
+	Provider string `json:"provider" env:"PICOCLAW_MODERATION_PROVIDER"` // openai
+	// APIKey defaults to providers.openai.api_key.
+	APIKey  string `json:"api_key,omitempty"  env:"PICOCLAW_MODERATION_API_KEY"`
+	APIBase string `json:"api_base,omitempty" env:"PICOCLAW_MODERATION_API_BASE"`
+	Model   string `json:"model,omitempty"    env:"PICOCLAW_MODERATION_MODEL"`
+	Proxy   string `json:"proxy,omitempty"    env:"PICOCLAW_MODERATION_PROXY"`
+
+	CheckInbound            bool   `json:"check_inbound"             env:"PICOCLAW_MODERATION_CHECK_INBOUND"`
+	CheckOutbound           bool   `json:"check_outbound"            env:"PICOCLAW_MODERATION_CHECK_OUTBOUND"`
+	BlockedInboundResponse  string `json:"blocked_inbound_response,omitempty"  env:"PICOCLAW_MODERATION_BLOCKED_INBOUND_RESPONSE"`
+	BlockedOutboundResponse string `json:"blocked_outbound_response,omitempty" env:"PICOCLAW_MODERATION_BLOCKED_OUTBOUND_RESPONSE"`
+}
+
+// RateLimitConfig throttles senders other than the command owners before
+// the agent is invoked. A zero limit means unlimited.
+type RateLimitConfig struct {
+	Enabled           bool `json:"enabled"             env:"PICOCLAW_RATE_LIMIT_ENABLED"`
+	MessagesPerMinute int  `json:"messages_per_minute" env:"PICOCLAW_RATE_LIMIT_MESSAGES_PER_MINUTE"`
+	MessagesPerHour   int  `json:"messages_per_hour"   env:"PICOCLAW_RATE_LIMIT_MESSAGES_PER_HOUR"`
+	DailyTokens       int  `json:"daily_tokens"        env:"PICOCLAW_RATE_LIMIT_DAILY_TOKENS"`
+	// Response is sent once when a sender first hits a limit; further
+	// messages in the same window are ignored silently. Empty uses the
+	// gateway language's default.
+	Response string `json:"response,omitempty" env:"PICOCLAW_RATE_LIMIT_RESPONSE"`
+	// Channels overrides the limits per channel; non-zero fields win.
+	Channels map[string]RateLimitRule `json:"channels,omitempty"`
+}
+
+// RateLimitRule is one set of per-sender limits.
+type RateLimitRule struct {
+	MessagesPerMinute int `json:"messages_per_minute,omitempty"`
+	MessagesPerHour   int `json:"messages_per_hour,omitempty"`
+	DailyTokens       int `json:"daily_tokens,omitempty"`
+}
+
+// RuleFor returns the limits that apply on channel.
+func (c RateLimitConfig) RuleFor(channel string) RateLimitRule {
+	rule := RateLimitRule{
+		MessagesPerMinute: c.MessagesPerMinute,
+		MessagesPerHour:   c.MessagesPerHour,
+		DailyTokens:       c.DailyTokens,
+	}
+	if override, ok := c.Channels[channel]; ok {
+		if override.MessagesPerMinute != 0 {
+			rule.MessagesPerMinute = override.MessagesPerMinute
+		}
+		if override.MessagesPerHour != 0 {
+			rule.MessagesPerHour = override.MessagesPerHour
+		}
+		if override.DailyTokens != 0 {
+			rule.DailyTokens = override.DailyTokens
+		}
+	}
+	return rule
+}
+
+// BudgetConfig caps what channels and chats spend on the model per day or
+// month. Channels limits the total of all chats on a channel, Chats
+// ("channel:chat_id") a single chat; chats without a limit are unlimited.
+type BudgetConfig struct {
+	Enabled bool `json:"enabled" env:"PICOCLAW_BUDGET_ENABLED"`
+	// Timezone sets when days and months begin; empty uses the local zone.
+	Timezone string `json:"timezone,omitempty" env:"PICOCLAW_BUDGET_TIMEZONE"`
+	// Response replies to messages over budget. Empty uses the gateway
+	// language's default.
+	Response string `json:"response,omitempty" env:"PICOCLAW_BUDGET_RESPONSE"`
+	// The owner is told the first time a limit is hit in a period, in
+	// NotifyChannel/NotifyChatID or else the last active chat.
+	NotifyChannel string                 `json:"notify_channel,omitempty" env:"PICOCLAW_BUDGET_NOTIFY_CHANNEL"`
+	NotifyChatID  string                 `json:"notify_chat_id,omitempty" env:"PICOCLAW_BUDGET_NOTIFY_CHAT_ID"`
+	Channels      map[string]BudgetLimit `json:"channels,omitempty"`
+	Chats         map[string]BudgetLimit `json:"chats,omitempty"`
+}
+
+// Budget periods.
+const (
+	BudgetDay   = "day"
+	BudgetMonth = "month"
+)
+
+// BudgetLimit is the spending limit of one channel or chat. Cost is in US
+// dollars, estimated from the pricing of model_list entries. A zero field is
+// not limited.
+type BudgetLimit struct {
+	Period string  `json:"period,omitempty"` // "day" (default) or "month"
+	Tokens int     `json:"tokens,omitempty"`
+	Cost   float64 `json:"cost_usd,omitempty"`
+}
+
+// ValidateBudget checks the budget timezone and limits.
+func (c *Config) ValidateBudget() error {
+	if c.Budget.Timezone != "" {
+		if _, err := time.LoadLocation(c.Budget.Timezone); err != nil {
+			return fmt.Errorf("budget.timezone: %w", err)
+		}
+	}
+	check := func(section, key string, limit BudgetLimit) error {
+		if limit.Period != "" && limit.Period != BudgetDay && limit.Period != BudgetMonth {
+			return fmt.Errorf("budget.%s.%s.period: use day or month, got %q", section, key, limit.Period)
+		}
+		if limit.Tokens < 0 || limit.Cost < 0 {
+			return fmt.Errorf("budget.%s.%s: limits must not be negative", section, key)
+		}
+		return nil
+	}
+	for channel, limit := range c.Budget.Channels {
+		if err := check("channels", channel, limit); err != nil {
+			return err
+		}
+	}
+	for chat, limit := range c.Budget.Chats {
+		if !strings.Contains(chat, ":") {
+			return fmt.Errorf("budget.chats.%s: use <channel>:<chat_id>", chat)
+		}
+		if err := check("chats", chat, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SecurityConfig holds the protections applied to untrusted input.
+type SecurityConfig struct {
+	Scan ScanConfig `json:"scan"`
+}
+
+// Attachment scan modes.
+const (
+	ScanModeOff     = "off"
+	ScanModeCommand = "command"
+	ScanModeClamd   = "clamd"
+)
+
+// ScanConfig scans inbound attachments for malware before the agent sees
+// them. Infected files are deleted and the message text is replaced with a
+// warning.
+type ScanConfig struct {
+	Mode string `json:"mode" env:"PICOCLAW_SECURITY_SCAN_MODE"` // off, command or clamd
+	// Command is run with Args and the file path appended. Exit status 0
+	// means clean and 1 infected, as with clamscan; anything else is an
+	// error.
+	Command string   `json:"command,omitempty" env:"PICOCLAW_SECURITY_SCAN_COMMAND"`
+	Args    []string `json:"args,omitempty"`
+	// ClamdAddress is the clamd socket: "unix:/path", an absolute path, or
+	// host:port for TCP.
+	ClamdAddress   string `json:"clamd_address,omitempty" env:"PICOCLAW_SECURITY_SCAN_CLAMD_ADDRESS"`
+	TimeoutSeconds int    `json:"timeout_seconds"         env:"PICOCLAW_SECURITY_SCAN_TIMEOUT_SECONDS"`
+	// BlockUnscanned drops attachments whose scan failed or timed out;
+	// otherwise they are passed through.
+	BlockUnscanned bool `json:"block_unscanned" env:"PICOCLAW_SECURITY_SCAN_BLOCK_UNSCANNED"`
+	// The owner is told about infected files in NotifyChannel/NotifyChatID.
+	NotifyChannel string `json:"notify_channel,omitempty" env:"PICOCLAW_SECURITY_SCAN_NOTIFY_CHANNEL"`
+	NotifyChatID  string `json:"notify_chat_id,omitempty" env:"PICOCLAW_SECURITY_SCAN_NOTIFY_CHAT_ID"`
+}
+
+// ValidateSecurity checks the attachment scan settings.
+func (c *Config) ValidateSecurity() error {
+	sc := c.Security.Scan
+	switch sc.Mode {
+	case "", ScanModeOff:
+		return nil
+	case ScanModeCommand:
+		if sc.Command == "" {
+			return fmt.Errorf("security.scan.command is required in command mode")
+		}
+	case ScanModeClamd:
+		if sc.ClamdAddress == "" {
+			return fmt.Errorf("security.scan.clamd_address is required in clamd mode")
+		}
+	default:
+		return fmt.Errorf("security.scan.mode: use off, command or clamd, got %q", sc.Mode)
+	}
+	if sc.TimeoutSeconds < 0 {
+		return fmt.Errorf("security.scan.timeout_seconds must not be negative")
+	}
+	return nil
 }
 
 type DevicesConfig struct {
@@ -400,7 +1102,7 @@ type ProvidersConfig struct {
 	Anthropic     ProviderConfig       `json:"anthropic"`
 	OpenAI        OpenAIProviderConfig `json:"openai"`
 	LiteLLM       ProviderConfig       `json:"litellm"`
-	OpenRouter    ProviderConfig       `json:"openrouter"`
+	OpenRouter    OpenRouterConfig     `json:"openrouter"`
 	Groq          ProviderConfig       `json:"groq"`
 	Zhipu         ProviderConfig       `json:"zhipu"`
 	VLLM          ProviderConfig       `json:"vllm"`
@@ -416,6 +1118,21 @@ type ProvidersConfig struct {
 	Antigravity   ProviderConfig       `json:"antigravity"`
 	Qwen          ProviderConfig       `json:"qwen"`
 	Mistral       ProviderConfig       `json:"mistral"`
+
+	// HTTP tunes the connection pool shared by the HTTP-based providers.
+	// Unlike the entries above it is not deprecated: it applies to
+	// model_list too.
+	HTTP HTTPClientConfig `json:"http"`
+}
+
+// HTTPClientConfig sets the connection limits of the HTTP client pool the
+// providers share. Timeouts are in seconds; zero means no limit, except for
+// MaxIdleConnsPerHost where it means Go's default of 2.
+type HTTPClientConfig struct {
+	MaxIdleConnsPerHost   int `json:"max_idle_conns_per_host" env:"PICOCLAW_PROVIDERS_HTTP_MAX_IDLE_CONNS_PER_HOST"`
+	TLSHandshakeTimeout   int `json:"tls_handshake_timeout"   env:"PICOCLAW_PROVIDERS_HTTP_TLS_HANDSHAKE_TIMEOUT"`
+	ResponseHeaderTimeout int `json:"response_header_timeout" env:"PICOCLAW_PROVIDERS_HTTP_RESPONSE_HEADER_TIMEOUT"`
+	IdleConnTimeout       int `json:"idle_conn_timeout"       env:"PICOCLAW_PROVIDERS_HTTP_IDLE_CONN_TIMEOUT"`
 }
 
 // IsEmpty checks if all provider configs are empty (no API keys or API bases set)
@@ -466,6 +1183,51 @@ type OpenAIProviderConfig struct {
 	WebSearch bool `json:"web_search" env:"PICOCLAW_PROVIDERS_OPENAI_WEB_SEARCH"`
 }
 
+type OpenRouterConfig struct {
+	ProviderConfig
+	// Route applies to every openrouter/ model; a model's own route
+	// overrides it field by field.
+	Route *OpenRouterRoute `json:"route,omitempty"`
+}
+
+// OpenRouterRoute selects which upstream providers OpenRouter may use.
+// See https://openrouter.ai/docs/features/provider-routing
+type OpenRouterRoute struct {
+	Order          []string `json:"order,omitempty"`           // providers to try first, in order
+	Allow          []string `json:"allow,omitempty"`           // only these providers
+	Deny           []string `json:"deny,omitempty"`            // never these providers
+	Sort           string   `json:"sort,omitempty"`            // price, throughput or latency
+	AllowFallbacks *bool    `json:"allow_fallbacks,omitempty"` // false pins the request to Order/Allow
+}
+
+// Merge returns r with the fields set in override replacing its own.
+// Either side may be nil.
+func (r *OpenRouterRoute) Merge(override *OpenRouterRoute) *OpenRouterRoute {
+	if r == nil {
+		return override
+	}
+	if override == nil {
+		return r
+	}
+	merged := *r
+	if len(override.Order) > 0 {
+		merged.Order = override.Order
+	}
+	if len(override.Allow) > 0 {
+		merged.Allow = override.Allow
+	}
+	if len(override.Deny) > 0 {
+		merged.Deny = override.Deny
+	}
+	if override.Sort != "" {
+		merged.Sort = override.Sort
+	}
+	if override.AllowFallbacks != nil {
+		merged.AllowFallbacks = override.AllowFallbacks
+	}
+	return &merged
+}
+
 // ModelConfig represents a model-centric provider configuration.
 // It allows adding new providers (especially OpenAI-compatible ones) via configuration only.
 // The model field uses protocol prefix format: [protocol/]model-identifier
@@ -483,13 +1245,58 @@ type ModelConfig struct {
 
 	// Special providers (CLI-based, OAuth, etc.)
 	AuthMethod  string `json:"auth_method,omitempty"`  // Authentication method: oauth, token
+	Account     string `json:"account,omitempty"`      // Named credential from `auth login --account`, for oauth/token
 	ConnectMode string `json:"connect_mode,omitempty"` // Connection mode: stdio, grpc
 	Workspace   string `json:"workspace,omitempty"`    // Workspace path for CLI-based providers
+	// SubprocessTimeout is how many seconds one run of a CLI-based provider
+	// may take before it is killed (default 600), whatever the caller's
+	// deadline.
+	SubprocessTimeout int `json:"subprocess_timeout,omitempty"`
 
 	// Optional optimizations
 	RPM            int    `json:"rpm,omitempty"`              // Requests per minute limit
 	MaxTokensField string `json:"max_tokens_field,omitempty"` // Field name for max tokens (e.g., "max_completion_tokens")
 	RequestTimeout int    `json:"request_timeout,omitempty"`
+
+	// Capabilities overrides the built-in capability registry (e.g. ["vision", "tools"]).
+	Capabilities []string `json:"capabilities,omitempty"`
+	// ContextWindow and MaxTokens override the registry's limits of the
+	// model, in tokens: the context it accepts and the longest reply.
+	ContextWindow int `json:"context_window,omitempty"`
+	MaxTokens     int `json:"max_tokens,omitempty"`
+
+	// Route overrides providers.openrouter.route for openrouter/ models.
+	Route *OpenRouterRoute `json:"route,omitempty"`
+
+	// Pricing enables cost estimates in the usage tracker.
+	Pricing *ModelPricing `json:"pricing,omitempty"`
+
+	// OffPeakHint logs a hint when a DeepSeek request runs in the
+	// discounted off-peak window. Ignored for other providers.
+	OffPeakHint bool `json:"off_peak_hint,omitempty"`
+}
+
+// ModelPricing holds a model's prices in USD per million tokens. Prompt
+// tokens served from the provider's cache are billed at CachedInput, which
+// falls back to Input when unset.
+type ModelPricing struct {
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cached_input,omitempty"`
+	Output      float64 `json:"output"`
+}
+
+// Cost returns the price of one request. cached is the part of prompt
+// tokens that hit the prompt cache.
+func (p *ModelPricing) Cost(prompt, cached, completion int) float64 {
+	if p == nil {
+		return 0
+	}
+	cachedRate := p.CachedInput
+	if cachedRate == 0 {
+		cachedRate = p.Input
+	}
+	cached = min(cached, prompt)
+	return (float64(prompt-cached)*p.Input + float64(cached)*cachedRate + float64(completion)*p.Output) / 1e6
 }
 
 // Validate checks if the ModelConfig has all required fields.
@@ -506,6 +1313,215 @@ func (c *ModelConfig) Validate() error {
 type GatewayConfig struct {
 	Host string `json:"host" env:"PICOCLAW_GATEWAY_HOST"`
 	Port int    `json:"port" env:"PICOCLAW_GATEWAY_PORT"`
+	// MaxConcurrentTurns caps how many agent turns run at once; up to
+	// MaxQueuedTurns further messages wait, the rest get a "busy" reply.
+	MaxConcurrentTurns int               `json:"max_concurrent_turns" env:"PICOCLAW_GATEWAY_MAX_CONCURRENT_TURNS"`
+	MaxQueuedTurns     int               `json:"max_queued_turns"     env:"PICOCLAW_GATEWAY_MAX_QUEUED_TURNS"`
+	UpdateCheck        UpdateCheckConfig `json:"update_check"`
+	Digest             DigestConfig      `json:"digest"`
+	StatusPage         StatusPageConfig  `json:"status_page"`
+	// ChannelRestart controls restarting a channel whose Start panics.
+	ChannelRestart ChannelRestartConfig `json:"channel_restart"`
+	// Streaming shows replies as they are written by editing the channel's
+	// placeholder message.
+	Streaming StreamingConfig `json:"streaming"`
+	// Middleware runs on every message in order: inbound before the agent
+	// sees it, outbound before a channel sends it.
+	Middleware []MiddlewareConfig `json:"middleware,omitempty"`
+	// Language is the locale of the bot's canned messages (en, zh-CN,
+	// zh-TW, ja); ChannelLanguages overrides it per channel.
+	Language         string            `json:"language,omitempty"          env:"PICOCLAW_GATEWAY_LANGUAGE"`
+	ChannelLanguages map[string]string `json:"channel_languages,omitempty"`
+	// ResponseLanguage asks the model to reply in a language ("French"), or
+	// in the language of each message with "auto"; empty leaves it to the
+	// model. ChannelResponseLanguages overrides it per channel.
+	ResponseLanguage         string            `json:"response_language,omitempty"          env:"PICOCLAW_GATEWAY_RESPONSE_LANGUAGE"`
+	ChannelResponseLanguages map[string]string `json:"channel_response_languages,omitempty"`
+	// QuietHours holds messages overnight; ChannelQuietHours overrides it
+	// per channel.
+	QuietHours        QuietHoursConfig            `json:"quiet_hours"`
+	ChannelQuietHours map[string]QuietHoursConfig `json:"channel_quiet_hours,omitempty"`
+	// Maintenance answers messages with a canned reply instead of calling
+	// the model during its schedule, or while switched on with
+	// "picoclaw gateway maintenance on".
+	Maintenance MaintenanceConfig `json:"maintenance"`
+	// NoProvider decides what the gateway does when no model provider is
+	// usable at startup, e.g. before "picoclaw auth login".
+	NoProvider NoProviderConfig `json:"no_provider"`
+}
+
+// What the gateway does without a usable provider.
+const (
+	NoProviderFailFast      = "fail-fast"
+	NoProviderFriendlyReply = "friendly-reply"
+)
+
+// NoProviderConfig configures the gateway when no provider is usable.
+// Behavior "fail-fast" (the default) refuses to start; "friendly-reply"
+// starts the channels and answers every message with Message, or a
+// localized "not configured yet" notice when empty.
+type NoProviderConfig struct {
+	Behavior string `json:"behavior"          env:"PICOCLAW_GATEWAY_NO_PROVIDER_BEHAVIOR"`
+	Message  string `json:"message,omitempty" env:"PICOCLAW_GATEWAY_NO_PROVIDER_MESSAGE"`
+}
+
+// ValidateNoProvider checks gateway.no_provider.behavior.
+func (c *Config) ValidateNoProvider() error {
+	switch b := c.Gateway.NoProvider.Behavior; b {
+	case "", NoProviderFailFast, NoProviderFriendlyReply:
+		return nil
+	default:
+		return fmt.Errorf("gateway.no_provider.behavior: use %s or %s, got %q",
+			NoProviderFailFast, NoProviderFriendlyReply, b)
+	}
+}
+
+// MaintenanceConfig configures maintenance mode. Message replaces the
+// localized "temporarily offline" notice; Schedule lists daily windows in
+// which maintenance is on without being switched on by hand.
+type MaintenanceConfig struct {
+	Message  string              `json:"message,omitempty"`
+	Schedule []MaintenanceWindow `json:"schedule,omitempty"`
+}
+
+// MaintenanceWindow is a daily maintenance window. Start and End are "HH:MM"
+// in Timezone (the system zone when empty); a window may pass midnight.
+type MaintenanceWindow struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// QuietHoursConfig silences a channel for part of the day. Start and End are
+// "HH:MM" in Timezone (the system zone when empty); a window may pass
+// midnight, e.g. 22:00 to 07:00. BehaviorOnReceive decides what happens to
+// messages received meanwhile: "queue" holds them, says when they will be
+// answered and replays them at End; "reject" answers that the bot is quiet
+// and drops them; "defer" holds them silently. Replies and notifications are
+// held until End either way.
+type QuietHoursConfig struct {
+	Enabled           bool   `json:"enabled"`
+	Start             string `json:"start"`
+	End               string `json:"end"`
+	Timezone          string `json:"timezone,omitempty"`
+	BehaviorOnReceive string `json:"behavior_on_receive,omitempty"`
+}
+
+// QuietHoursFor returns the quiet hours of channel.
+func (g GatewayConfig) QuietHoursFor(channel string) QuietHoursConfig {
+	if q, ok := g.ChannelQuietHours[channel]; ok {
+		return q
+	}
+	return g.QuietHours
+}
+
+// ChannelRestartConfig bounds how a panicking channel is restarted.
+// MaxRestarts 0 leaves a panicked channel down.
+type ChannelRestartConfig struct {
+	DelaySeconds int `json:"delay_seconds" env:"PICOCLAW_GATEWAY_CHANNEL_RESTART_DELAY_SECONDS"`
+	MaxRestarts  int `json:"max_restarts"  env:"PICOCLAW_GATEWAY_CHANNEL_RESTART_MAX_RESTARTS"`
+}
+
+// StreamingConfig controls streamed replies. They need a channel that can
+// edit messages and has its placeholder enabled; elsewhere the reply is sent
+// when it is complete. EditIntervalMS is the least time between two edits of
+// the same message.
+type StreamingConfig struct {
+	Enabled        bool `json:"enabled"          env:"PICOCLAW_GATEWAY_STREAMING_ENABLED"`
+	EditIntervalMS int  `json:"edit_interval_ms" env:"PICOCLAW_GATEWAY_STREAMING_EDIT_INTERVAL_MS"`
+}
+
+// ResponseLanguageFor returns the response language setting of channel.
+func (g GatewayConfig) ResponseLanguageFor(channel string) string {
+	if lang, ok := g.ChannelResponseLanguages[channel]; ok {
+		return lang
+	}
+	return g.ResponseLanguage
+}
+
+// LanguageFor returns the locale of canned messages sent on channel.
+func (g GatewayConfig) LanguageFor(channel string) string {
+	if lang := g.ChannelLanguages[channel]; lang != "" {
+		return lang
+	}
+	return g.Language
+}
+
+// MiddlewareConfig is one entry of gateway.middleware. Type selects the
+// middleware (regex, translate or exec); the remaining fields apply to the
+// types named in their comments. A failing middleware is logged and skipped
+// unless FailClosed is set, in which case the message is dropped.
+type MiddlewareConfig struct {
+	Type       string   `json:"type"`
+	Name       string   `json:"name,omitempty"`
+	Direction  string   `json:"direction,omitempty"` // inbound | outbound | both (default)
+	Channels   []string `json:"channels,omitempty"`  // "telegram" or "telegram:<chat_id>"; empty means all
+	FailClosed bool     `json:"fail_closed,omitempty"`
+
+	// regex: matches are replaced with Replacement (default "***"), or the
+	// whole message is dropped when Action is "drop".
+	Patterns    []string `json:"patterns,omitempty"`
+	Action      string   `json:"action,omitempty"` // redact (default) | drop
+	Replacement string   `json:"replacement,omitempty"`
+
+	// translate: Model is a model_list name, defaulting to the agent's model.
+	TargetLanguage string `json:"target_language,omitempty"`
+	Model          string `json:"model,omitempty"`
+
+	// exec: the message is piped as JSON through Command.
+	Command        []string `json:"command,omitempty"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"` // default 10
+}
+
+// UpdateCheckConfig controls the gateway's weekly check for new releases.
+// When NotifyChannel/NotifyChatID are empty the last active chat is notified.
+type UpdateCheckConfig struct {
+	Enabled       bool   `json:"enabled"        env:"PICOCLAW_GATEWAY_UPDATE_CHECK_ENABLED"`
+	Channel       string `json:"channel"        env:"PICOCLAW_GATEWAY_UPDATE_CHECK_CHANNEL"` // stable | prerelease
+	NotifyChannel string `json:"notify_channel" env:"PICOCLAW_GATEWAY_UPDATE_CHECK_NOTIFY_CHANNEL"`
+	NotifyChatID  string `json:"notify_chat_id" env:"PICOCLAW_GATEWAY_UPDATE_CHECK_NOTIFY_CHAT_ID"`
+	// VersionCheck lets `picoclaw version --check` ask GitHub for the latest
+	// release, independently of Enabled.
+	VersionCheck bool `json:"version_check" env:"PICOCLAW_GATEWAY_UPDATE_CHECK_VERSION_CHECK"`
+}
+
+// DigestConfig controls batching of non-urgent output (cron jobs flagged as
+// digest, heartbeat findings) into one message delivered at fixed times.
+// When Channel/ChatID are empty the last active chat receives the digest.
+type DigestConfig struct {
+	Enabled   bool                `json:"enabled"   env:"PICOCLAW_GATEWAY_DIGEST_ENABLED"`
+	Times     FlexibleStringSlice `json:"times"     env:"PICOCLAW_GATEWAY_DIGEST_TIMES"` // local "HH:MM"
+	Channel   string              `json:"channel"   env:"PICOCLAW_GATEWAY_DIGEST_CHANNEL"`
+	ChatID    string              `json:"chat_id"   env:"PICOCLAW_GATEWAY_DIGEST_CHAT_ID"`
+	Summarize bool                `json:"summarize" env:"PICOCLAW_GATEWAY_DIGEST_SUMMARIZE"`
+}
+
+// StatusPageConfig controls the HTML page at /status on the gateway's HTTP
+// server, a health overview for people who won't read /health JSON.
+type StatusPageConfig struct {
+	Enabled bool `json:"enabled" env:"PICOCLAW_GATEWAY_STATUS_PAGE_ENABLED"`
+	// BasicAuth protects the page when set. Put it behind TLS if the
+	// gateway listens beyond localhost.
+	BasicAuth *BasicAuthConfig `json:"basic_auth,omitempty"`
+}
+
+type BasicAuthConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ValidateStatusPage rejects a status page basic_auth without a username
+// or password, which would leave the page open to anyone sending that
+// empty credential.
+func (c *Config) ValidateStatusPage() error {
+	auth := c.Gateway.StatusPage.BasicAuth
+	if auth == nil {
+		return nil
+	}
+	if strings.TrimSpace(auth.Username) == "" || auth.Password == "" {
+		return fmt.Errorf("gateway.status_page.basic_auth: username and password are required")
+	}
+	return nil
 }
 
 type BraveConfig struct {
@@ -545,6 +1561,30 @@ type WebToolsConfig struct {
 
 type CronToolsConfig struct {
 	ExecTimeoutMinutes int `json:"exec_timeout_minutes" env:"PICOCLAW_TOOLS_CRON_EXEC_TIMEOUT_MINUTES"` // 0 means no timeout
+	// StaggerSeconds spreads jobs scheduled for the same instant: each one
+	// starts this long after the one before it. 0 starts them together.
+	StaggerSeconds int `json:"stagger_seconds" env:"PICOCLAW_TOOLS_CRON_STAGGER_SECONDS"`
+	// Presets are job templates for `picoclaw cron add --preset`. A preset
+	// with a built-in preset's name replaces it.
+	Presets []CronPreset `json:"presets,omitempty"`
+	// RecentContext bounds the summary added to jobs created with --with-context.
+	RecentContext RecentContextConfig `json:"recent_context"`
+	// MaxAgentJobs caps the jobs the agent may schedule itself with the cron
+	// tool. 0 means no limit.
+	MaxAgentJobs int `json:"max_agent_jobs" env:"PICOCLAW_TOOLS_CRON_MAX_AGENT_JOBS"`
+}
+
+// CronPreset fills in the defaults of a new cron job. Set Every (seconds)
+// or Cron, not both.
+type CronPreset struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	JobName     string `json:"job_name,omitempty"` // defaults to Name
+	Message     string `json:"message"`
+	Every       int64  `json:"every,omitempty"`
+	Cron        string `json:"cron,omitempty"`
+	Deliver     bool   `json:"deliver,omitempty"`
+	Digest      bool   `json:"digest,omitempty"`
 }
 
 type ExecConfig struct {
@@ -559,21 +1599,62 @@ type MediaCleanupConfig struct {
 	Interval int  `json:"interval_minutes" env:"PICOCLAW_MEDIA_CLEANUP_INTERVAL"`
 }
 
+// MediaNormalizeConfig controls how images downloaded from channels are
+// normalized before the agent sees them: metadata such as EXIF GPS tags is
+// always stripped, and images larger than MaxDimension are downscaled.
+type MediaNormalizeConfig struct {
+	Enabled      bool `json:"enabled"       env:"PICOCLAW_MEDIA_NORMALIZE_ENABLED"`
+	MaxDimension int  `json:"max_dimension" env:"PICOCLAW_MEDIA_NORMALIZE_MAX_DIMENSION"` // 0 keeps the size
+}
+
 type ToolsConfig struct {
-	AllowReadPaths  []string           `json:"allow_read_paths"  env:"PICOCLAW_TOOLS_ALLOW_READ_PATHS"`
-	AllowWritePaths []string           `json:"allow_write_paths" env:"PICOCLAW_TOOLS_ALLOW_WRITE_PATHS"`
-	Web             WebToolsConfig     `json:"web"`
-	Cron            CronToolsConfig    `json:"cron"`
-	Exec            ExecConfig         `json:"exec"`
-	Skills          SkillsToolsConfig  `json:"skills"`
-	MediaCleanup    MediaCleanupConfig `json:"media_cleanup"`
-	MCP             MCPConfig          `json:"mcp"`
+	AllowReadPaths  []string             `json:"allow_read_paths"  env:"PICOCLAW_TOOLS_ALLOW_READ_PATHS"`
+	AllowWritePaths []string             `json:"allow_write_paths" env:"PICOCLAW_TOOLS_ALLOW_WRITE_PATHS"`
+	Web             WebToolsConfig       `json:"web"`
+	Cron            CronToolsConfig      `json:"cron"`
+	Exec            ExecConfig           `json:"exec"`
+	Skills          SkillsToolsConfig    `json:"skills"`
+	MediaCleanup    MediaCleanupConfig   `json:"media_cleanup"`
+	MediaNormalize  MediaNormalizeConfig `json:"media_normalize"`
+	MCP             MCPConfig            `json:"mcp"`
+	SendMessage     SendMessageConfig    `json:"send_message"`
+	Audit           AuditConfig          `json:"audit"`
+}
+
+// AuditConfig controls the tool call audit log. It is kept outside the
+// workspace, by default in ~/.picoclaw/audit/tools.jsonl, so the agent's
+// file tools cannot rewrite it. Arguments whose name contains one of
+// RedactKeys are masked; leaving it unset masks common secrets such as
+// passwords and tokens.
+type AuditConfig struct {
+	Enabled    bool     `json:"enabled"               env:"PICOCLAW_TOOLS_AUDIT_ENABLED"`
+	Path       string   `json:"path,omitempty"        env:"PICOCLAW_TOOLS_AUDIT_PATH"`
+	RedactKeys []string `json:"redact_keys,omitempty"`
+}
+
+// LogPath returns the audit log file, with a leading ~ expanded.
+func (c AuditConfig) LogPath() string {
+	if c.Path == "" {
+		return filepath.Join(picoclawHome(), "audit", "tools.jsonl")
+	}
+	return expandHome(c.Path)
+}
+
+// SendMessageConfig enables the send_message tool, which lets the agent
+// message named contacts outside the current chat. Contacts map a name to
+// a "channel:chat_id" target; no other target can be reached.
+type SendMessageConfig struct {
+	Enabled  bool              `json:"enabled"  env:"PICOCLAW_TOOLS_SEND_MESSAGE_ENABLED"`
+	Contacts map[string]string `json:"contacts"`
 }
 
 type SkillsToolsConfig struct {
 	Registries            SkillsRegistriesConfig `json:"registries"`
 	MaxConcurrentSearches int                    `json:"max_concurrent_searches" env:"PICOCLAW_SKILLS_MAX_CONCURRENT_SEARCHES"`
 	SearchCache           SearchCacheConfig      `json:"search_cache"`
+	// Env holds the values of the environment variables skills declare in
+	// their SKILL.md; each skill's commands get the ones it declares.
+	Env map[string]string `json:"env,omitempty"`
 }
 
 type SearchCacheConfig struct {
@@ -581,8 +1662,69 @@ type SearchCacheConfig struct {
 	TTLSeconds int `json:"ttl_seconds" env:"PICOCLAW_SKILLS_SEARCH_CACHE_TTL_SECONDS"`
 }
 
+// SkillsRegistriesConfig holds the skill registries by name: "clawhub" is
+// the public ClawHub, and any other name adds a registry of its own, such as
+// a self-hosted catalog.
 type SkillsRegistriesConfig struct {
-	ClawHub ClawHubRegistryConfig `json:"clawhub"`
+	ClawHub ClawHubRegistryConfig
+	Custom  map[string]SkillRegistryConfig
+}
+
+// UnmarshalJSON reads the "clawhub" entry into ClawHub and the others into
+// Custom. Custom registries are enabled unless they say otherwise.
+func (r *SkillsRegistriesConfig) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for name, entry := range raw {
+		if name == "clawhub" {
+			if err := json.Unmarshal(entry, &r.ClawHub); err != nil {
+				return fmt.Errorf("skills registry %q: %w", name, err)
+			}
+			continue
+		}
+		reg := SkillRegistryConfig{ClawHubRegistryConfig: ClawHubRegistryConfig{Enabled: true}}
+		if err := json.Unmarshal(entry, &reg); err != nil {
+			return fmt.Errorf("skills registry %q: %w", name, err)
+		}
+		switch reg.Type {
+		case "", SkillRegistryClawHub, SkillRegistryIndex, SkillRegistryGit:
+		default:
+			return fmt.Errorf("skills registry %q: unknown type %q (use clawhub, index or git)", name, reg.Type)
+		}
+		if r.Custom == nil {
+			r.Custom = make(map[string]SkillRegistryConfig)
+		}
+		r.Custom[name] = reg
+	}
+	return nil
+}
+
+// MarshalJSON writes the registries back as one object keyed by name.
+func (r SkillsRegistriesConfig) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(r.Custom)+1)
+	for name, reg := range r.Custom {
+		out[name] = reg
+	}
+	out["clawhub"] = r.ClawHub
+	return json.Marshal(out)
+}
+
+// Skill registry types.
+const (
+	SkillRegistryClawHub = "clawhub" // a ClawHub-compatible HTTP API
+	SkillRegistryIndex   = "index"   // a static index.json and skill archives
+	SkillRegistryGit     = "git"     // a git repository, one skill per directory
+)
+
+// SkillRegistryConfig is a registry other than the public ClawHub. BaseURL
+// is the API base for the clawhub type, the URL of index.json for index, and
+// the repository URL for git. The ClawHub paths only apply to the clawhub
+// type.
+type SkillRegistryConfig struct {
+	Type string `json:"type,omitempty"` // default clawhub
+	ClawHubRegistryConfig
 }
 
 type ClawHubRegistryConfig struct {
@@ -592,9 +1734,15 @@ type ClawHubRegistryConfig struct {
 	SearchPath      string `json:"search_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SEARCH_PATH"`
 	SkillsPath      string `json:"skills_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_SKILLS_PATH"`
 	DownloadPath    string `json:"download_path"     env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_DOWNLOAD_PATH"`
+	UploadPath      string `json:"upload_path"       env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_UPLOAD_PATH"`
 	Timeout         int    `json:"timeout"           env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_TIMEOUT"`
 	MaxZipSize      int    `json:"max_zip_size"      env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_MAX_ZIP_SIZE"`
 	MaxResponseSize int    `json:"max_response_size" env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_MAX_RESPONSE_SIZE"`
+	// MaxRetries and RetryBackoff (seconds, doubled after each retry) retry
+	// the metadata fetch and download of an install after network errors
+	// and 5xx or 429 responses.
+	MaxRetries   int `json:"max_retries,omitempty"   env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_MAX_RETRIES"`
+	RetryBackoff int `json:"retry_backoff,omitempty" env:"PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_RETRY_BACKOFF"`
 }
 
 // MCPServerConfig defines configuration for a single MCP server
@@ -626,15 +1774,38 @@ type MCPConfig struct {
 }
 
 func LoadConfig(path string) (*Config, error) {
-	cfg := DefaultConfig()
-
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return cfg, nil
+			return DefaultConfig(), nil
 		}
 		return nil, err
 	}
+	cfg, err := parseConfig(data, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveSecretFiles(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadTemplate merges the config template at path over the defaults. Unlike
+// LoadConfig it ignores PICOCLAW_* environment overrides, so the result can
+// be saved as a config file without picking up the current environment.
+func LoadTemplate(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(data, false)
+}
+
+// parseConfig decodes data over the defaults, then migrates and validates
+// the result.
+func parseConfig(data []byte, applyEnv bool) (*Config, error) {
+	cfg := DefaultConfig()
 
 	// Pre-scan the JSON to check how many model_list entries the user provided.
 	// Go's JSON decoder reuses existing slice backing-array elements rather than
@@ -653,11 +1824,16 @@ func LoadConfig(path string) (*Config, error) {
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
-
-	if err := env.Parse(cfg); err != nil {
+	if err := cfg.Channels.enableConfigured(data); err != nil {
 		return nil, err
 	}
 
+	if applyEnv {
+		if err := env.Parse(cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	// Migrate legacy channel config fields to new unified structures
 	cfg.migrateChannelConfigs()
 
@@ -671,6 +1847,33 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.ValidateChatModels(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ValidateMediaConfigs(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.ValidateBudget(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateSecurity(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateAutoRoute(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateChannelTools(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateNoProvider(); err != nil {
+		return nil, err
+	}
+	if err := cfg.ValidateStatusPage(); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
@@ -687,8 +1890,10 @@ func (c *Config) migrateChannelConfigs() {
 	}
 }
 
+// SaveConfig writes cfg to path. API keys that were read from secret files
+// are written as their file references.
 func SaveConfig(path string, cfg *Config) error {
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.MarshalIndent(cfg.withSecretRefs(), "", "  ")
 	if err != nil {
 		return err
 	}
@@ -770,26 +1975,62 @@ func (c *Config) GetModelConfig(modelName string) (*ModelConfig, error) {
 	if len(matches) == 0 {
 		return nil, fmt.Errorf("model %q not found in model_list or providers", modelName)
 	}
-	if len(matches) == 1 {
-		return &matches[0], nil
+	idx := 0
+	if len(matches) > 1 {
+		// Multiple configs - use round-robin for load balancing
+		idx = int(rrCounter.Add(1) % uint64(len(matches)))
 	}
 
-	// Multiple configs - use round-robin for load balancing
-	idx := rrCounter.Add(1) % uint64(len(matches))
-	return &matches[idx], nil
+	return c.ModelConfigAt(matches[idx]), nil
 }
 
-// findMatches finds all ModelConfig entries with the given model_name.
-func (c *Config) findMatches(modelName string) []ModelConfig {
-	var matches []ModelConfig
+// LookupModelConfig returns the first model_list entry named modelName.
+// Unlike GetModelConfig it does not advance the round-robin cursor, so
+// reading an entry's settings (capabilities, pricing) does not shift which
+// entry serves the next request.
+func (c *Config) LookupModelConfig(modelName string) (*ModelConfig, bool) {
 	for i := range c.ModelList {
 		if c.ModelList[i].ModelName == modelName {
-			matches = append(matches, c.ModelList[i])
+			return c.ModelConfigAt(i), true
+		}
+	}
+	return nil, false
+}
+
+// ModelConfigAt returns a copy of model_list entry i with
+// providers.openrouter.route merged into an openrouter/ model's own route.
+// Build providers from it rather than from the raw entry.
+func (c *Config) ModelConfigAt(i int) *ModelConfig {
+	mc := c.ModelList[i]
+	if strings.HasPrefix(strings.TrimSpace(mc.Model), "openrouter/") {
+		mc.Route = c.Providers.OpenRouter.Route.Merge(mc.Route)
+	}
+	return &mc
+}
+
+// findMatches finds the indexes of the model_list entries with the given
+// model_name.
+func (c *Config) findMatches(modelName string) []int {
+	var matches []int
+	for i := range c.ModelList {
+		if c.ModelList[i].ModelName == modelName {
+			matches = append(matches, i)
 		}
 	}
 	return matches
 }
 
+// ModelNames returns the distinct model_name values in model_list, in order.
+func (c *Config) ModelNames() []string {
+	var names []string
+	for i := range c.ModelList {
+		if !slices.Contains(names, c.ModelList[i].ModelName) {
+			names = append(names, c.ModelList[i].ModelName)
+		}
+	}
+	return names
+}
+
 // HasProvidersConfig checks if any provider in the old providers config has configuration.
 func (c *Config) HasProvidersConfig() bool {
 	return !c.Providers.IsEmpty()
@@ -806,3 +2047,40 @@ func (c *Config) ValidateModelList() error {
 	}
 	return nil
 }
+
+// ValidateMediaConfigs checks that channels.<name>.media.download only
+// names known media kinds, and the media limits.
+func (c *Config) ValidateMediaConfigs() error {
+	if c.Tools.MediaNormalize.MaxDimension < 0 {
+		return fmt.Errorf("tools.media_normalize.max_dimension: must not be negative, got %d",
+			c.Tools.MediaNormalize.MaxDimension)
+	}
+	for channel, media := range c.Channels.MediaConfigs() {
+		if media.MaxConcurrentDownloads < 0 {
+			return fmt.Errorf("channels.%s.media.max_concurrent_downloads: must not be negative, got %d",
+				channel, media.MaxConcurrentDownloads)
+		}
+		for _, kind := range media.Download {
+			if !slices.Contains(MediaKinds, strings.ToLower(strings.TrimSpace(kind))) {
+				return fmt.Errorf("channels.%s.media.download: unknown media kind %q (valid: %s)",
+					channel, kind, strings.Join(MediaKinds, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateChatModels checks that every channels.<name>.chats.<id>.model
+// names an entry in model_list.
+func (c *Config) ValidateChatModels() error {
+	for channel, chats := range c.Channels.AllChatConfigs() {
+		for chatID, chat := range chats {
+			if chat.Model == "" || len(c.findMatches(chat.Model)) > 0 {
+				continue
+			}
+			return fmt.Errorf("channels.%s.chats.%s.model: unknown model %q (valid: %s)",
+				channel, chatID, chat.Model, strings.Join(c.ModelNames(), ", "))
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -434,7 +435,227 @@ func TestLoadConfig_WebToolsProxy(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_ChatModels(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	configJSON := `{
+  "model_list": [
+    {"model_name":"cheap","model":"openai/gpt-5-mini","api_key":"x"},
+    {"model_name":"best","model":"openai/gpt-5.2","api_key":"x"}
+  ],
+  "channels": {"telegram": {"chats": {"-100": {"model": "cheap"}}}}
+}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if got := cfg.Channels.ChatModel("telegram", "-100"); got != "cheap" {
+		t.Fatalf("ChatModel() = %q, want %q", got, "cheap")
+	}
+	if got := cfg.Channels.ChatModel("telegram", "-100:topic:5"); got != "cheap" {
+		t.Fatalf("ChatModel() for a topic = %q, want the group's %q", got, "cheap")
+	}
+	if got := cfg.Channels.ChatModel("telegram", "other"); got != "" {
+		t.Fatalf("ChatModel() for unconfigured chat = %q, want empty", got)
+	}
+
+	bad := strings.Replace(configJSON, `"model": "cheap"`, `"model": "nope"`, 1)
+	if err := os.WriteFile(configPath, []byte(bad), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	_, err = LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "valid: cheap, best") {
+		t.Fatalf("LoadConfig() error = %v, want unknown model listing valid names", err)
+	}
+}
+
+func TestLoadConfig_NamedAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	configJSON := `{
+  "agents": {
+    "list": [{"id": "main", "default": true}, {"id": "coder", "name": "old"}],
+    "named": {
+      "research": {"model": "best", "skills": ["arxiv"]},
+      "coder": {"model": "cheap", "tools": "exec,read_file", "system_prompt_file": "CODER.md"}
+    }
+  },
+  "bindings": [{"agent_id": "coder", "match": {"channel": "discord"}}]
+}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if got := cfg.Agents.IDs(); !slices.Equal(got, []string{"main", "coder", "research"}) {
+		t.Fatalf("IDs() = %v, want main, coder, research", got)
+	}
+	coder, err := cfg.Agents.Lookup("coder")
+	if err != nil {
+		t.Fatalf("Lookup() error: %v", err)
+	}
+	if coder.Name != "" || coder.Tools != "exec,read_file" || coder.SystemPromptFile != "CODER.md" {
+		t.Fatalf("Lookup(coder) = %+v, want the named entry", coder)
+	}
+	if _, err := cfg.Agents.Lookup("butler"); err == nil ||
+		!strings.Contains(err.Error(), "available: main, coder, research") {
+		t.Fatalf("Lookup(butler) error = %v, want the available agents", err)
+	}
+
+	bad := strings.Replace(configJSON, `"agent_id": "coder"`, `"agent_id": "butler"`, 1)
+	if err := os.WriteFile(configPath, []byte(bad), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	// An unknown agent in a binding is routed to the default agent, so it
+	// must not stop the config from loading.
+	if _, err := LoadConfig(configPath); err != nil {
+		t.Fatalf("LoadConfig() with an unknown binding agent error: %v", err)
+	}
+}
+
 // TestDefaultConfig_DMScope verifies the default dm_scope value
+func TestLoadConfig_MediaDownload(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	configJSON := `{
+  "channels": {
+    "telegram": {"media": {"download": ["image", "Audio"]}},
+    "line": {"media": {"download": []}}
+  }
+}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	tg := cfg.Channels.Telegram.Media
+	if !tg.Downloads(MediaImage) || !tg.Downloads(MediaAudio) || tg.Downloads(MediaVideo) {
+		t.Errorf("telegram downloads image %v, audio %v, video %v; want true, true, false",
+			tg.Downloads(MediaImage), tg.Downloads(MediaAudio), tg.Downloads(MediaVideo))
+	}
+	if cfg.Channels.LINE.Media.Downloads(MediaImage) {
+		t.Error("an empty download list should skip all media")
+	}
+	if !cfg.Channels.Slack.Media.Downloads(MediaVideo) {
+		t.Error("an unset download list should download all media")
+	}
+
+	configJSON = `{"channels": {"slack": {"media": {"download": ["image", "movies"]}}}}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), `"movies"`) {
+		t.Fatalf("LoadConfig() error = %v, want unknown media kind", err)
+	}
+
+	configJSON = `{"channels": {"line": {"media": {"max_concurrent_downloads": -1}}}}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "max_concurrent_downloads") {
+		t.Fatalf("LoadConfig() error = %v, want negative max_concurrent_downloads rejected", err)
+	}
+}
+
+func TestLoadConfig_ConfiguredChannelsEnabledByDefault(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{"channels":{
+		"telegram":{"token":"123:abc"},
+		"line":{"channel_secret":"s","enabled":false},
+		"discord":{}
+	}}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if !cfg.Channels.Telegram.Enabled {
+		t.Error("a configured channel without enabled should be enabled")
+	}
+	if cfg.Channels.LINE.Enabled {
+		t.Error("enabled: false should keep the channel off")
+	}
+	if cfg.Channels.Discord.Enabled {
+		t.Error("an empty channel section should stay off")
+	}
+}
+
+func TestChannelsConfig_Restrict(t *testing.T) {
+	enabled := func() ChannelsConfig {
+		var c ChannelsConfig
+		c.Telegram.Enabled = true
+		c.Discord.Enabled = true
+		c.LINE.Enabled = true
+		return c
+	}
+
+	c := enabled()
+	if err := c.Restrict([]string{"line", "telegram"}, nil); err != nil {
+		t.Fatalf("Restrict() error: %v", err)
+	}
+	if !c.Telegram.Enabled || !c.LINE.Enabled || c.Discord.Enabled {
+		t.Errorf("--only line,telegram left telegram=%v line=%v discord=%v",
+			c.Telegram.Enabled, c.LINE.Enabled, c.Discord.Enabled)
+	}
+
+	c = enabled()
+	if err := c.Restrict(nil, []string{"discord"}); err != nil {
+		t.Fatalf("Restrict() error: %v", err)
+	}
+	if !c.Telegram.Enabled || !c.LINE.Enabled || c.Discord.Enabled {
+		t.Errorf("--disable discord left telegram=%v line=%v discord=%v",
+			c.Telegram.Enabled, c.LINE.Enabled, c.Discord.Enabled)
+	}
+
+	// --only does not turn on a channel the config leaves off.
+	c = ChannelsConfig{}
+	if err := c.Restrict([]string{"slack"}, nil); err != nil || c.Slack.Enabled {
+		t.Errorf("Restrict() = %v, slack enabled %v; want nil, false", err, c.Slack.Enabled)
+	}
+
+	if err := c.Restrict([]string{"telegarm"}, nil); err == nil || !strings.Contains(err.Error(), "telegarm") {
+		t.Errorf("Restrict() with a typo error = %v, want it named", err)
+	}
+}
+
+func TestValidateBudget(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Budget = BudgetConfig{
+		Timezone: "UTC",
+		Channels: map[string]BudgetLimit{"discord": {Period: BudgetMonth, Tokens: 100000}},
+		Chats:    map[string]BudgetLimit{"telegram:-100": {Cost: 1}},
+	}
+	if err := cfg.ValidateBudget(); err != nil {
+		t.Fatalf("ValidateBudget() error: %v", err)
+	}
+
+	for name, budget := range map[string]BudgetConfig{
+		"timezone": {Timezone: "Mars/Olympus"},
+		"period":   {Channels: map[string]BudgetLimit{"discord": {Period: "week"}}},
+		"negative": {Chats: map[string]BudgetLimit{"telegram:1": {Tokens: -1}}},
+		"chat key": {Chats: map[string]BudgetLimit{"telegram": {Cost: 1}}},
+	} {
+		cfg.Budget = budget
+		if err := cfg.ValidateBudget(); err == nil {
+			t.Errorf("%s: ValidateBudget() accepted %+v", name, budget)
+		}
+	}
+}
+
 func TestDefaultConfig_DMScope(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -467,3 +688,170 @@ func TestDefaultConfig_WorkspacePath_WithPicoclawHome(t *testing.T) {
 		t.Errorf("Workspace path with PICOCLAW_HOME = %q, want %q", cfg.Agents.Defaults.Workspace, want)
 	}
 }
+
+func TestAuditConfig_LogPathOutsideWorkspace(t *testing.T) {
+	t.Setenv("PICOCLAW_HOME", "/custom/picoclaw/home")
+
+	cfg := DefaultConfig()
+	if got, want := cfg.Tools.Audit.LogPath(), "/custom/picoclaw/home/audit/tools.jsonl"; got != want {
+		t.Errorf("default LogPath() = %q, want %q", got, want)
+	}
+	if strings.HasPrefix(cfg.Tools.Audit.LogPath(), cfg.Agents.Defaults.Workspace+"/") {
+		t.Errorf("audit log %q is inside the workspace", cfg.Tools.Audit.LogPath())
+	}
+
+	home, _ := os.UserHomeDir()
+	cfg.Tools.Audit.Path = "~/logs/audit.jsonl"
+	if got, want := cfg.Tools.Audit.LogPath(), home+"/logs/audit.jsonl"; got != want {
+		t.Errorf("LogPath() = %q, want %q", got, want)
+	}
+}
+
+func TestModelPricing_Cost(t *testing.T) {
+	p := &ModelPricing{Input: 2, CachedInput: 0.5, Output: 8}
+	if got := p.Cost(1_000_000, 500_000, 250_000); got != 1+0.25+2 {
+		t.Errorf("Cost() = %v, want 3.25", got)
+	}
+
+	noCacheRate := &ModelPricing{Input: 2, Output: 8}
+	if got := noCacheRate.Cost(1_000_000, 500_000, 0); got != 2 {
+		t.Errorf("Cost() without cached_input = %v, want 2", got)
+	}
+
+	var unpriced *ModelPricing
+	if got := unpriced.Cost(1, 0, 1); got != 0 {
+		t.Errorf("nil pricing Cost() = %v, want 0", got)
+	}
+}
+
+func TestLoadConfig_SkillRegistries(t *testing.T) {
+	t.Setenv("PICOCLAW_SKILLS_REGISTRIES_CLAWHUB_TIMEOUT", "45")
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{"tools":{"skills":{"registries":{
+  "clawhub": {"enabled": false},
+  "acme": {"type": "index", "base_url": "https://skills.example.com/index.json"},
+  "mirror": {"base_url": "https://hub.example.com", "auth_token": "t", "enabled": false}
+}}}}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	regs := cfg.Tools.Skills.Registries
+	if regs.ClawHub.Enabled || regs.ClawHub.BaseURL != "https://clawhub.ai" || regs.ClawHub.Timeout != 45 {
+		t.Errorf("clawhub = %+v, want disabled with the default base URL and timeout from env", regs.ClawHub)
+	}
+	acme := regs.Custom["acme"]
+	if acme.Type != SkillRegistryIndex || !acme.Enabled || acme.BaseURL != "https://skills.example.com/index.json" {
+		t.Errorf("acme = %+v, want an enabled index registry", acme)
+	}
+	if mirror := regs.Custom["mirror"]; mirror.Enabled || mirror.AuthToken != "t" {
+		t.Errorf("mirror = %+v, want disabled with its token", mirror)
+	}
+
+	out, err := json.Marshal(regs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back SkillsRegistriesConfig
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Custom["acme"] != acme || back.ClawHub != regs.ClawHub {
+		t.Errorf("round trip = %+v, want %+v", back, regs)
+	}
+}
+
+func TestLoadConfig_SkillRegistryUnknownType(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	data := `{"tools":{"skills":{"registries":{"acme":{"type":"ftp"}}}}}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "unknown type") {
+		t.Errorf("LoadConfig() error = %v, want unknown type", err)
+	}
+}
+
+func TestValidateAutoRoute(t *testing.T) {
+	cfg := &Config{ModelList: []ModelConfig{{ModelName: "a", Model: "openai/a"}}}
+	cfg.Agents.Defaults.AutoRoute = AutoRouteConfig{
+		Enabled: true,
+		Tiers:   []AutoRouteTier{{Name: "cheap", Model: "a"}, {Name: "top", Model: "missing"}},
+	}
+	if err := cfg.ValidateAutoRoute(); err == nil || !strings.Contains(err.Error(), "top") {
+		t.Errorf("err = %v, want the unknown model of tier top", err)
+	}
+	cfg.Agents.Defaults.AutoRoute.Tiers[1] = AutoRouteTier{Name: "Cheap", Model: "a"}
+	if err := cfg.ValidateAutoRoute(); err == nil {
+		t.Error("expected duplicate tier names to be rejected")
+	}
+}
+
+func TestLoadConfig_RejectsUnknownNoProviderBehavior(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	data := `{"gateway":{"no_provider":{"behavior":"friendly"}}}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if _, err := LoadConfig(configPath); err == nil || !strings.Contains(err.Error(), "no_provider") {
+		t.Errorf("LoadConfig() error = %v, want the bad gateway.no_provider.behavior", err)
+	}
+}
+
+func TestValidateChannelTools(t *testing.T) {
+	cfg := &Config{}
+	cfg.Agents.Defaults.ChannelTools = map[string]string{
+		"telegram": "none",
+		"discord":  "web_search, read_file",
+		"slack":    "Auto",
+	}
+	if err := cfg.ValidateChannelTools(); err != nil {
+		t.Fatalf("ValidateChannelTools() error = %v", err)
+	}
+
+	cfg.Agents.Defaults.ChannelTools["line"] = " , "
+	if err := cfg.ValidateChannelTools(); err == nil || !strings.Contains(err.Error(), "channel_tools.line") {
+		t.Errorf("ValidateChannelTools() error = %v, want the invalid line entry", err)
+	}
+}
+
+func TestValidateStatusPage(t *testing.T) {
+	cfg := &Config{}
+	if err := cfg.ValidateStatusPage(); err != nil {
+		t.Fatalf("ValidateStatusPage() without basic_auth error = %v", err)
+	}
+
+	cfg.Gateway.StatusPage.BasicAuth = &BasicAuthConfig{Username: "ops", Password: "s3cret"}
+	if err := cfg.ValidateStatusPage(); err != nil {
+		t.Fatalf("ValidateStatusPage() error = %v", err)
+	}
+
+	for _, auth := range []BasicAuthConfig{{}, {Username: "ops"}, {Password: "s3cret"}} {
+		cfg.Gateway.StatusPage.BasicAuth = &auth
+		if err := cfg.ValidateStatusPage(); err == nil || !strings.Contains(err.Error(), "basic_auth") {
+			t.Errorf("ValidateStatusPage(%+v) error = %v, want the incomplete basic_auth", auth, err)
+		}
+	}
+}
+
+func TestAllChatConfigs_WhatsAppNative(t *testing.T) {
+	var c ChannelsConfig
+	c.WhatsApp.Chats = map[string]ChatConfig{"123": {Model: "fast"}}
+
+	if all := c.AllChatConfigs(); len(all) != 1 || all["whatsapp"]["123"].Model != "fast" {
+		t.Errorf("AllChatConfigs() = %v, want the chats under whatsapp", all)
+	}
+	c.WhatsApp.UseNative = true
+	if all := c.AllChatConfigs(); len(all) != 1 || all["whatsapp_native"]["123"].Model != "fast" {
+		t.Errorf("AllChatConfigs() = %v, want the chats under whatsapp_native", all)
+	}
+	if err := c.Restrict([]string{"whatsapp_native"}, nil); err != nil {
+		t.Errorf("Restrict(whatsapp_native) error = %v", err)
+	}
+}
@@ -10,17 +10,19 @@ import (
 	"path/filepath"
 )
 
+// picoclawHome returns the base path for the workspace and other state.
+// Priority: $PICOCLAW_HOME > ~/.picoclaw
+func picoclawHome() string {
+	if home := os.Getenv("PICOCLAW_HOME"); home != "" {
+		return home
+	}
+	userHome, _ := os.UserHomeDir()
+	return filepath.Join(userHome, ".picoclaw")
+}
+
 // DefaultConfig returns the default configuration for PicoClaw.
 func DefaultConfig() *Config {
-	// Determine the base path for the workspace.
-	// Priority: $PICOCLAW_HOME > ~/.picoclaw
-	var homePath string
-	if picoclawHome := os.Getenv("PICOCLAW_HOME"); picoclawHome != "" {
-		homePath = picoclawHome
-	} else {
-		userHome, _ := os.UserHomeDir()
-		homePath = filepath.Join(userHome, ".picoclaw")
-	}
+	homePath := picoclawHome()
 	workspacePath := filepath.Join(homePath, "workspace")
 
 	return &Config{
@@ -33,6 +35,10 @@ func DefaultConfig() *Config {
 				MaxTokens:           32768,
 				Temperature:         nil, // nil means use provider default
 				MaxToolIterations:   50,
+				AutoRoute: AutoRouteConfig{
+					Enabled:          false,
+					EscalateKeywords: []string{"think hard", "think harder", "step by step", "best model", "be thorough"},
+				},
 			},
 		},
 		Bindings: []AgentBinding{},
@@ -54,7 +60,6 @@ func DefaultConfig() *Config {
 				Typing:    TypingConfig{Enabled: true},
 				Placeholder: PlaceholderConfig{
 					Enabled: true,
-					Text:    "Thinking... 💭",
 				},
 			},
 			Feishu: FeishuConfig{
@@ -102,6 +107,7 @@ func DefaultConfig() *Config {
 				WebhookHost:        "0.0.0.0",
 				WebhookPort:        18791,
 				WebhookPath:        "/webhook/line",
+				MaxMediaSizeBytes:  20 << 20,
 				AllowFrom:          FlexibleStringSlice{},
 				GroupTrigger:       GroupTriggerConfig{MentionOnly: true},
 			},
@@ -159,6 +165,11 @@ func DefaultConfig() *Config {
 		},
 		Providers: ProvidersConfig{
 			OpenAI: OpenAIProviderConfig{WebSearch: true},
+			HTTP: HTTPClientConfig{
+				MaxIdleConnsPerHost: 8,
+				TLSHandshakeTimeout: 10,
+				IdleConnTimeout:     90,
+			},
 		},
 		ModelList: []ModelConfig{
 			// ============================================
@@ -315,8 +326,29 @@ func DefaultConfig() *Config {
 			},
 		},
 		Gateway: GatewayConfig{
-			Host: "127.0.0.1",
-			Port: 18790,
+			Host:               "127.0.0.1",
+			Port:               18790,
+			MaxConcurrentTurns: 2,
+			MaxQueuedTurns:     16,
+			NoProvider:         NoProviderConfig{Behavior: NoProviderFailFast},
+			UpdateCheck: UpdateCheckConfig{
+				Enabled:      false,
+				Channel:      "stable",
+				VersionCheck: true,
+			},
+			Digest: DigestConfig{
+				Enabled:   false,
+				Times:     FlexibleStringSlice{"08:00", "18:00"},
+				Summarize: true,
+			},
+			ChannelRestart: ChannelRestartConfig{
+				DelaySeconds: 5,
+				MaxRestarts:  3,
+			},
+			Streaming: StreamingConfig{
+				Enabled:        false,
+				EditIntervalMS: 1000,
+			},
 		},
 		Tools: ToolsConfig{
 			MediaCleanup: MediaCleanupConfig{
@@ -324,6 +356,10 @@ func DefaultConfig() *Config {
 				MaxAge:   30,
 				Interval: 5,
 			},
+			MediaNormalize: MediaNormalizeConfig{
+				Enabled:      true,
+				MaxDimension: 2048,
+			},
 			Web: WebToolsConfig{
 				Proxy:           "",
 				FetchLimitBytes: 10 * 1024 * 1024, // 10MB by default
@@ -344,10 +380,16 @@ func DefaultConfig() *Config {
 			},
 			Cron: CronToolsConfig{
 				ExecTimeoutMinutes: 5,
+				RecentContext:      RecentContextConfig{Turns: 6, MaxTokens: 400},
+				MaxAgentJobs:       20,
 			},
 			Exec: ExecConfig{
 				EnableDenyPatterns: true,
 			},
+			Audit: AuditConfig{
+				Enabled: true,
+				Path:    filepath.Join(homePath, "audit", "tools.jsonl"),
+			},
 			Skills: SkillsToolsConfig{
 				Registries: SkillsRegistriesConfig{
 					ClawHub: ClawHubRegistryConfig{
@@ -367,12 +409,36 @@ func DefaultConfig() *Config {
 			},
 		},
 		Heartbeat: HeartbeatConfig{
-			Enabled:  true,
-			Interval: 30,
+			Enabled:       true,
+			Interval:      30,
+			RecentContext: RecentContextConfig{Turns: 6, MaxTokens: 400},
 		},
 		Devices: DevicesConfig{
 			Enabled:    false,
 			MonitorUSB: true,
 		},
+		Commands: CommandsConfig{
+			Enabled: true,
+		},
+		Moderation: ModerationConfig{
+			Enabled:       false,
+			Provider:      "openai",
+			CheckInbound:  true,
+			CheckOutbound: true,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           false,
+			MessagesPerMinute: 6,
+			MessagesPerHour:   60,
+			DailyTokens:       200000,
+		},
+		Security: SecurityConfig{
+			Scan: ScanConfig{
+				Mode:           ScanModeOff,
+				Command:        "clamscan",
+				Args:           []string{"--no-summary"},
+				TimeoutSeconds: 30,
+			},
+		},
 	}
 }
@@ -144,7 +144,7 @@ func TestConvertProvidersToModelList_AllProviders(t *testing.T) {
 			OpenAI:        OpenAIProviderConfig{ProviderConfig: ProviderConfig{APIKey: "key1"}},
 			LiteLLM:       ProviderConfig{APIKey: "key-litellm", APIBase: "http://localhost:4000/v1"},
 			Anthropic:     ProviderConfig{APIKey: "key2"},
-			OpenRouter:    ProviderConfig{APIKey: "key3"},
+			OpenRouter:    OpenRouterConfig{ProviderConfig: ProviderConfig{APIKey: "key3"}},
 			Groq:          ProviderConfig{APIKey: "key4"},
 			Zhipu:         ProviderConfig{APIKey: "key5"},
 			VLLM:          ProviderConfig{APIKey: "key6"},
@@ -589,7 +589,7 @@ func TestConvertProvidersToModelList_LegacyModelWithProtocolPrefix(t *testing.T)
 			},
 		},
 		Providers: ProvidersConfig{
-			OpenRouter: ProviderConfig{APIKey: "sk-or-test"},
+			OpenRouter: OpenRouterConfig{ProviderConfig: ProviderConfig{APIKey: "sk-or-test"}},
 		},
 	}
 
@@ -398,3 +398,72 @@ func TestModelConfig_RequestTimeoutDefaultZeroValue(t *testing.T) {
 		t.Fatalf("RequestTimeout = %d, want 0", cfg.RequestTimeout)
 	}
 }
+
+func TestGetModelConfig_MergesOpenRouterRoute(t *testing.T) {
+	var cfg Config
+	data := `{
+		"providers": {"openrouter": {"route": {"deny": ["deepinfra"], "sort": "price"}}},
+		"model_list": [
+			{"model_name": "claude", "model": "openrouter/anthropic/claude-sonnet-4.6", "api_key": "k",
+			 "route": {"order": ["anthropic"], "sort": "latency"}},
+			{"model_name": "auto", "model": "openrouter/auto", "api_key": "k"},
+			{"model_name": "gpt", "model": "openai/gpt-4o", "api_key": "k"}
+		]
+	}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	claude, err := cfg.GetModelConfig("claude")
+	if err != nil {
+		t.Fatalf("GetModelConfig() error = %v", err)
+	}
+	if claude.Route == nil {
+		t.Fatal("expected merged route")
+	}
+	if strings.Join(claude.Route.Order, ",") != "anthropic" {
+		t.Errorf("Order = %v, want [anthropic]", claude.Route.Order)
+	}
+	if strings.Join(claude.Route.Deny, ",") != "deepinfra" {
+		t.Errorf("Deny = %v, want global [deepinfra]", claude.Route.Deny)
+	}
+	if claude.Route.Sort != "latency" {
+		t.Errorf("Sort = %q, want model override latency", claude.Route.Sort)
+	}
+
+	auto, _ := cfg.GetModelConfig("auto")
+	if auto.Route == nil || auto.Route.Sort != "price" {
+		t.Errorf("auto route = %+v, want global route", auto.Route)
+	}
+
+	gpt, _ := cfg.GetModelConfig("gpt")
+	if gpt.Route != nil {
+		t.Errorf("non-openrouter model got route %+v", gpt.Route)
+	}
+
+	// The stored model_list entry keeps only its own override.
+	if cfg.ModelList[0].Route.Deny != nil {
+		t.Errorf("GetModelConfig must not modify model_list, got %+v", cfg.ModelList[0].Route)
+	}
+}
+
+func TestModelConfigAt_MergesOpenRouterRoute(t *testing.T) {
+	cfg := Config{
+		Providers: ProvidersConfig{OpenRouter: OpenRouterConfig{Route: &OpenRouterRoute{Deny: []string{"deepinfra"}}}},
+		ModelList: []ModelConfig{
+			{ModelName: "or", Model: " openrouter/anthropic/claude-sonnet-4.6", Route: &OpenRouterRoute{Sort: "price"}},
+			{ModelName: "gpt", Model: "openai/gpt-4o"},
+		},
+	}
+
+	or := cfg.ModelConfigAt(0)
+	if or.Route == nil || or.Route.Sort != "price" || strings.Join(or.Route.Deny, ",") != "deepinfra" {
+		t.Errorf("ModelConfigAt(0).Route = %+v, want sort price and global deny", or.Route)
+	}
+	if cfg.ModelList[0].Route.Deny != nil {
+		t.Errorf("ModelConfigAt must not modify model_list, got %+v", cfg.ModelList[0].Route)
+	}
+	if gpt := cfg.ModelConfigAt(1); gpt.Route != nil {
+		t.Errorf("non-openrouter model got route %+v", gpt.Route)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// secretField is a config value that may hold a secret file reference.
+type secretField struct {
+	name  string
+	value *string
+}
+
+// secretFields returns the provider API key fields, which accept
+// "file:/path" or "${file:/path}" in place of the key itself.
+func (c *Config) secretFields() []secretField {
+	p := &c.Providers
+	fields := []secretField{
+		{"providers.anthropic.api_key", &p.Anthropic.APIKey},
+		{"providers.openai.api_key", &p.OpenAI.APIKey},
+		{"providers.litellm.api_key", &p.LiteLLM.APIKey},
+		{"providers.openrouter.api_key", &p.OpenRouter.APIKey},
+		{"providers.groq.api_key", &p.Groq.APIKey},
+		{"providers.zhipu.api_key", &p.Zhipu.APIKey},
+		{"providers.vllm.api_key", &p.VLLM.APIKey},
+		{"providers.gemini.api_key", &p.Gemini.APIKey},
+		{"providers.nvidia.api_key", &p.Nvidia.APIKey},
+		{"providers.ollama.api_key", &p.Ollama.APIKey},
+		{"providers.moonshot.api_key", &p.Moonshot.APIKey},
+		{"providers.shengsuanyun.api_key", &p.ShengSuanYun.APIKey},
+		{"providers.deepseek.api_key", &p.DeepSeek.APIKey},
+		{"providers.cerebras.api_key", &p.Cerebras.APIKey},
+		{"providers.volcengine.api_key", &p.VolcEngine.APIKey},
+		{"providers.github_copilot.api_key", &p.GitHubCopilot.APIKey},
+		{"providers.antigravity.api_key", &p.Antigravity.APIKey},
+		{"providers.qwen.api_key", &p.Qwen.APIKey},
+		{"providers.mistral.api_key", &p.Mistral.APIKey},
+	}
+	for i := range c.ModelList {
+		fields = append(fields, secretField{
+			name:  fmt.Sprintf("model_list[%s].api_key", c.ModelList[i].ModelName),
+			value: &c.ModelList[i].APIKey,
+		})
+	}
+	return fields
+}
+
+// secretFile returns the path of a "file:/path" or "${file:/path}"
+// reference, or false if value is not one.
+func secretFile(value string) (string, bool) {
+	v := strings.TrimSpace(value)
+	if strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}") {
+		v = v[2 : len(v)-1]
+	}
+	path, ok := strings.CutPrefix(v, "file:")
+	return strings.TrimSpace(path), ok
+}
+
+// resolveSecretFiles replaces secret file references with the trimmed
+// contents of the files, remembering each reference so SaveConfig writes it
+// back instead of the secret.
+func (c *Config) resolveSecretFiles() error {
+	for _, f := range c.secretFields() {
+		path, ok := secretFile(*f.value)
+		if !ok {
+			continue
+		}
+		if path == "" {
+			return fmt.Errorf("%s: secret file reference %q has no path", f.name, *f.value)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: cannot read secret file: %w", f.name, err)
+		}
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return fmt.Errorf("%s: secret file %s is empty", f.name, path)
+		}
+		if c.secretRefs == nil {
+			c.secretRefs = make(map[string]string)
+		}
+		c.secretRefs[secret] = *f.value
+		*f.value = secret
+	}
+	return nil
+}
+
+// withSecretRefs returns a copy of c with secrets read from files replaced
+// by their references again.
+func (c *Config) withSecretRefs() *Config {
+	if len(c.secretRefs) == 0 {
+		return c
+	}
+	out := *c
+	out.ModelList = slices.Clone(c.ModelList)
+	for _, f := range out.secretFields() {
+		if ref, ok := out.secretRefs[*f.value]; ok {
+			*f.value = ref
+		}
+	}
+	return &out
+}
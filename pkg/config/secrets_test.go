@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecretConfig(t *testing.T, dir, modelKey, providerKey string) string {
+	t.Helper()
+	configPath := filepath.Join(dir, "config.json")
+	configJSON := `{
+  "agents": {"defaults":{"workspace":"./workspace","model":"gpt4"}},
+  "model_list": [{"model_name":"gpt4","model":"openai/gpt-5.2","api_key":"` + modelKey + `"}],
+  "providers": {"anthropic": {"api_key": "` + providerKey + `"}}
+}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error: %v", err)
+	}
+	return configPath
+}
+
+func TestLoadConfig_SecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	openaiKey := filepath.Join(dir, "openai_key")
+	anthropicKey := filepath.Join(dir, "anthropic_key")
+	if err := os.WriteFile(openaiKey, []byte("sk-openai\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(anthropicKey, []byte("  sk-ant  "), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := writeSecretConfig(t, dir, "file:"+openaiKey, "${file:"+anthropicKey+"}")
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error: %v", err)
+	}
+	if got := cfg.ModelList[0].APIKey; got != "sk-openai" {
+		t.Errorf("model_list api_key = %q, want %q", got, "sk-openai")
+	}
+	if got := cfg.Providers.Anthropic.APIKey; got != "sk-ant" {
+		t.Errorf("providers.anthropic.api_key = %q, want %q", got, "sk-ant")
+	}
+
+	// Saving must keep the references, not the secrets they point to.
+	if err := SaveConfig(configPath, cfg); err != nil {
+		t.Fatalf("SaveConfig() error: %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-openai") || strings.Contains(string(data), "sk-ant") {
+		t.Errorf("saved config contains a secret:\n%s", data)
+	}
+	if !strings.Contains(string(data), "file:"+openaiKey) {
+		t.Errorf("saved config lost the secret file reference:\n%s", data)
+	}
+	if cfg.ModelList[0].APIKey != "sk-openai" {
+		t.Errorf("SaveConfig changed the loaded config")
+	}
+}
+
+func TestLoadConfig_SecretFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"file:" + filepath.Join(dir, "missing"): "model_list[gpt4].api_key: cannot read secret file",
+		"file:" + empty:                         "is empty",
+		"${file:}":                              "has no path",
+	}
+	for ref, want := range tests {
+		configPath := writeSecretConfig(t, dir, ref, "")
+		_, err := LoadConfig(configPath)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig() with api_key %q: error = %v, want it to contain %q", ref, err, want)
+		}
+	}
+}
+
+func TestLoadTemplate_KeepsSecretFileReferences(t *testing.T) {
+	dir := t.TempDir()
+	ref := "file:" + filepath.Join(dir, "not-created-yet")
+	cfg, err := LoadTemplate(writeSecretConfig(t, dir, ref, ""))
+	if err != nil {
+		t.Fatalf("LoadTemplate() error: %v", err)
+	}
+	if cfg.ModelList[0].APIKey != ref {
+		t.Errorf("api_key = %q, want the reference %q", cfg.ModelList[0].APIKey, ref)
+	}
+}
@@ -0,0 +1,187 @@
+// Package control is the running gateway's local control socket. The
+// gateway serves a few verbs (such as "send") as JSON over HTTP on a unix
+// socket next to its config, and CLI commands call them with Call. Access
+// is limited to the gateway's user: the socket lives in a directory only
+// that user can enter.
+package control
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+const (
+	socketDir  = "control"
+	socketName = "gateway.sock"
+)
+
+// ErrNotRunning is returned by Call when no gateway listens on the socket.
+var ErrNotRunning = errors.New("gateway is not running")
+
+// SocketPath returns the control socket path for the config directory dir.
+func SocketPath(dir string) string {
+	return filepath.Join(dir, socketDir, socketName)
+}
+
+// Handler serves one verb. It decodes the request from body and returns
+// the response to encode as JSON; an error is reported to the caller.
+type Handler func(ctx context.Context, body json.RawMessage) (any, error)
+
+// Server listens on the control socket.
+type Server struct {
+	path     string
+	mux      *http.ServeMux
+	server   *http.Server
+	listener net.Listener
+}
+
+func NewServer(path string) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		path:   path,
+		mux:    mux,
+		server: &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+	}
+}
+
+// Handle registers h for verb. Handlers must be registered before Start.
+func (s *Server) Handle(verb string, h Handler) {
+	s.mux.HandleFunc("POST /v1/"+verb, func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		resp, err := h(r.Context(), body)
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// Start listens on the socket and serves in the background. A socket left
+// behind by a crashed gateway is replaced; one that still answers means
+// another gateway is running. The socket's directory is created, or
+// tightened, to 0700 before listening, so no other user can connect in the
+// window before the socket itself is chmodded.
+func (s *Server) Start() error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create control socket directory: %w", err)
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to restrict control socket directory permissions: %w", err)
+	}
+
+	if conn, err := net.DialTimeout("unix", s.path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("another gateway is already listening on %s", s.path)
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale control socket: %w", err)
+	}
+
+	ln, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	if err := os.Chmod(s.path, 0o600); err != nil {
+		ln.Close()
+		return fmt.Errorf("failed to restrict control socket permissions: %w", err)
+	}
+	s.listener = ln
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.ErrorCF("control", "Control socket server stopped", map[string]any{"error": err.Error()})
+		}
+	}()
+	logger.InfoCF("control", "Control socket listening", map[string]any{"path": s.path})
+	return nil
+}
+
+// Stop closes the socket and removes its file.
+func (s *Server) Stop(ctx context.Context) {
+	if s.listener == nil {
+		return
+	}
+	s.server.Shutdown(ctx)
+	os.Remove(s.path)
+}
+
+// Call invokes verb on the gateway listening at path, sending req and
+// decoding the reply into resp (which may be nil).
+func Call(ctx context.Context, path, verb string, req, resp any) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ErrNotRunning
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://gateway/v1/"+verb, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return ErrNotRunning
+		}
+		return fmt.Errorf("control request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read control response: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		var e errorResponse
+		if json.Unmarshal(data, &e) == nil && e.Error != "" {
+			return errors.New(e.Error)
+		}
+		return fmt.Errorf("control request failed: status %d", httpResp.StatusCode)
+	}
+	if resp == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("failed to parse control response: %w", err)
+	}
+	return nil
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,107 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// shortTempDir keeps socket paths under the unix socket length limit.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestCallRoundTrip(t *testing.T) {
+	path := SocketPath(shortTempDir(t))
+	s := NewServer(path)
+	s.Handle("echo", func(ctx context.Context, body json.RawMessage) (any, error) {
+		var req struct{ Text string }
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		if req.Text == "" {
+			return nil, errors.New("text is required")
+		}
+		return map[string]string{"text": req.Text}, nil
+	})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer s.Stop(context.Background())
+
+	var resp struct{ Text string }
+	if err := Call(context.Background(), path, "echo", map[string]string{"text": "hi"}, &resp); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if resp.Text != "hi" {
+		t.Errorf("resp.Text = %q, want hi", resp.Text)
+	}
+
+	err := Call(context.Background(), path, "echo", map[string]string{}, nil)
+	if err == nil || err.Error() != "text is required" {
+		t.Errorf("Call() error = %v, want the handler's error", err)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode = %v (err %v), want 0600", info.Mode().Perm(), err)
+	}
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("socket directory mode = %v (err %v), want 0700", info.Mode().Perm(), err)
+	}
+}
+
+func TestStartTightensExistingSocketDir(t *testing.T) {
+	path := SocketPath(shortTempDir(t))
+	os.MkdirAll(filepath.Dir(path), 0o755)
+
+	s := NewServer(path)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer s.Stop(context.Background())
+
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("socket directory mode = %v (err %v), want 0700", info.Mode().Perm(), err)
+	}
+}
+
+func TestCallNotRunning(t *testing.T) {
+	dir := shortTempDir(t)
+	path := SocketPath(dir)
+
+	if err := Call(context.Background(), path, "echo", nil, nil); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Call() without socket error = %v, want ErrNotRunning", err)
+	}
+
+	// A socket file left behind by a crashed gateway.
+	os.MkdirAll(filepath.Dir(path), 0o700)
+	os.WriteFile(path, nil, 0o600)
+	if err := Call(context.Background(), path, "echo", nil, nil); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Call() with stale socket error = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestStartReplacesStaleSocketAndRefusesLiveOne(t *testing.T) {
+	path := SocketPath(shortTempDir(t))
+	os.MkdirAll(filepath.Dir(path), 0o700)
+	os.WriteFile(path, nil, 0o600)
+
+	first := NewServer(path)
+	if err := first.Start(); err != nil {
+		t.Fatalf("Start() over stale socket error = %v", err)
+	}
+	defer first.Stop(context.Background())
+
+	if err := NewServer(path).Start(); err == nil {
+		t.Error("second Start() on a live socket succeeded, want error")
+	}
+}
@@ -0,0 +1,150 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// notifyTimeout bounds a completion webhook call.
+const notifyTimeout = 10 * time.Second
+
+// SignatureHeader carries the HMAC-SHA256 of the webhook body, as
+// "sha256=<hex>", when the job's notification has a secret.
+const SignatureHeader = "X-Picoclaw-Signature"
+
+// Notification calls a webhook after every run of a job, e.g. to tell a
+// CI/CD system that the job completed.
+type Notification struct {
+	WebhookURL string `json:"webhookUrl"`
+	Method     string `json:"method,omitempty"` // POST when empty
+	// Template is a Go text/template rendered with NotificationData. When
+	// empty, the body is NotificationData as JSON.
+	Template string `json:"template,omitempty"`
+	// Secret signs the body with HMAC-SHA256 in SignatureHeader.
+	Secret string `json:"secret,omitempty"`
+}
+
+// NotificationData is what a notification template is rendered with. The
+// template function json encodes a value, e.g. {{json .Output}}.
+type NotificationData struct {
+	JobID      string `json:"jobId"`
+	JobName    string `json:"jobName"`
+	Status     string `json:"status"` // ok or error
+	Output     string `json:"output"`
+	Error      string `json:"error,omitempty"`
+	RunAtMS    int64  `json:"runAtMs"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+var notifyFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Validate checks the webhook URL, method and template.
+func (n *Notification) Validate() error {
+	u, err := url.Parse(n.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: use an http or https URL", n.WebhookURL)
+	}
+	switch n.method() {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("invalid webhook method %q: use POST, PUT or PATCH", n.Method)
+	}
+	if n.Template != "" {
+		if _, err := template.New("notification").Funcs(notifyFuncs).Parse(n.Template); err != nil {
+			return fmt.Errorf("invalid webhook template: %w", err)
+		}
+	}
+	return nil
+}
+
+func (n *Notification) method() string {
+	if n.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(n.Method)
+}
+
+// Render returns the request body for data.
+func (n *Notification) Render(data NotificationData) ([]byte, error) {
+	if n.Template == "" {
+		return json.Marshal(data)
+	}
+	tmpl, err := template.New("notification").Funcs(notifyFuncs).Parse(n.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the value of SignatureHeader for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send makes the webhook call.
+func (n *Notification) send(ctx context.Context, client *http.Client, data NotificationData) error {
+	body, err := n.Render(data)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, n.method(), n.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	if json.Valid(body) {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if n.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.Secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// notifyCompletion calls the job's webhook in the background, so a slow
+// endpoint never delays the scheduler. Failures are only logged.
+func (cs *CronService) notifyCompletion(job *CronJob, data NotificationData) {
+	if job.Notification == nil || job.Notification.WebhookURL == "" {
+		return
+	}
+	n := *job.Notification
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		if err := n.send(ctx, cs.httpClient, data); err != nil {
+			log.Printf("[cron] completion webhook for job %s failed: %v", data.JobID, err)
+		}
+	}()
+}
@@ -0,0 +1,80 @@
+package cron
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type webhookCall struct {
+	method    string
+	body      string
+	signature string
+}
+
+func TestNotifyCompletion_PostsSignedTemplate(t *testing.T) {
+	calls := make(chan webhookCall, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls <- webhookCall{method: r.Method, body: string(body), signature: r.Header.Get(SignatureHeader)}
+	}))
+	defer server.Close()
+
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	cs := NewCronService(storePath, func(job *CronJob) (string, error) {
+		return "build \"42\" passed", nil
+	})
+	job, err := cs.AddJob("ci", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	job.Notification = &Notification{
+		WebhookURL: server.URL,
+		Template:   `{"job":{{json .JobID}},"status":{{json .Status}},"output":{{json .Output}},"ms":{{.DurationMS}}}`,
+		Secret:     "s3cret",
+	}
+	if err := cs.UpdateJob(job); err != nil {
+		t.Fatalf("UpdateJob failed: %v", err)
+	}
+
+	cs.executeJobByID(job.ID, 0)
+
+	select {
+	case call := <-calls:
+		if call.method != http.MethodPost {
+			t.Errorf("method = %s, want POST", call.method)
+		}
+		want := `{"job":"` + job.ID + `","status":"ok","output":"build \"42\" passed","ms":`
+		if !strings.HasPrefix(call.body, want) {
+			t.Errorf("body = %s, want prefix %s", call.body, want)
+		}
+		if call.signature != Sign("s3cret", []byte(call.body)) {
+			t.Errorf("signature %q does not match the body", call.signature)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestNotificationValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		n    Notification
+		ok   bool
+	}{
+		{"default", Notification{WebhookURL: "https://ci.example.com/hook"}, true},
+		{"put", Notification{WebhookURL: "http://ci/hook", Method: "put"}, true},
+		{"no scheme", Notification{WebhookURL: "ci.example.com/hook"}, false},
+		{"get", Notification{WebhookURL: "https://ci/hook", Method: "GET"}, false},
+		{"bad template", Notification{WebhookURL: "https://ci/hook", Template: "{{.Status"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.n.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package cron
+
+import (
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// BuiltinPresets are the job templates available without any config.
+var BuiltinPresets = []config.CronPreset{
+	{
+		Name:        "daily-digest",
+		Description: "Evening summary of the day",
+		JobName:     "Daily digest",
+		Message:     "Summarize today's conversations, finished tasks and anything I should follow up on tomorrow.",
+		Cron:        "0 18 * * *",
+	},
+	{
+		Name:        "morning-briefing",
+		Description: "Short briefing to start the day",
+		JobName:     "Morning briefing",
+		Message:     "Give me a short morning briefing: today's scheduled tasks, reminders and anything left open yesterday.",
+		Cron:        "0 8 * * *",
+	},
+	{
+		Name:        "hourly-check",
+		Description: "Hourly look for anything that needs attention",
+		JobName:     "Hourly check",
+		Message:     "Check memory and pending tasks for anything that needs my attention now. Reply briefly, or say nothing needs attention.",
+		Every:       3600,
+		Digest:      true,
+	},
+	{
+		Name:        "weekly-review",
+		Description: "Friday review of the week",
+		JobName:     "Weekly review",
+		Message:     "Review this week's memory notes and summarize progress, open items and priorities for next week.",
+		Cron:        "0 17 * * 5",
+	},
+	{
+		Name:        "standup-reminder",
+		Description: "Weekday standup reminder, sent as is",
+		JobName:     "Standup reminder",
+		Message:     "Time for standup!",
+		Cron:        "25 9 * * 1-5",
+		Deliver:     true,
+	},
+}
+
+// Presets returns the built-in presets with user presets applied: a user
+// preset replaces the built-in of the same name, others are appended.
+func Presets(user []config.CronPreset) []config.CronPreset {
+	presets := make([]config.CronPreset, 0, len(BuiltinPresets)+len(user))
+	overridden := make(map[string]bool)
+	for _, p := range user {
+		overridden[p.Name] = true
+	}
+	for _, p := range BuiltinPresets {
+		if !overridden[p.Name] {
+			presets = append(presets, p)
+		}
+	}
+	return append(presets, user...)
+}
+
+// LookupPreset finds the preset called name among the built-in and user
+// presets.
+func LookupPreset(name string, user []config.CronPreset) (config.CronPreset, error) {
+	for _, p := range Presets(user) {
+		if p.Name == name {
+			if p.Every > 0 && p.Cron != "" {
+				return p, fmt.Errorf("preset %q sets both every and cron", name)
+			}
+			return p, nil
+		}
+	}
+	return config.CronPreset{}, fmt.Errorf("unknown preset %q (see `picoclaw cron presets`)", name)
+}
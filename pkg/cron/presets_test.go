@@ -0,0 +1,35 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestPresets_UserOverridesBuiltin(t *testing.T) {
+	user := []config.CronPreset{
+		{Name: "daily-digest", Message: "Team digest", Cron: "0 17 * * 1-5"},
+		{Name: "deploy-check", Message: "Check the deploy dashboard", Every: 900},
+	}
+
+	presets := Presets(user)
+	if len(presets) != len(BuiltinPresets)+1 {
+		t.Fatalf("got %d presets, want %d", len(presets), len(BuiltinPresets)+1)
+	}
+
+	p, err := LookupPreset("daily-digest", user)
+	if err != nil || p.Message != "Team digest" {
+		t.Errorf("LookupPreset(daily-digest) = %+v, %v; want the user preset", p, err)
+	}
+	if _, err := LookupPreset("deploy-check", user); err != nil {
+		t.Errorf("LookupPreset(deploy-check) error = %v", err)
+	}
+	if _, err := LookupPreset("missing", user); err == nil {
+		t.Error("LookupPreset(missing) should fail")
+	}
+
+	bad := []config.CronPreset{{Name: "both", Message: "x", Every: 60, Cron: "* * * * *"}}
+	if _, err := LookupPreset("both", bad); err == nil {
+		t.Error("a preset with both every and cron should be rejected")
+	}
+}
@@ -1,11 +1,14 @@
 package cron
 
 import (
-	"crypto/rand"
+	"context"
+	crand "crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"net/http"
 	"os"
 	"sync"
 	"time"
@@ -21,6 +24,9 @@ type CronSchedule struct {
 	EveryMS *int64 `json:"everyMs,omitempty"`
 	Expr    string `json:"expr,omitempty"`
 	TZ      string `json:"tz,omitempty"`
+	// JitterSeconds delays each run by a random amount of up to this many
+	// seconds, so jobs on the same schedule do not all start at once.
+	JitterSeconds int `json:"jitterSeconds,omitempty"`
 }
 
 type CronPayload struct {
@@ -30,42 +36,94 @@ type CronPayload struct {
 	Deliver bool   `json:"deliver"`
 	Channel string `json:"channel,omitempty"`
 	To      string `json:"to,omitempty"`
+	Digest  bool   `json:"digest,omitempty"` // batch output into the gateway digest
+	Urgent  bool   `json:"urgent,omitempty"` // always deliver immediately, even with Digest set
+	// WithContext adds a summary of the owner's recent conversation to the
+	// message for agent-processed jobs.
+	WithContext bool `json:"withContext,omitempty"`
 }
 
 type CronJobState struct {
 	NextRunAtMS *int64 `json:"nextRunAtMs,omitempty"`
-	LastRunAtMS *int64 `json:"lastRunAtMs,omitempty"`
-	LastStatus  string `json:"lastStatus,omitempty"`
-	LastError   string `json:"lastError,omitempty"`
+	// RunAtMS is when the next run actually starts: NextRunAtMS plus the
+	// job's jitter and the scheduler's stagger. It is planned by the run
+	// loop and cleared whenever NextRunAtMS changes.
+	RunAtMS     *int64    `json:"runAtMs,omitempty"`
+	LastRunAtMS *int64    `json:"lastRunAtMs,omitempty"`
+	LastStatus  string    `json:"lastStatus,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+	History     []CronRun `json:"history,omitempty"` // most recent last
+}
+
+// CronRun records one execution of a job. Delivery is filled in later, once
+// the channel reports whether the platform accepted the job's message; it
+// stays empty for runs that didn't send anything directly.
+type CronRun struct {
+	AtMS          int64  `json:"atMs"`
+	ScheduledAtMS int64  `json:"scheduledAtMs,omitempty"` // when the run was due, before jitter and stagger
+	Status        string `json:"status"`                  // running, ok, error or skipped
+	Error         string `json:"error,omitempty"`
+	Delivery      string `json:"delivery,omitempty"` // accepted or failed
+	DeliveryError string `json:"deliveryError,omitempty"`
+}
+
+// maxRunHistory is how many runs are kept per job.
+const maxRunHistory = 20
+
+// RunAsContact makes a job run in a specific user's chat context: the agent
+// sees the turn as coming from that chat and the reply is sent back to it.
+type RunAsContact struct {
+	Channel string `json:"channel"`
+	ChatID  string `json:"chatId"`
 }
 
 type CronJob struct {
-	ID             string       `json:"id"`
-	Name           string       `json:"name"`
-	Enabled        bool         `json:"enabled"`
-	Schedule       CronSchedule `json:"schedule"`
-	Payload        CronPayload  `json:"payload"`
-	State          CronJobState `json:"state"`
-	CreatedAtMS    int64        `json:"createdAtMs"`
-	UpdatedAtMS    int64        `json:"updatedAtMs"`
-	DeleteAfterRun bool         `json:"deleteAfterRun"`
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Enabled        bool          `json:"enabled"`
+	Schedule       CronSchedule  `json:"schedule"`
+	Payload        CronPayload   `json:"payload"`
+	RunAs          *RunAsContact `json:"runAs,omitempty"`
+	Notification   *Notification `json:"notification,omitempty"`
+	State          CronJobState  `json:"state"`
+	CreatedAtMS    int64         `json:"createdAtMs"`
+	UpdatedAtMS    int64         `json:"updatedAtMs"`
+	DeleteAfterRun bool          `json:"deleteAfterRun"`
+	CreatedBy      string        `json:"createdBy,omitempty"` // CreatedByAgent, or "" when added by the user
+}
+
+// CreatedByAgent marks jobs the agent scheduled itself with the cron tool.
+const CreatedByAgent = "agent"
+
+// ByAgent reports whether the agent scheduled job itself.
+func (job *CronJob) ByAgent() bool {
+	return job.CreatedBy == CreatedByAgent
 }
 
 type CronStore struct {
 	Version int       `json:"version"`
+	Paused  bool      `json:"paused,omitempty"` // set by PauseAll; jobs keep their Enabled state
 	Jobs    []CronJob `json:"jobs"`
 }
 
 type JobHandler func(job *CronJob) (string, error)
 
 type CronService struct {
-	storePath string
-	store     *CronStore
-	onJob     JobHandler
-	mu        sync.RWMutex
-	running   bool
-	stopChan  chan struct{}
-	gronx     *gronx.Gronx
+	storePath   string
+	store       *CronStore
+	onJob       JobHandler
+	mu          sync.RWMutex
+	running     bool
+	stopChan    chan struct{}
+	gronx       *gronx.Gronx
+	missed      []dueRun  // runs that were overdue when the service started
+	storeMod    time.Time // store file mtime at the last load/save, to notice external pause/resume
+	stagger     time.Duration
+	rng         *rand.Rand    // draws jitter
+	skip        func() string // reason to skip due jobs, "" to run them
+	savePending bool          // a saveLaterUnsafe save has not started yet
+
+	httpClient *http.Client // for completion webhooks
 }
 
 func NewCronService(storePath string, onJob JobHandler) *CronService {
@@ -73,12 +131,40 @@ func NewCronService(storePath string, onJob JobHandler) *CronService {
 		storePath: storePath,
 		onJob:     onJob,
 		gronx:     gronx.New(),
+		rng:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+
+		httpClient: &http.Client{Timeout: notifyTimeout},
 	}
 	// Initialize and load store on creation
 	cs.loadStore()
 	return cs
 }
 
+// SetStagger spreads jobs scheduled for the same instant: each one starts
+// d after the one before it. Zero starts them together.
+func (cs *CronService) SetStagger(d time.Duration) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.stagger = d
+}
+
+// SetRand replaces the random source jitter is drawn from, e.g. with a
+// seeded one in tests.
+func (cs *CronService) SetRand(r *rand.Rand) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.rng = r
+}
+
+// SetSkip makes due jobs be skipped while skip returns a reason, such as
+// "maintenance". A skipped run is recorded in the job's history with the
+// reason, and the job is scheduled as if it had run.
+func (cs *CronService) SetSkip(skip func() string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.skip = skip
+}
+
 func (cs *CronService) Start() error {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -91,7 +177,12 @@ func (cs *CronService) Start() error {
 		return fmt.Errorf("failed to load store: %w", err)
 	}
 
+	// Remember overdue jobs before their next run is recomputed, so
+	// RunAllDue can catch them up once the rest of the system is ready.
+	cs.missed = cs.dueRunsUnsafe(time.Now().UnixMilli())
+
 	cs.recomputeNextRuns()
+	cs.planRunsUnsafe()
 	if err := cs.saveStoreUnsafe(); err != nil {
 		return fmt.Errorf("failed to save store: %w", err)
 	}
@@ -140,63 +231,283 @@ func (cs *CronService) checkJobs() {
 		return
 	}
 
-	now := time.Now().UnixMilli()
-	var dueJobIDs []string
+	cs.syncPausedUnsafe()
+	if cs.store.Paused {
+		cs.mu.Unlock()
+		return
+	}
+
+	if cs.planRunsUnsafe() {
+		if err := cs.saveStoreUnsafe(); err != nil {
+			log.Printf("[cron] failed to save store: %v", err)
+		}
+	}
 
 	// Collect jobs that are due (we need to copy them to execute outside lock)
+	due := cs.dueRunsUnsafe(time.Now().UnixMilli())
+	cs.claimRunsUnsafe(due)
+
+	cs.mu.Unlock()
+
+	// Execute jobs outside lock.
+	for _, run := range due {
+		cs.executeJobByID(run.jobID, run.scheduledAtMS)
+	}
+}
+
+// RunAllDue immediately executes every enabled job whose next run is in the
+// past, including jobs that were overdue when the service started. It is
+// meant to be called once at startup to catch up on runs missed while the
+// gateway was down, and returns the number of jobs executed.
+func (cs *CronService) RunAllDue(ctx context.Context) int {
+	cs.mu.Lock()
+	if cs.store.Paused {
+		cs.missed = nil
+		cs.mu.Unlock()
+		return 0
+	}
+	seen := make(map[string]bool)
+	var due []dueRun
+	for _, run := range append(cs.missed, cs.dueRunsUnsafe(time.Now().UnixMilli())...) {
+		if !seen[run.jobID] {
+			seen[run.jobID] = true
+			due = append(due, run)
+		}
+	}
+	cs.missed = nil
+	cs.claimRunsUnsafe(due)
+	cs.mu.Unlock()
+
+	ran := 0
+	for _, run := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		cs.executeJobByID(run.jobID, run.scheduledAtMS)
+		ran++
+	}
+	return ran
+}
+
+// PauseAll stops all jobs from running until ResumeAll is called, without
+// touching each job's Enabled field. The paused state is saved in the store,
+// so it survives restarts and is picked up by a running gateway.
+func (cs *CronService) PauseAll() error {
+	return cs.setPaused(true)
+}
+
+// ResumeAll lifts a PauseAll. Runs that fell due while paused are skipped
+// and rescheduled rather than fired all at once.
+func (cs *CronService) ResumeAll() error {
+	return cs.setPaused(false)
+}
+
+// IsPaused reports whether PauseAll is in effect.
+func (cs *CronService) IsPaused() bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.store.Paused
+}
+
+func (cs *CronService) setPaused(paused bool) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.syncPausedUnsafe()
+	if cs.store.Paused == paused {
+		return nil
+	}
+	cs.store.Paused = paused
+	if !paused {
+		cs.skipOverdueUnsafe()
+	}
+	return cs.saveStoreUnsafe()
+}
+
+// syncPausedUnsafe picks up a pause or resume written to the store file by
+// another process (e.g. the CLI while the gateway is running).
+func (cs *CronService) syncPausedUnsafe() {
+	info, err := os.Stat(cs.storePath)
+	if err != nil || info.ModTime().Equal(cs.storeMod) {
+		return
+	}
+	cs.storeMod = info.ModTime()
+
+	data, err := os.ReadFile(cs.storePath)
+	if err != nil {
+		return
+	}
+	var onDisk struct {
+		Paused bool `json:"paused"`
+	}
+	if err := json.Unmarshal(data, &onDisk); err != nil || onDisk.Paused == cs.store.Paused {
+		return
+	}
+
+	cs.store.Paused = onDisk.Paused
+	if onDisk.Paused {
+		log.Printf("[cron] all jobs paused")
+	} else {
+		log.Printf("[cron] all jobs resumed")
+		cs.skipOverdueUnsafe()
+		if err := cs.saveStoreUnsafe(); err != nil {
+			log.Printf("[cron] failed to save store: %v", err)
+		}
+	}
+}
+
+// skipOverdueUnsafe reschedules enabled jobs whose next run already passed.
+func (cs *CronService) skipOverdueUnsafe() {
+	now := time.Now().UnixMilli()
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
-		if job.Enabled && job.State.NextRunAtMS != nil && *job.State.NextRunAtMS <= now {
-			dueJobIDs = append(dueJobIDs, job.ID)
+		if start := job.State.startAtMS(); job.Enabled && start != nil && *start <= now {
+			job.setNextRun(cs.computeNextRun(&job.Schedule, now))
 		}
 	}
+}
 
-	// Reset next run for due jobs before unlocking to avoid duplicate execution.
-	dueMap := make(map[string]bool, len(dueJobIDs))
-	for _, jobID := range dueJobIDs {
-		dueMap[jobID] = true
+// dueRun is a run of a job that should start, with the time it was
+// scheduled for.
+type dueRun struct {
+	jobID         string
+	scheduledAtMS int64
+}
+
+// startAtMS returns when the next run starts: RunAtMS once planned, else
+// NextRunAtMS.
+func (s *CronJobState) startAtMS() *int64 {
+	if s.RunAtMS != nil {
+		return s.RunAtMS
 	}
+	return s.NextRunAtMS
+}
+
+// setNextRun schedules the job's next run; when it actually starts is
+// planned again by planRunsUnsafe.
+func (job *CronJob) setNextRun(next *int64) {
+	job.State.NextRunAtMS = next
+	job.State.RunAtMS = nil
+}
+
+// planRunsUnsafe decides when runs that are not planned yet actually start:
+// at their scheduled time, plus a random delay of up to the job's jitter,
+// plus the stagger for each job already planned for the same scheduled
+// time. Jobs are planned in store order. It reports whether any run was
+// planned.
+func (cs *CronService) planRunsUnsafe() bool {
+	planned := make(map[int64]int) // scheduled time → runs planned for it
+	var unplanned []*CronJob
 	for i := range cs.store.Jobs {
-		if dueMap[cs.store.Jobs[i].ID] {
-			cs.store.Jobs[i].State.NextRunAtMS = nil
+		job := &cs.store.Jobs[i]
+		if !job.Enabled || job.State.NextRunAtMS == nil {
+			continue
+		}
+		if job.State.RunAtMS != nil {
+			planned[*job.State.NextRunAtMS]++
+		} else {
+			unplanned = append(unplanned, job)
 		}
 	}
 
-	if err := cs.saveStoreUnsafe(); err != nil {
-		log.Printf("[cron] failed to save store: %v", err)
+	for _, job := range unplanned {
+		at := *job.State.NextRunAtMS
+		runAt := at + int64(planned[at])*cs.stagger.Milliseconds()
+		if job.Schedule.JitterSeconds > 0 {
+			runAt += cs.rng.Int64N(int64(job.Schedule.JitterSeconds)*1000 + 1)
+		}
+		planned[at]++
+		job.State.RunAtMS = &runAt
 	}
+	return len(unplanned) > 0
+}
 
-	cs.mu.Unlock()
+// dueRunsUnsafe returns the runs of enabled jobs that start at or before
+// nowMS.
+func (cs *CronService) dueRunsUnsafe(nowMS int64) []dueRun {
+	var due []dueRun
+	for i := range cs.store.Jobs {
+		job := &cs.store.Jobs[i]
+		if start := job.State.startAtMS(); job.Enabled && start != nil && *start <= nowMS {
+			due = append(due, dueRun{jobID: job.ID, scheduledAtMS: *job.State.NextRunAtMS})
+		}
+	}
+	return due
+}
 
-	// Execute jobs outside lock.
-	for _, jobID := range dueJobIDs {
-		cs.executeJobByID(jobID)
+// claimRunsUnsafe clears the next run of the given jobs so the run loop does
+// not execute them a second time while they are running.
+func (cs *CronService) claimRunsUnsafe(runs []dueRun) {
+	if len(runs) == 0 {
+		return
+	}
+	claimed := make(map[string]bool, len(runs))
+	for _, run := range runs {
+		claimed[run.jobID] = true
+	}
+	for i := range cs.store.Jobs {
+		if claimed[cs.store.Jobs[i].ID] {
+			cs.store.Jobs[i].setNextRun(nil)
+		}
+	}
+
+	if err := cs.saveStoreUnsafe(); err != nil {
+		log.Printf("[cron] failed to save store: %v", err)
 	}
 }
 
-func (cs *CronService) executeJobByID(jobID string) {
-	startTime := time.Now().UnixMilli()
+// executeJobByID runs a job and records the run, which was due at
+// scheduledAtMS (0 when run outside its schedule).
+func (cs *CronService) executeJobByID(jobID string, scheduledAtMS int64) {
+	started := time.Now()
+	startTime := started.UnixMilli()
 
-	cs.mu.RLock()
+	// Open the run's history entry up front, so a delivery reported while
+	// the handler is still running has somewhere to go.
+	cs.mu.Lock()
+	var skipped string
+	if cs.skip != nil {
+		skipped = cs.skip()
+	}
 	var callbackJob *CronJob
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
 		if job.ID == jobID {
+			// Runs are keyed by start time, so two runs within the same
+			// millisecond must not share it.
+			if n := len(job.State.History); n > 0 && job.State.History[n-1].AtMS >= startTime {
+				startTime = job.State.History[n-1].AtMS + 1
+			}
+			job.State.History = appendRun(job.State.History, CronRun{
+				AtMS:          startTime,
+				ScheduledAtMS: scheduledAtMS,
+				Status:        "running",
+			})
 			jobCopy := *job
+			jobCopy.State.History = nil
+			// The handler sees the current run as the last one, which is
+			// how it refers to the run in RecordDelivery.
+			jobCopy.State.LastRunAtMS = &startTime
 			callbackJob = &jobCopy
 			break
 		}
 	}
-	cs.mu.RUnlock()
+	cs.mu.Unlock()
 
 	if callbackJob == nil {
 		return
 	}
 
-	var err error
-	if cs.onJob != nil {
-		_, err = cs.onJob(callbackJob)
+	var (
+		output string
+		err    error
+	)
+	if skipped != "" {
+		log.Printf("[cron] skipping job %s: %s", jobID, skipped)
+	} else if cs.onJob != nil {
+		output, err = cs.onJob(callbackJob)
 	}
+	duration := time.Since(started)
 
 	// Now acquire lock to update state
 	cs.mu.Lock()
@@ -217,25 +528,48 @@ func (cs *CronService) executeJobByID(jobID string) {
 	job.State.LastRunAtMS = &startTime
 	job.UpdatedAtMS = time.Now().UnixMilli()
 
-	if err != nil {
+	switch {
+	case skipped != "":
+		job.State.LastStatus = "skipped"
+		job.State.LastError = skipped
+	case err != nil:
 		job.State.LastStatus = "error"
 		job.State.LastError = err.Error()
-	} else {
+	default:
 		job.State.LastStatus = "ok"
 		job.State.LastError = ""
 	}
+	run := findRun(job.State.History, startTime)
+	if run == nil {
+		// The store was reloaded from disk while the job ran.
+		job.State.History = appendRun(job.State.History, CronRun{AtMS: startTime, ScheduledAtMS: scheduledAtMS})
+		run = &job.State.History[len(job.State.History)-1]
+	}
+	run.Status = job.State.LastStatus
+	run.Error = job.State.LastError
+
+	cs.notifyCompletion(job, NotificationData{
+		JobID:      job.ID,
+		JobName:    job.Name,
+		Status:     job.State.LastStatus,
+		Output:     output,
+		Error:      job.State.LastError,
+		RunAtMS:    startTime,
+		DurationMS: duration.Milliseconds(),
+	})
 
 	// Compute next run time
 	if job.Schedule.Kind == "at" {
-		if job.DeleteAfterRun {
+		// A skipped one-shot job is kept, disabled, so the skip shows in
+		// its history.
+		if job.DeleteAfterRun && skipped == "" {
 			cs.removeJobUnsafe(job.ID)
 		} else {
 			job.Enabled = false
-			job.State.NextRunAtMS = nil
+			job.setNextRun(nil)
 		}
 	} else {
-		nextRun := cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
-		job.State.NextRunAtMS = nextRun
+		job.setNextRun(cs.computeNextRun(&job.Schedule, time.Now().UnixMilli()))
 	}
 
 	if err := cs.saveStoreUnsafe(); err != nil {
@@ -243,6 +577,78 @@ func (cs *CronService) executeJobByID(jobID string) {
 	}
 }
 
+// RecordDelivery notes whether the message sent by a job's run was accepted
+// by the platform. runAtMS identifies the run (the job's LastRunAtMS as seen
+// by the handler). Unknown jobs and runs are ignored. The store is saved in
+// the background, since the report comes from a channel's send worker.
+func (cs *CronService) RecordDelivery(jobID string, runAtMS int64, delivery, deliveryErr string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	for i := range cs.store.Jobs {
+		job := &cs.store.Jobs[i]
+		if job.ID != jobID {
+			continue
+		}
+		run := findRun(job.State.History, runAtMS)
+		if run == nil {
+			return
+		}
+		run.Delivery = delivery
+		run.DeliveryError = deliveryErr
+		cs.saveLaterUnsafe()
+		return
+	}
+}
+
+// saveLaterUnsafe saves the store on another goroutine. Requests made
+// before that save starts are covered by it.
+func (cs *CronService) saveLaterUnsafe() {
+	if cs.savePending {
+		return
+	}
+	cs.savePending = true
+	go func() {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		cs.savePending = false
+		if err := cs.saveStoreUnsafe(); err != nil {
+			log.Printf("[cron] failed to save store: %v", err)
+		}
+	}()
+}
+
+// GetJob returns a copy of the job with the given ID, or nil.
+func (cs *CronService) GetJob(jobID string) *CronJob {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	for _, job := range cs.store.Jobs {
+		if job.ID == jobID {
+			job.State.History = append([]CronRun(nil), job.State.History...)
+			return &job
+		}
+	}
+	return nil
+}
+
+func appendRun(history []CronRun, run CronRun) []CronRun {
+	history = append(history, run)
+	if len(history) > maxRunHistory {
+		history = append([]CronRun(nil), history[len(history)-maxRunHistory:]...)
+	}
+	return history
+}
+
+func findRun(history []CronRun, atMS int64) *CronRun {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].AtMS == atMS {
+			return &history[i]
+		}
+	}
+	return nil
+}
+
 func (cs *CronService) computeNextRun(schedule *CronSchedule, nowMS int64) *int64 {
 	if schedule.Kind == "at" {
 		if schedule.AtMS != nil && *schedule.AtMS > nowMS {
@@ -284,7 +690,7 @@ func (cs *CronService) recomputeNextRuns() {
 	for i := range cs.store.Jobs {
 		job := &cs.store.Jobs[i]
 		if job.Enabled {
-			job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, now)
+			job.setNextRun(cs.computeNextRun(&job.Schedule, now))
 		}
 	}
 }
@@ -292,9 +698,9 @@ func (cs *CronService) recomputeNextRuns() {
 func (cs *CronService) getNextWakeMS() *int64 {
 	var nextWake *int64
 	for _, job := range cs.store.Jobs {
-		if job.Enabled && job.State.NextRunAtMS != nil {
-			if nextWake == nil || *job.State.NextRunAtMS < *nextWake {
-				nextWake = job.State.NextRunAtMS
+		if start := job.State.startAtMS(); job.Enabled && start != nil {
+			if nextWake == nil || *start < *nextWake {
+				nextWake = start
 			}
 		}
 	}
@@ -326,6 +732,9 @@ func (cs *CronService) loadStore() error {
 		}
 		return err
 	}
+	if info, err := os.Stat(cs.storePath); err == nil {
+		cs.storeMod = info.ModTime()
+	}
 
 	return json.Unmarshal(data, cs.store)
 }
@@ -337,7 +746,13 @@ func (cs *CronService) saveStoreUnsafe() error {
 	}
 
 	// Use unified atomic write utility with explicit sync for flash storage reliability.
-	return fileutil.WriteFileAtomic(cs.storePath, data, 0o600)
+	if err := fileutil.WriteFileAtomic(cs.storePath, data, 0o600); err != nil {
+		return err
+	}
+	if info, err := os.Stat(cs.storePath); err == nil {
+		cs.storeMod = info.ModTime()
+	}
+	return nil
 }
 
 func (cs *CronService) AddJob(
@@ -435,9 +850,9 @@ func (cs *CronService) EnableJob(jobID string, enabled bool) *CronJob {
 			job.UpdatedAtMS = time.Now().UnixMilli()
 
 			if enabled {
-				job.State.NextRunAtMS = cs.computeNextRun(&job.Schedule, time.Now().UnixMilli())
+				job.setNextRun(cs.computeNextRun(&job.Schedule, time.Now().UnixMilli()))
 			} else {
-				job.State.NextRunAtMS = nil
+				job.setNextRun(nil)
 			}
 
 			if err := cs.saveStoreUnsafe(); err != nil {
@@ -481,6 +896,7 @@ func (cs *CronService) Status() map[string]any {
 
 	return map[string]any{
 		"enabled":      cs.running,
+		"paused":       cs.store.Paused,
 		"jobs":         len(cs.store.Jobs),
 		"nextWakeAtMS": cs.getNextWakeMS(),
 	}
@@ -489,7 +905,7 @@ func (cs *CronService) Status() map[string]any {
 func generateID() string {
 	// Use crypto/rand for better uniqueness under concurrent access
 	b := make([]byte, 8)
-	if _, err := rand.Read(b); err != nil {
+	if _, err := crand.Read(b); err != nil {
 		// Fallback to time-based if crypto/rand fails
 		return fmt.Sprintf("%d", time.Now().UnixNano())
 	}
@@ -1,10 +1,14 @@
 package cron
 
 import (
+	"context"
+	"fmt"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 )
 
 func TestSaveStore_FilePermissions(t *testing.T) {
@@ -33,6 +37,317 @@ func TestSaveStore_FilePermissions(t *testing.T) {
 	}
 }
 
+func TestRunAllDue_CatchesUpMissedJobs(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+
+	// Simulate a job whose next run passed while the gateway was down.
+	cs := NewCronService(storePath, nil)
+	job, err := cs.AddJob("missed", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	if _, err := cs.AddJob("future", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "later", false, "cli", "direct"); err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	cs.mu.Lock()
+	for i := range cs.store.Jobs {
+		if cs.store.Jobs[i].ID == job.ID {
+			cs.store.Jobs[i].State.NextRunAtMS = int64Ptr(time.Now().Add(-time.Hour).UnixMilli())
+		}
+	}
+	if err := cs.saveStoreUnsafe(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	cs.mu.Unlock()
+
+	var ran []string
+	restarted := NewCronService(storePath, func(j *CronJob) (string, error) {
+		ran = append(ran, j.Name)
+		return "", nil
+	})
+	if err := restarted.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer restarted.Stop()
+
+	if n := restarted.RunAllDue(context.Background()); n != 1 {
+		t.Fatalf("RunAllDue ran %d jobs, want 1", n)
+	}
+	if len(ran) != 1 || ran[0] != "missed" {
+		t.Errorf("ran = %v, want [missed]", ran)
+	}
+
+	// Catch-up happens only once.
+	if n := restarted.RunAllDue(context.Background()); n != 0 {
+		t.Errorf("second RunAllDue ran %d jobs, want 0", n)
+	}
+}
+
+func TestPauseAll_PersistsAndBlocksExecution(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+
+	ran := 0
+	cs := NewCronService(storePath, func(*CronJob) (string, error) {
+		ran++
+		return "", nil
+	})
+	job, err := cs.AddJob("due", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	cs.running = true
+
+	if err := cs.PauseAll(); err != nil {
+		t.Fatalf("PauseAll failed: %v", err)
+	}
+
+	cs.mu.Lock()
+	cs.store.Jobs[0].State.NextRunAtMS = int64Ptr(time.Now().Add(-time.Minute).UnixMilli())
+	cs.mu.Unlock()
+
+	cs.checkJobs()
+	if ran != 0 {
+		t.Fatalf("job ran while paused")
+	}
+
+	// The paused state survives a reload and leaves Enabled untouched.
+	reloaded := NewCronService(storePath, nil)
+	if !reloaded.IsPaused() {
+		t.Errorf("paused state not persisted")
+	}
+	if jobs := reloaded.ListJobs(false); len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("paused job should still be enabled, got %+v", jobs)
+	}
+
+	// Resuming skips the run that fell due while paused.
+	if err := cs.ResumeAll(); err != nil {
+		t.Fatalf("ResumeAll failed: %v", err)
+	}
+	cs.checkJobs()
+	if ran != 0 {
+		t.Errorf("overdue run fired after resume, want it rescheduled")
+	}
+	if next := cs.store.Jobs[0].State.NextRunAtMS; next == nil || *next <= time.Now().UnixMilli() {
+		t.Errorf("next run not rescheduled into the future: %v", next)
+	}
+}
+
+func TestCheckJobs_PicksUpExternalPause(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+
+	ran := 0
+	gateway := NewCronService(storePath, func(*CronJob) (string, error) {
+		ran++
+		return "", nil
+	})
+	if _, err := gateway.AddJob("due", CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)}, "hi", false, "cli", "direct"); err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	gateway.running = true
+
+	// Ensure the CLI write gets a distinct mtime on coarse filesystems.
+	time.Sleep(10 * time.Millisecond)
+	if err := NewCronService(storePath, nil).PauseAll(); err != nil {
+		t.Fatalf("PauseAll failed: %v", err)
+	}
+
+	gateway.mu.Lock()
+	gateway.store.Jobs[0].State.NextRunAtMS = int64Ptr(time.Now().Add(-time.Minute).UnixMilli())
+	gateway.mu.Unlock()
+
+	gateway.checkJobs()
+	if ran != 0 {
+		t.Errorf("job ran after the store was paused by another process")
+	}
+	if !gateway.IsPaused() {
+		t.Errorf("gateway did not pick up the external pause")
+	}
+}
+
+func TestExecuteJob_RecordsHistoryAndDelivery(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+
+	var cs *CronService
+	cs = NewCronService(storePath, func(j *CronJob) (string, error) {
+		// Delivery may be reported before the handler returns.
+		cs.RecordDelivery(j.ID, *j.State.LastRunAtMS, "failed", "chat not found")
+		return "ok", nil
+	})
+	job, err := cs.AddJob("report", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "hi", true, "telegram", "1")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	cs.executeJobByID(job.ID, 0)
+
+	// Reload from disk, as `picoclaw cron history` does.
+	got := NewCronService(storePath, nil).GetJob(job.ID)
+	if got == nil {
+		t.Fatal("job not found after reload")
+	}
+	if len(got.State.History) != 1 {
+		t.Fatalf("history has %d runs, want 1", len(got.State.History))
+	}
+	run := got.State.History[0]
+	if run.Status != "ok" || run.Delivery != "failed" || run.DeliveryError != "chat not found" {
+		t.Errorf("run = %+v, want ok with failed delivery", run)
+	}
+}
+
+func TestExecuteJob_SkippedInMaintenance(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	ran := false
+	cs := NewCronService(storePath, func(*CronJob) (string, error) {
+		ran = true
+		return "", nil
+	})
+	reason := "maintenance"
+	cs.SetSkip(func() string { return reason })
+	job, err := cs.AddJob("report", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	cs.executeJobByID(job.ID, 0)
+	if ran {
+		t.Fatal("job ran while skipped")
+	}
+	got := cs.GetJob(job.ID)
+	run := got.State.History[len(got.State.History)-1]
+	if run.Status != "skipped" || run.Error != "maintenance" {
+		t.Errorf("run = %+v, want skipped for maintenance", run)
+	}
+	if got.State.NextRunAtMS == nil {
+		t.Error("skipped job was not rescheduled")
+	}
+
+	reason = ""
+	cs.executeJobByID(job.ID, 0)
+	if !ran {
+		t.Error("job did not run once the skip was lifted")
+	}
+}
+
+func TestExecuteJob_HistoryIsBounded(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	cs := NewCronService(storePath, func(*CronJob) (string, error) { return "", nil })
+	job, err := cs.AddJob("often", CronSchedule{Kind: "every", EveryMS: int64Ptr(60000)}, "hi", false, "cli", "direct")
+	if err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+
+	for range maxRunHistory + 5 {
+		cs.executeJobByID(job.ID, 0)
+	}
+
+	if n := len(cs.GetJob(job.ID).State.History); n != maxRunHistory {
+		t.Errorf("history has %d runs, want %d", n, maxRunHistory)
+	}
+}
+
+// newSameTimeJobs returns a service with n jobs all due at the same minute,
+// each with the given jitter, and their scheduled time.
+func newSameTimeJobs(t *testing.T, n, jitterSeconds int, seed uint64) (*CronService, int64) {
+	t.Helper()
+	cs := NewCronService(filepath.Join(t.TempDir(), "cron", "jobs.json"), nil)
+	cs.SetRand(rand.New(rand.NewPCG(seed, seed)))
+	for i := range n {
+		schedule := CronSchedule{Kind: "cron", Expr: "0 9 * * *", JitterSeconds: jitterSeconds}
+		if _, err := cs.AddJob(fmt.Sprintf("job%d", i), schedule, "hi", false, "cli", "direct"); err != nil {
+			t.Fatalf("AddJob failed: %v", err)
+		}
+	}
+	return cs, *cs.store.Jobs[0].State.NextRunAtMS
+}
+
+func plannedDelays(cs *CronService, scheduled int64) []int64 {
+	cs.planRunsUnsafe()
+	var delays []int64
+	for _, job := range cs.store.Jobs {
+		delays = append(delays, *job.State.RunAtMS-scheduled)
+	}
+	return delays
+}
+
+func TestPlanRuns_StaggersSameTimeJobs(t *testing.T) {
+	cs, scheduled := newSameTimeJobs(t, 3, 0, 1)
+	cs.SetStagger(30 * time.Second)
+
+	got := plannedDelays(cs, scheduled)
+	want := []int64{0, 30000, 60000}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("delays = %v, want %v", got, want)
+	}
+	for _, job := range cs.store.Jobs {
+		if *job.State.NextRunAtMS != scheduled {
+			t.Errorf("%s: NextRunAtMS moved to %d, want the scheduled %d", job.Name, *job.State.NextRunAtMS, scheduled)
+		}
+	}
+
+	// A job scheduled later for the same time goes after the planned ones.
+	if _, err := cs.AddJob("late", CronSchedule{Kind: "cron", Expr: "0 9 * * *"}, "hi", false, "cli", "direct"); err != nil {
+		t.Fatalf("AddJob failed: %v", err)
+	}
+	if got := plannedDelays(cs, scheduled); got[3] != 90000 {
+		t.Errorf("late job delay = %d, want 90000", got[3])
+	}
+}
+
+func TestPlanRuns_JitterIsSeeded(t *testing.T) {
+	a, scheduled := newSameTimeJobs(t, 8, 120, 42)
+	b, _ := newSameTimeJobs(t, 8, 120, 42)
+
+	got := plannedDelays(a, scheduled)
+	if again := plannedDelays(b, scheduled); fmt.Sprint(again) != fmt.Sprint(got) {
+		t.Errorf("same seed gave delays %v and %v", got, again)
+	}
+	distinct := make(map[int64]bool)
+	for _, d := range got {
+		if d < 0 || d > 120000 {
+			t.Errorf("delay %d outside [0, 120s]", d)
+		}
+		distinct[d] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("delays = %v, want them spread", got)
+	}
+}
+
+func TestCheckJobs_RecordsScheduledTime(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "cron", "jobs.json")
+	cs := NewCronService(storePath, func(*CronJob) (string, error) { return "", nil })
+	cs.SetStagger(time.Hour)
+	if err := cs.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer cs.Stop()
+
+	scheduled := time.Now().Add(-time.Minute).UnixMilli()
+	cs.mu.Lock()
+	for i := range 2 {
+		cs.store.Jobs = append(cs.store.Jobs, CronJob{
+			ID:       fmt.Sprintf("job%d", i),
+			Enabled:  true,
+			Schedule: CronSchedule{Kind: "every", EveryMS: int64Ptr(3600000)},
+			State:    CronJobState{NextRunAtMS: int64Ptr(scheduled)},
+		})
+	}
+	cs.mu.Unlock()
+
+	// The second job waits an hour for its stagger.
+	cs.checkJobs()
+	first, second := cs.GetJob("job0"), cs.GetJob("job1")
+	if len(first.State.History) != 1 || len(second.State.History) != 0 {
+		t.Fatalf("runs = %d and %d, want 1 and 0", len(first.State.History), len(second.State.History))
+	}
+	if run := first.State.History[0]; run.ScheduledAtMS != scheduled || run.AtMS < scheduled {
+		t.Errorf("run = %+v, want scheduled at %d", run, scheduled)
+	}
+	if *second.State.RunAtMS != scheduled+time.Hour.Milliseconds() {
+		t.Errorf("second job starts at %d, want an hour after %d", *second.State.RunAtMS, scheduled)
+	}
+}
+
 func int64Ptr(v int64) *int64 {
 	return &v
 }
@@ -0,0 +1,128 @@
+// Package digest batches non-urgent agent output (cron results, heartbeat
+// findings) and delivers it as one combined message at scheduled times.
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// Item is one piece of output waiting for the next digest.
+type Item struct {
+	Source    string    `json:"source"` // e.g. "cron: backup check" or "heartbeat"
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type bufferState struct {
+	Items          []Item `json:"items"`
+	LastSlot       string `json:"last_slot,omitempty"`
+	FlushRequested bool   `json:"flush_requested,omitempty"`
+}
+
+// Buffer is a file-backed queue of digest items. Every operation reads and
+// rewrites the file, so the gateway and CLI commands share the same state.
+type Buffer struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewBuffer returns the digest buffer stored in the workspace.
+func NewBuffer(workspace string) *Buffer {
+	return &Buffer{path: filepath.Join(workspace, "digest", "buffer.json")}
+}
+
+// Add appends an item to the buffer.
+func (b *Buffer) Add(item Item) error {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	return b.update(func(s *bufferState) {
+		s.Items = append(s.Items, item)
+	})
+}
+
+// Items returns a copy of the buffered items.
+func (b *Buffer) Items() ([]Item, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+	return s.Items, nil
+}
+
+// Remove drops the n oldest items, typically after they were delivered.
+func (b *Buffer) Remove(n int) error {
+	return b.update(func(s *bufferState) {
+		if n > len(s.Items) {
+			n = len(s.Items)
+		}
+		s.Items = s.Items[n:]
+	})
+}
+
+// RequestFlush asks a running gateway to deliver the digest on its next tick.
+func (b *Buffer) RequestFlush() error {
+	return b.update(func(s *bufferState) {
+		s.FlushRequested = true
+	})
+}
+
+// schedule returns the last delivered slot and whether a flush was requested.
+func (b *Buffer) schedule() (string, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, err := b.load()
+	if err != nil {
+		return "", false, err
+	}
+	return s.LastSlot, s.FlushRequested, nil
+}
+
+func (b *Buffer) update(fn func(*bufferState)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, err := b.load()
+	if err != nil {
+		return err
+	}
+	fn(s)
+	return b.save(s)
+}
+
+func (b *Buffer) load() (*bufferState, error) {
+	s := &bufferState{}
+	data, err := os.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read digest buffer: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse digest buffer: %w", err)
+	}
+	return s, nil
+}
+
+func (b *Buffer) save(s *bufferState) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(b.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write digest buffer: %w", err)
+	}
+	return nil
+}
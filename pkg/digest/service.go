@@ -0,0 +1,294 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/i18n"
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const (
+	tickInterval = 30 * time.Second
+	slotLayout   = "2006-01-02 15:04"
+
+	summarizePrompt = "You combine background updates from scheduled tasks into one short digest for the user. " +
+		"Group related items, keep concrete facts (numbers, names, errors), drop noise and duplicates, " +
+		"and write plain text suitable for a chat message. Do not invent information."
+)
+
+// ErrNoTarget is returned when a digest is due but no delivery chat is known.
+var ErrNoTarget = errors.New("no digest delivery target configured")
+
+// Summarizer condenses buffered items into one message.
+type Summarizer func(ctx context.Context, items []Item) (string, error)
+
+// Service delivers the buffered digest at the configured times of day.
+type Service struct {
+	buffer    *Buffer
+	bus       *bus.MessageBus
+	times     []string
+	target    func() (channel, chatID string)
+	summarize Summarizer
+	language  func(channel string) string
+	nowFunc   func() time.Time
+
+	// lastErr is the error of the last due delivery, so a delivery that
+	// keeps failing the same way is logged once. Used only by tick.
+	lastErr string
+
+	mu       sync.Mutex
+	stopChan chan struct{}
+}
+
+// NewService creates a digest service. times are local "HH:MM" delivery times.
+func NewService(buffer *Buffer, msgBus *bus.MessageBus, times []string) *Service {
+	return &Service{
+		buffer:  buffer,
+		bus:     msgBus,
+		times:   times,
+		nowFunc: time.Now,
+	}
+}
+
+// SetSummarizer sets the function used to condense items. Without one the
+// digest is a plain list of items.
+func (s *Service) SetSummarizer(fn Summarizer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summarize = fn
+}
+
+// SetTarget sets the function resolving the chat the digest is sent to.
+func (s *Service) SetTarget(fn func() (channel, chatID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.target = fn
+}
+
+// SetLanguage sets the function resolving the locale of the digest header
+// for the channel it is delivered to.
+func (s *Service) SetLanguage(fn func(channel string) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.language = fn
+}
+
+// Start begins checking for due deliveries in the background.
+func (s *Service) Start() error {
+	for _, t := range s.times {
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("invalid digest time %q: expected HH:MM", t)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopChan != nil {
+		return nil
+	}
+
+	// On first start, don't treat the most recent slot as missed.
+	lastSlot, _, err := s.buffer.schedule()
+	if err != nil {
+		return err
+	}
+	if lastSlot == "" {
+		if slot := latestSlot(s.times, s.nowFunc()); slot != "" {
+			if err := s.buffer.update(func(st *bufferState) { st.LastSlot = slot }); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.stopChan = make(chan struct{})
+	go s.runLoop(s.stopChan)
+	return nil
+}
+
+// Stop halts the background loop.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopChan != nil {
+		close(s.stopChan)
+		s.stopChan = nil
+	}
+}
+
+func (s *Service) runLoop(stopChan chan struct{}) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+			s.tick(context.Background())
+		}
+	}
+}
+
+// tick delivers the digest when a scheduled slot has passed since the last
+// delivery or a flush was requested from the CLI.
+func (s *Service) tick(ctx context.Context) {
+	lastSlot, flushRequested, err := s.buffer.schedule()
+	if err != nil {
+		logger.WarnCF("digest", "Failed to read digest buffer", map[string]any{"error": err.Error()})
+		return
+	}
+
+	slot := latestSlot(s.times, s.nowFunc())
+	slotDue := slot != "" && slot != lastSlot
+	if !slotDue && !flushRequested {
+		return
+	}
+
+	n, err := s.Flush(ctx)
+	switch {
+	case err != nil:
+		if err.Error() != s.lastErr {
+			logger.WarnCF("digest", "Digest delivery failed", map[string]any{"error": err.Error()})
+			s.lastErr = err.Error()
+		}
+	case s.lastErr != "":
+		logger.InfoCF("digest", "Digest delivery recovered", map[string]any{"items": n})
+		s.lastErr = ""
+	case n > 0:
+		logger.InfoCF("digest", "Digest delivered", map[string]any{"items": n})
+	}
+
+	if slotDue {
+		_ = s.buffer.update(func(st *bufferState) { st.LastSlot = slot })
+	}
+}
+
+// Flush delivers all buffered items now as one message and returns how many
+// were sent. Items stay buffered if delivery fails.
+func (s *Service) Flush(ctx context.Context) (int, error) {
+	items, err := s.buffer.Items()
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, s.buffer.update(func(st *bufferState) { st.FlushRequested = false })
+	}
+
+	s.mu.Lock()
+	target := s.target
+	language := s.language
+	s.mu.Unlock()
+
+	var channel, chatID string
+	if target != nil {
+		channel, chatID = target()
+	}
+	if channel == "" || chatID == "" {
+		return 0, ErrNoTarget
+	}
+
+	var lang string
+	if language != nil {
+		lang = language(channel)
+	}
+	content := s.Compose(ctx, items, lang)
+
+	pubCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := s.bus.PublishOutbound(pubCtx, bus.OutboundMessage{
+		Channel: channel,
+		ChatID:  chatID,
+		Content: content,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to publish digest: %w", err)
+	}
+
+	if err := s.buffer.update(func(st *bufferState) {
+		st.Items = st.Items[min(len(items), len(st.Items)):]
+		st.FlushRequested = false
+	}); err != nil {
+		return len(items), err
+	}
+	return len(items), nil
+}
+
+// Compose builds the digest message, summarizing with the model when a
+// summarizer is set and falling back to a plain list otherwise. lang is the
+// locale of the header.
+func (s *Service) Compose(ctx context.Context, items []Item, lang string) string {
+	s.mu.Lock()
+	summarize := s.summarize
+	s.mu.Unlock()
+
+	if summarize != nil {
+		summary, err := summarize(ctx, items)
+		if err == nil && strings.TrimSpace(summary) != "" {
+			return i18n.T(lang, i18n.DigestHeader, len(items)) + "\n\n" + strings.TrimSpace(summary)
+		}
+		if err != nil {
+			logger.WarnCF("digest", "Digest summarization failed, sending plain list",
+				map[string]any{"error": err.Error()})
+		}
+	}
+	return i18n.T(lang, i18n.DigestHeader, len(items)) + "\n\n" + FormatItems(items)
+}
+
+// FormatItems renders items as a plain bullet list.
+func FormatItems(items []Item) string {
+	var sb strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "• [%s %s] %s", item.CreatedAt.Format("15:04"), item.Source, strings.TrimSpace(item.Content))
+	}
+	return sb.String()
+}
+
+// NewLLMSummarizer returns a Summarizer that asks the model to condense items.
+func NewLLMSummarizer(provider providers.LLMProvider, model string) Summarizer {
+	return func(ctx context.Context, items []Item) (string, error) {
+		messages := []providers.Message{
+			{Role: "system", Content: summarizePrompt},
+			{Role: "user", Content: FormatItems(items)},
+		}
+		resp, err := provider.Chat(ctx, messages, nil, model, map[string]any{
+			"max_tokens":  1024,
+			"temperature": 0.3,
+		})
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+	}
+}
+
+// latestSlot returns the most recent scheduled time at or before now, as a
+// "YYYY-MM-DD HH:MM" key, or "" if no times are configured.
+func latestSlot(times []string, now time.Time) string {
+	var latest time.Time
+	for _, t := range times {
+		hm, err := time.Parse("15:04", t)
+		if err != nil {
+			continue
+		}
+		slot := time.Date(now.Year(), now.Month(), now.Day(), hm.Hour(), hm.Minute(), 0, 0, now.Location())
+		if slot.After(now) {
+			slot = slot.AddDate(0, 0, -1)
+		}
+		if slot.After(latest) {
+			latest = slot
+		}
+	}
+	if latest.IsZero() {
+		return ""
+	}
+	return latest.Format(slotLayout)
+}
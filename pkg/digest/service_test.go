@@ -0,0 +1,133 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+func TestLatestSlot(t *testing.T) {
+	times := []string{"08:00", "18:00"}
+	loc := time.Local
+
+	tests := []struct {
+		now  time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 10, 9, 30, 0, 0, loc), "2026-03-10 08:00"},
+		{time.Date(2026, 3, 10, 18, 0, 0, 0, loc), "2026-03-10 18:00"},
+		{time.Date(2026, 3, 10, 7, 0, 0, 0, loc), "2026-03-09 18:00"},
+	}
+	for _, tt := range tests {
+		if got := latestSlot(times, tt.now); got != tt.want {
+			t.Errorf("latestSlot(%v) = %q, want %q", tt.now, got, tt.want)
+		}
+	}
+
+	if got := latestSlot(nil, time.Now()); got != "" {
+		t.Errorf("latestSlot(nil) = %q, want empty", got)
+	}
+}
+
+func TestBufferPersistsAcrossInstances(t *testing.T) {
+	workspace := t.TempDir()
+
+	if err := NewBuffer(workspace).Add(Item{Source: "heartbeat", Content: "disk almost full"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	items, err := NewBuffer(workspace).Items()
+	if err != nil {
+		t.Fatalf("Items: %v", err)
+	}
+	if len(items) != 1 || items[0].Content != "disk almost full" {
+		t.Fatalf("items = %+v, want the buffered item", items)
+	}
+}
+
+func TestTickDeliversWhenSlotPassed(t *testing.T) {
+	buffer := NewBuffer(t.TempDir())
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	now := time.Date(2026, 3, 10, 7, 59, 0, 0, time.Local)
+	svc := NewService(buffer, msgBus, []string{"08:00"})
+	svc.nowFunc = func() time.Time { return now }
+	svc.SetTarget(func() (string, string) { return "telegram", "42" })
+	svc.SetSummarizer(func(_ context.Context, items []Item) (string, error) {
+		return "summary of " + items[0].Content, nil
+	})
+
+	if err := svc.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	svc.Stop()
+
+	_ = buffer.Add(Item{Source: "cron: backup", Content: "backup ok"})
+
+	// Before the slot nothing is delivered.
+	svc.tick(context.Background())
+	if items, _ := buffer.Items(); len(items) != 1 {
+		t.Fatalf("item delivered before the scheduled time")
+	}
+
+	now = now.Add(2 * time.Minute)
+	svc.tick(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, ok := msgBus.SubscribeOutbound(ctx)
+	if !ok {
+		t.Fatal("expected digest to be published")
+	}
+	if msg.Channel != "telegram" || msg.ChatID != "42" {
+		t.Errorf("digest sent to %s:%s, want telegram:42", msg.Channel, msg.ChatID)
+	}
+	if !strings.Contains(msg.Content, "summary of backup ok") {
+		t.Errorf("content = %q, want summarized digest", msg.Content)
+	}
+	if items, _ := buffer.Items(); len(items) != 0 {
+		t.Errorf("buffer not cleared after delivery: %+v", items)
+	}
+}
+
+func TestFlushKeepsItemsWithoutTarget(t *testing.T) {
+	buffer := NewBuffer(t.TempDir())
+	msgBus := bus.NewMessageBus()
+	defer msgBus.Close()
+
+	svc := NewService(buffer, msgBus, nil)
+	_ = buffer.Add(Item{Source: "heartbeat", Content: "note"})
+
+	if _, err := svc.Flush(context.Background()); !errors.Is(err, ErrNoTarget) {
+		t.Fatalf("Flush error = %v, want ErrNoTarget", err)
+	}
+	if items, _ := buffer.Items(); len(items) != 1 {
+		t.Errorf("items dropped on failed delivery")
+	}
+}
+
+func TestComposeFallsBackToListOnSummarizerError(t *testing.T) {
+	svc := NewService(NewBuffer(t.TempDir()), nil, nil)
+	svc.SetSummarizer(func(context.Context, []Item) (string, error) {
+		return "", errors.New("provider down")
+	})
+
+	got := svc.Compose(context.Background(), []Item{{Source: "heartbeat", Content: "a"}, {Source: "heartbeat", Content: "b"}}, "")
+	if !strings.Contains(got, "2 updates") || !strings.Contains(got, "] a") || !strings.Contains(got, "] b") {
+		t.Errorf("Compose = %q, want plain list of both items", got)
+	}
+}
+
+func TestComposeLocalizesHeader(t *testing.T) {
+	svc := NewService(NewBuffer(t.TempDir()), nil, nil)
+
+	got := svc.Compose(context.Background(), []Item{{Source: "cron", Content: "a"}}, "ja")
+	if !strings.HasPrefix(got, "📰 ダイジェスト（1 件の更新）\n\n") {
+		t.Errorf("Compose = %q, want the Japanese header", got)
+	}
+}
@@ -15,6 +15,7 @@ type Server struct {
 	mu        sync.RWMutex
 	ready     bool
 	checks    map[string]Check
+	stats     map[string]func() any
 	startTime time.Time
 }
 
@@ -29,6 +30,7 @@ type StatusResponse struct {
 	Status string           `json:"status"`
 	Uptime string           `json:"uptime"`
 	Checks map[string]Check `json:"checks,omitempty"`
+	Stats  map[string]any   `json:"stats,omitempty"`
 }
 
 func NewServer(host string, port int) *Server {
@@ -36,6 +38,7 @@ func NewServer(host string, port int) *Server {
 	s := &Server{
 		ready:     false,
 		checks:    make(map[string]Check),
+		stats:     make(map[string]func() any),
 		startTime: time.Now(),
 	}
 
@@ -104,6 +107,14 @@ func (s *Server) RegisterCheck(name string, checkFn func() (bool, string)) {
 	}
 }
 
+// RegisterStat adds a live value to the /health response. statFn is called
+// on every request, so it must be cheap and safe for concurrent use.
+func (s *Server) RegisterStat(name string, statFn func() any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats[name] = statFn
+}
+
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -114,6 +125,15 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 		Uptime: uptime.String(),
 	}
 
+	s.mu.RLock()
+	if len(s.stats) > 0 {
+		resp.Stats = make(map[string]any, len(s.stats))
+		for name, statFn := range s.stats {
+			resp.Stats[name] = statFn()
+		}
+	}
+	s.mu.RUnlock()
+
 	json.NewEncoder(w).Encode(resp)
 }
 
@@ -39,10 +39,18 @@ type HeartbeatService struct {
 	bus       *bus.MessageBus
 	state     *state.Manager
 	handler   HeartbeatHandler
+	skip      func() string // reason to skip a heartbeat, "" to run it
 	interval  time.Duration
 	enabled   bool
 	mu        sync.RWMutex
 	stopChan  chan struct{}
+
+	lastRunAt         time.Time
+	lastOutcome       string
+	nextRunAt         time.Time
+	consecutiveErrors int
+	totalRuns         int // since startup, not counting skipped heartbeats
+	errorCount        int // since startup
 }
 
 // NewHeartbeatService creates a new heartbeat service
@@ -78,6 +86,14 @@ func (hs *HeartbeatService) SetHandler(handler HeartbeatHandler) {
 	hs.handler = handler
 }
 
+// SetSkip makes heartbeats be skipped while skip returns a reason, such as
+// "maintenance". Skipped heartbeats are recorded as "skipped: <reason>".
+func (hs *HeartbeatService) SetSkip(skip func() string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.skip = skip
+}
+
 // Start begins the heartbeat service
 func (hs *HeartbeatService) Start() error {
 	hs.mu.Lock()
@@ -115,6 +131,8 @@ func (hs *HeartbeatService) Stop() {
 	logger.InfoC("heartbeat", "Stopping heartbeat service")
 	close(hs.stopChan)
 	hs.stopChan = nil
+	hs.nextRunAt = time.Time{}
+	hs.saveStatus(hs.statusLocked())
 }
 
 // IsRunning returns whether the service is running
@@ -124,13 +142,42 @@ func (hs *HeartbeatService) IsRunning() bool {
 	return hs.stopChan != nil
 }
 
+// LastRun returns when the last heartbeat ran and a short description of its
+// outcome. The time is zero if no heartbeat has run since startup.
+func (hs *HeartbeatService) LastRun() (time.Time, string) {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	return hs.lastRunAt, hs.lastOutcome
+}
+
+func (hs *HeartbeatService) recordRun(outcome string) {
+	hs.mu.Lock()
+	hs.lastRunAt = time.Now()
+	hs.lastOutcome = outcome
+	if !strings.HasPrefix(outcome, "skipped") {
+		hs.totalRuns++
+	}
+	if isErrorOutcome(outcome) {
+		hs.consecutiveErrors++
+		hs.errorCount++
+	} else {
+		hs.consecutiveErrors = 0
+	}
+	st := hs.statusLocked()
+	hs.mu.Unlock()
+	hs.saveStatus(st)
+}
+
 // runLoop runs the heartbeat ticker
 func (hs *HeartbeatService) runLoop(stopChan chan struct{}) {
+	started := time.Now()
 	ticker := time.NewTicker(hs.interval)
 	defer ticker.Stop()
 
 	// Run first heartbeat after initial delay
+	hs.setNextRun(started.Add(time.Second))
 	time.AfterFunc(time.Second, func() {
+		hs.setNextRun(started.Add(hs.interval))
 		hs.executeHeartbeat()
 	})
 
@@ -138,7 +185,8 @@ func (hs *HeartbeatService) runLoop(stopChan chan struct{}) {
 		select {
 		case <-stopChan:
 			return
-		case <-ticker.C:
+		case now := <-ticker.C:
+			hs.setNextRun(now.Add(hs.interval))
 			hs.executeHeartbeat()
 		}
 	}
@@ -149,6 +197,7 @@ func (hs *HeartbeatService) executeHeartbeat() {
 	hs.mu.RLock()
 	enabled := hs.enabled
 	handler := hs.handler
+	skip := hs.skip
 	if !hs.enabled || hs.stopChan == nil {
 		hs.mu.RUnlock()
 		return
@@ -159,16 +208,26 @@ func (hs *HeartbeatService) executeHeartbeat() {
 		return
 	}
 
+	if skip != nil {
+		if reason := skip(); reason != "" {
+			hs.logInfof("Heartbeat skipped: %s", reason)
+			hs.recordRun("skipped: " + reason)
+			return
+		}
+	}
+
 	logger.DebugC("heartbeat", "Executing heartbeat")
 
 	prompt := hs.buildPrompt()
 	if prompt == "" {
 		logger.InfoC("heartbeat", "No heartbeat prompt (HEARTBEAT.md empty or missing)")
+		hs.recordRun("skipped: HEARTBEAT.md empty or missing")
 		return
 	}
 
 	if handler == nil {
 		hs.logErrorf("Heartbeat handler not configured")
+		hs.recordRun("error: handler not configured")
 		return
 	}
 
@@ -183,16 +242,19 @@ func (hs *HeartbeatService) executeHeartbeat() {
 
 	if result == nil {
 		hs.logInfof("Heartbeat handler returned nil result")
+		hs.recordRun("ok: no result")
 		return
 	}
 
 	// Handle different result types
 	if result.IsError {
 		hs.logErrorf("Heartbeat error: %s", result.ForLLM)
+		hs.recordRun("error: " + result.ForLLM)
 		return
 	}
 
 	if result.Async {
+		hs.recordRun("ok: async task started")
 		hs.logInfof("Async task started: %s", result.ForLLM)
 		logger.InfoCF("heartbeat", "Async heartbeat task started",
 			map[string]any{
@@ -204,9 +266,12 @@ func (hs *HeartbeatService) executeHeartbeat() {
 	// Check if silent
 	if result.Silent {
 		hs.logInfof("Heartbeat OK - silent")
+		hs.recordRun("ok")
 		return
 	}
 
+	hs.recordRun("ok: result sent")
+
 	// Send result to user
 	if result.ForUser != "" {
 		hs.sendResponse(result.ForUser)
@@ -217,11 +282,24 @@ func (hs *HeartbeatService) executeHeartbeat() {
 	hs.logInfof("Heartbeat completed: %s", result.ForLLM)
 }
 
+// DryRun returns the prompt the next heartbeat would send, without sending
+// it, or "" when HEARTBEAT.md is empty. A missing HEARTBEAT.md is shown as
+// the default template a real run would create, but is not written.
+func (hs *HeartbeatService) DryRun() string {
+	data, err := os.ReadFile(TasksPath(hs.workspace))
+	if os.IsNotExist(err) {
+		return renderPrompt(DefaultTasks)
+	}
+	if err != nil {
+		hs.logErrorf("Error reading HEARTBEAT.md: %v", err)
+		return ""
+	}
+	return renderPrompt(string(data))
+}
+
 // buildPrompt builds the heartbeat prompt from HEARTBEAT.md
 func (hs *HeartbeatService) buildPrompt() string {
-	heartbeatPath := filepath.Join(hs.workspace, "HEARTBEAT.md")
-
-	data, err := os.ReadFile(heartbeatPath)
+	data, err := os.ReadFile(TasksPath(hs.workspace))
 	if err != nil {
 		if os.IsNotExist(err) {
 			hs.createDefaultHeartbeatTemplate()
@@ -230,8 +308,12 @@ func (hs *HeartbeatService) buildPrompt() string {
 		hs.logErrorf("Error reading HEARTBEAT.md: %v", err)
 		return ""
 	}
+	return renderPrompt(string(data))
+}
 
-	content := string(data)
+// renderPrompt wraps the tasks of HEARTBEAT.md in the heartbeat prompt, or
+// returns "" when there are none.
+func renderPrompt(content string) string {
 	if len(content) == 0 {
 		return ""
 	}
@@ -251,33 +333,7 @@ If there is nothing that requires attention, respond ONLY with: HEARTBEAT_OK
 
 // createDefaultHeartbeatTemplate creates the default HEARTBEAT.md file
 func (hs *HeartbeatService) createDefaultHeartbeatTemplate() {
-	heartbeatPath := filepath.Join(hs.workspace, "HEARTBEAT.md")
-
-	defaultContent := `# Heartbeat Check List
-
-This file contains tasks for the heartbeat service to check periodically.
-
-## Examples
-
-- Check for unread messages
-- Review upcoming calendar events
-- Check device status (e.g., MaixCam)
-
-## Instructions
-
-- Execute ALL tasks listed below. Do NOT skip any task.
-- For simple tasks (e.g., report current time), respond directly.
-- For complex tasks that may take time, use the spawn tool to create a subagent.
-- The spawn tool is async - subagent results will be sent to the user automatically.
-- After spawning a subagent, CONTINUE to process remaining tasks.
-- Only respond with HEARTBEAT_OK when ALL tasks are done AND nothing needs attention.
-
----
-
-Add your heartbeat tasks below this line:
-`
-
-	if err := fileutil.WriteFileAtomic(heartbeatPath, []byte(defaultContent), 0o644); err != nil {
+	if err := fileutil.WriteFileAtomic(TasksPath(hs.workspace), []byte(DefaultTasks), 0o644); err != nil {
 		hs.logErrorf("Failed to create default HEARTBEAT.md: %v", err)
 	} else {
 		hs.logInfof("Created default HEARTBEAT.md template")
@@ -315,9 +371,20 @@ func (hs *HeartbeatService) sendResponse(response string) {
 		Channel: platform,
 		ChatID:  userID,
 		Content: response,
+		OnDelivery: func(status bus.DeliveryStatus) {
+			if status.State == bus.DeliveryFailed {
+				hs.logErrorf("Heartbeat delivery to %s failed: %s", platform, status.Error)
+				return
+			}
+			if status.State == bus.DeliveryQueued {
+				hs.logInfof("Heartbeat result held by %s until quiet hours end", platform)
+				return
+			}
+			hs.logInfof("Heartbeat result accepted by %s", platform)
+		},
 	})
 
-	hs.logInfof("Heartbeat result sent to %s", platform)
+	hs.logInfof("Heartbeat result queued for %s", platform)
 }
 
 // parseLastChannel parses the last channel string into platform and userID.
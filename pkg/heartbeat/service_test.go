@@ -3,6 +3,7 @@ package heartbeat
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -203,3 +204,137 @@ func TestHeartbeatFilePath(t *testing.T) {
 		t.Errorf("Expected HEARTBEAT.md at %s, but it doesn't exist", expectedPath)
 	}
 }
+
+func TestExecuteHeartbeat_RecordsLastRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte("Check the weather"), 0o644)
+
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	hs.stopChan = make(chan struct{}) // Enable for testing
+
+	if at, _ := hs.LastRun(); !at.IsZero() {
+		t.Fatalf("LastRun() before any run = %v, want zero", at)
+	}
+
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		return tools.ErrorResult("provider unreachable")
+	})
+	hs.executeHeartbeat()
+
+	at, outcome := hs.LastRun()
+	if at.IsZero() {
+		t.Error("LastRun() time is zero after a run")
+	}
+	if outcome != "error: provider unreachable" {
+		t.Errorf("LastRun() outcome = %q, want the error", outcome)
+	}
+}
+
+func TestExecuteHeartbeat_Skipped(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte("Check the weather"), 0o644)
+
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	hs.stopChan = make(chan struct{}) // Enable for testing
+
+	called := false
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		called = true
+		return tools.SilentResult("ok")
+	})
+	hs.SetSkip(func() string { return "maintenance" })
+	hs.executeHeartbeat()
+
+	if called {
+		t.Error("handler called while skipped")
+	}
+	if _, outcome := hs.LastRun(); outcome != "skipped: maintenance" {
+		t.Errorf("LastRun() outcome = %q, want skipped: maintenance", outcome)
+	}
+}
+
+func TestHeartbeatStatusFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	hs.stopChan = make(chan struct{}) // Enable for testing
+	os.WriteFile(filepath.Join(tmpDir, "HEARTBEAT.md"), []byte("Test task"), 0o644)
+
+	result := &tools.ToolResult{ForLLM: "failed", IsError: true}
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult { return result })
+
+	hs.setNextRun(time.Now().Add(30 * time.Minute))
+	hs.executeHeartbeat()
+	hs.executeHeartbeat()
+
+	st, err := ReadStatus(tmpDir)
+	if err != nil {
+		t.Fatalf("ReadStatus: %v", err)
+	}
+	if !st.Running || st.IntervalMinutes != 30 || st.NextRunAt.IsZero() {
+		t.Errorf("status = %+v; want running every 30 minutes with a next run", st)
+	}
+	if st.ConsecutiveErrors != 2 || st.LastOutcome != "error: failed" || st.LastRunAt.IsZero() {
+		t.Errorf("status = %+v; want 2 consecutive errors", st)
+	}
+
+	result = &tools.ToolResult{ForLLM: "done", Silent: true}
+	hs.executeHeartbeat()
+	if st, _ = ReadStatus(tmpDir); st.ConsecutiveErrors != 0 {
+		t.Errorf("consecutive errors = %d after a success, want 0", st.ConsecutiveErrors)
+	}
+
+	got := hs.GetStatus()
+	if !got.IsRunning || got.TotalRuns != 3 || got.ErrorCount != 2 || got.LastResultSummary != "ok" {
+		t.Errorf("GetStatus() = %+v; want running with 3 runs and 2 errors", got)
+	}
+	hs.SetSkip(func() string { return "maintenance" })
+	hs.executeHeartbeat()
+	if got = hs.GetStatus(); got.TotalRuns != 3 {
+		t.Errorf("TotalRuns = %d after a skipped heartbeat, want 3", got.TotalRuns)
+	}
+
+	hs.Stop()
+	if st, _ = ReadStatus(tmpDir); st.Running || !st.NextRunAt.IsZero() {
+		t.Errorf("status after Stop = %+v; want stopped without a next run", st)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	hs := NewHeartbeatService(tmpDir, 30, true)
+	called := false
+	hs.SetHandler(func(prompt, channel, chatID string) *tools.ToolResult {
+		called = true
+		return tools.SilentResult("ok")
+	})
+
+	// A missing HEARTBEAT.md shows the default template without creating it.
+	prompt := hs.DryRun()
+	if !strings.Contains(prompt, "Add your heartbeat tasks below this line:") {
+		t.Errorf("expected the default template in the prompt, got %q", prompt)
+	}
+	if _, err := os.Stat(TasksPath(tmpDir)); !os.IsNotExist(err) {
+		t.Errorf("DryRun must not write HEARTBEAT.md: %v", err)
+	}
+
+	if err := os.WriteFile(TasksPath(tmpDir), []byte("- water the plants\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prompt = hs.DryRun()
+	if !strings.Contains(prompt, "Current time:") || !strings.Contains(prompt, "- water the plants") {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+	if called {
+		t.Error("DryRun must not call the handler")
+	}
+	if _, err := ReadStatus(tmpDir); err == nil {
+		t.Error("DryRun must not record a run")
+	}
+
+	if err := os.WriteFile(TasksPath(tmpDir), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if prompt := hs.DryRun(); prompt != "" {
+		t.Errorf("expected no prompt for an empty file, got %q", prompt)
+	}
+}
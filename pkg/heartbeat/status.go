@@ -0,0 +1,128 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// Status is the machine-readable state of the heartbeat, written to
+// state/heartbeat.json in the workspace after every run.
+type Status struct {
+	Running           bool      `json:"running"`
+	IntervalMinutes   int       `json:"interval_minutes"`
+	LastRunAt         time.Time `json:"last_run_at,omitzero"`
+	LastOutcome       string    `json:"last_outcome,omitempty"`
+	NextRunAt         time.Time `json:"next_run_at,omitzero"`
+	ConsecutiveErrors int       `json:"consecutive_errors"`
+	TotalRuns         int       `json:"total_runs"`
+	ErrorCount        int       `json:"error_count"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// HeartbeatStatus is the summary of the heartbeat shown by picoclaw status
+// and the gateway's /status page. Counts are since the gateway started.
+type HeartbeatStatus struct {
+	LastRunAt         time.Time `json:"last_run_at,omitzero"`
+	LastResultSummary string    `json:"last_result_summary,omitempty"`
+	NextRunAt         time.Time `json:"next_run_at,omitzero"`
+	IsRunning         bool      `json:"is_running"`
+	TotalRuns         int       `json:"total_runs"`
+	ErrorCount        int       `json:"error_count"`
+}
+
+// Summary returns the summary of st.
+func (st Status) Summary() HeartbeatStatus {
+	return HeartbeatStatus{
+		LastRunAt:         st.LastRunAt,
+		LastResultSummary: st.LastOutcome,
+		NextRunAt:         st.NextRunAt,
+		IsRunning:         st.Running,
+		TotalRuns:         st.TotalRuns,
+		ErrorCount:        st.ErrorCount,
+	}
+}
+
+// StatusPath returns the status file of a workspace.
+func StatusPath(workspace string) string {
+	return filepath.Join(workspace, "state", "heartbeat.json")
+}
+
+// ReadStatus reads the status file of a workspace.
+func ReadStatus(workspace string) (Status, error) {
+	var st Status
+	data, err := os.ReadFile(StatusPath(workspace))
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("failed to parse heartbeat status: %w", err)
+	}
+	return st, nil
+}
+
+// Status returns the current heartbeat state.
+func (hs *HeartbeatService) Status() Status {
+	hs.mu.RLock()
+	defer hs.mu.RUnlock()
+	return hs.statusLocked()
+}
+
+// GetStatus returns the summary of the current heartbeat state.
+func (hs *HeartbeatService) GetStatus() HeartbeatStatus {
+	return hs.Status().Summary()
+}
+
+func (hs *HeartbeatService) statusLocked() Status {
+	return Status{
+		Running:           hs.stopChan != nil,
+		IntervalMinutes:   int(hs.interval.Minutes()),
+		LastRunAt:         hs.lastRunAt,
+		LastOutcome:       hs.lastOutcome,
+		NextRunAt:         hs.nextRunAt,
+		ConsecutiveErrors: hs.consecutiveErrors,
+		TotalRuns:         hs.totalRuns,
+		ErrorCount:        hs.errorCount,
+		UpdatedAt:         time.Now(),
+	}
+}
+
+// setNextRun records when the next heartbeat is due and saves the status.
+func (hs *HeartbeatService) setNextRun(next time.Time) {
+	hs.mu.Lock()
+	if hs.stopChan == nil {
+		hs.mu.Unlock()
+		return
+	}
+	hs.nextRunAt = next
+	st := hs.statusLocked()
+	hs.mu.Unlock()
+	hs.saveStatus(st)
+}
+
+// saveStatus writes st to the status file. Failures only go to the
+// heartbeat log; the status is informational.
+func (hs *HeartbeatService) saveStatus(st Status) {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return
+	}
+	path := StatusPath(hs.workspace)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		hs.logErrorf("Failed to save heartbeat status: %v", err)
+		return
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0o644); err != nil {
+		hs.logErrorf("Failed to save heartbeat status: %v", err)
+	}
+}
+
+// isErrorOutcome reports whether a recorded outcome is a failure.
+func isErrorOutcome(outcome string) bool {
+	return strings.HasPrefix(outcome, "error")
+}
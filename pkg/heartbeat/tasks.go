@@ -0,0 +1,72 @@
+package heartbeat
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTasks is the HEARTBEAT.md created in a workspace that has none.
+const DefaultTasks = `# Heartbeat Check List
+
+This file contains tasks for the heartbeat service to check periodically.
+
+## Examples
+
+- Check for unread messages
+- Review upcoming calendar events
+- Check device status (e.g., MaixCam)
+
+## Instructions
+
+- Execute ALL tasks listed below. Do NOT skip any task.
+- For simple tasks (e.g., report current time), respond directly.
+- For complex tasks that may take time, use the spawn tool to create a subagent.
+- The spawn tool is async - subagent results will be sent to the user automatically.
+- After spawning a subagent, CONTINUE to process remaining tasks.
+- Only respond with HEARTBEAT_OK when ALL tasks are done AND nothing needs attention.
+
+---
+
+Add your heartbeat tasks below this line:
+`
+
+// TasksPath returns the HEARTBEAT.md of a workspace.
+func TasksPath(workspace string) string {
+	return filepath.Join(workspace, "HEARTBEAT.md")
+}
+
+// CheckTasks reports whether content is usable as HEARTBEAT.md: it must
+// have at least one line that is not blank, a "#" heading or comment, or
+// inside an HTML comment.
+func CheckTasks(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return errors.New("HEARTBEAT.md is empty")
+	}
+	inComment := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		for line != "" {
+			if inComment {
+				end := strings.Index(line, "-->")
+				if end < 0 {
+					line = ""
+					break
+				}
+				inComment = false
+				line = strings.TrimSpace(line[end+3:])
+				continue
+			}
+			if strings.HasPrefix(line, "<!--") {
+				inComment = true
+				line = line[4:]
+				continue
+			}
+			break
+		}
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return nil
+		}
+	}
+	return errors.New("HEARTBEAT.md has only comments and headings; add at least one task")
+}
@@ -0,0 +1,27 @@
+package heartbeat
+
+import "testing"
+
+func TestCheckTasks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		ok      bool
+	}{
+		{"empty", "", false},
+		{"blank", " \n\n", false},
+		{"headings only", "# Tasks\n\n## Daily\n", false},
+		{"html comment", "# Tasks\n<!--\n- not a task\n-->\n", false},
+		{"task", "# Tasks\n- check the weather\n", true},
+		{"task after comment", "<!-- note --> check mail\n", true},
+		{"default template", DefaultTasks, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTasks(tt.content)
+			if (err == nil) != tt.ok {
+				t.Errorf("CheckTasks(%q) = %v, want ok=%v", tt.content, err, tt.ok)
+			}
+		})
+	}
+}
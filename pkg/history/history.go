@@ -0,0 +1,177 @@
+// Package history keeps an append-only transcript of every chat. Unlike the
+// session, which is summarized and truncated to fit the model's context, the
+// transcript keeps every user message and reply with its time, so a
+// conversation can be exported later.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// Entry is one message of a chat.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Role    string    `json:"role"` // "user" or "assistant"
+	Agent   string    `json:"agent,omitempty"`
+	Content string    `json:"content"`
+}
+
+// Path returns the transcript of a chat in a workspace, e.g.
+// <workspace>/history/telegram/123456.jsonl.
+func Path(workspace, channel, chatID string) string {
+	return filepath.Join(workspace, "history", fileName(channel), fileName(chatID)+".jsonl")
+}
+
+// fileName makes a channel or chat ID safe to use as a file name. ':' is
+// replaced as well, since it separates volumes on Windows.
+func fileName(s string) string {
+	return strings.ReplaceAll(utils.SanitizeFilename(s), ":", "_")
+}
+
+// MaxFileBytes caps the size of a transcript file. A transcript that would
+// grow beyond it is rotated to Path + ".1", replacing the previous rotation,
+// so a chat keeps at most twice this much history on disk.
+const MaxFileBytes = 4 << 20
+
+// Store appends entries to the transcripts of a workspace.
+type Store struct {
+	workspace string
+	maxBytes  int64
+
+	mu sync.Mutex
+}
+
+// NewStore returns the store of workspace.
+func NewStore(workspace string) *Store {
+	return &Store{workspace: workspace, maxBytes: MaxFileBytes}
+}
+
+// rotatedPath is where the older part of the transcript at path is kept.
+func rotatedPath(path string) string {
+	return path + ".1"
+}
+
+// Append adds entries to the transcript of a chat.
+func (s *Store) Append(channel, chatID string, entries ...Entry) error {
+	var buf []byte
+	for _, e := range entries {
+		if e.Time.IsZero() {
+			e.Time = time.Now()
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	path := Path(s.workspace, channel, chatID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 && info.Size()+int64(len(buf)) > s.maxBytes {
+		if err := os.Rename(path, rotatedPath(path)); err != nil {
+			return fmt.Errorf("failed to rotate history: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// Filter selects entries by time. Zero fields match everything.
+type Filter struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Read returns the entries of the transcript at path that match f, oldest
+// first, including its rotated part. A missing file has no entries; lines
+// that do not parse are skipped.
+func Read(path string, f Filter) ([]Entry, error) {
+	older, err := readFile(rotatedPath(path), f)
+	if err != nil {
+		return older, err
+	}
+	entries, err := readFile(path, f)
+	return append(older, entries...), err
+}
+
+func readFile(path string, f Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		if f.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// Markdown renders entries as a transcript titled with the chat.
+func Markdown(channel, chatID string, entries []Entry) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Conversation %s:%s\n", channel, chatID)
+	if len(entries) > 0 {
+		fmt.Fprintf(&sb, "\n%s to %s, %d messages\n",
+			entries[0].Time.Local().Format(time.DateTime),
+			entries[len(entries)-1].Time.Local().Format(time.DateTime),
+			len(entries))
+	}
+	for _, e := range entries {
+		role := "User"
+		if e.Role == "assistant" {
+			role = "Assistant"
+			if e.Agent != "" {
+				role += " (" + e.Agent + ")"
+			}
+		}
+		fmt.Fprintf(&sb, "\n### %s · %s\n\n%s\n", role, e.Time.Local().Format(time.DateTime), strings.TrimSpace(e.Content))
+	}
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoreAppendRead(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewStore(workspace)
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if err := store.Append("slack", "C1:thread", Entry{Time: start, Role: "user", Content: "hi"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := store.Append("slack", "C1:thread",
+		Entry{Time: start.Add(time.Hour), Role: "assistant", Agent: "main", Content: "hello"},
+		Entry{Time: start.Add(48 * time.Hour), Role: "user", Content: "later"},
+	); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	path := Path(workspace, "slack", "C1:thread")
+	if want := filepath.Join(workspace, "history", "slack", "C1_thread.jsonl"); path != want {
+		t.Errorf("Path = %q, want %q", path, want)
+	}
+
+	all, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(all) != 3 || all[1].Agent != "main" || all[2].Content != "later" {
+		t.Fatalf("Read = %+v", all)
+	}
+
+	day, err := Read(path, Filter{Since: start, Until: start.Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(day) != 2 {
+		t.Errorf("filtered Read returned %d entries, want 2", len(day))
+	}
+}
+
+func TestReadMissingAndCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	entries, err := Read(filepath.Join(dir, "missing.jsonl"), Filter{})
+	if err != nil || entries != nil {
+		t.Errorf("Read(missing) = %v, %v; want nil, nil", entries, err)
+	}
+
+	path := filepath.Join(dir, "chat.jsonl")
+	data := "not json\n" + `{"time":"2026-03-01T09:00:00Z","role":"user","content":"ok"}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = Read(path, Filter{})
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Read(corrupt) = %v, %v; want 1 entry", entries, err)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	md := Markdown("telegram", "123", []Entry{
+		{Time: at, Role: "user", Content: "  what time is it?\n"},
+		{Time: at.Add(time.Second), Role: "assistant", Agent: "main", Content: "Nine."},
+	})
+
+	for _, want := range []string{
+		"# Conversation telegram:123\n",
+		"2026-03-01 09:00:00 to 2026-03-01 09:00:01, 2 messages",
+		"### User · 2026-03-01 09:00:00\n\nwhat time is it?\n",
+		"### Assistant (main) · 2026-03-01 09:00:01\n\nNine.\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestStoreRotatesLargeTranscript(t *testing.T) {
+	workspace := t.TempDir()
+	store := NewStore(workspace)
+	store.maxBytes = 200
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 10 {
+		entry := Entry{Time: start.Add(time.Duration(i) * time.Minute), Role: "user", Content: strings.Repeat("x", 40)}
+		if err := store.Append("telegram", "1", entry); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	path := Path(workspace, "telegram", "1")
+	for _, p := range []string{path, rotatedPath(path)} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("stat %s: %v", p, err)
+		}
+		if info.Size() > store.maxBytes {
+			t.Errorf("%s is %d bytes, want at most %d", p, info.Size(), store.maxBytes)
+		}
+	}
+
+	entries, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(entries) == 0 || len(entries) >= 10 {
+		t.Fatalf("Read returned %d entries, want the newest few", len(entries))
+	}
+	if last := entries[len(entries)-1]; !last.Time.Equal(start.Add(9 * time.Minute)) {
+		t.Errorf("last entry at %v, want the newest", last.Time)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Time.Before(entries[i-1].Time) {
+			t.Fatalf("entries out of order: %+v", entries)
+		}
+	}
+}
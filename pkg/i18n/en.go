@@ -0,0 +1,72 @@
+package i18n
+
+var en = map[string]string{
+	AgentBusy:               "I'm busy with other conversations right now. Please try again in a moment.",
+	AgentError:              "Error processing message: %v",
+	AgentNoResponse:         "I've completed processing but have no response to give. Increase `max_tool_iterations` in config.json.",
+	AgentEmptyResponse:      "Sorry, I couldn't come up with a reply to that. Please try asking again, perhaps in other words.",
+	AgentContextCompressing: "Context window exceeded. Compressing history and retrying...",
+	AgentBackgroundDone:     "Background task completed.",
+	AgentDegradedNote:       "⚠️ Degraded answer: the full request failed, so this was written without tools and with less context.",
+	AgentMaintenance:        "I'm temporarily offline for maintenance. Please try again later.",
+	AgentNotConfigured:      "I'm not configured yet: no model provider is set up. The owner can fix this with `picoclaw auth login`.",
+	RateLimitSlowDown:       "You're sending messages faster than I can keep up. Please slow down and try again later.",
+	BudgetExceeded:          "This chat has reached its spending limit. Please try again once it resets.",
+	BudgetNoticeDay:         "Budget for %s used up for today: %d tokens, $%.2f. Further messages are refused until tomorrow.",
+	BudgetNoticeMonth:       "Budget for %s used up for this month: %d tokens, $%.2f. Further messages are refused until next month.",
+
+	ProviderAuth:             "I can't reach the AI model: the provider rejected my credentials or account. Please ask the bot owner to check the configuration.",
+	ProviderRateLimited:      "I'm being rate limited by the AI provider. Please try again in a minute.",
+	ProviderRateLimitedRetry: "I'm being rate limited by the AI provider. Please try again in %s.",
+	ProviderContextTooLong:   "This conversation has grown too long for the model. Send /reset to start over, or ask something shorter.",
+	ProviderContentFiltered:  "The AI provider's content filter blocked this request, so I can't answer it.",
+	ProviderUnavailable:      "The AI provider is unavailable right now. Please try again in a few minutes.",
+	ProviderBadRequest:       "The AI provider couldn't process this request. Please try again, perhaps in other words.",
+
+	CommandOwnerOnly:      "%s is restricted to the bot owner.",
+	CommandHelpHeader:     "Available commands:",
+	CommandHelpOwnerTag:   " (owner only)",
+	CommandHelpFooter:     "Anything else is sent to the assistant.",
+	CommandHelpDesc:       "Show available commands",
+	CommandResetDesc:      "Clear the conversation history for this chat",
+	CommandResetDone:      "Conversation history cleared. Starting fresh.",
+	CommandResetFailed:    "Conversation cleared, but saving failed: %v",
+	CommandWhoamiDesc:     "Show how the bot identifies you",
+	CommandWhoamiReply:    "You are %s on %s, chat %s, talking to agent %s.",
+	CommandWhoamiOwner:    "You are a bot owner.",
+	CommandModelDesc:      "Show or switch the model used in this chat",
+	CommandModelCurrent:   "Model for this chat: %s",
+	CommandModelNoState:   "Per-chat models are not available: no workspace state.",
+	CommandModelSwitched:  "This chat now uses %s.",
+	CommandModelUnknown:   "Unknown model %q. Valid models: %s",
+	CommandModelNoModels:  "Unknown model %q. No models are configured in model_list.",
+	CommandModelSetFailed: "Failed to switch model: %v",
+	CommandRouteDesc:      "Show or pin the auto-route tier of this chat",
+	CommandRouteOff:       "Auto-routing is off (agents.defaults.auto_route).",
+	CommandRouteAuto:      "This chat is routed automatically. Tiers: %s",
+	CommandRoutePinned:    "This chat is pinned to the %s tier.",
+	CommandRouteUnknown:   "Unknown tier %q. Tiers: %s, or auto",
+	CommandRouteSetFailed: "Failed to pin the tier: %v",
+	CommandStatusDesc:     "Show session usage for this chat",
+	CommandSkillsDesc:     "List installed skills",
+	CommandSkillsNone:     "No skills installed.",
+	CommandSkillsHeader:   "Installed skills (%d):",
+	CommandShowDesc:       "Show current configuration",
+	CommandListDesc:       "List available options",
+	CommandSwitchDesc:     "Switch the default model or target channel",
+
+	ChannelPlaceholder:    "Thinking... 💭",
+	ModerationInbound:     "Sorry, I can't help with that message.",
+	ModerationOutbound:    "Sorry, I can't share that response.",
+	ScanRemoved:           "⚠️ %d attachment(s) from this message were removed by the malware scan.",
+	ScanUnscanned:         "⚠️ %d attachment(s) from this message could not be checked for malware and were removed.",
+	ScanOwnerNotice:       "Deleted %d infected attachment(s) from %s in %s: %s",
+	ChannelMessageExpired: "Sorry, I was offline when you sent this %d minutes ago. Please send it again if you still need an answer.",
+	QuietHoursQueued:      "Quiet hours until %s. I'll reply then.",
+	QuietHoursRejected:    "Quiet hours until %s, so this message won't be answered. Please send it again later.",
+	DigestHeader:          "📰 Digest (%d updates)",
+	WeComUnsupportedType:  "Unsupported message type: %s",
+	WeComTaskNotFound:     "Task not found or already finished. Please resend your message to start a new session.",
+	WeComImageUnsupported: "Image received (URL: %s), but image messages are not yet supported",
+	WeComMixedUnsupported: "Mixed message type is not yet supported",
+}
@@ -0,0 +1,144 @@
+// Package i18n is the catalog of canned messages the bot sends on its own
+// (busy and error replies, command confirmations, moderation and rate-limit
+// notices, ...), so they follow the configured gateway language. Replies
+// written by the model are not affected.
+package i18n
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DefaultLocale is used for unknown locales and keys missing from a locale.
+const DefaultLocale = "en"
+
+// Message keys. The comment names the fmt arguments a message takes.
+const (
+	AgentBusy               = "agent.busy"
+	AgentError              = "agent.error" // error
+	AgentNoResponse         = "agent.no_response"
+	AgentEmptyResponse      = "agent.empty_response"
+	AgentContextCompressing = "agent.context_compressing"
+	AgentBackgroundDone     = "agent.background_done"
+	AgentDegradedNote       = "agent.degraded_note"
+	AgentMaintenance        = "agent.maintenance"
+	AgentNotConfigured      = "agent.not_configured"
+	RateLimitSlowDown       = "rate_limit.slow_down"
+
+	ProviderAuth             = "provider.auth"
+	ProviderRateLimited      = "provider.rate_limited"
+	ProviderRateLimitedRetry = "provider.rate_limited_retry" // wait, e.g. "45s"
+	ProviderContextTooLong   = "provider.context_too_long"
+	ProviderContentFiltered  = "provider.content_filtered"
+	ProviderUnavailable      = "provider.unavailable"
+	ProviderBadRequest       = "provider.bad_request"
+
+	BudgetExceeded    = "budget.exceeded"
+	BudgetNoticeDay   = "budget.notice_day"   // budget, tokens, cost
+	BudgetNoticeMonth = "budget.notice_month" // budget, tokens, cost
+
+	CommandOwnerOnly      = "command.owner_only" // command with prefix
+	CommandHelpHeader     = "command.help.header"
+	CommandHelpOwnerTag   = "command.help.owner_tag"
+	CommandHelpFooter     = "command.help.footer"
+	CommandHelpDesc       = "command.help.description"
+	CommandResetDesc      = "command.reset.description"
+	CommandResetDone      = "command.reset.done"
+	CommandResetFailed    = "command.reset.failed" // error
+	CommandWhoamiDesc     = "command.whoami.description"
+	CommandWhoamiReply    = "command.whoami.reply" // sender, channel, chat ID, agent ID
+	CommandWhoamiOwner    = "command.whoami.owner"
+	CommandModelDesc      = "command.model.description"
+	CommandModelCurrent   = "command.model.current" // model
+	CommandModelNoState   = "command.model.no_state"
+	CommandModelSwitched  = "command.model.switched"   // model
+	CommandModelUnknown   = "command.model.unknown"    // name, valid names
+	CommandModelNoModels  = "command.model.no_models"  // name
+	CommandModelSetFailed = "command.model.set_failed" // error
+	CommandRouteDesc      = "command.route.description"
+	CommandRouteOff       = "command.route.off"
+	CommandRouteAuto      = "command.route.auto"       // tier names
+	CommandRoutePinned    = "command.route.pinned"     // tier
+	CommandRouteUnknown   = "command.route.unknown"    // name, tier names
+	CommandRouteSetFailed = "command.route.set_failed" // error
+	CommandStatusDesc     = "command.status.description"
+	CommandSkillsDesc     = "command.skills.description"
+	CommandSkillsNone     = "command.skills.none"
+	CommandSkillsHeader   = "command.skills.header" // count
+	CommandShowDesc       = "command.show.description"
+	CommandListDesc       = "command.list.description"
+	CommandSwitchDesc     = "command.switch.description"
+
+	ChannelPlaceholder    = "channel.placeholder"
+	ModerationInbound     = "moderation.blocked_inbound"
+	ModerationOutbound    = "moderation.blocked_outbound"
+	ScanRemoved           = "scan.removed"            // file count
+	ScanUnscanned         = "scan.unscanned"          // file count
+	ScanOwnerNotice       = "scan.owner_notice"       // file count, sender, channel:chat, signatures
+	ChannelMessageExpired = "channel.message_expired" // age in minutes
+	QuietHoursQueued      = "quiet_hours.queued"      // end time
+	QuietHoursRejected    = "quiet_hours.rejected"    // end time
+	DigestHeader          = "digest.header"           // update count
+	WeComUnsupportedType  = "wecom.unsupported_type"  // message type
+	WeComTaskNotFound     = "wecom.task_not_found"
+	WeComImageUnsupported = "wecom.image_unsupported" // image URL
+	WeComMixedUnsupported = "wecom.mixed_unsupported"
+)
+
+var catalogs = map[string]map[string]string{
+	"en":    en,
+	"zh-CN": zhCN,
+	"zh-TW": zhTW,
+	"ja":    ja,
+}
+
+// Locales returns the shipped locales, sorted.
+func Locales() []string {
+	return slices.Sorted(maps.Keys(catalogs))
+}
+
+// Keys returns every message key, sorted.
+func Keys() []string {
+	return slices.Sorted(maps.Keys(catalogs[DefaultLocale]))
+}
+
+// Normalize maps a language tag such as "zh_cn", "zh-Hant" or "ja-JP" to a
+// shipped locale, falling back to DefaultLocale.
+func Normalize(lang string) string {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+	for locale := range catalogs {
+		if strings.ToLower(locale) == tag {
+			return locale
+		}
+	}
+
+	base, region, _ := strings.Cut(tag, "-")
+	switch base {
+	case "zh":
+		switch region {
+		case "tw", "hk", "mo", "hant":
+			return "zh-TW"
+		}
+		return "zh-CN"
+	case "ja":
+		return "ja"
+	}
+	return DefaultLocale
+}
+
+// T returns the message for key in lang, formatted with args. Keys missing
+// from the locale fall back to English.
+func T(lang, key string, args ...any) string {
+	msg, ok := catalogs[Normalize(lang)][key]
+	if !ok {
+		if msg, ok = en[key]; !ok {
+			return key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+var verbRe = regexp.MustCompile(`%[vsdq]`)
+
+func TestEveryKeyInEveryLocale(t *testing.T) {
+	keys := Keys()
+	for _, locale := range Locales() {
+		catalog := catalogs[locale]
+		for _, key := range keys {
+			msg, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing key %q", locale, key)
+				continue
+			}
+			// Translations must take the same arguments in the same order.
+			if got, want := verbRe.FindAllString(msg, -1), verbRe.FindAllString(en[key], -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has format verbs %v, want %v", locale, key, got, want)
+			}
+		}
+		for key := range catalog {
+			if _, ok := en[key]; !ok {
+				t.Errorf("%s: key %q is not in the English catalog", locale, key)
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"":        "en",
+		"en-US":   "en",
+		"zh-CN":   "zh-CN",
+		"zh_cn":   "zh-CN",
+		"zh":      "zh-CN",
+		"zh-Hans": "zh-CN",
+		"zh-TW":   "zh-TW",
+		"zh-HK":   "zh-TW",
+		"zh-Hant": "zh-TW",
+		"ja-JP":   "ja",
+		"fr":      "en",
+	}
+	for lang, want := range tests {
+		if got := Normalize(lang); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("ja", CommandOwnerOnly, "/model"); got != "/model はボットの所有者のみ使用できます。" {
+		t.Errorf("T(ja) = %q", got)
+	}
+	if got := T("fr", CommandResetDone); got != en[CommandResetDone] {
+		t.Errorf("unknown locale should fall back to English, got %q", got)
+	}
+	if got := T("en", "no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key = %q", got)
+	}
+}
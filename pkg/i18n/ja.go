@@ -0,0 +1,72 @@
+package i18n
+
+var ja = map[string]string{
+	AgentBusy:               "現在ほかの会話に対応中です。しばらくしてからもう一度お試しください。",
+	AgentError:              "メッセージの処理中にエラーが発生しました: %v",
+	AgentNoResponse:         "処理は完了しましたが、返答できる内容がありません。config.json の `max_tool_iterations` を増やしてください。",
+	AgentEmptyResponse:      "すみません、うまく返答できませんでした。言い方を変えてもう一度お試しください。",
+	AgentContextCompressing: "コンテキストウィンドウを超えました。履歴を圧縮して再試行しています…",
+	AgentBackgroundDone:     "バックグラウンドタスクが完了しました。",
+	AgentDegradedNote:       "⚠️ 簡易回答：通常の処理に失敗したため、ツールを使わず限られた文脈で回答しています。",
+	AgentMaintenance:        "メンテナンスのため一時的にオフラインです。しばらくしてからもう一度お試しください。",
+	AgentNotConfigured:      "まだ設定されていません。モデルプロバイダーが未設定です。管理者は `picoclaw auth login` で設定できます。",
+	RateLimitSlowDown:       "メッセージの送信が速すぎます。少し間をおいてから、もう一度お試しください。",
+	BudgetExceeded:          "このチャットは利用上限に達しました。リセット後にもう一度お試しください。",
+	BudgetNoticeDay:         "%s の本日の予算を使い切りました（%d トークン、$%.2f）。明日まで以降のメッセージはお断りします。",
+	BudgetNoticeMonth:       "%s の今月の予算を使い切りました（%d トークン、$%.2f）。来月まで以降のメッセージはお断りします。",
+
+	ProviderAuth:             "AI モデルに接続できません。プロバイダーが認証情報またはアカウントを拒否しました。ボットの管理者に設定の確認を依頼してください。",
+	ProviderRateLimited:      "AI プロバイダーのレート制限に達しました。1 分ほどしてからもう一度お試しください。",
+	ProviderRateLimitedRetry: "AI プロバイダーのレート制限に達しました。%s 後にもう一度お試しください。",
+	ProviderContextTooLong:   "この会話はモデルにとって長くなりすぎました。/reset でやり直すか、もっと短い質問をしてください。",
+	ProviderContentFiltered:  "AI プロバイダーのコンテンツフィルターによりこのリクエストはブロックされたため、お答えできません。",
+	ProviderUnavailable:      "AI プロバイダーは現在利用できません。数分後にもう一度お試しください。",
+	ProviderBadRequest:       "AI プロバイダーがこのリクエストを処理できませんでした。言い方を変えてもう一度お試しください。",
+
+	CommandOwnerOnly:      "%s はボットの所有者のみ使用できます。",
+	CommandHelpHeader:     "使用できるコマンド:",
+	CommandHelpOwnerTag:   "（所有者のみ）",
+	CommandHelpFooter:     "それ以外のメッセージはアシスタントに送られます。",
+	CommandHelpDesc:       "使用できるコマンドを表示",
+	CommandResetDesc:      "このチャットの会話履歴を消去",
+	CommandResetDone:      "会話履歴を消去しました。新しく始めましょう。",
+	CommandResetFailed:    "会話は消去しましたが、保存に失敗しました: %v",
+	CommandWhoamiDesc:     "ボットから見たあなたの情報を表示",
+	CommandWhoamiReply:    "あなたは %s（%s、チャット %s）、エージェント %s と会話中です。",
+	CommandWhoamiOwner:    "あなたはボットの所有者です。",
+	CommandModelDesc:      "このチャットで使うモデルを表示・切り替え",
+	CommandModelCurrent:   "このチャットのモデル: %s",
+	CommandModelNoState:   "チャットごとのモデルは使用できません: ワークスペースの状態がありません。",
+	CommandModelSwitched:  "このチャットは %s を使用します。",
+	CommandModelUnknown:   "不明なモデル %q です。使用できるモデル: %s",
+	CommandModelNoModels:  "不明なモデル %q です。model_list にモデルが設定されていません。",
+	CommandModelSetFailed: "モデルの切り替えに失敗しました: %v",
+	CommandRouteDesc:      "このチャットの自動ルーティングの階層を表示・固定",
+	CommandRouteOff:       "自動ルーティングは無効です（agents.defaults.auto_route）。",
+	CommandRouteAuto:      "このチャットは自動でルーティングされます。階層: %s",
+	CommandRoutePinned:    "このチャットは %s 階層に固定されています。",
+	CommandRouteUnknown:   "不明な階層 %q です。階層: %s、または auto",
+	CommandRouteSetFailed: "階層の固定に失敗しました: %v",
+	CommandStatusDesc:     "このチャットのセッション使用状況を表示",
+	CommandSkillsDesc:     "インストール済みのスキルを一覧表示",
+	CommandSkillsNone:     "スキルはインストールされていません。",
+	CommandSkillsHeader:   "インストール済みのスキル（%d）:",
+	CommandShowDesc:       "現在の設定を表示",
+	CommandListDesc:       "選択肢を一覧表示",
+	CommandSwitchDesc:     "デフォルトのモデルまたは送信先チャネルを切り替え",
+
+	ChannelPlaceholder:    "考え中… 💭",
+	ModerationInbound:     "申し訳ありませんが、そのメッセージにはお答えできません。",
+	ModerationOutbound:    "申し訳ありませんが、その返答はお伝えできません。",
+	ScanRemoved:           "⚠️ このメッセージの添付ファイル %d 件はマルウェアスキャンにより削除されました。",
+	ScanUnscanned:         "⚠️ このメッセージの添付ファイル %d 件はマルウェアスキャンで確認できなかったため削除されました。",
+	ScanOwnerNotice:       "感染した添付ファイル %d 件を削除しました（送信者 %s、%s）: %s",
+	ChannelMessageExpired: "%d 分前にこのメッセージが送られたとき、オフラインでした。まだ回答が必要な場合はもう一度送ってください。",
+	QuietHoursQueued:      "%s まで休止時間です。その後に返信します。",
+	QuietHoursRejected:    "%s まで休止時間のため、このメッセージには返信しません。後でもう一度送ってください。",
+	DigestHeader:          "📰 ダイジェスト（%d 件の更新）",
+	WeComUnsupportedType:  "サポートされていないメッセージタイプです: %s",
+	WeComTaskNotFound:     "タスクが見つからないか、すでに終了しています。新しいセッションを始めるにはメッセージを再送してください。",
+	WeComImageUnsupported: "画像を受け取りました（URL: %s）が、画像メッセージにはまだ対応していません",
+	WeComMixedUnsupported: "テキストと画像の混在メッセージにはまだ対応していません",
+}
@@ -0,0 +1,72 @@
+package i18n
+
+var zhCN = map[string]string{
+	AgentBusy:               "我正在处理其他对话，请稍后再试。",
+	AgentError:              "处理消息时出错：%v",
+	AgentNoResponse:         "处理已完成，但没有可回复的内容。请在 config.json 中调大 `max_tool_iterations`。",
+	AgentEmptyResponse:      "抱歉，我没能给出回复。请换个说法再试一次。",
+	AgentContextCompressing: "上下文窗口已满，正在压缩历史记录并重试……",
+	AgentBackgroundDone:     "后台任务已完成。",
+	AgentDegradedNote:       "⚠️ 降级回复：完整请求失败，以下内容未使用工具且上下文有限。",
+	AgentMaintenance:        "我正在维护，暂时离线，请稍后再试。",
+	AgentNotConfigured:      "我还没有配置好：尚未设置模型提供方。管理员可以运行 `picoclaw auth login` 进行设置。",
+	RateLimitSlowDown:       "你发送消息的速度太快了，请放慢一些，稍后再试。",
+	BudgetExceeded:          "此聊天已达到消费上限，请在额度重置后再试。",
+	BudgetNoticeDay:         "%s 今日预算已用完：%d 个 token，$%.2f。明天之前的消息将被拒绝。",
+	BudgetNoticeMonth:       "%s 本月预算已用完：%d 个 token，$%.2f。下个月之前的消息将被拒绝。",
+
+	ProviderAuth:             "无法访问 AI 模型：服务商拒绝了我的凭据或账户。请联系机器人管理员检查配置。",
+	ProviderRateLimited:      "AI 服务商正在限制我的请求频率，请一分钟后再试。",
+	ProviderRateLimitedRetry: "AI 服务商正在限制我的请求频率，请在 %s 后再试。",
+	ProviderContextTooLong:   "这段对话对模型来说已经太长了。发送 /reset 重新开始，或者问一个更短的问题。",
+	ProviderContentFiltered:  "AI 服务商的内容过滤拦截了这个请求，我无法回答。",
+	ProviderUnavailable:      "AI 服务商暂时不可用，请几分钟后再试。",
+	ProviderBadRequest:       "AI 服务商无法处理这个请求。请再试一次，或者换个说法。",
+
+	CommandOwnerOnly:      "%s 仅限机器人所有者使用。",
+	CommandHelpHeader:     "可用命令：",
+	CommandHelpOwnerTag:   "（仅限所有者）",
+	CommandHelpFooter:     "其他内容都会发送给助手。",
+	CommandHelpDesc:       "显示可用命令",
+	CommandResetDesc:      "清除此聊天的对话历史",
+	CommandResetDone:      "对话历史已清除，重新开始。",
+	CommandResetFailed:    "对话已清除，但保存失败：%v",
+	CommandWhoamiDesc:     "显示机器人如何识别你",
+	CommandWhoamiReply:    "你是 %s（%s，聊天 %s），正在与智能体 %s 对话。",
+	CommandWhoamiOwner:    "你是机器人所有者。",
+	CommandModelDesc:      "查看或切换此聊天使用的模型",
+	CommandModelCurrent:   "此聊天的模型：%s",
+	CommandModelNoState:   "无法使用按聊天设置的模型：没有工作区状态。",
+	CommandModelSwitched:  "此聊天现在使用 %s。",
+	CommandModelUnknown:   "未知模型 %q。可用模型：%s",
+	CommandModelNoModels:  "未知模型 %q。model_list 中没有配置任何模型。",
+	CommandModelSetFailed: "切换模型失败：%v",
+	CommandRouteDesc:      "查看或固定此聊天的自动路由层级",
+	CommandRouteOff:       "自动路由未开启（agents.defaults.auto_route）。",
+	CommandRouteAuto:      "此聊天自动路由。层级：%s",
+	CommandRoutePinned:    "此聊天已固定为 %s 层级。",
+	CommandRouteUnknown:   "未知层级 %q。层级：%s，或 auto",
+	CommandRouteSetFailed: "固定层级失败：%v",
+	CommandStatusDesc:     "显示此聊天的会话用量",
+	CommandSkillsDesc:     "列出已安装的技能",
+	CommandSkillsNone:     "尚未安装任何技能。",
+	CommandSkillsHeader:   "已安装的技能（%d）：",
+	CommandShowDesc:       "显示当前配置",
+	CommandListDesc:       "列出可用选项",
+	CommandSwitchDesc:     "切换默认模型或目标渠道",
+
+	ChannelPlaceholder:    "思考中… 💭",
+	ModerationInbound:     "抱歉，我无法处理这条消息。",
+	ModerationOutbound:    "抱歉，我无法提供这条回复。",
+	ScanRemoved:           "⚠️ 此消息中的 %d 个附件已被恶意软件扫描移除。",
+	ScanUnscanned:         "⚠️ 此消息中的 %d 个附件无法进行恶意软件扫描，已被移除。",
+	ScanOwnerNotice:       "已删除 %d 个受感染的附件（发送者 %s，%s）：%s",
+	ChannelMessageExpired: "抱歉，%d 分钟前你发送这条消息时我处于离线状态。如仍需回复，请重新发送。",
+	QuietHoursQueued:      "现在是免打扰时间，直到 %s。届时我会回复。",
+	QuietHoursRejected:    "现在是免打扰时间，直到 %s，这条消息不会被处理。请稍后再发送。",
+	DigestHeader:          "📰 摘要（%d 条更新）",
+	WeComUnsupportedType:  "不支持的消息类型：%s",
+	WeComTaskNotFound:     "任务不存在或已结束，请重新发送消息以开始新的会话。",
+	WeComImageUnsupported: "已收到图片（URL：%s），但暂不支持图片消息",
+	WeComMixedUnsupported: "暂不支持图文混合消息",
+}
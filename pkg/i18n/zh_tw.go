@@ -0,0 +1,72 @@
+package i18n
+
+var zhTW = map[string]string{
+	AgentBusy:               "我正在處理其他對話，請稍後再試。",
+	AgentError:              "處理訊息時發生錯誤：%v",
+	AgentNoResponse:         "處理已完成，但沒有可回覆的內容。請在 config.json 中調高 `max_tool_iterations`。",
+	AgentEmptyResponse:      "抱歉，我沒能給出回覆。請換個說法再試一次。",
+	AgentContextCompressing: "上下文視窗已滿，正在壓縮歷史紀錄並重試……",
+	AgentBackgroundDone:     "背景工作已完成。",
+	AgentDegradedNote:       "⚠️ 降級回覆：完整請求失敗，以下內容未使用工具且上下文有限。",
+	AgentMaintenance:        "我正在維護，暫時離線，請稍後再試。",
+	AgentNotConfigured:      "我還沒有設定好：尚未設定模型提供者。管理員可以執行 `picoclaw auth login` 進行設定。",
+	RateLimitSlowDown:       "你傳送訊息的速度太快了，請放慢一些，稍後再試。",
+	BudgetExceeded:          "此聊天已達到消費上限，請在額度重置後再試。",
+	BudgetNoticeDay:         "%s 今日預算已用完：%d 個 token，$%.2f。明天之前的訊息將被拒絕。",
+	BudgetNoticeMonth:       "%s 本月預算已用完：%d 個 token，$%.2f。下個月之前的訊息將被拒絕。",
+
+	ProviderAuth:             "無法存取 AI 模型：服務商拒絕了我的憑證或帳戶。請聯絡機器人管理員檢查設定。",
+	ProviderRateLimited:      "AI 服務商正在限制我的請求頻率，請一分鐘後再試。",
+	ProviderRateLimitedRetry: "AI 服務商正在限制我的請求頻率，請在 %s 後再試。",
+	ProviderContextTooLong:   "這段對話對模型來說已經太長了。傳送 /reset 重新開始，或者問一個更短的問題。",
+	ProviderContentFiltered:  "AI 服務商的內容過濾攔截了這個請求，我無法回答。",
+	ProviderUnavailable:      "AI 服務商暫時無法使用，請幾分鐘後再試。",
+	ProviderBadRequest:       "AI 服務商無法處理這個請求。請再試一次，或者換個說法。",
+
+	CommandOwnerOnly:      "%s 僅限機器人擁有者使用。",
+	CommandHelpHeader:     "可用指令：",
+	CommandHelpOwnerTag:   "（僅限擁有者）",
+	CommandHelpFooter:     "其他內容都會傳送給助理。",
+	CommandHelpDesc:       "顯示可用指令",
+	CommandResetDesc:      "清除此聊天的對話紀錄",
+	CommandResetDone:      "對話紀錄已清除，重新開始。",
+	CommandResetFailed:    "對話已清除，但儲存失敗：%v",
+	CommandWhoamiDesc:     "顯示機器人如何識別你",
+	CommandWhoamiReply:    "你是 %s（%s，聊天 %s），正在與代理 %s 對話。",
+	CommandWhoamiOwner:    "你是機器人擁有者。",
+	CommandModelDesc:      "查看或切換此聊天使用的模型",
+	CommandModelCurrent:   "此聊天的模型：%s",
+	CommandModelNoState:   "無法使用個別聊天的模型：沒有工作區狀態。",
+	CommandModelSwitched:  "此聊天現在使用 %s。",
+	CommandModelUnknown:   "未知的模型 %q。可用模型：%s",
+	CommandModelNoModels:  "未知的模型 %q。model_list 中沒有設定任何模型。",
+	CommandModelSetFailed: "切換模型失敗：%v",
+	CommandRouteDesc:      "查看或固定此聊天的自動路由層級",
+	CommandRouteOff:       "自動路由未開啟（agents.defaults.auto_route）。",
+	CommandRouteAuto:      "此聊天自動路由。層級：%s",
+	CommandRoutePinned:    "此聊天已固定為 %s 層級。",
+	CommandRouteUnknown:   "未知的層級 %q。層級：%s，或 auto",
+	CommandRouteSetFailed: "固定層級失敗：%v",
+	CommandStatusDesc:     "顯示此聊天的工作階段用量",
+	CommandSkillsDesc:     "列出已安裝的技能",
+	CommandSkillsNone:     "尚未安裝任何技能。",
+	CommandSkillsHeader:   "已安裝的技能（%d）：",
+	CommandShowDesc:       "顯示目前設定",
+	CommandListDesc:       "列出可用選項",
+	CommandSwitchDesc:     "切換預設模型或目標頻道",
+
+	ChannelPlaceholder:    "思考中… 💭",
+	ModerationInbound:     "抱歉，我無法處理這則訊息。",
+	ModerationOutbound:    "抱歉，我無法提供這則回覆。",
+	ScanRemoved:           "⚠️ 此訊息中的 %d 個附件已被惡意軟體掃描移除。",
+	ScanUnscanned:         "⚠️ 此訊息中的 %d 個附件無法進行惡意軟體掃描，已被移除。",
+	ScanOwnerNotice:       "已刪除 %d 個受感染的附件（傳送者 %s，%s）：%s",
+	ChannelMessageExpired: "抱歉，%d 分鐘前你傳送這則訊息時我處於離線狀態。如仍需回覆，請重新傳送。",
+	QuietHoursQueued:      "現在是勿擾時段，直到 %s。屆時我會回覆。",
+	QuietHoursRejected:    "現在是勿擾時段，直到 %s，這則訊息不會被處理。請稍後再傳送。",
+	DigestHeader:          "📰 摘要（%d 則更新）",
+	WeComUnsupportedType:  "不支援的訊息類型：%s",
+	WeComTaskNotFound:     "找不到任務或任務已結束，請重新傳送訊息以開始新的工作階段。",
+	WeComImageUnsupported: "已收到圖片（URL：%s），但目前尚不支援圖片訊息",
+	WeComMixedUnsupported: "目前尚不支援圖文混合訊息",
+}
@@ -0,0 +1,148 @@
+// Package maintenance implements the gateway's maintenance mode: while it is
+// on, messages are still received but answered with a canned notice instead
+// of calling the model, and cron jobs and heartbeats are skipped. It is
+// switched on by hand (persisted in the workspace state, so it survives a
+// restart) or by a daily schedule in the config.
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+// window is a parsed daily maintenance window, in minutes after midnight.
+type window struct {
+	start, end int
+	loc        *time.Location
+	label      string // "02:00-04:00 UTC", for status output
+}
+
+// Mode reports whether maintenance is on. A nil *Mode is never on.
+type Mode struct {
+	message string
+	windows []window
+	state   *state.Manager
+	now     func() time.Time
+}
+
+// Status describes why maintenance is on.
+type Status struct {
+	Active bool   `json:"active"`
+	Manual bool   `json:"manual"`           // switched on by hand
+	Window string `json:"window,omitempty"` // the scheduled window in effect
+}
+
+// New returns the maintenance mode configured by cfg, keeping the manual
+// switch in sm. sm may be nil, in which case only the schedule applies.
+func New(cfg config.MaintenanceConfig, sm *state.Manager) (*Mode, error) {
+	m := &Mode{
+		message: cfg.Message,
+		state:   sm,
+		now:     time.Now,
+	}
+	for i, w := range cfg.Schedule {
+		parsed, err := parseWindow(w)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance schedule %d: %w", i+1, err)
+		}
+		m.windows = append(m.windows, parsed)
+	}
+	return m, nil
+}
+
+func parseWindow(w config.MaintenanceWindow) (window, error) {
+	start, err := time.Parse("15:04", strings.TrimSpace(w.Start))
+	if err != nil {
+		return window{}, fmt.Errorf("invalid start %q: use HH:MM", w.Start)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(w.End))
+	if err != nil {
+		return window{}, fmt.Errorf("invalid end %q: use HH:MM", w.End)
+	}
+	if start.Equal(end) {
+		return window{}, fmt.Errorf("start and end are both %s", w.Start)
+	}
+	loc := time.Local
+	if w.Timezone != "" {
+		if loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return window{}, fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+		}
+	}
+	label := start.Format("15:04") + "-" + end.Format("15:04")
+	if w.Timezone != "" {
+		label += " " + w.Timezone
+	}
+	return window{
+		start: start.Hour()*60 + start.Minute(),
+		end:   end.Hour()*60 + end.Minute(),
+		loc:   loc,
+		label: label,
+	}, nil
+}
+
+// contains reports whether t falls within w. Start is inclusive, End
+// exclusive.
+func (w window) contains(t time.Time) bool {
+	t = t.In(w.loc)
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return minute >= w.start && minute < w.end
+	}
+	// The window passes midnight.
+	return minute >= w.start || minute < w.end
+}
+
+// Status reports whether maintenance is on, and why.
+func (m *Mode) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+	var st Status
+	if m.state != nil {
+		st.Manual = m.state.GetMaintenance()
+	}
+	now := m.now()
+	for _, w := range m.windows {
+		if w.contains(now) {
+			st.Window = w.label
+			break
+		}
+	}
+	st.Active = st.Manual || st.Window != ""
+	return st
+}
+
+// Active reports whether maintenance is on.
+func (m *Mode) Active() bool {
+	return m.Status().Active
+}
+
+// SkipReason returns "maintenance" while maintenance is on and "" otherwise,
+// for the cron and heartbeat services to note in their history.
+func (m *Mode) SkipReason() string {
+	if m.Active() {
+		return "maintenance"
+	}
+	return ""
+}
+
+// Set switches maintenance on or off by hand. The scheduled windows apply
+// regardless.
+func (m *Mode) Set(on bool) error {
+	if m == nil || m.state == nil {
+		return fmt.Errorf("maintenance mode needs a workspace state")
+	}
+	return m.state.SetMaintenance(on)
+}
+
+// Message returns the configured reply, or "" to use the localized notice.
+func (m *Mode) Message() string {
+	if m == nil {
+		return ""
+	}
+	return m.message
+}
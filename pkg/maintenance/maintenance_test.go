@@ -0,0 +1,93 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/state"
+)
+
+func TestMode_Schedule(t *testing.T) {
+	m, err := New(config.MaintenanceConfig{
+		Schedule: []config.MaintenanceWindow{
+			{Start: "02:00", End: "04:00", Timezone: "UTC"},
+			{Start: "23:30", End: "00:15", Timezone: "UTC"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		at     string
+		window string
+	}{
+		{"01:59", ""},
+		{"02:00", "02:00-04:00 UTC"},
+		{"03:59", "02:00-04:00 UTC"},
+		{"04:00", ""},
+		{"23:45", "23:30-00:15 UTC"},
+		{"00:10", "23:30-00:15 UTC"},
+		{"00:15", ""},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse("15:04", tt.at)
+		m.now = func() time.Time { return time.Date(2026, 3, 1, at.Hour(), at.Minute(), 0, 0, time.UTC) }
+		st := m.Status()
+		if st.Window != tt.window || st.Active != (tt.window != "") || st.Manual {
+			t.Errorf("Status() at %s = %+v, want window %q", tt.at, st, tt.window)
+		}
+	}
+}
+
+func TestMode_ManualSurvivesRestart(t *testing.T) {
+	workspace := t.TempDir()
+	m, err := New(config.MaintenanceConfig{}, state.NewManager(workspace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Active() || m.SkipReason() != "" {
+		t.Fatal("maintenance on before being switched on")
+	}
+	if err := m.Set(true); err != nil {
+		t.Fatalf("Set(true) error = %v", err)
+	}
+
+	restarted, _ := New(config.MaintenanceConfig{}, state.NewManager(workspace))
+	if st := restarted.Status(); !st.Active || !st.Manual {
+		t.Errorf("Status() after restart = %+v, want manual maintenance", st)
+	}
+	if got := restarted.SkipReason(); got != "maintenance" {
+		t.Errorf("SkipReason() = %q, want maintenance", got)
+	}
+
+	if err := restarted.Set(false); err != nil {
+		t.Fatal(err)
+	}
+	if restarted.Active() {
+		t.Error("maintenance still on after Set(false)")
+	}
+}
+
+func TestMode_Nil(t *testing.T) {
+	var m *Mode
+	if m.Active() || m.Message() != "" {
+		t.Error("nil Mode should never be active")
+	}
+	if err := m.Set(true); err == nil {
+		t.Error("Set() on nil Mode should fail")
+	}
+}
+
+func TestNew_InvalidSchedule(t *testing.T) {
+	for _, w := range []config.MaintenanceWindow{
+		{Start: "2am", End: "04:00"},
+		{Start: "02:00", End: "02:00"},
+		{Start: "02:00", End: "04:00", Timezone: "Mars/Olympus"},
+	} {
+		if _, err := New(config.MaintenanceConfig{Schedule: []config.MaintenanceWindow{w}}, nil); err == nil {
+			t.Errorf("New(%+v) succeeded, want error", w)
+		}
+	}
+}
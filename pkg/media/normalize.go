@@ -0,0 +1,410 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// ImageOptions controls how downloaded images are normalized before they
+// are handed to the agent and, from there, to the LLM provider.
+type ImageOptions struct {
+	// MaxDimension is the longest side in pixels; larger images are
+	// downscaled. 0 keeps the size.
+	MaxDimension int
+}
+
+// jpegQuality is used whenever an image has to be re-encoded.
+const jpegQuality = 90
+
+var imageOptions atomic.Pointer[ImageOptions]
+
+// SetImageNormalization enables NormalizeImage with opts; nil disables it.
+func SetImageNormalization(opts *ImageOptions) {
+	imageOptions.Store(opts)
+}
+
+// NormalizeImage normalizes the image at path with the options set by
+// SetImageNormalization, and returns the path of the result. Files that are
+// not images, or that cannot be normalized, are left alone and path is
+// returned; failures are only logged.
+func NormalizeImage(path string) string {
+	opts := imageOptions.Load()
+	if opts == nil {
+		return path
+	}
+	normalized, err := opts.Normalize(path)
+	if err != nil {
+		logger.WarnCF("media", "Failed to normalize image, keeping the original", map[string]any{
+			"path":  path,
+			"error": err.Error(),
+		})
+		return path
+	}
+	return normalized
+}
+
+// Normalize rewrites the image at path without metadata such as EXIF GPS
+// tags. JPEG and PNG files are stripped in place without re-encoding, unless
+// they have to be downscaled or, for JPEG, rotated upright first, since the
+// EXIF orientation is dropped with the rest. Other formats with a registered
+// decoder (e.g. HEIC) are converted to JPEG, at path with a .jpg extension;
+// formats without one, and GIFs, are left unchanged. It returns the path of
+// the normalized file.
+func (o ImageOptions) Normalize(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, err
+	}
+
+	var out []byte
+	outPath := path
+	format := sniffImage(data)
+	switch format {
+	case "jpeg":
+		out, err = o.normalizeJPEG(data)
+	case "png":
+		out, err = o.normalizePNG(data)
+	case "gif", "":
+		return path, nil
+	default:
+		out, err = o.convertToJPEG(data)
+		if out == nil && err == nil {
+			logger.DebugCF("media", "No decoder for image format, keeping it as is", map[string]any{
+				"path":   path,
+				"format": format,
+			})
+			return path, nil
+		}
+		outPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".jpg"
+	}
+	if err != nil {
+		return path, err
+	}
+	if outPath == path && bytes.Equal(out, data) {
+		return path, nil
+	}
+
+	tmp := outPath + ".tmp"
+	if err := os.WriteFile(tmp, out, 0o600); err != nil {
+		return path, fmt.Errorf("failed to write normalized image: %w", err)
+	}
+	if err := os.Rename(tmp, outPath); err != nil {
+		os.Remove(tmp)
+		return path, fmt.Errorf("failed to replace image: %w", err)
+	}
+	if outPath != path {
+		os.Remove(path)
+	}
+
+	fields := map[string]any{
+		"path":             outPath,
+		"format":           format,
+		"original_bytes":   len(data),
+		"normalized_bytes": len(out),
+	}
+	if before, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		fields["original_size"] = fmt.Sprintf("%dx%d", before.Width, before.Height)
+	}
+	if after, _, err := image.DecodeConfig(bytes.NewReader(out)); err == nil {
+		fields["normalized_size"] = fmt.Sprintf("%dx%d", after.Width, after.Height)
+	}
+	logger.DebugCF("media", "Normalized image", fields)
+	return outPath, nil
+}
+
+// sniffImage returns the format of an image file: jpeg, png, gif, heic or
+// the name of another registered decoder, or "" for anything else.
+func sniffImage(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return "gif"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		switch string(data[8:12]) {
+		case "heic", "heix", "hevc", "heim", "heis", "mif1", "msf1":
+			return "heic"
+		}
+	}
+	if _, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		return format
+	}
+	return ""
+}
+
+// normalizeJPEG drops the APP1 (EXIF, XMP), APP13 (IPTC) and comment
+// segments of a JPEG, re-encoding it only to downscale or rotate it.
+func (o ImageOptions) normalizeJPEG(data []byte) ([]byte, error) {
+	segments, err := jpegSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	orientation := 1
+	for _, seg := range segments {
+		if seg.marker == 0xE1 && bytes.HasPrefix(seg.payload, []byte("Exif\x00\x00")) {
+			orientation = exifOrientation(seg.payload[6:])
+		}
+	}
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JPEG: %w", err)
+	}
+	if orientation != 1 || o.tooLarge(cfg.Width, cfg.Height) {
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JPEG: %w", err)
+		}
+		upright := o.resize(orient(toRGBA(img), orientation))
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, upright, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:2]) // SOI
+	for _, seg := range segments {
+		if seg.marker == 0xE1 || seg.marker == 0xED || seg.marker == 0xFE {
+			continue
+		}
+		buf.Write(seg.raw)
+	}
+	return buf.Bytes(), nil
+}
+
+type jpegSegment struct {
+	marker  byte
+	payload []byte // without marker and length
+	raw     []byte // the whole segment; for SOS, the rest of the file
+}
+
+// jpegSegments splits a JPEG after its SOI marker into segments, up to and
+// including the start of scan, which holds the rest of the file.
+func jpegSegments(data []byte) ([]jpegSegment, error) {
+	var segments []jpegSegment
+	for i := 2; i < len(data); {
+		if data[i] != 0xFF || i+1 >= len(data) {
+			return nil, fmt.Errorf("invalid JPEG: bad marker at offset %d", i)
+		}
+		marker := data[i+1]
+		if marker == 0xFF { // fill byte
+			i++
+			continue
+		}
+		if marker == 0xD8 || marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			segments = append(segments, jpegSegment{marker: marker, raw: data[i : i+2]})
+			i += 2
+			continue
+		}
+		if marker == 0xD9 {
+			segments = append(segments, jpegSegment{marker: marker, raw: data[i:]})
+			break
+		}
+		if i+4 > len(data) {
+			return nil, fmt.Errorf("invalid JPEG: truncated segment at offset %d", i)
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return nil, fmt.Errorf("invalid JPEG: truncated segment at offset %d", i)
+		}
+		if marker == 0xDA {
+			segments = append(segments, jpegSegment{marker: marker, payload: data[i+4 : end], raw: data[i:]})
+			break
+		}
+		segments = append(segments, jpegSegment{marker: marker, payload: data[i+4 : end], raw: data[i:end]})
+		i = end
+	}
+	return segments, nil
+}
+
+// exifOrientation returns the orientation tag (1 to 8) of a TIFF-structured
+// EXIF block, or 1 when it has none.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := range count {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// normalizePNG drops the eXIf and text chunks of a PNG, re-encoding it only
+// to downscale it.
+func (o ImageOptions) normalizePNG(data []byte) ([]byte, error) {
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PNG: %w", err)
+	}
+	if o.tooLarge(cfg.Width, cfg.Height) {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PNG: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, o.resize(toRGBA(img))); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:8])
+	for i := 8; i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if end > len(data) {
+			return nil, fmt.Errorf("invalid PNG: truncated chunk at offset %d", i)
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			buf.Write(data[i:end])
+		}
+		i = end
+	}
+	return buf.Bytes(), nil
+}
+
+// convertToJPEG re-encodes an image in a format LLM providers may not
+// accept. It returns nil and no error when no decoder is registered.
+func (o ImageOptions) convertToJPEG(data []byte) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, o.resize(toRGBA(img)), &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (o ImageOptions) tooLarge(width, height int) bool {
+	return o.MaxDimension > 0 && max(width, height) > o.MaxDimension
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+	return rgba
+}
+
+// orient turns an image with EXIF orientation o upright.
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o < 2 || o > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch o {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+	return dst
+}
+
+// resize scales src down so its longest side is MaxDimension, averaging the
+// source pixels each destination pixel covers.
+func (o ImageOptions) resize(src *image.RGBA) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	if !o.tooLarge(w, h) {
+		return src
+	}
+	dw, dh := o.MaxDimension, h*o.MaxDimension/w
+	if h > w {
+		dw, dh = w*o.MaxDimension/h, o.MaxDimension
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := range dh {
+		y0, y1 := dy*h/dh, max((dy+1)*h/dh, dy*h/dh+1)
+		for dx := range dw {
+			x0, x1 := dx*w/dw, max((dx+1)*w/dw, dx*w/dw+1)
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[src.PixOffset(x0, y):]
+				for x := 0; x < x1-x0; x++ {
+					for c := range 4 {
+						sum[c] += int(row[4*x+c])
+					}
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			px := dst.Pix[dst.PixOffset(dx, dy):]
+			for c := range 4 {
+				px[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
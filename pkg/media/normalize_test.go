@@ -0,0 +1,250 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// gpsLatitude is a marker written into the GPS IFD of the fixtures, so a
+// test can tell whether any of it survived.
+var gpsLatitude = []byte("GPSLAT:37.7749N")
+
+// exifWithGPS returns a little-endian TIFF block with an orientation tag
+// and a GPS IFD, as a phone camera writes it.
+func exifWithGPS(orientation uint16) []byte {
+	le := binary.LittleEndian
+	var b bytes.Buffer
+	b.WriteString("II")
+	binary.Write(&b, le, uint16(42))
+	binary.Write(&b, le, uint32(8)) // IFD0 offset
+
+	// IFD0: Orientation and the GPSInfo pointer.
+	gpsIFD := uint32(8 + 2 + 2*12 + 4)
+	binary.Write(&b, le, uint16(2))
+	binary.Write(&b, le, []uint16{0x0112, 3})
+	binary.Write(&b, le, uint32(1))
+	binary.Write(&b, le, []uint16{orientation, 0})
+	binary.Write(&b, le, []uint16{0x8825, 4})
+	binary.Write(&b, le, uint32(1))
+	binary.Write(&b, le, gpsIFD)
+	binary.Write(&b, le, uint32(0))
+
+	// GPS IFD: GPSLatitudeRef, and the latitude as an ASCII marker.
+	data := gpsIFD + 2 + 2*12 + 4
+	binary.Write(&b, le, uint16(2))
+	binary.Write(&b, le, []uint16{0x0001, 2})
+	binary.Write(&b, le, uint32(2))
+	b.WriteString("N\x00\x00\x00")
+	binary.Write(&b, le, []uint16{0x0002, 2})
+	binary.Write(&b, le, uint32(len(gpsLatitude)))
+	binary.Write(&b, le, data)
+	binary.Write(&b, le, uint32(0))
+	b.Write(gpsLatitude)
+	return b.Bytes()
+}
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{uint8(x * 255 / w), uint8(y * 255 / h), 128, 255})
+		}
+	}
+	// A white marker in the top-left corner, to check rotation.
+	img.Set(0, 0, color.White)
+	img.Set(1, 0, color.White)
+	img.Set(0, 1, color.White)
+	img.Set(1, 1, color.White)
+	return img
+}
+
+// writeJPEGWithGPS writes a w x h JPEG with an EXIF APP1 segment holding
+// orientation and GPS tags.
+func writeJPEGWithGPS(t *testing.T, w, h int, orientation uint16) string {
+	t.Helper()
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, testImage(w, h), &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	payload := append([]byte("Exif\x00\x00"), exifWithGPS(orientation)...)
+	var out bytes.Buffer
+	out.Write(enc.Bytes()[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(&out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+	out.Write(enc.Bytes()[2:])
+
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint32(len(data)))
+	b.WriteString(typ)
+	b.Write(data)
+	binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+	return b.Bytes()
+}
+
+// writePNGWithGPS writes a PNG with an eXIf chunk and a text chunk, both
+// carrying the GPS marker.
+func writePNGWithGPS(t *testing.T, w, h int) string {
+	t.Helper()
+	var enc bytes.Buffer
+	if err := png.Encode(&enc, testImage(w, h)); err != nil {
+		t.Fatal(err)
+	}
+	ihdrEnd := 8 + 12 + 13
+	var out bytes.Buffer
+	out.Write(enc.Bytes()[:ihdrEnd])
+	out.Write(pngChunk("eXIf", exifWithGPS(1)))
+	out.Write(pngChunk("tEXt", append([]byte("Location\x00"), gpsLatitude...)))
+	out.Write(enc.Bytes()[ihdrEnd:])
+
+	path := filepath.Join(t.TempDir(), "screenshot.png")
+	if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readNormalized(t *testing.T, path string) ([]byte, image.Image) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, gpsLatitude) || bytes.Contains(data, []byte("Exif\x00\x00")) {
+		t.Error("metadata survived normalization")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("normalized image does not decode: %v", err)
+	}
+	return data, img
+}
+
+func TestNormalizeJPEGStripsGPSWithoutReencoding(t *testing.T) {
+	path := writeJPEGWithGPS(t, 64, 48, 1)
+	before, _ := os.ReadFile(path)
+
+	got, err := ImageOptions{MaxDimension: 2048}.Normalize(path)
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if got != path {
+		t.Errorf("path = %q, want %q", got, path)
+	}
+	data, img := readNormalized(t, got)
+	if img.Bounds().Dx() != 64 || img.Bounds().Dy() != 48 {
+		t.Errorf("size = %v, want 64x48", img.Bounds())
+	}
+	// Only the EXIF segment is gone; the scan data is untouched.
+	if !bytes.HasSuffix(before, data[2:]) {
+		t.Error("JPEG was re-encoded although nothing but metadata changed")
+	}
+}
+
+func TestNormalizeJPEGAppliesOrientation(t *testing.T) {
+	path := writeJPEGWithGPS(t, 64, 48, 6) // needs a 90° clockwise turn
+
+	got, err := ImageOptions{}.Normalize(path)
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	_, img := readNormalized(t, got)
+	if img.Bounds().Dx() != 48 || img.Bounds().Dy() != 64 {
+		t.Fatalf("size = %v, want 48x64", img.Bounds())
+	}
+	// The white top-left corner ends up top-right.
+	if r, g, b, _ := img.At(47, 0).RGBA(); r < 0xD000 || g < 0xD000 || b < 0xD000 {
+		t.Errorf("top-right pixel = %v, want white", img.At(47, 0))
+	}
+}
+
+func TestNormalizeDownscales(t *testing.T) {
+	path := writeJPEGWithGPS(t, 400, 100, 1)
+
+	got, err := ImageOptions{MaxDimension: 200}.Normalize(path)
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	_, img := readNormalized(t, got)
+	if img.Bounds().Dx() != 200 || img.Bounds().Dy() != 50 {
+		t.Errorf("size = %v, want 200x50", img.Bounds())
+	}
+}
+
+func TestNormalizePNG(t *testing.T) {
+	path := writePNGWithGPS(t, 30, 20)
+	got, err := ImageOptions{MaxDimension: 2048}.Normalize(path)
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	_, img := readNormalized(t, got)
+	if img.Bounds().Dx() != 30 || img.Bounds().Dy() != 20 {
+		t.Errorf("size = %v, want 30x20", img.Bounds())
+	}
+
+	path = writePNGWithGPS(t, 300, 200)
+	got, err = ImageOptions{MaxDimension: 150}.Normalize(path)
+	if err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	_, img = readNormalized(t, got)
+	if img.Bounds().Dx() != 150 || img.Bounds().Dy() != 100 {
+		t.Errorf("size = %v, want 150x100", img.Bounds())
+	}
+}
+
+func TestNormalizeLeavesOtherFilesAlone(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"notes.txt": []byte("GPSLAT:37.7749N is where we met"),
+		// An HEIC header; no HEIC decoder is compiled into the tests.
+		"photo.heic": append([]byte{0, 0, 0, 24}, []byte("ftypheic\x00\x00\x00\x00mif1heic")...),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ImageOptions{MaxDimension: 100}.Normalize(path)
+		if err != nil || got != path {
+			t.Errorf("%s: Normalize = %q, %v; want it unchanged", name, got, err)
+		}
+		if data, _ := os.ReadFile(path); !bytes.Equal(data, content) {
+			t.Errorf("%s: content changed", name)
+		}
+	}
+}
+
+func TestNormalizeImageDisabled(t *testing.T) {
+	path := writeJPEGWithGPS(t, 16, 16, 1)
+	before, _ := os.ReadFile(path)
+
+	SetImageNormalization(nil)
+	if got := NormalizeImage(path); got != path {
+		t.Errorf("path = %q, want %q", got, path)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("image changed with normalization disabled")
+	}
+
+	SetImageNormalization(&ImageOptions{})
+	t.Cleanup(func() { SetImageNormalization(nil) })
+	NormalizeImage(path)
+	readNormalized(t, path)
+}
@@ -31,6 +31,10 @@ type MediaStore interface {
 	// ResolveWithMeta returns the local file path and metadata for a given ref.
 	ResolveWithMeta(ref string) (localPath string, meta MediaMeta, err error)
 
+	// Release deletes the file registered under ref and forgets the ref.
+	// An unknown ref is not an error.
+	Release(ref string) error
+
 	// ReleaseAll deletes all files registered under the given scope
 	// and removes the mapping entries. File-not-exist errors are ignored.
 	ReleaseAll(scope string) error
@@ -132,6 +136,32 @@ func (s *FileMediaStore) ResolveWithMeta(ref string) (string, MediaMeta, error)
 	return entry.path, entry.meta, nil
 }
 
+// Release removes the file registered under ref and its mappings.
+func (s *FileMediaStore) Release(ref string) error {
+	s.mu.Lock()
+	entry, ok := s.refs[ref]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	if scope, ok := s.refToScope[ref]; ok {
+		if scopeRefs, ok := s.scopeToRefs[scope]; ok {
+			delete(scopeRefs, ref)
+			if len(scopeRefs) == 0 {
+				delete(s.scopeToRefs, scope)
+			}
+		}
+	}
+	delete(s.refs, ref)
+	delete(s.refToScope, ref)
+	s.mu.Unlock()
+
+	if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // ReleaseAll removes all files under the given scope and cleans up mappings.
 // Phase 1 (under lock): remove entries from maps.
 // Phase 2 (no lock): delete files from disk.
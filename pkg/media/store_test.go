@@ -77,6 +77,39 @@ func TestReleaseAll(t *testing.T) {
 	}
 }
 
+func TestRelease(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileMediaStore()
+
+	gone := createTempFile(t, dir, "virus.exe")
+	kept := createTempFile(t, dir, "photo.jpg")
+	goneRef, _ := store.Store(gone, MediaMeta{Source: "test"}, "scope1")
+	keptRef, _ := store.Store(kept, MediaMeta{Source: "test"}, "scope1")
+
+	if err := store.Release(goneRef); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := os.Stat(gone); !os.IsNotExist(err) {
+		t.Error("released file should have been deleted")
+	}
+	if _, err := store.Resolve(goneRef); err == nil {
+		t.Error("released ref should be unresolvable")
+	}
+	if err := store.Release(goneRef); err != nil {
+		t.Errorf("second Release failed: %v", err)
+	}
+
+	if _, err := store.Resolve(keptRef); err != nil {
+		t.Errorf("other ref in the scope should still resolve: %v", err)
+	}
+	if err := store.ReleaseAll("scope1"); err != nil {
+		t.Fatalf("ReleaseAll failed: %v", err)
+	}
+	if _, err := os.Stat(kept); !os.IsNotExist(err) {
+		t.Error("ReleaseAll should still delete the rest of the scope")
+	}
+}
+
 func TestMultiScopeIsolation(t *testing.T) {
 	dir := t.TempDir()
 	store := NewFileMediaStore()
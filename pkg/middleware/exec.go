@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+const defaultExecTimeout = 10 * time.Second
+
+// Exec pipes each message as JSON through an external command. The command
+// gets the direction in PICOCLAW_MIDDLEWARE_DIRECTION and may print:
+//   - nothing, to pass the message on unchanged;
+//   - {"drop": true}, to drop it;
+//   - a JSON message, whose fields replace those of the original.
+//
+// A non-zero exit, a timeout or unparsable output is an error.
+type Exec struct {
+	name    string
+	command []string
+	timeout time.Duration
+}
+
+// NewExec returns a middleware running command with the given timeout in
+// seconds (10 if not positive).
+func NewExec(name string, command []string, timeoutSeconds int) (*Exec, error) {
+	if len(command) == 0 || command[0] == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+	timeout := defaultExecTimeout
+	if timeoutSeconds > 0 {
+		timeout = time.Duration(timeoutSeconds) * time.Second
+	}
+	return &Exec{name: name, command: command, timeout: timeout}, nil
+}
+
+func (e *Exec) Name() string { return e.name }
+
+func (e *Exec) OnInbound(ctx context.Context, msg bus.InboundMessage) (bus.InboundMessage, bus.Decision, error) {
+	return runExec(ctx, e, "inbound", msg)
+}
+
+func (e *Exec) OnOutbound(ctx context.Context, msg bus.OutboundMessage) (bus.OutboundMessage, bus.Decision, error) {
+	return runExec(ctx, e, "outbound", msg)
+}
+
+func runExec[M bus.InboundMessage | bus.OutboundMessage](
+	ctx context.Context,
+	e *Exec,
+	direction string,
+	msg M,
+) (M, bus.Decision, error) {
+	input, err := json.Marshal(msg)
+	if err != nil {
+		return msg, bus.Pass, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.command[0], e.command[1:]...)
+	cmd.Env = append(os.Environ(), "PICOCLAW_MIDDLEWARE_DIRECTION="+direction)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return msg, bus.Pass, fmt.Errorf("command timed out after %s", e.timeout)
+		}
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return msg, bus.Pass, fmt.Errorf("command failed: %w: %s", err, detail)
+		}
+		return msg, bus.Pass, fmt.Errorf("command failed: %w", err)
+	}
+
+	output := bytes.TrimSpace(stdout.Bytes())
+	if len(output) == 0 {
+		return msg, bus.Pass, nil
+	}
+
+	var verdict struct {
+		Drop bool `json:"drop"`
+	}
+	if err := json.Unmarshal(output, &verdict); err != nil {
+		return msg, bus.Pass, fmt.Errorf("command printed invalid JSON: %w", err)
+	}
+	if verdict.Drop {
+		return msg, bus.Drop, nil
+	}
+
+	// Fields the command leaves out keep their original values.
+	updated := msg
+	if err := json.Unmarshal(output, &updated); err != nil {
+		return msg, bus.Pass, fmt.Errorf("command printed an invalid message: %w", err)
+	}
+	return updated, bus.Pass, nil
+}
@@ -0,0 +1,100 @@
+// Package middleware provides the built-in gateway message middlewares and
+// builds the bus middleware chain from gateway.middleware.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+// BuildChain creates the middleware chain described by cfgs, in order.
+// Translation middlewares call provider, using defaultModel unless their
+// entry names another model.
+func BuildChain(
+	cfgs []config.MiddlewareConfig,
+	provider providers.LLMProvider,
+	defaultModel string,
+) (*bus.MiddlewareChain, error) {
+	chain := &bus.MiddlewareChain{}
+	for i, c := range cfgs {
+		mw, err := build(c, provider, defaultModel)
+		if err != nil {
+			return nil, fmt.Errorf("gateway.middleware[%d] (%s): %w", i, c.Type, err)
+		}
+		scoped, err := scope(mw, c)
+		if err != nil {
+			return nil, fmt.Errorf("gateway.middleware[%d] (%s): %w", i, c.Type, err)
+		}
+		chain.Add(scoped, c.FailClosed)
+	}
+	return chain, nil
+}
+
+func build(c config.MiddlewareConfig, provider providers.LLMProvider, defaultModel string) (bus.Middleware, error) {
+	name := c.Name
+	if name == "" {
+		name = c.Type
+	}
+	switch c.Type {
+	case "regex":
+		return NewRegexFilter(name, c.Patterns, c.Action, c.Replacement)
+	case "translate":
+		model := c.Model
+		if model == "" {
+			model = defaultModel
+		}
+		return NewTranslator(name, provider, model, c.TargetLanguage)
+	case "exec":
+		return NewExec(name, c.Command, c.TimeoutSeconds)
+	default:
+		return nil, fmt.Errorf("unknown middleware type %q (want regex, translate or exec)", c.Type)
+	}
+}
+
+// scoped limits a middleware to one direction and/or some channels.
+type scoped struct {
+	bus.Middleware
+	inbound  bool
+	outbound bool
+	channels []string
+}
+
+func scope(mw bus.Middleware, c config.MiddlewareConfig) (bus.Middleware, error) {
+	s := &scoped{Middleware: mw, channels: c.Channels}
+	switch c.Direction {
+	case "", "both":
+		s.inbound, s.outbound = true, true
+	case "inbound":
+		s.inbound = true
+	case "outbound":
+		s.outbound = true
+	default:
+		return nil, fmt.Errorf("invalid direction %q (want inbound, outbound or both)", c.Direction)
+	}
+	return s, nil
+}
+
+func (s *scoped) applies(channel, chatID string) bool {
+	return len(s.channels) == 0 ||
+		slices.Contains(s.channels, channel) ||
+		slices.Contains(s.channels, channel+":"+chatID)
+}
+
+func (s *scoped) OnInbound(ctx context.Context, msg bus.InboundMessage) (bus.InboundMessage, bus.Decision, error) {
+	if !s.inbound || !s.applies(msg.Channel, msg.ChatID) {
+		return msg, bus.Pass, nil
+	}
+	return s.Middleware.OnInbound(ctx, msg)
+}
+
+func (s *scoped) OnOutbound(ctx context.Context, msg bus.OutboundMessage) (bus.OutboundMessage, bus.Decision, error) {
+	if !s.outbound || !s.applies(msg.Channel, msg.ChatID) {
+		return msg, bus.Pass, nil
+	}
+	return s.Middleware.OnOutbound(ctx, msg)
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+type echoProvider struct {
+	model string
+}
+
+func (p *echoProvider) Chat(
+	_ context.Context, messages []providers.Message, _ []providers.ToolDefinition, model string, _ map[string]any,
+) (*providers.LLMResponse, error) {
+	p.model = model
+	return &providers.LLMResponse{Content: "[fr] " + messages[len(messages)-1].Content}, nil
+}
+
+func (p *echoProvider) GetDefaultModel() string { return "echo" }
+
+func TestBuildChain_ScopesByDirectionAndChannel(t *testing.T) {
+	chain, err := BuildChain([]config.MiddlewareConfig{{
+		Type:      "regex",
+		Direction: "inbound",
+		Channels:  []string{"telegram:kids"},
+		Patterns:  []string{`(?i)\bdarn\b`},
+	}}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	in, _ := chain.Inbound(ctx, bus.InboundMessage{Channel: "telegram", ChatID: "kids", Content: "Darn it"})
+	if in.Content != "*** it" {
+		t.Errorf("kids chat inbound = %q, want redacted", in.Content)
+	}
+	in, _ = chain.Inbound(ctx, bus.InboundMessage{Channel: "telegram", ChatID: "adults", Content: "Darn it"})
+	if in.Content != "Darn it" {
+		t.Errorf("other chat inbound = %q, want unchanged", in.Content)
+	}
+	out, _ := chain.Outbound(ctx, bus.OutboundMessage{Channel: "telegram", ChatID: "kids", Content: "Darn it"})
+	if out.Content != "Darn it" {
+		t.Errorf("outbound = %q, want unchanged for an inbound-only filter", out.Content)
+	}
+}
+
+func TestBuildChain_RejectsInvalidConfig(t *testing.T) {
+	tests := []config.MiddlewareConfig{
+		{Type: "unknown"},
+		{Type: "regex"},
+		{Type: "regex", Patterns: []string{"("}},
+		{Type: "regex", Patterns: []string{"x"}, Action: "explode"},
+		{Type: "regex", Patterns: []string{"x"}, Direction: "sideways"},
+		{Type: "translate"},
+		{Type: "exec"},
+	}
+	for _, c := range tests {
+		if _, err := BuildChain([]config.MiddlewareConfig{c}, &echoProvider{}, "m"); err == nil {
+			t.Errorf("BuildChain(%+v) succeeded, want error", c)
+		}
+	}
+}
+
+func TestRegexFilter_Drop(t *testing.T) {
+	f, err := NewRegexFilter("spam", []string{"buy now"}, "drop", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, d, _ := f.OnInbound(context.Background(), bus.InboundMessage{Content: "buy now!"}); d != bus.Drop {
+		t.Error("matching message should be dropped")
+	}
+	if _, d, _ := f.OnInbound(context.Background(), bus.InboundMessage{Content: "hello"}); d != bus.Pass {
+		t.Error("other message should pass")
+	}
+}
+
+func TestTranslator(t *testing.T) {
+	provider := &echoProvider{}
+	tr, err := NewTranslator("translate", provider, "cheap", "French")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := tr.OnOutbound(context.Background(), bus.OutboundMessage{Content: "hello"})
+	if err != nil || out.Content != "[fr] hello" || provider.model != "cheap" {
+		t.Errorf("OnOutbound = %q, %v (model %q)", out.Content, err, provider.model)
+	}
+	in, _, _ := tr.OnInbound(context.Background(), bus.InboundMessage{Content: "/status"})
+	if in.Content != "/status" {
+		t.Errorf("commands must not be translated, got %q", in.Content)
+	}
+}
+
+func TestExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "mw.sh")
+	body := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *drop-me*) echo '{"drop": true}' ;;
+  *rewrite*) echo '{"content": "rewritten '"$PICOCLAW_MIDDLEWARE_DIRECTION"'"}' ;;
+  *fail*) echo "nope" >&2; exit 3 ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewExec("script", []string{script}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	in, d, err := e.OnInbound(ctx, bus.InboundMessage{Channel: "cli", Content: "please rewrite"})
+	if err != nil || d != bus.Pass || in.Content != "rewritten inbound" || in.Channel != "cli" {
+		t.Errorf("rewrite: %+v, %v, %v", in, d, err)
+	}
+	if _, d, _ := e.OnOutbound(ctx, bus.OutboundMessage{Content: "drop-me"}); d != bus.Drop {
+		t.Error("drop verdict ignored")
+	}
+	out, _, err := e.OnOutbound(ctx, bus.OutboundMessage{Content: "keep"})
+	if err != nil || out.Content != "keep" {
+		t.Errorf("empty output should pass through, got %q, %v", out.Content, err)
+	}
+	if _, _, err := e.OnOutbound(ctx, bus.OutboundMessage{Content: "fail"}); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("failing command error = %v", err)
+	}
+}
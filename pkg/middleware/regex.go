@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+)
+
+// RegexFilter redacts, or drops messages containing, text matching any of
+// its patterns.
+type RegexFilter struct {
+	name        string
+	patterns    []*regexp.Regexp
+	drop        bool
+	replacement string
+}
+
+// NewRegexFilter compiles patterns. action is "redact" (the default) or
+// "drop"; redacted matches become replacement, "***" if empty.
+func NewRegexFilter(name string, patterns []string, action, replacement string) (*RegexFilter, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("patterns is required")
+	}
+	f := &RegexFilter{name: name, replacement: replacement}
+	switch action {
+	case "", "redact":
+	case "drop":
+		f.drop = true
+	default:
+		return nil, fmt.Errorf("invalid action %q (want redact or drop)", action)
+	}
+	if f.replacement == "" {
+		f.replacement = "***"
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+	return f, nil
+}
+
+func (f *RegexFilter) Name() string { return f.name }
+
+func (f *RegexFilter) OnInbound(_ context.Context, msg bus.InboundMessage) (bus.InboundMessage, bus.Decision, error) {
+	content, decision := f.filter(msg.Content)
+	msg.Content = content
+	return msg, decision, nil
+}
+
+func (f *RegexFilter) OnOutbound(_ context.Context, msg bus.OutboundMessage) (bus.OutboundMessage, bus.Decision, error) {
+	content, decision := f.filter(msg.Content)
+	msg.Content = content
+	return msg, decision, nil
+}
+
+func (f *RegexFilter) filter(content string) (string, bus.Decision) {
+	for _, re := range f.patterns {
+		if !re.MatchString(content) {
+			continue
+		}
+		if f.drop {
+			return content, bus.Drop
+		}
+		content = re.ReplaceAllLiteralString(content, f.replacement)
+	}
+	return content, bus.Pass
+}
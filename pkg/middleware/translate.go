@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/providers"
+)
+
+const translatePrompt = `Translate the user's message into %s. Keep formatting, code, URLs and names unchanged. If it is already in %s, return it unchanged. Reply with only the translation.`
+
+// Translator translates message text into a target language with the LLM.
+type Translator struct {
+	name     string
+	provider providers.LLMProvider
+	model    string
+	language string
+}
+
+// NewTranslator returns a middleware translating into language via model.
+func NewTranslator(name string, provider providers.LLMProvider, model, language string) (*Translator, error) {
+	if language == "" {
+		return nil, fmt.Errorf("target_language is required")
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("no LLM provider available")
+	}
+	return &Translator{name: name, provider: provider, model: model, language: language}, nil
+}
+
+func (t *Translator) Name() string { return t.name }
+
+func (t *Translator) OnInbound(ctx context.Context, msg bus.InboundMessage) (bus.InboundMessage, bus.Decision, error) {
+	// Slash commands are handled by the agent verbatim.
+	if strings.HasPrefix(strings.TrimSpace(msg.Content), "/") {
+		return msg, bus.Pass, nil
+	}
+	content, err := t.translate(ctx, msg.Content)
+	if err != nil {
+		return msg, bus.Pass, err
+	}
+	msg.Content = content
+	return msg, bus.Pass, nil
+}
+
+func (t *Translator) OnOutbound(ctx context.Context, msg bus.OutboundMessage) (bus.OutboundMessage, bus.Decision, error) {
+	content, err := t.translate(ctx, msg.Content)
+	if err != nil {
+		return msg, bus.Pass, err
+	}
+	msg.Content = content
+	return msg, bus.Pass, nil
+}
+
+func (t *Translator) translate(ctx context.Context, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	messages := []providers.Message{
+		{Role: "system", Content: fmt.Sprintf(translatePrompt, t.language, t.language)},
+		{Role: "user", Content: text},
+	}
+	resp, err := t.provider.Chat(ctx, messages, nil, t.model, map[string]any{
+		"max_tokens":  4096,
+		"temperature": 0.2,
+	})
+	if err != nil {
+		return "", fmt.Errorf("translation failed: %w", err)
+	}
+	if strings.TrimSpace(resp.Content) == "" {
+		return "", fmt.Errorf("translation returned no text")
+	}
+	return resp.Content, nil
+}
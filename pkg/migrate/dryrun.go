@@ -0,0 +1,283 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/migrate/internal"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// ConfigChange is one config field a migration would change. Old is empty
+// when the field would be added, New when it would be removed.
+type ConfigChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// FilePlan is a workspace file a migration would copy.
+type FilePlan struct {
+	Path      string // relative to the source home
+	Size      int64
+	Overwrite bool // target exists and would be backed up first
+	Ask       bool // target exists and the user would be asked
+}
+
+// DryRunReport describes what a migration would do without doing it.
+type DryRunReport struct {
+	ConfigTarget  string
+	ConfigExists  bool
+	ConfigChanges []ConfigChange
+	ConfigBytes   int64
+	Files         []FilePlan
+	Warnings      []string
+}
+
+// TotalBytes estimates how much data the migration would write.
+func (r *DryRunReport) TotalBytes() int64 {
+	total := r.ConfigBytes
+	for _, f := range r.Files {
+		total += f.Size
+	}
+	return total
+}
+
+// DryRun builds a report of the planned actions: the config fields that would
+// change (secrets masked) and the workspace files that would be copied.
+func (m *MigrateInstance) DryRun(actions []Action, warnings []string, sourceHome string) (*DryRunReport, error) {
+	handler, err := m.getCurrentHandler()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DryRunReport{Warnings: warnings}
+	for _, action := range actions {
+		switch action.Type {
+		case ActionConvertConfig:
+			if err := report.diffConfig(handler, action); err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("Config diff unavailable: %v", err))
+			}
+		case ActionCopy, ActionBackup, ActionConflict:
+			info, err := os.Stat(action.Source)
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("Cannot stat %s: %v", action.Source, err))
+				continue
+			}
+			report.Files = append(report.Files, FilePlan{
+				Path:      internal.RelPath(action.Source, sourceHome),
+				Size:      info.Size(),
+				Overwrite: action.Type == ActionBackup,
+				Ask:       action.Type == ActionConflict,
+			})
+		}
+	}
+	return report, nil
+}
+
+func (r *DryRunReport) diffConfig(handler Operation, action Action) error {
+	converter, ok := handler.(internal.ConfigConverter)
+	if !ok {
+		return fmt.Errorf("source %q cannot preview config conversion", handler.GetSourceName())
+	}
+	incoming, warnings, err := converter.ConvertConfig(action.Source, action.Target)
+	if err != nil {
+		return err
+	}
+	r.Warnings = append(r.Warnings, warnings...)
+
+	newData, err := json.MarshalIndent(incoming, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode converted config: %w", err)
+	}
+
+	// Without an existing config the migration replaces the defaults.
+	oldData, err := os.ReadFile(action.Target)
+	if err == nil {
+		r.ConfigExists = true
+	} else if os.IsNotExist(err) {
+		if oldData, err = json.Marshal(config.DefaultConfig()); err != nil {
+			return fmt.Errorf("failed to encode default config: %w", err)
+		}
+	} else {
+		return fmt.Errorf("failed to read %s: %w", action.Target, err)
+	}
+
+	changes, err := DiffConfigJSON(oldData, newData)
+	if err != nil {
+		return err
+	}
+	r.ConfigTarget = action.Target
+	r.ConfigChanges = changes
+	r.ConfigBytes = int64(len(newData))
+	return nil
+}
+
+// DiffConfigJSON compares two JSON config documents field by field. Fields
+// are named by their dotted JSON path and secret values are masked.
+func DiffConfigJSON(oldData, newData []byte) ([]ConfigChange, error) {
+	var oldDoc, newDoc any
+	if err := json.Unmarshal(oldData, &oldDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse current config: %w", err)
+	}
+	if err := json.Unmarshal(newData, &newDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse converted config: %w", err)
+	}
+
+	oldFields := make(map[string]string)
+	newFields := make(map[string]string)
+	flattenJSON("", oldDoc, oldFields)
+	flattenJSON("", newDoc, newFields)
+
+	var changes []ConfigChange
+	for field, newVal := range newFields {
+		if oldVal, ok := oldFields[field]; !ok || oldVal != newVal {
+			changes = append(changes, ConfigChange{Field: field, Old: maskField(field, oldVal), New: maskField(field, newVal)})
+		}
+	}
+	for field, oldVal := range oldFields {
+		if _, ok := newFields[field]; !ok {
+			changes = append(changes, ConfigChange{Field: field, Old: maskField(field, oldVal)})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// flattenJSON records every leaf of v under its dotted path. Empty objects
+// and arrays are leaves too, so clearing a list shows up as a change.
+func flattenJSON(path string, v any, out map[string]string) {
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 && path != "" {
+			out[path] = "{}"
+			return
+		}
+		for key, child := range val {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenJSON(childPath, child, out)
+		}
+	case []any:
+		if len(val) == 0 {
+			out[path] = "[]"
+			return
+		}
+		for i, child := range val {
+			flattenJSON(fmt.Sprintf("%s[%d]", path, i), child, out)
+		}
+	default:
+		data, _ := json.Marshal(val)
+		out[path] = string(data)
+	}
+}
+
+// secretFieldSuffixes mark config fields whose values must not be printed.
+var secretFieldSuffixes = []string{"key", "token", "secret", "password"}
+
+// maskField hides the value of secret fields, keeping the last four
+// characters of long values so keys can still be told apart.
+func maskField(field, value string) string {
+	if value == "" || value == `""` {
+		return value
+	}
+	name := field[strings.LastIndex(field, ".")+1:]
+	name = strings.ToLower(name)
+	secret := false
+	for _, suffix := range secretFieldSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			secret = true
+			break
+		}
+	}
+	if !secret {
+		return value
+	}
+	raw := strings.Trim(value, `"`)
+	if len(raw) >= 12 {
+		return `"****` + raw[len(raw)-4:] + `"`
+	}
+	return `"****"`
+}
+
+// PrintDryRun prints a dry-run report as human-readable tables.
+func PrintDryRun(report *DryRunReport) {
+	fmt.Println("Dry run: no changes will be made.")
+
+	if report.ConfigTarget != "" {
+		fmt.Println()
+		if report.ConfigExists {
+			fmt.Printf("Config changes (%s):\n", report.ConfigTarget)
+		} else {
+			fmt.Printf("Config changes from defaults (%s does not exist yet):\n", report.ConfigTarget)
+		}
+		if len(report.ConfigChanges) == 0 {
+			fmt.Println("  No changes.")
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "  FIELD\tCURRENT\tNEW")
+			for _, c := range report.ConfigChanges {
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", c.Field, displayValue(c.Old), displayValue(c.New))
+			}
+			w.Flush()
+		}
+	}
+
+	if len(report.Files) > 0 {
+		fmt.Println()
+		fmt.Println("Workspace files to copy:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  FILE\tSIZE\tACTION")
+		for _, f := range report.Files {
+			action := "copy"
+			switch {
+			case f.Overwrite:
+				action = "overwrite (backup first)"
+			case f.Ask:
+				action = "ask"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", f.Path, formatSize(f.Size), action)
+		}
+		w.Flush()
+	}
+
+	if len(report.Warnings) > 0 {
+		fmt.Println()
+		fmt.Println("Warnings:")
+		for _, warning := range report.Warnings {
+			fmt.Printf("  - %s\n", warning)
+		}
+	}
+
+	fmt.Println()
+	parts := []string{
+		fmt.Sprintf("%d config fields would change", len(report.ConfigChanges)),
+		fmt.Sprintf("%d files would be copied", len(report.Files)),
+	}
+	fmt.Printf("%s, about %s in total.\n", strings.Join(parts, ", "), formatSize(report.TotalBytes()))
+}
+
+func displayValue(v string) string {
+	if v == "" {
+		return "(unset)"
+	}
+	return utils.Truncate(v, 48)
+}
+
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
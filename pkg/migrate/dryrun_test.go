@@ -0,0 +1,104 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestDiffConfigJSON(t *testing.T) {
+	oldData := []byte(`{
+  "agents": {"defaults": {"model_name": "gpt4", "max_tokens": 8192}},
+  "model_list": [{"model_name": "gpt4", "api_key": "sk-old-0000000000001111"}],
+  "tools": {"exec": {"enabled": true}}
+}`)
+	newData := []byte(`{
+  "agents": {"defaults": {"model_name": "claude", "max_tokens": 8192}},
+  "model_list": [{"model_name": "claude", "api_key": "sk-new-0000000000002222"}],
+  "channels": {"telegram": {"token": "short"}}
+}`)
+
+	changes, err := DiffConfigJSON(oldData, newData)
+	require.NoError(t, err)
+
+	assert.Equal(t, []ConfigChange{
+		{Field: "agents.defaults.model_name", Old: `"gpt4"`, New: `"claude"`},
+		{Field: "channels.telegram.token", New: `"****"`},
+		{Field: "model_list[0].api_key", Old: `"****1111"`, New: `"****2222"`},
+		{Field: "model_list[0].model_name", Old: `"gpt4"`, New: `"claude"`},
+		{Field: "tools.exec.enabled", Old: "true"},
+	}, changes)
+}
+
+func TestDryRunReportsConfigAndFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	targetDir := filepath.Join(tmpDir, "target")
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceDir, "workspace"), 0o755))
+	require.NoError(t, os.MkdirAll(targetDir, 0o755))
+
+	src := filepath.Join(sourceDir, "workspace", "AGENTS.md")
+	require.NoError(t, os.WriteFile(src, []byte("0123456789"), 0o644))
+
+	existing := config.DefaultConfig()
+	require.NoError(t, config.SaveConfig(filepath.Join(targetDir, "config.json"), existing))
+
+	incoming := config.DefaultConfig()
+	incoming.Agents.Defaults.ModelName = "migrated"
+
+	instance := &MigrateInstance{
+		options:  Options{Source: "mock"},
+		handlers: make(map[string]Operation),
+	}
+	instance.Register("mock", &mockConverter{mockOperation: mockOperation{sourceHome: sourceDir}, cfg: incoming})
+
+	actions := []Action{
+		{Type: ActionConvertConfig, Source: "openclaw.json", Target: filepath.Join(targetDir, "config.json")},
+		{Type: ActionBackup, Source: src, Target: filepath.Join(targetDir, "workspace", "AGENTS.md")},
+		{Type: ActionSkip, Source: filepath.Join(sourceDir, "workspace", "missing.md")},
+	}
+
+	report, err := instance.DryRun(actions, nil, sourceDir)
+	require.NoError(t, err)
+
+	assert.True(t, report.ConfigExists)
+	assert.Equal(t, []ConfigChange{
+		{Field: "agents.defaults.model_name", New: `"migrated"`},
+	}, report.ConfigChanges)
+	assert.Equal(t, []FilePlan{
+		{Path: filepath.Join("workspace", "AGENTS.md"), Size: 10, Overwrite: true},
+	}, report.Files)
+	assert.Equal(t, report.ConfigBytes+10, report.TotalBytes())
+
+	_, err = os.Stat(filepath.Join(targetDir, "workspace"))
+	assert.True(t, os.IsNotExist(err), "dry run must not write files")
+
+	PrintDryRun(report)
+}
+
+func TestDryRunWithoutConverterWarns(t *testing.T) {
+	instance := &MigrateInstance{
+		options:  Options{Source: "mock"},
+		handlers: make(map[string]Operation),
+	}
+	instance.Register("mock", &mockOperation{})
+
+	report, err := instance.DryRun([]Action{{Type: ActionConvertConfig, Source: "a", Target: "b"}}, nil, "/tmp")
+	require.NoError(t, err)
+	require.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], "Config diff unavailable")
+}
+
+type mockConverter struct {
+	mockOperation
+	cfg *config.Config
+}
+
+func (m *mockConverter) ConvertConfig(string, string) (*config.Config, []string, error) {
+	return m.cfg, nil, nil
+}
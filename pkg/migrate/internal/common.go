@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func ResolveTargetHome(override string) (string, error) {
@@ -39,18 +40,43 @@ func ResolveWorkspace(homeDir string) string {
 	return filepath.Join(homeDir, "workspace")
 }
 
+// ResolveConflictStrategy validates opts.ConflictStrategy and fills in the
+// default. Interactive prompting gives way to overwrite under --force so
+// unattended runs never block on stdin.
+func ResolveConflictStrategy(opts Options) (ConflictStrategy, error) {
+	strategy := opts.ConflictStrategy
+	if strategy == "" {
+		if opts.Force || opts.Refresh {
+			return ConflictOverwrite, nil
+		}
+		return ConflictBackupOverwrite, nil
+	}
+
+	valid := make([]string, 0, len(ConflictStrategies))
+	for _, s := range ConflictStrategies {
+		if s == strategy {
+			if strategy == ConflictInteractive && opts.Force {
+				return ConflictOverwrite, nil
+			}
+			return strategy, nil
+		}
+		valid = append(valid, string(s))
+	}
+	return "", fmt.Errorf("invalid conflict strategy %q (valid: %s)", strategy, strings.Join(valid, ", "))
+}
+
 func PlanWorkspaceMigration(
 	srcWorkspace, dstWorkspace string,
 	migrateableFiles []string,
 	migrateableDirs []string,
-	force bool,
+	strategy ConflictStrategy,
 ) ([]Action, error) {
 	var actions []Action
 
 	for _, filename := range migrateableFiles {
 		src := filepath.Join(srcWorkspace, filename)
 		dst := filepath.Join(dstWorkspace, filename)
-		action := planFileCopy(src, dst, force)
+		action := planFileCopy(src, dst, strategy)
 		if action.Type != ActionSkip || action.Description != "" {
 			actions = append(actions, action)
 		}
@@ -61,7 +87,7 @@ func PlanWorkspaceMigration(
 		if _, err := os.Stat(srcDir); os.IsNotExist(err) {
 			continue
 		}
-		dirActions, err := planDirCopy(srcDir, filepath.Join(dstWorkspace, dirname), force)
+		dirActions, err := planDirCopy(srcDir, filepath.Join(dstWorkspace, dirname), strategy)
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +97,7 @@ func PlanWorkspaceMigration(
 	return actions, nil
 }
 
-func planFileCopy(src, dst string, force bool) Action {
+func planFileCopy(src, dst string, strategy ConflictStrategy) Action {
 	if _, err := os.Stat(src); os.IsNotExist(err) {
 		return Action{
 			Type:        ActionSkip,
@@ -81,13 +107,36 @@ func planFileCopy(src, dst string, force bool) Action {
 		}
 	}
 
-	_, dstExists := os.Stat(dst)
-	if dstExists == nil && !force {
-		return Action{
-			Type:        ActionBackup,
-			Source:      src,
-			Target:      dst,
-			Description: "destination exists, will backup and overwrite",
+	if _, err := os.Stat(dst); err == nil {
+		switch strategy {
+		case ConflictSkip:
+			return Action{
+				Type:        ActionSkip,
+				Source:      src,
+				Target:      dst,
+				Description: "destination exists, keeping it",
+			}
+		case ConflictOverwrite:
+			return Action{
+				Type:        ActionCopy,
+				Source:      src,
+				Target:      dst,
+				Description: "destination exists, will overwrite",
+			}
+		case ConflictInteractive:
+			return Action{
+				Type:        ActionConflict,
+				Source:      src,
+				Target:      dst,
+				Description: "destination exists, will ask",
+			}
+		default:
+			return Action{
+				Type:        ActionBackup,
+				Source:      src,
+				Target:      dst,
+				Description: "destination exists, will backup and overwrite",
+			}
 		}
 	}
 
@@ -99,7 +148,7 @@ func planFileCopy(src, dst string, force bool) Action {
 	}
 }
 
-func planDirCopy(srcDir, dstDir string, force bool) ([]Action, error) {
+func planDirCopy(srcDir, dstDir string, strategy ConflictStrategy) ([]Action, error) {
 	var actions []Action
 
 	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
@@ -123,7 +172,7 @@ func planDirCopy(srcDir, dstDir string, force bool) ([]Action, error) {
 			return nil
 		}
 
-		action := planFileCopy(path, dst, force)
+		action := planFileCopy(path, dst, strategy)
 		actions = append(actions, action)
 		return nil
 	})
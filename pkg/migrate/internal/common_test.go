@@ -111,7 +111,7 @@ func TestPlanWorkspaceMigration(t *testing.T) {
 		dstWorkspace,
 		[]string{"file1.txt"},
 		[]string{"subdir"},
-		false,
+		ConflictBackupOverwrite,
 	)
 	require.NoError(t, err)
 
@@ -121,19 +121,29 @@ func TestPlanWorkspaceMigration(t *testing.T) {
 func TestPlanWorkspaceMigrationExistingFile(t *testing.T) {
 	tests := []struct {
 		name           string
-		force          bool
+		strategy       ConflictStrategy
 		wantActionType ActionType
 	}{
 		{
-			name:           "backup when not forced",
-			force:          false,
+			name:           "backup_overwrite backs up",
+			strategy:       ConflictBackupOverwrite,
 			wantActionType: ActionBackup,
 		},
 		{
-			name:           "copy when forced",
-			force:          true,
+			name:           "overwrite copies",
+			strategy:       ConflictOverwrite,
 			wantActionType: ActionCopy,
 		},
+		{
+			name:           "skip keeps existing",
+			strategy:       ConflictSkip,
+			wantActionType: ActionSkip,
+		},
+		{
+			name:           "interactive defers to execution",
+			strategy:       ConflictInteractive,
+			wantActionType: ActionConflict,
+		},
 	}
 
 	for _, tt := range tests {
@@ -159,7 +169,7 @@ func TestPlanWorkspaceMigrationExistingFile(t *testing.T) {
 				dstWorkspace,
 				[]string{"file1.txt"},
 				[]string{},
-				tt.force,
+				tt.strategy,
 			)
 			require.NoError(t, err)
 
@@ -177,10 +187,41 @@ func TestPlanWorkspaceMigrationNonExistentSource(t *testing.T) {
 		filepath.Join(tmpDir, "dst", "workspace"),
 		[]string{"file1.txt"},
 		[]string{},
-		false,
+		ConflictBackupOverwrite,
 	)
 	require.NoError(t, err)
 	require.Len(t, actions, 1)
 	assert.Equal(t, ActionSkip, actions[0].Type)
 	assert.Contains(t, actions[0].Description, "source file not found")
 }
+
+func TestResolveConflictStrategy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want ConflictStrategy
+	}{
+		{name: "default", opts: Options{}, want: ConflictBackupOverwrite},
+		{name: "default with force", opts: Options{Force: true}, want: ConflictOverwrite},
+		{name: "default with refresh", opts: Options{Refresh: true}, want: ConflictOverwrite},
+		{name: "explicit skip", opts: Options{ConflictStrategy: ConflictSkip, Force: true}, want: ConflictSkip},
+		{name: "interactive", opts: Options{ConflictStrategy: ConflictInteractive}, want: ConflictInteractive},
+		{
+			name: "interactive with force",
+			opts: Options{ConflictStrategy: ConflictInteractive, Force: true},
+			want: ConflictOverwrite,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveConflictStrategy(tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ResolveConflictStrategy(Options{ConflictStrategy: "merge"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backup_overwrite")
+}
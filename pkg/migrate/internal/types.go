@@ -1,5 +1,7 @@
 package internal
 
+import "github.com/sipeed/picoclaw/pkg/config"
+
 type Options struct {
 	DryRun        bool
 	ConfigOnly    bool
@@ -9,6 +11,34 @@ type Options struct {
 	Source        string
 	SourceHome    string
 	TargetHome    string
+
+	// FromConfig and FromWorkspace migrate from another PicoClaw install
+	// (or a backup of one) instead of discovering an OpenClaw home.
+	FromConfig    string
+	FromWorkspace string
+
+	// ConflictStrategy decides what happens to workspace files that already
+	// exist in the target. Empty means backup_overwrite, or overwrite with
+	// --force/--refresh.
+	ConflictStrategy ConflictStrategy
+}
+
+// ConflictStrategy is how the migration treats a target file that exists.
+type ConflictStrategy string
+
+const (
+	ConflictSkip            ConflictStrategy = "skip"
+	ConflictOverwrite       ConflictStrategy = "overwrite"
+	ConflictBackupOverwrite ConflictStrategy = "backup_overwrite"
+	ConflictInteractive     ConflictStrategy = "interactive"
+)
+
+// ConflictStrategies lists the valid strategies in the order shown to users.
+var ConflictStrategies = []ConflictStrategy{
+	ConflictSkip,
+	ConflictOverwrite,
+	ConflictBackupOverwrite,
+	ConflictInteractive,
 }
 
 type Operation interface {
@@ -21,6 +51,13 @@ type Operation interface {
 	GetMigrateableDirs() []string
 }
 
+// ConfigConverter is implemented by handlers that can produce the converted
+// config without writing it, so dry runs can report what would change. The
+// result is exactly what ExecuteConfigMigration would write to dstConfigPath.
+type ConfigConverter interface {
+	ConvertConfig(srcConfigPath, dstConfigPath string) (*config.Config, []string, error)
+}
+
 type HandlerFactory func(opts Options) Operation
 
 type ActionType int
@@ -32,6 +69,7 @@ const (
 	ActionConvertConfig
 	ActionCreateDir
 	ActionMergeConfig
+	ActionConflict
 )
 
 type Action struct {
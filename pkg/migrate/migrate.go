@@ -8,15 +8,17 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/migrate/internal"
 	"github.com/sipeed/picoclaw/pkg/migrate/sources/openclaw"
+	"github.com/sipeed/picoclaw/pkg/migrate/sources/picoclaw"
 )
 
 type (
-	Options        = internal.Options
-	Operation      = internal.Operation
-	ActionType     = internal.ActionType
-	Action         = internal.Action
-	Result         = internal.Result
-	HandlerFactory = internal.HandlerFactory
+	Options          = internal.Options
+	ConflictStrategy = internal.ConflictStrategy
+	Operation        = internal.Operation
+	ActionType       = internal.ActionType
+	Action           = internal.Action
+	Result           = internal.Result
+	HandlerFactory   = internal.HandlerFactory
 )
 
 const (
@@ -26,22 +28,47 @@ const (
 	ActionConvertConfig = internal.ActionConvertConfig
 	ActionCreateDir     = internal.ActionCreateDir
 	ActionMergeConfig   = internal.ActionMergeConfig
+	ActionConflict      = internal.ActionConflict
+)
+
+const (
+	ConflictSkip            = internal.ConflictSkip
+	ConflictOverwrite       = internal.ConflictOverwrite
+	ConflictBackupOverwrite = internal.ConflictBackupOverwrite
+	ConflictInteractive     = internal.ConflictInteractive
 )
 
 type MigrateInstance struct {
 	options  Options
 	handlers map[string]Operation
+	// handlerErrs explains why a source could not be registered.
+	handlerErrs map[string]error
+	// prompt asks how to resolve an interactive conflict; PromptConflict
+	// when nil.
+	prompt func(target string) ConflictStrategy
 }
 
 func NewMigrateInstance(opts Options) *MigrateInstance {
 	instance := &MigrateInstance{
-		options:  opts,
-		handlers: make(map[string]Operation),
+		options:     opts,
+		handlers:    make(map[string]Operation),
+		handlerErrs: make(map[string]error),
 	}
 
 	openclaw_handler, err := openclaw.NewOpenclawHandler(opts)
 	if err == nil {
 		instance.Register(openclaw_handler.GetSourceName(), openclaw_handler)
+	} else {
+		instance.handlerErrs["openclaw"] = err
+	}
+
+	if opts.FromConfig != "" || opts.FromWorkspace != "" {
+		picoclaw_handler, err := picoclaw.NewPicoclawHandler(opts)
+		if err == nil {
+			instance.Register(picoclaw_handler.GetSourceName(), picoclaw_handler)
+		} else {
+			instance.handlerErrs["picoclaw"] = err
+		}
 	}
 
 	return instance
@@ -56,8 +83,15 @@ func (m *MigrateInstance) getCurrentHandler() (Operation, error) {
 	if source == "" {
 		source = "openclaw"
 	}
+	// An explicit config or workspace path wins over source discovery.
+	if m.options.FromConfig != "" || m.options.FromWorkspace != "" {
+		source = "picoclaw"
+	}
 	handler, ok := m.handlers[source]
 	if !ok {
+		if err := m.handlerErrs[source]; err != nil {
+			return nil, fmt.Errorf("Source '%s' not found: %w", source, err)
+		}
 		return nil, fmt.Errorf("Source '%s' not found", source)
 	}
 	return handler, nil
@@ -102,8 +136,12 @@ func (m *MigrateInstance) Run(opts Options) (*Result, error) {
 	fmt.Println()
 
 	if opts.DryRun {
-		PrintPlan(actions, warnings)
-		return &Result{Warnings: warnings}, nil
+		report, err := m.DryRun(actions, warnings, sourceHome)
+		if err != nil {
+			return nil, err
+		}
+		PrintDryRun(report)
+		return &Result{Warnings: report.Warnings}, nil
 	}
 
 	if !opts.Force {
@@ -128,7 +166,10 @@ func (m *MigrateInstance) Plan(opts Options, sourceHome, targetHome string) ([]A
 		return nil, nil, err
 	}
 
-	force := opts.Force || opts.Refresh
+	strategy, err := internal.ResolveConflictStrategy(opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if !opts.WorkspaceOnly {
 		configPath, err := handler.GetSourceConfigFile()
@@ -138,6 +179,9 @@ func (m *MigrateInstance) Plan(opts Options, sourceHome, targetHome string) ([]A
 			}
 			warnings = append(warnings, fmt.Sprintf("Config migration skipped: %v", err))
 		} else {
+			if sameFile(configPath, filepath.Join(targetHome, "config.json")) {
+				return nil, nil, fmt.Errorf("source config %s is the target config", configPath)
+			}
 			actions = append(actions, Action{
 				Type:        ActionConvertConfig,
 				Source:      configPath,
@@ -153,12 +197,15 @@ func (m *MigrateInstance) Plan(opts Options, sourceHome, targetHome string) ([]A
 			return nil, nil, fmt.Errorf("getting source workspace: %w", err)
 		}
 		dstWorkspace := internal.ResolveWorkspace(targetHome)
+		if sameFile(srcWorkspace, dstWorkspace) {
+			return nil, nil, fmt.Errorf("source workspace %s is the target workspace", srcWorkspace)
+		}
 
 		if _, err := os.Stat(srcWorkspace); err == nil {
 			wsActions, err := internal.PlanWorkspaceMigration(srcWorkspace, dstWorkspace,
 				handler.GetMigrateableFiles(),
 				handler.GetMigrateableDirs(),
-				force)
+				strategy)
 			if err != nil {
 				return nil, nil, fmt.Errorf("planning workspace migration: %w", err)
 			}
@@ -179,6 +226,10 @@ func (m *MigrateInstance) Execute(actions []Action, sourceHome, targetHome strin
 	}
 
 	for _, action := range actions {
+		if action.Type == ActionConflict {
+			action.Type = m.resolveConflict(action.Target)
+		}
+
 		switch action.Type {
 		case ActionConvertConfig:
 			if err := handler.ExecuteConfigMigration(action.Source, action.Target); err != nil {
@@ -196,7 +247,7 @@ func (m *MigrateInstance) Execute(actions []Action, sourceHome, targetHome strin
 			}
 		case ActionBackup:
 			bakPath := action.Target + ".bak"
-			if err := internal.CopyFile(action.Target, bakPath); err != nil {
+			if err := os.Rename(action.Target, bakPath); err != nil {
 				result.Errors = append(result.Errors, fmt.Errorf("backup %s: %w", action.Target, err))
 				fmt.Printf("  ✗ Backup failed: %s\n", action.Target)
 				continue
@@ -239,6 +290,49 @@ func (m *MigrateInstance) Execute(actions []Action, sourceHome, targetHome strin
 	return result
 }
 
+// sameFile reports whether two paths name the same file or directory.
+func sameFile(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ai, errA := os.Stat(a)
+	bi, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(ai, bi)
+}
+
+// resolveConflict turns an interactive conflict into the action the user
+// picked for that file.
+func (m *MigrateInstance) resolveConflict(target string) ActionType {
+	prompt := m.prompt
+	if prompt == nil {
+		prompt = PromptConflict
+	}
+	switch prompt(target) {
+	case ConflictOverwrite:
+		return ActionCopy
+	case ConflictBackupOverwrite:
+		return ActionBackup
+	default:
+		return ActionSkip
+	}
+}
+
+// PromptConflict asks on stdin what to do with an existing target file.
+// Anything other than o or b keeps the existing file.
+func PromptConflict(target string) ConflictStrategy {
+	fmt.Printf("  %s already exists. [o]verwrite, [b]ackup and overwrite, [s]kip (default s): ", target)
+	var response string
+	fmt.Scanln(&response)
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "o", "overwrite":
+		return ConflictOverwrite
+	case "b", "backup":
+		return ConflictBackupOverwrite
+	default:
+		return ConflictSkip
+	}
+}
+
 func Confirm() bool {
 	fmt.Print("Proceed with migration? (y/n): ")
 	var response string
@@ -282,6 +376,7 @@ func PrintPlan(actions []Action, warnings []string) {
 	copies := 0
 	skips := 0
 	backups := 0
+	conflicts := 0
 	configCount := 0
 
 	for _, action := range actions {
@@ -296,6 +391,9 @@ func PrintPlan(actions []Action, warnings []string) {
 			fmt.Printf("  [backup]  %s (exists, will backup and overwrite)\n", filepath.Base(action.Target))
 			backups++
 			copies++
+		case ActionConflict:
+			fmt.Printf("  [ask]     %s (exists, will ask)\n", filepath.Base(action.Target))
+			conflicts++
 		case ActionSkip:
 			if action.Description != "" {
 				fmt.Printf("  [skip]    %s (%s)\n", filepath.Base(action.Source), action.Description)
@@ -317,4 +415,7 @@ func PrintPlan(actions []Action, warnings []string) {
 	fmt.Println()
 	fmt.Printf("%d files to copy, %d configs to convert, %d backups needed, %d skipped\n",
 		copies, configCount, backups, skips)
+	if conflicts > 0 {
+		fmt.Printf("%d existing files will be resolved interactively\n", conflicts)
+	}
 }
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 func TestNewMigrateInstance(t *testing.T) {
@@ -274,6 +276,91 @@ func TestMigrateInstanceExecuteSkip(t *testing.T) {
 	assert.Equal(t, 1, result.FilesSkipped)
 }
 
+func TestMigrateInstanceExecuteConflict(t *testing.T) {
+	tests := []struct {
+		name        string
+		answer      ConflictStrategy
+		wantContent string
+		wantBackup  bool
+		wantCopied  int
+		wantSkipped int
+	}{
+		{name: "overwrite", answer: ConflictOverwrite, wantContent: "source", wantCopied: 1},
+		{name: "backup", answer: ConflictBackupOverwrite, wantContent: "source", wantBackup: true, wantCopied: 1},
+		{name: "skip", answer: ConflictSkip, wantContent: "target", wantSkipped: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			sourceFile := filepath.Join(tmpDir, "source.txt")
+			targetFile := filepath.Join(tmpDir, "target.txt")
+			require.NoError(t, os.WriteFile(sourceFile, []byte("source"), 0o644))
+			require.NoError(t, os.WriteFile(targetFile, []byte("target"), 0o644))
+
+			var asked []string
+			instance := &MigrateInstance{
+				options:  Options{Source: "mock"},
+				handlers: make(map[string]Operation),
+				prompt: func(target string) ConflictStrategy {
+					asked = append(asked, target)
+					return tt.answer
+				},
+			}
+			instance.Register("mock", &mockOperation{})
+
+			result := instance.Execute([]Action{
+				{Type: ActionConflict, Source: sourceFile, Target: targetFile},
+			}, tmpDir, tmpDir)
+
+			assert.Equal(t, []string{targetFile}, asked)
+			assert.Equal(t, tt.wantCopied, result.FilesCopied)
+			assert.Equal(t, tt.wantSkipped, result.FilesSkipped)
+
+			content, err := os.ReadFile(targetFile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(content))
+
+			_, err = os.Stat(targetFile + ".bak")
+			assert.Equal(t, tt.wantBackup, err == nil)
+		})
+	}
+}
+
+func TestMigrateInstancePlanConflictSkip(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "source")
+	targetDir := filepath.Join(tmpDir, "target")
+	srcWorkspace := filepath.Join(sourceDir, "workspace")
+	dstWorkspace := filepath.Join(targetDir, "workspace")
+	require.NoError(t, os.MkdirAll(srcWorkspace, 0o755))
+	require.NoError(t, os.MkdirAll(dstWorkspace, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcWorkspace, "AGENTS.md"), []byte("new"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dstWorkspace, "AGENTS.md"), []byte("old"), 0o644))
+
+	instance := &MigrateInstance{
+		options:  Options{Source: "mock"},
+		handlers: make(map[string]Operation),
+	}
+	instance.Register("mock", &mockOperation{
+		sourceHome:   sourceDir,
+		sourceWs:     srcWorkspace,
+		migrateFiles: []string{"AGENTS.md"},
+	})
+
+	opts := Options{WorkspaceOnly: true, ConflictStrategy: ConflictSkip}
+	actions, _, err := instance.Plan(opts, sourceDir, targetDir)
+	require.NoError(t, err)
+
+	result := instance.Execute(actions, sourceDir, targetDir)
+	assert.Equal(t, 0, result.FilesCopied)
+	assert.Equal(t, 0, result.BackupsCreated)
+
+	content, err := os.ReadFile(filepath.Join(dstWorkspace, "AGENTS.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+}
+
 func TestMigrateInstancePrintSummary(t *testing.T) {
 	instance := NewMigrateInstance(Options{})
 
@@ -409,3 +496,50 @@ func (m *mockOperation) GetMigrateableDirs() []string {
 	}
 	return []string{}
 }
+
+func TestMigrateInstanceRunFromConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "backup")
+	sourceWs := filepath.Join(sourceDir, "workspace")
+	targetDir := filepath.Join(tmpDir, "target")
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceWs, "memory"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceWs, "IDENTITY.md"), []byte("me"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceWs, "memory", "MEMORY.md"), []byte("notes"), 0o644))
+
+	srcCfg := config.DefaultConfig()
+	srcCfg.Agents.Defaults.Workspace = sourceWs
+	srcCfg.Agents.Defaults.ModelName = "restored"
+	configPath := filepath.Join(sourceDir, "config.json")
+	require.NoError(t, config.SaveConfig(configPath, srcCfg))
+
+	opts := Options{FromConfig: configPath, TargetHome: targetDir, Force: true}
+	instance := NewMigrateInstance(opts)
+	result, err := instance.Run(opts)
+	require.NoError(t, err)
+
+	assert.True(t, result.ConfigMigrated)
+	assert.Equal(t, 2, result.FilesCopied)
+	content, err := os.ReadFile(filepath.Join(targetDir, "workspace", "memory", "MEMORY.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "notes", string(content))
+
+	migrated, err := config.LoadConfig(filepath.Join(targetDir, "config.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "restored", migrated.Agents.Defaults.ModelName)
+}
+
+func TestMigrateInstanceRunFromOwnWorkspace(t *testing.T) {
+	targetDir := t.TempDir()
+	workspace := filepath.Join(targetDir, "workspace")
+	require.NoError(t, os.MkdirAll(workspace, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "AGENTS.md"), []byte("keep"), 0o644))
+
+	opts := Options{FromWorkspace: workspace, TargetHome: targetDir, WorkspaceOnly: true, Force: true}
+	_, err := NewMigrateInstance(opts).Run(opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is the target workspace")
+
+	content, err := os.ReadFile(filepath.Join(workspace, "AGENTS.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(content))
+}
@@ -85,13 +85,24 @@ func (o *OpenclawHandler) GetMigrateableDirs() []string {
 	return migrateableDirs
 }
 
-func (o *OpenclawHandler) ExecuteConfigMigration(srcConfigPath, dstConfigPath string) error {
+// ConvertConfig converts the OpenClaw config at srcConfigPath into the
+// PicoClaw config that ExecuteConfigMigration would write.
+func (o *OpenclawHandler) ConvertConfig(srcConfigPath, _ string) (*config.Config, []string, error) {
 	openclawCfg, err := LoadOpenClawConfig(srcConfigPath)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	picoCfg, warnings, err := openclawCfg.ConvertToPicoClaw(o.opts.SourceHome)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return picoCfg.ToStandardConfig(), warnings, nil
+}
+
+func (o *OpenclawHandler) ExecuteConfigMigration(srcConfigPath, dstConfigPath string) error {
+	incoming, warnings, err := o.ConvertConfig(srcConfigPath, dstConfigPath)
 	if err != nil {
 		return err
 	}
@@ -100,7 +111,6 @@ func (o *OpenclawHandler) ExecuteConfigMigration(srcConfigPath, dstConfigPath st
 		fmt.Printf("  Warning: %s\n", w)
 	}
 
-	incoming := picoCfg.ToStandardConfig()
 	if err := os.MkdirAll(filepath.Dir(dstConfigPath), 0o755); err != nil {
 		return err
 	}
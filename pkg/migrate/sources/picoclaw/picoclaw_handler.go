@@ -0,0 +1,133 @@
+package picoclaw
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/migrate/internal"
+)
+
+type (
+	Options   = internal.Options
+	Operation = internal.Operation
+)
+
+var migrateableFiles = []string{
+	"AGENTS.md",
+	"IDENTITY.md",
+	"SOUL.md",
+	"USER.md",
+	"TOOLS.md",
+	"HEARTBEAT.md",
+}
+
+var migrateableDirs = []string{
+	"memory",
+	"skills",
+	"cron",
+}
+
+// PicoclawHandler migrates from an arbitrary PicoClaw config and workspace,
+// e.g. another install being consolidated or a restored backup.
+type PicoclawHandler struct {
+	opts             Options
+	sourceHome       string
+	sourceConfigFile string
+	sourceWorkspace  string
+}
+
+// NewPicoclawHandler builds a handler from opts.FromConfig and/or
+// opts.FromWorkspace. Without --from-workspace the workspace is the one the
+// source config points at.
+func NewPicoclawHandler(opts Options) (Operation, error) {
+	if opts.FromConfig == "" && opts.FromWorkspace == "" {
+		return nil, fmt.Errorf("--from-config or --from-workspace is required")
+	}
+
+	h := &PicoclawHandler{opts: opts}
+
+	if opts.FromConfig != "" {
+		h.sourceConfigFile = internal.ExpandHome(opts.FromConfig)
+		if _, err := os.Stat(h.sourceConfigFile); err != nil {
+			return nil, fmt.Errorf("config file %s not found", h.sourceConfigFile)
+		}
+		h.sourceHome = filepath.Dir(h.sourceConfigFile)
+	}
+
+	switch {
+	case opts.FromWorkspace != "":
+		h.sourceWorkspace = internal.ExpandHome(opts.FromWorkspace)
+		if _, err := os.Stat(h.sourceWorkspace); err != nil {
+			return nil, fmt.Errorf("workspace %s not found", h.sourceWorkspace)
+		}
+		if h.sourceHome == "" {
+			h.sourceHome = filepath.Dir(h.sourceWorkspace)
+		}
+	default:
+		cfg, err := config.LoadConfig(h.sourceConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s: %w", h.sourceConfigFile, err)
+		}
+		h.sourceWorkspace = cfg.WorkspacePath()
+	}
+
+	return h, nil
+}
+
+func (p *PicoclawHandler) GetSourceName() string {
+	return "picoclaw"
+}
+
+func (p *PicoclawHandler) GetSourceHome() (string, error) {
+	return p.sourceHome, nil
+}
+
+func (p *PicoclawHandler) GetSourceWorkspace() (string, error) {
+	return p.sourceWorkspace, nil
+}
+
+func (p *PicoclawHandler) GetSourceConfigFile() (string, error) {
+	if p.sourceConfigFile == "" {
+		return "", fmt.Errorf("no --from-config given")
+	}
+	return p.sourceConfigFile, nil
+}
+
+func (p *PicoclawHandler) GetMigrateableFiles() []string {
+	return migrateableFiles
+}
+
+func (p *PicoclawHandler) GetMigrateableDirs() []string {
+	return migrateableDirs
+}
+
+// ConvertConfig loads the source config as PicoClaw would, so older config
+// formats are upgraded on the way.
+func (p *PicoclawHandler) ConvertConfig(srcConfigPath, dstConfigPath string) (*config.Config, []string, error) {
+	cfg, err := config.LoadConfig(srcConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading %s: %w", srcConfigPath, err)
+	}
+
+	// The workspace files are copied next to the new config, so point the
+	// config there rather than at the source install.
+	if !p.opts.ConfigOnly {
+		cfg.Agents.Defaults.Workspace = internal.ResolveWorkspace(filepath.Dir(dstConfigPath))
+	}
+	return cfg, nil, nil
+}
+
+func (p *PicoclawHandler) ExecuteConfigMigration(srcConfigPath, dstConfigPath string) error {
+	incoming, _, err := p.ConvertConfig(srcConfigPath, dstConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstConfigPath), 0o755); err != nil {
+		return err
+	}
+
+	return config.SaveConfig(dstConfigPath, incoming)
+}
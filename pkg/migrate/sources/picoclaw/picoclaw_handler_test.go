@@ -0,0 +1,115 @@
+package picoclaw
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/migrate/internal"
+)
+
+func writeSourceConfig(t *testing.T, dir, workspace string) string {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.Workspace = workspace
+	cfg.Agents.Defaults.ModelName = "backup-model"
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, config.SaveConfig(path, cfg))
+	return path
+}
+
+func TestNewPicoclawHandlerRequiresPath(t *testing.T) {
+	_, err := NewPicoclawHandler(Options{})
+	require.Error(t, err)
+}
+
+func TestNewPicoclawHandlerMissingConfig(t *testing.T) {
+	_, err := NewPicoclawHandler(Options{FromConfig: filepath.Join(t.TempDir(), "nope.json")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestPicoclawHandlerWorkspaceFromConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	workspace := filepath.Join(tmpDir, "elsewhere")
+	configPath := writeSourceConfig(t, tmpDir, workspace)
+
+	handler, err := NewPicoclawHandler(Options{FromConfig: configPath})
+	require.NoError(t, err)
+
+	assert.Equal(t, "picoclaw", handler.GetSourceName())
+	home, err := handler.GetSourceHome()
+	require.NoError(t, err)
+	assert.Equal(t, tmpDir, home)
+	ws, err := handler.GetSourceWorkspace()
+	require.NoError(t, err)
+	assert.Equal(t, workspace, ws)
+	src, err := handler.GetSourceConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, configPath, src)
+}
+
+func TestPicoclawHandlerWorkspaceOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := writeSourceConfig(t, tmpDir, filepath.Join(tmpDir, "from-config"))
+	override := filepath.Join(tmpDir, "override")
+	require.NoError(t, os.MkdirAll(override, 0o755))
+
+	handler, err := NewPicoclawHandler(Options{FromConfig: configPath, FromWorkspace: override})
+	require.NoError(t, err)
+
+	ws, err := handler.GetSourceWorkspace()
+	require.NoError(t, err)
+	assert.Equal(t, override, ws)
+}
+
+func TestPicoclawHandlerWorkspaceOnly(t *testing.T) {
+	workspace := filepath.Join(t.TempDir(), "workspace")
+	require.NoError(t, os.MkdirAll(workspace, 0o755))
+
+	handler, err := NewPicoclawHandler(Options{FromWorkspace: workspace})
+	require.NoError(t, err)
+
+	home, err := handler.GetSourceHome()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Dir(workspace), home)
+	_, err = handler.GetSourceConfigFile()
+	require.Error(t, err)
+}
+
+func TestPicoclawHandlerExecuteConfigMigration(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := writeSourceConfig(t, tmpDir, filepath.Join(tmpDir, "workspace"))
+	targetDir := filepath.Join(tmpDir, "target")
+
+	handler, err := NewPicoclawHandler(Options{FromConfig: configPath})
+	require.NoError(t, err)
+
+	dst := filepath.Join(targetDir, "config.json")
+	require.NoError(t, handler.ExecuteConfigMigration(configPath, dst))
+
+	migrated, err := config.LoadConfig(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "backup-model", migrated.Agents.Defaults.ModelName)
+	assert.Equal(t, filepath.Join(targetDir, "workspace"), migrated.Agents.Defaults.Workspace)
+}
+
+func TestPicoclawHandlerConvertConfigMatchesMigration(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := writeSourceConfig(t, tmpDir, filepath.Join(tmpDir, "workspace"))
+	dst := filepath.Join(tmpDir, "target", "config.json")
+
+	handler, err := NewPicoclawHandler(Options{FromConfig: configPath})
+	require.NoError(t, err)
+	converter, ok := handler.(internal.ConfigConverter)
+	require.True(t, ok)
+
+	// The dry-run preview shows the workspace the migration would write.
+	preview, _, err := converter.ConvertConfig(configPath, dst)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "target", "workspace"), preview.Agents.Defaults.Workspace)
+}
@@ -0,0 +1,118 @@
+// Package moderation screens user messages and agent replies for abusive or
+// unsafe content.
+package moderation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Verdict is the outcome of a moderation check.
+type Verdict struct {
+	Allowed bool
+	Reason  string // why the content was blocked
+}
+
+// Allow is the verdict for content that passed.
+var Allow = Verdict{Allowed: true}
+
+// Moderator checks inbound messages before the agent sees them and outbound
+// messages before a channel sends them.
+type Moderator interface {
+	CheckInbound(ctx context.Context, msg bus.InboundMessage) (Verdict, error)
+	CheckOutbound(ctx context.Context, msg bus.OutboundMessage) (Verdict, error)
+}
+
+// Noop allows everything. It is used when moderation is disabled.
+type Noop struct{}
+
+func (Noop) CheckInbound(context.Context, bus.InboundMessage) (Verdict, error) { return Allow, nil }
+
+func (Noop) CheckOutbound(context.Context, bus.OutboundMessage) (Verdict, error) { return Allow, nil }
+
+// Policy is a Moderator together with the canned replies that replace
+// blocked content.
+type Policy struct {
+	Moderator     Moderator
+	InboundReply  string
+	OutboundReply string
+}
+
+// NewPolicy builds the moderation policy for cfg. When moderation is off the
+// policy uses Noop.
+func NewPolicy(cfg *config.Config) (*Policy, error) {
+	mc := cfg.Moderation
+	policy := &Policy{
+		Moderator:     Noop{},
+		InboundReply:  mc.BlockedInboundResponse,
+		OutboundReply: mc.BlockedOutboundResponse,
+	}
+	if !mc.Enabled {
+		return policy, nil
+	}
+
+	var checker TextChecker
+	switch mc.Provider {
+	case "", "openai":
+		apiKey := mc.APIKey
+		if apiKey == "" {
+			apiKey = cfg.Providers.OpenAI.APIKey
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("moderation: api_key is required for the openai provider")
+		}
+		checker = NewOpenAI(apiKey, mc.APIBase, mc.Model, mc.Proxy)
+	default:
+		return nil, fmt.Errorf("moderation: unknown provider %q", mc.Provider)
+	}
+
+	policy.Moderator = &TextModerator{
+		Checker:  checker,
+		Inbound:  mc.CheckInbound,
+		Outbound: mc.CheckOutbound,
+	}
+	return policy, nil
+}
+
+// ChecksOutbound reports whether the policy screens outbound messages. Only
+// then must a reply reach the channel whole instead of streamed in parts.
+func (p *Policy) ChecksOutbound() bool {
+	switch m := p.Moderator.(type) {
+	case Noop, nil:
+		return false
+	case *TextModerator:
+		return m.Outbound
+	default:
+		return true
+	}
+}
+
+// TextChecker classifies a piece of text.
+type TextChecker interface {
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// TextModerator moderates message text with a TextChecker, in the
+// directions enabled.
+type TextModerator struct {
+	Checker  TextChecker
+	Inbound  bool
+	Outbound bool
+}
+
+func (m *TextModerator) CheckInbound(ctx context.Context, msg bus.InboundMessage) (Verdict, error) {
+	if !m.Inbound || msg.Content == "" {
+		return Allow, nil
+	}
+	return m.Checker.Check(ctx, msg.Content)
+}
+
+func (m *TextModerator) CheckOutbound(ctx context.Context, msg bus.OutboundMessage) (Verdict, error) {
+	if !m.Outbound || msg.Content == "" {
+		return Allow, nil
+	}
+	return m.Checker.Check(ctx, msg.Content)
+}
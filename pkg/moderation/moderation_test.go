@@ -0,0 +1,83 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/bus"
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestOpenAI_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/moderations" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Model string `json:"model"`
+			Input string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		flagged := req.Input == "nasty"
+		json.NewEncoder(w).Encode(map[string]any{
+			"results": []map[string]any{{
+				"flagged":    flagged,
+				"categories": map[string]bool{"violence": flagged, "harassment": flagged, "sexual": false},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	checker := NewOpenAI("key", server.URL, "", "")
+
+	v, err := checker.Check(context.Background(), "hello")
+	if err != nil || !v.Allowed {
+		t.Fatalf("Check(hello) = %+v, %v; want allowed", v, err)
+	}
+	v, err = checker.Check(context.Background(), "nasty")
+	if err != nil || v.Allowed || v.Reason != "flagged: harassment, violence" {
+		t.Fatalf("Check(nasty) = %+v, %v", v, err)
+	}
+
+	if _, err := NewOpenAI("wrong", server.URL, "", "").Check(context.Background(), "hello"); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	cfg := config.DefaultConfig()
+	policy, err := NewPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := policy.Moderator.(Noop); !ok {
+		t.Errorf("disabled moderation should use Noop, got %T", policy.Moderator)
+	}
+	if policy.ChecksOutbound() {
+		t.Error("disabled moderation must not check outbound messages")
+	}
+
+	cfg.Moderation.Enabled = true
+	if _, err := NewPolicy(cfg); err == nil {
+		t.Error("expected an error without an API key")
+	}
+
+	cfg.Moderation.APIKey = "key"
+	cfg.Moderation.CheckOutbound = false
+	policy, err = NewPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.ChecksOutbound() {
+		t.Error("ChecksOutbound() = true with check_outbound off")
+	}
+	// Outbound checks are off, so no request is made.
+	v, err := policy.Moderator.CheckOutbound(context.Background(), bus.OutboundMessage{Content: "anything"})
+	if err != nil || !v.Allowed {
+		t.Errorf("CheckOutbound = %+v, %v; want allowed without a request", v, err)
+	}
+}
@@ -0,0 +1,110 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIBase  = "https://api.openai.com/v1"
+	defaultOpenAIModel = "omni-moderation-latest"
+)
+
+// OpenAI checks text with the OpenAI moderations endpoint.
+type OpenAI struct {
+	apiKey     string
+	apiBase    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAI creates a checker for apiBase (default the OpenAI API) using
+// model (default omni-moderation-latest).
+func NewOpenAI(apiKey, apiBase, model, proxy string) *OpenAI {
+	if apiBase == "" {
+		apiBase = defaultOpenAIBase
+	}
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	if proxy != "" {
+		if parsed, err := url.Parse(proxy); err == nil {
+			client.Transport = &http.Transport{Proxy: http.ProxyURL(parsed)}
+		}
+	}
+	return &OpenAI{
+		apiKey:     apiKey,
+		apiBase:    strings.TrimRight(apiBase, "/"),
+		model:      model,
+		httpClient: client,
+	}
+}
+
+// Check blocks text the endpoint flags, naming the flagged categories.
+func (o *OpenAI) Check(ctx context.Context, text string) (Verdict, error) {
+	body, err := json.Marshal(map[string]any{"model": o.model, "input": text})
+	if err != nil {
+		return Verdict{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.apiBase+"/moderations", bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to read moderation response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("moderation request failed: status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var result struct {
+		Results []struct {
+			Flagged    bool            `json:"flagged"`
+			Categories map[string]bool `json:"categories"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return Verdict{}, fmt.Errorf("failed to parse moderation response: %w", err)
+	}
+
+	var categories []string
+	flagged := false
+	for _, r := range result.Results {
+		if !r.Flagged {
+			continue
+		}
+		flagged = true
+		for name, hit := range r.Categories {
+			if hit {
+				categories = append(categories, name)
+			}
+		}
+	}
+	if !flagged {
+		return Allow, nil
+	}
+	sort.Strings(categories)
+	reason := "flagged"
+	if len(categories) > 0 {
+		reason = "flagged: " + strings.Join(categories, ", ")
+	}
+	return Verdict{Reason: reason}, nil
+}
@@ -3,8 +3,10 @@ package anthropicprovider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 
 	"github.com/anthropics/anthropic-sdk-go"
@@ -35,12 +37,14 @@ func NewProvider(token string) *Provider {
 	return NewProviderWithBaseURL(token, "")
 }
 
-func NewProviderWithBaseURL(token, apiBase string) *Provider {
+// NewProviderWithBaseURL creates a provider for apiBase. opts are passed to
+// the SDK client, e.g. option.WithHTTPClient for a shared connection pool.
+func NewProviderWithBaseURL(token, apiBase string, opts ...option.RequestOption) *Provider {
 	baseURL := normalizeBaseURL(apiBase)
-	client := anthropic.NewClient(
+	client := anthropic.NewClient(append([]option.RequestOption{
 		option.WithAuthToken(token),
 		option.WithBaseURL(baseURL),
-	)
+	}, opts...)...)
 	return &Provider{
 		client:  &client,
 		baseURL: baseURL,
@@ -58,8 +62,10 @@ func NewProviderWithTokenSource(token string, tokenSource func() (string, error)
 	return NewProviderWithTokenSourceAndBaseURL(token, tokenSource, "")
 }
 
-func NewProviderWithTokenSourceAndBaseURL(token string, tokenSource func() (string, error), apiBase string) *Provider {
-	p := NewProviderWithBaseURL(token, apiBase)
+func NewProviderWithTokenSourceAndBaseURL(
+	token string, tokenSource func() (string, error), apiBase string, opts ...option.RequestOption,
+) *Provider {
+	p := NewProviderWithBaseURL(token, apiBase, opts...)
 	p.tokenSource = tokenSource
 	return p
 }
@@ -87,7 +93,16 @@ func (p *Provider) Chat(
 
 	resp, err := p.client.Messages.New(ctx, params, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("claude API call: %w", err)
+		err = fmt.Errorf("claude API call: %w", err)
+		var apiErr *anthropic.Error
+		if errors.As(err, &apiErr) {
+			var header http.Header
+			if apiErr.Response != nil {
+				header = apiErr.Response.Header
+			}
+			return nil, protocoltypes.HTTPError("claude", apiErr.StatusCode, header, apiErr.RawJSON(), err)
+		}
+		return nil, err
 	}
 
 	return parseResponse(resp), nil
@@ -2,13 +2,17 @@ package anthropicprovider
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestBuildParams_BasicMessage(t *testing.T) {
@@ -193,6 +197,70 @@ func TestProvider_ChatRoundTrip(t *testing.T) {
 	}
 }
 
+func TestProvider_ChatMapsErrorKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+		wait   time.Duration
+	}{
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			body: `{"type":"error","error":{"type":"rate_limit_error",` +
+				`"message":"Number of request tokens has exceeded your per-minute rate limit"}}`,
+			kind: protocoltypes.ErrRateLimited,
+			wait: 45 * time.Second,
+		},
+		{
+			name:   "prompt too long",
+			status: http.StatusBadRequest,
+			body: `{"type":"error","error":{"type":"invalid_request_error",` +
+				`"message":"prompt is too long: 212345 tokens > 200000 maximum"}}`,
+			kind: protocoltypes.ErrContextTooLong,
+		},
+		{
+			name:   "auth",
+			status: http.StatusUnauthorized,
+			body:   `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`,
+			kind:   protocoltypes.ErrAuth,
+		},
+		{
+			name:   "overloaded",
+			status: 529,
+			body:   `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			kind:   protocoltypes.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", "45")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := anthropic.NewClient(
+				anthropicoption.WithAuthToken("test-token"),
+				anthropicoption.WithBaseURL(server.URL),
+				anthropicoption.WithMaxRetries(0),
+			)
+			provider := NewProviderWithClient(&client)
+			_, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "Hello"}}, nil, "claude-sonnet-4.6", nil)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("error = %v, want %v", err, tt.kind)
+			}
+			var pe *protocoltypes.ProviderError
+			if !errors.As(err, &pe) || pe.Status != tt.status || pe.RetryAfter != tt.wait {
+				t.Errorf("ProviderError = %+v, want status %d, retry after %v", pe, tt.status, tt.wait)
+			}
+		})
+	}
+}
+
 func TestProvider_GetDefaultModel(t *testing.T) {
 	p := NewProvider("test-token")
 	if got := p.GetDefaultModel(); got != "claude-sonnet-4.6" {
@@ -14,6 +14,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 const (
@@ -22,6 +23,11 @@ const (
 	antigravityUserAgent    = "antigravity"
 	antigravityXGoogClient  = "google-cloud-sdk vscode_cloudshelleditor/0.1"
 	antigravityVersion      = "1.15.8"
+
+	// antigravitySkipSignature is accepted by Gemini 3 in place of a thought
+	// signature for function calls it did not produce (e.g. history recorded
+	// with another model), which would otherwise be rejected.
+	antigravitySkipSignature = "skip_thought_signature_validator"
 )
 
 // AntigravityProvider implements LLMProvider using Google's Cloud Code Assist (Antigravity) API.
@@ -30,16 +36,33 @@ const (
 type AntigravityProvider struct {
 	tokenSource func() (string, string, error) // Returns (accessToken, projectID, error)
 	httpClient  *http.Client
+	baseURL     string
 }
 
 // NewAntigravityProvider creates a new Antigravity provider using stored auth credentials.
 func NewAntigravityProvider() *AntigravityProvider {
-	return &AntigravityProvider{
+	return NewAntigravityProviderWithBaseURL("")
+}
+
+// NewAntigravityProviderWithBaseURL is NewAntigravityProvider sending chat
+// requests to apiBase instead of Cloud Code Assist. An empty apiBase uses
+// the default.
+func NewAntigravityProviderWithBaseURL(apiBase string) *AntigravityProvider {
+	return newAntigravityProvider(defaultHTTPClientConfig, apiBase)
+}
+
+func newAntigravityProvider(pool HTTPClientConfig, apiBase string) *AntigravityProvider {
+	apiBase = strings.TrimRight(apiBase, "/")
+	if apiBase == "" {
+		apiBase = antigravityBaseURL
+	}
+	p := &AntigravityProvider{
 		tokenSource: createAntigravityTokenSource(),
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		httpClient:  NewHTTPClient(pool),
+		baseURL:     apiBase,
 	}
+	p.httpClient.Timeout = 120 * time.Second
+	return p
 }
 
 // Chat implements LLMProvider.Chat using the Cloud Code Assist v1internal API.
@@ -54,7 +77,7 @@ func (p *AntigravityProvider) Chat(
 ) (*LLMResponse, error) {
 	accessToken, projectID, err := p.tokenSource()
 	if err != nil {
-		return nil, fmt.Errorf("antigravity auth: %w", err)
+		return nil, &ProviderError{Kind: ErrAuth, Provider: "antigravity", Err: fmt.Errorf("antigravity auth: %w", err)}
 	}
 
 	if model == "" || model == "antigravity" || model == "google-antigravity" {
@@ -89,7 +112,7 @@ func (p *AntigravityProvider) Chat(
 	}
 
 	// Build API URL — uses Cloud Code Assist v1internal streaming endpoint
-	apiURL := fmt.Sprintf("%s/v1internal:streamGenerateContent?alt=sse", antigravityBaseURL)
+	apiURL := fmt.Sprintf("%s/v1internal:streamGenerateContent?alt=sse", p.baseURL)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(bodyBytes))
 	if err != nil {
@@ -108,10 +131,13 @@ func (p *AntigravityProvider) Chat(
 	req.Header.Set("User-Agent", fmt.Sprintf("antigravity/%s linux/amd64", antigravityVersion))
 	req.Header.Set("X-Goog-Api-Client", antigravityXGoogClient)
 	req.Header.Set("Client-Metadata", string(clientMetadata))
+	if projectID != "" {
+		req.Header.Set("X-Goog-User-Project", projectID)
+	}
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("antigravity API call: %w", err)
+		return nil, MapError("antigravity", fmt.Errorf("antigravity API call: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -127,7 +153,7 @@ func (p *AntigravityProvider) Chat(
 			"model":       model,
 		})
 
-		return nil, p.parseAntigravityError(resp.StatusCode, respBody)
+		return nil, p.parseAntigravityError(resp.StatusCode, respBody, model)
 	}
 
 	// Response is always SSE from streamGenerateContent — each line is "data: {...}"
@@ -247,6 +273,9 @@ func (p *AntigravityProvider) buildRequest(
 			if msg.Content != "" {
 				content.Parts = append(content.Parts, antigravityPart{Text: msg.Content})
 			}
+			// Reasoning text is never echoed back; Gemini only needs the
+			// signatures, and requires one on the first function call of a step.
+			needSignature := requiresThoughtSignature(model)
 			for _, tc := range msg.ToolCalls {
 				toolName, toolArgs, thoughtSignature := normalizeStoredToolCall(tc)
 				if toolName == "" {
@@ -262,6 +291,12 @@ func (p *AntigravityProvider) buildRequest(
 				if tc.ID != "" {
 					toolCallNames[tc.ID] = toolName
 				}
+				if needSignature {
+					if thoughtSignature == "" {
+						thoughtSignature = antigravitySkipSignature
+					}
+					needSignature = false
+				}
 				content.Parts = append(content.Parts, antigravityPart{
 					ThoughtSignature:      thoughtSignature,
 					ThoughtSignatureSnake: thoughtSignature,
@@ -328,6 +363,12 @@ func (p *AntigravityProvider) buildRequest(
 	return req
 }
 
+// requiresThoughtSignature reports whether the model rejects function calls
+// in history that carry no thought signature.
+func requiresThoughtSignature(model string) bool {
+	return strings.HasPrefix(strings.ToLower(model), "gemini-3")
+}
+
 func normalizeStoredToolCall(tc ToolCall) (string, map[string]any, string) {
 	name := tc.Name
 	args := tc.Arguments
@@ -389,6 +430,7 @@ type antigravityJSONResponse struct {
 		Content struct {
 			Parts []struct {
 				Text                  string                   `json:"text,omitempty"`
+				Thought               bool                     `json:"thought,omitempty"`
 				ThoughtSignature      string                   `json:"thoughtSignature,omitempty"`
 				ThoughtSignatureSnake string                   `json:"thought_signature,omitempty"`
 				FunctionCall          *antigravityFunctionCall `json:"functionCall,omitempty"`
@@ -400,15 +442,20 @@ type antigravityJSONResponse struct {
 	UsageMetadata struct {
 		PromptTokenCount     int `json:"promptTokenCount"`
 		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
 		TotalTokenCount      int `json:"totalTokenCount"`
 	} `json:"usageMetadata"`
 }
 
 func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error) {
 	var contentParts []string
+	var reasoningParts []string
 	var toolCalls []ToolCall
 	var usage *UsageInfo
 	var finishReason string
+	// A signature may arrive on a part of its own (e.g. an empty text part
+	// closing the stream) rather than on the function call it belongs to.
+	var orphanSignature string
 
 	scanner := bufio.NewScanner(strings.NewReader(body))
 	for scanner.Scan() {
@@ -432,9 +479,18 @@ func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error
 
 		for _, candidate := range resp.Candidates {
 			for _, part := range candidate.Content.Parts {
-				if part.Text != "" {
+				signature := extractPartThoughtSignature(part.ThoughtSignature, part.ThoughtSignatureSnake)
+				if part.Thought {
+					// Thinking summaries are reasoning, not part of the reply.
+					if part.Text != "" {
+						reasoningParts = append(reasoningParts, part.Text)
+					}
+				} else if part.Text != "" {
 					contentParts = append(contentParts, part.Text)
 				}
+				if part.FunctionCall == nil && signature != "" && orphanSignature == "" {
+					orphanSignature = signature
+				}
 				if part.FunctionCall != nil {
 					argumentsJSON, _ := json.Marshal(part.FunctionCall.Args)
 					toolCalls = append(toolCalls, ToolCall{
@@ -442,12 +498,9 @@ func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error
 						Name:      part.FunctionCall.Name,
 						Arguments: part.FunctionCall.Args,
 						Function: &FunctionCall{
-							Name:      part.FunctionCall.Name,
-							Arguments: string(argumentsJSON),
-							ThoughtSignature: extractPartThoughtSignature(
-								part.ThoughtSignature,
-								part.ThoughtSignatureSnake,
-							),
+							Name:             part.FunctionCall.Name,
+							Arguments:        string(argumentsJSON),
+							ThoughtSignature: signature,
 						},
 					})
 				}
@@ -462,10 +515,15 @@ func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error
 				PromptTokens:     resp.UsageMetadata.PromptTokenCount,
 				CompletionTokens: resp.UsageMetadata.CandidatesTokenCount,
 				TotalTokens:      resp.UsageMetadata.TotalTokenCount,
+				ReasoningTokens:  resp.UsageMetadata.ThoughtsTokenCount,
 			}
 		}
 	}
 
+	if orphanSignature != "" && len(toolCalls) > 0 && toolCalls[0].Function.ThoughtSignature == "" {
+		toolCalls[0].Function.ThoughtSignature = orphanSignature
+	}
+
 	mappedFinish := "stop"
 	if len(toolCalls) > 0 {
 		mappedFinish = "tool_calls"
@@ -474,11 +532,14 @@ func (p *AntigravityProvider) parseSSEResponse(body string) (*LLMResponse, error
 		mappedFinish = "length"
 	}
 
+	reasoning := strings.Join(reasoningParts, "")
 	return &LLMResponse{
-		Content:      strings.Join(contentParts, ""),
-		ToolCalls:    toolCalls,
-		FinishReason: mappedFinish,
-		Usage:        usage,
+		Content:          strings.Join(contentParts, ""),
+		ReasoningContent: reasoning,
+		Reasoning:        reasoning,
+		ToolCalls:        toolCalls,
+		FinishReason:     mappedFinish,
+		Usage:            usage,
 	}, nil
 }
 
@@ -633,7 +694,8 @@ func FetchAntigravityProjectID(accessToken string) (string, error) {
 	req.Header.Set("User-Agent", antigravityUserAgent)
 	req.Header.Set("X-Goog-Api-Client", antigravityXGoogClient)
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := NewHTTPClient(defaultHTTPClientConfig)
+	client.Timeout = 15 * time.Second
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -674,7 +736,8 @@ func FetchAntigravityModels(accessToken, projectID string) ([]AntigravityModelIn
 	req.Header.Set("User-Agent", antigravityUserAgent)
 	req.Header.Set("X-Goog-Api-Client", antigravityXGoogClient)
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := NewHTTPClient(defaultHTTPClientConfig)
+	client.Timeout = 15 * time.Second
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -764,7 +827,10 @@ func randomString(n int) string {
 	return string(b)
 }
 
-func (p *AntigravityProvider) parseAntigravityError(statusCode int, body []byte) error {
+// parseAntigravityError turns an error response into an error. A 429 whose
+// ErrorInfo says the quota is exhausted, or gives a quota reset delay,
+// becomes a *QuotaExhaustedError; other 429s are plain rate limits.
+func (p *AntigravityProvider) parseAntigravityError(statusCode int, body []byte, model string) error {
 	var errResp struct {
 		Error struct {
 			Code    int              `json:"code"`
@@ -775,23 +841,41 @@ func (p *AntigravityProvider) parseAntigravityError(statusCode int, body []byte)
 	}
 
 	if err := json.Unmarshal(body, &errResp); err != nil {
-		return fmt.Errorf("antigravity API error (HTTP %d): %s", statusCode, truncateString(string(body), 500))
+		err = fmt.Errorf("antigravity API error (HTTP %d): %s", statusCode, truncateString(string(body), 500))
+		return protocoltypes.HTTPError("antigravity", statusCode, nil, string(body), err)
 	}
 
 	msg := errResp.Error.Message
-	if statusCode == 429 {
-		// Try to extract quota reset info
+	if statusCode == http.StatusTooManyRequests {
+		var retryDelay string
 		for _, detail := range errResp.Error.Details {
-			if typeVal, ok := detail["@type"].(string); ok && strings.HasSuffix(typeVal, "ErrorInfo") {
-				if metadata, ok := detail["metadata"].(map[string]any); ok {
-					if delay, ok := metadata["quotaResetDelay"].(string); ok {
-						return fmt.Errorf("antigravity rate limit exceeded: %s (reset in %s)", msg, delay)
-					}
-				}
+			typeVal, _ := detail["@type"].(string)
+			if strings.HasSuffix(typeVal, "RetryInfo") {
+				retryDelay, _ = detail["retryDelay"].(string)
+			}
+			if !strings.HasSuffix(typeVal, "ErrorInfo") {
+				continue
+			}
+			reason, _ := detail["reason"].(string)
+			metadata, _ := detail["metadata"].(map[string]any)
+			delay, _ := metadata["quotaResetDelay"].(string)
+			if reason == "QUOTA_EXHAUSTED" || delay != "" {
+				pe := protocoltypes.HTTPError("antigravity", statusCode, nil, msg, &QuotaExhaustedError{
+					Provider:   "antigravity",
+					Model:      model,
+					Message:    msg,
+					ResetAfter: delay,
+				})
+				pe.RetryAfter, _ = time.ParseDuration(delay)
+				return pe
 			}
 		}
-		return fmt.Errorf("antigravity rate limit exceeded: %s", msg)
+		pe := protocoltypes.HTTPError("antigravity", statusCode, nil, msg,
+			fmt.Errorf("antigravity rate limit exceeded: %s", msg))
+		pe.RetryAfter, _ = time.ParseDuration(retryDelay)
+		return pe
 	}
 
-	return fmt.Errorf("antigravity API error (%s): %s", errResp.Error.Status, msg)
+	return protocoltypes.HTTPError("antigravity", statusCode, nil, msg,
+		fmt.Errorf("antigravity API error (%s): %s", errResp.Error.Status, msg))
 }
@@ -1,6 +1,16 @@
 package providers
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestBuildRequestUsesFunctionFieldsWhenToolCallNameMissing(t *testing.T) {
 	p := &AntigravityProvider{}
@@ -54,3 +64,266 @@ func TestResolveToolResponseNameInfersNameFromGeneratedCallID(t *testing.T) {
 		t.Fatalf("expected inferred tool name search_docs, got %q", got)
 	}
 }
+
+// Recorded streamGenerateContent output from gemini-3-flash for a turn that
+// thinks, then calls a tool. The signature arrives on the function call part.
+const antigravityToolCallTurn = `data: {"response": {"candidates": [{"content": {"role": "model","parts": [{"text": "**Locating the file**\nThe user wants the README, so I should read it first.","thought": true}]}}],"usageMetadata": {"promptTokenCount": 812,"totalTokenCount": 812},"modelVersion": "gemini-3-flash"},"traceId": "4b1c"}
+
+data: {"response": {"candidates": [{"content": {"role": "model","parts": [{"functionCall": {"name": "read_file","args": {"path": "README.md"}},"thoughtSignature": "CiQBcsjafRrJ3Vbq0R1Xh9h4Nf1gq2jQ=="}]},"finishReason": "STOP"}],"usageMetadata": {"promptTokenCount": 812,"candidatesTokenCount": 18,"totalTokenCount": 902,"thoughtsTokenCount": 72},"modelVersion": "gemini-3-flash"},"traceId": "4b1c"}
+`
+
+// Recorded output for a parallel tool-call turn where the signature is sent
+// on a trailing empty text part instead of the first function call.
+const antigravityTrailingSignatureTurn = `data: {"response": {"candidates": [{"content": {"role": "model","parts": [{"functionCall": {"name": "list_dir","args": {"path": "."}}},{"functionCall": {"name": "read_file","args": {"path": "go.mod"}}}]}}],"modelVersion": "gemini-3-flash"},"traceId": "77aa"}
+
+data: {"response": {"candidates": [{"content": {"role": "model","parts": [{"text": "","thoughtSignature": "EpYBAXLI2nw0q7Kp9Ux=="}]},"finishReason": "STOP"}],"usageMetadata": {"promptTokenCount": 640,"candidatesTokenCount": 30,"totalTokenCount": 700,"thoughtsTokenCount": 30},"modelVersion": "gemini-3-flash"},"traceId": "77aa"}
+`
+
+// Recorded final answer after the tool result, with a thought summary first.
+const antigravityFinalTurn = `data: {"response": {"candidates": [{"content": {"role": "model","parts": [{"text": "**Summarizing**\nThe README describes the project.","thought": true}]}}],"modelVersion": "gemini-3-flash"},"traceId": "9d02"}
+
+data: {"response": {"candidates": [{"content": {"role": "model","parts": [{"text": "PicoClaw is an ultra-lightweight assistant.","thoughtSignature": "Eq8CAXLI2nz=="}]},"finishReason": "STOP"}],"usageMetadata": {"promptTokenCount": 1030,"candidatesTokenCount": 9,"totalTokenCount": 1091,"thoughtsTokenCount": 52},"modelVersion": "gemini-3-flash"},"traceId": "9d02"}
+`
+
+// assistantFromResponse mirrors how the agent loop records a tool-call turn.
+func assistantFromResponse(resp *LLMResponse) Message {
+	msg := Message{Role: "assistant", Content: resp.Content, ReasoningContent: resp.ReasoningContent}
+	for _, tc := range resp.ToolCalls {
+		tc = NormalizeToolCall(tc)
+		msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+			ID:   tc.ID,
+			Type: "function",
+			Name: tc.Name,
+			Function: &FunctionCall{
+				Name:             tc.Name,
+				Arguments:        tc.Function.Arguments,
+				ThoughtSignature: tc.Function.ThoughtSignature,
+			},
+		})
+	}
+	return msg
+}
+
+func TestAntigravityMultiTurnToolCallPreservesSignature(t *testing.T) {
+	p := &AntigravityProvider{}
+
+	first, err := p.parseSSEResponse(antigravityToolCallTurn)
+	if err != nil {
+		t.Fatalf("parse first turn: %v", err)
+	}
+	if first.Content != "" {
+		t.Fatalf("thought text leaked into content: %q", first.Content)
+	}
+	if !strings.Contains(first.ReasoningContent, "Locating the file") {
+		t.Fatalf("reasoning content = %q", first.ReasoningContent)
+	}
+	if first.Usage == nil || first.Usage.ReasoningTokens != 72 || first.Usage.CompletionTokens != 18 {
+		t.Fatalf("usage = %+v, want 72 reasoning and 18 completion tokens", first.Usage)
+	}
+	if len(first.ToolCalls) != 1 || first.ToolCalls[0].Function.ThoughtSignature != "CiQBcsjafRrJ3Vbq0R1Xh9h4Nf1gq2jQ==" {
+		t.Fatalf("tool calls = %+v", first.ToolCalls)
+	}
+
+	assistant := assistantFromResponse(first)
+	history := []Message{
+		{Role: "user", Content: "What is in the README?"},
+		assistant,
+		{Role: "tool", ToolCallID: assistant.ToolCalls[0].ID, Content: "# PicoClaw"},
+	}
+
+	// The session round-trips through JSON between turns.
+	data, err := json.Marshal(history)
+	if err != nil {
+		t.Fatalf("marshal history: %v", err)
+	}
+	var restored []Message
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unmarshal history: %v", err)
+	}
+
+	req := p.buildRequest(restored, nil, "gemini-3-flash", nil)
+	if len(req.Contents) != 3 {
+		t.Fatalf("expected 3 contents, got %d", len(req.Contents))
+	}
+	model := req.Contents[1]
+	if len(model.Parts) != 1 {
+		t.Fatalf("reasoning must not be echoed back, got parts %+v", model.Parts)
+	}
+	if model.Parts[0].ThoughtSignature != "CiQBcsjafRrJ3Vbq0R1Xh9h4Nf1gq2jQ==" {
+		t.Fatalf("signature not passed back: %+v", model.Parts[0])
+	}
+
+	final, err := p.parseSSEResponse(antigravityFinalTurn)
+	if err != nil {
+		t.Fatalf("parse final turn: %v", err)
+	}
+	if final.Content != "PicoClaw is an ultra-lightweight assistant." {
+		t.Fatalf("content = %q", final.Content)
+	}
+	if final.Usage.ReasoningTokens != 52 {
+		t.Fatalf("reasoning tokens = %d, want 52", final.Usage.ReasoningTokens)
+	}
+}
+
+func TestAntigravityTrailingSignatureAttachesToFirstCall(t *testing.T) {
+	p := &AntigravityProvider{}
+
+	resp, err := p.parseSSEResponse(antigravityTrailingSignatureTurn)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(resp.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(resp.ToolCalls))
+	}
+	if got := resp.ToolCalls[0].Function.ThoughtSignature; got != "EpYBAXLI2nw0q7Kp9Ux==" {
+		t.Fatalf("first call signature = %q", got)
+	}
+	if got := resp.ToolCalls[1].Function.ThoughtSignature; got != "" {
+		t.Fatalf("second call should carry no signature, got %q", got)
+	}
+}
+
+func TestAntigravityBuildRequestFillsMissingSignatureForGemini3(t *testing.T) {
+	p := &AntigravityProvider{}
+	messages := []Message{{
+		Role: "assistant",
+		ToolCalls: []ToolCall{
+			{ID: "call_a_1", Function: &FunctionCall{Name: "a", Arguments: `{}`}},
+			{ID: "call_b_2", Function: &FunctionCall{Name: "b", Arguments: `{}`}},
+		},
+	}}
+
+	req := p.buildRequest(messages, nil, "gemini-3-pro-preview", nil)
+	parts := req.Contents[0].Parts
+	if parts[0].ThoughtSignature != antigravitySkipSignature {
+		t.Fatalf("first call signature = %q, want placeholder", parts[0].ThoughtSignature)
+	}
+	if parts[1].ThoughtSignature != "" {
+		t.Fatalf("only the first call needs a signature, got %q", parts[1].ThoughtSignature)
+	}
+
+	req = p.buildRequest(messages, nil, "claude-sonnet-4-5", nil)
+	if got := req.Contents[0].Parts[0].ThoughtSignature; got != "" {
+		t.Fatalf("non-Gemini 3 model got signature %q", got)
+	}
+}
+
+func newTestAntigravityProvider(t *testing.T, handler http.HandlerFunc) *AntigravityProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &AntigravityProvider{
+		tokenSource: func() (string, string, error) { return "access-token", "proj-123", nil },
+		httpClient:  server.Client(),
+		baseURL:     server.URL,
+	}
+}
+
+func TestAntigravityChatSendsProjectAndCredential(t *testing.T) {
+	p := newTestAntigravityProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer access-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Goog-User-Project"); got != "proj-123" {
+			t.Errorf("X-Goog-User-Project = %q", got)
+		}
+		var envelope map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&envelope); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if envelope["project"] != "proj-123" || envelope["model"] != "gemini-3-flash" {
+			t.Errorf("envelope project = %v, model = %v", envelope["project"], envelope["model"])
+		}
+		fmt.Fprintln(w, `data: {"response": {"candidates": [{"content": {"parts": [{"text": "hello"}]}}]}}`)
+	})
+
+	resp, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "antigravity/gemini-3-flash", nil)
+	if err != nil {
+		t.Fatalf("Chat() error: %v", err)
+	}
+	if resp.Content != "hello" {
+		t.Errorf("Content = %q, want hello", resp.Content)
+	}
+}
+
+func TestAntigravityChatQuotaExhausted(t *testing.T) {
+	p := newTestAntigravityProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "message": "You have exhausted your capacity",
+			"details": [{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "QUOTA_EXHAUSTED",
+			"metadata": {"quotaResetDelay": "3h5m"}}]}}`)
+	})
+
+	_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "gemini-3-pro", nil)
+	if !errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("Chat() error = %v, want ErrQuotaExhausted", err)
+	}
+	var quotaErr *QuotaExhaustedError
+	if !errors.As(err, &quotaErr) || quotaErr.Model != "gemini-3-pro" || quotaErr.ResetAfter != "3h5m" {
+		t.Errorf("QuotaExhaustedError = %+v", quotaErr)
+	}
+	if fe := ClassifyError(err, "antigravity", "gemini-3-pro"); fe == nil || fe.Reason != FailoverRateLimit {
+		t.Errorf("ClassifyError() = %v, want rate_limit", fe)
+	}
+	var pe *ProviderError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &pe) || pe.RetryAfter != 3*time.Hour+5*time.Minute {
+		t.Errorf("error = %v (%+v), want ErrRateLimited with the reset delay", err, pe)
+	}
+}
+
+func TestAntigravityChatMapsErrorKinds(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		kind   error
+		wait   time.Duration
+	}{
+		{
+			http.StatusTooManyRequests,
+			`{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "message": "Resource has been exhausted",
+			"details": [{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "27s"}]}}`,
+			ErrRateLimited, 27 * time.Second,
+		},
+		{
+			http.StatusForbidden,
+			`{"error": {"code": 403, "status": "PERMISSION_DENIED", "message": "The caller does not have permission"}}`,
+			ErrAuth, 0,
+		},
+		{
+			http.StatusBadRequest,
+			`{"error": {"code": 400, "status": "INVALID_ARGUMENT",
+			"message": "The input token count (1200000) exceeds the maximum number of tokens allowed (1048576)."}}`,
+			ErrContextTooLong, 0,
+		},
+		{
+			http.StatusBadRequest,
+			`{"error": {"code": 400, "status": "INVALID_ARGUMENT", "message": "Invalid JSON payload received."}}`,
+			ErrBadRequest, 0,
+		},
+		{
+			http.StatusServiceUnavailable,
+			`{"error": {"code": 503, "status": "UNAVAILABLE", "message": "The model is overloaded."}}`,
+			ErrUnavailable, 0,
+		},
+	}
+	for _, tt := range tests {
+		p := newTestAntigravityProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, tt.body)
+		})
+		_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil, "gemini-3-flash", nil)
+		var pe *ProviderError
+		if !errors.Is(err, tt.kind) || !errors.As(err, &pe) || pe.RetryAfter != tt.wait {
+			t.Errorf("HTTP %d: error = %v (%+v), want %v", tt.status, err, pe, tt.kind)
+		}
+	}
+}
+
+func TestAntigravityRateLimitIsNotQuotaExhausted(t *testing.T) {
+	p := &AntigravityProvider{}
+	err := p.parseAntigravityError(http.StatusTooManyRequests,
+		[]byte(`{"error": {"code": 429, "message": "slow down", "details": []}}`), "gemini-3-flash")
+	if err == nil || errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("parseAntigravityError() = %v, want a plain rate limit error", err)
+	}
+}
@@ -0,0 +1,207 @@
+package providers
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Capability is a feature a model may or may not support.
+type Capability string
+
+const (
+	CapabilityVision    Capability = "vision"
+	CapabilityTools     Capability = "tools"
+	CapabilityStreaming Capability = "streaming"
+)
+
+// modelEntry is an entry of the capability registry. Pattern is a model
+// family: it matches a model ID that equals it or continues it past a
+// non-letter, so "o3" matches "o3-mini" but not "gpt-4o3x" or "gemma3o3".
+// Only the last path segment of the ID is compared. "protocol/family"
+// matches one provider only; an empty family matches every model of it.
+// ContextWindow and MaxTokens are in tokens, 0 when unknown.
+type modelEntry struct {
+	Pattern       string
+	Vision        bool
+	NoTools       bool
+	NoStreaming   bool
+	ContextWindow int
+	MaxTokens     int
+}
+
+// modelRegistry lists what is known about model families. Every matching
+// entry applies: a model supports vision if any entry says so, and tools or
+// streaming unless an entry says otherwise. Limits come from the first
+// matching entry that sets them, so specific patterns go first.
+var modelRegistry = []modelEntry{
+	{Pattern: "gpt-4o", Vision: true, ContextWindow: 128000, MaxTokens: 16384},
+	{Pattern: "gpt-4.1", Vision: true, ContextWindow: 1047576, MaxTokens: 32768},
+	{Pattern: "gpt-5", Vision: true, ContextWindow: 400000, MaxTokens: 128000},
+	{Pattern: "o3", Vision: true, ContextWindow: 200000, MaxTokens: 100000},
+	{Pattern: "o4", Vision: true, ContextWindow: 200000, MaxTokens: 100000},
+	{Pattern: "o1-mini", NoTools: true},
+	{Pattern: "o1-preview", NoTools: true},
+	{Pattern: "claude-3", Vision: true, ContextWindow: 200000},
+	{Pattern: "claude-sonnet", Vision: true, ContextWindow: 200000},
+	{Pattern: "claude-opus", Vision: true, ContextWindow: 200000},
+	{Pattern: "claude-haiku", Vision: true, ContextWindow: 200000},
+	{Pattern: "gemini", Vision: true, ContextWindow: 1048576},
+	{Pattern: "deepseek-reasoner", NoTools: true},
+	{Pattern: "gemma", NoTools: true},
+	{Pattern: "llava", Vision: true, NoTools: true},
+	{Pattern: "pixtral", Vision: true},
+	{Pattern: "glm-4v", Vision: true},
+	{Pattern: "glm-4.5v", Vision: true},
+	{Pattern: "qwen-vl", Vision: true},
+	{Pattern: "qwen2-vl", Vision: true},
+	{Pattern: "qwen2.5-vl", Vision: true},
+	{Pattern: "qwen3-vl", Vision: true},
+	{Pattern: "kimi-vl", Vision: true},
+	{Pattern: "llama-3.2-11b", Vision: true},
+	{Pattern: "llama-3.2-90b", Vision: true},
+	{Pattern: "llama-4", Vision: true},
+	// CLI providers return the whole answer when the process exits.
+	{Pattern: "claude-cli/", NoStreaming: true},
+	{Pattern: "codex-cli/", NoStreaming: true},
+}
+
+// ModelInfo is what is known about a model: the features it supports and
+// its limits in tokens, 0 when unknown.
+type ModelInfo struct {
+	Capabilities  []Capability
+	ContextWindow int
+	MaxTokens     int
+}
+
+// Has reports whether the model supports c.
+func (i ModelInfo) Has(c Capability) bool {
+	return HasCapabilities(i.Capabilities, c)
+}
+
+// LookupModel returns the registry's knowledge of a "protocol/model-id"
+// string. Unknown models are assumed to support tools and streaming only.
+func LookupModel(model string) ModelInfo {
+	protocol, modelID := ExtractProtocol(model)
+	protocol, id := strings.ToLower(protocol), strings.ToLower(modelID)
+
+	var info ModelInfo
+	vision, tools, streaming := false, true, true
+	for _, e := range modelRegistry {
+		if !e.matches(protocol, id) {
+			continue
+		}
+		vision = vision || e.Vision
+		tools = tools && !e.NoTools
+		streaming = streaming && !e.NoStreaming
+		if info.ContextWindow == 0 {
+			info.ContextWindow = e.ContextWindow
+		}
+		if info.MaxTokens == 0 {
+			info.MaxTokens = e.MaxTokens
+		}
+	}
+	if vision {
+		info.Capabilities = append(info.Capabilities, CapabilityVision)
+	}
+	if tools {
+		info.Capabilities = append(info.Capabilities, CapabilityTools)
+	}
+	if streaming {
+		info.Capabilities = append(info.Capabilities, CapabilityStreaming)
+	}
+	return info
+}
+
+func (e modelEntry) matches(protocol, id string) bool {
+	pattern := e.Pattern
+	if p, rest, ok := strings.Cut(pattern, "/"); ok {
+		if p != protocol {
+			return false
+		}
+		pattern = rest
+	}
+	if i := strings.LastIndexByte(id, '/'); i >= 0 {
+		id = id[i+1:]
+	}
+	rest, ok := strings.CutPrefix(id, pattern)
+	if !ok {
+		return false
+	}
+	return rest == "" || pattern == "" || !unicode.IsLetter(rune(rest[0]))
+}
+
+// ModelCapabilities returns the capabilities of a "protocol/model-id" string
+// based on the built-in registry of known model families.
+func ModelCapabilities(model string) []Capability {
+	return LookupModel(model).Capabilities
+}
+
+// ModelConfigInfo returns what is known about a model_list entry. The
+// entry's capabilities, context_window and max_tokens take precedence over
+// the registry, so self-hosted and unknown models can be described in
+// config.
+func ModelConfigInfo(mc *config.ModelConfig) ModelInfo {
+	if mc == nil {
+		return ModelInfo{}
+	}
+	info := LookupModel(mc.Model)
+	if len(mc.Capabilities) > 0 {
+		// The list is about what the model accepts; whether its answer can
+		// be streamed is a property of the provider and stays as it was.
+		streaming := info.Has(CapabilityStreaming)
+		info.Capabilities = make([]Capability, 0, len(mc.Capabilities)+1)
+		for _, c := range mc.Capabilities {
+			info.Capabilities = append(info.Capabilities, Capability(strings.ToLower(strings.TrimSpace(c))))
+		}
+		if streaming && !info.Has(CapabilityStreaming) {
+			info.Capabilities = append(info.Capabilities, CapabilityStreaming)
+		}
+	}
+	if mc.ContextWindow > 0 {
+		info.ContextWindow = mc.ContextWindow
+	}
+	if mc.MaxTokens > 0 {
+		info.MaxTokens = mc.MaxTokens
+	}
+	return info
+}
+
+// ModelConfigCapabilities returns the capabilities of a model_list entry.
+// Capabilities declared explicitly in config take precedence over the registry.
+func ModelConfigCapabilities(mc *config.ModelConfig) []Capability {
+	return ModelConfigInfo(mc).Capabilities
+}
+
+// HasCapabilities reports whether caps contains every capability in required.
+func HasCapabilities(caps []Capability, required ...Capability) bool {
+	for _, r := range required {
+		found := false
+		for _, c := range caps {
+			if c == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FindModelWithCapabilities returns the first model_list entry supporting
+// all required capabilities, or nil if none is configured.
+func FindModelWithCapabilities(cfg *config.Config, required ...Capability) *config.ModelConfig {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.ModelList {
+		mc := &cfg.ModelList[i]
+		if HasCapabilities(ModelConfigCapabilities(mc), required...) {
+			return mc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestModelCapabilities(t *testing.T) {
+	tests := []struct {
+		model      string
+		wantVision bool
+		wantTools  bool
+	}{
+		{"openai/gpt-4o", true, true},
+		{"anthropic/claude-sonnet-4.6", true, true},
+		{"zhipu/glm-4.7", false, true},
+		{"qwen/qwen-vl-max", true, true},
+		{"deepseek/deepseek-reasoner", false, false},
+		{"ollama/llava", true, false},
+		{"ollama/llava:13b", true, false},
+		{"openrouter/openai/o3-mini", true, true},
+		{"openrouter/meta-llama/llama-4-scout", true, true},
+		// Families match from the start of the ID, not anywhere in it.
+		{"ollama/myo3model", false, true},
+		{"ollama/gemma-vision-test", false, false},
+	}
+
+	for _, tt := range tests {
+		caps := ModelCapabilities(tt.model)
+		if got := HasCapabilities(caps, CapabilityVision); got != tt.wantVision {
+			t.Errorf("%s: vision = %v, want %v", tt.model, got, tt.wantVision)
+		}
+		if got := HasCapabilities(caps, CapabilityTools); got != tt.wantTools {
+			t.Errorf("%s: tools = %v, want %v", tt.model, got, tt.wantTools)
+		}
+	}
+}
+
+func TestModelConfigCapabilities_ExplicitOverride(t *testing.T) {
+	mc := &config.ModelConfig{ModelName: "local", Model: "vllm/my-model", Capabilities: []string{"Vision"}}
+	caps := ModelConfigCapabilities(mc)
+	if !HasCapabilities(caps, CapabilityVision) {
+		t.Error("expected explicit vision capability")
+	}
+	if HasCapabilities(caps, CapabilityTools) {
+		t.Error("explicit capabilities should replace the registry defaults")
+	}
+	if !HasCapabilities(caps, CapabilityStreaming) {
+		t.Error("explicit capabilities should keep the registry's streaming support")
+	}
+	cli := &config.ModelConfig{Model: "claude-cli/claude-sonnet", Capabilities: []string{"tools"}}
+	if HasCapabilities(ModelConfigCapabilities(cli), CapabilityStreaming) {
+		t.Error("explicit capabilities should not enable streaming for a model that cannot stream")
+	}
+}
+
+func TestFindModelWithCapabilities(t *testing.T) {
+	cfg := &config.Config{
+		ModelList: []config.ModelConfig{
+			{ModelName: "glm", Model: "zhipu/glm-4.7"},
+			{ModelName: "vision", Model: "openai/gpt-4o"},
+		},
+	}
+
+	mc := FindModelWithCapabilities(cfg, CapabilityVision, CapabilityTools)
+	if mc == nil || mc.ModelName != "vision" {
+		t.Fatalf("expected vision model, got %+v", mc)
+	}
+
+	cfg.ModelList = cfg.ModelList[:1]
+	if mc := FindModelWithCapabilities(cfg, CapabilityVision); mc != nil {
+		t.Fatalf("expected no match, got %+v", mc)
+	}
+}
+
+func TestLookupModel(t *testing.T) {
+	info := LookupModel("openai/gpt-4o-mini")
+	if info.ContextWindow != 128000 || info.MaxTokens != 16384 {
+		t.Errorf("gpt-4o-mini limits = %d/%d, want 128000/16384", info.ContextWindow, info.MaxTokens)
+	}
+	if !info.Has(CapabilityStreaming) {
+		t.Error("expected streaming for an HTTP provider")
+	}
+
+	info = LookupModel("claude-cli/claude-sonnet-4.6")
+	if info.Has(CapabilityStreaming) {
+		t.Error("CLI providers should not stream")
+	}
+	if !info.Has(CapabilityVision) || info.ContextWindow != 200000 {
+		t.Errorf("claude-cli model info = %+v, want the Claude family's", info)
+	}
+
+	if info := LookupModel("vllm/my-model"); info.ContextWindow != 0 || info.Has(CapabilityVision) ||
+		!info.Has(CapabilityTools) {
+		t.Errorf("unknown model info = %+v, want tools and streaming only", info)
+	}
+}
+
+func TestModelConfigInfo_Overrides(t *testing.T) {
+	mc := &config.ModelConfig{Model: "openai/gpt-4o", ContextWindow: 32000, MaxTokens: 2048}
+	info := ModelConfigInfo(mc)
+	if info.ContextWindow != 32000 || info.MaxTokens != 2048 {
+		t.Errorf("limits = %d/%d, want the config's 32000/2048", info.ContextWindow, info.MaxTokens)
+	}
+	if !info.Has(CapabilityVision) {
+		t.Error("registry capabilities should apply when the entry sets none")
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // ClaudeCliProvider implements LLMProvider using the claude CLI as a subprocess.
 type ClaudeCliProvider struct {
 	command   string
 	workspace string
+	timeout   time.Duration
 }
 
 // NewClaudeCliProvider creates a new Claude CLI provider.
@@ -20,9 +22,33 @@ func NewClaudeCliProvider(workspace string) *ClaudeCliProvider {
 	return &ClaudeCliProvider{
 		command:   "claude",
 		workspace: workspace,
+		timeout:   defaultCLITimeout,
 	}
 }
 
+// SetTimeout bounds each claude run, independently of the caller's
+// context, so a wedged CLI is killed rather than waited on forever.
+func (p *ClaudeCliProvider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// Probe implements CLIProber.
+func (p *ClaudeCliProvider) Probe(ctx context.Context) ProbeResult {
+	res := probeCLI(ctx, p.command, minClaudeCliVersion)
+	if res.Status != "" {
+		return res
+	}
+	login, err := claudeCliLogin()
+	if err != nil {
+		res.Status = ProbeNeedsLogin
+		res.Detail = err.Error()
+		return res
+	}
+	res.Status = ProbeOK
+	res.Detail = login
+	return res
+}
+
 // Chat implements LLMProvider.Chat by executing the claude CLI.
 func (p *ClaudeCliProvider) Chat(
 	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
@@ -39,7 +65,15 @@ func (p *ClaudeCliProvider) Chat(
 	}
 	args = append(args, "-") // read from stdin
 
-	cmd := exec.CommandContext(ctx, p.command, args...)
+	timeout := p.timeout
+	if timeout <= 0 {
+		timeout = defaultCLITimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, p.command, args...)
+	cmd.WaitDelay = cliWaitDelay
 	if p.workspace != "" {
 		cmd.Dir = p.workspace
 	}
@@ -50,13 +84,23 @@ func (p *ClaudeCliProvider) Chat(
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, partialResponse(ctx, p.partialContent(stdout.String()))
+		}
+		if runCtx.Err() != nil {
+			return nil, MapError("claude-cli", errCLITimeout("claude cli", timeout))
+		}
 		if stderrStr := stderr.String(); stderrStr != "" {
-			return nil, fmt.Errorf("claude cli error: %s", stderrStr)
+			return nil, MapError("claude-cli", fmt.Errorf("claude cli error: %s", stderrStr))
 		}
-		return nil, fmt.Errorf("claude cli error: %w", err)
+		return nil, MapError("claude-cli", fmt.Errorf("claude cli error: %w", err))
 	}
 
-	return p.parseClaudeCliResponse(stdout.String())
+	resp, err := p.parseClaudeCliResponse(stdout.String())
+	if err != nil {
+		return nil, MapError("claude-cli", err)
+	}
+	return resp, nil
 }
 
 // GetDefaultModel returns the default model identifier.
@@ -143,6 +187,18 @@ func (p *ClaudeCliProvider) parseClaudeCliResponse(output string) (*LLMResponse,
 	}, nil
 }
 
+// partialContent returns the text in what the claude CLI printed before it
+// was killed: the result if the JSON got out complete, else the raw output.
+func (p *ClaudeCliProvider) partialContent(output string) string {
+	if resp, err := p.parseClaudeCliResponse(output); err == nil {
+		return resp.Content
+	}
+	if strings.HasPrefix(strings.TrimSpace(output), "{") {
+		return "" // cut-off or error JSON
+	}
+	return output
+}
+
 // extractToolCalls delegates to the shared extractToolCallsFromText function.
 func (p *ClaudeCliProvider) extractToolCalls(text string) []ToolCall {
 	return extractToolCallsFromText(text)
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -227,6 +228,51 @@ func TestChat_StderrError(t *testing.T) {
 	}
 }
 
+func TestChat_MapsErrorKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		stderr string
+		kind   error
+	}{
+		{
+			name:   "rate limited result",
+			stdout: `{"type":"result","subtype":"error","is_error":true,"result":"Rate limit exceeded"}`,
+			kind:   ErrRateLimited,
+		},
+		{
+			name:   "prompt too long result",
+			stdout: `{"type":"result","subtype":"error","is_error":true,"result":"Prompt is too long"}`,
+			kind:   ErrContextTooLong,
+		},
+		{
+			name:   "logged out",
+			stderr: `Invalid API key · Please run /login`,
+			kind:   ErrAuth,
+		},
+		{
+			name:   "overloaded",
+			stderr: `API Error: 529 {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			kind:   ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exitCode := 0
+			if tt.stderr != "" {
+				exitCode = 1
+			}
+			p := NewClaudeCliProvider(t.TempDir())
+			p.command = createMockCLI(t, tt.stdout, tt.stderr, exitCode)
+
+			_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "Hello"}}, nil, "", nil)
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("error = %v, want %v", err, tt.kind)
+			}
+		})
+	}
+}
+
 func TestChat_NonZeroExitNoStderr(t *testing.T) {
 	script := createMockCLI(t, "", "", 1)
 
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/anthropics/anthropic-sdk-go/option"
+
+	"github.com/sipeed/picoclaw/pkg/auth"
 	anthropicprovider "github.com/sipeed/picoclaw/pkg/providers/anthropic"
 )
 
@@ -13,30 +16,42 @@ type ClaudeProvider struct {
 
 func NewClaudeProvider(token string) *ClaudeProvider {
 	return &ClaudeProvider{
-		delegate: anthropicprovider.NewProvider(token),
+		delegate: anthropicprovider.NewProviderWithBaseURL(token, "", pooledHTTPClient()),
 	}
 }
 
 func NewClaudeProviderWithBaseURL(token, apiBase string) *ClaudeProvider {
 	return &ClaudeProvider{
-		delegate: anthropicprovider.NewProviderWithBaseURL(token, apiBase),
+		delegate: anthropicprovider.NewProviderWithBaseURL(token, apiBase, pooledHTTPClient()),
 	}
 }
 
 func NewClaudeProviderWithTokenSource(token string, tokenSource func() (string, error)) *ClaudeProvider {
 	return &ClaudeProvider{
-		delegate: anthropicprovider.NewProviderWithTokenSource(token, tokenSource),
+		delegate: anthropicprovider.NewProviderWithTokenSourceAndBaseURL(token, tokenSource, "", pooledHTTPClient()),
 	}
 }
 
 func NewClaudeProviderWithTokenSourceAndBaseURL(
 	token string, tokenSource func() (string, error), apiBase string,
+) *ClaudeProvider {
+	return newClaudeProvider(defaultHTTPClientConfig, token, tokenSource, apiBase)
+}
+
+func newClaudeProvider(
+	pool HTTPClientConfig, token string, tokenSource func() (string, error), apiBase string,
 ) *ClaudeProvider {
 	return &ClaudeProvider{
-		delegate: anthropicprovider.NewProviderWithTokenSourceAndBaseURL(token, tokenSource, apiBase),
+		delegate: anthropicprovider.NewProviderWithTokenSourceAndBaseURL(
+			token, tokenSource, apiBase, option.WithHTTPClient(NewHTTPClient(pool))),
 	}
 }
 
+// pooledHTTPClient makes the SDK client use the shared connection pool.
+func pooledHTTPClient() option.RequestOption {
+	return option.WithHTTPClient(NewHTTPClient(defaultHTTPClientConfig))
+}
+
 func newClaudeProviderWithDelegate(delegate *anthropicprovider.Provider) *ClaudeProvider {
 	return &ClaudeProvider{delegate: delegate}
 }
@@ -46,7 +61,7 @@ func (p *ClaudeProvider) Chat(
 ) (*LLMResponse, error) {
 	resp, err := p.delegate.Chat(ctx, messages, tools, model, options)
 	if err != nil {
-		return nil, err
+		return nil, MapError("claude", err)
 	}
 	return resp, nil
 }
@@ -55,14 +70,15 @@ func (p *ClaudeProvider) GetDefaultModel() string {
 	return p.delegate.GetDefaultModel()
 }
 
-func createClaudeTokenSource() func() (string, error) {
+func createClaudeTokenSource(account string) func() (string, error) {
 	return func() (string, error) {
-		cred, err := getCredential("anthropic")
+		key := auth.CredentialKey("anthropic", account)
+		cred, err := getCredential(key)
 		if err != nil {
 			return "", fmt.Errorf("loading auth credentials: %w", err)
 		}
 		if cred == nil {
-			return "", fmt.Errorf("no credentials for anthropic. Run: picoclaw auth login --provider anthropic")
+			return "", fmt.Errorf("no credentials for %s. Run: %s", key, loginHint("anthropic", account))
 		}
 		return cred.AccessToken, nil
 	}
@@ -0,0 +1,250 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// ProbeStatus classifies the result of probing a CLI provider.
+type ProbeStatus string
+
+const (
+	ProbeOK                 ProbeStatus = "ok"
+	ProbeMissing            ProbeStatus = "missing"
+	ProbeNeedsLogin         ProbeStatus = "needs login"
+	ProbeUnsupportedVersion ProbeStatus = "unsupported version"
+)
+
+const (
+	// defaultCLITimeout bounds one CLI provider call when the model entry
+	// sets no subprocess_timeout.
+	defaultCLITimeout = 10 * time.Minute
+	// probeTimeout bounds the `--version` run of a probe.
+	probeTimeout = 10 * time.Second
+	// cliWaitDelay is how long a killed CLI gets to close its output before
+	// picoclaw stops waiting for it.
+	cliWaitDelay = 2 * time.Second
+)
+
+// Oldest CLI versions with the flags and output format picoclaw uses.
+var (
+	minClaudeCliVersion = [3]int{1, 0, 0}
+	minCodexCliVersion  = [3]int{0, 44, 0}
+)
+
+// ProbeResult is what a probe found out about a CLI provider.
+type ProbeResult struct {
+	Status  ProbeStatus
+	Command string
+	Version string
+	// Detail says what is wrong, or how the CLI is logged in.
+	Detail string
+}
+
+// CLIProber is implemented by the providers that run a local CLI.
+type CLIProber interface {
+	// Probe checks, without touching the network, that the CLI is
+	// installed, new enough, and logged in.
+	Probe(ctx context.Context) ProbeResult
+}
+
+// CLIModelProbe is the probe result of one model_list entry.
+type CLIModelProbe struct {
+	ModelName string
+	Model     string
+	Result    ProbeResult
+}
+
+// ProbeCLIModels probes every model_list entry served by a CLI provider.
+func ProbeCLIModels(ctx context.Context, cfg *config.Config) []CLIModelProbe {
+	var probes []CLIModelProbe
+	for i := range cfg.ModelList {
+		mc := cfg.ModelConfigAt(i)
+		provider, _, err := CreateProviderFromConfig(mc, cfg.Providers.HTTP)
+		if err != nil {
+			continue
+		}
+		prober, ok := provider.(CLIProber)
+		if !ok {
+			continue
+		}
+		probes = append(probes, CLIModelProbe{
+			ModelName: mc.ModelName,
+			Model:     mc.Model,
+			Result:    prober.Probe(ctx),
+		})
+	}
+	return probes
+}
+
+// probeCLI runs `command --version` and checks the version against min.
+// It returns a zero Status when the CLI runs and is new enough.
+func probeCLI(ctx context.Context, command string, min [3]int) ProbeResult {
+	res := ProbeResult{Command: command}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		res.Status = ProbeMissing
+		res.Detail = fmt.Sprintf("%s not found in PATH", command)
+		return res
+	}
+	res.Command = path
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, "--version")
+	cmd.WaitDelay = cliWaitDelay
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		res.Status = ProbeUnsupportedVersion
+		if ctx.Err() != nil {
+			res.Detail = fmt.Sprintf("%s --version timed out after %s", command, probeTimeout)
+		} else {
+			res.Detail = fmt.Sprintf("%s --version failed: %v", command, err)
+		}
+		return res
+	}
+
+	version, ok := parseCLIVersion(out.String())
+	if !ok {
+		res.Status = ProbeUnsupportedVersion
+		res.Detail = fmt.Sprintf("no version in %q", strings.TrimSpace(out.String()))
+		return res
+	}
+	res.Version = fmt.Sprintf("%d.%d.%d", version[0], version[1], version[2])
+	if compareVersions(version, min) < 0 {
+		res.Status = ProbeUnsupportedVersion
+		res.Detail = fmt.Sprintf("version %s is older than %d.%d.%d; upgrade %s",
+			res.Version, min[0], min[1], min[2], command)
+	}
+	return res
+}
+
+var cliVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// parseCLIVersion finds the first x.y.z version in output.
+func parseCLIVersion(output string) ([3]int, bool) {
+	m := cliVersionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return [3]int{}, false
+	}
+	var v [3]int
+	for i := range v {
+		v[i], _ = strconv.Atoi(m[i+1])
+	}
+	return v, true
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// cliTimeout converts a subprocess_timeout in seconds, zero meaning the
+// default.
+func cliTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultCLITimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// errCLITimeout reports a CLI killed at its subprocess timeout. It wraps
+// context.DeadlineExceeded so the fallback chain treats it as unavailable.
+func errCLITimeout(name string, timeout time.Duration) error {
+	return fmt.Errorf("%s timed out after %s (raise subprocess_timeout in model_list if it needs longer): %w",
+		name, timeout, context.DeadlineExceeded)
+}
+
+// claudeCliLogin reports how the claude CLI would authenticate, or an
+// error when it is not logged in. The token itself lives in the macOS
+// keychain on darwin, so the account recorded in .claude.json counts too.
+func claudeCliLogin() (string, error) {
+	for _, env := range []string{"ANTHROPIC_API_KEY", "CLAUDE_CODE_OAUTH_TOKEN"} {
+		if os.Getenv(env) != "" {
+			return env, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home dir: %w", err)
+	}
+	configDir := os.Getenv("CLAUDE_CONFIG_DIR")
+	if configDir == "" {
+		configDir = filepath.Join(home, ".claude")
+	}
+	if _, err := os.Stat(filepath.Join(configDir, ".credentials.json")); err == nil {
+		return "logged in", nil
+	}
+	for _, path := range []string{filepath.Join(configDir, ".claude.json"), filepath.Join(home, ".claude.json")} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var state struct {
+			OAuthAccount *struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"oauthAccount"`
+		}
+		if json.Unmarshal(data, &state) == nil && state.OAuthAccount != nil {
+			if state.OAuthAccount.EmailAddress != "" {
+				return "logged in as " + state.OAuthAccount.EmailAddress, nil
+			}
+			return "logged in", nil
+		}
+	}
+	return "", errors.New("not logged in; run: claude login")
+}
+
+// codexCliLogin reports how the codex CLI would authenticate, or an error
+// when it is not logged in.
+func codexCliLogin() (string, error) {
+	if os.Getenv("CODEX_API_KEY") != "" {
+		return "CODEX_API_KEY", nil
+	}
+	authPath, err := resolveCodexAuthPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(authPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", errors.New("not logged in; run: codex login")
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", authPath, err)
+	}
+	var auth struct {
+		CodexCliAuth
+		APIKey string `json:"OPENAI_API_KEY"`
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", authPath, err)
+	}
+	switch {
+	case auth.Tokens.AccessToken != "":
+		return "logged in with ChatGPT", nil
+	case auth.APIKey != "":
+		return "logged in with an API key", nil
+	}
+	return "", errors.New("not logged in; run: codex login")
+}
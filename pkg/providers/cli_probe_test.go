@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// createVersionCLI writes a script named name that prints output for
+// --version.
+func createVersionCLI(t *testing.T, name, output string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("mock CLI scripts not supported on Windows")
+	}
+	script := filepath.Join(t.TempDir(), name)
+	body := "#!/bin/sh\necho '" + output + "'\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestParseCLIVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   [3]int
+		ok     bool
+	}{
+		{"2.0.14 (Claude Code)", [3]int{2, 0, 14}, true},
+		{"codex-cli 0.46.0", [3]int{0, 46, 0}, true},
+		{"unknown", [3]int{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseCLIVersion(tt.output)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseCLIVersion(%q) = %v, %v; want %v, %v", tt.output, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClaudeCliProvider_Probe(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", "")
+	t.Setenv("CLAUDE_CODE_OAUTH_TOKEN", "")
+
+	p := NewClaudeCliProvider(t.TempDir())
+	p.command = filepath.Join(t.TempDir(), "claude")
+	if res := p.Probe(context.Background()); res.Status != ProbeMissing {
+		t.Errorf("missing CLI: got %+v", res)
+	}
+
+	p.command = createVersionCLI(t, "claude", "0.2.9 (Claude Code)")
+	if res := p.Probe(context.Background()); res.Status != ProbeUnsupportedVersion || res.Version != "0.2.9" {
+		t.Errorf("old CLI: got %+v", res)
+	}
+
+	p.command = createVersionCLI(t, "claude", "2.0.14 (Claude Code)")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if res := p.Probe(context.Background()); res.Status != ProbeNeedsLogin {
+		t.Errorf("logged out: got %+v", res)
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test")
+	if res := p.Probe(context.Background()); res.Status != ProbeOK || res.Version != "2.0.14" {
+		t.Errorf("logged in: got %+v", res)
+	}
+}
+
+func TestCodexCliProvider_Probe(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	t.Setenv("CODEX_API_KEY", "")
+
+	p := &CodexCliProvider{command: createVersionCLI(t, "codex", "codex-cli 0.46.0")}
+	if res := p.Probe(context.Background()); res.Status != ProbeNeedsLogin {
+		t.Errorf("no auth.json: got %+v", res)
+	}
+
+	auth := `{"tokens":{"access_token":"tok","account_id":"acct"}}`
+	if err := os.WriteFile(filepath.Join(codexHome, "auth.json"), []byte(auth), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if res := p.Probe(context.Background()); res.Status != ProbeOK {
+		t.Errorf("logged in: got %+v", res)
+	}
+}
+
+func TestClaudeCliProvider_SubprocessTimeout(t *testing.T) {
+	p := NewClaudeCliProvider(t.TempDir())
+	p.command = createSlowMockCLI(t, 5)
+	p.SetTimeout(200 * time.Millisecond)
+
+	start := time.Now()
+	_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "Hello"}}, nil, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Fatalf("err = %v, want a subprocess timeout", err)
+	}
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("err = %v, want it classified as unavailable", err)
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("Chat() took %v, want the CLI killed at the timeout", elapsed)
+	}
+}
+
+func TestProbeCLIModels(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ModelList = []config.ModelConfig{
+		{ModelName: "gpt", Model: "openai/gpt-4o", APIKey: "sk-test"},
+		{ModelName: "codex", Model: "codex-cli/codex-cli", SubprocessTimeout: 30},
+	}
+	probes := ProbeCLIModels(context.Background(), cfg)
+	if len(probes) != 1 || probes[0].ModelName != "codex" {
+		t.Fatalf("probes = %+v, want only the codex-cli entry", probes)
+	}
+
+	provider, _, err := CreateProviderFromConfig(&cfg.ModelList[1], defaultHTTPClientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := provider.(*CodexCliProvider).timeout; got != 30*time.Second {
+		t.Errorf("timeout = %v, want 30s", got)
+	}
+}
@@ -0,0 +1,146 @@
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// CoalescingProvider merges identical Chat requests, e.g. from a webhook
+// delivered twice. A request with the same model, messages, tools and options
+// as one still in flight, started at most window before, waits for that call
+// and shares its response. Finished responses are never reused: asking the
+// same thing again later gets a fresh answer. Only the first caller gets the
+// response's usage, so the call is counted once.
+type CoalescingProvider struct {
+	inner  LLMProvider
+	window time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done    chan struct{}
+	resp    *LLMResponse
+	err     error
+	started time.Time
+}
+
+// NewCoalescingProvider wraps inner so that an identical request made while
+// another is in flight, within window of its start, makes no upstream call
+// of its own.
+func NewCoalescingProvider(inner LLMProvider, window time.Duration) *CoalescingProvider {
+	return &CoalescingProvider{
+		inner:  inner,
+		window: window,
+		now:    time.Now,
+		calls:  make(map[string]*coalescedCall),
+	}
+}
+
+func (p *CoalescingProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	key, err := coalesceKey(messages, tools, model, options)
+	if err != nil {
+		return p.inner.Chat(ctx, messages, tools, model, options)
+	}
+
+	p.mu.Lock()
+	now := p.now()
+	if call, ok := p.calls[key]; ok && now.Sub(call.started) <= p.window {
+		p.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// The first caller's cancellation is not ours to share.
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			return p.inner.Chat(ctx, messages, tools, model, options)
+		}
+		logger.DebugCF("provider", "Reusing response of an identical request", map[string]any{"model": model})
+		return sharedResponse(call.resp), call.err
+	}
+	call := &coalescedCall{done: make(chan struct{}), started: now}
+	p.calls[key] = call
+	p.mu.Unlock()
+
+	call.resp, call.err = p.inner.Chat(ctx, messages, tools, model, options)
+
+	// The result goes to the requests already waiting only.
+	p.mu.Lock()
+	if p.calls[key] == call {
+		delete(p.calls, key)
+	}
+	p.mu.Unlock()
+	close(call.done)
+	return call.resp, call.err
+}
+
+func (p *CoalescingProvider) GetDefaultModel() string {
+	return p.inner.GetDefaultModel()
+}
+
+// Close closes the wrapped provider when it holds resources.
+func (p *CoalescingProvider) Close() {
+	if sp, ok := p.inner.(StatefulProvider); ok {
+		sp.Close()
+	}
+}
+
+// ChatStream streams through the wrapped provider without coalescing: a
+// streamed reply goes to one chat, so there is nothing to share. A wrapped
+// provider that cannot stream answers through Chat, with no deltas.
+func (p *CoalescingProvider) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(string),
+) (*LLMResponse, error) {
+	if sp, ok := p.inner.(StreamingProvider); ok {
+		return sp.ChatStream(ctx, messages, tools, model, options, onDelta)
+	}
+	return p.Chat(ctx, messages, tools, model, options)
+}
+
+// coalesceKey hashes everything that determines a response.
+func coalesceKey(messages []Message, tools []ToolDefinition, model string, options map[string]any) (string, error) {
+	data, err := json.Marshal(struct {
+		Model    string           `json:"model"`
+		Messages []Message        `json:"messages"`
+		Tools    []ToolDefinition `json:"tools"`
+		Options  map[string]any   `json:"options"`
+	}{model, messages, tools, options})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sharedResponse copies resp for another caller, without its usage.
+func sharedResponse(resp *LLMResponse) *LLMResponse {
+	if resp == nil {
+		return nil
+	}
+	shared := *resp
+	shared.Usage = nil
+	shared.ToolCalls = slices.Clone(resp.ToolCalls)
+	return &shared
+}
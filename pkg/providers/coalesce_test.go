@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedProvider counts Chat calls and holds each until release is closed.
+type gatedProvider struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (p *gatedProvider) Chat(
+	ctx context.Context, _ []Message, _ []ToolDefinition, _ string, _ map[string]any,
+) (*LLMResponse, error) {
+	p.calls.Add(1)
+	p.started <- struct{}{}
+	<-p.release
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &LLMResponse{Content: "pong", Usage: &UsageInfo{PromptTokens: 10, CompletionTokens: 2}}, nil
+}
+
+func (p *gatedProvider) GetDefaultModel() string { return "test" }
+
+func newGatedProvider() *gatedProvider {
+	return &gatedProvider{started: make(chan struct{}, 8), release: make(chan struct{})}
+}
+
+func TestCoalescingProvider_ConcurrentIdenticalRequests(t *testing.T) {
+	inner := newGatedProvider()
+	p := NewCoalescingProvider(inner, time.Second)
+	messages := []Message{{Role: "user", Content: "ping"}}
+
+	var wg sync.WaitGroup
+	responses := make([]*LLMResponse, 2)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := p.Chat(context.Background(), messages, nil, "gpt-4", nil)
+			if err != nil {
+				t.Errorf("Chat %d: %v", i, err)
+			}
+			responses[i] = resp
+		}()
+	}
+	<-inner.started
+	// Give the second request time to join the first before it finishes.
+	time.Sleep(50 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	if n := inner.calls.Load(); n != 1 {
+		t.Fatalf("upstream calls = %d, want 1", n)
+	}
+	usages := 0
+	for _, resp := range responses {
+		if resp == nil || resp.Content != "pong" {
+			t.Fatalf("response = %+v, want pong", resp)
+		}
+		if resp.Usage != nil {
+			usages++
+		}
+	}
+	if usages != 1 {
+		t.Errorf("%d responses carry usage, want 1", usages)
+	}
+}
+
+func TestCoalescingProvider_FinishedAndDistinctRequests(t *testing.T) {
+	inner := newGatedProvider()
+	close(inner.release)
+	p := NewCoalescingProvider(inner, time.Minute)
+	ping := []Message{{Role: "user", Content: "ping"}}
+
+	p.Chat(context.Background(), ping, nil, "gpt-4", nil)
+	p.Chat(context.Background(), ping, nil, "gpt-4", nil)
+	if n := inner.calls.Load(); n != 2 {
+		t.Fatalf("upstream calls after the first finished = %d, want 2", n)
+	}
+
+	p.Chat(context.Background(), ping, nil, "claude", nil)
+	p.Chat(context.Background(), []Message{{Role: "user", Content: "pong"}}, nil, "gpt-4", nil)
+	if n := inner.calls.Load(); n != 4 {
+		t.Fatalf("upstream calls for distinct requests = %d, want 4", n)
+	}
+}
+
+func TestCoalescingProvider_InFlightPastWindow(t *testing.T) {
+	inner := newGatedProvider()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	p := NewCoalescingProvider(inner, 2*time.Second)
+	p.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	ping := []Message{{Role: "user", Content: "ping"}}
+
+	first := make(chan struct{})
+	go func() {
+		defer close(first)
+		p.Chat(context.Background(), ping, nil, "gpt-4", nil)
+	}()
+	<-inner.started
+
+	mu.Lock()
+	now = now.Add(5 * time.Second)
+	mu.Unlock()
+	second := make(chan struct{})
+	go func() {
+		defer close(second)
+		p.Chat(context.Background(), ping, nil, "gpt-4", nil)
+	}()
+	<-inner.started // the late request makes its own call
+	close(inner.release)
+	<-first
+	<-second
+
+	if n := inner.calls.Load(); n != 2 {
+		t.Errorf("upstream calls = %d, want 2", n)
+	}
+}
+
+func TestCoalescingProvider_FailureNotReused(t *testing.T) {
+	inner := newGatedProvider()
+	inner.err = errors.New("boom")
+	close(inner.release)
+	p := NewCoalescingProvider(inner, time.Minute)
+	ping := []Message{{Role: "user", Content: "ping"}}
+
+	if _, err := p.Chat(context.Background(), ping, nil, "gpt-4", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	p.Chat(context.Background(), ping, nil, "gpt-4", nil)
+	if n := inner.calls.Load(); n != 2 {
+		t.Errorf("upstream calls = %d, want a retry after a failure", n)
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // CodexCliProvider implements LLMProvider by wrapping the codex CLI as a subprocess.
 type CodexCliProvider struct {
 	command   string
 	workspace string
+	timeout   time.Duration
 }
 
 // NewCodexCliProvider creates a new Codex CLI provider.
@@ -21,9 +23,35 @@ func NewCodexCliProvider(workspace string) *CodexCliProvider {
 	return &CodexCliProvider{
 		command:   "codex",
 		workspace: workspace,
+		timeout:   defaultCLITimeout,
 	}
 }
 
+// SetTimeout bounds each codex run, independently of the caller's context.
+func (p *CodexCliProvider) SetTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+// Probe implements CLIProber.
+func (p *CodexCliProvider) Probe(ctx context.Context) ProbeResult {
+	if p.command == "" {
+		return ProbeResult{Status: ProbeMissing, Detail: "codex command not configured"}
+	}
+	res := probeCLI(ctx, p.command, minCodexCliVersion)
+	if res.Status != "" {
+		return res
+	}
+	login, err := codexCliLogin()
+	if err != nil {
+		res.Status = ProbeNeedsLogin
+		res.Detail = err.Error()
+		return res
+	}
+	res.Status = ProbeOK
+	res.Detail = login
+	return res
+}
+
 // Chat implements LLMProvider.Chat by executing the codex CLI in non-interactive mode.
 func (p *CodexCliProvider) Chat(
 	ctx context.Context, messages []Message, tools []ToolDefinition, model string, options map[string]any,
@@ -49,7 +77,15 @@ func (p *CodexCliProvider) Chat(
 	}
 	args = append(args, "-") // read prompt from stdin
 
-	cmd := exec.CommandContext(ctx, p.command, args...)
+	timeout := p.timeout
+	if timeout <= 0 {
+		timeout = defaultCLITimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, p.command, args...)
+	cmd.WaitDelay = cliWaitDelay
 	cmd.Stdin = bytes.NewReader([]byte(prompt))
 
 	var stdout, stderr bytes.Buffer
@@ -58,6 +94,19 @@ func (p *CodexCliProvider) Chat(
 
 	err := cmd.Run()
 
+	// Cancelled: whatever messages codex completed before the kill are all
+	// the caller gets.
+	if ctx.Err() != nil {
+		var content string
+		if resp, parseErr := p.parseJSONLEvents(stdout.String()); parseErr == nil {
+			content = resp.Content
+		}
+		return nil, partialResponse(ctx, content)
+	}
+	if runCtx.Err() != nil {
+		return nil, MapError("codex-cli", errCLITimeout("codex cli", timeout))
+	}
+
 	// Parse JSONL from stdout even if exit code is non-zero,
 	// because codex writes diagnostic noise to stderr (e.g. rollout errors)
 	// but still produces valid JSONL output.
@@ -69,16 +118,21 @@ func (p *CodexCliProvider) Chat(
 	}
 
 	if err != nil {
-		if ctx.Err() == context.Canceled {
-			return nil, ctx.Err()
+		// A failed turn explains itself in the JSONL; stderr is mostly noise.
+		if _, parseErr := p.parseJSONLEvents(stdout.String()); parseErr != nil {
+			return nil, MapError("codex-cli", parseErr)
 		}
 		if stderrStr := stderr.String(); stderrStr != "" {
-			return nil, fmt.Errorf("codex cli error: %s", stderrStr)
+			return nil, MapError("codex-cli", fmt.Errorf("codex cli error: %s", stderrStr))
 		}
-		return nil, fmt.Errorf("codex cli error: %w", err)
+		return nil, MapError("codex-cli", fmt.Errorf("codex cli error: %w", err))
 	}
 
-	return p.parseJSONLEvents(stdout.String())
+	resp, err := p.parseJSONLEvents(stdout.String())
+	if err != nil {
+		return nil, MapError("codex-cli", err)
+	}
+	return resp, nil
 }
 
 // GetDefaultModel returns the default model identifier.
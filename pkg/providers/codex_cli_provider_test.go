@@ -3,12 +3,14 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 // --- JSONL Event Parsing Tests ---
@@ -470,6 +472,30 @@ func TestCodexCliProvider_MockCLI_Error(t *testing.T) {
 	}
 }
 
+func TestCodexCliProvider_MockCLI_MapsErrorKinds(t *testing.T) {
+	tests := []struct {
+		message string
+		kind    error
+	}{
+		{"Your access token could not be refreshed. Please log out and sign in again. (401 Unauthorized)", ErrAuth},
+		{"You have hit your usage limit. Upgrade to Pro or try again in 2h.", ErrRateLimited},
+		{"Codex ran out of room in the context window. Start a new conversation.", ErrContextTooLong},
+		{"stream disconnected before completion: error sending request: timed out", ErrUnavailable},
+	}
+	for _, tt := range tests {
+		scriptPath := createMockCodexCLI(t, []string{
+			`{"type":"turn.started"}`,
+			`{"type":"turn.failed","error":{"message":"` + tt.message + `"}}`,
+		})
+		p := &CodexCliProvider{command: scriptPath}
+
+		_, err := p.Chat(context.Background(), []Message{{Role: "user", Content: "Hello"}}, nil, "", nil)
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("%q: error = %v, want %v", tt.message, err, tt.kind)
+		}
+	}
+}
+
 func TestCodexCliProvider_MockCLI_WithModel(t *testing.T) {
 	// Mock script that captures args to verify model flag is passed
 	tmpDir := t.TempDir()
@@ -541,6 +567,34 @@ func TestCodexCliProvider_MockCLI_ContextCancel(t *testing.T) {
 	}
 }
 
+func TestCodexCliProvider_MockCLI_ContextCancelKeepsOutput(t *testing.T) {
+	// Script that finishes one message, then hangs until killed
+	tmpDir := t.TempDir()
+	scriptPath := filepath.Join(tmpDir, "codex")
+	script := `#!/bin/bash
+echo '{"type":"item.completed","item":{"id":"1","type":"agent_message","text":"Step one is done."}}'
+exec sleep 60`
+
+	if err := os.WriteFile(scriptPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &CodexCliProvider{command: scriptPath}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	messages := []Message{{Role: "user", Content: "test"}}
+	_, err := p.Chat(ctx, messages, nil, "", nil)
+	var partial *PartialResponseError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected a partial response, got %v", err)
+	}
+	if partial.Content != "Step one is done." {
+		t.Errorf("partial content = %q", partial.Content)
+	}
+}
+
 func TestCodexCliProvider_EmptyCommand(t *testing.T) {
 	p := &CodexCliProvider{command: ""}
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/openai/openai-go/v3"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 const (
@@ -27,14 +29,32 @@ type CodexProvider struct {
 	enableWebSearch bool
 }
 
-const defaultCodexInstructions = "You are Codex, a coding assistant."
+const (
+	defaultCodexInstructions = "You are Codex, a coding assistant."
+	defaultCodexBaseURL      = "https://chatgpt.com/backend-api/codex"
+)
 
 func NewCodexProvider(token, accountID string) *CodexProvider {
+	return NewCodexProviderWithBaseURL(token, accountID, "")
+}
+
+// NewCodexProviderWithBaseURL sends requests to apiBase instead of the
+// ChatGPT backend, e.g. through a corporate proxy. An empty apiBase uses the
+// default.
+func NewCodexProviderWithBaseURL(token, accountID, apiBase string) *CodexProvider {
+	return newCodexProvider(defaultHTTPClientConfig, token, accountID, apiBase)
+}
+
+func newCodexProvider(pool HTTPClientConfig, token, accountID, apiBase string) *CodexProvider {
+	if apiBase == "" {
+		apiBase = defaultCodexBaseURL
+	}
 	opts := []option.RequestOption{
-		option.WithBaseURL("https://chatgpt.com/backend-api/codex"),
+		option.WithBaseURL(apiBase),
 		option.WithAPIKey(token),
 		option.WithHeader("originator", "codex_cli_rs"),
 		option.WithHeader("OpenAI-Beta", "responses=experimental"),
+		option.WithHTTPClient(NewHTTPClient(pool)),
 	}
 	if accountID != "" {
 		opts = append(opts, option.WithHeader("Chatgpt-Account-Id", accountID))
@@ -50,7 +70,13 @@ func NewCodexProvider(token, accountID string) *CodexProvider {
 func NewCodexProviderWithTokenSource(
 	token, accountID string, tokenSource func() (string, string, error),
 ) *CodexProvider {
-	p := NewCodexProvider(token, accountID)
+	return NewCodexProviderWithTokenSourceAndBaseURL(token, accountID, tokenSource, "")
+}
+
+func NewCodexProviderWithTokenSourceAndBaseURL(
+	token, accountID string, tokenSource func() (string, string, error), apiBase string,
+) *CodexProvider {
+	p := NewCodexProviderWithBaseURL(token, accountID, apiBase)
 	p.tokenSource = tokenSource
 	return p
 }
@@ -101,8 +127,12 @@ func (p *CodexProvider) Chat(
 	defer stream.Close()
 
 	var resp *responses.Response
+	var streamed strings.Builder
 	for stream.Next() {
 		evt := stream.Current()
+		if evt.Type == "response.output_text.delta" {
+			streamed.WriteString(evt.Delta)
+		}
 		if evt.Type == "response.completed" || evt.Type == "response.failed" || evt.Type == "response.incomplete" {
 			evtResp := evt.Response
 			if evtResp.ID != "" {
@@ -112,6 +142,9 @@ func (p *CodexProvider) Chat(
 		}
 	}
 	err := stream.Err()
+	if err != nil && ctx.Err() != nil {
+		return nil, partialResponse(ctx, streamed.String())
+	}
 	if err != nil {
 		fields := map[string]any{
 			"requested_model":    model,
@@ -136,7 +169,15 @@ func (p *CodexProvider) Chat(
 			}
 		}
 		logger.ErrorCF("provider.codex", "Codex API call failed", fields)
-		return nil, fmt.Errorf("codex API call: %w", err)
+		err = fmt.Errorf("codex API call: %w", err)
+		if apiErr != nil {
+			var header http.Header
+			if apiErr.Response != nil {
+				header = apiErr.Response.Header
+			}
+			return nil, protocoltypes.HTTPError("codex", apiErr.StatusCode, header, apiErr.RawJSON(), err)
+		}
+		return nil, MapError("codex", err)
 	}
 	if resp == nil {
 		fields := map[string]any{
@@ -411,14 +452,15 @@ func parseCodexResponse(resp *responses.Response) *LLMResponse {
 	}
 }
 
-func createCodexTokenSource() func() (string, string, error) {
+func createCodexTokenSource(account string) func() (string, string, error) {
 	return func() (string, string, error) {
-		cred, err := auth.GetCredential("openai")
+		key := auth.CredentialKey("openai", account)
+		cred, err := auth.GetCredential(key)
 		if err != nil {
 			return "", "", fmt.Errorf("loading auth credentials: %w", err)
 		}
 		if cred == nil {
-			return "", "", fmt.Errorf("no credentials for openai. Run: picoclaw auth login --provider openai")
+			return "", "", fmt.Errorf("no credentials for %s. Run: %s", key, loginHint("openai", account))
 		}
 
 		if cred.AuthMethod == "oauth" && cred.NeedsRefresh() && cred.RefreshToken != "" {
@@ -430,7 +472,7 @@ func createCodexTokenSource() func() (string, string, error) {
 			if refreshed.AccountID == "" {
 				refreshed.AccountID = cred.AccountID
 			}
-			if err := auth.SetCredential("openai", refreshed); err != nil {
+			if err := auth.SetCredential(key, refreshed); err != nil {
 				return "", "", fmt.Errorf("saving refreshed token: %w", err)
 			}
 			return refreshed.AccessToken, refreshed.AccountID, nil
@@ -2,9 +2,11 @@ package providers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/openai/openai-go/v3"
@@ -643,3 +645,59 @@ func writeCompletedSSE(w http.ResponseWriter, response map[string]any) {
 	fmt.Fprintf(w, "data: %s\n\n", string(b))
 	fmt.Fprintf(w, "data: [DONE]\n\n")
 }
+
+func TestNewCodexProviderWithBaseURL(t *testing.T) {
+	paths := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case paths <- r.URL.Path:
+		default:
+		}
+		http.Error(w, `{"error":{"message":"stop"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	provider := NewCodexProviderWithBaseURL("codex-token", "acct", server.URL+"/codex")
+	_, _ = provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-5.2", nil)
+
+	select {
+	case path := <-paths:
+		if !strings.HasPrefix(path, "/codex/") {
+			t.Errorf("request path = %q, want it under the base URL", path)
+		}
+	default:
+		t.Fatal("request did not reach the base URL")
+	}
+}
+
+func TestCodexProvider_ChatMapsErrorKinds(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		kind   error
+	}{
+		{http.StatusUnauthorized, `{"error":{"message":"Your authentication token has expired.","code":"token_expired"}}`, ErrAuth},
+		{
+			http.StatusBadRequest,
+			`{"error":{"message":"Your input exceeds the context window of this model.","code":"context_length_exceeded"}}`,
+			ErrContextTooLong,
+		},
+		{http.StatusBadRequest, `{"error":{"message":"Unsupported parameter: 'temperature'"}}`, ErrBadRequest},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, tt.body)
+		}))
+
+		provider := NewCodexProviderWithBaseURL("codex-token", "acct", server.URL)
+		_, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-5.2", nil)
+		server.Close()
+
+		var pe *ProviderError
+		if !errors.Is(err, tt.kind) || !errors.As(err, &pe) || pe.Status != tt.status {
+			t.Errorf("HTTP %d: error = %v, want %v", tt.status, err, tt.kind)
+		}
+	}
+}
@@ -8,6 +8,9 @@ import (
 
 const (
 	defaultFailureWindow = 24 * time.Hour
+	// defaultQuotaCooldown is how long a provider whose quota is used up is
+	// skipped when it does not say when the quota resets.
+	defaultQuotaCooldown = 5 * time.Hour
 )
 
 // CooldownTracker manages per-provider cooldown state for the fallback chain.
@@ -23,8 +26,8 @@ type cooldownEntry struct {
 	ErrorCount     int
 	FailureCounts  map[FailoverReason]int
 	CooldownEnd    time.Time      // standard cooldown expiry
-	DisabledUntil  time.Time      // billing-specific disable expiry
-	DisabledReason FailoverReason // reason for disable (billing)
+	DisabledUntil  time.Time      // billing and quota disable expiry
+	DisabledReason FailoverReason // reason for disable (billing, quota)
 	LastFailure    time.Time
 }
 
@@ -56,15 +59,31 @@ func (ct *CooldownTracker) MarkFailure(provider string, reason FailoverReason) {
 	entry.FailureCounts[reason]++
 	entry.LastFailure = now
 
-	if reason == FailoverBilling {
+	switch reason {
+	case FailoverBilling:
 		billingCount := entry.FailureCounts[FailoverBilling]
 		entry.DisabledUntil = now.Add(calculateBillingCooldown(billingCount))
 		entry.DisabledReason = FailoverBilling
-	} else {
+	case FailoverQuota:
+		entry.DisabledUntil = now.Add(defaultQuotaCooldown)
+		entry.DisabledReason = FailoverQuota
+	default:
 		entry.CooldownEnd = now.Add(calculateStandardCooldown(entry.ErrorCount))
 	}
 }
 
+// MarkQuotaExhausted records that a provider's quota is used up and skips it
+// until resetIn has passed, or for defaultQuotaCooldown when resetIn is 0.
+func (ct *CooldownTracker) MarkQuotaExhausted(provider string, resetIn time.Duration) {
+	ct.MarkFailure(provider, FailoverQuota)
+	if resetIn <= 0 {
+		return
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.entries[provider].DisabledUntil = ct.nowFunc().Add(resetIn)
+}
+
 // MarkSuccess resets all counters and cooldowns for a provider.
 func (ct *CooldownTracker) MarkSuccess(provider string) {
 	ct.mu.Lock()
@@ -92,6 +92,28 @@ func TestCooldown_BillingEscalation(t *testing.T) {
 	}
 }
 
+func TestCooldown_QuotaExhausted(t *testing.T) {
+	now := time.Now()
+	ct, current := newTestTracker(now)
+
+	// Known reset → disabled until then, well past a rate-limit cooldown
+	ct.MarkQuotaExhausted("antigravity", 3*time.Hour)
+	*current = now.Add(2 * time.Hour)
+	if ct.IsAvailable("antigravity") {
+		t.Error("should be disabled until the quota resets")
+	}
+	*current = now.Add(3*time.Hour + time.Second)
+	if !ct.IsAvailable("antigravity") {
+		t.Error("should be available once the quota resets")
+	}
+
+	// Unknown reset → the default quota cooldown
+	ct.MarkQuotaExhausted("gemini", 0)
+	if got := ct.CooldownRemaining("gemini"); got != defaultQuotaCooldown {
+		t.Errorf("CooldownRemaining() = %v, want %v", got, defaultQuotaCooldown)
+	}
+}
+
 func TestCooldown_BillingCap(t *testing.T) {
 	expected := []time.Duration{
 		5 * time.Hour,
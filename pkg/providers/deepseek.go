@@ -0,0 +1,17 @@
+package providers
+
+import "time"
+
+// DeepSeek bills API calls at a discount between 16:30 and 00:30 UTC.
+const (
+	deepSeekOffPeakStart = 16*time.Hour + 30*time.Minute
+	deepSeekOffPeakEnd   = 30 * time.Minute
+)
+
+// InDeepSeekOffPeak reports whether t falls in DeepSeek's discounted
+// off-peak window.
+func InDeepSeekOffPeak(t time.Time) bool {
+	t = t.UTC()
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	return sinceMidnight >= deepSeekOffPeakStart || sinceMidnight < deepSeekOffPeakEnd
+}
@@ -0,0 +1,30 @@
+package providers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInDeepSeekOffPeak(t *testing.T) {
+	tests := []struct {
+		at   string
+		want bool
+	}{
+		{"2026-01-01T16:29:00Z", false},
+		{"2026-01-01T16:30:00Z", true},
+		{"2026-01-01T23:59:00Z", true},
+		{"2026-01-02T00:29:00Z", true},
+		{"2026-01-02T00:30:00Z", false},
+		{"2026-01-02T08:30:00+08:00", false},
+		{"2026-01-02T08:00:00+08:00", true},
+	}
+	for _, tt := range tests {
+		at, err := time.Parse(time.RFC3339, tt.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := InDeepSeekOffPeak(at); got != tt.want {
+			t.Errorf("InDeepSeekOffPeak(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
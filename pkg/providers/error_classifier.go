@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"regexp"
 	"strings"
 )
@@ -93,6 +94,9 @@ var (
 		rxp(`image exceeds.*mb`),
 	}
 
+	// A wait stated in a rate-limit message, e.g. "try again in 1m30s".
+	retryAfterPattern = regexp.MustCompile(`(?:try again|retry) (?:in|after) ((?:\d+(?:\.\d+)?(?:ms|s|m|h))+)`)
+
 	// Transient HTTP status codes that map to timeout (server-side failures).
 	transientStatusCodes = map[int]bool{
 		500: true, 502: true, 503: true,
@@ -123,6 +127,28 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 		}
 	}
 
+	// Errors the provider already mapped onto an error kind.
+	var pe *ProviderError
+	if errors.As(err, &pe) && pe.Kind != nil {
+		return &FailoverError{
+			Reason:   failoverReason(pe),
+			Provider: provider,
+			Model:    model,
+			Status:   pe.Status,
+			Wrapped:  err,
+		}
+	}
+
+	if errors.Is(err, ErrQuotaExhausted) {
+		return &FailoverError{
+			Reason:   FailoverQuota,
+			Provider: provider,
+			Model:    model,
+			Status:   429,
+			Wrapped:  err,
+		}
+	}
+
 	msg := strings.ToLower(err.Error())
 
 	// Image dimension/size errors: non-retriable, non-fallback.
@@ -161,6 +187,28 @@ func ClassifyError(err error, provider, model string) *FailoverError {
 	return nil
 }
 
+// failoverReason maps an error kind to the FailoverReason that drives
+// fallback and cooldowns.
+func failoverReason(pe *ProviderError) FailoverReason {
+	switch {
+	case errors.Is(pe.Kind, ErrAuth):
+		if pe.Status == 402 || matchesAny(strings.ToLower(pe.Error()), billingPatterns) {
+			return FailoverBilling
+		}
+		return FailoverAuth
+	case errors.Is(pe.Kind, ErrRateLimited):
+		return FailoverRateLimit
+	case errors.Is(pe.Kind, ErrUnavailable):
+		if matchesAny(strings.ToLower(pe.Error()), overloadedPatterns) {
+			return FailoverRateLimit // as classifyByMessage does
+		}
+		return FailoverTimeout
+	}
+	// Bad requests, too-long contexts and filtered content fail the same
+	// way on every candidate.
+	return FailoverFormat
+}
+
 // classifyByStatus maps HTTP status codes to FailoverReason.
 func classifyByStatus(status int) FailoverReason {
 	switch {
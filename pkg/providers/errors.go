@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+// ProviderError is a provider failure classified as one of the error kinds
+// below; see protocoltypes.ProviderError.
+type ProviderError = protocoltypes.ProviderError
+
+// Error kinds every provider maps its failures onto. Match them with
+// errors.Is; errors.As with *ProviderError gives the status and, for
+// ErrRateLimited, how long to wait.
+var (
+	ErrAuth            = protocoltypes.ErrAuth
+	ErrRateLimited     = protocoltypes.ErrRateLimited
+	ErrContextTooLong  = protocoltypes.ErrContextTooLong
+	ErrContentFiltered = protocoltypes.ErrContentFiltered
+	ErrUnavailable     = protocoltypes.ErrUnavailable
+	ErrBadRequest      = protocoltypes.ErrBadRequest
+)
+
+// MapError maps a provider failure onto one of the error kinds, keeping err
+// as the cause. Providers that get a status code build the ProviderError
+// themselves; this fills in the rest from the error, so it also covers
+// CLI and SDK providers that only have a message. Cancellation and errors
+// that match no kind are returned unchanged.
+func MapError(provider string, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+	var pe *ProviderError
+	if errors.As(err, &pe) {
+		if pe.Provider == "" {
+			pe.Provider = provider
+		}
+		return err
+	}
+
+	mapped := &ProviderError{Provider: provider, Err: err}
+	var quota *QuotaExhaustedError
+	var netErr net.Error
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.As(err, &quota):
+		mapped.Kind = ErrRateLimited
+		mapped.RetryAfter, _ = time.ParseDuration(quota.ResetAfter)
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		mapped.Kind = ErrUnavailable
+	default:
+		mapped.Status = extractHTTPStatus(msg)
+		mapped.Kind = kindFromMessage(mapped.Status, msg)
+	}
+	if mapped.Kind == nil {
+		return err
+	}
+	if mapped.Kind == ErrRateLimited && mapped.RetryAfter == 0 {
+		mapped.RetryAfter = retryAfterFromMessage(msg)
+	}
+	return mapped
+}
+
+// kindFromMessage classifies an error known only by its message, using the
+// status code found in it when there is one.
+func kindFromMessage(status int, msg string) error {
+	if status == 0 && matchesAny(msg, rateLimitPatterns) {
+		return ErrRateLimited
+	}
+	if kind := protocoltypes.KindFor(status, msg); kind != nil {
+		return kind
+	}
+	switch classifyByMessage(msg) {
+	case FailoverRateLimit:
+		if matchesAny(msg, overloadedPatterns) {
+			return ErrUnavailable
+		}
+		return ErrRateLimited
+	case FailoverAuth, FailoverBilling:
+		return ErrAuth
+	case FailoverTimeout:
+		return ErrUnavailable
+	case FailoverFormat:
+		return ErrBadRequest
+	}
+	return nil
+}
+
+// retryAfterFromMessage finds a wait in messages like "try again in 20s"
+// or "Please retry after 1m30s".
+func retryAfterFromMessage(msg string) time.Duration {
+	m := retryAfterPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return 0
+	}
+	d, err := time.ParseDuration(strings.ReplaceAll(m[1], " ", ""))
+	if err != nil {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,106 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+		wait time.Duration
+	}{
+		{"rate limit message", errors.New("rate limit reached, please try again in 20s."), ErrRateLimited, 20 * time.Second},
+		{"status in message", errors.New("copilot: request failed with status: 429"), ErrRateLimited, 0},
+		{"quota", &QuotaExhaustedError{Provider: "antigravity", ResetAfter: "1h2m"}, ErrRateLimited, time.Hour + 2*time.Minute},
+		{"context", errors.New("claude cli error: Prompt is too long"), ErrContextTooLong, 0},
+		{"content filter", errors.New("finish_reason: content_filter"), ErrContentFiltered, 0},
+		{"auth", errors.New("codex cli: Your access token could not be refreshed: invalid token"), ErrAuth, 0},
+		{"billing", errors.New("insufficient credits for this request"), ErrAuth, 0},
+		{"timeout", fmt.Errorf("failed to send request: %w", context.DeadlineExceeded), ErrUnavailable, 0},
+		{"overloaded", errors.New(`{"type":"overloaded_error"}`), ErrUnavailable, 0},
+		{"server error", errors.New("API request failed: status 502"), ErrUnavailable, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := MapError("test", tt.err)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("MapError(%v) = %v, want %v", tt.err, err, tt.kind)
+			}
+			if !errors.Is(err, tt.err) || err.Error() != tt.err.Error() {
+				t.Errorf("MapError lost the original error: %v", err)
+			}
+			var pe *ProviderError
+			if !errors.As(err, &pe) || pe.Provider != "test" || pe.RetryAfter != tt.wait {
+				t.Errorf("ProviderError = %+v, want retry after %v", pe, tt.wait)
+			}
+		})
+	}
+}
+
+func TestMapErrorLeavesOthersAlone(t *testing.T) {
+	for _, err := range []error{nil, context.Canceled, errors.New("something odd happened")} {
+		if got := MapError("test", err); got != err {
+			t.Errorf("MapError(%v) = %v, want it unchanged", err, got)
+		}
+	}
+
+	mapped := protocoltypes.HTTPError("", http.StatusBadRequest, nil, "", errors.New("bad"))
+	if got := MapError("openai", mapped); got != mapped || mapped.Provider != "openai" {
+		t.Errorf("MapError of a ProviderError = %v (provider %q)", got, mapped.Provider)
+	}
+}
+
+func TestClassifyErrorUsesErrorKinds(t *testing.T) {
+	tests := []struct {
+		err    error
+		reason FailoverReason
+	}{
+		{&ProviderError{Kind: ErrRateLimited, Err: errors.New("slow down")}, FailoverRateLimit},
+		{&ProviderError{Kind: ErrAuth, Status: 401, Err: errors.New("nope")}, FailoverAuth},
+		{&ProviderError{Kind: ErrAuth, Status: 402, Err: errors.New("pay up")}, FailoverBilling},
+		{&ProviderError{Kind: ErrUnavailable, Status: 503, Err: errors.New("down")}, FailoverTimeout},
+		{&ProviderError{Kind: ErrContextTooLong, Status: 400, Err: errors.New("long")}, FailoverFormat},
+		{&ProviderError{Kind: ErrContentFiltered, Status: 400, Err: errors.New("no")}, FailoverFormat},
+	}
+	for _, tt := range tests {
+		fe := ClassifyError(tt.err, "p", "m")
+		if fe == nil || fe.Reason != tt.reason {
+			t.Errorf("ClassifyError(%v) = %v, want %s", tt.err, fe, tt.reason)
+		}
+	}
+}
+
+func TestFallbackExhaustedErrorUnwrapsLastFailure(t *testing.T) {
+	err := &FallbackExhaustedError{Attempts: []FallbackAttempt{
+		{Provider: "a", Error: &ProviderError{Kind: ErrAuth, Err: errors.New("bad key")}},
+		{Provider: "b", Error: &ProviderError{Kind: ErrUnavailable, Err: errors.New("503")}},
+		{Provider: "c", Skipped: true, Error: &ProviderError{Kind: ErrRateLimited, Err: errors.New("cooldown")}},
+	}}
+	if !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrRateLimited) {
+		t.Errorf("FallbackExhaustedError should match the last tried candidate's error kind")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := protocoltypes.ParseRetryAfter("120"); got != 2*time.Minute {
+		t.Errorf("ParseRetryAfter(120) = %v", got)
+	}
+	date := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	if got := protocoltypes.ParseRetryAfter(date); got < 80*time.Second || got > 90*time.Second {
+		t.Errorf("ParseRetryAfter(%q) = %v", date, got)
+	}
+	for _, v := range []string{"", "soon", "-5"} {
+		if got := protocoltypes.ParseRetryAfter(v); got != 0 {
+			t.Errorf("ParseRetryAfter(%q) = %v, want 0", v, got)
+		}
+	}
+}
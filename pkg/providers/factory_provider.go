@@ -9,31 +9,55 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sipeed/picoclaw/pkg/auth"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
 )
 
 // createClaudeAuthProvider creates a Claude provider using OAuth credentials from auth store.
-func createClaudeAuthProvider() (LLMProvider, error) {
-	cred, err := getCredential("anthropic")
+// A non-empty account selects the credential saved with `auth login --account`; an empty
+// apiBase uses the official endpoint.
+func createClaudeAuthProvider(account, apiBase string, pool HTTPClientConfig) (LLMProvider, error) {
+	key := auth.CredentialKey("anthropic", account)
+	cred, err := getCredential(key)
 	if err != nil {
 		return nil, fmt.Errorf("loading auth credentials: %w", err)
 	}
 	if cred == nil {
-		return nil, fmt.Errorf("no credentials for anthropic. Run: picoclaw auth login --provider anthropic")
+		return nil, fmt.Errorf("no credentials for %s. Run: %s", key, loginHint("anthropic", account))
 	}
-	return NewClaudeProviderWithTokenSource(cred.AccessToken, createClaudeTokenSource()), nil
+	return newClaudeProvider(pool, cred.AccessToken, createClaudeTokenSource(account), apiBase), nil
 }
 
 // createCodexAuthProvider creates a Codex provider using OAuth credentials from auth store.
-func createCodexAuthProvider() (LLMProvider, error) {
-	cred, err := getCredential("openai")
+// A non-empty account selects the credential saved with `auth login --account`; an empty
+// apiBase uses the official endpoint.
+func createCodexAuthProvider(account, apiBase string, pool HTTPClientConfig) (LLMProvider, error) {
+	key := auth.CredentialKey("openai", account)
+	cred, err := getCredential(key)
 	if err != nil {
 		return nil, fmt.Errorf("loading auth credentials: %w", err)
 	}
 	if cred == nil {
-		return nil, fmt.Errorf("no credentials for openai. Run: picoclaw auth login --provider openai")
+		return nil, fmt.Errorf("no credentials for %s. Run: %s", key, loginHint("openai", account))
 	}
-	return NewCodexProviderWithTokenSource(cred.AccessToken, cred.AccountID, createCodexTokenSource()), nil
+	// The public API's URL, which model_list entries for openai usually
+	// carry, is not where OAuth tokens go; only a different URL is an override.
+	if strings.TrimRight(apiBase, "/") == getDefaultAPIBase("openai") {
+		apiBase = ""
+	}
+	p := newCodexProvider(pool, cred.AccessToken, cred.AccountID, apiBase)
+	p.tokenSource = createCodexTokenSource(account)
+	return p, nil
+}
+
+// loginHint is the command that creates the credential for provider/account.
+func loginHint(provider, account string) string {
+	hint := "picoclaw auth login --provider " + provider
+	if account != "" {
+		hint += " --account " + account
+	}
+	return hint
 }
 
 // ExtractProtocol extracts the protocol prefix and model identifier from a model string.
@@ -53,9 +77,13 @@ func ExtractProtocol(model string) (protocol, modelID string) {
 
 // CreateProviderFromConfig creates a provider based on the ModelConfig.
 // It uses the protocol prefix in the Model field to determine which provider to create.
-// Supported protocols: openai, litellm, anthropic, antigravity, claude-cli, codex-cli, github-copilot
+// Every HTTP-based protocol sends its requests to APIBase when it is set, and to the
+// protocol's official endpoint otherwise.
+// Supported protocols: openai, litellm, vllm, anthropic, antigravity, claude-cli, codex-cli, github-copilot
+// HTTP-based providers take their connections from the pool with the pool
+// settings, usually providers.http.
 // Returns the provider, the model ID (without protocol prefix), and any error.
-func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, error) {
+func CreateProviderFromConfig(cfg *config.ModelConfig, pool HTTPClientConfig) (LLMProvider, string, error) {
 	if cfg == nil {
 		return nil, "", fmt.Errorf("config is nil")
 	}
@@ -70,7 +98,7 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 	case "openai":
 		// OpenAI with OAuth/token auth (Codex-style)
 		if cfg.AuthMethod == "oauth" || cfg.AuthMethod == "token" {
-			provider, err := createCodexAuthProvider()
+			provider, err := createCodexAuthProvider(cfg.Account, cfg.APIBase, pool)
 			if err != nil {
 				return nil, "", err
 			}
@@ -84,17 +112,37 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
-		return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
+		return newHTTPProvider(
+			pool,
+			cfg.APIKey,
+			apiBase,
+			cfg.Proxy,
+			cfg.MaxTokensField,
+			cfg.RequestTimeout,
+			nil,
+		), modelID, nil
+
+	case "openrouter":
+		if cfg.APIKey == "" && cfg.APIBase == "" {
+			return nil, "", fmt.Errorf("api_key or api_base is required for HTTP-based protocol %q", protocol)
+		}
+		apiBase := cfg.APIBase
+		if apiBase == "" {
+			apiBase = getDefaultAPIBase(protocol)
+		}
+		return newHTTPProvider(
+			pool,
 			cfg.APIKey,
 			apiBase,
 			cfg.Proxy,
 			cfg.MaxTokensField,
 			cfg.RequestTimeout,
+			openRouterRouting(cfg.Route),
 		), modelID, nil
 
-	case "litellm", "openrouter", "groq", "zhipu", "gemini", "nvidia",
+	case "litellm", "groq", "zhipu", "gemini", "nvidia",
 		"ollama", "moonshot", "shengsuanyun", "deepseek", "cerebras",
-		"volcengine", "vllm", "qwen", "mistral":
+		"volcengine", "qwen", "mistral":
 		// All other OpenAI-compatible HTTP providers
 		if cfg.APIKey == "" && cfg.APIBase == "" {
 			return nil, "", fmt.Errorf("api_key or api_base is required for HTTP-based protocol %q", protocol)
@@ -103,18 +151,31 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if apiBase == "" {
 			apiBase = getDefaultAPIBase(protocol)
 		}
-		return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
+		return newHTTPProvider(
+			pool,
 			cfg.APIKey,
 			apiBase,
 			cfg.Proxy,
 			cfg.MaxTokensField,
 			cfg.RequestTimeout,
+			nil,
+		), modelID, nil
+
+	case "vllm":
+		// A local vLLM server needs neither a key nor a base URL.
+		return newVLLMProvider(
+			pool,
+			cfg.APIKey,
+			cfg.APIBase,
+			cfg.Proxy,
+			cfg.MaxTokensField,
+			cfg.RequestTimeout,
 		), modelID, nil
 
 	case "anthropic":
 		if cfg.AuthMethod == "oauth" || cfg.AuthMethod == "token" {
 			// Use OAuth credentials from auth store
-			provider, err := createClaudeAuthProvider()
+			provider, err := createClaudeAuthProvider(cfg.Account, cfg.APIBase, pool)
 			if err != nil {
 				return nil, "", err
 			}
@@ -128,30 +189,36 @@ func CreateProviderFromConfig(cfg *config.ModelConfig) (LLMProvider, string, err
 		if cfg.APIKey == "" {
 			return nil, "", fmt.Errorf("api_key is required for anthropic protocol (model: %s)", cfg.Model)
 		}
-		return NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
+		return newHTTPProvider(
+			pool,
 			cfg.APIKey,
 			apiBase,
 			cfg.Proxy,
 			cfg.MaxTokensField,
 			cfg.RequestTimeout,
+			nil,
 		), modelID, nil
 
 	case "antigravity":
-		return NewAntigravityProvider(), modelID, nil
+		return newAntigravityProvider(pool, cfg.APIBase), modelID, nil
 
 	case "claude-cli", "claudecli":
 		workspace := cfg.Workspace
 		if workspace == "" {
 			workspace = "."
 		}
-		return NewClaudeCliProvider(workspace), modelID, nil
+		provider := NewClaudeCliProvider(workspace)
+		provider.SetTimeout(cliTimeout(cfg.SubprocessTimeout))
+		return provider, modelID, nil
 
 	case "codex-cli", "codexcli":
 		workspace := cfg.Workspace
 		if workspace == "" {
 			workspace = "."
 		}
-		return NewCodexCliProvider(workspace), modelID, nil
+		provider := NewCodexCliProvider(workspace)
+		provider.SetTimeout(cliTimeout(cfg.SubprocessTimeout))
+		return provider, modelID, nil
 
 	case "github-copilot", "copilot":
 		apiBase := cfg.APIBase
@@ -205,10 +272,25 @@ func getDefaultAPIBase(protocol string) string {
 	case "qwen":
 		return "https://dashscope.aliyuncs.com/compatible-mode/v1"
 	case "vllm":
-		return "http://localhost:8000/v1"
+		return defaultVLLMAPIBase
 	case "mistral":
 		return "https://api.mistral.ai/v1"
 	default:
 		return ""
 	}
 }
+
+// openRouterRouting converts a configured route into OpenRouter's request
+// "provider" object, or nil when no route is set.
+func openRouterRouting(route *config.OpenRouterRoute) *openai_compat.ProviderRouting {
+	if route == nil {
+		return nil
+	}
+	return &openai_compat.ProviderRouting{
+		Order:          route.Order,
+		Only:           route.Allow,
+		Ignore:         route.Deny,
+		Sort:           route.Sort,
+		AllowFallbacks: route.AllowFallbacks,
+	}
+}
@@ -6,6 +6,7 @@
 package providers
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -87,7 +88,7 @@ func TestCreateProviderFromConfig_OpenAI(t *testing.T) {
 		APIBase:   "https://api.example.com/v1",
 	}
 
-	provider, modelID, err := CreateProviderFromConfig(cfg)
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err != nil {
 		t.Fatalf("CreateProviderFromConfig() error = %v", err)
 	}
@@ -109,7 +110,6 @@ func TestCreateProviderFromConfig_DefaultAPIBase(t *testing.T) {
 		{"openrouter", "openrouter"},
 		{"cerebras", "cerebras"},
 		{"qwen", "qwen"},
-		{"vllm", "vllm"},
 		{"deepseek", "deepseek"},
 		{"ollama", "ollama"},
 	}
@@ -122,7 +122,7 @@ func TestCreateProviderFromConfig_DefaultAPIBase(t *testing.T) {
 				APIKey:    "test-key",
 			}
 
-			provider, _, err := CreateProviderFromConfig(cfg)
+			provider, _, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 			if err != nil {
 				t.Fatalf("CreateProviderFromConfig() error = %v", err)
 			}
@@ -149,7 +149,7 @@ func TestCreateProviderFromConfig_LiteLLM(t *testing.T) {
 		APIBase:   "http://localhost:4000/v1",
 	}
 
-	provider, modelID, err := CreateProviderFromConfig(cfg)
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err != nil {
 		t.Fatalf("CreateProviderFromConfig() error = %v", err)
 	}
@@ -168,7 +168,7 @@ func TestCreateProviderFromConfig_Anthropic(t *testing.T) {
 		APIKey:    "test-key",
 	}
 
-	provider, modelID, err := CreateProviderFromConfig(cfg)
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err != nil {
 		t.Fatalf("CreateProviderFromConfig() error = %v", err)
 	}
@@ -186,7 +186,7 @@ func TestCreateProviderFromConfig_Antigravity(t *testing.T) {
 		Model:     "antigravity/gemini-2.0-flash",
 	}
 
-	provider, modelID, err := CreateProviderFromConfig(cfg)
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err != nil {
 		t.Fatalf("CreateProviderFromConfig() error = %v", err)
 	}
@@ -198,13 +198,27 @@ func TestCreateProviderFromConfig_Antigravity(t *testing.T) {
 	}
 }
 
+func TestCreateProviderFromConfig_AntigravityAPIBase(t *testing.T) {
+	provider, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName: "test-antigravity",
+		Model:     "antigravity/gemini-2.0-flash",
+		APIBase:   "https://llm-proxy.example.com/cloudcode/",
+	}, defaultHTTPClientConfig)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if got := provider.(*AntigravityProvider).baseURL; got != "https://llm-proxy.example.com/cloudcode" {
+		t.Errorf("baseURL = %q, want the api_base override", got)
+	}
+}
+
 func TestCreateProviderFromConfig_ClaudeCLI(t *testing.T) {
 	cfg := &config.ModelConfig{
 		ModelName: "test-claude-cli",
 		Model:     "claude-cli/claude-sonnet-4.6",
 	}
 
-	provider, modelID, err := CreateProviderFromConfig(cfg)
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err != nil {
 		t.Fatalf("CreateProviderFromConfig() error = %v", err)
 	}
@@ -222,7 +236,7 @@ func TestCreateProviderFromConfig_CodexCLI(t *testing.T) {
 		Model:     "codex-cli/codex",
 	}
 
-	provider, modelID, err := CreateProviderFromConfig(cfg)
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err != nil {
 		t.Fatalf("CreateProviderFromConfig() error = %v", err)
 	}
@@ -240,7 +254,7 @@ func TestCreateProviderFromConfig_MissingAPIKey(t *testing.T) {
 		Model:     "openai/gpt-4o",
 	}
 
-	_, _, err := CreateProviderFromConfig(cfg)
+	_, _, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err == nil {
 		t.Fatal("CreateProviderFromConfig() expected error for missing API key")
 	}
@@ -253,14 +267,14 @@ func TestCreateProviderFromConfig_UnknownProtocol(t *testing.T) {
 		APIKey:    "test-key",
 	}
 
-	_, _, err := CreateProviderFromConfig(cfg)
+	_, _, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err == nil {
 		t.Fatal("CreateProviderFromConfig() expected error for unknown protocol")
 	}
 }
 
 func TestCreateProviderFromConfig_NilConfig(t *testing.T) {
-	_, _, err := CreateProviderFromConfig(nil)
+	_, _, err := CreateProviderFromConfig(nil, defaultHTTPClientConfig)
 	if err == nil {
 		t.Fatal("CreateProviderFromConfig(nil) expected error")
 	}
@@ -272,7 +286,7 @@ func TestCreateProviderFromConfig_EmptyModel(t *testing.T) {
 		Model:     "",
 	}
 
-	_, _, err := CreateProviderFromConfig(cfg)
+	_, _, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err == nil {
 		t.Fatal("CreateProviderFromConfig() expected error for empty model")
 	}
@@ -293,7 +307,7 @@ func TestCreateProviderFromConfig_RequestTimeoutPropagation(t *testing.T) {
 		RequestTimeout: 1,
 	}
 
-	provider, modelID, err := CreateProviderFromConfig(cfg)
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
 	if err != nil {
 		t.Fatalf("CreateProviderFromConfig() error = %v", err)
 	}
@@ -316,3 +330,38 @@ func TestCreateProviderFromConfig_RequestTimeoutPropagation(t *testing.T) {
 		t.Fatalf("Chat() error = %q, want timeout-related error", errMsg)
 	}
 }
+
+func TestCreateProviderFromConfig_OpenRouterRoute(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.ModelConfig{
+		ModelName: "claude",
+		Model:     "openrouter/anthropic/claude-sonnet-4.6",
+		APIBase:   server.URL,
+		Route: &config.OpenRouterRoute{
+			Allow: []string{"anthropic"},
+			Deny:  []string{"deepinfra"},
+			Sort:  "throughput",
+		},
+	}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, err := provider.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, modelID, nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	want := `"provider":{"only":["anthropic"],"ignore":["deepinfra"],"sort":"throughput"}`
+	if !strings.Contains(body, want) {
+		t.Fatalf("request body = %s, want %s", body, want)
+	}
+}
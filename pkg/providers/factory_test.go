@@ -310,7 +310,50 @@ func TestCreateProviderReturnsClaudeProviderForAnthropicOAuth(t *testing.T) {
 	if _, ok := provider.(*ClaudeProvider); !ok {
 		t.Fatalf("provider type = %T, want *ClaudeProvider", provider)
 	}
-	// TODO: Test custom APIBase when createClaudeAuthProvider supports it
+
+	cfg.ModelList[0].APIBase = "https://llm-proxy.example.com/anthropic/v1"
+	provider, _, err = CreateProvider(cfg)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+	if got := provider.(*ClaudeProvider).delegate.BaseURL(); got != "https://llm-proxy.example.com/anthropic" {
+		t.Errorf("BaseURL() = %q, want the api_base override", got)
+	}
+}
+
+func TestCreateProviderUsesNamedAccountCredential(t *testing.T) {
+	originalGetCredential := getCredential
+	t.Cleanup(func() { getCredential = originalGetCredential })
+
+	getCredential = func(provider string) (*auth.AuthCredential, error) {
+		if provider != "anthropic:work" {
+			return nil, nil
+		}
+		return &auth.AuthCredential{AccessToken: "work-token"}, nil
+	}
+
+	provider, _, err := CreateProviderFromConfig(&config.ModelConfig{
+		ModelName:  "claude-work",
+		Model:      "anthropic/claude-sonnet-4.6",
+		AuthMethod: "token",
+		Account:    "work",
+	}, defaultHTTPClientConfig)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	if _, ok := provider.(*ClaudeProvider); !ok {
+		t.Fatalf("provider type = %T, want *ClaudeProvider", provider)
+	}
+
+	_, _, err = CreateProviderFromConfig(&config.ModelConfig{
+		ModelName:  "claude-home",
+		Model:      "anthropic/claude-sonnet-4.6",
+		AuthMethod: "token",
+		Account:    "home",
+	}, defaultHTTPClientConfig)
+	if err == nil || !strings.Contains(err.Error(), "--account home") {
+		t.Fatalf("missing account error = %v, want login hint with --account home", err)
+	}
 }
 
 func TestCreateProviderReturnsCodexProviderForOpenAIOAuth(t *testing.T) {
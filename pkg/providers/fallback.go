@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ type FallbackChain struct {
 type FallbackCandidate struct {
 	Provider string
 	Model    string
+	Name     string // the model as configured, e.g. a model_list name
 }
 
 // FallbackResult contains the successful response and metadata about all attempts.
@@ -23,6 +25,7 @@ type FallbackResult struct {
 	Response *LLMResponse
 	Provider string
 	Model    string
+	Name     string // the Name of the candidate that succeeded
 	Attempts []FallbackAttempt
 }
 
@@ -74,6 +77,7 @@ func ResolveCandidatesWithLookup(
 		candidates = append(candidates, FallbackCandidate{
 			Provider: ref.Provider,
 			Model:    ref.Model,
+			Name:     strings.TrimSpace(raw),
 		})
 	}
 
@@ -125,11 +129,16 @@ func (fc *FallbackChain) Execute(
 				Model:    candidate.Model,
 				Skipped:  true,
 				Reason:   FailoverRateLimit,
-				Error: fmt.Errorf(
-					"provider %s in cooldown (%s remaining)",
-					candidate.Provider,
-					remaining.Round(time.Second),
-				),
+				Error: &ProviderError{
+					Kind:       ErrRateLimited,
+					Provider:   candidate.Provider,
+					RetryAfter: remaining.Round(time.Second),
+					Err: fmt.Errorf(
+						"provider %s in cooldown (%s remaining)",
+						candidate.Provider,
+						remaining.Round(time.Second),
+					),
+				},
 			})
 			continue
 		}
@@ -142,13 +151,16 @@ func (fc *FallbackChain) Execute(
 		if err == nil {
 			// Success.
 			fc.cooldown.MarkSuccess(candidate.Provider)
+			Health().RecordSuccess(candidate.Provider)
 			result.Response = resp
 			result.Provider = candidate.Provider
 			result.Model = candidate.Model
+			result.Name = candidate.Name
 			return result, nil
 		}
 
-		// Context cancellation: abort immediately, no fallback.
+		// Context cancellation: abort immediately, no fallback. Content the
+		// candidate produced before that is passed on.
 		if ctx.Err() == context.Canceled {
 			result.Attempts = append(result.Attempts, FallbackAttempt{
 				Provider: candidate.Provider,
@@ -156,9 +168,15 @@ func (fc *FallbackChain) Execute(
 				Error:    err,
 				Duration: elapsed,
 			})
+			var partial *PartialResponseError
+			if errors.As(err, &partial) {
+				return nil, partial
+			}
 			return nil, context.Canceled
 		}
 
+		Health().RecordError(candidate.Provider, err)
+
 		// Classify the error.
 		failErr := ClassifyError(err, candidate.Provider, candidate.Model)
 
@@ -187,7 +205,12 @@ func (fc *FallbackChain) Execute(
 		}
 
 		// Retriable error: mark failure and continue to next candidate.
-		fc.cooldown.MarkFailure(candidate.Provider, failErr.Reason)
+		var quotaErr *QuotaExhaustedError
+		if failErr.Reason == FailoverQuota && errors.As(err, &quotaErr) {
+			fc.cooldown.MarkQuotaExhausted(candidate.Provider, quotaErr.ResetIn())
+		} else {
+			fc.cooldown.MarkFailure(candidate.Provider, failErr.Reason)
+		}
 		result.Attempts = append(result.Attempts, FallbackAttempt{
 			Provider: candidate.Provider,
 			Model:    candidate.Model,
@@ -235,6 +258,7 @@ func (fc *FallbackChain) ExecuteImage(
 			result.Response = resp
 			result.Provider = candidate.Provider
 			result.Model = candidate.Model
+			result.Name = candidate.Name
 			return result, nil
 		}
 
@@ -287,6 +311,24 @@ type FallbackExhaustedError struct {
 	Attempts []FallbackAttempt
 }
 
+// Unwrap returns the error of the last candidate that was actually tried,
+// or of the last one skipped if all were in cooldown, so errors.Is sees
+// the error kind behind the failure.
+func (e *FallbackExhaustedError) Unwrap() error {
+	var tried, skipped error
+	for _, a := range e.Attempts {
+		if a.Skipped {
+			skipped = a.Error
+		} else {
+			tried = a.Error
+		}
+	}
+	if tried != nil {
+		return tried
+	}
+	return skipped
+}
+
 func (e *FallbackExhaustedError) Error() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("fallback: all %d candidates failed:", len(e.Attempts)))
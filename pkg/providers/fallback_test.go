@@ -121,6 +121,27 @@ func TestFallback_ContextCanceled(t *testing.T) {
 	}
 }
 
+func TestFallback_ContextCanceledKeepsPartial(t *testing.T) {
+	fc := NewFallbackChain(NewCooldownTracker())
+	ctx, cancel := context.WithCancel(context.Background())
+	candidates := []FallbackCandidate{
+		makeCandidate("openai", "gpt-4"),
+		makeCandidate("anthropic", "claude"),
+	}
+
+	_, err := fc.Execute(ctx, candidates, func(ctx context.Context, provider, model string) (*LLMResponse, error) {
+		cancel()
+		return nil, partialResponse(ctx, "half an answer")
+	})
+	var partial *PartialResponseError
+	if !errors.As(err, &partial) || partial.Content != "half an answer" {
+		t.Fatalf("expected the partial response, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("partial response error should match context.Canceled")
+	}
+}
+
 func TestFallback_NonRetriableError(t *testing.T) {
 	ct := NewCooldownTracker()
 	fc := NewFallbackChain(ct)
@@ -105,7 +105,7 @@ func (p *GitHubCopilotProvider) Chat(
 		Prompt: string(fullcontent),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to send message to copilot: %w", err)
+		return nil, MapError("github-copilot", fmt.Errorf("failed to send message to copilot: %w", err))
 	}
 
 	if resp == nil {
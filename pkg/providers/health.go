@@ -0,0 +1,192 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// healthSaveInterval limits how often successes are persisted, so a busy
+// gateway does not rewrite the health file on every LLM call.
+const healthSaveInterval = time.Minute
+
+// ProviderHealth is the most recent outcome of calls to one provider.
+type ProviderHealth struct {
+	Provider      string    `json:"provider"`
+	LastError     string    `json:"last_error,omitempty"`
+	LastErrorAt   time.Time `json:"last_error_at,omitzero"`
+	LastSuccessAt time.Time `json:"last_success_at,omitzero"`
+}
+
+// Healthy reports whether the last recorded call succeeded.
+func (h ProviderHealth) Healthy() bool {
+	return h.LastErrorAt.IsZero() || h.LastSuccessAt.After(h.LastErrorAt)
+}
+
+// HealthRegistry tracks the last error and last success per provider.
+// It is safe for concurrent use.
+type HealthRegistry struct {
+	mu       sync.Mutex
+	entries  map[string]*ProviderHealth
+	path     string
+	lastSave time.Time
+	nowFunc  func() time.Time
+}
+
+var defaultHealth = NewHealthRegistry()
+
+// Health returns the process-wide provider health registry.
+func Health() *HealthRegistry {
+	return defaultHealth
+}
+
+// NewHealthRegistry creates an empty, in-memory registry.
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{
+		entries: make(map[string]*ProviderHealth),
+		nowFunc: time.Now,
+	}
+}
+
+// SetPersistPath enables persisting the registry to path. Existing entries
+// in the file are loaded so history survives restarts.
+func (h *HealthRegistry) SetPersistPath(path string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.path = path
+	entries, err := LoadProviderHealth(path)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if _, ok := h.entries[e.Provider]; !ok {
+			h.entries[e.Provider] = &e
+		}
+	}
+}
+
+// RecordSuccess marks a successful call to provider.
+func (h *HealthRegistry) RecordSuccess(provider string) {
+	if provider == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.nowFunc()
+	_, known := h.entries[provider]
+	e := h.entry(provider)
+	recovered := !e.Healthy()
+	e.LastSuccessAt = now
+	if !known || recovered || now.Sub(h.lastSave) >= healthSaveInterval {
+		h.saveLocked()
+	}
+}
+
+// RecordError marks a failed call to provider.
+func (h *HealthRegistry) RecordError(provider string, err error) {
+	if provider == "" || err == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e := h.entry(provider)
+	e.LastError = describeProviderError(err)
+	e.LastErrorAt = h.nowFunc()
+	h.saveLocked()
+}
+
+// Snapshot returns a copy of all entries sorted by provider name.
+func (h *HealthRegistry) Snapshot() []ProviderHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]ProviderHealth, 0, len(h.entries))
+	for _, e := range h.entries {
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+func (h *HealthRegistry) entry(provider string) *ProviderHealth {
+	e, ok := h.entries[provider]
+	if !ok {
+		e = &ProviderHealth{Provider: provider}
+		h.entries[provider] = e
+	}
+	return e
+}
+
+func (h *HealthRegistry) saveLocked() {
+	if h.path == "" {
+		return
+	}
+	out := make([]ProviderHealth, 0, len(h.entries))
+	for _, e := range h.entries {
+		out = append(out, *e)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := fileutil.WriteFileAtomic(h.path, data, 0o600); err == nil {
+		h.lastSave = h.nowFunc()
+	}
+}
+
+// HealthFilePath returns where the gateway persists provider health for a workspace.
+func HealthFilePath(workspace string) string {
+	return filepath.Join(workspace, "state", "provider_health.json")
+}
+
+// LoadProviderHealth reads a health file written by a HealthRegistry.
+func LoadProviderHealth(path string) ([]ProviderHealth, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ProviderHealth
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Provider < entries[j].Provider })
+	return entries, nil
+}
+
+// describeProviderError turns a provider error into a short, human readable
+// reason such as "rate limited".
+func describeProviderError(err error) string {
+	var failErr *FailoverError
+	if !errors.As(err, &failErr) {
+		failErr = ClassifyError(err, "", "")
+	}
+	if failErr != nil {
+		switch failErr.Reason {
+		case FailoverRateLimit:
+			return "rate limited"
+		case FailoverQuota:
+			return "quota exhausted"
+		case FailoverAuth:
+			return "authentication failed"
+		case FailoverBilling:
+			return "billing or quota error"
+		case FailoverTimeout:
+			return "timed out"
+		case FailoverOverloaded:
+			return "overloaded"
+		case FailoverFormat:
+			return "rejected request format"
+		}
+	}
+	return utils.Truncate(err.Error(), 120)
+}
@@ -0,0 +1,60 @@
+package providers
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealth_RecordErrorThenSuccess(t *testing.T) {
+	current := time.Now()
+	h := NewHealthRegistry()
+	h.nowFunc = func() time.Time { return current }
+
+	h.RecordError("openai", &FailoverError{Reason: FailoverRateLimit, Wrapped: errors.New("429")})
+
+	snap := h.Snapshot()
+	if len(snap) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(snap))
+	}
+	if snap[0].Healthy() {
+		t.Error("provider should be unhealthy after an error")
+	}
+	if snap[0].LastError != "rate limited" {
+		t.Errorf("LastError = %q, want %q", snap[0].LastError, "rate limited")
+	}
+
+	current = current.Add(time.Second)
+	h.RecordSuccess("openai")
+	if snap := h.Snapshot(); !snap[0].Healthy() {
+		t.Error("provider should be healthy after a later success")
+	}
+}
+
+func TestHealth_PersistAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "provider_health.json")
+
+	h := NewHealthRegistry()
+	h.SetPersistPath(path)
+	h.RecordError("anthropic", errors.New("connection reset by peer"))
+	h.RecordSuccess("openai")
+
+	entries, err := LoadProviderHealth(path)
+	if err != nil {
+		t.Fatalf("LoadProviderHealth: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Provider != "anthropic" || entries[1].Provider != "openai" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if entries[0].LastError == "" {
+		t.Error("expected persisted error message")
+	}
+
+	// A fresh registry picks up history from the file.
+	h2 := NewHealthRegistry()
+	h2.SetPersistPath(path)
+	if len(h2.Snapshot()) != 2 {
+		t.Error("expected entries to be loaded from disk")
+	}
+}
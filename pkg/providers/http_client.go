@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// HTTPClientConfig sets the connection limits of the shared client pool;
+// it is providers.http in the config.
+type HTTPClientConfig = config.HTTPClientConfig
+
+// transportKey identifies a shared transport: providers with the same pool
+// settings and proxy reuse each other's connections.
+type transportKey struct {
+	cfg   HTTPClientConfig
+	proxy string
+}
+
+var httpPool = struct {
+	mu         sync.Mutex
+	transports map[transportKey]*http.Transport
+}{
+	transports: make(map[transportKey]*http.Transport),
+}
+
+// defaultHTTPClientConfig is the pool of providers created by their
+// constructors rather than from the config.
+var defaultHTTPClientConfig = config.DefaultConfig().Providers.HTTP
+
+// NewHTTPClient returns a client whose transport is shared by every client
+// created with the same cfg. The client itself is new, so callers may set
+// its Timeout.
+func NewHTTPClient(cfg HTTPClientConfig) *http.Client {
+	return newProxiedHTTPClient(cfg, "")
+}
+
+// newProxiedHTTPClient is NewHTTPClient sending requests through proxy when
+// it is set.
+func newProxiedHTTPClient(cfg HTTPClientConfig, proxy string) *http.Client {
+	return &http.Client{Transport: sharedTransport(cfg, proxy)}
+}
+
+func sharedTransport(cfg HTTPClientConfig, proxy string) *http.Transport {
+	key := transportKey{cfg: cfg, proxy: proxy}
+	httpPool.mu.Lock()
+	defer httpPool.mu.Unlock()
+	if t, ok := httpPool.transports[key]; ok {
+		return t
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	t.TLSHandshakeTimeout = time.Duration(cfg.TLSHandshakeTimeout) * time.Second
+	t.ResponseHeaderTimeout = time.Duration(cfg.ResponseHeaderTimeout) * time.Second
+	t.IdleConnTimeout = time.Duration(cfg.IdleConnTimeout) * time.Second
+	if proxy != "" {
+		if parsed, err := url.Parse(proxy); err == nil {
+			t.Proxy = http.ProxyURL(parsed)
+		} else {
+			logger.WarnCF("provider", "Invalid proxy URL, connecting directly",
+				map[string]any{"proxy": proxy, "error": err.Error()})
+		}
+	}
+	httpPool.transports[key] = t
+	return t
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	cfg := HTTPClientConfig{
+		MaxIdleConnsPerHost:   16,
+		TLSHandshakeTimeout:   5,
+		ResponseHeaderTimeout: 30,
+		IdleConnTimeout:       60,
+	}
+	a, b := NewHTTPClient(cfg), NewHTTPClient(cfg)
+	if a == b || a.Transport != b.Transport {
+		t.Fatal("clients with the same settings should be distinct but share one transport")
+	}
+
+	tr := a.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 16 || tr.TLSHandshakeTimeout != 5*time.Second ||
+		tr.ResponseHeaderTimeout != 30*time.Second || tr.IdleConnTimeout != 60*time.Second {
+		t.Errorf("transport = %+v, want the configured limits", tr)
+	}
+
+	cfg.MaxIdleConnsPerHost = 4
+	if NewHTTPClient(cfg).Transport == a.Transport {
+		t.Error("different settings should get their own transport")
+	}
+}
+
+func TestNewProxiedHTTPClient(t *testing.T) {
+	cfg := HTTPClientConfig{MaxIdleConnsPerHost: 3}
+	direct := newProxiedHTTPClient(cfg, "")
+	if got := direct.Transport.(*http.Transport).MaxIdleConnsPerHost; got != 3 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want the configured 3", got)
+	}
+	if direct.Transport != NewHTTPClient(cfg).Transport {
+		t.Error("providers without a proxy should share a transport")
+	}
+
+	proxied := newProxiedHTTPClient(cfg, "http://proxy.example:3128")
+	if proxied.Transport == direct.Transport {
+		t.Fatal("a proxied client should not share the direct transport")
+	}
+	req, _ := http.NewRequest("GET", "https://api.example.com/v1", nil)
+	proxyURL, err := proxied.Transport.(*http.Transport).Proxy(req)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.example:3128" {
+		t.Errorf("proxy = %v, %v; want proxy.example:3128", proxyURL, err)
+	}
+}
+
+func TestCreateProviderFromConfig_UsesPoolSettings(t *testing.T) {
+	provider, _, err := CreateProviderFromConfig(
+		&config.ModelConfig{ModelName: "local", Model: "vllm/auto"},
+		HTTPClientConfig{MaxIdleConnsPerHost: 7},
+	)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	tr := provider.(*VLLMProvider).httpClient.Transport.(*http.Transport)
+	if tr.MaxIdleConnsPerHost != 7 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want the configured 7", tr.MaxIdleConnsPerHost)
+	}
+}
@@ -19,7 +19,8 @@ type HTTPProvider struct {
 
 func NewHTTPProvider(apiKey, apiBase, proxy string) *HTTPProvider {
 	return &HTTPProvider{
-		delegate: openai_compat.NewProvider(apiKey, apiBase, proxy),
+		delegate: openai_compat.NewProvider(apiKey, apiBase, proxy,
+			openai_compat.WithHTTPClient(newProxiedHTTPClient(defaultHTTPClientConfig, proxy))),
 	}
 }
 
@@ -30,14 +31,37 @@ func NewHTTPProviderWithMaxTokensField(apiKey, apiBase, proxy, maxTokensField st
 func NewHTTPProviderWithMaxTokensFieldAndRequestTimeout(
 	apiKey, apiBase, proxy, maxTokensField string,
 	requestTimeoutSeconds int,
+) *HTTPProvider {
+	return newHTTPProvider(defaultHTTPClientConfig, apiKey, apiBase, proxy, maxTokensField, requestTimeoutSeconds, nil)
+}
+
+// NewOpenRouterProvider is an HTTP provider that sends OpenRouter provider
+// routing preferences with each request.
+func NewOpenRouterProvider(
+	apiKey, apiBase, proxy, maxTokensField string,
+	requestTimeoutSeconds int,
+	routing *openai_compat.ProviderRouting,
+) *HTTPProvider {
+	return newHTTPProvider(defaultHTTPClientConfig, apiKey, apiBase, proxy, maxTokensField, requestTimeoutSeconds, routing)
+}
+
+// newHTTPProvider creates an HTTP provider whose connections come from the
+// pool with the pool settings.
+func newHTTPProvider(
+	pool HTTPClientConfig,
+	apiKey, apiBase, proxy, maxTokensField string,
+	requestTimeoutSeconds int,
+	routing *openai_compat.ProviderRouting,
 ) *HTTPProvider {
 	return &HTTPProvider{
 		delegate: openai_compat.NewProvider(
 			apiKey,
 			apiBase,
 			proxy,
+			openai_compat.WithHTTPClient(newProxiedHTTPClient(pool, proxy)),
 			openai_compat.WithMaxTokensField(maxTokensField),
 			openai_compat.WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+			openai_compat.WithProviderRouting(routing),
 		),
 	}
 }
@@ -49,7 +73,29 @@ func (p *HTTPProvider) Chat(
 	model string,
 	options map[string]any,
 ) (*LLMResponse, error) {
-	return p.delegate.Chat(ctx, messages, tools, model, options)
+	resp, err := p.delegate.Chat(ctx, messages, tools, model, options)
+	if err != nil {
+		return nil, MapError("", err)
+	}
+	return resp, nil
+}
+
+func (p *HTTPProvider) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(string),
+) (*LLMResponse, error) {
+	resp, err := p.delegate.ChatStream(ctx, messages, tools, model, options, onDelta)
+	if err != nil {
+		if ctx.Err() != nil && resp != nil {
+			return nil, partialResponse(ctx, resp.Content)
+		}
+		return nil, MapError("", err)
+	}
+	return resp, nil
 }
 
 func (p *HTTPProvider) GetDefaultModel() string {
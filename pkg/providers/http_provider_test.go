@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPProviderChatStream_CancelKeepsPartial(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hello, wor\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := NewHTTPProvider("key", server.URL, "")
+	_, err := p.ChatStream(ctx, []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil,
+		func(string) { cancel() })
+
+	var partial *PartialResponseError
+	if !errors.As(err, &partial) {
+		t.Fatalf("ChatStream() error = %v, want a PartialResponseError", err)
+	}
+	if partial.Content != "Hello, wor" {
+		t.Errorf("partial content = %q, want %q", partial.Content, "Hello, wor")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v should match context.Canceled", err)
+	}
+}
@@ -6,7 +6,9 @@
 package providers
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/config"
 )
@@ -52,10 +54,32 @@ func CreateProvider(cfg *config.Config) (LLMProvider, string, error) {
 	}
 
 	// Use factory to create provider
-	provider, modelID, err := CreateProviderFromConfig(modelCfg)
+	provider, modelID, err := CreateProviderFromConfig(modelCfg, cfg.Providers.HTTP)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create provider for model %q: %w", model, err)
 	}
 
+	if window := cfg.Agents.Defaults.CoalesceWindowSeconds; window > 0 {
+		provider = NewCoalescingProvider(provider, time.Duration(window)*time.Second)
+	}
+
 	return provider, modelID, nil
 }
+
+// UnconfiguredProvider stands in for the provider when none could be
+// created. Every call fails with the creation error.
+type UnconfiguredProvider struct {
+	Err error
+}
+
+func (p *UnconfiguredProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	return nil, fmt.Errorf("no provider configured: %w", p.Err)
+}
+
+func (p *UnconfiguredProvider) GetDefaultModel() string { return "" }
@@ -32,9 +32,20 @@ type Provider struct {
 	apiKey         string
 	apiBase        string
 	maxTokensField string // Field name for max tokens (e.g., "max_completion_tokens" for o1/glm models)
+	routing        *ProviderRouting
 	httpClient     *http.Client
 }
 
+// ProviderRouting is OpenRouter's "provider" request object, which picks the
+// upstream providers a request may be sent to.
+type ProviderRouting struct {
+	Order          []string `json:"order,omitempty"`
+	Only           []string `json:"only,omitempty"`
+	Ignore         []string `json:"ignore,omitempty"`
+	Sort           string   `json:"sort,omitempty"`
+	AllowFallbacks *bool    `json:"allow_fallbacks,omitempty"`
+}
+
 type Option func(*Provider)
 
 const defaultRequestTimeout = 120 * time.Second
@@ -45,6 +56,31 @@ func WithMaxTokensField(maxTokensField string) Option {
 	}
 }
 
+// WithProviderRouting sends routing preferences with every request. Only
+// OpenRouter understands them.
+func WithProviderRouting(routing *ProviderRouting) Option {
+	return func(p *Provider) {
+		p.routing = routing
+	}
+}
+
+// WithHTTPClient sends requests through client, e.g. one from a pool
+// shared with other providers, instead of a client of the provider's own.
+// The client's transport is expected to handle any proxy. A client without
+// a timeout gets the default one; WithRequestTimeout after this option
+// overrides it.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		if client == nil {
+			return
+		}
+		if client.Timeout == 0 {
+			client.Timeout = p.httpClient.Timeout
+		}
+		p.httpClient = client
+	}
+}
+
 func WithRequestTimeout(timeout time.Duration) Option {
 	return func(p *Provider) {
 		if timeout > 0 {
@@ -108,6 +144,29 @@ func (p *Provider) Chat(
 	model string,
 	options map[string]any,
 ) (*LLMResponse, error) {
+	resp, err := p.post(ctx, messages, tools, model, options, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return parseResponse(body)
+}
+
+// post sends a chat completion request, streamed or not, and returns the
+// response once it is known to be successful. The caller closes the body.
+func (p *Provider) post(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	stream bool,
+) (*http.Response, error) {
 	if p.apiBase == "" {
 		return nil, fmt.Errorf("API base not configured")
 	}
@@ -162,6 +221,15 @@ func (p *Provider) Chat(
 		}
 	}
 
+	if p.routing != nil {
+		requestBody["provider"] = p.routing
+	}
+
+	if stream {
+		requestBody["stream"] = true
+		requestBody["stream_options"] = map[string]any{"include_usage": true}
+	}
+
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -173,6 +241,9 @@ func (p *Provider) Chat(
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
 	if p.apiKey != "" {
 		req.Header.Set("Authorization", "Bearer "+p.apiKey)
 	}
@@ -181,18 +252,79 @@ func (p *Provider) Chat(
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if upstream := upstreamError(body); upstream != "" {
+			err = fmt.Errorf("API request failed:\n  Status: %d\n  Upstream: %s\n  Body:   %s",
+				resp.StatusCode, upstream, string(body))
+		} else {
+			err = fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+		}
+		return nil, protocoltypes.HTTPError("", resp.StatusCode, resp.Header, string(body), err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed:\n  Status: %d\n  Body:   %s", resp.StatusCode, string(body))
+	return resp, nil
+}
+
+// upstreamError digs the real cause out of an OpenRouter error payload,
+// which wraps the upstream provider's response in error.metadata.raw, e.g.
+// "Anthropic: Overloaded (overloaded_error)". Returns "" for other payloads.
+func upstreamError(body []byte) string {
+	var payload struct {
+		Error struct {
+			Message  string `json:"message"`
+			Metadata struct {
+				ProviderName string          `json:"provider_name"`
+				Raw          json.RawMessage `json:"raw"`
+			} `json:"metadata"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	meta := payload.Error.Metadata
+	if meta.ProviderName == "" {
+		return ""
 	}
 
-	return parseResponse(body)
+	// raw is usually the upstream body as a JSON string, but may be an
+	// object or plain text.
+	raw := string(meta.Raw)
+	var rawText string
+	if json.Unmarshal(meta.Raw, &rawText) == nil {
+		raw = rawText
+	}
+
+	detail := raw
+	var upstream struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Error   *struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if json.Unmarshal([]byte(raw), &upstream) == nil {
+		msg, typ := upstream.Message, upstream.Type
+		if upstream.Error != nil {
+			msg, typ = upstream.Error.Message, upstream.Error.Type
+		}
+		switch {
+		case msg != "" && typ != "":
+			detail = fmt.Sprintf("%s (%s)", msg, typ)
+		case msg != "":
+			detail = msg
+		}
+	}
+	if strings.TrimSpace(detail) == "" {
+		detail = payload.Error.Message
+	}
+	return fmt.Sprintf("%s: %s", meta.ProviderName, strings.TrimSpace(detail))
 }
 
 func parseResponse(body []byte) (*LLMResponse, error) {
@@ -203,23 +335,11 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 				ReasoningContent string            `json:"reasoning_content"`
 				Reasoning        string            `json:"reasoning"`
 				ReasoningDetails []ReasoningDetail `json:"reasoning_details"`
-				ToolCalls        []struct {
-					ID       string `json:"id"`
-					Type     string `json:"type"`
-					Function *struct {
-						Name      string `json:"name"`
-						Arguments string `json:"arguments"`
-					} `json:"function"`
-					ExtraContent *struct {
-						Google *struct {
-							ThoughtSignature string `json:"thought_signature"`
-						} `json:"google"`
-					} `json:"extra_content"`
-				} `json:"tool_calls"`
+				ToolCalls        []apiToolCall     `json:"tool_calls"`
 			} `json:"message"`
 			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
-		Usage *UsageInfo `json:"usage"`
+		Usage *apiUsage `json:"usage"`
 	}
 
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
@@ -236,42 +356,7 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 	choice := apiResponse.Choices[0]
 	toolCalls := make([]ToolCall, 0, len(choice.Message.ToolCalls))
 	for _, tc := range choice.Message.ToolCalls {
-		arguments := make(map[string]any)
-		name := ""
-
-		// Extract thought_signature from Gemini/Google-specific extra content
-		thoughtSignature := ""
-		if tc.ExtraContent != nil && tc.ExtraContent.Google != nil {
-			thoughtSignature = tc.ExtraContent.Google.ThoughtSignature
-		}
-
-		if tc.Function != nil {
-			name = tc.Function.Name
-			if tc.Function.Arguments != "" {
-				if err := json.Unmarshal([]byte(tc.Function.Arguments), &arguments); err != nil {
-					log.Printf("openai_compat: failed to decode tool call arguments for %q: %v", name, err)
-					arguments["raw"] = tc.Function.Arguments
-				}
-			}
-		}
-
-		// Build ToolCall with ExtraContent for Gemini 3 thought_signature persistence
-		toolCall := ToolCall{
-			ID:               tc.ID,
-			Name:             name,
-			Arguments:        arguments,
-			ThoughtSignature: thoughtSignature,
-		}
-
-		if thoughtSignature != "" {
-			toolCall.ExtraContent = &ExtraContent{
-				Google: &GoogleExtra{
-					ThoughtSignature: thoughtSignature,
-				},
-			}
-		}
-
-		toolCalls = append(toolCalls, toolCall)
+		toolCalls = append(toolCalls, tc.toToolCall())
 	}
 
 	return &LLMResponse{
@@ -281,10 +366,105 @@ func parseResponse(body []byte) (*LLMResponse, error) {
 		ReasoningDetails: choice.Message.ReasoningDetails,
 		ToolCalls:        toolCalls,
 		FinishReason:     choice.FinishReason,
-		Usage:            apiResponse.Usage,
+		Usage:            apiResponse.Usage.toUsageInfo(),
 	}, nil
 }
 
+// apiToolCall is a tool call in a chat completion, or a piece of one in a
+// streamed delta.
+type apiToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function *struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+	ExtraContent *struct {
+		Google *struct {
+			ThoughtSignature string `json:"thought_signature"`
+		} `json:"google"`
+	} `json:"extra_content"`
+}
+
+func (tc *apiToolCall) toToolCall() ToolCall {
+	arguments := make(map[string]any)
+	name := ""
+
+	// Extract thought_signature from Gemini/Google-specific extra content
+	thoughtSignature := ""
+	if tc.ExtraContent != nil && tc.ExtraContent.Google != nil {
+		thoughtSignature = tc.ExtraContent.Google.ThoughtSignature
+	}
+
+	if tc.Function != nil {
+		name = tc.Function.Name
+		if tc.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &arguments); err != nil {
+				log.Printf("openai_compat: failed to decode tool call arguments for %q: %v", name, err)
+				arguments["raw"] = tc.Function.Arguments
+			}
+		}
+	}
+
+	// Build ToolCall with ExtraContent for Gemini 3 thought_signature persistence
+	toolCall := ToolCall{
+		ID:               tc.ID,
+		Name:             name,
+		Arguments:        arguments,
+		ThoughtSignature: thoughtSignature,
+	}
+
+	if thoughtSignature != "" {
+		toolCall.ExtraContent = &ExtraContent{
+			Google: &GoogleExtra{
+				ThoughtSignature: thoughtSignature,
+			},
+		}
+	}
+	return toolCall
+}
+
+// apiUsage is the usage object of a chat completion. Providers report prompt
+// cache hits under different names: OpenAI in prompt_tokens_details,
+// DeepSeek as prompt_cache_hit_tokens and Moonshot as cached_tokens.
+type apiUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails *struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+	PromptCacheHitTokens int `json:"prompt_cache_hit_tokens"`
+	CachedTokens         int `json:"cached_tokens"`
+}
+
+func (u *apiUsage) toUsageInfo() *UsageInfo {
+	if u == nil {
+		return nil
+	}
+	cached := u.PromptCacheHitTokens
+	if cached == 0 {
+		cached = u.CachedTokens
+	}
+	if cached == 0 && u.PromptTokensDetails != nil {
+		cached = u.PromptTokensDetails.CachedTokens
+	}
+	info := &UsageInfo{
+		PromptTokens:       u.PromptTokens,
+		CompletionTokens:   u.CompletionTokens,
+		TotalTokens:        u.TotalTokens,
+		CachedPromptTokens: cached,
+	}
+	if u.CompletionTokensDetails != nil {
+		info.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return info
+}
+
 // openaiMessage is the wire-format message for OpenAI-compatible APIs.
 // It mirrors protocoltypes.Message but omits SystemParts, which is an
 // internal field that would be unknown to third-party endpoints.
@@ -2,11 +2,15 @@ package openai_compat
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
 
 func TestProviderChat_UsesMaxCompletionTokensForGLM(t *testing.T) {
@@ -209,6 +213,83 @@ func TestProviderChat_HTTPError(t *testing.T) {
 	}
 }
 
+func TestProviderChat_MapsErrorKinds(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		body       string
+		kind       error
+		wait       time.Duration
+	}{
+		{
+			name:       "rate limited",
+			status:     http.StatusTooManyRequests,
+			retryAfter: "30",
+			body:       `{"error":{"message":"Rate limit reached for gpt-4o","type":"requests","code":"rate_limit_exceeded"}}`,
+			kind:       protocoltypes.ErrRateLimited,
+			wait:       30 * time.Second,
+		},
+		{
+			name:   "context too long",
+			status: http.StatusBadRequest,
+			body: `{"error":{"message":"This model's maximum context length is 128000 tokens.",` +
+				`"code":"context_length_exceeded"}}`,
+			kind: protocoltypes.ErrContextTooLong,
+		},
+		{
+			name:   "content filtered",
+			status: http.StatusBadRequest,
+			body: `{"error":{"message":"The response was filtered due to the prompt triggering ` +
+				`Azure OpenAI's content management policy.","code":"content_filter"}}`,
+			kind: protocoltypes.ErrContentFiltered,
+		},
+		{
+			name:   "auth",
+			status: http.StatusUnauthorized,
+			body:   `{"error":{"message":"Incorrect API key provided","code":"invalid_api_key"}}`,
+			kind:   protocoltypes.ErrAuth,
+		},
+		{
+			name:   "unavailable",
+			status: http.StatusServiceUnavailable,
+			body:   `{"error":{"message":"The server is overloaded or not ready yet."}}`,
+			kind:   protocoltypes.ErrUnavailable,
+		},
+		{
+			name:   "bad request",
+			status: http.StatusBadRequest,
+			body:   `{"error":{"message":"Invalid value for 'role'"}}`,
+			kind:   protocoltypes.ErrBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewProvider("key", server.URL, "")
+			_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("error = %v, want %v", err, tt.kind)
+			}
+			var pe *protocoltypes.ProviderError
+			if !errors.As(err, &pe) || pe.Status != tt.status || pe.RetryAfter != tt.wait {
+				t.Errorf("ProviderError = %+v, want status %d, retry after %v", pe, tt.status, tt.wait)
+			}
+			if !strings.Contains(err.Error(), "Status: ") {
+				t.Errorf("error = %q, want the full detail kept", err.Error())
+			}
+		})
+	}
+}
+
 func TestProviderChat_StripsMoonshotPrefixAndNormalizesKimiTemperature(t *testing.T) {
 	var requestBody map[string]any
 
@@ -416,3 +497,163 @@ func TestProvider_FunctionalOptionRequestTimeoutNonPositive(t *testing.T) {
 		t.Fatalf("http timeout = %v, want %v", p.httpClient.Timeout, defaultRequestTimeout)
 	}
 }
+
+func TestProviderChat_SendsProviderRouting(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	noFallbacks := false
+	p := NewProvider("key", server.URL, "", WithProviderRouting(&ProviderRouting{
+		Order:          []string{"anthropic", "google-vertex"},
+		Ignore:         []string{"deepinfra"},
+		Sort:           "latency",
+		AllowFallbacks: &noFallbacks,
+	}))
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "anthropic/claude-sonnet-4.6", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	provider, ok := requestBody["provider"].(map[string]any)
+	if !ok {
+		t.Fatalf("provider object missing from request: %v", requestBody)
+	}
+	if got := provider["order"]; len(got.([]any)) != 2 {
+		t.Errorf("order = %v, want 2 entries", got)
+	}
+	if got := provider["ignore"].([]any)[0]; got != "deepinfra" {
+		t.Errorf("ignore = %v, want deepinfra", got)
+	}
+	if provider["sort"] != "latency" {
+		t.Errorf("sort = %v, want latency", provider["sort"])
+	}
+	if provider["allow_fallbacks"] != false {
+		t.Errorf("allow_fallbacks = %v, want false", provider["allow_fallbacks"])
+	}
+	if _, ok := provider["only"]; ok {
+		t.Errorf("unset fields must be omitted, got only = %v", provider["only"])
+	}
+}
+
+func TestProviderChat_NoProviderRoutingByDefault(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil); err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if _, ok := requestBody["provider"]; ok {
+		t.Fatalf("did not expect provider object, got %v", requestBody["provider"])
+	}
+}
+
+func TestProviderChat_UnwrapsOpenRouterUpstreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{"error":{"code":502,"message":"Provider returned error","metadata":{` +
+			`"provider_name":"Anthropic",` +
+			`"raw":"{\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}"}}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	_, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "anthropic/claude-sonnet-4.6", nil)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "Upstream: Anthropic: Overloaded (overloaded_error)") {
+		t.Fatalf("error = %q, want upstream cause", err.Error())
+	}
+	if !strings.Contains(err.Error(), "Status: 502") {
+		t.Fatalf("error = %q, want status kept for classification", err.Error())
+	}
+}
+
+func TestUpstreamError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "raw text",
+			body: `{"error":{"message":"Provider returned error","metadata":{"provider_name":"DeepInfra","raw":"upstream timeout"}}}`,
+			want: "DeepInfra: upstream timeout",
+		},
+		{
+			name: "raw object",
+			body: `{"error":{"message":"Provider returned error","metadata":{"provider_name":"OpenAI","raw":{"error":{"message":"Rate limit reached"}}}}}`,
+			want: "OpenAI: Rate limit reached",
+		},
+		{
+			name: "no raw",
+			body: `{"error":{"message":"Provider returned error","metadata":{"provider_name":"Mistral"}}}`,
+			want: "Mistral: Provider returned error",
+		},
+		{
+			name: "not openrouter",
+			body: `{"error":{"message":"invalid api key"}}`,
+			want: "",
+		},
+		{
+			name: "not json",
+			body: `bad gateway`,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := upstreamError([]byte(tt.body)); got != tt.want {
+				t.Fatalf("upstreamError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderChat_ParsesCachedPromptTokens(t *testing.T) {
+	tests := []struct {
+		name          string
+		usage         string
+		wantReasoning int
+	}{
+		{"deepseek", `{"prompt_tokens":100,"completion_tokens":5,"total_tokens":105,"prompt_cache_hit_tokens":80,"prompt_cache_miss_tokens":20}`, 0},
+		{"moonshot", `{"prompt_tokens":100,"completion_tokens":5,"total_tokens":105,"cached_tokens":80}`, 0},
+		{"openai", `{"prompt_tokens":100,"completion_tokens":5,"total_tokens":105,"prompt_tokens_details":{"cached_tokens":80},` +
+			`"completion_tokens_details":{"reasoning_tokens":3}}`, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}],"usage":` + tt.usage + `}`))
+			}))
+			defer server.Close()
+
+			p := NewProvider("key", server.URL, "")
+			out, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "m", nil)
+			if err != nil {
+				t.Fatalf("Chat() error = %v", err)
+			}
+			if out.Usage == nil {
+				t.Fatal("Usage = nil")
+			}
+			if out.Usage.PromptTokens != 100 || out.Usage.CachedPromptTokens != 80 {
+				t.Fatalf("Usage = %+v, want 100 prompt tokens with 80 cached", out.Usage)
+			}
+			if out.Usage.ReasoningTokens != tt.wantReasoning {
+				t.Errorf("ReasoningTokens = %d, want %d", out.Usage.ReasoningTokens, tt.wantReasoning)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package openai_compat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ChatStream is Chat with a streamed response. onDelta is called with each
+// piece of reply text as it arrives; the returned response is the whole
+// reply, as Chat would have returned it. When ctx is cancelled mid-stream,
+// the reply received so far is returned together with ctx.Err().
+func (p *Provider) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(string),
+) (*LLMResponse, error) {
+	resp, err := p.post(ctx, messages, tools, model, options, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	out, err := parseStream(resp.Body, onDelta)
+	if err != nil && ctx.Err() != nil {
+		return out, ctx.Err()
+	}
+	return out, err
+}
+
+// streamChunk is one server-sent event of a streamed chat completion.
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content          string            `json:"content"`
+			ReasoningContent string            `json:"reasoning_content"`
+			Reasoning        string            `json:"reasoning"`
+			ReasoningDetails []ReasoningDetail `json:"reasoning_details"`
+			ToolCalls        []apiToolCall     `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *apiUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// parseStream reads the events of a streamed chat completion, passing
+// content deltas to onDelta, and assembles the complete response. Tool call
+// fragments are joined by their index.
+func parseStream(body io.Reader, onDelta func(string)) (*LLMResponse, error) {
+	var (
+		content, reasoningContent, reasoning strings.Builder
+		reasoningDetails                     []ReasoningDetail
+		finishReason                         string
+		usage                                *apiUsage
+		calls                                = make(map[int]*apiToolCall)
+	)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("API stream failed: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			finishReason = choice.FinishReason
+		}
+		delta := choice.Delta
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			if onDelta != nil {
+				onDelta(delta.Content)
+			}
+		}
+		reasoningContent.WriteString(delta.ReasoningContent)
+		reasoning.WriteString(delta.Reasoning)
+		reasoningDetails = append(reasoningDetails, delta.ReasoningDetails...)
+
+		for _, part := range delta.ToolCalls {
+			call, ok := calls[part.Index]
+			if !ok {
+				call = &apiToolCall{Index: part.Index}
+				calls[part.Index] = call
+			}
+			if part.ID != "" {
+				call.ID = part.ID
+			}
+			if part.ExtraContent != nil {
+				call.ExtraContent = part.ExtraContent
+			}
+			if part.Function != nil {
+				if call.Function == nil {
+					call.Function = part.Function
+					continue
+				}
+				call.Function.Name += part.Function.Name
+				call.Function.Arguments += part.Function.Arguments
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// Keep the text read so far; ChatStream hands it back on cancellation.
+		return &LLMResponse{Content: content.String()}, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	indexes := make([]int, 0, len(calls))
+	for i := range calls {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	toolCalls := make([]ToolCall, 0, len(calls))
+	for _, i := range indexes {
+		toolCalls = append(toolCalls, calls[i].toToolCall())
+	}
+
+	if finishReason == "" {
+		finishReason = "stop"
+	}
+	return &LLMResponse{
+		Content:          content.String(),
+		ReasoningContent: reasoningContent.String(),
+		Reasoning:        reasoning.String(),
+		ReasoningDetails: reasoningDetails,
+		ToolCalls:        toolCalls,
+		FinishReason:     finishReason,
+		Usage:            usage.toUsageInfo(),
+	}, nil
+}
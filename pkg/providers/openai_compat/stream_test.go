@@ -0,0 +1,80 @@
+package openai_compat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProviderChatStream(t *testing.T) {
+	var requestBody map[string]any
+	events := []string{
+		`{"choices":[{"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`{"choices":[{"delta":{"content":"lo"}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function",` +
+			`"function":{"name":"read_file","arguments":"{\"pa"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"th\":\"a.txt\"}"}}]}}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			fmt.Fprintf(w, "data: %s\n\n", e)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	var deltas []string
+	resp, err := p.ChatStream(
+		t.Context(),
+		[]Message{{Role: "user", Content: "hi"}},
+		nil,
+		"gpt-4o",
+		nil,
+		func(s string) { deltas = append(deltas, s) },
+	)
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	if requestBody["stream"] != true {
+		t.Errorf("request stream = %v, want true", requestBody["stream"])
+	}
+	if got := strings.Join(deltas, "|"); got != "Hel|lo" {
+		t.Errorf("deltas = %q, want %q", got, "Hel|lo")
+	}
+	if resp.Content != "Hello" || resp.FinishReason != "tool_calls" {
+		t.Errorf("response = %q/%q, want Hello/tool_calls", resp.Content, resp.FinishReason)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" ||
+		resp.ToolCalls[0].Arguments["path"] != "a.txt" {
+		t.Errorf("tool calls = %+v, want read_file(path=a.txt)", resp.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 15 {
+		t.Errorf("usage = %+v, want 15 total tokens", resp.Usage)
+	}
+}
+
+func TestProviderChatStream_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"slow down"}}`, http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	p := NewProvider("key", server.URL, "")
+	_, err := p.ChatStream(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "gpt-4o", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("ChatStream() error = %v, want the 429 status", err)
+	}
+}
@@ -0,0 +1,129 @@
+package protocoltypes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error kinds every provider maps its failures onto, so callers can branch
+// with errors.Is instead of matching provider-specific messages.
+var (
+	ErrAuth            = errors.New("provider rejected the credentials")
+	ErrRateLimited     = errors.New("provider rate limit reached")
+	ErrContextTooLong  = errors.New("request exceeds the model's context window")
+	ErrContentFiltered = errors.New("provider content filter blocked the request")
+	ErrUnavailable     = errors.New("provider unavailable")
+	ErrBadRequest      = errors.New("provider rejected the request")
+)
+
+// ProviderError is a provider failure classified as one of the error kinds.
+// Error returns the provider's own message, which is what goes in logs;
+// errors.Is matches both Kind and whatever Err wraps.
+type ProviderError struct {
+	Kind       error
+	Provider   string
+	Status     int           // HTTP status, 0 when there was none
+	RetryAfter time.Duration // for ErrRateLimited, when the provider said
+	Err        error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Messages that pin down a kind the status code alone does not, since
+// providers report both with a plain 400.
+var (
+	contextTooLongMarkers = []string{
+		"context_length_exceeded",
+		"context window",
+		"maximum context length",
+		"token limit",
+		"too many tokens",
+		"max_tokens",
+		"invalidparameter",
+		"prompt is too long",
+		"request too large",
+		"exceeds the maximum number of tokens",
+	}
+	contentFilteredMarkers = []string{
+		"content_filter",
+		"content filter",
+		"content management policy",
+		"responsibleaipolicyviolation",
+		"safety system",
+		"blocked by safety",
+		"prohibited_content",
+	}
+)
+
+// KindFor returns the error kind for a failed call with the given HTTP
+// status (0 if unknown) and error message, or nil if neither identifies
+// one.
+func KindFor(status int, message string) error {
+	msg := strings.ToLower(message)
+	if status != http.StatusTooManyRequests && status < 500 {
+		for _, m := range contextTooLongMarkers {
+			if strings.Contains(msg, m) {
+				return ErrContextTooLong
+			}
+		}
+		for _, m := range contentFilteredMarkers {
+			if strings.Contains(msg, m) {
+				return ErrContentFiltered
+			}
+		}
+	}
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden ||
+		status == http.StatusPaymentRequired:
+		return ErrAuth
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusRequestTimeout || status >= 500:
+		return ErrUnavailable
+	case status >= 400:
+		return ErrBadRequest
+	}
+	return nil
+}
+
+// ParseRetryAfter reads a Retry-After header value, either seconds or an
+// HTTP date. It returns 0 when the value is missing or invalid.
+func ParseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d.Round(time.Second)
+		}
+	}
+	return 0
+}
+
+// HTTPError returns the ProviderError for an HTTP response with a failure
+// status. err is the error the provider would otherwise return.
+func HTTPError(provider string, status int, header http.Header, body string, err error) *ProviderError {
+	kind := KindFor(status, body)
+	if kind == nil {
+		kind = ErrUnavailable
+	}
+	pe := &ProviderError{Kind: kind, Provider: provider, Status: status, Err: err}
+	if kind == ErrRateLimited && header != nil {
+		pe.RetryAfter = ParseRetryAfter(header.Get("Retry-After"))
+	}
+	return pe
+}
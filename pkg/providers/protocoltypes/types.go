@@ -45,6 +45,10 @@ type UsageInfo struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	ReasoningTokens  int `json:"reasoning_tokens,omitempty"` // thinking tokens, not included in CompletionTokens
+	// CachedPromptTokens is the part of PromptTokens served from the
+	// provider's prompt cache, which is usually billed at a lower rate.
+	CachedPromptTokens int `json:"cached_prompt_tokens,omitempty"`
 }
 
 // CacheControl marks a content block for LLM-side prefix caching.
@@ -43,6 +43,22 @@ func buildCLIToolsPrompt(tools []ToolDefinition) string {
 	return sb.String()
 }
 
+// TextToolsPrompt describes tools in the system prompt of a model without
+// native tool calling, in the format CLI providers use.
+func TextToolsPrompt(tools []ToolDefinition) string {
+	return buildCLIToolsPrompt(tools)
+}
+
+// ExtractTextToolCalls returns the tool calls a model wrote into its reply
+// as asked by TextToolsPrompt, and the reply without them.
+func ExtractTextToolCalls(text string) ([]ToolCall, string) {
+	calls := extractToolCallsFromText(text)
+	if len(calls) == 0 {
+		return nil, text
+	}
+	return calls, stripToolCallsFromText(text)
+}
+
 // NormalizeToolCall normalizes a ToolCall to ensure all fields are properly populated.
 // It handles cases where Name/Arguments might be in different locations (top-level vs Function)
 // and ensures both are populated consistently.
@@ -2,7 +2,10 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/sipeed/picoclaw/pkg/providers/protocoltypes"
 )
@@ -32,6 +35,21 @@ type LLMProvider interface {
 	GetDefaultModel() string
 }
 
+// StreamingProvider is an LLMProvider that can stream its reply. onDelta
+// is called with each piece of reply text as it arrives; the returned
+// response is the same as Chat's.
+type StreamingProvider interface {
+	LLMProvider
+	ChatStream(
+		ctx context.Context,
+		messages []Message,
+		tools []ToolDefinition,
+		model string,
+		options map[string]any,
+		onDelta func(string),
+	) (*LLMResponse, error)
+}
+
 type StatefulProvider interface {
 	LLMProvider
 	Close()
@@ -43,6 +61,7 @@ type FailoverReason string
 const (
 	FailoverAuth       FailoverReason = "auth"
 	FailoverRateLimit  FailoverReason = "rate_limit"
+	FailoverQuota      FailoverReason = "quota" // used up until it resets, see QuotaExhaustedError
 	FailoverBilling    FailoverReason = "billing"
 	FailoverTimeout    FailoverReason = "timeout"
 	FailoverFormat     FailoverReason = "format"
@@ -74,6 +93,66 @@ func (e *FailoverError) IsRetriable() bool {
 	return e.Reason != FailoverFormat
 }
 
+// ErrQuotaExhausted matches a *QuotaExhaustedError with errors.Is.
+var ErrQuotaExhausted = errors.New("quota exhausted")
+
+// QuotaExhaustedError reports that a provider's quota for a model is used
+// up until it resets, as opposed to a short-lived rate limit.
+type QuotaExhaustedError struct {
+	Provider   string
+	Model      string
+	Message    string
+	ResetAfter string // e.g. "3h12m5s" when the provider says, else ""
+}
+
+func (e *QuotaExhaustedError) Error() string {
+	msg := fmt.Sprintf("%s quota exhausted for %s: %s", e.Provider, e.Model, e.Message)
+	if e.ResetAfter != "" {
+		msg += " (reset in " + e.ResetAfter + ")"
+	}
+	return msg
+}
+
+func (e *QuotaExhaustedError) Is(target error) bool {
+	return target == ErrQuotaExhausted
+}
+
+// ResetIn returns how long until the quota resets, or 0 when unknown.
+func (e *QuotaExhaustedError) ResetIn() time.Duration {
+	d, err := time.ParseDuration(e.ResetAfter)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// PartialResponseError is returned when a request is cancelled after the
+// provider had already produced some content, e.g. part of a stream or the
+// output a CLI printed before it was killed. errors.Is matches the
+// cancellation through Err.
+type PartialResponseError struct {
+	Content string
+	Err     error
+}
+
+func (e *PartialResponseError) Error() string {
+	return fmt.Sprintf("interrupted after %d chars: %v", len(e.Content), e.Err)
+}
+
+func (e *PartialResponseError) Unwrap() error {
+	return e.Err
+}
+
+// partialResponse returns the error for a request cancelled through ctx
+// after producing content. Without content it is just ctx.Err().
+func partialResponse(ctx context.Context, content string) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ctx.Err()
+	}
+	return &PartialResponseError{Content: content, Err: ctx.Err()}
+}
+
 // ModelConfig holds primary model and fallback list.
 type ModelConfig struct {
 	Primary   string
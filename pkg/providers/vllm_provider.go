@@ -0,0 +1,210 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sipeed/picoclaw/pkg/providers/openai_compat"
+)
+
+const defaultVLLMAPIBase = "http://localhost:8000/v1"
+
+// VLLMProvider talks to a vLLM server through its OpenAI-compatible API.
+// A local server usually runs without auth, so the API key is optional.
+// vLLM omits usage in some configurations; token counts are then estimated
+// so usage tracking and budgets keep working.
+type VLLMProvider struct {
+	delegate   *openai_compat.Provider
+	apiKey     string
+	apiBase    string
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	servedModel string
+}
+
+func NewVLLMProvider(apiKey, apiBase, proxy, maxTokensField string, requestTimeoutSeconds int) *VLLMProvider {
+	return newVLLMProvider(defaultHTTPClientConfig, apiKey, apiBase, proxy, maxTokensField, requestTimeoutSeconds)
+}
+
+func newVLLMProvider(
+	pool HTTPClientConfig,
+	apiKey, apiBase, proxy, maxTokensField string,
+	requestTimeoutSeconds int,
+) *VLLMProvider {
+	if apiBase == "" {
+		apiBase = defaultVLLMAPIBase
+	}
+	client := newProxiedHTTPClient(pool, proxy)
+	client.Timeout = 10 * time.Second
+	return &VLLMProvider{
+		delegate: openai_compat.NewProvider(
+			apiKey,
+			apiBase,
+			proxy,
+			openai_compat.WithHTTPClient(newProxiedHTTPClient(pool, proxy)),
+			openai_compat.WithMaxTokensField(maxTokensField),
+			openai_compat.WithRequestTimeout(time.Duration(requestTimeoutSeconds)*time.Second),
+		),
+		apiKey:     apiKey,
+		apiBase:    strings.TrimRight(apiBase, "/"),
+		httpClient: client,
+	}
+}
+
+func (p *VLLMProvider) Chat(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+) (*LLMResponse, error) {
+	model, err := p.model(ctx, model)
+	if err != nil {
+		return nil, MapError("vllm", err)
+	}
+
+	resp, err := p.delegate.Chat(ctx, messages, tools, model, options)
+	if err != nil {
+		return nil, MapError("vllm", err)
+	}
+	resp.Usage = fillMissingUsage(resp.Usage, messages, tools, resp)
+	return resp, nil
+}
+
+func (p *VLLMProvider) ChatStream(
+	ctx context.Context,
+	messages []Message,
+	tools []ToolDefinition,
+	model string,
+	options map[string]any,
+	onDelta func(string),
+) (*LLMResponse, error) {
+	model, err := p.model(ctx, model)
+	if err != nil {
+		return nil, MapError("vllm", err)
+	}
+
+	resp, err := p.delegate.ChatStream(ctx, messages, tools, model, options, onDelta)
+	if err != nil {
+		if ctx.Err() != nil && resp != nil {
+			return nil, partialResponse(ctx, resp.Content)
+		}
+		return nil, MapError("vllm", err)
+	}
+	resp.Usage = fillMissingUsage(resp.Usage, messages, tools, resp)
+	return resp, nil
+}
+
+// model returns the model to request, asking the server for the one it
+// serves when none is named.
+func (p *VLLMProvider) model(ctx context.Context, model string) (string, error) {
+	if model == "" || model == "auto" {
+		return p.resolveServedModel(ctx)
+	}
+	return model, nil
+}
+
+func (p *VLLMProvider) GetDefaultModel() string {
+	return ""
+}
+
+// resolveServedModel asks the server which model it serves, for configs
+// like "vllm/auto" that don't name one. vLLM serves a single model unless
+// started with several --served-model-name aliases; the first is used.
+func (p *VLLMProvider) resolveServedModel(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.servedModel != "" {
+		return p.servedModel, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiBase+"/models", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to list vLLM models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vLLM models: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to list vLLM models: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &models); err != nil {
+		return "", fmt.Errorf("failed to parse vLLM models: %w", err)
+	}
+	if len(models.Data) == 0 || models.Data[0].ID == "" {
+		return "", fmt.Errorf("vLLM server at %s reports no models", p.apiBase)
+	}
+	p.servedModel = models.Data[0].ID
+	return p.servedModel, nil
+}
+
+// fillMissingUsage estimates the token counts a response didn't report,
+// using the same 2.5 characters per token heuristic as the agent's context
+// estimate. Reported counts are kept as they are.
+func fillMissingUsage(usage *UsageInfo, messages []Message, tools []ToolDefinition, resp *LLMResponse) *UsageInfo {
+	if usage == nil {
+		usage = &UsageInfo{}
+	}
+	if usage.PromptTokens == 0 {
+		chars := 0
+		for _, m := range messages {
+			chars += utf8.RuneCountInString(m.Content)
+			for _, tc := range m.ToolCalls {
+				chars += toolCallChars(tc)
+			}
+		}
+		if len(tools) > 0 {
+			if data, err := json.Marshal(tools); err == nil {
+				chars += utf8.RuneCount(data)
+			}
+		}
+		usage.PromptTokens = chars * 2 / 5
+	}
+	if usage.CompletionTokens == 0 {
+		chars := utf8.RuneCountInString(resp.Content) + utf8.RuneCountInString(resp.ReasoningContent)
+		for _, tc := range resp.ToolCalls {
+			chars += toolCallChars(tc)
+		}
+		usage.CompletionTokens = chars * 2 / 5
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	return usage
+}
+
+func toolCallChars(tc ToolCall) int {
+	chars := utf8.RuneCountInString(tc.Name)
+	if tc.Function != nil {
+		chars += utf8.RuneCountInString(tc.Function.Name) + utf8.RuneCountInString(tc.Function.Arguments)
+	} else if len(tc.Arguments) > 0 {
+		if data, err := json.Marshal(tc.Arguments); err == nil {
+			chars += utf8.RuneCount(data)
+		}
+	}
+	return chars
+}
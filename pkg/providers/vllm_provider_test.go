@@ -0,0 +1,169 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+func TestVLLMProviderChat_NoAuthEstimatesMissingUsage(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization = %q, want none", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&requestBody)
+		// Some vLLM versions leave out the usage object.
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{
+				"message": map[string]any{
+					"content": "",
+					"tool_calls": []map[string]any{{
+						"id":       "call_1",
+						"type":     "function",
+						"function": map[string]any{"name": "read_file", "arguments": `{"path":"notes.md"}`},
+					}},
+				},
+				"finish_reason": "tool_calls",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewVLLMProvider("", server.URL+"/v1", "", "", 0)
+	tools := []ToolDefinition{{
+		Type:     "function",
+		Function: ToolFunctionDefinition{Name: "read_file", Description: "Read a file"},
+	}}
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "Please read my notes file"}},
+		tools, "Qwen/Qwen2.5-7B-Instruct", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if requestBody["model"] != "Qwen/Qwen2.5-7B-Instruct" {
+		t.Errorf("model = %v, want the served model name unchanged", requestBody["model"])
+	}
+	if _, ok := requestBody["tools"]; !ok {
+		t.Error("expected tools in request body")
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" {
+		t.Fatalf("ToolCalls = %+v, want one read_file call", resp.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens == 0 || resp.Usage.CompletionTokens == 0 {
+		t.Fatalf("Usage = %+v, want estimated prompt and completion tokens", resp.Usage)
+	}
+	if resp.Usage.TotalTokens != resp.Usage.PromptTokens+resp.Usage.CompletionTokens {
+		t.Errorf("TotalTokens = %d, want prompt + completion", resp.Usage.TotalTokens)
+	}
+}
+
+func TestVLLMProviderChatStream_EstimatesMissingUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hello \"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"there\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var _ StreamingProvider = (*VLLMProvider)(nil)
+	p := NewVLLMProvider("", server.URL+"/v1", "", "", 0)
+	var streamed string
+	resp, err := p.ChatStream(t.Context(), []Message{{Role: "user", Content: "Say hello"}}, nil,
+		"Qwen/Qwen2.5-7B-Instruct", nil, func(delta string) { streamed += delta })
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if streamed != "Hello there" || resp.Content != "Hello there" {
+		t.Errorf("streamed %q, content %q, want %q", streamed, resp.Content, "Hello there")
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens == 0 || resp.Usage.CompletionTokens == 0 {
+		t.Errorf("Usage = %+v, want estimated counts", resp.Usage)
+	}
+}
+
+func TestVLLMProviderChat_KeepsReportedUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want Bearer secret", got)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]any{"content": "hello"}, "finish_reason": "stop"}},
+			"usage":   map[string]any{"prompt_tokens": 11, "completion_tokens": 0, "total_tokens": 0},
+		})
+	}))
+	defer server.Close()
+
+	p := NewVLLMProvider("secret", server.URL, "", "", 0)
+	resp, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "m", nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Usage.PromptTokens != 11 {
+		t.Errorf("PromptTokens = %d, want reported 11", resp.Usage.PromptTokens)
+	}
+	if resp.Usage.CompletionTokens != 2 || resp.Usage.TotalTokens != 13 {
+		t.Errorf("Usage = %+v, want estimated completion 2 and total 13", resp.Usage)
+	}
+}
+
+func TestVLLMProviderChat_AutoModelUsesServedModel(t *testing.T) {
+	var modelCalls int
+	var gotModel any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/models":
+			modelCalls++
+			json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{{"id": "served-model"}}})
+		case "/chat/completions":
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			gotModel = body["model"]
+			json.NewEncoder(w).Encode(map[string]any{
+				"choices": []map[string]any{{"message": map[string]any{"content": "ok"}, "finish_reason": "stop"}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	p := NewVLLMProvider("", server.URL, "", "", 0)
+	for range 2 {
+		if _, err := p.Chat(t.Context(), []Message{{Role: "user", Content: "hi"}}, nil, "auto", nil); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if gotModel != "served-model" {
+		t.Errorf("model = %v, want served-model", gotModel)
+	}
+	if modelCalls != 1 {
+		t.Errorf("/models called %d times, want 1 (cached)", modelCalls)
+	}
+}
+
+func TestCreateProviderFromConfig_VLLMWithoutKeyOrBase(t *testing.T) {
+	cfg := &config.ModelConfig{ModelName: "local", Model: "vllm/auto"}
+
+	provider, modelID, err := CreateProviderFromConfig(cfg, defaultHTTPClientConfig)
+	if err != nil {
+		t.Fatalf("CreateProviderFromConfig() error = %v", err)
+	}
+	p, ok := provider.(*VLLMProvider)
+	if !ok {
+		t.Fatalf("expected *VLLMProvider, got %T", provider)
+	}
+	if p.apiBase != defaultVLLMAPIBase {
+		t.Errorf("apiBase = %q, want %q", p.apiBase, defaultVLLMAPIBase)
+	}
+	if modelID != "auto" {
+		t.Errorf("modelID = %q, want auto", modelID)
+	}
+}
@@ -220,20 +220,47 @@ func (r *RouteResolver) pickAgentID(agentID string) string {
 		return NormalizeAgentID(r.resolveDefaultAgentID())
 	}
 	normalized := NormalizeAgentID(trimmed)
-	agents := r.cfg.Agents.List
-	if len(agents) == 0 {
+	if r.hasAgent(normalized) {
 		return normalized
 	}
+	return NormalizeAgentID(r.resolveDefaultAgentID())
+}
+
+// hasAgent reports whether a normalized agent ID names a configured agent.
+// Without configured agents every ID is accepted.
+func (r *RouteResolver) hasAgent(normalized string) bool {
+	agents := r.cfg.Agents.All()
+	if len(agents) == 0 {
+		return true
+	}
 	for _, a := range agents {
 		if NormalizeAgentID(a.ID) == normalized {
-			return normalized
+			return true
 		}
 	}
+	return false
+}
+
+// UnknownBindings returns the bindings whose agent_id names no configured
+// agent. Messages they match go to the default agent. A binding without an
+// agent_id is not unknown: it names the default agent on purpose.
+func (r *RouteResolver) UnknownBindings() []config.AgentBinding {
+	var unknown []config.AgentBinding
+	for _, b := range r.cfg.Bindings {
+		if strings.TrimSpace(b.AgentID) != "" && !r.hasAgent(NormalizeAgentID(b.AgentID)) {
+			unknown = append(unknown, b)
+		}
+	}
+	return unknown
+}
+
+// DefaultAgentID returns the agent that handles messages no binding matches.
+func (r *RouteResolver) DefaultAgentID() string {
 	return NormalizeAgentID(r.resolveDefaultAgentID())
 }
 
 func (r *RouteResolver) resolveDefaultAgentID() string {
-	agents := r.cfg.Agents.List
+	agents := r.cfg.Agents.All()
 	if len(agents) == 0 {
 		return DefaultAgentID
 	}
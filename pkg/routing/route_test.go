@@ -261,6 +261,27 @@ func TestResolveRoute_InvalidAgentFallsToDefault(t *testing.T) {
 	}
 }
 
+func TestUnknownBindings(t *testing.T) {
+	agents := []config.AgentConfig{
+		{ID: "main", Default: true},
+		{ID: "coder-bot"},
+	}
+	bindings := []config.AgentBinding{
+		{AgentID: "Coder Bot", Match: config.BindingMatch{Channel: "telegram"}},
+		{AgentID: "", Match: config.BindingMatch{Channel: "slack"}},
+		{AgentID: "stale", Match: config.BindingMatch{Channel: "discord"}},
+	}
+	r := NewRouteResolver(testConfig(agents, bindings))
+
+	unknown := r.UnknownBindings()
+	if len(unknown) != 1 || unknown[0].AgentID != "stale" {
+		t.Fatalf("UnknownBindings() = %+v, want only the stale binding", unknown)
+	}
+	if route := r.ResolveRoute(RouteInput{Channel: "telegram"}); route.AgentID != "coder-bot" {
+		t.Errorf("AgentID = %q, want coder-bot", route.AgentID)
+	}
+}
+
 func TestResolveRoute_DefaultAgentSelection(t *testing.T) {
 	agents := []config.AgentConfig{
 		{ID: "alpha"},
@@ -0,0 +1,57 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CommandScanner runs a scanner command such as clamscan with the file path
+// appended to Args. Exit status 0 means clean, 1 infected and anything else
+// an error.
+type CommandScanner struct {
+	Command string
+	Args    []string
+}
+
+func (s *CommandScanner) Scan(ctx context.Context, path string) (Result, error) {
+	args := append(append([]string{}, s.Args...), path)
+	cmd := exec.CommandContext(ctx, s.Command, args...)
+	// A killed scanner may leave children holding its output open.
+	cmd.WaitDelay = time.Second
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if err == nil {
+		return Result{}, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && ctx.Err() == nil {
+		return Result{Infected: true, Signature: signature(out.String(), path)}, nil
+	}
+	if msg := strings.TrimSpace(out.String()); msg != "" {
+		return Result{}, fmt.Errorf("%s failed: %w: %s", filepath.Base(s.Command), err, msg)
+	}
+	return Result{}, fmt.Errorf("%s failed: %w", filepath.Base(s.Command), err)
+}
+
+// signature picks the detection name out of scanner output in clamscan's
+// "<path>: <signature> FOUND" format, falling back to the first line.
+func signature(output, path string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if found, ok := strings.CutSuffix(line, " FOUND"); ok {
+			found = strings.TrimPrefix(found, path+":")
+			return strings.TrimSpace(found)
+		}
+	}
+	return strings.TrimSpace(lines[0])
+}
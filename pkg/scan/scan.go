@@ -0,0 +1,81 @@
+// Package scan checks inbound attachments for malware with an external
+// scanner command or a clamd daemon.
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// Result is the outcome of scanning one file.
+type Result struct {
+	Infected  bool
+	Signature string // what the scanner found, when infected
+}
+
+// Scanner scans a local file.
+type Scanner interface {
+	Scan(ctx context.Context, path string) (Result, error)
+}
+
+const defaultTimeout = 30 * time.Second
+
+// Policy is a Scanner together with how scan results are handled.
+type Policy struct {
+	Scanner        Scanner
+	Timeout        time.Duration // per file; zero means no limit
+	BlockUnscanned bool          // drop files whose scan failed
+	NotifyChannel  string
+	NotifyChatID   string
+}
+
+// NewPolicy builds the scan policy for cfg. It returns nil when scanning is
+// off.
+func NewPolicy(cfg *config.Config) (*Policy, error) {
+	sc := cfg.Security.Scan
+	policy := &Policy{
+		Timeout:        defaultTimeout,
+		BlockUnscanned: sc.BlockUnscanned,
+		NotifyChannel:  sc.NotifyChannel,
+		NotifyChatID:   sc.NotifyChatID,
+	}
+	if sc.TimeoutSeconds > 0 {
+		policy.Timeout = time.Duration(sc.TimeoutSeconds) * time.Second
+	}
+
+	switch sc.Mode {
+	case "", config.ScanModeOff:
+		return nil, nil
+	case config.ScanModeCommand:
+		if sc.Command == "" {
+			return nil, fmt.Errorf("scan: command is required in command mode")
+		}
+		policy.Scanner = &CommandScanner{Command: sc.Command, Args: sc.Args}
+	case config.ScanModeClamd:
+		if sc.ClamdAddress == "" {
+			return nil, fmt.Errorf("scan: clamd_address is required in clamd mode")
+		}
+		policy.Scanner = &ClamdScanner{Address: sc.ClamdAddress}
+	default:
+		return nil, fmt.Errorf("scan: unknown mode %q", sc.Mode)
+	}
+	return policy, nil
+}
+
+// Scan scans path within the policy's timeout.
+func (p *Policy) Scan(ctx context.Context, path string) (Result, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	res, err := p.Scanner.Scan(ctx, path)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Result{}, fmt.Errorf("scan timed out after %s", p.Timeout)
+	}
+	return res, err
+}
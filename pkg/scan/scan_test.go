@@ -0,0 +1,187 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+)
+
+// fakeScanner writes a clamscan-like script: files containing "EICAR" are
+// infected, files containing "SLOW" hang, and anything else is clean.
+func fakeScanner(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake scanner is a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "fakescan")
+	body := `#!/bin/sh
+for f; do :; done
+if grep -q SLOW "$f"; then sleep 10; fi
+if grep -q EICAR "$f"; then echo "$f: Eicar-Test-Signature FOUND"; exit 1; fi
+echo "$f: OK"
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "attachment.bin")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommandScanner(t *testing.T) {
+	p := &Policy{
+		Scanner: &CommandScanner{Command: fakeScanner(t), Args: []string{"--no-summary"}},
+		Timeout: 5 * time.Second,
+	}
+
+	res, err := p.Scan(context.Background(), writeFile(t, "hello"))
+	if err != nil || res.Infected {
+		t.Fatalf("clean file: got %+v, %v", res, err)
+	}
+
+	res, err = p.Scan(context.Background(), writeFile(t, "X5O EICAR"))
+	if err != nil {
+		t.Fatalf("infected file: %v", err)
+	}
+	if !res.Infected || res.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("infected file: got %+v", res)
+	}
+}
+
+func TestCommandScannerTimeout(t *testing.T) {
+	p := &Policy{
+		Scanner: &CommandScanner{Command: fakeScanner(t)},
+		Timeout: 200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err := p.Scan(context.Background(), writeFile(t, "SLOW"))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("err = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("scan took %s, want it killed at the timeout", elapsed)
+	}
+}
+
+func TestCommandScannerError(t *testing.T) {
+	s := &CommandScanner{Command: filepath.Join(t.TempDir(), "missing")}
+	if _, err := s.Scan(context.Background(), writeFile(t, "hello")); err == nil {
+		t.Fatal("expected an error for a missing scanner")
+	}
+}
+
+// fakeClamd serves one INSTREAM request, reporting data containing "EICAR"
+// as infected.
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if cmd, err := r.ReadString(0); err != nil || cmd != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var data []byte
+				for {
+					var size uint32
+					if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					chunk := make([]byte, size)
+					if _, err := io.ReadFull(r, chunk); err != nil {
+						return
+					}
+					data = append(data, chunk...)
+				}
+				if strings.Contains(string(data), "EICAR") {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+				} else {
+					conn.Write([]byte("stream: OK\x00"))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestClamdScanner(t *testing.T) {
+	s := &ClamdScanner{Address: fakeClamd(t)}
+
+	res, err := s.Scan(context.Background(), writeFile(t, strings.Repeat("a", 3*clamdChunkSize)))
+	if err != nil || res.Infected {
+		t.Fatalf("clean file: got %+v, %v", res, err)
+	}
+
+	res, err = s.Scan(context.Background(), writeFile(t, strings.Repeat("a", clamdChunkSize)+"EICAR"))
+	if err != nil {
+		t.Fatalf("infected file: %v", err)
+	}
+	if !res.Infected || res.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("infected file: got %+v", res)
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	if _, err := parseClamdReply("INSTREAM size limit exceeded. ERROR"); err == nil {
+		t.Error("expected an error for an ERROR reply")
+	}
+	if res, err := parseClamdReply("stream: OK"); err != nil || res.Infected {
+		t.Errorf("OK reply: got %+v, %v", res, err)
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if p, err := NewPolicy(cfg); err != nil || p != nil {
+		t.Fatalf("scanning off: got %+v, %v; want nil", p, err)
+	}
+
+	cfg.Security.Scan.Mode = config.ScanModeClamd
+	cfg.Security.Scan.ClamdAddress = "unix:/run/clamav/clamd.ctl"
+	cfg.Security.Scan.TimeoutSeconds = 5
+	p, err := NewPolicy(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Scanner.(*ClamdScanner); !ok || p.Timeout != 5*time.Second {
+		t.Fatalf("got %+v", p)
+	}
+
+	cfg.Security.Scan.Mode = "bogus"
+	if _, err := NewPolicy(cfg); err == nil {
+		t.Fatal("expected an error for an unknown mode")
+	}
+}
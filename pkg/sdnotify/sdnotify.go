@@ -0,0 +1,58 @@
+// Package sdnotify tells systemd about the service's state with the
+// sd_notify datagram protocol: startup completion, a free-form status line
+// and watchdog pings. It writes to $NOTIFY_SOCKET directly, without
+// libsystemd, and does nothing when the process was not started by systemd.
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// States sent with Notify.
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Status returns the state that sets the status line systemctl shows.
+func Status(s string) string {
+	return "STATUS=" + s
+}
+
+// Notify sends state to systemd. Several states may be sent at once,
+// separated by newlines. It returns false, with no error, when
+// $NOTIFY_SOCKET is not set.
+func Notify(state string) (bool, error) {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return false, nil
+	}
+	// A leading "@" names a socket in the abstract namespace, which the net
+	// package understands as is.
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout systemd expects pings
+// within, or 0 when the watchdog is off or meant for another process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
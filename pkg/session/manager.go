@@ -15,6 +15,7 @@ type Session struct {
 	Key      string              `json:"key"`
 	Messages []providers.Message `json:"messages"`
 	Summary  string              `json:"summary,omitempty"`
+	Model    string              `json:"model,omitempty"` // model_list name of the latest turn
 	Created  time.Time           `json:"created"`
 	Updated  time.Time           `json:"updated"`
 }
@@ -122,6 +123,31 @@ func (sm *SessionManager) SetSummary(key string, summary string) {
 	}
 }
 
+// GetModel returns the model_list name that served the session's latest
+// turn, or "" if none is recorded.
+func (sm *SessionManager) GetModel(key string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	session, ok := sm.sessions[key]
+	if !ok {
+		return ""
+	}
+	return session.Model
+}
+
+// SetModel records the model_list name that served the session's latest
+// turn.
+func (sm *SessionManager) SetModel(key, model string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	session, ok := sm.sessions[key]
+	if ok {
+		session.Model = model
+	}
+}
+
 func (sm *SessionManager) TruncateHistory(key string, keepLast int) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
@@ -180,6 +206,7 @@ func (sm *SessionManager) Save(key string) error {
 	snapshot := Session{
 		Key:     stored.Key,
 		Summary: stored.Summary,
+		Model:   stored.Model,
 		Created: stored.Created,
 		Updated: stored.Updated,
 	}
@@ -233,6 +260,24 @@ func (sm *SessionManager) Save(key string) error {
 	return nil
 }
 
+// Load reads the session stored under key in storage without loading the
+// others, for commands that inspect a session outside the agent.
+func Load(storage, key string) (*Session, error) {
+	filename := sanitizeFilename(key)
+	if filename == "." || !filepath.IsLocal(filename) || strings.ContainsAny(filename, `/\`) {
+		return nil, os.ErrInvalid
+	}
+	data, err := os.ReadFile(filepath.Join(storage, filename+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
 func (sm *SessionManager) loadSessions() error {
 	files, err := os.ReadDir(sm.storage)
 	if err != nil {
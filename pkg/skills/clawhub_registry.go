@@ -1,10 +1,13 @@
 package skills
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
@@ -26,8 +29,11 @@ type ClawHubRegistry struct {
 	searchPath      string // Search API
 	skillsPath      string // For retrieving skill metadata
 	downloadPath    string // For fetching ZIP files for download
+	uploadPath      string // For publishing skill tarballs
 	maxZipSize      int
 	maxResponseSize int
+	name            string
+	retry           retryPolicy
 	client          *http.Client
 }
 
@@ -49,6 +55,10 @@ func NewClawHubRegistry(cfg ClawHubConfig) *ClawHubRegistry {
 	if downloadPath == "" {
 		downloadPath = "/api/v1/download"
 	}
+	uploadPath := cfg.UploadPath
+	if uploadPath == "" {
+		uploadPath = "/api/v1/publish"
+	}
 
 	timeout := defaultClawHubTimeout
 	if cfg.Timeout > 0 {
@@ -71,8 +81,11 @@ func NewClawHubRegistry(cfg ClawHubConfig) *ClawHubRegistry {
 		searchPath:      searchPath,
 		skillsPath:      skillsPath,
 		downloadPath:    downloadPath,
+		uploadPath:      uploadPath,
 		maxZipSize:      maxZip,
 		maxResponseSize: maxResp,
+		name:            "clawhub",
+		retry:           newRetryPolicy("clawhub", cfg),
 		client: &http.Client{
 			Timeout: timeout,
 			Transport: &http.Transport{
@@ -84,8 +97,17 @@ func NewClawHubRegistry(cfg ClawHubConfig) *ClawHubRegistry {
 	}
 }
 
+// newNamedClawHubRegistry returns a client for a ClawHub-compatible
+// registry configured under name.
+func newNamedClawHubRegistry(name string, cfg ClawHubConfig) *ClawHubRegistry {
+	c := NewClawHubRegistry(cfg)
+	c.name = name
+	c.retry.registry = name
+	return c
+}
+
 func (c *ClawHubRegistry) Name() string {
-	return "clawhub"
+	return c.name
 }
 
 // --- Search ---
@@ -213,7 +235,10 @@ func (c *ClawHubRegistry) GetSkillMeta(ctx context.Context, slug string) (*Skill
 
 // DownloadAndInstall fetches metadata (with fallback), resolves version,
 // downloads the skill ZIP, and extracts it to targetDir.
-// Returns an InstallResult for the caller to use for moderation decisions.
+// Returns an InstallResult for the caller to use for moderation decisions;
+// a skill blocked as malware is not downloaded. The metadata fetch and the
+// download are retried after transient failures, and the download reports
+// progress to the function set with WithDownloadProgress.
 func (c *ClawHubRegistry) DownloadAndInstall(
 	ctx context.Context,
 	slug, version, targetDir string,
@@ -224,7 +249,11 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 
 	// Step 1: Fetch metadata (with fallback).
 	result := &InstallResult{}
-	meta, err := c.GetSkillMeta(ctx, slug)
+	var meta *SkillMeta
+	err := c.retry.do(ctx, "metadata", func() (err error) {
+		meta, err = c.GetSkillMeta(ctx, slug)
+		return err
+	})
 	if err != nil {
 		// Fallback: proceed without metadata.
 		meta = nil
@@ -235,6 +264,9 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 		result.IsSuspicious = meta.IsSuspicious
 		result.Summary = meta.Summary
 	}
+	if result.IsMalwareBlocked {
+		return result, nil
+	}
 
 	// Step 2: Resolve version.
 	installVersion := version
@@ -259,15 +291,18 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 	}
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	if c.authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
-	}
-
-	tmpPath, err := utils.DownloadToFile(ctx, c.client, req, int64(c.maxZipSize))
+	var tmpPath string
+	err = c.retry.do(ctx, "download", func() error {
+		req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if c.authToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.authToken)
+		}
+		tmpPath, err = utils.DownloadToFileWithProgress(ctx, c.client, req, int64(c.maxZipSize), downloadProgress(ctx))
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("download failed: %w", err)
 	}
@@ -281,33 +316,108 @@ func (c *ClawHubRegistry) DownloadAndInstall(
 	return result, nil
 }
 
+// --- Publish ---
+
+// Publish uploads a signed skill tarball as a multipart form.
+// Uploading requires an auth token.
+func (c *ClawHubRegistry) Publish(ctx context.Context, pkg *SkillPackage) (*PublishResult, error) {
+	if c.authToken == "" {
+		return nil, fmt.Errorf("clawhub publish requires authentication")
+	}
+	if err := utils.ValidateSkillIdentifier(pkg.Slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", pkg.Slug, err.Error())
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fields := map[string]string{
+		"slug":       pkg.Slug,
+		"version":    pkg.Version,
+		"summary":    pkg.Description,
+		"sha256":     pkg.Checksum,
+		"signature":  base64.StdEncoding.EncodeToString(pkg.Signature),
+		"public_key": base64.StdEncoding.EncodeToString(pkg.PublicKey),
+	}
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return nil, fmt.Errorf("failed to build upload form: %w", err)
+		}
+	}
+	fw, err := mw.CreateFormFile("file", pkg.Slug+"-"+pkg.Version+".tar.gz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload form: %w", err)
+	}
+	if _, err := fw.Write(pkg.Tarball); err != nil {
+		return nil, fmt.Errorf("failed to build upload form: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+c.uploadPath, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.authToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upload request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(c.maxResponseSize)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("upload request failed: HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	result := &PublishResult{Slug: pkg.Slug, Version: pkg.Version}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, result); err != nil {
+			return nil, fmt.Errorf("failed to parse publish response: %w", err)
+		}
+	}
+	return result, nil
+}
+
 // --- HTTP helper ---
 
 func (c *ClawHubRegistry) doGet(ctx context.Context, urlStr string) ([]byte, error) {
+	return getJSON(ctx, c.client, urlStr, c.authToken, c.maxResponseSize)
+}
+
+// getJSON fetches urlStr, sending authToken as a bearer token when set, and
+// returns at most maxSize bytes of the body.
+func getJSON(ctx context.Context, client *http.Client, urlStr, authToken string, maxSize int) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Accept", "application/json")
-	if c.authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	if authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+authToken)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// Limit response body read to prevent memory issues.
-	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(c.maxResponseSize)))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, &utils.HTTPStatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return body, nil
@@ -0,0 +1,227 @@
+package skills
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// SkillCommand is a named entrypoint declared under "commands" in the
+// SKILL.md frontmatter. The agent offers it to the model as a tool, so a
+// deterministic script runs instead of a shell command the model writes:
+//
+//	commands:
+//	  forecast:
+//	    description: Get the weather forecast for a city
+//	    command: python3 forecast.py --city {city} --days={days}
+//	    parameters:
+//	      type: object
+//	      properties:
+//	        city: {type: string, maxLength: 64}
+//	        days: {type: integer, minimum: 1, maximum: 7}
+//	      required: [city]
+//
+// Command is split into words on whitespace and run without a shell, from
+// the skill's directory. A {name} placeholder is replaced by the parameter's
+// value inside its word, so a value always stays a single argument and is
+// never interpreted. Words with a placeholder for an optional parameter that
+// was not given are left out. A value may not turn a word into an option:
+// an argument starting with "-" is rejected unless the template word does.
+type SkillCommand struct {
+	Description string         `yaml:"description" json:"description"`
+	Command     string         `yaml:"command"     json:"command"`
+	Parameters  map[string]any `yaml:"parameters"  json:"parameters,omitempty"`
+}
+
+var (
+	commandNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// Schema returns the command's parameter schema, an empty object schema
+// when none is declared.
+func (c SkillCommand) Schema() map[string]any {
+	if len(c.Parameters) == 0 {
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return c.Parameters
+}
+
+// validate checks the command template and parameter schema.
+func (c SkillCommand) validate() error {
+	words := strings.Fields(c.Command)
+	if len(words) == 0 {
+		return errors.New("command is required")
+	}
+	if placeholderPattern.MatchString(words[0]) {
+		return errors.New("the program must not be a parameter")
+	}
+	if _, err := c.resolveSchema(); err != nil {
+		return err
+	}
+	props, _ := c.Schema()["properties"].(map[string]any)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(c.Command, -1) {
+		if _, ok := props[m[1]]; !ok {
+			return fmt.Errorf("placeholder {%s} is not a declared parameter", m[1])
+		}
+	}
+	return nil
+}
+
+func (c SkillCommand) resolveSchema() (*jsonschema.Resolved, error) {
+	data, err := json.Marshal(c.Schema())
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	if schema.Type != "object" {
+		return nil, errors.New("parameters must be an object schema")
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	return resolved, nil
+}
+
+// Args validates params against the schema and returns the command line to
+// run, with placeholders replaced by the parameter values.
+func (c SkillCommand) Args(params map[string]any) ([]string, error) {
+	resolved, err := c.resolveSchema()
+	if err != nil {
+		return nil, err
+	}
+	if params == nil {
+		params = map[string]any{}
+	}
+	if err := resolved.Validate(params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	var args []string
+	for _, word := range strings.Fields(c.Command) {
+		missing := false
+		arg := placeholderPattern.ReplaceAllStringFunc(word, func(ph string) string {
+			value, ok := params[ph[1:len(ph)-1]]
+			if !ok || value == nil {
+				missing = true
+				return ""
+			}
+			return formatArg(value)
+		})
+		if missing {
+			continue
+		}
+		if strings.HasPrefix(arg, "-") && !strings.HasPrefix(word, "-") {
+			return nil, fmt.Errorf("invalid arguments: %q would be read as an option", arg)
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// formatArg renders a JSON value as a command-line argument.
+func formatArg(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// validateCommands checks the commands of a manifest.
+func validateCommands(commands map[string]SkillCommand) error {
+	for name, cmd := range commands {
+		if !commandNamePattern.MatchString(name) {
+			return fmt.Errorf("command %q: name must be lowercase alphanumeric with - or _", name)
+		}
+		if err := cmd.validate(); err != nil {
+			return fmt.Errorf("command %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// CommandToolName returns the name of the tool that runs a skill command,
+// e.g. skill_weather_forecast.
+func CommandToolName(skill, command string) string {
+	name := "skill_" + strings.ToLower(skill) + "_" + command
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// SkillCommandInfo is a command of an installed skill.
+type SkillCommandInfo struct {
+	Skill   string
+	Name    string
+	Dir     string // the skill's directory, where the command runs
+	Command SkillCommand
+	Env     []SkillEnvVar // the variables the skill declares
+}
+
+// ToolName returns the name of the tool that runs the command.
+func (i SkillCommandInfo) ToolName() string {
+	return CommandToolName(i.Skill, i.Name)
+}
+
+// GetManifest returns the parsed frontmatter of an available skill.
+func (sl *SkillsLoader) GetManifest(name string) (*SkillManifest, bool) {
+	for _, info := range sl.ListSkills() {
+		if info.Name != name {
+			continue
+		}
+		content, err := os.ReadFile(info.Path)
+		if err != nil {
+			return nil, false
+		}
+		m, err := ParseSkillManifest(string(content))
+		return m, err == nil
+	}
+	return nil, false
+}
+
+// ListCommands returns the commands declared by the available skills,
+// sorted by tool name.
+func (sl *SkillsLoader) ListCommands() []SkillCommandInfo {
+	var commands []SkillCommandInfo
+	for _, info := range sl.ListSkills() {
+		content, err := os.ReadFile(info.Path)
+		if err != nil {
+			continue
+		}
+		m, err := ParseSkillManifest(string(content))
+		if err != nil {
+			continue
+		}
+		for name, cmd := range m.Commands {
+			commands = append(commands, SkillCommandInfo{
+				Skill:   m.Name,
+				Name:    name,
+				Dir:     filepath.Dir(info.Path),
+				Command: cmd,
+				Env:     m.Env,
+			})
+		}
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].ToolName() < commands[j].ToolName() })
+	return commands
+}
@@ -0,0 +1,90 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const weatherSkill = `---
+name: weather
+description: Weather forecasts
+commands:
+  forecast:
+    description: Get the forecast for a city
+    command: python3 forecast.py --city {city} --days={days}
+    parameters:
+      type: object
+      properties:
+        city: {type: string, maxLength: 64}
+        days: {type: integer, minimum: 1, maximum: 7}
+      required: [city]
+      additionalProperties: false
+---
+
+# Weather
+`
+
+func TestSkillCommandArgs(t *testing.T) {
+	m, err := ParseSkillManifest(weatherSkill)
+	require.NoError(t, err)
+	cmd := m.Commands["forecast"]
+
+	args, err := cmd.Args(map[string]any{"city": "Rio; rm -rf / $(id)", "days": float64(3)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"python3", "forecast.py", "--city", "Rio; rm -rf / $(id)", "--days=3"}, args,
+		"a value must stay one argument, verbatim")
+
+	args, err = cmd.Args(map[string]any{"city": "Oslo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"python3", "forecast.py", "--city", "Oslo"}, args, "optional word left out")
+
+	for name, params := range map[string]map[string]any{
+		"missing required": {"days": float64(1)},
+		"out of range":     {"city": "Oslo", "days": float64(30)},
+		"wrong type":       {"city": float64(1)},
+		"unknown":          {"city": "Oslo", "shell": "sh"},
+		"option injection": {"city": "--output=/etc/passwd"},
+	} {
+		_, err := cmd.Args(params)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseSkillManifestRejectsBadCommands(t *testing.T) {
+	for name, commands := range map[string]string{
+		"no command":        "  run:\n    description: x\n",
+		"undeclared param":  "  run:\n    command: echo {who}\n",
+		"program is param":  "  run:\n    command: '{prog} x'\n    parameters: {type: object, properties: {prog: {type: string}}}\n",
+		"bad name":          "  Run Me:\n    command: echo hi\n",
+		"non-object schema": "  run:\n    command: echo hi\n    parameters: {type: string}\n",
+	} {
+		content := "---\nname: demo\ndescription: demo\ncommands:\n" + commands + "---\n"
+		_, err := ParseSkillManifest(content)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestListCommands(t *testing.T) {
+	ws := t.TempDir()
+	dir := filepath.Join(ws, "skills", "weather")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(weatherSkill), 0o644))
+	createSkillDir(t, filepath.Join(ws, "skills"), "plain", "plain", "no commands")
+
+	sl := NewSkillsLoader(ws, "", "")
+	commands := sl.ListCommands()
+	require.Len(t, commands, 1)
+	assert.Equal(t, "skill_weather_forecast", commands[0].ToolName())
+	assert.Equal(t, dir, commands[0].Dir)
+
+	m, ok := sl.GetSkillByToolName("skill_weather_forecast")
+	require.True(t, ok)
+	assert.Equal(t, "weather", m.Name)
+
+	sl.SetAllowed([]string{"plain"})
+	assert.Empty(t, sl.ListCommands())
+}
@@ -0,0 +1,83 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SkillEnvVar is an environment variable a skill needs, declared under
+// "env" in the SKILL.md frontmatter. An entry is either just the name or a
+// mapping:
+//
+//	env:
+//	  - OPENWEATHER_API_KEY
+//	  - name: WEATHER_UNITS
+//	    description: metric or imperial
+//	    optional: true
+//
+// Variables are required unless marked optional. Their values come from
+// tools.skills.env in the config, or from picoclaw's own environment.
+type SkillEnvVar struct {
+	Name        string `yaml:"name"        json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Optional    bool   `yaml:"optional"    json:"optional,omitempty"`
+}
+
+// UnmarshalYAML accepts a bare variable name as well as a mapping.
+func (v *SkillEnvVar) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		v.Name = node.Value
+		return nil
+	}
+	type plain SkillEnvVar
+	return node.Decode((*plain)(v))
+}
+
+// validateEnv checks the env declarations of a manifest.
+func validateEnv(vars []SkillEnvVar) error {
+	seen := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		if !envNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("env %q: not a valid variable name", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("env %q: declared twice", v.Name)
+		}
+		seen[v.Name] = true
+	}
+	return nil
+}
+
+// MissingEnv returns the required variables in vars that are set neither in
+// configured nor in the process environment.
+func MissingEnv(vars []SkillEnvVar, configured map[string]string) []SkillEnvVar {
+	var missing []SkillEnvVar
+	for _, v := range vars {
+		if v.Optional {
+			continue
+		}
+		if configured[v.Name] != "" || os.Getenv(v.Name) != "" {
+			continue
+		}
+		missing = append(missing, v)
+	}
+	return missing
+}
+
+// ResolveEnv returns the NAME=value pairs of the variables in vars that
+// configured sets. Only declared variables are passed on, so a skill never
+// sees the keys meant for another one.
+func ResolveEnv(vars []SkillEnvVar, configured map[string]string) []string {
+	var env []string
+	for _, v := range vars {
+		if value, ok := configured[v.Name]; ok && value != "" {
+			env = append(env, v.Name+"="+value)
+		}
+	}
+	return env
+}
@@ -0,0 +1,27 @@
+package skills
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingEnv(t *testing.T) {
+	t.Setenv("PICOCLAW_TEST_FROM_PROCESS", "set")
+	vars := []SkillEnvVar{
+		{Name: "PICOCLAW_TEST_FROM_CONFIG"},
+		{Name: "PICOCLAW_TEST_FROM_PROCESS"},
+		{Name: "PICOCLAW_TEST_UNSET"},
+		{Name: "PICOCLAW_TEST_OPTIONAL", Optional: true},
+	}
+	configured := map[string]string{"PICOCLAW_TEST_FROM_CONFIG": "key"}
+
+	assert.Equal(t, []SkillEnvVar{{Name: "PICOCLAW_TEST_UNSET"}}, MissingEnv(vars, configured))
+}
+
+func TestResolveEnv(t *testing.T) {
+	vars := []SkillEnvVar{{Name: "API_KEY"}, {Name: "UNITS", Optional: true}}
+	configured := map[string]string{"API_KEY": "secret", "OTHER_KEY": "not declared"}
+
+	assert.Equal(t, []string{"API_KEY=secret"}, ResolveEnv(vars, configured))
+}
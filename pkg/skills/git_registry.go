@@ -0,0 +1,266 @@
+package skills
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// GitRegistry implements SkillRegistry over a git repository holding one
+// skill per top-level directory, each with its own SKILL.md. A skill's slug
+// is its directory name. The repository is shallow-cloned on demand; the
+// skill listing used by Search and GetSkillMeta is kept for gitListingTTL.
+// A version names a branch or tag, and the installed version is the short
+// commit hash.
+type GitRegistry struct {
+	name      string
+	repoURL   string
+	authToken string
+	timeout   time.Duration
+	retry     retryPolicy
+
+	mu      sync.Mutex
+	listing *gitListing
+}
+
+// gitListingTTL is how long the skills of the default branch are cached.
+const gitListingTTL = 5 * time.Minute
+
+// gitSkill is a skill found in the repository.
+type gitSkill struct {
+	dir      string // the top-level directory, used as the slug
+	manifest *SkillManifest
+}
+
+// gitListing is the cached content of the default branch.
+type gitListing struct {
+	skills  []gitSkill
+	version string
+	fetched time.Time
+}
+
+// NewGitRegistry creates a registry reading the repository at cfg.BaseURL.
+func NewGitRegistry(name string, cfg ClawHubConfig) *GitRegistry {
+	timeout := 2 * time.Minute
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	return &GitRegistry{
+		name:      name,
+		repoURL:   cfg.BaseURL,
+		authToken: cfg.AuthToken,
+		timeout:   timeout,
+		retry:     newRetryPolicy(name, cfg),
+	}
+}
+
+func (g *GitRegistry) Name() string {
+	return g.name
+}
+
+// clone shallow-clones ref (the default branch when empty) into a temporary
+// directory, which the caller removes.
+func (g *GitRegistry) clone(ctx context.Context, ref string) (string, error) {
+	dir, err := os.MkdirTemp("", "picoclaw-registry-*")
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", g.repoURL, dir)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if g.authToken != "" {
+		// Passed through the environment rather than -c so the token does
+		// not show up in the process list.
+		cmd.Env = append(cmd.Env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Bearer "+g.authToken,
+		)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("git clone failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return dir, nil
+}
+
+// cloneWithRetry clones like clone, retrying failures: git does not say
+// whether an error was transient, so all of them are retried except a
+// cancelled context.
+func (g *GitRegistry) cloneWithRetry(ctx context.Context, ref string) (string, error) {
+	var dir string
+	p := g.retry
+	p.retryable = func(error) bool { return true }
+	err := p.do(ctx, "clone", func() (err error) {
+		dir, err = g.clone(ctx, ref)
+		return err
+	})
+	return dir, err
+}
+
+// skills lists the skills in a cloned repository, sorted by directory.
+// Directories without a valid SKILL.md are left out.
+func (g *GitRegistry) skills(repoDir string) ([]gitSkill, error) {
+	entries, err := os.ReadDir(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	var found []gitSkill
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		m, err := LoadSkillManifest(filepath.Join(repoDir, e.Name()))
+		if err != nil {
+			continue
+		}
+		found = append(found, gitSkill{dir: e.Name(), manifest: m})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].dir < found[j].dir })
+	return found, nil
+}
+
+// list returns the skills on the default branch, cloning the repository
+// only when the cached listing is older than gitListingTTL.
+func (g *GitRegistry) list(ctx context.Context) (*gitListing, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.listing != nil && time.Since(g.listing.fetched) < gitListingTTL {
+		return g.listing, nil
+	}
+
+	repoDir, err := g.clone(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(repoDir)
+
+	found, err := g.skills(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	version, err := g.commit(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	g.listing = &gitListing{skills: found, version: version, fetched: time.Now()}
+	return g.listing, nil
+}
+
+// commit returns the short hash of the checked-out commit.
+func (g *GitRegistry) commit(ctx context.Context, repoDir string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoDir, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Search matches the query terms against each skill's name and description.
+// The score is the fraction of terms found.
+func (g *GitRegistry) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	listing, err := g.list(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	var results []SearchResult
+	for _, s := range listing.skills {
+		score := matchScore(terms, s.dir+" "+s.manifest.Name+" "+s.manifest.Description)
+		if score == 0 {
+			continue
+		}
+		results = append(results, SearchResult{
+			Score:        score,
+			Slug:         s.dir,
+			DisplayName:  s.manifest.Name,
+			Summary:      s.manifest.Description,
+			Version:      listing.version,
+			RegistryName: g.name,
+		})
+	}
+
+	sortByScoreDesc(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// GetSkillMeta returns the manifest details of slug on the default branch.
+func (g *GitRegistry) GetSkillMeta(ctx context.Context, slug string) (*SkillMeta, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+	listing, err := g.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range listing.skills {
+		if s.dir != slug {
+			continue
+		}
+		return &SkillMeta{
+			Slug:          s.dir,
+			DisplayName:   s.manifest.Name,
+			Summary:       s.manifest.Description,
+			LatestVersion: listing.version,
+			RegistryName:  g.name,
+		}, nil
+	}
+	return nil, fmt.Errorf("skill %q not found in registry %s", slug, g.name)
+}
+
+// DownloadAndInstall clones the repository at version (a branch or tag, or
+// the default branch when empty) and copies the skill's directory to
+// targetDir.
+func (g *GitRegistry) DownloadAndInstall(
+	ctx context.Context,
+	slug, version, targetDir string,
+) (*InstallResult, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+
+	repoDir, err := g.cloneWithRetry(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(repoDir)
+
+	skillDir := filepath.Join(repoDir, slug)
+	m, err := LoadSkillManifest(skillDir)
+	if err != nil {
+		return nil, fmt.Errorf("skill %q not found in registry %s: %w", slug, g.name, err)
+	}
+	commit, err := g.commit(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CopyDirectory(skillDir, targetDir); err != nil {
+		return nil, fmt.Errorf("failed to copy skill: %w", err)
+	}
+	return &InstallResult{Version: commit, Summary: m.Description}, nil
+}
@@ -0,0 +1,95 @@
+package skills
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGitRepo creates a local repository with a skill per entry in skills,
+// keyed by name and holding its description.
+func newGitRepo(t *testing.T, skills map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	for name, desc := range skills {
+		dir := filepath.Join(repo, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		manifest := "---\nname: " + name + "\ndescription: " + desc + "\n---\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(manifest), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "README.md"), []byte("# Skills\n"), 0o644))
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "skills"},
+		{"tag", "v1"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return repo
+}
+
+func TestGitRegistrySearch(t *testing.T) {
+	repo := newGitRepo(t, map[string]string{
+		"weather": "Forecasts for any city",
+		"github":  "Work with GitHub issues",
+	})
+
+	reg := NewGitRegistry("team", ClawHubConfig{BaseURL: repo})
+	results, err := reg.Search(context.Background(), "forecasts", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "weather", results[0].Slug)
+	assert.Equal(t, "team", results[0].RegistryName)
+	assert.NotEmpty(t, results[0].Version)
+
+	meta, err := reg.GetSkillMeta(context.Background(), "github")
+	require.NoError(t, err)
+	assert.Equal(t, "Work with GitHub issues", meta.Summary)
+}
+
+func TestGitRegistrySearch_CachesListing(t *testing.T) {
+	repo := newGitRepo(t, map[string]string{"weather": "Forecasts for any city"})
+
+	reg := NewGitRegistry("team", ClawHubConfig{BaseURL: repo, AuthToken: "secret"})
+	_, err := reg.Search(context.Background(), "forecasts", 10)
+	require.NoError(t, err)
+
+	// The second search must not clone again.
+	require.NoError(t, os.RemoveAll(repo))
+	results, err := reg.Search(context.Background(), "forecasts", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "weather", results[0].Slug)
+
+	_, err = reg.GetSkillMeta(context.Background(), "missing")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestGitRegistryDownloadAndInstall(t *testing.T) {
+	repo := newGitRepo(t, map[string]string{"weather": "Forecasts"})
+	reg := NewGitRegistry("team", ClawHubConfig{BaseURL: repo, MaxRetries: -1})
+
+	targetDir := filepath.Join(t.TempDir(), "weather")
+	result, err := reg.DownloadAndInstall(context.Background(), "weather", "v1", targetDir)
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Version)
+	_, err = os.Stat(filepath.Join(targetDir, "SKILL.md"))
+	assert.NoError(t, err)
+
+	_, err = reg.DownloadAndInstall(context.Background(), "missing", "", t.TempDir())
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = reg.DownloadAndInstall(context.Background(), "weather", "no-such-tag", t.TempDir())
+	assert.ErrorContains(t, err, "git clone failed")
+}
@@ -0,0 +1,285 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+// IndexRegistry implements SkillRegistry over a static index.json, so a
+// skill catalog can be served from plain object storage. The index lists
+// each skill with the URL of its ZIP archive:
+//
+//	{
+//	  "skills": [
+//	    {
+//	      "slug": "weather",
+//	      "display_name": "Weather",
+//	      "summary": "Current conditions and forecasts",
+//	      "version": "1.2.0",
+//	      "url": "weather-1.2.0.zip",
+//	      "sha256": "9f86d08..."
+//	    }
+//	  ]
+//	}
+//
+// A relative url is resolved against the index URL. When sha256 is set, the
+// archive must match it.
+type IndexRegistry struct {
+	name            string
+	indexURL        string
+	authToken       string
+	maxZipSize      int
+	maxResponseSize int
+	retry           retryPolicy
+	client          *http.Client
+}
+
+type skillIndex struct {
+	Skills []indexEntry `json:"skills"`
+}
+
+type indexEntry struct {
+	Slug             string `json:"slug"`
+	DisplayName      string `json:"display_name"`
+	Summary          string `json:"summary"`
+	Version          string `json:"version"`
+	URL              string `json:"url"`
+	SHA256           string `json:"sha256"`
+	IsMalwareBlocked bool   `json:"is_malware_blocked"`
+	IsSuspicious     bool   `json:"is_suspicious"`
+}
+
+// NewIndexRegistry creates a registry reading the index at cfg.BaseURL.
+func NewIndexRegistry(name string, cfg ClawHubConfig) *IndexRegistry {
+	timeout := defaultClawHubTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	maxZip := defaultMaxZipSize
+	if cfg.MaxZipSize > 0 {
+		maxZip = cfg.MaxZipSize
+	}
+	maxResp := defaultMaxResponseSize
+	if cfg.MaxResponseSize > 0 {
+		maxResp = cfg.MaxResponseSize
+	}
+	return &IndexRegistry{
+		name:            name,
+		indexURL:        cfg.BaseURL,
+		authToken:       cfg.AuthToken,
+		maxZipSize:      maxZip,
+		maxResponseSize: maxResp,
+		retry:           newRetryPolicy(name, cfg),
+		client:          &http.Client{Timeout: timeout},
+	}
+}
+
+func (r *IndexRegistry) Name() string {
+	return r.name
+}
+
+// fetch downloads and parses the index.
+func (r *IndexRegistry) fetch(ctx context.Context) (*skillIndex, error) {
+	body, err := getJSON(ctx, r.client, r.indexURL, r.authToken, r.maxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	var idx skillIndex
+	if err := json.Unmarshal(body, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	return &idx, nil
+}
+
+// lookup returns the index entry for slug.
+func (r *IndexRegistry) lookup(ctx context.Context, slug string) (*indexEntry, error) {
+	idx, err := r.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range idx.Skills {
+		if idx.Skills[i].Slug == slug {
+			return &idx.Skills[i], nil
+		}
+	}
+	return nil, fmt.Errorf("skill %q not found in registry %s", slug, r.name)
+}
+
+// Search matches the query terms against each skill's slug, name and
+// summary. The score is the fraction of terms found.
+func (r *IndexRegistry) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	idx, err := r.fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	var results []SearchResult
+	for _, s := range idx.Skills {
+		if s.Slug == "" || s.IsMalwareBlocked {
+			continue
+		}
+		score := matchScore(terms, s.Slug+" "+s.DisplayName+" "+s.Summary)
+		if score == 0 {
+			continue
+		}
+		displayName := s.DisplayName
+		if displayName == "" {
+			displayName = s.Slug
+		}
+		results = append(results, SearchResult{
+			Score:        score,
+			Slug:         s.Slug,
+			DisplayName:  displayName,
+			Summary:      s.Summary,
+			Version:      s.Version,
+			RegistryName: r.name,
+		})
+	}
+
+	sortByScoreDesc(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// matchScore returns the fraction of the lowercase terms found in text, or 1
+// when there are no terms.
+func matchScore(terms []string, text string) float64 {
+	if len(terms) == 0 {
+		return 1
+	}
+	text = strings.ToLower(text)
+	matched := 0
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(terms))
+}
+
+// GetSkillMeta returns the index entry for slug.
+func (r *IndexRegistry) GetSkillMeta(ctx context.Context, slug string) (*SkillMeta, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+	entry, err := r.lookup(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return &SkillMeta{
+		Slug:             entry.Slug,
+		DisplayName:      entry.DisplayName,
+		Summary:          entry.Summary,
+		LatestVersion:    entry.Version,
+		IsMalwareBlocked: entry.IsMalwareBlocked,
+		IsSuspicious:     entry.IsSuspicious,
+		RegistryName:     r.name,
+	}, nil
+}
+
+// DownloadAndInstall downloads the archive listed for slug, checks its
+// checksum and extracts it to targetDir. The index holds one version of
+// each skill, so asking for another one fails.
+func (r *IndexRegistry) DownloadAndInstall(
+	ctx context.Context,
+	slug, version, targetDir string,
+) (*InstallResult, error) {
+	if err := utils.ValidateSkillIdentifier(slug); err != nil {
+		return nil, fmt.Errorf("invalid slug %q: error: %s", slug, err.Error())
+	}
+
+	var entry *indexEntry
+	err := r.retry.do(ctx, "metadata", func() (err error) {
+		entry, err = r.lookup(ctx, slug)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InstallResult{
+		Version:          entry.Version,
+		IsMalwareBlocked: entry.IsMalwareBlocked,
+		IsSuspicious:     entry.IsSuspicious,
+		Summary:          entry.Summary,
+	}
+	if result.IsMalwareBlocked {
+		return result, nil
+	}
+	if version != "" && version != entry.Version {
+		return nil, fmt.Errorf("registry %s only has version %q of %s", r.name, entry.Version, slug)
+	}
+	if entry.URL == "" {
+		return nil, fmt.Errorf("index entry for %s has no url", slug)
+	}
+
+	base, err := url.Parse(r.indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index URL: %w", err)
+	}
+	archive, err := base.Parse(entry.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url for %s: %w", slug, err)
+	}
+
+	var tmpPath string
+	err = r.retry.do(ctx, "download", func() error {
+		req, err := http.NewRequestWithContext(ctx, "GET", archive.String(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		// Archives may live on another host, such as a CDN; only send the
+		// token to the index's own host.
+		if r.authToken != "" && archive.Host == base.Host {
+			req.Header.Set("Authorization", "Bearer "+r.authToken)
+		}
+		tmpPath, err = utils.DownloadToFileWithProgress(ctx, r.client, req, int64(r.maxZipSize), downloadProgress(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	if entry.SHA256 != "" {
+		if err := verifySHA256(tmpPath, entry.SHA256); err != nil {
+			return nil, fmt.Errorf("%s: %w", slug, err)
+		}
+	}
+
+	if err := utils.ExtractZipFile(tmpPath, targetDir); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// verifySHA256 checks that the file at path has the hex digest want.
+func verifySHA256(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch: got sha256 %s, want %s", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package skills
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIndexServer(t *testing.T, index string, archives map[string][]byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/catalog/index.json" {
+			w.Write([]byte(index))
+			return
+		}
+		if data, ok := archives[r.URL.Path]; ok {
+			w.Write(data)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIndexRegistrySearch(t *testing.T) {
+	srv := newIndexServer(t, `{"skills": [
+		{"slug": "weather", "display_name": "Weather", "summary": "Forecasts for any city", "version": "1.0.0"},
+		{"slug": "github", "summary": "Work with GitHub issues", "version": "0.3.0"},
+		{"slug": "evil", "summary": "weather stealer", "is_malware_blocked": true}
+	]}`, nil)
+
+	reg := NewIndexRegistry("internal", ClawHubConfig{BaseURL: srv.URL + "/catalog/index.json"})
+	results, err := reg.Search(context.Background(), "weather city", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "weather", results[0].Slug)
+	assert.Equal(t, 1.0, results[0].Score)
+	assert.Equal(t, "internal", results[0].RegistryName)
+
+	results, err = reg.Search(context.Background(), "", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "github", results[1].DisplayName)
+}
+
+func TestIndexRegistryDownloadAndInstall(t *testing.T) {
+	zipBuf := createTestZip(t, map[string]string{"SKILL.md": "---\nname: weather\ndescription: Forecasts\n---\n"})
+	sum := sha256.Sum256(zipBuf)
+	srv := newIndexServer(t, `{"skills": [
+		{"slug": "weather", "version": "1.0.0", "url": "archives/weather.zip", "sha256": "`+hex.EncodeToString(sum[:])+`"},
+		{"slug": "broken", "version": "1.0.0", "url": "/catalog/archives/weather.zip", "sha256": "00"}
+	]}`, map[string][]byte{"/catalog/archives/weather.zip": zipBuf})
+
+	reg := NewIndexRegistry("internal", ClawHubConfig{BaseURL: srv.URL + "/catalog/index.json"})
+	targetDir := filepath.Join(t.TempDir(), "weather")
+	result, err := reg.DownloadAndInstall(context.Background(), "weather", "", targetDir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", result.Version)
+	_, err = os.Stat(filepath.Join(targetDir, "SKILL.md"))
+	assert.NoError(t, err)
+
+	_, err = reg.DownloadAndInstall(context.Background(), "weather", "2.0.0", t.TempDir())
+	assert.ErrorContains(t, err, `only has version "1.0.0"`)
+
+	_, err = reg.DownloadAndInstall(context.Background(), "broken", "", filepath.Join(t.TempDir(), "broken"))
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	_, err = reg.DownloadAndInstall(context.Background(), "missing", "", t.TempDir())
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestIndexRegistryAuthToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"skills": []}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewIndexRegistry("internal", ClawHubConfig{BaseURL: srv.URL}).Search(context.Background(), "x", 5)
+	assert.Error(t, err)
+	_, err = NewIndexRegistry("internal", ClawHubConfig{BaseURL: srv.URL, AuthToken: "secret"}).
+		Search(context.Background(), "x", 5)
+	assert.NoError(t, err)
+}
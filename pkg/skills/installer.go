@@ -23,6 +23,11 @@ func NewSkillInstaller(workspace string) *SkillInstaller {
 	}
 }
 
+// SkillDir returns the directory the skill name is installed in.
+func (si *SkillInstaller) SkillDir(name string) string {
+	return filepath.Join(si.workspace, "skills", name)
+}
+
 func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) error {
 	skillDir := filepath.Join(si.workspace, "skills", filepath.Base(repo))
 
@@ -64,19 +69,115 @@ func (si *SkillInstaller) InstallFromGitHub(ctx context.Context, repo string) er
 		return fmt.Errorf("failed to write skill file: %w", err)
 	}
 
-	return nil
+	return si.recordLock(filepath.Base(repo), LockEntry{Source: "github", Path: repo})
+}
+
+// InstallLocal copies a skill directory from the local filesystem into the
+// workspace skills folder.
+func (si *SkillInstaller) InstallLocal(srcPath string) error {
+	src, name, err := si.prepareLocal(srcPath)
+	if err != nil {
+		return err
+	}
+	skillDir := filepath.Join(si.workspace, "skills", name)
+
+	if err := CopyDirectory(src, skillDir); err != nil {
+		os.RemoveAll(skillDir)
+		return fmt.Errorf("failed to copy skill: %w", err)
+	}
+
+	return si.recordLock(name, LockEntry{Source: "local", Path: src})
+}
+
+// LinkLocal symlinks a local skill directory into the workspace skills
+// folder so edits to the source take effect without reinstalling.
+func (si *SkillInstaller) LinkLocal(srcPath string) error {
+	src, name, err := si.prepareLocal(srcPath)
+	if err != nil {
+		return err
+	}
+	skillDir := filepath.Join(si.workspace, "skills", name)
+
+	if err := os.Symlink(src, skillDir); err != nil {
+		return fmt.Errorf("failed to link skill: %w", err)
+	}
+
+	return si.recordLock(name, LockEntry{Source: "local", Path: src, Linked: true})
+}
+
+// prepareLocal resolves srcPath, checks it contains a SKILL.md and that no
+// skill with the same name is already installed. It returns the absolute
+// source path and the skill's directory name.
+func (si *SkillInstaller) prepareLocal(srcPath string) (string, string, error) {
+	src, err := filepath.Abs(srcPath)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid path %q: %w", srcPath, err)
+	}
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("%s is not a directory", srcPath)
+	}
+	if _, err := os.Stat(filepath.Join(src, "SKILL.md")); err != nil {
+		return "", "", fmt.Errorf("SKILL.md not found in %s", srcPath)
+	}
+
+	name := filepath.Base(src)
+	skillsDir := filepath.Join(si.workspace, "skills")
+	if _, err := os.Lstat(filepath.Join(skillsDir, name)); err == nil {
+		return "", "", fmt.Errorf("skill '%s' already exists", name)
+	}
+	if err := os.MkdirAll(skillsDir, 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	return src, name, nil
 }
 
 func (si *SkillInstaller) Uninstall(skillName string) error {
 	skillDir := filepath.Join(si.workspace, "skills", skillName)
 
-	if _, err := os.Stat(skillDir); os.IsNotExist(err) {
+	// Lstat so a linked skill whose source has moved can still be removed.
+	if _, err := os.Lstat(skillDir); os.IsNotExist(err) {
 		return fmt.Errorf("skill '%s' not found", skillName)
 	}
 
+	// RemoveAll deletes only the link itself for linked skills.
 	if err := os.RemoveAll(skillDir); err != nil {
 		return fmt.Errorf("failed to remove skill: %w", err)
 	}
 
-	return nil
+	return si.removeLock(skillName)
+}
+
+// CopyDirectory recursively copies src into dst, preserving file modes.
+func CopyDirectory(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		dstPath := filepath.Join(dst, relPath)
+
+		if info.IsDir() {
+			return os.MkdirAll(dstPath, info.Mode())
+		}
+
+		srcFile, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer srcFile.Close()
+
+		dstFile, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+		if err != nil {
+			return err
+		}
+		defer dstFile.Close()
+
+		_, err = io.Copy(dstFile, srcFile)
+		return err
+	})
 }
@@ -0,0 +1,75 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallLocalCopiesAndLocks(t *testing.T) {
+	src := t.TempDir()
+	createSkillDir(t, src, "my-skill", "my-skill", "local test skill")
+	require.NoError(t, os.WriteFile(filepath.Join(src, "my-skill", "notes.txt"), []byte("hi"), 0o644))
+
+	workspace := t.TempDir()
+	si := NewSkillInstaller(workspace)
+	require.NoError(t, si.InstallLocal(filepath.Join(src, "my-skill")))
+
+	installed := filepath.Join(workspace, "skills", "my-skill")
+	info, err := os.Lstat(installed)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	assert.FileExists(t, filepath.Join(installed, "notes.txt"))
+
+	lf, err := LoadLockFile(workspace)
+	require.NoError(t, err)
+	entry, ok := lf.Skills["my-skill"]
+	require.True(t, ok)
+	assert.Equal(t, "local", entry.Source)
+	assert.False(t, entry.Linked)
+
+	err = si.InstallLocal(filepath.Join(src, "my-skill"))
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestInstallLocalRequiresSkillMD(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "no-manifest")
+	require.NoError(t, os.MkdirAll(src, 0o755))
+
+	si := NewSkillInstaller(t.TempDir())
+	err := si.InstallLocal(src)
+	assert.ErrorContains(t, err, "SKILL.md not found")
+}
+
+func TestLinkLocalAndUninstall(t *testing.T) {
+	src := t.TempDir()
+	createSkillDir(t, src, "linked-skill", "linked-skill", "linked test skill")
+
+	workspace := t.TempDir()
+	si := NewSkillInstaller(workspace)
+	require.NoError(t, si.LinkLocal(filepath.Join(src, "linked-skill")))
+
+	link := filepath.Join(workspace, "skills", "linked-skill")
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink)
+
+	loader := NewSkillsLoader(workspace, "", "")
+	skills := loader.ListSkills()
+	require.Len(t, skills, 1)
+	assert.Equal(t, "linked-skill", skills[0].Name)
+
+	lf, err := LoadLockFile(workspace)
+	require.NoError(t, err)
+	assert.True(t, lf.Skills["linked-skill"].Linked)
+
+	require.NoError(t, si.Uninstall("linked-skill"))
+	assert.FileExists(t, filepath.Join(src, "linked-skill", "SKILL.md"))
+
+	lf, err = LoadLockFile(workspace)
+	require.NoError(t, err)
+	assert.NotContains(t, lf.Skills, "linked-skill")
+}
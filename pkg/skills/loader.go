@@ -8,7 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
@@ -59,9 +61,13 @@ func (info SkillInfo) validate() error {
 
 type SkillsLoader struct {
 	workspace       string
-	workspaceSkills string // workspace skills (project-level)
-	globalSkills    string // global skills (~/.picoclaw/skills)
-	builtinSkills   string // builtin skills
+	workspaceSkills string          // workspace skills (project-level)
+	globalSkills    string          // global skills (~/.picoclaw/skills)
+	builtinSkills   string          // builtin skills
+	allowed         map[string]bool // nil lists every skill
+
+	toolsMu    sync.Mutex
+	toolSkills map[string]*SkillManifest // by tool name, built on first lookup
 }
 
 func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string) *SkillsLoader {
@@ -73,6 +79,21 @@ func NewSkillsLoader(workspace string, globalSkills string, builtinSkills string
 	}
 }
 
+// SetAllowed limits ListSkills to the named skills; an empty list allows all.
+func (sl *SkillsLoader) SetAllowed(names []string) {
+	sl.toolsMu.Lock()
+	sl.toolSkills = nil
+	sl.toolsMu.Unlock()
+	if len(names) == 0 {
+		sl.allowed = nil
+		return
+	}
+	sl.allowed = make(map[string]bool, len(names))
+	for _, name := range names {
+		sl.allowed[strings.TrimSpace(name)] = true
+	}
+}
+
 func (sl *SkillsLoader) ListSkills() []SkillInfo {
 	skills := make([]SkillInfo, 0)
 	seen := make(map[string]bool)
@@ -86,7 +107,8 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 			return
 		}
 		for _, d := range dirs {
-			if !d.IsDir() {
+			// Symlinked skill directories come from `picoclaw skills link`.
+			if !d.IsDir() && d.Type()&os.ModeSymlink == 0 {
 				continue
 			}
 			skillFile := filepath.Join(dir, d.Name(), "SKILL.md")
@@ -107,7 +129,7 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 				slog.Warn("invalid skill from "+source, "name", info.Name, "error", err)
 				continue
 			}
-			if seen[info.Name] {
+			if seen[info.Name] || (sl.allowed != nil && !sl.allowed[info.Name]) {
 				continue
 			}
 			seen[info.Name] = true
@@ -123,6 +145,41 @@ func (sl *SkillsLoader) ListSkills() []SkillInfo {
 	return skills
 }
 
+// GetSkillByToolName returns the manifest of the skill whose tools list
+// contains toolName, e.g. "weather.get_forecast", or that declares the
+// command run by that tool, e.g. "skill_weather_forecast". When several
+// skills declare the same tool, the one ListSkills returns first wins. The
+// reverse map is built on the first call; skills installed later are not
+// seen.
+func (sl *SkillsLoader) GetSkillByToolName(toolName string) (*SkillManifest, bool) {
+	sl.toolsMu.Lock()
+	defer sl.toolsMu.Unlock()
+	if sl.toolSkills == nil {
+		sl.toolSkills = make(map[string]*SkillManifest)
+		for _, info := range sl.ListSkills() {
+			content, err := os.ReadFile(info.Path)
+			if err != nil {
+				continue
+			}
+			m, err := ParseSkillManifest(string(content))
+			if err != nil {
+				continue
+			}
+			tools := slices.Clone(m.Tools)
+			for name := range m.Commands {
+				tools = append(tools, CommandToolName(m.Name, name))
+			}
+			for _, tool := range tools {
+				if _, taken := sl.toolSkills[tool]; !taken {
+					sl.toolSkills[tool] = m
+				}
+			}
+		}
+	}
+	m, ok := sl.toolSkills[toolName]
+	return m, ok
+}
+
 func (sl *SkillsLoader) LoadSkill(name string) (string, bool) {
 	// 1. load from workspace skills first (project-level)
 	if sl.workspaceSkills != "" {
@@ -222,7 +279,14 @@ func (sl *SkillsLoader) getSkillMetadata(skillPath string) *SkillMetadata {
 		}
 	}
 
-	// Fall back to simple YAML parsing
+	if m, err := ParseSkillManifest(string(content)); err == nil {
+		return &SkillMetadata{
+			Name:        m.Name,
+			Description: m.Description,
+		}
+	}
+
+	// Fall back to simple YAML parsing for frontmatter that isn't strict YAML
 	yamlMeta := sl.parseSimpleYAML(frontmatter)
 	return &SkillMetadata{
 		Name:        yamlMeta["name"],
@@ -220,6 +220,40 @@ func TestListSkillsMultipleDistinctSkills(t *testing.T) {
 	assert.Equal(t, "builtin", names["skill-c"])
 }
 
+func TestGetSkillByToolName(t *testing.T) {
+	tmp := t.TempDir()
+	ws := filepath.Join(tmp, "workspace")
+	global := filepath.Join(tmp, "global")
+
+	writeSkill := func(base, name, tools string) {
+		dir := filepath.Join(base, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		content := "---\nname: " + name + "\ndescription: " + name + " skill\ntools:\n" + tools + "---\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644))
+	}
+	writeSkill(filepath.Join(ws, "skills"), "weather", "  - weather.get_forecast\n  - weather.alerts\n")
+	writeSkill(global, "weather-old", "  - weather.get_forecast\n")
+	writeSkill(global, "calendar", "  - calendar.today\n")
+
+	sl := NewSkillsLoader(ws, global, "")
+
+	m, ok := sl.GetSkillByToolName("weather.get_forecast")
+	require.True(t, ok)
+	assert.Equal(t, "weather", m.Name, "workspace skill should win")
+
+	m, ok = sl.GetSkillByToolName("calendar.today")
+	require.True(t, ok)
+	assert.Equal(t, "calendar", m.Name)
+
+	_, ok = sl.GetSkillByToolName("unknown.tool")
+	assert.False(t, ok)
+
+	// The reverse map is cached, so skills added later are not seen.
+	writeSkill(global, "notes", "  - notes.add\n")
+	_, ok = sl.GetSkillByToolName("notes.add")
+	assert.False(t, ok)
+}
+
 func TestListSkillsInvalidSkillSkipped(t *testing.T) {
 	tmp := t.TempDir()
 	ws := filepath.Join(tmp, "workspace")
@@ -0,0 +1,91 @@
+package skills
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// LockFileName is the lockfile kept in the workspace skills directory that
+// records where each installed skill came from.
+const LockFileName = "skills-lock.json"
+
+// LockEntry records the origin of one installed skill.
+type LockEntry struct {
+	Source      string `json:"source"`           // "github", "local", ...
+	Path        string `json:"path,omitempty"`   // source path or repo
+	Linked      bool   `json:"linked,omitempty"` // true if the skill is a symlink to Path
+	InstalledAt int64  `json:"installed_at"`
+}
+
+// LockFile maps skill names to their lock entries.
+type LockFile struct {
+	Version int                  `json:"version"`
+	Skills  map[string]LockEntry `json:"skills"`
+}
+
+func lockFilePath(workspace string) string {
+	return filepath.Join(workspace, "skills", LockFileName)
+}
+
+// LoadLockFile reads the workspace lockfile, returning an empty one if it
+// does not exist yet.
+func LoadLockFile(workspace string) (*LockFile, error) {
+	lf := &LockFile{Version: 1, Skills: make(map[string]LockEntry)}
+
+	data, err := os.ReadFile(lockFilePath(workspace))
+	if os.IsNotExist(err) {
+		return lf, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skills lockfile: %w", err)
+	}
+	if err := json.Unmarshal(data, lf); err != nil {
+		return nil, fmt.Errorf("failed to parse skills lockfile: %w", err)
+	}
+	if lf.Skills == nil {
+		lf.Skills = make(map[string]LockEntry)
+	}
+	return lf, nil
+}
+
+// Save writes the lockfile back to the workspace.
+func (lf *LockFile) Save(workspace string) error {
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(lockFilePath(workspace), data, 0o644)
+}
+
+func (si *SkillInstaller) recordLock(name string, entry LockEntry) error {
+	lf, err := LoadLockFile(si.workspace)
+	if err != nil {
+		return err
+	}
+	entry.InstalledAt = time.Now().Unix()
+	lf.Skills[name] = entry
+	if err := lf.Save(si.workspace); err != nil {
+		return fmt.Errorf("failed to write skills lockfile: %w", err)
+	}
+	return nil
+}
+
+func (si *SkillInstaller) removeLock(name string) error {
+	lf, err := LoadLockFile(si.workspace)
+	if err != nil {
+		return err
+	}
+	if _, ok := lf.Skills[name]; !ok {
+		return nil
+	}
+	delete(lf.Skills, name)
+	if err := lf.Save(si.workspace); err != nil {
+		return fmt.Errorf("failed to write skills lockfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package skills
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// semverPattern matches MAJOR.MINOR.PATCH with optional pre-release and build
+// metadata, per semver.org. A leading "v" is tolerated.
+var semverPattern = regexp.MustCompile(
+	`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`,
+)
+
+// SkillManifest is the YAML frontmatter of a SKILL.md file.
+type SkillManifest struct {
+	Name               string   `yaml:"name"                 json:"name"`
+	Description        string   `yaml:"description"          json:"description"`
+	Version            string   `yaml:"version"              json:"version,omitempty"`
+	Author             string   `yaml:"author"               json:"author,omitempty"`
+	Tags               []string `yaml:"tags"                 json:"tags,omitempty"`
+	Category           string   `yaml:"category"             json:"category,omitempty"`
+	Dependencies       []string `yaml:"dependencies"         json:"dependencies,omitempty"`
+	Tools              []string `yaml:"tools"                json:"tools,omitempty"`
+	MinPicoClawVersion string   `yaml:"min_picoclaw_version" json:"min_picoclaw_version,omitempty"`
+
+	Env      []SkillEnvVar           `yaml:"env"      json:"env,omitempty"`
+	Commands map[string]SkillCommand `yaml:"commands" json:"commands,omitempty"`
+}
+
+// ParseSkillManifest parses the YAML frontmatter block at the top of a
+// SKILL.md file. The name and description are validated, and the version
+// must be valid semver when present.
+func ParseSkillManifest(content string) (*SkillManifest, error) {
+	match := reFrontmatter.FindStringSubmatch(content)
+	if len(match) < 2 {
+		return nil, errors.New("missing frontmatter block")
+	}
+
+	var m SkillManifest
+	if err := yaml.Unmarshal([]byte(match[1]), &m); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	info := SkillInfo{Name: m.Name, Description: m.Description}
+	if err := info.validate(); err != nil {
+		return nil, err
+	}
+	if m.Version != "" && !semverPattern.MatchString(m.Version) {
+		return nil, fmt.Errorf("version %q is not valid semver", m.Version)
+	}
+	if m.MinPicoClawVersion != "" && !semverPattern.MatchString(m.MinPicoClawVersion) {
+		return nil, fmt.Errorf("min_picoclaw_version %q is not valid semver", m.MinPicoClawVersion)
+	}
+	if err := validateEnv(m.Env); err != nil {
+		return nil, err
+	}
+	if err := validateCommands(m.Commands); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// LoadSkillManifest reads and parses the SKILL.md in skillDir, checking that
+// the manifest name matches the directory name.
+func LoadSkillManifest(skillDir string) (*SkillManifest, error) {
+	content, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SKILL.md: %w", err)
+	}
+
+	m, err := ParseSkillManifest(string(content))
+	if err != nil {
+		return nil, err
+	}
+	if dirName := filepath.Base(skillDir); m.Name != dirName {
+		return nil, fmt.Errorf("skill name %q does not match directory name %q", m.Name, dirName)
+	}
+	return m, nil
+}
@@ -0,0 +1,112 @@
+package skills
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSkillManifest(t *testing.T) {
+	content := `---
+name: weather
+description: "Get weather: current and forecast"
+version: 1.2.0
+author: sipeed
+tags: [weather, api]
+category: utilities
+dependencies:
+  - curl
+tools:
+  - web_fetch
+min_picoclaw_version: 0.2.0
+env:
+  - OPENWEATHER_API_KEY
+  - name: WEATHER_UNITS
+    description: metric or imperial
+    optional: true
+---
+
+# Weather
+`
+	m, err := ParseSkillManifest(content)
+	require.NoError(t, err)
+
+	assert.Equal(t, "weather", m.Name)
+	assert.Equal(t, "Get weather: current and forecast", m.Description)
+	assert.Equal(t, "1.2.0", m.Version)
+	assert.Equal(t, "sipeed", m.Author)
+	assert.Equal(t, []string{"weather", "api"}, m.Tags)
+	assert.Equal(t, "utilities", m.Category)
+	assert.Equal(t, []string{"curl"}, m.Dependencies)
+	assert.Equal(t, []string{"web_fetch"}, m.Tools)
+	assert.Equal(t, "0.2.0", m.MinPicoClawVersion)
+	assert.Equal(t, []SkillEnvVar{
+		{Name: "OPENWEATHER_API_KEY"},
+		{Name: "WEATHER_UNITS", Description: "metric or imperial", Optional: true},
+	}, m.Env)
+}
+
+func TestParseSkillManifestErrors(t *testing.T) {
+	testcases := []struct {
+		name        string
+		content     string
+		errContains string
+	}{
+		{
+			name:        "no-frontmatter",
+			content:     "# Just markdown",
+			errContains: "missing frontmatter",
+		},
+		{
+			name:        "bad-yaml",
+			content:     "---\nname: [unclosed\n---\n",
+			errContains: "invalid frontmatter",
+		},
+		{
+			name:        "missing-description",
+			content:     "---\nname: weather\n---\n",
+			errContains: "description is required",
+		},
+		{
+			name:        "bad-version",
+			content:     "---\nname: weather\ndescription: d\nversion: 1.2\n---\n",
+			errContains: "not valid semver",
+		},
+		{
+			name:        "bad-env-name",
+			content:     "---\nname: weather\ndescription: d\nenv: [API-KEY]\n---\n",
+			errContains: "not a valid variable name",
+		},
+		{
+			name:        "duplicate-env",
+			content:     "---\nname: weather\ndescription: d\nenv: [KEY, KEY]\n---\n",
+			errContains: "declared twice",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseSkillManifest(tc.content)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
+
+func TestLoadSkillManifestNameMustMatchDir(t *testing.T) {
+	base := t.TempDir()
+	createSkillDir(t, base, "weather", "weather", "weather skill")
+	createSkillDir(t, base, "renamed", "weather", "weather skill")
+
+	m, err := LoadSkillManifest(filepath.Join(base, "weather"))
+	require.NoError(t, err)
+	assert.Equal(t, "weather", m.Name)
+
+	_, err = LoadSkillManifest(filepath.Join(base, "renamed"))
+	assert.ErrorContains(t, err, "does not match directory name")
+
+	_, err = LoadSkillManifest(filepath.Join(base, "missing"))
+	assert.ErrorContains(t, err, "failed to read SKILL.md")
+}
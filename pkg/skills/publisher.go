@@ -0,0 +1,257 @@
+package skills
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+const (
+	// skillTestScript is the optional script a skill ships to self-test before publishing.
+	skillTestScript    = "scripts/test.sh"
+	defaultTestTimeout = 2 * time.Minute
+
+	signingKeyPEMType = "PICOCLAW SKILL SIGNING KEY"
+)
+
+// SkillUploader is implemented by registries that accept skill uploads.
+type SkillUploader interface {
+	Name() string
+	Publish(ctx context.Context, pkg *SkillPackage) (*PublishResult, error)
+}
+
+// SkillPackage is a packaged, signed skill ready to be uploaded.
+type SkillPackage struct {
+	Slug        string
+	Version     string
+	Description string
+	Tarball     []byte
+	Checksum    string // hex-encoded SHA-256 of Tarball
+	Signature   []byte // Ed25519 signature over Tarball
+	PublicKey   ed25519.PublicKey
+}
+
+// PublishResult is returned by a registry after a successful upload.
+type PublishResult struct {
+	Slug    string `json:"slug"`
+	Version string `json:"version"`
+	URL     string `json:"url,omitempty"`
+}
+
+// SkillPublisher packages a local skill directory, signs it and pushes it
+// to a registry.
+type SkillPublisher struct {
+	uploader    SkillUploader
+	signingKey  ed25519.PrivateKey
+	testTimeout time.Duration
+}
+
+// NewSkillPublisher creates a publisher that signs with key and uploads via uploader.
+func NewSkillPublisher(uploader SkillUploader, key ed25519.PrivateKey) *SkillPublisher {
+	return &SkillPublisher{
+		uploader:    uploader,
+		signingKey:  key,
+		testTimeout: defaultTestTimeout,
+	}
+}
+
+// Publish validates, tests, packages and uploads the skill in dir.
+func (p *SkillPublisher) Publish(ctx context.Context, dir, version string) (*PublishResult, error) {
+	pkg, err := p.Package(ctx, dir, version)
+	if err != nil {
+		return nil, err
+	}
+	return p.uploader.Publish(ctx, pkg)
+}
+
+// Package validates the SKILL.md frontmatter, runs the skill's test script
+// if present, and builds a signed tarball of dir.
+func (p *SkillPublisher) Package(ctx context.Context, dir, version string) (*SkillPackage, error) {
+	if version == "" {
+		return nil, errors.New("version is required")
+	}
+	if len(p.signingKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid signing key")
+	}
+
+	meta, err := validateSkillDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.runTests(ctx, dir); err != nil {
+		return nil, err
+	}
+
+	tarball, err := buildSkillTarball(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to package skill: %w", err)
+	}
+
+	sum := sha256.Sum256(tarball)
+	return &SkillPackage{
+		Slug:        meta.Name,
+		Version:     version,
+		Description: meta.Description,
+		Tarball:     tarball,
+		Checksum:    hex.EncodeToString(sum[:]),
+		Signature:   ed25519.Sign(p.signingKey, tarball),
+		PublicKey:   p.signingKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+// validateSkillDir checks that dir contains a SKILL.md with valid frontmatter.
+func validateSkillDir(dir string) (*SkillMetadata, error) {
+	skillFile := filepath.Join(dir, "SKILL.md")
+	if _, err := os.Stat(skillFile); err != nil {
+		return nil, fmt.Errorf("SKILL.md not found in %s", dir)
+	}
+
+	sl := &SkillsLoader{}
+	meta := sl.getSkillMetadata(skillFile)
+	if meta == nil {
+		return nil, fmt.Errorf("failed to read %s", skillFile)
+	}
+
+	info := SkillInfo{Name: meta.Name, Description: meta.Description}
+	if err := info.validate(); err != nil {
+		return nil, fmt.Errorf("invalid SKILL.md frontmatter: %w", err)
+	}
+	return meta, nil
+}
+
+// runTests executes scripts/test.sh from the skill directory when it exists.
+func (p *SkillPublisher) runTests(ctx context.Context, dir string) error {
+	script := filepath.Join(dir, skillTestScript)
+	if _, err := os.Stat(script); err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.testTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", skillTestScript)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("skill tests failed: %w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// buildSkillTarball writes every regular file under dir into a gzipped tar,
+// skipping hidden files and directories such as .git.
+func buildSkillTarball(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() && !d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LoadOrCreateSigningKey reads a PEM-encoded Ed25519 private key from path,
+// generating and saving a new one if the file does not exist. created
+// reports a new key, which the caller should tell the user about: the
+// registry knows the author by its public key.
+func LoadOrCreateSigningKey(path string) (key ed25519.PrivateKey, created bool, err error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != signingKeyPEMType || len(block.Bytes) != ed25519.SeedSize {
+			return nil, false, fmt.Errorf("invalid signing key in %s", path)
+		}
+		return ed25519.NewKeyFromSeed(block.Bytes), false, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, false, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	_, key, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, false, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	encoded := pem.EncodeToMemory(&pem.Block{Type: signingKeyPEMType, Bytes: key.Seed()})
+	if err := fileutil.WriteFileAtomic(path, encoded, 0o600); err != nil {
+		return nil, false, fmt.Errorf("failed to save signing key: %w", err)
+	}
+	return key, true, nil
+}
@@ -0,0 +1,140 @@
+package skills
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestSkill(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestSkillPublisherPackage(t *testing.T) {
+	dir := writeTestSkill(t, map[string]string{
+		"SKILL.md":        "---\nname: weather\ndescription: Weather lookups\n---\n# Weather",
+		"scripts/run.sh":  "echo hi",
+		".git/HEAD":       "ref: refs/heads/main",
+		".hidden-secrets": "nope",
+	})
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	p := NewSkillPublisher(nil, key)
+	pkg, err := p.Package(context.Background(), dir, "v1.0.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, "weather", pkg.Slug)
+	assert.Equal(t, "v1.0.0", pkg.Version)
+	assert.True(t, ed25519.Verify(pub, pkg.Tarball, pkg.Signature))
+	assert.Len(t, pkg.Checksum, 64)
+
+	gz, err := gzip.NewReader(bytes.NewReader(pkg.Tarball))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	assert.ElementsMatch(t, []string{"SKILL.md", "scripts/", "scripts/run.sh"}, names)
+}
+
+func TestSkillPublisherPackageInvalidFrontmatter(t *testing.T) {
+	dir := writeTestSkill(t, map[string]string{
+		"SKILL.md": "---\nname: Bad Name!\n---\n",
+	})
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+
+	_, err := NewSkillPublisher(nil, key).Package(context.Background(), dir, "v1.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid SKILL.md frontmatter")
+}
+
+func TestSkillPublisherPackageFailingTests(t *testing.T) {
+	dir := writeTestSkill(t, map[string]string{
+		"SKILL.md":        "---\nname: weather\ndescription: Weather lookups\n---\n",
+		"scripts/test.sh": "echo broken; exit 1",
+	})
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+
+	_, err := NewSkillPublisher(nil, key).Package(context.Background(), dir, "v1.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "skill tests failed")
+	assert.Contains(t, err.Error(), "broken")
+}
+
+func TestClawHubRegistryPublish(t *testing.T) {
+	dir := writeTestSkill(t, map[string]string{
+		"SKILL.md": "---\nname: weather\ndescription: Weather lookups\n---\n",
+	})
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/publish", r.URL.Path)
+		assert.Equal(t, "Bearer hub-token", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "weather", r.FormValue("slug"))
+		assert.Equal(t, "v2.0.0", r.FormValue("version"))
+
+		f, _, err := r.FormFile("file")
+		require.NoError(t, err)
+		tarball, _ := io.ReadAll(f)
+		sig, _ := base64.StdEncoding.DecodeString(r.FormValue("signature"))
+		assert.True(t, ed25519.Verify(pub, tarball, sig))
+
+		json.NewEncoder(w).Encode(PublishResult{
+			Slug: "weather", Version: "v2.0.0", URL: "https://clawhub.ai/skills/weather",
+		})
+	}))
+	defer srv.Close()
+
+	reg := newTestRegistry(srv.URL, "hub-token")
+	result, err := NewSkillPublisher(reg, key).Publish(context.Background(), dir, "v2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "https://clawhub.ai/skills/weather", result.URL)
+}
+
+func TestClawHubRegistryPublishRequiresAuth(t *testing.T) {
+	reg := newTestRegistry("https://example.com", "")
+	_, err := reg.Publish(context.Background(), &SkillPackage{Slug: "weather"})
+	assert.Error(t, err)
+}
+
+func TestLoadOrCreateSigningKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "skill_signing.pem")
+
+	key1, created, err := LoadOrCreateSigningKey(path)
+	require.NoError(t, err)
+	assert.True(t, created)
+	key2, created, err := LoadOrCreateSigningKey(path)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.True(t, key1.Equal(key2))
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"sync"
 	"time"
+
+	"github.com/sipeed/picoclaw/pkg/config"
 )
 
 const (
@@ -61,9 +65,38 @@ type SkillRegistry interface {
 // This is the input to NewRegistryManagerFromConfig.
 type RegistryConfig struct {
 	ClawHub               ClawHubConfig
+	Sources               []RegistrySource
 	MaxConcurrentSearches int
 }
 
+// RegistryConfigFrom returns the skill registries configured in cfg, the
+// named ones sorted by name after ClawHub.
+func RegistryConfigFrom(cfg *config.Config) RegistryConfig {
+	regs := cfg.Tools.Skills.Registries
+	rc := RegistryConfig{
+		MaxConcurrentSearches: cfg.Tools.Skills.MaxConcurrentSearches,
+		ClawHub:               ClawHubConfig(regs.ClawHub),
+	}
+	for _, name := range slices.Sorted(maps.Keys(regs.Custom)) {
+		reg := regs.Custom[name]
+		rc.Sources = append(rc.Sources, RegistrySource{
+			Name:   name,
+			Type:   reg.Type,
+			Config: ClawHubConfig(reg.ClawHubRegistryConfig),
+		})
+	}
+	return rc
+}
+
+// RegistrySource is a named registry besides the public ClawHub. For the
+// index type Config.BaseURL is the URL of index.json, and for git the
+// repository URL.
+type RegistrySource struct {
+	Name   string
+	Type   string // one of config.SkillRegistry*, default clawhub
+	Config ClawHubConfig
+}
+
 // ClawHubConfig configures the ClawHub registry.
 type ClawHubConfig struct {
 	Enabled         bool
@@ -72,9 +105,12 @@ type ClawHubConfig struct {
 	SearchPath      string // e.g. "/api/v1/search"
 	SkillsPath      string // e.g. "/api/v1/skills"
 	DownloadPath    string // e.g. "/api/v1/download"
+	UploadPath      string // e.g. "/api/v1/publish"
 	Timeout         int    // seconds, 0 = default (30s)
 	MaxZipSize      int    // bytes, 0 = default (50MB)
 	MaxResponseSize int    // bytes, 0 = default (2MB)
+	MaxRetries      int    // retries of install requests, 0 = default (2), negative = none
+	RetryBackoff    int    // seconds before the first retry, 0 = default (1s)
 }
 
 // RegistryManager coordinates multiple skill registries.
@@ -103,9 +139,39 @@ func NewRegistryManagerFromConfig(cfg RegistryConfig) *RegistryManager {
 	if cfg.ClawHub.Enabled {
 		rm.AddRegistry(NewClawHubRegistry(cfg.ClawHub))
 	}
+	for _, src := range cfg.Sources {
+		if !src.Config.Enabled {
+			continue
+		}
+		if src.Config.BaseURL == "" {
+			slog.Warn("skill registry has no base_url, skipping", "registry", src.Name)
+			continue
+		}
+		switch src.Type {
+		case "", config.SkillRegistryClawHub:
+			rm.AddRegistry(newNamedClawHubRegistry(src.Name, src.Config))
+		case config.SkillRegistryIndex:
+			rm.AddRegistry(NewIndexRegistry(src.Name, src.Config))
+		case config.SkillRegistryGit:
+			rm.AddRegistry(NewGitRegistry(src.Name, src.Config))
+		default:
+			slog.Warn("unknown skill registry type, skipping", "registry", src.Name, "type", src.Type)
+		}
+	}
 	return rm
 }
 
+// Names returns the names of the registries, in the order they were added.
+func (rm *RegistryManager) Names() []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	names := make([]string, 0, len(rm.registries))
+	for _, r := range rm.registries {
+		names = append(names, r.Name())
+	}
+	return names
+}
+
 // AddRegistry adds a registry to the manager.
 func (rm *RegistryManager) AddRegistry(r SkillRegistry) {
 	rm.mu.Lock()
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/sipeed/picoclaw/pkg/config"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -178,3 +179,22 @@ func TestIsSafeSlug(t *testing.T) {
 	assert.Error(t, utils.ValidateSkillIdentifier("path/traversal"))
 	assert.Error(t, utils.ValidateSkillIdentifier("path\\traversal"))
 }
+
+func TestNewRegistryManagerFromConfigSources(t *testing.T) {
+	mgr := NewRegistryManagerFromConfig(RegistryConfig{
+		ClawHub: ClawHubConfig{Enabled: true},
+		Sources: []RegistrySource{
+			{Name: "mirror", Config: ClawHubConfig{Enabled: true, BaseURL: "https://hub.example.com"}},
+			{Name: "catalog", Type: config.SkillRegistryIndex, Config: ClawHubConfig{Enabled: true, BaseURL: "https://example.com/index.json"}},
+			{Name: "team", Type: config.SkillRegistryGit, Config: ClawHubConfig{Enabled: true, BaseURL: "https://example.com/skills.git"}},
+			{Name: "off", Type: config.SkillRegistryIndex, Config: ClawHubConfig{BaseURL: "https://example.com/index.json"}},
+			{Name: "nourl", Type: config.SkillRegistryIndex, Config: ClawHubConfig{Enabled: true}},
+		},
+	})
+
+	assert.Equal(t, []string{"clawhub", "mirror", "catalog", "team"}, mgr.Names())
+	assert.IsType(t, &ClawHubRegistry{}, mgr.GetRegistry("mirror"))
+	assert.IsType(t, &IndexRegistry{}, mgr.GetRegistry("catalog"))
+	assert.IsType(t, &GitRegistry{}, mgr.GetRegistry("team"))
+	assert.Nil(t, mgr.GetRegistry("off"))
+}
@@ -0,0 +1,99 @@
+package skills
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	defaultRegistryRetries      = 2
+	defaultRegistryRetryBackoff = time.Second
+)
+
+// retryPolicy retries a registry's requests after transient failures.
+type retryPolicy struct {
+	registry string
+	retries  int
+	backoff  time.Duration // before the first retry, doubled after each
+	// retryable decides which errors are worth retrying.
+	retryable func(error) bool
+}
+
+// newRetryPolicy reads the retry settings of a registry's config.
+func newRetryPolicy(registry string, cfg ClawHubConfig) retryPolicy {
+	p := retryPolicy{
+		registry:  registry,
+		retries:   defaultRegistryRetries,
+		backoff:   defaultRegistryRetryBackoff,
+		retryable: retryable,
+	}
+	if cfg.MaxRetries > 0 {
+		p.retries = cfg.MaxRetries
+	} else if cfg.MaxRetries < 0 {
+		p.retries = 0
+	}
+	if cfg.RetryBackoff > 0 {
+		p.backoff = time.Duration(cfg.RetryBackoff) * time.Second
+	}
+	return p
+}
+
+// do runs fn until it succeeds, fails in a way retrying can't fix, or the
+// retries are used up.
+func (p retryPolicy) do(ctx context.Context, step string, fn func() error) error {
+	backoff := p.backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > p.retries || !p.retryable(err) || ctx.Err() != nil {
+			return err
+		}
+		logger.WarnCF("skills", "Registry request failed, retrying",
+			map[string]any{
+				"registry": p.registry,
+				"step":     step,
+				"attempt":  attempt,
+				"backoff":  backoff.String(),
+				"error":    err.Error(),
+			})
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether err may go away on its own: a network error or
+// timeout, a connection cut mid-body, or a 5xx or 429 response.
+func retryable(err error) bool {
+	var statusErr *utils.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+type downloadProgressKey struct{}
+
+// WithDownloadProgress returns a context under which DownloadAndInstall
+// reports how much of the skill archive has arrived to fn.
+func WithDownloadProgress(ctx context.Context, fn utils.DownloadProgress) context.Context {
+	return context.WithValue(ctx, downloadProgressKey{}, fn)
+}
+
+func downloadProgress(ctx context.Context) utils.DownloadProgress {
+	fn, _ := ctx.Value(downloadProgressKey{}).(utils.DownloadProgress)
+	return fn
+}
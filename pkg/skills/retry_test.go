@@ -0,0 +1,115 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClawHubRegistryDownloadRetries(t *testing.T) {
+	zipBuf := createTestZip(t, map[string]string{"SKILL.md": "---\nname: flaky\ndescription: A test\n---\n"})
+
+	var metaCalls, downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/skills/flaky":
+			if metaCalls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			json.NewEncoder(w).Encode(clawhubSkillResponse{
+				Slug:          "flaky",
+				LatestVersion: &clawhubVersionInfo{Version: "2.0.0"},
+			})
+		case "/api/v1/download":
+			if downloads.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write(zipBuf)
+		}
+	}))
+	defer srv.Close()
+
+	reg := newTestRegistry(srv.URL, "")
+	reg.retry.backoff = time.Millisecond
+
+	var reported int64
+	ctx := WithDownloadProgress(context.Background(), func(written, total int64) { reported = written })
+	result, err := reg.DownloadAndInstall(ctx, "flaky", "", filepath.Join(t.TempDir(), "flaky"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", result.Version)
+	assert.Equal(t, int32(2), metaCalls.Load())
+	assert.Equal(t, int32(3), downloads.Load())
+	assert.Equal(t, int64(len(zipBuf)), reported)
+}
+
+func TestClawHubRegistryDownloadGivesUp(t *testing.T) {
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/download" {
+			downloads.Add(1)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	reg := NewClawHubRegistry(ClawHubConfig{BaseURL: srv.URL, MaxRetries: 1})
+	reg.retry.backoff = time.Millisecond
+
+	_, err := reg.DownloadAndInstall(context.Background(), "down", "1.0.0", filepath.Join(t.TempDir(), "down"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+	assert.Equal(t, int32(2), downloads.Load())
+}
+
+func TestClawHubRegistryDownloadNoRetryOnClientError(t *testing.T) {
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/download" {
+			downloads.Add(1)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	reg := newTestRegistry(srv.URL, "")
+	reg.retry.backoff = time.Millisecond
+
+	_, err := reg.DownloadAndInstall(context.Background(), "missing", "1.0.0", filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+	assert.Equal(t, int32(1), downloads.Load())
+}
+
+func TestClawHubRegistryMalwareBlockedIsNotDownloaded(t *testing.T) {
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/skills/evil":
+			json.NewEncoder(w).Encode(clawhubSkillResponse{
+				Slug:       "evil",
+				Moderation: &clawhubModerationInfo{IsMalwareBlocked: true},
+			})
+		case "/api/v1/download":
+			downloads.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	reg := newTestRegistry(srv.URL, "")
+	result, err := reg.DownloadAndInstall(context.Background(), "evil", "", filepath.Join(t.TempDir(), "evil"))
+
+	require.NoError(t, err)
+	assert.True(t, result.IsMalwareBlocked)
+	assert.Zero(t, downloads.Load())
+}
@@ -0,0 +1,277 @@
+package skills
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const scaffoldVersion = "0.1.0"
+
+// Languages of the entry script generated by ScaffoldOptions.Lang.
+const (
+	ScaffoldPython = "python"
+	ScaffoldShell  = "shell"
+	ScaffoldGo     = "go"
+)
+
+// scaffoldEntries maps a scaffold language to its entry script and the command
+// that runs it from the skill directory.
+var scaffoldEntries = map[string]struct{ file, run string }{
+	ScaffoldPython: {"skill.py", "python3 skill.py"},
+	ScaffoldShell:  {"skill.sh", "sh skill.sh"},
+	ScaffoldGo:     {"skill.go", "go run skill.go"},
+}
+
+// skillTestFile lists test cases for a scaffolded skill's entry script.
+const skillTestFile = "SKILL_TEST.json"
+
+// ScaffoldOptions controls what ScaffoldSkill generates.
+type ScaffoldOptions struct {
+	Description string
+	Author      string
+	Tags        []string
+	WithTool    bool // also generate an executable tool stub under scripts/
+
+	// Lang, when set, generates an entry script in that language
+	// (ScaffoldPython, ScaffoldShell or ScaffoldGo) next to SKILL.md, an
+	// empty SKILL_TEST.json, and fills every frontmatter field with a
+	// placeholder. It replaces WithTool.
+	Lang string
+	// MinPicoClawVersion is the running build's version, written as
+	// min_picoclaw_version with Lang. Builds without a release version,
+	// such as "dev", leave the field out.
+	MinPicoClawVersion string
+}
+
+// ScaffoldSkill creates skillsDir/<name> with a SKILL.md containing valid
+// frontmatter, a scripts/test.sh run by `skills publish`, and optionally an
+// executable tool stub. It returns the path of the new skill directory.
+func ScaffoldSkill(skillsDir, name string, opts ScaffoldOptions) (string, error) {
+	if opts.Description == "" {
+		opts.Description = fmt.Sprintf("Describe what %s does and when the agent should use it.", name)
+	}
+	info := SkillInfo{Name: name, Description: opts.Description}
+	if err := info.validate(); err != nil {
+		return "", fmt.Errorf("invalid skill: %w", err)
+	}
+	if opts.Lang != "" {
+		if _, ok := scaffoldEntries[opts.Lang]; !ok {
+			return "", fmt.Errorf("unknown skill language %q: use python, shell or go", opts.Lang)
+		}
+		opts.WithTool = false
+	}
+
+	dir := filepath.Join(skillsDir, name)
+	if _, err := os.Lstat(dir); err == nil {
+		return "", fmt.Errorf("skill '%s' already exists at %s", name, dir)
+	}
+
+	files := map[string]string{
+		"SKILL.md":            renderSkillMD(name, opts),
+		"scripts/test.sh":     renderTestScript(name, opts.WithTool),
+		"references/.gitkeep": "",
+	}
+	if opts.WithTool {
+		files[filepath.Join("scripts", name+".sh")] = renderToolStub(name)
+	}
+	if entry, ok := scaffoldEntries[opts.Lang]; ok {
+		files[entry.file] = renderEntryStub(name, opts.Lang)
+		files[skillTestFile] = "[]\n"
+	}
+
+	if err := writeScaffold(dir, files); err != nil {
+		// Leave nothing half-written behind to block a retry.
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// writeScaffold writes files, keyed by their path relative to dir, and
+// checks the resulting SKILL.md.
+func writeScaffold(dir string, files map[string]string) error {
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		perm := os.FileMode(0o644)
+		if strings.HasSuffix(rel, ".sh") || strings.HasSuffix(rel, ".py") {
+			perm = 0o755
+		}
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			return fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+	}
+
+	if _, err := LoadSkillManifest(dir); err != nil {
+		return fmt.Errorf("generated SKILL.md is invalid: %w", err)
+	}
+	return nil
+}
+
+// releaseVersion returns the major.minor.patch part of a build version such
+// as "v0.2.1-3-gabc123", or "" when it has none.
+func releaseVersion(v string) string {
+	m := releaseVersionPattern.FindStringSubmatch(v)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+var releaseVersionPattern = regexp.MustCompile(`^v?(\d+\.\d+\.\d+)`)
+
+// yamlQuote returns s as a double-quoted YAML scalar, so user input such as
+// "a: b" or "#tag" keeps its meaning in the frontmatter.
+func yamlQuote(s string) string {
+	out, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: s})
+	if err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+func renderSkillMD(name string, opts ScaffoldOptions) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "name: %s\n", name)
+	fmt.Fprintf(&sb, "description: %s\n", yamlQuote(opts.Description))
+	fmt.Fprintf(&sb, "version: %s\n", scaffoldVersion)
+	entry, typed := scaffoldEntries[opts.Lang]
+	if opts.Author != "" {
+		fmt.Fprintf(&sb, "author: %s\n", yamlQuote(opts.Author))
+	} else if typed {
+		sb.WriteString("author: \"Your Name <you@example.com>\"\n")
+	}
+	if typed {
+		sb.WriteString("category: general\n")
+		sb.WriteString("dependencies: []\n")
+		if v := releaseVersion(opts.MinPicoClawVersion); v != "" {
+			fmt.Fprintf(&sb, "min_picoclaw_version: %s\n", v)
+		}
+	}
+	if len(opts.Tags) > 0 {
+		sb.WriteString("tags:\n")
+		for _, tag := range opts.Tags {
+			fmt.Fprintf(&sb, "  - %s\n", yamlQuote(tag))
+		}
+	} else {
+		sb.WriteString("tags: []\n")
+	}
+	if opts.WithTool || typed {
+		fmt.Fprintf(&sb, "tools:\n  - %s\n", name)
+	} else {
+		sb.WriteString("# Declare executables shipped in scripts/ that the agent may run:\n")
+		sb.WriteString("# tools:\n")
+		fmt.Fprintf(&sb, "#   - %s\n", name)
+	}
+	sb.WriteString("---\n\n")
+
+	title := strings.ReplaceAll(name, "-", " ")
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	sb.WriteString("Explain when to use this skill and the steps the agent should follow.\n")
+	sb.WriteString("Keep this file short; move long reference material to `references/`.\n\n")
+	sb.WriteString("## Usage\n\n")
+	if typed {
+		fmt.Fprintf(&sb, "Run the entry script with the exec tool:\n\n```bash\ncd {baseDir} && %s <input>\n```\n\n", entry.run)
+		sb.WriteString("It prints its result as a single line of JSON on stdout.\n")
+	} else if opts.WithTool {
+		fmt.Fprintf(&sb, "Run the bundled tool with the exec tool:\n\n```bash\nsh {baseDir}/scripts/%s.sh <input>\n```\n\n", name)
+		sb.WriteString("It prints its result as a single line of JSON on stdout.\n")
+	} else {
+		sb.WriteString("<!--\nExample tool usage, if you add scripts/" + name + ".sh:\n\n")
+		fmt.Fprintf(&sb, "```bash\nsh {baseDir}/scripts/%s.sh <input>\n```\n-->\n", name)
+	}
+	return sb.String()
+}
+
+func renderToolStub(name string) string {
+	return fmt.Sprintf(`#!/bin/sh
+# %s tool stub. Reads its input from the first argument and prints a
+# JSON result on stdout; exit non-zero on failure.
+set -eu
+
+if [ $# -lt 1 ]; then
+	echo "usage: %s.sh <input>" >&2
+	exit 2
+fi
+
+input="$1"
+printf '{"input": "%%s", "result": "TODO"}\n' "$input"
+`, name, name)
+}
+
+// renderEntryStub returns the entry script of a skill in language lang. Like the tool
+// stub, it takes its input as the first argument and prints JSON.
+func renderEntryStub(name, lang string) string {
+	switch lang {
+	case ScaffoldPython:
+		return fmt.Sprintf(`#!/usr/bin/env python3
+"""%s skill entry point. Prints a JSON result on stdout; exits non-zero on failure."""
+import json
+import sys
+
+
+def main(argv):
+    if len(argv) < 2:
+        print("usage: skill.py <input>", file=sys.stderr)
+        return 2
+    print(json.dumps({"input": argv[1], "result": "TODO"}))
+    return 0
+
+
+if __name__ == "__main__":
+    sys.exit(main(sys.argv))
+`, name)
+	case ScaffoldGo:
+		return fmt.Sprintf(`// %s skill entry point. Run with "go run skill.go <input>"; prints a JSON
+// result on stdout and exits non-zero on failure.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run skill.go <input>")
+		os.Exit(2)
+	}
+	_ = json.NewEncoder(os.Stdout).Encode(map[string]string{"input": os.Args[1], "result": "TODO"})
+}
+`, name)
+	default:
+		return strings.Replace(renderToolStub(name), name+".sh <input>", "skill.sh <input>", 1)
+	}
+}
+
+func renderTestScript(name string, withTool bool) string {
+	if withTool {
+		return fmt.Sprintf(`#!/bin/sh
+# Run by 'picoclaw skills publish' before packaging. Exit non-zero to abort.
+set -eu
+
+out="$(sh scripts/%s.sh example)"
+case "$out" in
+	*'"result"'*) echo "ok" ;;
+	*) echo "unexpected output: $out" >&2; exit 1 ;;
+esac
+`, name)
+	}
+	return `#!/bin/sh
+# Run by 'picoclaw skills publish' before packaging. Exit non-zero to abort.
+set -eu
+
+test -s SKILL.md
+echo "ok"
+`
+}
@@ -0,0 +1,122 @@
+package skills
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffoldSkillWritesValidManifest(t *testing.T) {
+	workspace := t.TempDir()
+	skillsDir := filepath.Join(workspace, "skills")
+
+	dir, err := ScaffoldSkill(skillsDir, "my-skill", ScaffoldOptions{
+		Description: "Does: a thing",
+		Author:      `Ann "ops" O'Neil`,
+		Tags:        []string{"demo", "#test", "a: b"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(skillsDir, "my-skill"), dir)
+
+	m, err := LoadSkillManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "my-skill", m.Name)
+	assert.Equal(t, "Does: a thing", m.Description)
+	assert.Equal(t, scaffoldVersion, m.Version)
+	assert.Equal(t, `Ann "ops" O'Neil`, m.Author)
+	assert.Equal(t, []string{"demo", "#test", "a: b"}, m.Tags)
+	assert.Empty(t, m.Tools)
+
+	assert.FileExists(t, filepath.Join(dir, "scripts", "test.sh"))
+	assert.NoFileExists(t, filepath.Join(dir, "scripts", "my-skill.sh"))
+
+	// The loader must pick the skill up as-is.
+	sl := NewSkillsLoader(workspace, "", "")
+	skills := sl.ListSkills()
+	require.Len(t, skills, 1)
+	assert.Equal(t, "my-skill", skills[0].Name)
+}
+
+func TestScaffoldSkillWithTool(t *testing.T) {
+	dir, err := ScaffoldSkill(t.TempDir(), "lookup", ScaffoldOptions{WithTool: true})
+	require.NoError(t, err)
+
+	m, err := LoadSkillManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lookup"}, m.Tools)
+
+	stub := filepath.Join(dir, "scripts", "lookup.sh")
+	info, err := os.Stat(stub)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o111, "tool stub should be executable")
+
+	if _, err := exec.LookPath("sh"); err == nil {
+		cmd := exec.Command("sh", "scripts/test.sh")
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestScaffoldSkillRejectsInvalidOrExisting(t *testing.T) {
+	skillsDir := t.TempDir()
+
+	_, err := ScaffoldSkill(skillsDir, "bad name", ScaffoldOptions{})
+	assert.Error(t, err)
+
+	_, err = ScaffoldSkill(skillsDir, "dup", ScaffoldOptions{})
+	require.NoError(t, err)
+	_, err = ScaffoldSkill(skillsDir, "dup", ScaffoldOptions{})
+	assert.Error(t, err)
+}
+
+func TestScaffoldSkillWithLang(t *testing.T) {
+	for lang, entry := range map[string]string{
+		ScaffoldPython: "skill.py",
+		ScaffoldShell:  "skill.sh",
+		ScaffoldGo:     "skill.go",
+	} {
+		t.Run(lang, func(t *testing.T) {
+			dir, err := ScaffoldSkill(t.TempDir(), "lookup", ScaffoldOptions{
+				Lang:               lang,
+				MinPicoClawVersion: "v0.2.1-3-gabc123-dirty",
+			})
+			require.NoError(t, err)
+
+			m, err := LoadSkillManifest(dir)
+			require.NoError(t, err)
+			assert.NotEmpty(t, m.Author)
+			assert.NotEmpty(t, m.Category)
+			assert.Equal(t, "0.2.1", m.MinPicoClawVersion)
+			assert.Equal(t, []string{"lookup"}, m.Tools)
+
+			assert.FileExists(t, filepath.Join(dir, entry))
+			data, err := os.ReadFile(filepath.Join(dir, skillTestFile))
+			require.NoError(t, err)
+			assert.JSONEq(t, "[]", string(data))
+		})
+	}
+
+	if _, err := exec.LookPath("sh"); err == nil {
+		dir, err := ScaffoldSkill(t.TempDir(), "echo", ScaffoldOptions{Lang: ScaffoldShell})
+		require.NoError(t, err)
+		cmd := exec.Command("sh", "skill.sh", "hello")
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		assert.Contains(t, string(out), `"input": "hello"`)
+	}
+
+	dir, err := ScaffoldSkill(t.TempDir(), "devbuild", ScaffoldOptions{Lang: ScaffoldGo, MinPicoClawVersion: "dev"})
+	require.NoError(t, err)
+	m, err := LoadSkillManifest(dir)
+	require.NoError(t, err)
+	assert.Empty(t, m.MinPicoClawVersion)
+
+	_, err = ScaffoldSkill(t.TempDir(), "rusty", ScaffoldOptions{Lang: "rust"})
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"sync"
@@ -21,10 +22,46 @@ type State struct {
 	// LastChatID is the last chat ID used for communication
 	LastChatID string `json:"last_chat_id,omitempty"`
 
+	// LastSessionKey is the session of the owner's most recent message
+	LastSessionKey string `json:"last_session_key,omitempty"`
+
+	// ChatModels maps "channel:chat_id" to a model_list name chosen with /model
+	ChatModels map[string]string `json:"chat_models,omitempty"`
+
+	// ChatTiers maps "channel:chat_id" to an auto-route tier pinned with /route
+	ChatTiers map[string]string `json:"chat_tiers,omitempty"`
+
+	// SenderUsage maps "channel:sender_id" to the sender's usage today, so
+	// daily rate-limit budgets survive a gateway restart
+	SenderUsage map[string]SenderUsage `json:"sender_usage,omitempty"`
+
+	// BudgetUsage maps a budgeted channel or "channel:chat_id" to its
+	// spending in the current budget period
+	BudgetUsage map[string]BudgetUsage `json:"budget_usage,omitempty"`
+
+	// Maintenance is set while maintenance mode is switched on by hand
+	Maintenance bool `json:"maintenance,omitempty"`
+
 	// Timestamp is the last time this state was updated
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// SenderUsage is how much one sender used the agent on one day.
+type SenderUsage struct {
+	Day      string `json:"day"` // local date, YYYY-MM-DD
+	Messages int    `json:"messages"`
+	Tokens   int    `json:"tokens"`
+	Warned   bool   `json:"warned,omitempty"` // told the daily budget ran out
+}
+
+// BudgetUsage is how much a channel or chat spent in one budget period.
+type BudgetUsage struct {
+	Period   string  `json:"period"` // YYYY-MM-DD or YYYY-MM
+	Tokens   int     `json:"tokens"`
+	Cost     float64 `json:"cost_usd"`
+	Notified bool    `json:"notified,omitempty"` // owner told the limit was hit
+}
+
 // Manager manages persistent state with atomic saves.
 type Manager struct {
 	workspace string
@@ -108,6 +145,31 @@ func (sm *Manager) SetLastChatID(chatID string) error {
 	return nil
 }
 
+// SetLastSessionKey updates the owner's most recent session and saves the state.
+func (sm *Manager) SetLastSessionKey(key string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.state.LastSessionKey == key {
+		return nil
+	}
+	sm.state.LastSessionKey = key
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
+// GetLastSessionKey returns the owner's most recent session.
+func (sm *Manager) GetLastSessionKey() string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.state.LastSessionKey
+}
+
 // GetLastChannel returns the last channel from the state.
 func (sm *Manager) GetLastChannel() string {
 	sm.mu.RLock()
@@ -122,6 +184,157 @@ func (sm *Manager) GetLastChatID() string {
 	return sm.state.LastChatID
 }
 
+// SetChatModel sets the model used for a chat ("channel:chat_id").
+// An empty model clears the override.
+func (sm *Manager) SetChatModel(chatKey, model string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if model == "" {
+		delete(sm.state.ChatModels, chatKey)
+	} else {
+		if sm.state.ChatModels == nil {
+			sm.state.ChatModels = make(map[string]string)
+		}
+		sm.state.ChatModels[chatKey] = model
+	}
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatModel returns the model override for a chat, or "" if none is set.
+func (sm *Manager) GetChatModel(chatKey string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.state.ChatModels[chatKey]
+}
+
+// ChatModels returns a copy of all per-chat model overrides, keyed by
+// "channel:chat_id".
+func (sm *Manager) ChatModels() map[string]string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return maps.Clone(sm.state.ChatModels)
+}
+
+// SetChatTier pins the auto-route tier of a chat ("channel:chat_id").
+// An empty tier goes back to routing automatically.
+func (sm *Manager) SetChatTier(chatKey, tier string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if tier == "" {
+		delete(sm.state.ChatTiers, chatKey)
+	} else {
+		if sm.state.ChatTiers == nil {
+			sm.state.ChatTiers = make(map[string]string)
+		}
+		sm.state.ChatTiers[chatKey] = tier
+	}
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatTier returns the tier pinned for a chat, or "" if none is.
+func (sm *Manager) GetChatTier(chatKey string) string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.state.ChatTiers[chatKey]
+}
+
+// SetSenderUsage records a sender's usage ("channel:sender_id"). Entries
+// from other days are dropped so the state file doesn't grow unbounded.
+func (sm *Manager) SetSenderUsage(senderKey string, usage SenderUsage) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.state.SenderUsage == nil {
+		sm.state.SenderUsage = make(map[string]SenderUsage)
+	}
+	for key, u := range sm.state.SenderUsage {
+		if u.Day != usage.Day {
+			delete(sm.state.SenderUsage, key)
+		}
+	}
+	sm.state.SenderUsage[senderKey] = usage
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
+// SenderUsages returns a copy of the recorded per-sender usage, keyed by
+// "channel:sender_id".
+func (sm *Manager) SenderUsages() map[string]SenderUsage {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return maps.Clone(sm.state.SenderUsage)
+}
+
+// SetBudgetUsage records the spending of a budgeted channel or chat.
+func (sm *Manager) SetBudgetUsage(key string, usage BudgetUsage) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.state.BudgetUsage == nil {
+		sm.state.BudgetUsage = make(map[string]BudgetUsage)
+	}
+	sm.state.BudgetUsage[key] = usage
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
+// BudgetUsages returns a copy of the recorded budget spending, keyed by
+// channel or "channel:chat_id".
+func (sm *Manager) BudgetUsages() map[string]BudgetUsage {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return maps.Clone(sm.state.BudgetUsage)
+}
+
+// SetMaintenance switches maintenance mode on or off and saves the state.
+func (sm *Manager) SetMaintenance(on bool) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.state.Maintenance == on {
+		return nil
+	}
+	sm.state.Maintenance = on
+	sm.state.Timestamp = time.Now()
+
+	if err := sm.saveAtomic(); err != nil {
+		return fmt.Errorf("failed to save state atomically: %w", err)
+	}
+
+	return nil
+}
+
+// GetMaintenance reports whether maintenance mode is switched on.
+func (sm *Manager) GetMaintenance() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.state.Maintenance
+}
+
 // GetTimestamp returns the timestamp of the last state update.
 func (sm *Manager) GetTimestamp() time.Time {
 	sm.mu.RLock()
@@ -83,6 +83,23 @@ func TestSetLastChatID(t *testing.T) {
 	}
 }
 
+func TestSetLastSessionKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewManager(tmpDir)
+
+	if got := sm.GetLastSessionKey(); got != "" {
+		t.Errorf("Expected no session key, got %q", got)
+	}
+	if err := sm.SetLastSessionKey("agent:main:main"); err != nil {
+		t.Fatalf("SetLastSessionKey failed: %v", err)
+	}
+
+	sm2 := NewManager(tmpDir)
+	if got := sm2.GetLastSessionKey(); got != "agent:main:main" {
+		t.Errorf("Expected persistent session key 'agent:main:main', got %q", got)
+	}
+}
+
 func TestAtomicity_NoCorruptionOnInterrupt(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "state-test-*")
 	if err != nil {
@@ -252,3 +269,24 @@ func TestNewManager_MkdirFailureCrashes(t *testing.T) {
 
 	t.Fatalf("The process ended without error, a crash was expected via os.Exit(1). Err: %v", err)
 }
+
+func TestSenderUsage(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sm := NewManager(tmpDir)
+	if err := sm.SetSenderUsage("telegram:1", SenderUsage{Day: "2026-01-01", Messages: 3, Tokens: 100}); err != nil {
+		t.Fatalf("SetSenderUsage failed: %v", err)
+	}
+	if err := sm.SetSenderUsage("telegram:2", SenderUsage{Day: "2026-01-02", Messages: 1, Tokens: 10}); err != nil {
+		t.Fatalf("SetSenderUsage failed: %v", err)
+	}
+
+	// Usage from an earlier day is dropped and the rest survives a restart.
+	usages := NewManager(tmpDir).SenderUsages()
+	if len(usages) != 1 {
+		t.Fatalf("Expected 1 sender, got %v", usages)
+	}
+	if got := usages["telegram:2"]; got.Messages != 1 || got.Tokens != 10 {
+		t.Errorf("Unexpected usage for telegram:2: %+v", got)
+	}
+}
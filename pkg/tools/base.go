@@ -17,6 +17,38 @@ type ContextualTool interface {
 	SetContext(channel, chatID string)
 }
 
+type toolContextKey struct{}
+
+type toolContext struct{ channel, chatID string }
+
+// WithToolContext returns a copy of ctx that carries the conversation a tool
+// call belongs to. Turns of different chats run concurrently on the same tool
+// instances, so contextual tools read the conversation from here rather than
+// from what SetContext last stored.
+func WithToolContext(ctx context.Context, channel, chatID string) context.Context {
+	return context.WithValue(ctx, toolContextKey{}, toolContext{channel: channel, chatID: chatID})
+}
+
+// ToolContextFrom returns the conversation stored by WithToolContext.
+func ToolContextFrom(ctx context.Context) (channel, chatID string, ok bool) {
+	tc, ok := ctx.Value(toolContextKey{}).(toolContext)
+	return tc.channel, tc.chatID, ok
+}
+
+type userTurnKey struct{}
+
+// WithUserTurn marks ctx as a turn started by a message from the user, as
+// opposed to a heartbeat, cron or system turn.
+func WithUserTurn(ctx context.Context) context.Context {
+	return context.WithValue(ctx, userTurnKey{}, true)
+}
+
+// IsUserTurn reports whether ctx was marked by WithUserTurn.
+func IsUserTurn(ctx context.Context) bool {
+	v, _ := ctx.Value(userTurnKey{}).(bool)
+	return v
+}
+
 // AsyncCallback is a function type that async tools use to notify completion.
 // When an async tool finishes its work, it calls this callback with the result.
 //
@@ -9,7 +9,9 @@ import (
 
 	"github.com/sipeed/picoclaw/pkg/bus"
 	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/constants"
 	"github.com/sipeed/picoclaw/pkg/cron"
+	"github.com/sipeed/picoclaw/pkg/logger"
 	"github.com/sipeed/picoclaw/pkg/utils"
 )
 
@@ -18,15 +20,42 @@ type JobExecutor interface {
 	ProcessDirectWithChannel(ctx context.Context, content, sessionKey, channel, chatID string) (string, error)
 }
 
+// ContactJobExecutor is implemented by executors that can run a job as a turn
+// from a specific chat and deliver the reply there.
+type ContactJobExecutor interface {
+	ProcessAsContact(ctx context.Context, content, channel, chatID string) (string, error)
+}
+
+// RecentContextProvider is implemented by executors that can add the owner's
+// recent conversation to a job's message.
+type RecentContextProvider interface {
+	WithRecentContext(ctx context.Context, prompt string, turns, maxTokens int) string
+}
+
+// DigestSink receives job output that should be batched into a digest
+// instead of being delivered immediately.
+type DigestSink func(source, content string) error
+
+// urgentMarker lets a digest job's output escalate itself to immediate delivery.
+const urgentMarker = "[urgent]"
+
+// agentJobPrefix starts the name of every job the agent schedules, so users
+// can tell them apart from their own in `picoclaw cron list`.
+const agentJobPrefix = "agent: "
+
 // CronTool provides scheduling capabilities for the agent
 type CronTool struct {
 	cronService *cron.CronService
 	executor    JobExecutor
 	msgBus      *bus.MessageBus
 	execTool    *ExecTool
+	digestSink  DigestSink
+	recentCtx   config.RecentContextConfig
+	maxJobs     int // cap on agent-created jobs, 0 for none
 	channel     string
 	chatID      string
 	mu          sync.RWMutex
+	addMu       sync.Mutex // keeps the job count and the add together
 }
 
 // NewCronTool creates a new CronTool
@@ -41,12 +70,17 @@ func NewCronTool(
 	}
 
 	execTool.SetTimeout(execTimeout)
-	return &CronTool{
+	t := &CronTool{
 		cronService: cronService,
 		executor:    executor,
 		msgBus:      msgBus,
 		execTool:    execTool,
-	}, nil
+	}
+	if config != nil {
+		t.recentCtx = config.Tools.Cron.RecentContext
+		t.maxJobs = config.Tools.Cron.MaxAgentJobs
+	}
+	return t, nil
 }
 
 // Name returns the tool name
@@ -97,11 +131,27 @@ func (t *CronTool) Parameters() map[string]any {
 				"type":        "boolean",
 				"description": "If true, send message directly to channel. If false, let agent process message (for complex tasks). Default: true",
 			},
+			"digest": map[string]any{
+				"type":        "boolean",
+				"description": "If true, collect the job's output into the periodic digest instead of sending it right away. Output starting with '[urgent]' is still sent immediately. Default: false",
+			},
+			"urgent": map[string]any{
+				"type":        "boolean",
+				"description": "If true, always send the job's output immediately, bypassing the digest. Default: false",
+			},
 		},
 		"required": []string{"action"},
 	}
 }
 
+// SetDigestSink enables digest delivery for jobs created with digest=true.
+// Without a sink such jobs are delivered immediately.
+func (t *CronTool) SetDigestSink(sink DigestSink) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.digestSink = sink
+}
+
 // SetContext sets the current session context for job creation
 func (t *CronTool) SetContext(channel, chatID string) {
 	t.mu.Lock()
@@ -119,7 +169,7 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 
 	switch action {
 	case "add":
-		return t.addJob(args)
+		return t.addJob(ctx, args)
 	case "list":
 		return t.listJobs()
 	case "remove":
@@ -133,15 +183,22 @@ func (t *CronTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	}
 }
 
-func (t *CronTool) addJob(args map[string]any) *ToolResult {
+func (t *CronTool) addJob(ctx context.Context, args map[string]any) *ToolResult {
 	t.mu.RLock()
 	channel := t.channel
 	chatID := t.chatID
 	t.mu.RUnlock()
+	if c, id, ok := ToolContextFrom(ctx); ok {
+		channel, chatID = c, id
+	}
 
 	if channel == "" || chatID == "" {
 		return ErrorResult("no session context (channel/chat_id not set). Use this tool in an active conversation.")
 	}
+	if constants.IsInternalChannel(channel) {
+		return ErrorResult(fmt.Sprintf("jobs need a chat to deliver to, and %q is not one. "+
+			"Ask the user to schedule it with 'picoclaw cron add' instead.", channel))
+	}
 
 	message, ok := args["message"].(string)
 	if !ok || message == "" {
@@ -192,11 +249,20 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 		deliver = false
 	}
 
+	t.addMu.Lock()
+	defer t.addMu.Unlock()
+	if t.maxJobs > 0 {
+		if n := t.agentJobCount(); n >= t.maxJobs {
+			return ErrorResult(fmt.Sprintf("already %d scheduled jobs created by the agent (limit %d). "+
+				"Remove one with action 'remove' first.", n, t.maxJobs))
+		}
+	}
+
 	// Truncate message for job name (max 30 chars)
 	messagePreview := utils.Truncate(message, 30)
 
 	job, err := t.cronService.AddJob(
-		messagePreview,
+		agentJobPrefix+messagePreview,
 		schedule,
 		message,
 		deliver,
@@ -207,15 +273,29 @@ func (t *CronTool) addJob(args map[string]any) *ToolResult {
 		return ErrorResult(fmt.Sprintf("Error adding job: %v", err))
 	}
 
-	if command != "" {
-		job.Payload.Command = command
-		// Need to save the updated payload
-		t.cronService.UpdateJob(job)
-	}
+	digest, _ := args["digest"].(bool)
+	urgent, _ := args["urgent"].(bool)
+	job.Payload.Command = command
+	job.Payload.Digest = digest
+	job.Payload.Urgent = urgent
+	job.CreatedBy = cron.CreatedByAgent
+	// Need to save the updated payload
+	t.cronService.UpdateJob(job)
 
 	return SilentResult(fmt.Sprintf("Cron job added: %s (id: %s)", job.Name, job.ID))
 }
 
+// agentJobCount returns how many jobs the agent has scheduled, enabled or not.
+func (t *CronTool) agentJobCount() int {
+	n := 0
+	for _, j := range t.cronService.ListJobs(true) {
+		if j.ByAgent() {
+			n++
+		}
+	}
+	return n
+}
+
 func (t *CronTool) listJobs() *ToolResult {
 	jobs := t.cronService.ListJobs(false)
 
@@ -224,6 +304,9 @@ func (t *CronTool) listJobs() *ToolResult {
 	}
 
 	var result strings.Builder
+	if t.cronService.IsPaused() {
+		result.WriteString("All jobs are currently paused (maintenance).\n")
+	}
 	result.WriteString("Scheduled jobs:\n")
 	for _, j := range jobs {
 		var scheduleInfo string
@@ -236,6 +319,9 @@ func (t *CronTool) listJobs() *ToolResult {
 		} else {
 			scheduleInfo = "unknown"
 		}
+		if j.ByAgent() {
+			scheduleInfo += ", created by you"
+		}
 		result.WriteString(fmt.Sprintf("- %s (id: %s, %s)\n", j.Name, j.ID, scheduleInfo))
 	}
 
@@ -278,6 +364,12 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 	channel := job.Payload.Channel
 	chatID := job.Payload.To
 
+	// A run-as contact overrides where the job runs and replies
+	runAs := job.RunAs != nil && job.RunAs.Channel != "" && job.RunAs.ChatID != ""
+	if runAs {
+		channel, chatID = job.RunAs.Channel, job.RunAs.ChatID
+	}
+
 	// Default values if not set
 	if channel == "" {
 		channel = "cli"
@@ -300,25 +392,28 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 			output = fmt.Sprintf("Scheduled command '%s' executed:\n%s", job.Payload.Command, result.ForLLM)
 		}
 
-		pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer pubCancel()
-		t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-			Channel: channel,
-			ChatID:  chatID,
-			Content: output,
-		})
+		t.deliver(job, channel, chatID, output)
 		return "ok"
 	}
 
 	// If deliver=true, send message directly without agent processing
 	if job.Payload.Deliver {
-		pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer pubCancel()
-		t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
-			Channel: channel,
-			ChatID:  chatID,
-			Content: job.Payload.Message,
-		})
+		t.deliver(job, channel, chatID, job.Payload.Message)
+		return "ok"
+	}
+
+	message := job.Payload.Message
+	// The owner's recent conversation never goes into a contact's chat.
+	if rc, ok := t.executor.(RecentContextProvider); ok && job.Payload.WithContext && !runAs {
+		message = rc.WithRecentContext(ctx, message, t.recentCtx.Turns, t.recentCtx.MaxTokens)
+	}
+
+	// Run-as jobs become a turn in the contact's own conversation. Digest
+	// jobs still go through the plain path below so their reply is buffered.
+	if ce, ok := t.executor.(ContactJobExecutor); ok && runAs && !(job.Payload.Digest && t.hasDigestSink()) {
+		if _, err := ce.ProcessAsContact(ctx, message, channel, chatID); err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
 		return "ok"
 	}
 
@@ -328,7 +423,7 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 	// Call agent with job's message
 	response, err := t.executor.ProcessDirectWithChannel(
 		ctx,
-		job.Payload.Message,
+		message,
 		sessionKey,
 		channel,
 		chatID,
@@ -337,7 +432,84 @@ func (t *CronTool) ExecuteJob(ctx context.Context, job *cron.CronJob) string {
 		return fmt.Sprintf("Error: %v", err)
 	}
 
-	// Response is automatically sent via MessageBus by AgentLoop
-	_ = response // Will be sent by AgentLoop
+	// The agent normally talks to the user through its own tools, so the
+	// response is only forwarded for digest jobs, where it becomes the item.
+	if response != "" && job.Payload.Digest && t.hasDigestSink() {
+		t.deliver(job, channel, chatID, response)
+	}
 	return "ok"
 }
+
+func (t *CronTool) hasDigestSink() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.digestSink != nil
+}
+
+// deliver sends job output to the chat, or into the digest when the job asks for it.
+func (t *CronTool) deliver(job *cron.CronJob, channel, chatID, content string) {
+	content, urgent := stripUrgentMarker(content)
+	if !urgent && t.toDigest(job, content) {
+		return
+	}
+
+	pubCtx, pubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer pubCancel()
+	t.msgBus.PublishOutbound(pubCtx, bus.OutboundMessage{
+		Channel:    channel,
+		ChatID:     chatID,
+		Content:    content,
+		OnDelivery: t.recordDelivery(job),
+	})
+}
+
+// recordDelivery returns a callback that logs whether the platform accepted
+// the job's message and stores the outcome in the run history.
+func (t *CronTool) recordDelivery(job *cron.CronJob) func(bus.DeliveryStatus) {
+	var runAt int64
+	if job.State.LastRunAtMS != nil {
+		runAt = *job.State.LastRunAtMS
+	}
+	return func(status bus.DeliveryStatus) {
+		fields := map[string]any{"job_id": job.ID, "channel": status.Channel, "chat_id": status.ChatID}
+		switch status.State {
+		case bus.DeliveryFailed:
+			fields["error"] = status.Error
+			logger.WarnCF("cron", "Job delivery failed", fields)
+		case bus.DeliveryQueued:
+			logger.InfoCF("cron", "Job delivery queued for the end of quiet hours", fields)
+		default:
+			logger.InfoCF("cron", "Job delivery accepted", fields)
+		}
+		if runAt != 0 {
+			t.cronService.RecordDelivery(job.ID, runAt, string(status.State), status.Error)
+		}
+	}
+}
+
+// stripUrgentMarker removes a leading "[urgent]" marker and reports whether it was present.
+func stripUrgentMarker(content string) (string, bool) {
+	trimmed := strings.TrimSpace(content)
+	if len(trimmed) >= len(urgentMarker) && strings.EqualFold(trimmed[:len(urgentMarker)], urgentMarker) {
+		return strings.TrimSpace(trimmed[len(urgentMarker):]), true
+	}
+	return content, false
+}
+
+// toDigest buffers content when the job is digest-eligible and not marked
+// urgent. It reports whether the content was buffered.
+func (t *CronTool) toDigest(job *cron.CronJob, content string) bool {
+	t.mu.RLock()
+	sink := t.digestSink
+	t.mu.RUnlock()
+
+	if sink == nil || !job.Payload.Digest || job.Payload.Urgent {
+		return false
+	}
+	if err := sink("cron: "+job.Name, content); err != nil {
+		logger.WarnCF("cron", "Failed to buffer digest item, delivering now",
+			map[string]any{"job_id": job.ID, "error": err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/config"
+	"github.com/sipeed/picoclaw/pkg/cron"
+)
+
+func newTestCronTool(t *testing.T, maxJobs int) (*CronTool, *cron.CronService) {
+	t.Helper()
+	cs := cron.NewCronService(filepath.Join(t.TempDir(), "cron", "jobs.json"), nil)
+	cfg := config.DefaultConfig()
+	cfg.Tools.Cron.MaxAgentJobs = maxJobs
+	tool, err := NewCronTool(cs, nil, nil, t.TempDir(), true, 0, cfg)
+	if err != nil {
+		t.Fatalf("NewCronTool() error = %v", err)
+	}
+	tool.SetContext("telegram", "42")
+	return tool, cs
+}
+
+func TestCronTool_AddMarksAgentJobs(t *testing.T) {
+	tool, cs := newTestCronTool(t, 5)
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "add", "message": "call mum", "cron_expr": "0 9 * * *",
+	})
+	if result.IsError {
+		t.Fatalf("add failed: %s", result.ForLLM)
+	}
+
+	jobs := cs.ListJobs(true)
+	if len(jobs) != 1 {
+		t.Fatalf("got %d jobs, want 1", len(jobs))
+	}
+	if job := jobs[0]; !job.ByAgent() || job.Name != "agent: call mum" || job.Payload.To != "42" {
+		t.Errorf("job = %+v; want an agent job named 'agent: call mum' for chat 42", job)
+	}
+
+	list := tool.Execute(context.Background(), map[string]any{"action": "list"})
+	if !strings.Contains(list.ForLLM, "created by you") {
+		t.Errorf("list = %q; want agent jobs marked", list.ForLLM)
+	}
+}
+
+func TestCronTool_AgentJobLimit(t *testing.T) {
+	tool, cs := newTestCronTool(t, 2)
+	// Jobs the user added don't count against the limit.
+	if _, err := cs.AddJob("backup", cron.CronSchedule{Kind: "cron", Expr: "0 3 * * *"}, "backup", true, "telegram", "42"); err != nil {
+		t.Fatal(err)
+	}
+
+	add := map[string]any{"action": "add", "message": "drink water", "every_seconds": float64(3600)}
+	for i := 0; i < 2; i++ {
+		if result := tool.Execute(context.Background(), add); result.IsError {
+			t.Fatalf("add %d failed: %s", i+1, result.ForLLM)
+		}
+	}
+	result := tool.Execute(context.Background(), add)
+	if !result.IsError || !strings.Contains(result.ForLLM, "limit 2") {
+		t.Errorf("third add = %+v; want the limit error", result)
+	}
+}
+
+func TestCronTool_RequiresDeliveryTarget(t *testing.T) {
+	tool, _ := newTestCronTool(t, 0)
+	tool.SetContext("cli", "direct")
+
+	result := tool.Execute(context.Background(), map[string]any{
+		"action": "add", "message": "stretch", "at_seconds": float64(600),
+	})
+	if !result.IsError || !strings.Contains(result.ForLLM, "deliver") {
+		t.Errorf("add from the CLI = %+v; want an error about the delivery target", result)
+	}
+}
+
+// contactExecutor records the prompts of jobs run through it.
+type contactExecutor struct {
+	prompts []string
+}
+
+func (e *contactExecutor) ProcessDirectWithChannel(
+	ctx context.Context, content, sessionKey, channel, chatID string,
+) (string, error) {
+	e.prompts = append(e.prompts, content)
+	return "", nil
+}
+
+func (e *contactExecutor) ProcessAsContact(ctx context.Context, content, channel, chatID string) (string, error) {
+	e.prompts = append(e.prompts, content)
+	return "", nil
+}
+
+func (e *contactExecutor) WithRecentContext(ctx context.Context, prompt string, turns, maxTokens int) string {
+	return prompt + "\n\nowner's private chat"
+}
+
+func TestCronTool_RunAsJobsGetNoRecentContext(t *testing.T) {
+	cs := cron.NewCronService(filepath.Join(t.TempDir(), "cron", "jobs.json"), nil)
+	exec := &contactExecutor{}
+	tool, err := NewCronTool(cs, exec, nil, t.TempDir(), true, 0, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewCronTool() error = %v", err)
+	}
+
+	job := &cron.CronJob{
+		ID:      "1",
+		Payload: cron.CronPayload{Message: "remind them", WithContext: true},
+		RunAs:   &cron.RunAsContact{Channel: "telegram", ChatID: "99"},
+	}
+	tool.ExecuteJob(context.Background(), job)
+	job.RunAs = nil
+	tool.ExecuteJob(context.Background(), job)
+
+	if len(exec.prompts) != 2 {
+		t.Fatalf("ran %d prompts, want 2", len(exec.prompts))
+	}
+	if strings.Contains(exec.prompts[0], "owner's private chat") {
+		t.Errorf("run-as prompt = %q, must not carry the owner's recent context", exec.prompts[0])
+	}
+	if !strings.Contains(exec.prompts[1], "owner's private chat") {
+		t.Errorf("owner job prompt = %q, want the recent context", exec.prompts[1])
+	}
+}
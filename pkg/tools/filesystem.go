@@ -66,6 +66,13 @@ func validatePath(path, workspace string, restrict bool) (string, error) {
 	return absPath, nil
 }
 
+// ValidateWorkspacePath resolves path, relative to workspace or absolute,
+// and rejects it when it or a symlink along it leads outside the workspace.
+// The path need not exist.
+func ValidateWorkspacePath(path, workspace string) (string, error) {
+	return validatePath(path, workspace, true)
+}
+
 func resolveExistingAncestor(path string) (string, error) {
 	for current := filepath.Clean(path); ; current = filepath.Dir(current) {
 		if resolved, err := filepath.EvalSymlinks(current); err == nil {
@@ -3,15 +3,20 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sync"
 )
 
 type SendCallback func(channel, chatID, content string) error
 
 type MessageTool struct {
 	sendCallback   SendCallback
+	mu             sync.Mutex
 	defaultChannel string
 	defaultChatID  string
-	sentInRound    bool // Tracks whether a message was sent in the current processing round
+	// sentTo tracks, per "channel:chatID", whether a message was sent in the
+	// chat's current processing round. Turns for different chats may run
+	// concurrently, so a single flag would let one chat suppress another's reply.
+	sentTo map[string]bool
 }
 
 func NewMessageTool() *MessageTool {
@@ -48,14 +53,26 @@ func (t *MessageTool) Parameters() map[string]any {
 }
 
 func (t *MessageTool) SetContext(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	t.defaultChannel = channel
 	t.defaultChatID = chatID
-	t.sentInRound = false // Reset send tracking for new processing round
+	delete(t.sentTo, channel+":"+chatID) // Reset send tracking for new processing round
 }
 
 // HasSentInRound returns true if the message tool sent a message during the current round.
 func (t *MessageTool) HasSentInRound() bool {
-	return t.sentInRound
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sentTo[t.defaultChannel+":"+t.defaultChatID]
+}
+
+// HasSentTo returns true if the message tool sent a message during the
+// current round of the given chat.
+func (t *MessageTool) HasSentTo(channel, chatID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sentTo[channel+":"+chatID]
 }
 
 func (t *MessageTool) SetSendCallback(callback SendCallback) {
@@ -71,11 +88,18 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 	channel, _ := args["channel"].(string)
 	chatID, _ := args["chat_id"].(string)
 
+	t.mu.Lock()
+	curChannel, curChatID, ok := ToolContextFrom(ctx)
+	if !ok {
+		curChannel, curChatID = t.defaultChannel, t.defaultChatID
+	}
+	t.mu.Unlock()
+	round := curChannel + ":" + curChatID
 	if channel == "" {
-		channel = t.defaultChannel
+		channel = curChannel
 	}
 	if chatID == "" {
-		chatID = t.defaultChatID
+		chatID = curChatID
 	}
 
 	if channel == "" || chatID == "" {
@@ -94,7 +118,12 @@ func (t *MessageTool) Execute(ctx context.Context, args map[string]any) *ToolRes
 		}
 	}
 
-	t.sentInRound = true
+	t.mu.Lock()
+	if t.sentTo == nil {
+		t.sentTo = make(map[string]bool)
+	}
+	t.sentTo[round] = true
+	t.mu.Unlock()
 	// Silent: user already received the message directly
 	return &ToolResult{
 		ForLLM: fmt.Sprintf("Message sent to %s:%s", channel, chatID),
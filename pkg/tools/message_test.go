@@ -257,3 +257,51 @@ func TestMessageTool_Parameters(t *testing.T) {
 		t.Error("Expected chat_id type to be 'string'")
 	}
 }
+
+func TestMessageTool_SentTrackingIsPerChat(t *testing.T) {
+	tool := NewMessageTool()
+	tool.SetSendCallback(func(channel, chatID, content string) error { return nil })
+
+	tool.SetContext("telegram", "a")
+	tool.SetContext("telegram", "b")
+	tool.SetContext("telegram", "a")
+	if result := tool.Execute(context.Background(), map[string]any{"content": "hi"}); result.IsError {
+		t.Fatalf("Execute failed: %s", result.ForLLM)
+	}
+
+	if !tool.HasSentTo("telegram", "a") {
+		t.Error("Expected chat a to be marked as sent")
+	}
+	if tool.HasSentTo("telegram", "b") {
+		t.Error("A send in chat a must not suppress the reply in chat b")
+	}
+
+	tool.SetContext("telegram", "a")
+	if tool.HasSentTo("telegram", "a") {
+		t.Error("Expected a new round to reset chat a")
+	}
+}
+
+func TestMessageTool_ExecutePrefersCallContext(t *testing.T) {
+	tool := NewMessageTool()
+	var gotChatID string
+	tool.SetSendCallback(func(channel, chatID, content string) error {
+		gotChatID = chatID
+		return nil
+	})
+
+	// Another chat's turn started after this call's turn did.
+	tool.SetContext("telegram", "a")
+	tool.SetContext("telegram", "b")
+	ctx := WithToolContext(context.Background(), "telegram", "a")
+	if result := tool.Execute(ctx, map[string]any{"content": "hi"}); result.IsError {
+		t.Fatalf("Execute failed: %s", result.ForLLM)
+	}
+
+	if gotChatID != "a" {
+		t.Errorf("message went to chat %q, want a", gotChatID)
+	}
+	if !tool.HasSentTo("telegram", "a") || tool.HasSentTo("telegram", "b") {
+		t.Error("the send must count for chat a only")
+	}
+}
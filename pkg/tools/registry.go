@@ -69,9 +69,10 @@ func (r *ToolRegistry) ExecuteWithContext(
 		return ErrorResult(fmt.Sprintf("tool %q not found", name)).WithError(fmt.Errorf("tool not found"))
 	}
 
-	// If tool implements ContextualTool, set context
-	if contextualTool, ok := tool.(ContextualTool); ok && channel != "" && chatID != "" {
-		contextualTool.SetContext(channel, chatID)
+	// The conversation travels with the call rather than through SetContext,
+	// which a concurrent turn of another chat could overwrite before Execute.
+	if channel != "" && chatID != "" {
+		ctx = WithToolContext(ctx, channel, chatID)
 	}
 
 	// If tool implements AsyncTool and callback is provided, set callback
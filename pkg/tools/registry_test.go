@@ -36,6 +36,12 @@ func (m *mockCtxTool) SetContext(channel, chatID string) {
 	m.chatID = chatID
 }
 
+// Execute records the conversation the call carries in ctx.
+func (m *mockCtxTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	m.channel, m.chatID, _ = ToolContextFrom(ctx)
+	return m.mockRegistryTool.Execute(ctx, args)
+}
+
 type mockAsyncRegistryTool struct {
 	mockRegistryTool
 	cb AsyncCallback
@@ -163,7 +169,7 @@ func TestToolRegistry_ExecuteWithContext_SkipsEmptyContext(t *testing.T) {
 	r.ExecuteWithContext(context.Background(), "ctx_tool", nil, "", "", nil)
 
 	if ct.channel != "" || ct.chatID != "" {
-		t.Error("SetContext should not be called with empty channel/chatID")
+		t.Error("an empty channel/chatID must not be passed to the tool")
 	}
 }
 
@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/logger"
+)
+
+// DeliverCallback sends content to channel:chatID and returns once the
+// channel reports the outcome; a non-nil error means it was not delivered.
+type DeliverCallback func(ctx context.Context, channel, chatID, content string) error
+
+// Contact is a named target of the send_message tool.
+type Contact struct {
+	Channel string
+	ChatID  string
+}
+
+// ParseContacts parses configured "channel:chat_id" targets by name.
+func ParseContacts(raw map[string]string) (map[string]Contact, error) {
+	contacts := make(map[string]Contact, len(raw))
+	for name, target := range raw {
+		channel, chatID, ok := strings.Cut(target, ":")
+		if !ok || channel == "" || chatID == "" {
+			return nil, fmt.Errorf("contact %q: target %q must be channel:chat_id", name, target)
+		}
+		contacts[strings.ToLower(name)] = Contact{Channel: channel, ChatID: chatID}
+	}
+	return contacts, nil
+}
+
+// conversationIdle is how long a conversation may go without a user message
+// before its session is over and confirmed contacts must be confirmed again.
+const conversationIdle = 24 * time.Hour
+
+// pendingSend is a message to another chat waiting for the user's go-ahead.
+type pendingSend struct {
+	contact string
+	content string
+	turn    int
+}
+
+// SendMessageTool lets the agent message a configured contact other than the
+// current chat. Only listed contacts can be reached. The first message to a
+// contact from a conversation must be confirmed by the user in a later turn
+// of the same chat; after that the contact is trusted until the conversation
+// is reset or goes idle. Every decision is written to the audit log.
+type SendMessageTool struct {
+	contacts  map[string]Contact
+	deliver   DeliverCallback
+	auditPath string
+
+	mu        sync.Mutex
+	channel   string
+	chatID    string
+	turns     map[string]int             // conversation -> user turns seen
+	lastTurn  map[string]time.Time       // conversation -> time of the last user turn
+	pending   map[string]pendingSend     // conversation -> awaiting confirmation
+	confirmed map[string]map[string]bool // conversation -> contact -> confirmed
+}
+
+// NewSendMessageTool creates the tool. auditPath is the JSON Lines file the
+// audit trail is appended to; when empty, decisions are only logged.
+func NewSendMessageTool(contacts map[string]Contact, deliver DeliverCallback, auditPath string) *SendMessageTool {
+	return &SendMessageTool{
+		contacts:  contacts,
+		deliver:   deliver,
+		auditPath: auditPath,
+		turns:     make(map[string]int),
+		lastTurn:  make(map[string]time.Time),
+		pending:   make(map[string]pendingSend),
+		confirmed: make(map[string]map[string]bool),
+	}
+}
+
+func (t *SendMessageTool) Name() string {
+	return "send_message"
+}
+
+func (t *SendMessageTool) Description() string {
+	return fmt.Sprintf("Send a message to one of the user's saved contacts (%s), e.g. to tell someone the user is on "+
+		"their way. The first message to a contact needs the user's confirmation: ask them, and once they agree "+
+		"call this tool again with confirm=true and the same content. Reports whether the message was delivered.",
+		strings.Join(t.contactNames(), ", "))
+}
+
+func (t *SendMessageTool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"contact": map[string]any{
+				"type":        "string",
+				"description": "Name of the saved contact",
+				"enum":        t.contactNames(),
+			},
+			"content": map[string]any{
+				"type":        "string",
+				"description": "The message to send",
+			},
+			"confirm": map[string]any{
+				"type":        "boolean",
+				"description": "Set only after the user has agreed to send this message",
+			},
+		},
+		"required": []string{"contact", "content"},
+	}
+}
+
+// SetContext sets the conversation used when a call carries none.
+func (t *SendMessageTool) SetContext(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channel = channel
+	t.chatID = chatID
+}
+
+// UserTurn records a message from the user in channel:chatID. Only turns
+// recorded here can confirm a pending send. A conversation that has been
+// idle for conversationIdle starts over.
+func (t *SendMessageTool) UserTurn(channel, chatID string) {
+	conversation := channel + ":" + chatID
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.lastTurn[conversation]; ok && now.Sub(last) > conversationIdle {
+		t.forgetLocked(conversation)
+	}
+	t.turns[conversation]++
+	t.lastTurn[conversation] = now
+}
+
+// EndConversation forgets the pending and confirmed sends of channel:chatID.
+func (t *SendMessageTool) EndConversation(channel, chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.forgetLocked(channel + ":" + chatID)
+}
+
+func (t *SendMessageTool) forgetLocked(conversation string) {
+	delete(t.pending, conversation)
+	delete(t.confirmed, conversation)
+}
+
+func (t *SendMessageTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	name, _ := args["contact"].(string)
+	content, _ := args["content"].(string)
+	confirm, _ := args["confirm"].(bool)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.TrimSpace(content) == "" {
+		return ErrorResult("contact and content are required")
+	}
+
+	t.mu.Lock()
+	conversation := t.channel + ":" + t.chatID
+	if channel, chatID, ok := ToolContextFrom(ctx); ok {
+		conversation = channel + ":" + chatID
+	}
+	contact, known := t.contacts[name]
+	if !known {
+		t.mu.Unlock()
+		t.audit(conversation, name, "", "denied", "not an allowed contact")
+		return ErrorResult(fmt.Sprintf("%q is not an allowed contact. Allowed contacts: %s",
+			name, strings.Join(t.contactNames(), ", ")))
+	}
+	target := contact.Channel + ":" + contact.ChatID
+
+	needsConfirmation := target != conversation && !t.confirmed[conversation][name]
+	if needsConfirmation {
+		p, ok := t.pending[conversation]
+		turn := t.turns[conversation]
+		userTurn := IsUserTurn(ctx)
+		if !confirm || !ok || !userTurn || p.contact != name || p.content != content || p.turn >= turn {
+			// Confirmation must come from a later user turn, so a model
+			// cannot ask and confirm in the same breath, nor in a heartbeat
+			// or cron turn.
+			t.pending[conversation] = pendingSend{contact: name, content: content, turn: turn}
+			t.mu.Unlock()
+			t.audit(conversation, name, target, "confirmation_requested", "")
+			return NewToolResult(fmt.Sprintf("Not sent yet: the user must confirm the first message to %s. "+
+				"Show them the message, ask whether to send it, and if they agree call send_message again with "+
+				"confirm=true and the same content.", name))
+		}
+		delete(t.pending, conversation)
+		if t.confirmed[conversation] == nil {
+			t.confirmed[conversation] = make(map[string]bool)
+		}
+		t.confirmed[conversation][name] = true
+		t.audit(conversation, name, target, "confirmed", "")
+	}
+	t.mu.Unlock()
+
+	if t.deliver == nil {
+		return ErrorResult("Message sending not configured")
+	}
+	if err := t.deliver(ctx, contact.Channel, contact.ChatID, content); err != nil {
+		t.audit(conversation, name, target, "failed", err.Error())
+		return &ToolResult{
+			ForLLM:  fmt.Sprintf("Message to %s was not delivered: %v", name, err),
+			IsError: true,
+			Err:     err,
+		}
+	}
+	t.audit(conversation, name, target, "delivered", "")
+	return NewToolResult(fmt.Sprintf("Message delivered to %s", name))
+}
+
+func (t *SendMessageTool) contactNames() []string {
+	names := make([]string, 0, len(t.contacts))
+	for name := range t.contacts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+type sendMessageAuditEntry struct {
+	Time         time.Time `json:"time"`
+	Conversation string    `json:"conversation"`
+	Contact      string    `json:"contact"`
+	Target       string    `json:"target,omitempty"`
+	Outcome      string    `json:"outcome"`
+	Detail       string    `json:"detail,omitempty"`
+}
+
+// audit logs a decision and appends it to the audit file. Message content
+// is left out so the trail does not duplicate private conversations.
+func (t *SendMessageTool) audit(conversation, contact, target, outcome, detail string) {
+	entry := sendMessageAuditEntry{
+		Time:         time.Now().UTC(),
+		Conversation: conversation,
+		Contact:      contact,
+		Target:       target,
+		Outcome:      outcome,
+		Detail:       detail,
+	}
+	logger.InfoCF("send_message", "Send message "+outcome, map[string]any{
+		"conversation": conversation,
+		"contact":      contact,
+		"target":       target,
+		"detail":       detail,
+	})
+	if t.auditPath == "" {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(t.auditPath), 0o755); err != nil {
+		logger.WarnCF("send_message", "Failed to create audit directory", map[string]any{"error": err.Error()})
+		return
+	}
+	f, err := os.OpenFile(t.auditPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		logger.WarnCF("send_message", "Failed to open audit log", map[string]any{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logger.WarnCF("send_message", "Failed to write audit log", map[string]any{"error": err.Error()})
+	}
+}
@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type recordedSend struct {
+	channel, chatID, content string
+}
+
+func newTestSendMessageTool(t *testing.T, deliverErr error) (*SendMessageTool, *[]recordedSend, string) {
+	t.Helper()
+	contacts, err := ParseContacts(map[string]string{
+		"Wife": "telegram:111",
+		"me":   "telegram:999",
+	})
+	if err != nil {
+		t.Fatalf("ParseContacts() error = %v", err)
+	}
+	var sent []recordedSend
+	auditPath := filepath.Join(t.TempDir(), "audit", "send_message.jsonl")
+	tool := NewSendMessageTool(contacts, func(_ context.Context, channel, chatID, content string) error {
+		sent = append(sent, recordedSend{channel, chatID, content})
+		return deliverErr
+	}, auditPath)
+	return tool, &sent, auditPath
+}
+
+func readAuditOutcomes(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+	var outcomes []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry sendMessageAuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("bad audit line %q: %v", scanner.Text(), err)
+		}
+		outcomes = append(outcomes, entry.Outcome)
+	}
+	return outcomes
+}
+
+func TestParseContacts_RejectsBadTarget(t *testing.T) {
+	if _, err := ParseContacts(map[string]string{"bob": "12345"}); err == nil {
+		t.Error("ParseContacts() accepted a target without a channel")
+	}
+}
+
+func TestSendMessageTool_DeniesUnknownContact(t *testing.T) {
+	tool, sent, auditPath := newTestSendMessageTool(t, nil)
+	tool.SetContext("telegram", "999")
+
+	result := tool.Execute(context.Background(), map[string]any{"contact": "boss", "content": "hi", "confirm": true})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not an allowed contact") {
+		t.Errorf("result = %+v, want denial", result)
+	}
+	if len(*sent) != 0 {
+		t.Errorf("sent %d messages to an unknown contact", len(*sent))
+	}
+	if got := readAuditOutcomes(t, auditPath); len(got) != 1 || got[0] != "denied" {
+		t.Errorf("audit outcomes = %v, want [denied]", got)
+	}
+}
+
+func TestSendMessageTool_ConfirmationFlow(t *testing.T) {
+	tool, sent, auditPath := newTestSendMessageTool(t, nil)
+	ctx := WithUserTurn(context.Background())
+	args := map[string]any{"contact": "wife", "content": "Leaving now"}
+
+	tool.SetContext("telegram", "999")
+	tool.UserTurn("telegram", "999")
+	result := tool.Execute(ctx, args)
+	if result.IsError || !strings.Contains(result.ForLLM, "must confirm") {
+		t.Fatalf("first call result = %+v, want confirmation request", result)
+	}
+
+	// Confirming in the same turn does not count.
+	args["confirm"] = true
+	tool.Execute(ctx, args)
+	if len(*sent) != 0 {
+		t.Fatal("message sent without a user turn confirming it")
+	}
+
+	// The user agrees in the next turn.
+	tool.UserTurn("telegram", "999")
+	result = tool.Execute(ctx, args)
+	if result.IsError || result.ForLLM != "Message delivered to wife" {
+		t.Fatalf("confirmed call result = %+v, want delivery", result)
+	}
+	if len(*sent) != 1 || (*sent)[0] != (recordedSend{"telegram", "111", "Leaving now"}) {
+		t.Fatalf("sent = %+v, want one message to telegram:111", *sent)
+	}
+
+	// The contact stays confirmed for the rest of the conversation.
+	result = tool.Execute(ctx, map[string]any{"contact": "wife", "content": "Traffic is bad"})
+	if result.IsError || len(*sent) != 2 {
+		t.Errorf("follow-up result = %+v, sent %d, want direct delivery", result, len(*sent))
+	}
+
+	// Another conversation must confirm on its own.
+	tool.SetContext("discord", "42")
+	tool.UserTurn("discord", "42")
+	tool.Execute(ctx, map[string]any{"contact": "wife", "content": "Hello", "confirm": true})
+	if len(*sent) != 2 {
+		t.Error("confirmation leaked into another conversation")
+	}
+
+	want := []string{
+		"confirmation_requested", "confirmation_requested", "confirmed",
+		"delivered", "delivered", "confirmation_requested",
+	}
+	if got := readAuditOutcomes(t, auditPath); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("audit outcomes = %v, want %v", got, want)
+	}
+}
+
+func TestSendMessageTool_OnlyUserTurnsConfirm(t *testing.T) {
+	tool, sent, _ := newTestSendMessageTool(t, nil)
+	tool.SetContext("telegram", "999")
+	tool.UserTurn("telegram", "999")
+	args := map[string]any{"contact": "wife", "content": "Leaving now"}
+	tool.Execute(WithUserTurn(context.Background()), args)
+
+	// A heartbeat or cron turn that follows cannot confirm.
+	args["confirm"] = true
+	tool.Execute(context.Background(), args)
+	if len(*sent) != 0 {
+		t.Fatal("a non-user turn confirmed the send")
+	}
+
+	// Neither can a user turn in another chat.
+	tool.UserTurn("telegram", "555")
+	tool.Execute(WithUserTurn(context.Background()), args)
+	if len(*sent) != 0 {
+		t.Fatal("a user turn in another chat confirmed the send")
+	}
+}
+
+func TestSendMessageTool_EndConversationForgetsConfirmation(t *testing.T) {
+	tool, sent, _ := newTestSendMessageTool(t, nil)
+	ctx := WithUserTurn(context.Background())
+	tool.SetContext("telegram", "999")
+	tool.UserTurn("telegram", "999")
+	tool.Execute(ctx, map[string]any{"contact": "wife", "content": "Leaving now"})
+	tool.UserTurn("telegram", "999")
+	tool.Execute(ctx, map[string]any{"contact": "wife", "content": "Leaving now", "confirm": true})
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d messages, want the confirmed one", len(*sent))
+	}
+
+	tool.EndConversation("telegram", "999")
+	tool.UserTurn("telegram", "999")
+	result := tool.Execute(ctx, map[string]any{"contact": "wife", "content": "Home soon"})
+	if len(*sent) != 1 || !strings.Contains(result.ForLLM, "must confirm") {
+		t.Errorf("result = %+v, want a new confirmation after the conversation ended", result)
+	}
+}
+
+func TestSendMessageTool_CurrentChatNeedsNoConfirmation(t *testing.T) {
+	tool, sent, _ := newTestSendMessageTool(t, nil)
+	tool.SetContext("telegram", "999")
+
+	result := tool.Execute(context.Background(), map[string]any{"contact": "me", "content": "note to self"})
+	if result.IsError || len(*sent) != 1 {
+		t.Errorf("result = %+v, sent %d, want direct delivery", result, len(*sent))
+	}
+}
+
+func TestSendMessageTool_ReportsDeliveryFailure(t *testing.T) {
+	tool, _, auditPath := newTestSendMessageTool(t, errors.New("chat not found"))
+	tool.SetContext("telegram", "111")
+
+	result := tool.Execute(context.Background(), map[string]any{"contact": "wife", "content": "hi"})
+	if !result.IsError || !strings.Contains(result.ForLLM, "not delivered: chat not found") {
+		t.Errorf("result = %+v, want delivery failure", result)
+	}
+	if got := readAuditOutcomes(t, auditPath); len(got) != 1 || got[0] != "failed" {
+		t.Errorf("audit outcomes = %v, want [failed]", got)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -196,6 +197,36 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 		return ErrorResult(guardError)
 	}
 
+	if runtime.GOOS == "windows" {
+		return t.runCommand(ctx, cwd, nil, "powershell", "-NoProfile", "-NonInteractive", "-Command", command)
+	}
+	return t.runCommand(ctx, cwd, nil, "sh", "-c", command)
+}
+
+// RunArgs runs a program with arguments in dir without a shell, so the
+// arguments are passed as they are. The command line is checked by the same
+// safety guard as Execute. env holds NAME=value pairs added to the
+// environment the program inherits.
+func (t *ExecTool) RunArgs(ctx context.Context, dir string, args, env []string) *ToolResult {
+	if len(args) == 0 {
+		return ErrorResult("command is required")
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"") {
+			quoted[i] = strconv.Quote(arg)
+		}
+	}
+	if guardError := t.guardCommand(strings.Join(quoted, " "), dir); guardError != "" {
+		return ErrorResult(guardError)
+	}
+	return t.runCommand(ctx, dir, env, args[0], args[1:]...)
+}
+
+// runCommand runs a program in cwd with the tool's timeout and returns its
+// combined, truncated output. env is added to the inherited environment.
+func (t *ExecTool) runCommand(ctx context.Context, cwd string, env []string, name string, args ...string) *ToolResult {
 	// timeout == 0 means no timeout
 	var cmdCtx context.Context
 	var cancel context.CancelFunc
@@ -206,15 +237,13 @@ func (t *ExecTool) Execute(ctx context.Context, args map[string]any) *ToolResult
 	}
 	defer cancel()
 
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(cmdCtx, "powershell", "-NoProfile", "-NonInteractive", "-Command", command)
-	} else {
-		cmd = exec.CommandContext(cmdCtx, "sh", "-c", command)
-	}
+	cmd := exec.CommandContext(cmdCtx, name, args...)
 	if cwd != "" {
 		cmd.Dir = cwd
 	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	prepareCommandForTermination(cmd)
 
@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+// SkillCommandTool runs a command declared in a skill's frontmatter. The
+// arguments are validated against the command's schema and passed to the
+// program without a shell, through the agent's exec tool so its safety
+// guard and timeout apply. The variables the skill declares are set from
+// env, the configured tools.skills.env.
+type SkillCommandTool struct {
+	info skills.SkillCommandInfo
+	exec *ExecTool
+	env  map[string]string
+}
+
+// NewSkillCommandTool creates the tool for one skill command.
+func NewSkillCommandTool(info skills.SkillCommandInfo, exec *ExecTool, env map[string]string) *SkillCommandTool {
+	return &SkillCommandTool{info: info, exec: exec, env: env}
+}
+
+func (t *SkillCommandTool) Name() string {
+	return t.info.ToolName()
+}
+
+func (t *SkillCommandTool) Description() string {
+	desc := t.info.Command.Description
+	if desc == "" {
+		desc = "Run the " + t.info.Name + " command"
+	}
+	return fmt.Sprintf("[skill %s] %s", t.info.Skill, desc)
+}
+
+func (t *SkillCommandTool) Parameters() map[string]any {
+	return t.info.Command.Schema()
+}
+
+func (t *SkillCommandTool) Execute(ctx context.Context, args map[string]any) *ToolResult {
+	argv, err := t.info.Command.Args(args)
+	if err != nil {
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	if missing := skills.MissingEnv(t.info.Env, t.env); len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, v := range missing {
+			names[i] = v.Name
+		}
+		err := fmt.Errorf("skill %s needs %s; set it in tools.skills.env", t.info.Skill, strings.Join(names, ", "))
+		return ErrorResult(err.Error()).WithError(err)
+	}
+	return structuredSkillResult(t.exec.RunArgs(ctx, t.info.Dir, argv, skills.ResolveEnv(t.info.Env, t.env)))
+}
+
+// skillResult is the optional structured output of a skill command: a
+// single JSON object on stdout instead of free text.
+type skillResult struct {
+	// Summary tells the model in a sentence what the command found.
+	Summary string `json:"summary"`
+	// Data is the result itself, passed to the model verbatim.
+	Data json.RawMessage `json:"data"`
+	// Display is shown to the user as it is.
+	Display string `json:"display"`
+}
+
+// structuredSkillResult turns a command result whose stdout is a
+// skillResult into a tool result: the model gets the summary and the data,
+// the user gets the display text. Anything else, including failed runs and
+// JSON with other fields, is returned unchanged as plain text.
+func structuredSkillResult(result *ToolResult) *ToolResult {
+	if result.IsError {
+		return result
+	}
+	stdout, stderr, _ := strings.Cut(result.ForLLM, "\nSTDERR:\n")
+	sr, ok := parseSkillResult(stdout)
+	if !ok {
+		return result
+	}
+
+	var llm strings.Builder
+	if sr.Summary != "" {
+		llm.WriteString(sr.Summary)
+	}
+	if len(sr.Data) > 0 && string(sr.Data) != "null" {
+		if llm.Len() > 0 {
+			llm.WriteString("\n\n")
+		}
+		llm.WriteString("Data:\n")
+		llm.Write(sr.Data)
+	}
+	if sr.Display != "" {
+		if llm.Len() > 0 {
+			llm.WriteString("\n\n")
+		}
+		llm.WriteString("Already shown to the user:\n" + sr.Display)
+	}
+	if stderr != "" {
+		llm.WriteString("\n\nSTDERR:\n" + stderr)
+	}
+	return &ToolResult{
+		ForLLM:  llm.String(),
+		ForUser: sr.Display,
+		Silent:  sr.Display == "",
+		Media:   result.Media,
+	}
+}
+
+// parseSkillResult parses output as a skillResult. It accepts only an
+// object with no fields but summary, data and display, and with a summary
+// or a display, so ordinary JSON output stays plain text.
+func parseSkillResult(output string) (skillResult, bool) {
+	output = strings.TrimSpace(output)
+	if !strings.HasPrefix(output, "{") {
+		return skillResult{}, false
+	}
+	dec := json.NewDecoder(strings.NewReader(output))
+	dec.DisallowUnknownFields()
+	var sr skillResult
+	if err := dec.Decode(&sr); err != nil || dec.More() {
+		return skillResult{}, false
+	}
+	if sr.Summary == "" && sr.Display == "" {
+		return skillResult{}, false
+	}
+	if len(sr.Data) > 0 {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, sr.Data); err == nil {
+			sr.Data = compact.Bytes()
+		}
+	}
+	return sr, true
+}
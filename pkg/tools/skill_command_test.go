@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sipeed/picoclaw/pkg/skills"
+)
+
+func TestSkillCommandTool_PassesArgumentsWithoutShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	script := "printf '%s|' \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "echo.sh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	execTool, err := NewExecTool(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool := NewSkillCommandTool(skills.SkillCommandInfo{
+		Skill: "demo",
+		Name:  "echo",
+		Dir:   dir,
+		Command: skills.SkillCommand{
+			Description: "Echo a word",
+			Command:     "sh echo.sh {word}",
+			Parameters: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"word": map[string]any{"type": "string"}},
+				"required":   []any{"word"},
+			},
+		},
+	}, execTool, nil)
+
+	if tool.Name() != "skill_demo_echo" || !strings.Contains(tool.Description(), "Echo a word") {
+		t.Errorf("name %q, description %q", tool.Name(), tool.Description())
+	}
+
+	result := tool.Execute(context.Background(), map[string]any{"word": "a b; echo pwned"})
+	if result.IsError {
+		t.Fatalf("Execute error: %s", result.ForLLM)
+	}
+	if got := strings.TrimSpace(result.ForLLM); got != "a b; echo pwned|" {
+		t.Errorf("output = %q, want the value as one argument", got)
+	}
+
+	if result := tool.Execute(context.Background(), map[string]any{}); !result.IsError {
+		t.Error("missing required parameter accepted")
+	}
+}
+
+func TestSkillCommandTool_PassesDeclaredEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	script := "printf '%s|%s' \"$API_KEY\" \"$OTHER_KEY\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "env.sh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	execTool, err := NewExecTool(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := skills.SkillCommandInfo{
+		Skill:   "demo",
+		Name:    "env",
+		Dir:     dir,
+		Command: skills.SkillCommand{Command: "sh env.sh"},
+		Env:     []skills.SkillEnvVar{{Name: "API_KEY"}},
+	}
+
+	result := NewSkillCommandTool(info, execTool, nil).Execute(context.Background(), nil)
+	if !result.IsError || !strings.Contains(result.ForLLM, "needs API_KEY") {
+		t.Errorf("result without API_KEY = %q, want an error naming it", result.ForLLM)
+	}
+
+	env := map[string]string{"API_KEY": "secret", "OTHER_KEY": "not declared"}
+	result = NewSkillCommandTool(info, execTool, env).Execute(context.Background(), nil)
+	if result.IsError {
+		t.Fatalf("Execute error: %s", result.ForLLM)
+	}
+	if got := strings.TrimSpace(result.ForLLM); got != "secret|" {
+		t.Errorf("output = %q, want only the declared variable set", got)
+	}
+}
+
+func TestStructuredSkillResult(t *testing.T) {
+	out := `{"summary": "AAPL closed up 1.2%", "data": {"symbol": "AAPL", "price": 231.5},
+		"display": "AAPL: $231.50 (+1.2%)"}`
+	result := structuredSkillResult(UserResult(out))
+	if result.ForUser != "AAPL: $231.50 (+1.2%)" {
+		t.Errorf("ForUser = %q, want the display text", result.ForUser)
+	}
+	for _, want := range []string{"AAPL closed up 1.2%", `{"symbol":"AAPL","price":231.5}`, "Already shown to the user"} {
+		if !strings.Contains(result.ForLLM, want) {
+			t.Errorf("ForLLM = %q, want it to contain %q", result.ForLLM, want)
+		}
+	}
+
+	result = structuredSkillResult(UserResult(`{"summary": "saved", "data": [1, 2]}` + "\nSTDERR:\nwarning: slow"))
+	if result.ForUser != "" || !result.Silent {
+		t.Errorf("without display: got %+v, want nothing for the user", result)
+	}
+	if !strings.Contains(result.ForLLM, "[1,2]") || !strings.Contains(result.ForLLM, "warning: slow") {
+		t.Errorf("ForLLM = %q, want the data and stderr", result.ForLLM)
+	}
+}
+
+func TestStructuredSkillResult_PlainOutputUnchanged(t *testing.T) {
+	for _, out := range []string{
+		"It is sunny in Paris.",
+		`{"temperature": 21, "summary": "sunny"}`,
+		`{"data": {"a": 1}}`,
+		`{"summary": "one"} {"summary": "two"}`,
+		`{"summary": "truncated`,
+	} {
+		original := UserResult(out)
+		if result := structuredSkillResult(original); result != original {
+			t.Errorf("output %q was rewritten to %+v", out, result)
+		}
+	}
+
+	failed := ErrorResult(`{"summary": "partial", "display": "x"}`)
+	if result := structuredSkillResult(failed); result != failed {
+		t.Error("a failed run must stay as it is")
+	}
+}
@@ -86,7 +86,11 @@ func (t *SpawnTool) Execute(ctx context.Context, args map[string]any) *ToolResul
 	}
 
 	// Pass callback to manager for async completion notification
-	result, err := t.manager.Spawn(ctx, task, label, agentID, t.originChannel, t.originChatID, t.callback)
+	originChannel, originChatID := t.originChannel, t.originChatID
+	if channel, chatID, ok := ToolContextFrom(ctx); ok {
+		originChannel, originChatID = channel, chatID
+	}
+	result, err := t.manager.Spawn(ctx, task, label, agentID, originChannel, originChatID, t.callback)
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("failed to spawn subagent: %v", err))
 	}
@@ -307,6 +307,11 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		return ErrorResult("Subagent manager not configured").WithError(fmt.Errorf("manager is nil"))
 	}
 
+	originChannel, originChatID := t.originChannel, t.originChatID
+	if channel, chatID, ok := ToolContextFrom(ctx); ok {
+		originChannel, originChatID = channel, chatID
+	}
+
 	// Build messages for subagent
 	messages := []providers.Message{
 		{
@@ -347,7 +352,7 @@ func (t *SubagentTool) Execute(ctx context.Context, args map[string]any) *ToolRe
 		Tools:         tools,
 		MaxIterations: maxIter,
 		LLMOptions:    llmOptions,
-	}, messages, t.originChannel, t.originChatID)
+	}, messages, originChannel, originChatID)
 	if err != nil {
 		return ErrorResult(fmt.Sprintf("Subagent execution failed: %v", err)).WithError(err)
 	}
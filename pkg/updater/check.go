@@ -0,0 +1,62 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/fileutil"
+)
+
+// CheckInterval is how long a cached release check stays valid.
+const CheckInterval = 24 * time.Hour
+
+// CheckResult is the outcome of a release check, cached between runs.
+type CheckResult struct {
+	Latest    string    `json:"latest"`
+	URL       string    `json:"url,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// CheckCachePath returns where the last release check is stored.
+func CheckCachePath(workspace string) string {
+	return filepath.Join(workspace, "state", "update_check.json")
+}
+
+// CheckLatest returns the latest stable release, reusing the result cached
+// at cachePath when it is younger than maxAge. A fresh result is written
+// back; failing to write it does not fail the check.
+func (c *Client) CheckLatest(ctx context.Context, cachePath string, maxAge time.Duration) (*CheckResult, error) {
+	if cached, ok := readCheck(cachePath); ok && time.Since(cached.CheckedAt) < maxAge {
+		return cached, nil
+	}
+
+	rel, err := c.LatestRelease(ctx, ChannelStable)
+	if err != nil {
+		return nil, err
+	}
+	res := &CheckResult{Latest: rel.TagName, URL: rel.HTMLURL, CheckedAt: time.Now()}
+	if data, err := json.MarshalIndent(res, "", "  "); err == nil {
+		_ = fileutil.WriteFileAtomic(cachePath, data, 0o644)
+	}
+	return res, nil
+}
+
+func readCheck(path string) (*CheckResult, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var res CheckResult
+	if err := json.Unmarshal(data, &res); err != nil || res.Latest == "" {
+		return nil, false
+	}
+	return &res, true
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+
+package updater
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// replaceExecutable renames newPath over exePath. Unix keeps a running
+// executable's inode alive after it is unlinked, so the rename is safe while
+// picoclaw is running. When keepBackup is set the current binary is first
+// hard-linked (or copied) to BackupPath so there is never a moment without
+// a binary at exePath.
+func replaceExecutable(exePath, newPath string, keepBackup bool) error {
+	if keepBackup {
+		backup := BackupPath(exePath)
+		_ = os.Remove(backup)
+		if err := os.Link(exePath, backup); err != nil {
+			if err := copyFile(exePath, backup); err != nil {
+				return fmt.Errorf("failed to back up current binary: %w", err)
+			}
+		}
+	}
+
+	if err := os.Rename(newPath, exePath); err != nil {
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
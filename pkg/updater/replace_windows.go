@@ -0,0 +1,37 @@
+//go:build windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+)
+
+// replaceExecutable swaps newPath into exePath. Windows refuses to overwrite
+// or delete a running executable but does allow renaming it, so the current
+// binary is moved aside first and the new one renamed into place.
+func replaceExecutable(exePath, newPath string, keepBackup bool) error {
+	backup := BackupPath(exePath)
+
+	// Rollback: the new binary is the backup itself, so park the current
+	// one under a temporary name instead.
+	aside := backup
+	if newPath == backup {
+		aside = exePath + ".rollback"
+	}
+
+	_ = os.Remove(aside)
+	if err := os.Rename(exePath, aside); err != nil {
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(newPath, exePath); err != nil {
+		_ = os.Rename(aside, exePath)
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+
+	if !keepBackup || aside != backup {
+		// Best effort: fails while the old binary is still running.
+		_ = os.Remove(aside)
+	}
+	return nil
+}
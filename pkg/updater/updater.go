@@ -0,0 +1,510 @@
+// PicoClaw - Ultra-lightweight personal AI agent
+// License: MIT
+//
+// Copyright (c) 2026 PicoClaw contributors
+
+// Package updater checks GitHub releases for new picoclaw builds and
+// replaces the running binary in place.
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sipeed/picoclaw/pkg/utils"
+)
+
+const (
+	DefaultRepo    = "sipeed/picoclaw"
+	defaultAPIBase = "https://api.github.com"
+
+	ChannelStable     = "stable"
+	ChannelPrerelease = "prerelease"
+
+	defaultTimeout     = 60 * time.Second
+	maxReleaseJSONSize = 4 * 1024 * 1024   // 4 MB
+	maxArchiveSize     = 200 * 1024 * 1024 // 200 MB
+)
+
+// Release is the subset of the GitHub release payload the updater needs.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	HTMLURL    string  `json:"html_url"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a downloadable file attached to a release.
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+}
+
+// Client queries GitHub releases for a repository.
+type Client struct {
+	repo    string
+	apiBase string
+	client  *http.Client
+}
+
+// NewClient creates a release client for repo ("owner/name").
+func NewClient(repo string) *Client {
+	if repo == "" {
+		repo = DefaultRepo
+	}
+	return &Client{
+		repo:    repo,
+		apiBase: defaultAPIBase,
+		client:  &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// WithAPIBase overrides the GitHub API base URL (used by tests and mirrors).
+func (c *Client) WithAPIBase(apiBase string) *Client {
+	c.apiBase = strings.TrimRight(apiBase, "/")
+	return c
+}
+
+// LatestRelease returns the newest release on the given channel.
+// The stable channel ignores prereleases; the prerelease channel considers both.
+func (c *Client) LatestRelease(ctx context.Context, channel string) (*Release, error) {
+	switch channel {
+	case "", ChannelStable:
+		var rel Release
+		if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", c.apiBase, c.repo), &rel); err != nil {
+			return nil, err
+		}
+		return &rel, nil
+	case ChannelPrerelease:
+		var releases []Release
+		if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/releases?per_page=20", c.apiBase, c.repo), &releases); err != nil {
+			return nil, err
+		}
+		for i := range releases {
+			if !releases[i].Draft {
+				return &releases[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no releases found for %s", c.repo)
+	default:
+		return nil, fmt.Errorf("unknown release channel %q (expected %s or %s)", channel, ChannelStable, ChannelPrerelease)
+	}
+}
+
+// ReleaseByTag returns the release tagged tag; "1.3.0" and "V1.3.0" are
+// read as "v1.3.0".
+func (c *Client) ReleaseByTag(ctx context.Context, tag string) (*Release, error) {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return nil, fmt.Errorf("empty release tag")
+	}
+	var rel Release
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/releases/tags/%s", c.apiBase, c.repo, url.PathEscape(tag)), &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("release request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReleaseJSONSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("release request failed: HTTP %d: %s", resp.StatusCode, utils.Truncate(string(body), 200))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse release response: %w", err)
+	}
+	return nil
+}
+
+// AssetName returns the release archive name for a platform, matching the
+// goreleaser name_template (e.g. picoclaw_Linux_x86_64.tar.gz).
+func AssetName(goos, goarch, goarm string) string {
+	osName := strings.ToUpper(goos[:1]) + goos[1:]
+
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	case "arm":
+		if goarm == "" {
+			goarm = "7"
+		}
+		arch = "armv" + goarm
+	}
+
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return "picoclaw_" + osName + "_" + arch + ext
+}
+
+// CurrentAssetName returns the archive name for the running platform.
+func CurrentAssetName() string {
+	return AssetName(runtime.GOOS, runtime.GOARCH, goarm())
+}
+
+// goarm returns the GOARM level the binary was built with ("" if unknown).
+func goarm() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "GOARM" && s.Value != "" {
+				return s.Value[:1]
+			}
+		}
+	}
+	return ""
+}
+
+// FindAsset returns the asset with the given name, or nil.
+func (r *Release) FindAsset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// ChecksumAsset returns the goreleaser checksums file, or nil.
+func (r *Release) ChecksumAsset() *Asset {
+	for i := range r.Assets {
+		if strings.HasSuffix(r.Assets[i].Name, "checksums.txt") {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// NormalizeTag returns version as a release tag: trimmed, with a leading
+// "v" added to a bare version number and a leading "V" lowercased.
+func NormalizeTag(version string) string {
+	version = strings.TrimSpace(version)
+	switch {
+	case version == "":
+		return ""
+	case version[0] == 'V':
+		return "v" + version[1:]
+	case version[0] >= '0' && version[0] <= '9':
+		return "v" + version
+	}
+	return version
+}
+
+// IsNewer reports whether latest is a higher version than current.
+// Non-release builds (e.g. "dev") are always considered older.
+func IsNewer(current, latest string) bool {
+	cur, okCur := parseVersion(current)
+	lat, okLat := parseVersion(latest)
+	if !okLat {
+		return false
+	}
+	if !okCur {
+		return true
+	}
+	for i := range 3 {
+		if lat.nums[i] != cur.nums[i] {
+			return lat.nums[i] > cur.nums[i]
+		}
+	}
+	// Same core version: a release outranks its prereleases.
+	if cur.pre != "" && lat.pre == "" {
+		return true
+	}
+	if cur.pre == "" || lat.pre == "" {
+		return false
+	}
+	return comparePrerelease(lat.pre, cur.pre) > 0
+}
+
+// comparePrerelease orders two prerelease strings as semver does: the
+// dot-separated identifiers are compared in turn, numerically when both are
+// numbers, a number sorts before a word, and a longer list wins a tie, so
+// rc.9 < rc.10 and beta < beta.1.
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return cmp.Compare(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+type semver struct {
+	nums [3]int
+	pre  string
+}
+
+func parseVersion(v string) (semver, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	var sv semver
+	core := v
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		core, sv.pre = v[:i], v[i+1:]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return semver{}, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return semver{}, false
+		}
+		sv.nums[i] = n
+	}
+	return sv, true
+}
+
+// Download fetches asset, verifies it against the release's published
+// sha256 checksums and extracts the picoclaw binary into dir.
+// It returns the path of the extracted binary.
+func (c *Client) Download(ctx context.Context, rel *Release, asset *Asset, dir string) (string, error) {
+	sumsAsset := rel.ChecksumAsset()
+	if sumsAsset == nil {
+		return "", fmt.Errorf("release %s has no checksums file", rel.TagName)
+	}
+	expected, err := c.fetchChecksum(ctx, sumsAsset, asset.Name)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", asset.BrowserDownloadURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	archivePath, err := utils.DownloadToFile(ctx, c.client, req, maxArchiveSize)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	defer os.Remove(archivePath)
+
+	if err := VerifyChecksum(archivePath, expected); err != nil {
+		return "", err
+	}
+
+	return extractBinary(archivePath, asset.Name, dir)
+}
+
+func (c *Client) fetchChecksum(ctx context.Context, sums *Asset, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", sums.BrowserDownloadURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("checksum request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checksum request failed: HTTP %d", resp.StatusCode)
+	}
+
+	sum, err := ParseChecksums(io.LimitReader(resp.Body, maxReleaseJSONSize), name)
+	if err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// ParseChecksums finds the sha256 for name in a "<hex>  <filename>" listing.
+func ParseChecksums(r io.Reader, name string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksums: %w", err)
+	}
+	return "", fmt.Errorf("no checksum published for %s", name)
+}
+
+// VerifyChecksum compares the sha256 of the file at path to the expected hex digest.
+func VerifyChecksum(path, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to hash download: %w", err)
+	}
+	got := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(got, expected) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, got)
+	}
+	return nil
+}
+
+// binaryName is the executable name inside release archives.
+func binaryName() string {
+	if runtime.GOOS == "windows" {
+		return "picoclaw.exe"
+	}
+	return "picoclaw"
+}
+
+func extractBinary(archivePath, assetName, dir string) (string, error) {
+	out, err := os.CreateTemp(dir, ".picoclaw-new-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	outPath := out.Name()
+	ok := false
+	defer func() {
+		out.Close()
+		if !ok {
+			os.Remove(outPath)
+		}
+	}()
+
+	if strings.HasSuffix(assetName, ".zip") {
+		err = extractFromZip(archivePath, out)
+	} else {
+		err = extractFromTarGz(archivePath, out)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := out.Sync(); err != nil {
+		return "", err
+	}
+	if err := out.Chmod(0o755); err != nil {
+		return "", err
+	}
+	ok = true
+	return outPath, nil
+}
+
+func extractFromTarGz(archivePath string, out io.Writer) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == binaryName() {
+			_, err = io.Copy(out, io.LimitReader(tr, maxArchiveSize))
+			return err
+		}
+	}
+	return fmt.Errorf("%s not found in archive", binaryName())
+}
+
+func extractFromZip(archivePath string, out io.Writer) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+		if filepath.Base(zf.Name) != binaryName() || zf.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		_, err = io.Copy(out, io.LimitReader(rc, maxArchiveSize))
+		return err
+	}
+	return fmt.Errorf("%s not found in archive", binaryName())
+}
+
+// BackupPath returns where the previous binary is kept after an update.
+func BackupPath(exePath string) string {
+	ext := filepath.Ext(exePath)
+	return strings.TrimSuffix(exePath, ext) + ".old" + ext
+}
+
+// Rollback restores the binary saved by the last update.
+func Rollback(exePath string) error {
+	backup := BackupPath(exePath)
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("no previous binary found at %s", backup)
+	}
+	return replaceExecutable(exePath, backup, false)
+}
+
+// Install atomically replaces exePath with newPath, keeping the previous
+// binary next to it (see BackupPath) so the update can be rolled back.
+func Install(exePath, newPath string) error {
+	return replaceExecutable(exePath, newPath, true)
+}
@@ -0,0 +1,234 @@
+package updater
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetName(t *testing.T) {
+	assert.Equal(t, "picoclaw_Linux_x86_64.tar.gz", AssetName("linux", "amd64", ""))
+	assert.Equal(t, "picoclaw_Linux_armv6.tar.gz", AssetName("linux", "arm", "6"))
+	assert.Equal(t, "picoclaw_Darwin_arm64.tar.gz", AssetName("darwin", "arm64", ""))
+	assert.Equal(t, "picoclaw_Windows_x86_64.zip", AssetName("windows", "amd64", ""))
+}
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"v0.1.0", "v0.2.0", true},
+		{"0.2.0", "v0.2.0", false},
+		{"v1.2.3", "v1.2.2", false},
+		{"v1.0.0-rc1", "v1.0.0", true},
+		{"v1.0.0", "v1.0.0-rc1", false},
+		{"v1.0.0-rc1", "v1.0.0-rc2", true},
+		{"v1.0.0-rc.9", "v1.0.0-rc.10", true},
+		{"v1.0.0-rc.10", "v1.0.0-rc.9", false},
+		{"v1.0.0-rc.1", "v1.0.0", true},
+		{"v1.0.0", "v1.0.0-rc.1", false},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", true},
+		{"v1.0.0-alpha.1", "v1.0.0-alpha.beta", true},
+		{"v1.0.0-beta.2", "v1.0.0-beta.11", true},
+		{"v1.0.0-rc.1", "v1.0.0-rc.1", false},
+		{"dev", "v0.1.0", true},
+		{"v0.1.0", "nightly", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsNewer(tt.current, tt.latest), "%s -> %s", tt.current, tt.latest)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	listing := "abc123  picoclaw_Linux_x86_64.tar.gz\nDEF456  picoclaw_Windows_x86_64.zip\n"
+
+	sum, err := ParseChecksums(strings.NewReader(listing), "picoclaw_Windows_x86_64.zip")
+	require.NoError(t, err)
+	assert.Equal(t, "def456", sum)
+
+	_, err = ParseChecksums(strings.NewReader(listing), "picoclaw_Darwin_arm64.tar.gz")
+	assert.Error(t, err)
+}
+
+func TestLatestReleasePrereleaseChannel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/sipeed/picoclaw/releases", r.URL.Path)
+		json.NewEncoder(w).Encode([]Release{
+			{TagName: "v0.3.0-draft", Draft: true},
+			{TagName: "v0.3.0-rc1", Prerelease: true},
+			{TagName: "v0.2.0"},
+		})
+	}))
+	defer srv.Close()
+
+	rel, err := NewClient("").WithAPIBase(srv.URL).LatestRelease(context.Background(), ChannelPrerelease)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.3.0-rc1", rel.TagName)
+
+	_, err = NewClient("").WithAPIBase(srv.URL).LatestRelease(context.Background(), "nightly")
+	assert.Error(t, err)
+}
+
+func TestDownloadVerifiesChecksum(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("archive fixture is tar.gz")
+	}
+	archive := buildTarGz(t, "picoclaw", "new-binary")
+	sum := sha256.Sum256(archive)
+	assetName := "picoclaw_Linux_x86_64.tar.gz"
+
+	checksums := hex.EncodeToString(sum[:]) + "  " + assetName + "\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/checksums.txt":
+			fmt.Fprint(w, checksums)
+		case "/" + assetName:
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	rel := &Release{
+		TagName: "v9.9.9",
+		Assets: []Asset{
+			{Name: assetName, BrowserDownloadURL: srv.URL + "/" + assetName},
+			{Name: "picoclaw_9.9.9_checksums.txt", BrowserDownloadURL: srv.URL + "/checksums.txt"},
+		},
+	}
+	client := NewClient("")
+	dir := t.TempDir()
+
+	path, err := client.Download(context.Background(), rel, rel.FindAsset(assetName), dir)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new-binary", string(data))
+
+	checksums = strings.Repeat("0", 64) + "  " + assetName + "\n"
+	_, err = client.Download(context.Background(), rel, rel.FindAsset(assetName), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestInstallAndRollback(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "picoclaw")
+	newBin := filepath.Join(dir, ".picoclaw-new")
+	require.NoError(t, os.WriteFile(exe, []byte("old"), 0o755))
+	require.NoError(t, os.WriteFile(newBin, []byte("new"), 0o755))
+
+	require.NoError(t, Install(exe, newBin))
+	assertFile(t, exe, "new")
+	assertFile(t, BackupPath(exe), "old")
+
+	require.NoError(t, Rollback(exe))
+	assertFile(t, exe, "old")
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(data))
+}
+
+func buildTarGz(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg,
+	}))
+	_, err := tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestCheckLatestCachesResult(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/repos/sipeed/picoclaw/releases/latest", r.URL.Path)
+		json.NewEncoder(w).Encode(Release{TagName: "v1.3.0", HTMLURL: "https://example.com/v1.3.0"})
+	}))
+	defer srv.Close()
+
+	client := NewClient("").WithAPIBase(srv.URL)
+	cachePath := CheckCachePath(t.TempDir())
+
+	res, err := client.CheckLatest(context.Background(), cachePath, CheckInterval)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", res.Latest)
+	assert.Equal(t, "https://example.com/v1.3.0", res.URL)
+
+	res, err = client.CheckLatest(context.Background(), cachePath, CheckInterval)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", res.Latest)
+	assert.Equal(t, 1, requests, "a fresh cached result should not hit the API")
+
+	stale := CheckResult{Latest: "v1.2.0", CheckedAt: time.Now().Add(-25 * time.Hour)}
+	data, _ := json.Marshal(stale)
+	require.NoError(t, os.WriteFile(cachePath, data, 0o644))
+
+	res, err = client.CheckLatest(context.Background(), cachePath, CheckInterval)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", res.Latest)
+	assert.Equal(t, 2, requests, "an expired cached result should be refreshed")
+}
+
+func TestReleaseByTag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/sipeed/picoclaw/releases/tags/v1.3.0" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(Release{TagName: "v1.3.0"})
+	}))
+	defer srv.Close()
+
+	client := NewClient("").WithAPIBase(srv.URL)
+	for _, tag := range []string{"v1.3.0", "1.3.0", " V1.3.0"} {
+		rel, err := client.ReleaseByTag(context.Background(), tag)
+		require.NoError(t, err, tag)
+		assert.Equal(t, "v1.3.0", rel.TagName)
+	}
+
+	_, err := client.ReleaseByTag(context.Background(), "v9.9.9")
+	assert.ErrorContains(t, err, "HTTP 404")
+}
+
+func TestNormalizeTag(t *testing.T) {
+	for in, want := range map[string]string{
+		"1.3.0":     "v1.3.0",
+		"v1.3.0":    "v1.3.0",
+		"V1.3.0":    "v1.3.0",
+		" 1.3.0 ":   "v1.3.0",
+		"nightly":   "nightly",
+		"":          "",
+		"1.4.0-rc1": "v1.4.0-rc1",
+	} {
+		assert.Equal(t, want, NormalizeTag(in), in)
+	}
+}
@@ -10,6 +10,20 @@ import (
 	"github.com/sipeed/picoclaw/pkg/logger"
 )
 
+// HTTPStatusError is returned when a download gets a non-2xx response.
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string // the start of the response body
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// DownloadProgress is told how many bytes of a download have been written,
+// and the total from Content-Length (-1 when unknown).
+type DownloadProgress func(written, total int64)
+
 // DownloadToFile streams an HTTP response body to a temporary file in small
 // chunks (~32KB), keeping peak memory usage constant regardless of file size.
 //
@@ -24,6 +38,18 @@ import (
 //
 // On any error the temp file is cleaned up automatically.
 func DownloadToFile(ctx context.Context, client *http.Client, req *http.Request, maxBytes int64) (string, error) {
+	return DownloadToFileWithProgress(ctx, client, req, maxBytes, nil)
+}
+
+// DownloadToFileWithProgress is DownloadToFile, calling progress (if not
+// nil) as the body is written.
+func DownloadToFileWithProgress(
+	ctx context.Context,
+	client *http.Client,
+	req *http.Request,
+	maxBytes int64,
+	progress DownloadProgress,
+) (string, error) {
 	// Attach context.
 	req = req.WithContext(ctx)
 
@@ -42,7 +68,7 @@ func DownloadToFile(ctx context.Context, client *http.Client, req *http.Request,
 		// Read a small amount for the error message.
 		errBody := make([]byte, 512)
 		n, _ := io.ReadFull(resp.Body, errBody)
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(errBody[:n]))
+		return "", &HTTPStatusError{StatusCode: resp.StatusCode, Body: string(errBody[:n])}
 	}
 
 	// Create temp file.
@@ -67,6 +93,9 @@ func DownloadToFile(ctx context.Context, client *http.Client, req *http.Request,
 	if maxBytes > 0 {
 		src = io.LimitReader(resp.Body, maxBytes+1) // +1 to detect overflow
 	}
+	if progress != nil {
+		src = &progressReader{r: src, total: resp.ContentLength, progress: progress}
+	}
 
 	written, err := io.Copy(tmpFile, src)
 	if err != nil {
@@ -91,3 +120,20 @@ func DownloadToFile(ctx context.Context, client *http.Client, req *http.Request,
 
 	return tmpPath, nil
 }
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	r        io.Reader
+	read     int64
+	total    int64
+	progress DownloadProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		p.progress(p.read, p.total)
+	}
+	return n, err
+}
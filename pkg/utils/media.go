@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -11,6 +13,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/sipeed/picoclaw/pkg/logger"
+	"github.com/sipeed/picoclaw/pkg/media"
 )
 
 // IsAudioFile checks if a file is an audio file based on its filename extension and content type.
@@ -52,11 +55,33 @@ type DownloadOptions struct {
 	Timeout      time.Duration
 	ExtraHeaders map[string]string
 	LoggerPrefix string
+	MaxBytes     int64 // largest file accepted; 0 means no limit
 }
 
+// ErrFileTooLarge is returned by FetchFile when a file exceeds
+// DownloadOptions.MaxBytes.
+var ErrFileTooLarge = errors.New("file too large")
+
 // DownloadFile downloads a file from URL to a local temp directory.
 // Returns the local file path or empty string on error.
 func DownloadFile(url, filename string, opts DownloadOptions) string {
+	localPath, err := FetchFile(url, filename, opts)
+	if errors.Is(err, ErrFileTooLarge) {
+		logger.WarnCF(opts.LoggerPrefix, "Rejected oversized download", map[string]any{
+			"url":   url,
+			"error": err.Error(),
+		})
+	}
+	return localPath
+}
+
+// FetchFile is DownloadFile returning why a download failed. A file over
+// opts.MaxBytes is rejected from its Content-Length before anything is
+// written, or once it grows past the limit when the length is not known;
+// the partial file is removed and the error wraps ErrFileTooLarge. Other
+// errors are logged. Images are normalized with media.NormalizeImage, which
+// may change the returned path's extension.
+func FetchFile(url, filename string, opts DownloadOptions) (string, error) {
 	// Set defaults
 	if opts.Timeout == 0 {
 		opts.Timeout = 60 * time.Second
@@ -70,7 +95,7 @@ func DownloadFile(url, filename string, opts DownloadOptions) string {
 		logger.ErrorCF(opts.LoggerPrefix, "Failed to create media directory", map[string]any{
 			"error": err.Error(),
 		})
-		return ""
+		return "", err
 	}
 
 	// Generate unique filename with UUID prefix to prevent conflicts
@@ -83,7 +108,7 @@ func DownloadFile(url, filename string, opts DownloadOptions) string {
 		logger.ErrorCF(opts.LoggerPrefix, "Failed to create download request", map[string]any{
 			"error": err.Error(),
 		})
-		return ""
+		return "", err
 	}
 
 	// Add extra headers (e.g., Authorization for Slack)
@@ -98,7 +123,7 @@ func DownloadFile(url, filename string, opts DownloadOptions) string {
 			"error": err.Error(),
 			"url":   url,
 		})
-		return ""
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -107,7 +132,11 @@ func DownloadFile(url, filename string, opts DownloadOptions) string {
 			"status": resp.StatusCode,
 			"url":    url,
 		})
-		return ""
+		return "", fmt.Errorf("download returned HTTP %d", resp.StatusCode)
+	}
+
+	if opts.MaxBytes > 0 && resp.ContentLength > opts.MaxBytes {
+		return "", fmt.Errorf("%w: %d bytes (max %d)", ErrFileTooLarge, resp.ContentLength, opts.MaxBytes)
 	}
 
 	out, err := os.Create(localPath)
@@ -115,24 +144,36 @@ func DownloadFile(url, filename string, opts DownloadOptions) string {
 		logger.ErrorCF(opts.LoggerPrefix, "Failed to create local file", map[string]any{
 			"error": err.Error(),
 		})
-		return ""
+		return "", err
 	}
 	defer out.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
+	var body io.Reader = resp.Body
+	if opts.MaxBytes > 0 {
+		body = io.LimitReader(resp.Body, opts.MaxBytes+1) // +1 to detect overflow
+	}
+	written, err := io.Copy(out, body)
+	if err != nil {
 		out.Close()
 		os.Remove(localPath)
 		logger.ErrorCF(opts.LoggerPrefix, "Failed to write file", map[string]any{
 			"error": err.Error(),
 		})
-		return ""
+		return "", err
+	}
+	if opts.MaxBytes > 0 && written > opts.MaxBytes {
+		out.Close()
+		os.Remove(localPath)
+		return "", fmt.Errorf("%w: over %d bytes", ErrFileTooLarge, opts.MaxBytes)
 	}
 
+	out.Close()
+
 	logger.DebugCF(opts.LoggerPrefix, "File downloaded successfully", map[string]any{
 		"path": localPath,
 	})
 
-	return localPath
+	return media.NormalizeImage(localPath), nil
 }
 
 // DownloadFileSimple is a simplified version of DownloadFile without options
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetchFile_MaxBytes(t *testing.T) {
+	body := strings.Repeat("x", 1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// No Content-Length: the limit applies while streaming.
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	path, err := FetchFile(server.URL+"/file", "small.bin", DownloadOptions{MaxBytes: 2048})
+	if err != nil {
+		t.Fatalf("FetchFile() under the limit error: %v", err)
+	}
+	defer os.Remove(path)
+	if data, _ := os.ReadFile(path); len(data) != len(body) {
+		t.Errorf("downloaded %d bytes, want %d", len(data), len(body))
+	}
+
+	for _, url := range []string{server.URL + "/file", server.URL + "/chunked"} {
+		before, _ := filepath.Glob(filepath.Join(os.TempDir(), "picoclaw_media", "*_big.bin"))
+		path, err := FetchFile(url, "big.bin", DownloadOptions{MaxBytes: 100})
+		if !errors.Is(err, ErrFileTooLarge) || path != "" {
+			t.Fatalf("FetchFile(%s) = %q, %v; want ErrFileTooLarge", url, path, err)
+		}
+		after, _ := filepath.Glob(filepath.Join(os.TempDir(), "picoclaw_media", "*_big.bin"))
+		if len(after) != len(before) {
+			t.Errorf("FetchFile(%s) left a partial file behind", url)
+		}
+	}
+}